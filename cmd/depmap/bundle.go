@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-depmap/pkg/format"
+	"go-depmap/pkg/graph"
+	"go-depmap/pkg/report"
+)
+
+// bundleReport is a file of reports written by -output-dir
+type bundleReport struct {
+	name    string
+	reports []*report.Report
+}
+
+// writeBundle writes the artifacts of one run to a directory, for -output-dir: the written
+// graph as canonical JSON (graph.json), a file per other format, named after it, and a file
+// per report in the given style. Formats with an HTML page write the page unless the config
+// sets htmlPage, drawing the sample of the config, if any (see previewGraph). Plugin files
+// are named after their command, see bundleFileName.
+func writeBundle(dir string, output *graph.DependencyGraph, formats []string, config format.Config, reports []bundleReport, style string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	pageConfig := maps.Clone(config)
+	if !pageConfig.Has("htmlPage") {
+		pageConfig["htmlPage"] = true
+	}
+	if err := writeBundleFile(dir, "graph.json", func(f *os.File) error {
+		return format.GetFormatWriter("json").Write(f, output, config)
+	}); err != nil {
+		return err
+	}
+	var preview *graph.DependencyGraph
+	written := map[string]string{"graph.json": "json"} // Format writing each file
+	for _, name := range formats {
+		if name == "json" {
+			continue
+		}
+		fileName := bundleFileName(name, pageConfig)
+		if previous, exists := written[fileName]; exists {
+			return fmt.Errorf("formats %s and %s both write %s", previous, name, fileName)
+		}
+		written[fileName] = name
+		g := output
+		if format.FileExtension(name, pageConfig) == "html" {
+			if preview == nil {
+				preview = previewGraph(output, config)
+			}
+			g = preview
+		}
+		if err := writeBundleFile(dir, fileName, func(f *os.File) error {
+			return format.GetFormatWriter(name).Write(f, g, pageConfig)
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range reports {
+		fileName := r.name + "." + reportExtension(style)
+		if err := writeBundleFile(dir, fileName, func(f *os.File) error {
+			return report.RenderAll(f, r.reports, style)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bundleFileName returns the name of the file of a format in the bundle: the format name, or
+// exec-<command name> for plugins, e.g. exec-my-writer.out for exec:./bin/my-writer --flag
+func bundleFileName(name string, config format.Config) string {
+	base := name
+	if command, ok := strings.CutPrefix(name, "exec:"); ok {
+		base = "exec"
+		if fields := strings.Fields(command); len(fields) > 0 {
+			base += "-" + filepath.Base(fields[0])
+		}
+	}
+	return base + "." + format.FileExtension(name, config)
+}
+
+// writeBundleFile creates a file of the bundle and writes it
+func writeBundleFile(dir, name string, write func(f *os.File) error) error {
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	slog.Debug("Wrote bundle file", "path", path)
+	return nil
+}
+
+// reportExtension returns the file extension of a report style
+func reportExtension(style string) string {
+	switch style {
+	case "json":
+		return "json"
+	case "markdown":
+		return "md"
+	}
+	return "txt"
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-depmap/pkg/analyzer"
+	"go-depmap/pkg/server"
+)
+
+// runDaemon analyzes a project and keeps its graph in memory, answering the queries of
+// depmap query over a unix socket until interrupted. The project is analyzed again when its
+// files change. The socket also serves the endpoints of depmap serve:
+//
+//	depmap daemon ./...                               then: depmap query dependents -to pkg/db::Open
+//	curl --unix-socket <socket> http://depmap/api/cycles
+func runDaemon(args []string) {
+	fs := flag.NewFlagSet("depmap daemon", flag.ExitOnError)
+	load := addLoadFlags(fs)
+	include := addIncludeFlags(fs)
+	edgesPtr := fs.String("edges", "all", "Function dependencies to record: all, signature (API surface) or body (implementation)")
+	configPtr := fs.String("config", "{}", "JSON configuration object, for the subgraph scoring options")
+	socketPtr := fs.String("socket", "", "Unix socket to listen on (default: one derived from the project and analysis options, which depmap query finds)")
+	watchIntervalPtr := fs.Duration("watch-interval", time.Second, "How often to check the Go files for changes")
+	logging := addLogFlags(fs)
+	parseLoadFlags(fs, load, args)
+	logging.setup()
+
+	config := parseConfig(*configPtr)
+	options := include.apply(analyzer.Options{
+		Scoring: config.ScoringOptions(),
+		Scope:   parseEdgeScope(*edgesPtr),
+	})
+	socket := *socketPtr
+	if socket == "" {
+		var err error
+		if socket, err = daemonSocket(*load, options); err != nil {
+			fatalf("Failed to create the socket directory: %v", err)
+		}
+	}
+
+	// A socket nobody listens on is left over by a daemon that did not stop cleanly
+	if conn, err := net.Dial("unix", socket); err == nil {
+		_ = conn.Close()
+		fatalf("A daemon is already running on %s", socket)
+	}
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fatalf("Failed to remove the stale socket %s: %v", socket, err)
+	}
+
+	watcher, err := newFileWatcher(load.Source)
+	if err != nil {
+		fatalf("Failed to watch %s: %v", load.Source, err)
+	}
+	project, graph := loadProject(*load, options)
+	graph.UpdateMetadataCounts()
+
+	srv, err := server.New(graph)
+	if err != nil {
+		fatalf("Failed to create server: %v", err)
+	}
+	listener, err := listenPrivate(socket)
+	if err != nil {
+		fatalf("Failed to listen on %s: %v", socket, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", srv.Handler())
+	mux.HandleFunc("POST /query", func(w http.ResponseWriter, r *http.Request) {
+		serveQuery(srv, w, r)
+	})
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	// Stop accepting queries on interrupt, and let the ones in progress finish; closing the
+	// listener removes the socket
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("Failed to shut down cleanly", "error", err)
+		}
+	}()
+	go watchProject(ctx, watcher, *watchIntervalPtr, srv, project)
+
+	slog.Info("Daemon ready", "socket", socket, "nodes", len(graph.Nodes), "edges", graph.CountEdges())
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		fatalf("Failed to serve: %v", err)
+	}
+	slog.Info("Daemon stopped")
+}
+
+// serveQuery answers a query posted by depmap query on the graph being served. The result is
+// written as is, and errors as plain text with a 422 status.
+func serveQuery(srv *server.Server, w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	var request queryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var result bytes.Buffer
+	if err := request.answer(srv.Graph(), &result); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if _, err := result.WriteTo(w); err != nil {
+		slog.Warn("Failed to write query result", "error", err)
+	}
+	slog.Info("Answered query", "query", request.Query, "symbol", request.Symbol, "duration", time.Since(start).Round(time.Millisecond))
+}
+
+// askDaemon has the daemon listening on a socket answer a query, and copies the result to w.
+// It reports false when no daemon is running there, for the query to be answered locally. A
+// socket of another user is refused: its results could be forged.
+func askDaemon(socket string, request queryRequest, w io.Writer) (bool, error) {
+	info, err := os.Lstat(socket)
+	if err != nil {
+		return false, nil
+	}
+	if info.Mode()&os.ModeSocket == 0 || !ownedByUser(info) {
+		return false, fmt.Errorf("%s is not a socket of the current user, refusing to query it", socket)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return false, err
+	}
+	response, err := client.Post("http://depmap/query", "application/json", bytes.NewReader(body))
+	if err != nil {
+		slog.Debug("Daemon not reachable, loading the project", "socket", socket, "error", err)
+		return false, nil
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(response.Body)
+		return true, errors.New(strings.TrimSpace(string(message)))
+	}
+	slog.Debug("Query answered by the daemon", "socket", socket)
+	if _, err := io.Copy(w, response.Body); err != nil {
+		return true, fmt.Errorf("failed to read query result: %w", err)
+	}
+	return true, nil
+}
+
+// daemonSocket returns the default socket of the daemon analyzing a project: a path in the
+// socket directory derived from the project directory, the package patterns and the options
+// that change the graph, so that queries only reach a daemon whose graph they would have
+// loaded themselves
+func daemonSocket(load loadOptions, options analyzer.Options) (string, error) {
+	source, err := filepath.Abs(load.Source)
+	if err != nil {
+		source = load.Source
+	}
+	key, _ := json.Marshal(struct {
+		Source           string
+		Patterns         []string
+		Tests            bool
+		ContinueOnError  bool
+		Tags             string
+		GOOS             string
+		GOARCH           string
+		Scope            analyzer.EdgeScope
+		External         analyzer.ExternalOptions
+		Stdlib           analyzer.StdlibOptions
+		Mocks            analyzer.MockOptions
+		Globals          bool
+		ExcludeGenerated bool
+		Occurrences      bool
+	}{
+		source, load.patterns(), load.Tests, load.ContinueOnError, load.Tags, load.GOOS, load.GOARCH,
+		options.Scope, options.External, options.Stdlib, options.Mocks, options.Globals, options.ExcludeGenerated,
+		options.Occurrences,
+	})
+	sum := sha256.Sum256(key)
+	dir, err := socketDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("depmap-%x.sock", sum[:6])), nil
+}
+
+// socketDir returns the directory of the default daemon sockets, in the runtime directory of
+// the user ($XDG_RUNTIME_DIR) or else their cache directory, rather than the shared temporary
+// directory where another user could create a socket first. It is created for its user only.
+func socketDir() (string, error) {
+	base := os.Getenv("XDG_RUNTIME_DIR")
+	if base == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		base = cache
+	}
+	dir := filepath.Join(base, "depmap")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
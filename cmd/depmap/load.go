@@ -0,0 +1,532 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go-depmap/pkg/analyzer"
+	"go-depmap/pkg/check"
+	"go-depmap/pkg/format"
+	"go-depmap/pkg/graph"
+	"go-depmap/pkg/overlay"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadOptions selects the packages to load, shared by the analyze, stats and query commands
+type loadOptions struct {
+	Source          string   // Directory of the project
+	Patterns        []string // Package patterns relative to Source, ./... when empty
+	Tests           bool     // Include _test.go files
+	ContinueOnError bool     // Analyze the healthy packages when some have errors
+	Tags            string   // Comma-separated build tags
+	GOOS            string   // Target operating system, empty for the host's
+	GOARCH          string   // Target architecture, empty for the host's
+	Progress        string   // Progress mode: auto, bar, log or off
+
+	Flags *flag.FlagSet // Parsed command line, recorded in the graph metadata
+}
+
+// addLoadFlags registers the flags of loadOptions on a command's flag set. The package
+// patterns are the positional arguments, set with parseLoadFlags.
+func addLoadFlags(fs *flag.FlagSet) *loadOptions {
+	opts := &loadOptions{}
+	fs.StringVar(&opts.Source, "source", ".", "The directory of the Go project to analyze")
+	fs.BoolVar(&opts.Tests, "tests", false, "Include _test.go files in the analysis")
+	fs.BoolVar(&opts.ContinueOnError, "continue-on-error", false, "Analyze the packages that load cleanly and record the load errors of the others in the output, instead of aborting")
+	fs.StringVar(&opts.Tags, "tags", "", "Comma-separated build tags to satisfy, as for go build -tags")
+	fs.StringVar(&opts.GOOS, "goos", "", "Target operating system whose files are analyzed (default: the host's, or $GOOS)")
+	fs.StringVar(&opts.GOARCH, "goarch", "", "Target architecture whose files are analyzed (default: the host's, or $GOARCH)")
+	fs.StringVar(&opts.Progress, "progress", progressAuto, "Progress reporting on stderr: auto (a bar on terminals, periodic log lines otherwise), bar, log or off")
+	return opts
+}
+
+// includeFlags select the symbols that become nodes beyond the project's hand-written
+// code, shared by the analyze, stats and query commands
+type includeFlags struct {
+	external         bool
+	externalPackages string
+	stdlib           bool
+	stdlibPackages   string
+	excludeGenerated bool
+	mockPatterns     string
+	excludeMocks     bool
+	globals          bool
+	occurrences      bool
+}
+
+// addIncludeFlags registers the flags of includeFlags on a command's flag set
+func addIncludeFlags(fs *flag.FlagSet) *includeFlags {
+	f := &includeFlags{}
+	fs.BoolVar(&f.external, "include-external", false, "Add nodes for the third-party (non-module, non-stdlib) functions and types used by the project")
+	fs.StringVar(&f.externalPackages, "external-packages", "", "Comma-separated package patterns limiting -include-external, e.g. github.com/aws/...")
+	fs.BoolVar(&f.stdlib, "include-stdlib", false, "Add a node for every standard library package used by the project, with edges from the functions using it")
+	fs.StringVar(&f.stdlibPackages, "stdlib-packages", "", "Comma-separated package patterns limiting -include-stdlib, e.g. unsafe,reflect,os/exec,net/...")
+	fs.BoolVar(&f.excludeGenerated, "exclude-generated", false, "Leave out the symbols of generated files (\"// Code generated ... DO NOT EDIT.\"); otherwise they are tagged \"generated\"")
+	fs.StringVar(&f.mockPatterns, "mock-patterns", strings.Join(analyzer.DefaultMockPatterns, ","), "Comma-separated patterns of the files holding mocks, tagged \"mock\": file names like *_mock.go, or package directories ending in / like mocks/")
+	fs.BoolVar(&f.excludeMocks, "exclude-mocks", false, "Leave out the symbols of the files matching -mock-patterns")
+	fs.BoolVar(&f.globals, "globals", false, "Add nodes for package-level variables, with reads and writes edges from the functions using them")
+	fs.BoolVar(&f.occurrences, "occurrences", false, "Record the positions of the definitions of the symbols and of their uses, for the lsif format")
+	return f
+}
+
+// apply sets the external, standard library, generated file, mock, globals and occurrences
+// options; package patterns imply their flag
+func (f *includeFlags) apply(options analyzer.Options) analyzer.Options {
+	options.External = analyzer.ExternalOptions{
+		Enabled:  f.external || f.externalPackages != "",
+		Packages: parseList(f.externalPackages),
+	}
+	options.Stdlib = analyzer.StdlibOptions{
+		Enabled:  f.stdlib || f.stdlibPackages != "",
+		Packages: parseList(f.stdlibPackages),
+	}
+	options.ExcludeGenerated = f.excludeGenerated
+	options.Globals = f.globals
+	options.Occurrences = f.occurrences
+	options.Mocks = analyzer.MockOptions{
+		Patterns: parseList(f.mockPatterns),
+		Exclude:  f.excludeMocks,
+	}
+	return options
+}
+
+// parseLoadFlags parses the command line and takes the remaining arguments as package patterns
+func parseLoadFlags(fs *flag.FlagSet, opts *loadOptions, args []string) {
+	_ = fs.Parse(args)
+	opts.Patterns = fs.Args()
+	opts.Flags = fs
+}
+
+// patterns returns the package patterns to load, the whole module by default
+func (o loadOptions) patterns() []string {
+	if len(o.Patterns) == 0 {
+		return []string{"./..."}
+	}
+	return o.Patterns
+}
+
+// packagesConfig returns the go/packages configuration for the load options. Build tags
+// become build flags, and the target platform overrides GOOS and GOARCH in the environment.
+func (o loadOptions) packagesConfig() *packages.Config {
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps | packages.NeedModule,
+		Dir:   o.Source,
+		Tests: o.Tests,
+	}
+	if o.Tags != "" {
+		cfg.BuildFlags = []string{"-tags=" + o.Tags}
+	}
+	if o.GOOS != "" || o.GOARCH != "" {
+		cfg.Env = os.Environ()
+		if o.GOOS != "" {
+			cfg.Env = append(cfg.Env, "GOOS="+o.GOOS)
+		}
+		if o.GOARCH != "" {
+			cfg.Env = append(cfg.Env, "GOARCH="+o.GOARCH)
+		}
+	}
+	return cfg
+}
+
+// loadGraph loads the Go packages of the project, optionally including their _test.go
+// files, and analyzes them. Packages with errors abort the run, unless ContinueOnError is
+// set: then only the healthy packages are analyzed, and the errors are logged and recorded
+// in the graph. The graph carries the metadata of the run. Progress is reported as
+// configured by the Progress load option.
+func loadGraph(load loadOptions, options analyzer.Options) *graph.DependencyGraph {
+	_, g := loadProject(load, options)
+	return g
+}
+
+// loadProject is loadGraph also returning the analyzer, for the commands updating the graph
+// as the project changes (see watchProject)
+func loadProject(load loadOptions, options analyzer.Options) (*analyzer.Analyzer, *graph.DependencyGraph) {
+	start := time.Now()
+	slog.Info("Analyzing", "patterns", strings.Join(load.patterns(), " "), "source", load.Source)
+	if load.GOOS != "" || load.GOARCH != "" || load.Tags != "" {
+		slog.Info("Build context", "goos", load.GOOS, "goarch", load.GOARCH, "tags", load.Tags)
+	}
+
+	progress := newProgressReporter(load.Progress)
+	defer progress.close()
+	if progress != nil {
+		options.Progress = progress.update
+	}
+	options.Load = load.reload
+
+	// Load the packages using go/packages
+	progress.startPhase(phaseLoad)
+	endLoad := timings.track("load")
+	pkgs, err := packages.Load(load.packagesConfig(), load.patterns()...)
+	endLoad()
+	progress.endPhase()
+	if err != nil {
+		fatalf("Failed to load packages: %v", err)
+	}
+	files := 0
+	for _, pkg := range pkgs {
+		files += len(pkg.Syntax)
+	}
+	slog.Info("Loaded packages", "packages", len(pkgs), "files", files, "duration", time.Since(start).Round(time.Millisecond))
+
+	if !load.ContinueOnError {
+		if packages.PrintErrors(pkgs) > 0 {
+			fatalf("Packages contained errors (use -continue-on-error to analyze the healthy packages)")
+		}
+		a := analyzer.NewWithOptions(pkgs, options)
+		g := a.Analyze()
+		addAnalyzerTimings(a)
+		g.Metadata = newMetadata(load, pkgs, start)
+		return a, g
+	}
+
+	healthy, loadErrors := analyzer.SplitBroken(pkgs)
+	logLoadErrors(loadErrors, len(pkgs)-len(healthy))
+
+	// Analyze the packages
+	a := analyzer.NewWithOptions(healthy, options)
+	g := a.Analyze()
+	addAnalyzerTimings(a)
+	g.LoadErrors = loadErrors
+	g.Metadata = newMetadata(load, pkgs, start)
+	return a, g
+}
+
+// reload loads packages again for analyzer.Update. Packages with errors fail the update, as
+// the previous graph is better than one missing them; with ContinueOnError, they are left out.
+func (o loadOptions) reload(patterns []string) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(o.packagesConfig(), patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	if o.ContinueOnError {
+		healthy, loadErrors := analyzer.SplitBroken(pkgs)
+		logLoadErrors(loadErrors, len(pkgs)-len(healthy))
+		return healthy, nil
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("packages contained errors (use -continue-on-error to analyze the healthy packages)")
+	}
+	return pkgs, nil
+}
+
+// addAnalyzerTimings adds the durations of the analysis phases to the run's timings
+func addAnalyzerTimings(a *analyzer.Analyzer) {
+	for _, timing := range a.Timings() {
+		timings.add(timing.Phase, timing.Duration)
+	}
+}
+
+// logLoadErrors prints the load errors and a summary of the packages skipped because of them
+func logLoadErrors(loadErrors []graph.LoadError, skipped int) {
+	if len(loadErrors) == 0 {
+		return
+	}
+	for _, loadError := range loadErrors {
+		attrs := []any{"package", loadError.Package, "kind", loadError.Kind}
+		if loadError.Position != "" {
+			attrs = append(attrs, "position", loadError.Position)
+		}
+		slog.Warn(loadError.Message, attrs...)
+	}
+	slog.Warn("Continuing past load errors", "errors", len(loadErrors), "skipped_packages", skipped)
+}
+
+// applyGitChurn attaches the git history of the module containing sourceDir to the graph
+func applyGitChurn(g *graph.DependencyGraph, sourceDir, since string) {
+	defer timings.track("overlays")()
+	dir, err := moduleDir(sourceDir)
+	if err != nil {
+		fatalf("Failed to locate the module root: %v", err)
+	}
+	churn, err := overlay.GitChurn(dir, since)
+	if err != nil {
+		fatalf("Failed to compute git churn: %v", err)
+	}
+	overlay.ApplyChurn(g, churn)
+	slog.Info("Computed git churn", "files", len(churn))
+}
+
+// applyCodeOwners attaches the owners listed in a CODEOWNERS file to the graph
+func applyCodeOwners(g *graph.DependencyGraph, sourceDir, filename string) {
+	defer timings.track("overlays")()
+	codeOwners, err := overlay.LoadCodeOwners(filename)
+	if err != nil {
+		fatalf("Failed to read CODEOWNERS: %v", err)
+	}
+	dir, err := moduleDir(sourceDir)
+	if err != nil {
+		fatalf("Failed to locate the module root: %v", err)
+	}
+	repoRoot, err := filepath.Abs(overlay.CodeOwnersRoot(filename))
+	if err != nil {
+		fatalf("Failed to locate the repository root: %v", err)
+	}
+	if err := overlay.ApplyOwners(g, codeOwners, repoRoot, dir); err != nil {
+		fatalf("Failed to apply CODEOWNERS: %v", err)
+	}
+}
+
+// applyTraces maps the spans of a trace export onto the graph
+func applyTraces(g *graph.DependencyGraph, filename string) {
+	defer timings.track("overlays")()
+	spans, err := overlay.LoadTraces(filename)
+	if err != nil {
+		fatalf("Failed to read traces: %v", err)
+	}
+	stats := overlay.ApplyTraces(g, spans)
+	slog.Info("Mapped trace spans onto the graph", "matched", stats.Matched, "spans", stats.Spans, "unmatched", stats.Unmatched)
+}
+
+// moduleDir returns the root directory of the module containing sourceDir, which node paths
+// are relative to
+func moduleDir(sourceDir string) (string, error) {
+	cmd := exec.Command("go", "env", "GOMOD")
+	cmd.Dir = sourceDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	gomod := strings.TrimSpace(string(out))
+	if gomod == "" || gomod == os.DevNull {
+		return "", fmt.Errorf("%s is not inside a module", sourceDir)
+	}
+	return filepath.Dir(gomod), nil
+}
+
+// parseEdgeScope validates the -edges flag
+func parseEdgeScope(scope string) analyzer.EdgeScope {
+	edgeScope := analyzer.EdgeScope(scope)
+	if !edgeScope.IsValid() {
+		fatalf("Unknown edge scope: %s (available: all, signature, body)", scope)
+	}
+	return edgeScope
+}
+
+// parseConfig parses the JSON formatter configuration given on the command line
+func parseConfig(configJSON string) format.Config {
+	var configMap map[string]any
+	if err := json.Unmarshal([]byte(configJSON), &configMap); err != nil {
+		fatalf("Failed to parse config JSON: %v", err)
+	}
+	config := format.Config(configMap)
+
+	if strategy := config.ScoringOptions().Strategy; !strategy.IsValid() {
+		fatalf("Unknown scoring strategy: %s", strategy)
+	}
+
+	return config
+}
+
+// applyCheckFlags stores the threshold flags in the config, so that the junit and gh-summary
+// formats check the same rules. Flags left at their zero value keep the config's setting.
+func applyCheckFlags(config format.Config, failOnCycles bool, maxFanIn, maxPackageDeps int, noRecursion string) {
+	if failOnCycles {
+		config["failOnCycles"] = true
+	}
+	if maxFanIn > 0 {
+		config["maxFanIn"] = maxFanIn
+	}
+	if maxPackageDeps > 0 {
+		config["maxPackageDeps"] = maxPackageDeps
+	}
+	if noRecursion != "" {
+		config["noRecursion"] = noRecursion
+	}
+}
+
+// applyOutputFlags stores the output filter and layout flags in the config, so that they
+// combine with the settings there. Flags left at their zero value keep the config's setting.
+func applyOutputFlags(config format.Config, selectExpr string, topSubgraphs int, pruneIsolated, bundleEdges bool, layout, layoutFrom string) {
+	if selectExpr != "" {
+		config["select"] = selectExpr
+	}
+	if layout != "" {
+		config["layout"] = layout
+	}
+	if layoutFrom != "" {
+		config["layoutFrom"] = layoutFrom
+	}
+	if pruneIsolated {
+		config["pruneIsolated"] = true
+	}
+	if bundleEdges {
+		config["bundleEdges"] = true
+	}
+	if topSubgraphs > 0 {
+		config["topSubgraphs"] = topSubgraphs
+	}
+}
+
+// outputSelection compiles the select filter expression of the config, or returns nil
+// without one
+func outputSelection(config format.Config) (*graph.Expr, error) {
+	source := config.GetString("select", "")
+	if source == "" {
+		return nil, nil
+	}
+	return graph.ParseExpr(source)
+}
+
+// filterOutput applies the output filters of the config to a graph about to be written. The
+// selection comes first, and has its subgraphs computed anew.
+func filterOutput(g *graph.DependencyGraph, config format.Config) (*graph.DependencyGraph, error) {
+	expr, err := outputSelection(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid select expression: %w", err)
+	}
+	if expr != nil {
+		nodes := len(g.Nodes)
+		g = g.Select(expr)
+		g.ComputeSubgraphsWithScoring(config.ScoringOptions())
+		slog.Info("Selected nodes", "expr", expr.String(), "kept", len(g.Nodes), "nodes", nodes)
+	}
+	if config.GetBool("pruneIsolated", false) {
+		nodes := len(g.Nodes)
+		g = g.PruneIsolated()
+		slog.Info("Pruned isolated nodes", "pruned", nodes-len(g.Nodes))
+	}
+	if n := config.GetInt("topSubgraphs", 0); n > 0 && len(g.Subgraphs) > n {
+		slog.Info("Keeping the highest-scoring subgraphs", "kept", n, "subgraphs", len(g.Subgraphs))
+		g = g.TopSubgraphs(n)
+	}
+	if config.GetBool("bundleEdges", false) {
+		g = g.BundlePackageEdges()
+		g.ComputeSubgraphsWithScoring(config.ScoringOptions())
+	}
+	return g, nil
+}
+
+// checkLayout fails on an unknown layout in the config, before the analysis rather than
+// once the graph is about to be written
+func checkLayout(config format.Config) {
+	if layout := config.GetString("layout", ""); layout != "" && !slices.Contains(graph.LayoutAlgorithms, layout) {
+		fatalf("Unknown layout: %s (available: %s)", layout, strings.Join(graph.LayoutAlgorithms, ", "))
+	}
+}
+
+// checkSample fails on a sample fraction outside (0, 1] or a negative maxEdges in the config,
+// before the analysis rather than once the graph is about to be written
+func checkSample(config format.Config) {
+	if sample := config.GetFloat("sample", 1); sample <= 0 || sample > 1 {
+		fatalf("Invalid sample: %v (expected a fraction of the edges in (0, 1])", sample)
+	}
+	if maxEdges := config.GetInt("maxEdges", 0); maxEdges < 0 {
+		fatalf("Invalid maxEdges: %d (expected 0 for no limit, or a number of edges)", maxEdges)
+	}
+}
+
+// checkFormats exits when a name of -format selects no writer, before the analysis, rather
+// than writing JSON under a mistyped name
+func checkFormats(formats []string) {
+	for _, name := range formats {
+		if !format.IsFormat(name) {
+			fatalf("Unknown format: %s (see -help for the formats)", name)
+		}
+	}
+}
+
+// previewGraph returns the graph the HTML pages draw: a sample of the written graph (see
+// graph.Sample) of the sample fraction of the config, or of at most its maxEdges edges, so
+// that the page of a huge graph opens rather than freezing the tab. Other outputs, json
+// included, keep the full graph.
+func previewGraph(g *graph.DependencyGraph, config format.Config) *graph.DependencyGraph {
+	fraction := config.GetFloat("sample", 1)
+	maxEdges := config.GetInt("maxEdges", 0)
+	if edges := g.CountEdges(); maxEdges > 0 && edges > maxEdges {
+		fraction = min(fraction, float64(maxEdges)/float64(edges))
+	}
+	sampled, sampling := g.Sample(fraction, config.ScoringOptions())
+	// The sample has about the fraction of the edges; a few more shrink it below maxEdges
+	for sampling != nil && maxEdges > 0 && sampling.KeptEdges > maxEdges {
+		fraction *= float64(maxEdges) / float64(sampling.KeptEdges)
+		sampled, sampling = g.Sample(fraction, config.ScoringOptions())
+	}
+	if sampling != nil {
+		slog.Warn("Graph sampled for the HTML pages", "reason", sampling.String())
+		sampled.UpdateMetadataCounts()
+	}
+	return sampled
+}
+
+// layoutOutput computes the positions of the nodes of a graph about to be written with the
+// layout of the config, if any (see graph.Layout). The nodes of the graph read from the
+// layoutFrom file, the json output of a previous run, keep their positions there; a missing
+// file is the first run, laid out from scratch. layoutFrom alone lays out with force.
+func layoutOutput(g *graph.DependencyGraph, config format.Config) error {
+	layout := config.GetString("layout", "")
+	from := config.GetString("layoutFrom", "")
+	if layout == "" && from == "" {
+		return nil
+	}
+	if layout == "" {
+		layout = graph.LayoutForce
+	}
+
+	var previous map[string]graph.Position
+	if from != "" {
+		previousGraph, err := readGraph(from)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			slog.Info("No previous layout, laying out from scratch", "file", from)
+		case err != nil:
+			return fmt.Errorf("failed to read the previous layout: %w", err)
+		default:
+			previous = previousGraph.Positions()
+		}
+	}
+
+	start := time.Now()
+	if err := g.Layout(layout, previous); err != nil {
+		return err
+	}
+	kept := 0
+	for nodeID := range g.Nodes {
+		if _, exists := previous[nodeID]; exists {
+			kept++
+		}
+	}
+	slog.Info("Laid out the graph", "layout", layout, "nodes", len(g.Nodes), "kept", kept, "duration", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// enforceRules checks the graph against the enforced rules and exits with status 1 if any
+// has violations, after the output has been written
+func enforceRules(g *graph.DependencyGraph, opts check.Options) {
+	results := check.Run(g, check.Rules(opts))
+	if !check.AnyFailed(results) {
+		return
+	}
+	for _, result := range results {
+		if !result.Failed() {
+			continue
+		}
+		slog.Error("Rule failed", "rule", result.Rule, "description", result.Description)
+		for _, v := range result.Violations {
+			slog.Error(v.Message, "rule", result.Rule, "subject", v.Subject)
+		}
+	}
+	os.Exit(1)
+}
+
+// parseList splits a comma-separated flag value, ignoring empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
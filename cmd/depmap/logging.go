@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logFlags holds the logging flags shared by all commands. Logs always go to stderr, so that
+// stdout only carries the requested output.
+type logFlags struct {
+	quiet   bool
+	verbose bool
+	level   string
+	format  string
+}
+
+// addLogFlags registers the logging flags on a command's flag set
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+	f := &logFlags{}
+	fs.BoolVar(&f.quiet, "quiet", false, "Only log errors (same as -log-level error)")
+	fs.BoolVar(&f.verbose, "verbose", false, "Also log debug messages, such as the analysis phases (same as -log-level debug)")
+	fs.StringVar(&f.level, "log-level", "info", "Minimum level of the logged messages: debug, info, warn or error")
+	fs.StringVar(&f.format, "log-format", "text", "Log format: text, or json for one JSON object per line")
+	return f
+}
+
+// setup installs the default logger described by the flags. The standard log package
+// writes through it too, at the info level.
+func (f *logFlags) setup() {
+	if f.quiet && f.verbose {
+		fatalf("-quiet and -verbose are mutually exclusive")
+	}
+
+	var level slog.Level
+	switch {
+	case f.quiet:
+		level = slog.LevelError
+	case f.verbose:
+		level = slog.LevelDebug
+	default:
+		if err := level.UnmarshalText([]byte(f.level)); err != nil {
+			fatalf("Unknown log level: %s (available: debug, info, warn, error)", f.level)
+		}
+	}
+
+	handler := newLogHandler(os.Stderr, f.format, level)
+	if handler == nil {
+		fatalf("Unknown log format: %s (available: text, json)", f.format)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// newLogHandler returns the slog handler of a log format, or nil for an unknown format
+func newLogHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	options := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.NewTextHandler(w, options)
+	case "json":
+		return slog.NewJSONHandler(w, options)
+	}
+	return nil
+}
+
+// fatalf logs an error and exits with status 1. Unlike log.Fatalf, the message is logged at
+// the error level, so -quiet does not hide it.
+func fatalf(format string, args ...any) {
+	slog.Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
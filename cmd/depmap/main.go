@@ -3,65 +3,223 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
-	"log"
+	"log/slog"
 	"os"
 	"reflect"
+	"strings"
 
 	"go-depmap/pkg/analyzer"
 	"go-depmap/pkg/format"
-
-	"golang.org/x/tools/go/packages"
+	"go-depmap/pkg/graph"
+	"go-depmap/pkg/report"
 )
 
 func main() {
+	// Subcommands; without one, the classic flag-only invocation analyzes and formats the graph
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "stats":
+			runStats(os.Args[2:])
+			return
+		case "query":
+			runQuery(os.Args[2:])
+			return
+		case "analyze":
+			runAnalyze(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "mcp":
+			runMCP(os.Args[2:])
+			return
+		case "daemon":
+			runDaemon(os.Args[2:])
+			return
+		case "merge":
+			runMerge(os.Args[2:])
+			return
+		case "version":
+			runVersion()
+			return
+		}
+	}
+	runAnalyze(os.Args[1:])
+}
+
+// runAnalyze analyzes a project and writes the graph in the requested format
+func runAnalyze(args []string) {
+	fs := flag.NewFlagSet("depmap", flag.ExitOnError)
+
 	// CLI Flags
-	sourcePtr := flag.String("source", ".", "The directory of the Go project to analyze")
-	formatPtr := flag.String("format", "json", "Output format: json, d3js")
-	configPtr := flag.String("config", "{}", "JSON configuration object for the formatter (e.g., {\"pretty\":true,\"groupByPackage\":true})")
-	flag.Parse()
+	load := addLoadFlags(fs)
+	edgesPtr := fs.String("edges", "all", "Function dependencies to record: all, signature (API surface) or body (implementation)")
+	formatPtr := fs.String("format", "json", "Output format (with -output-dir, a comma-separated list): json, d3js, cosmo, antvg6, cytoscape, echarts, visjs, sigma, deckgl, lod, 3d, dgml, graphml, dot, tgf, pajek, parquet, structurizr, backstage, grafana, lsif, matrix, dashboard, gh-summary, junit, or exec:<command> to pipe JSON to an external writer")
+	configPtr := fs.String("config", "{}", "JSON configuration object for the formatter (e.g., {\"pretty\":true,\"groupByPackage\":true})")
+	reportPtr := fs.String("report", "", "Write an analysis report instead of the graph: "+strings.Join(report.Names(), ", "))
+	reportFormatPtr := fs.String("report-format", "table", "Report output style: table, json, markdown")
+	outputDirPtr := fs.String("output-dir", "", "Write a bundle to this directory instead of STDOUT: the graph as JSON, a file per -format (HTML pages for the visualizations), the stats and cycles reports, and the -report")
+	chainsPtr := fs.Int("chains", 1, "Number of chains for the chain report (0 = one per root)")
+	fullChainPtr := fs.Bool("full-chain", false, "List every node of each chain in the chain report")
+	snippetLinesPtr := fs.Int("snippet-lines", 0, "Embed the first N lines of each function in its node (0 = none, -1 = the full function)")
+	snippetBytesPtr := fs.Int("snippet-bytes", 4096, "Size cap of an embedded snippet in bytes (0 = no cap)")
+	include := addIncludeFlags(fs)
+	gitChurnPtr := fs.Bool("git-churn", false, "Attach the number of commits that changed each node's file, from git log")
+	churnSincePtr := fs.String("churn-since", "", "Only count commits newer than this git date for -git-churn, e.g. \"6 months ago\"")
+	codeOwnersPtr := fs.String("codeowners", "", "CODEOWNERS file whose owners are attached to the nodes, e.g. .github/CODEOWNERS")
+	tracesPtr := fs.String("traces", "", "OTLP/JSON or Jaeger JSON trace export whose spans are mapped onto the nodes")
+	failOnCyclesPtr := fs.Bool("fail-on-cycles", false, "Exit with status 1 when the graph has dependency cycles")
+	maxFanInPtr := fs.Int("max-fan-in", 0, "Exit with status 1 when a symbol has more dependents than this (0 = no limit)")
+	maxPackageDepsPtr := fs.Int("max-package-deps", 0, "Exit with status 1 when a package depends on more packages than this (0 = no limit)")
+	noRecursionPtr := fs.String("no-recursion", "", "Exit with status 1 when functions in these comma-separated package patterns are recursive, e.g. example.com/app/domain/...")
+	selectPtr := fs.String("select", "", "Filter expression selecting the nodes or edges to write, e.g. 'package=example.com/app/db || package=example.com/app/api'")
+	topSubgraphsPtr := fs.Int("top-subgraphs", 0, "Only write the N highest-scoring subgraphs (0 = all)")
+	pruneIsolatedPtr := fs.Bool("prune-isolated", false, "Leave out the nodes without edges from the written graph")
+	bundleEdgesPtr := fs.Bool("bundle-edges", false, "Replace the edges between the symbols of two packages with one weighted edge between the packages, keeping the edges within packages")
+	maxNodesPtr := fs.Int("max-nodes", 0, "Truncate the written graph to at most this many nodes by collapsing it to packages, or keeping its highest-scoring subgraphs (0 = no limit)")
+	layoutPtr := fs.String("layout", "", "Lay out the written graph, so that the d3js, cosmo, antvg6, sigma, deckgl and lod pages draw it at once instead of simulating it: "+strings.Join(graph.LayoutAlgorithms, ", "))
+	layoutFromPtr := fs.String("layout-from", "", "json output of a previous run whose node positions are kept, laying out only the new nodes next to their neighbors (default layout: force)")
+	rootPtr := fs.String("root", "", "Symbol analyzed by the dominators, reachable and dependents reports, e.g. cmd/server::main")
+	versionPtr := fs.Bool("version", false, "Print the version, commit and build date, and exit")
+	logging := addLogFlags(fs)
+	profile := addProfileFlags(fs)
+	parseLoadFlags(fs, load, args)
+	logging.setup()
 
-	log.Printf("Analyzing project in: %s", *sourcePtr)
+	if *versionPtr {
+		runVersion()
+		return
+	}
+	profile.start()
 
-	// Parse config JSON
-	var configMap map[string]any
-	if err := json.Unmarshal([]byte(*configPtr), &configMap); err != nil {
-		log.Fatalf("Failed to parse config JSON: %v", err)
+	var generateReport report.Generator
+	if *reportPtr != "" {
+		var ok bool
+		if generateReport, ok = report.Get(*reportPtr); !ok {
+			fatalf("Unknown report: %s (available: %s)", *reportPtr, strings.Join(report.Names(), ", "))
+		}
 	}
-	config := format.Config(configMap)
 
-	// Load the packages using go/packages
-	cfg := &packages.Config{
-		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps | packages.NeedModule,
-		Dir:   *sourcePtr,
-		Tests: false, // Set to true if you want to include test files
+	config := parseConfig(*configPtr)
+	applyCheckFlags(config, *failOnCyclesPtr, *maxFanInPtr, *maxPackageDepsPtr, *noRecursionPtr)
+	applyOutputFlags(config, *selectPtr, *topSubgraphsPtr, *pruneIsolatedPtr, *bundleEdgesPtr, *layoutPtr, *layoutFromPtr)
+	if _, err := outputSelection(config); err != nil {
+		fatalf("Invalid select expression: %v", err)
 	}
+	checkLayout(config)
+	checkSample(config)
+	formats := []string{*formatPtr}
+	if *outputDirPtr != "" {
+		formats = strings.Split(*formatPtr, ",")
+	}
+	checkFormats(formats)
+	graph := loadGraph(*load, include.apply(analyzer.Options{
+		Scoring: config.ScoringOptions(),
+		Scope:   parseEdgeScope(*edgesPtr),
+		Snippets: analyzer.SnippetOptions{
+			Lines:    *snippetLinesPtr,
+			MaxBytes: *snippetBytesPtr,
+		},
+	}))
 
-	pkgs, err := packages.Load(cfg, "./...")
-	if err != nil {
-		log.Fatalf("Failed to load packages: %v", err)
+	if *gitChurnPtr {
+		applyGitChurn(graph, load.Source, *churnSincePtr)
+	}
+	if *codeOwnersPtr != "" {
+		applyCodeOwners(graph, load.Source, *codeOwnersPtr)
+	}
+	if *tracesPtr != "" {
+		applyTraces(graph, *tracesPtr)
+	}
+
+	opts := report.DefaultOptions()
+	opts.Chains = *chainsPtr
+	opts.FullChain = *fullChainPtr
+	if *rootPtr != "" {
+		root, err := graph.ResolveNode(*rootPtr)
+		if err != nil {
+			fatalf("Invalid root: %v", err)
+		}
+		opts.Root = root
+	} else if generateReport != nil && report.RequiresRoot(*reportPtr) {
+		fatalf("The %s report requires -root", *reportPtr)
 	}
 
-	if packages.PrintErrors(pkgs) > 0 {
-		log.Fatalf("Packages contained errors")
+	// One run writing every artifact, rather than one run per artifact
+	if *outputDirPtr != "" {
+		endReport := timings.track("report")
+		statsOpts := opts
+		statsOpts.Limit = defaultStatsTop
+		bundleReports := []bundleReport{
+			{"stats", generateAll(graph, statsReports, statsOpts)},
+			{"cycles", generateAll(graph, []report.Generator{report.Cycles}, opts)},
+		}
+		if generateReport != nil {
+			bundleReports = append(bundleReports, bundleReport{*reportPtr, generateAll(graph, []report.Generator{generateReport}, opts)})
+		}
+		endReport()
+
+		endFormat := timings.track("format")
+		output := writtenGraph(graph, config, *maxNodesPtr)
+		if err := writeBundle(*outputDirPtr, output, formats, config, bundleReports, *reportFormatPtr); err != nil {
+			fatalf("Failed to write bundle: %v", err)
+		}
+		endFormat()
+
+		slog.Info("Analysis complete", "nodes", len(graph.Nodes), "edges", graph.CountEdges(), "output", *outputDirPtr)
+		finishRun(profile)
+		enforceRules(graph, config.CheckOptions())
+		return
 	}
 
-	// Analyze the packages
-	a := analyzer.New(pkgs)
-	graph := a.Analyze()
+	// Reports replace the formatter output
+	if generateReport != nil {
+		endReport := timings.track("report")
+		if err := generateReport(graph, opts).Render(os.Stdout, *reportFormatPtr); err != nil {
+			fatalf("Failed to write report: %v", err)
+		}
+		endReport()
+		finishRun(profile)
+		enforceRules(graph, config.CheckOptions())
+		return
+	}
 
 	// Get the appropriate format writer
 	writer := format.GetFormatWriter(*formatPtr)
 	writerType := reflect.TypeOf(writer).Elem().Name()
-	log.Printf("Using writer: %s", writerType)
+	slog.Debug("Using writer", "writer", writerType)
 
 	// Write to STDOUT
-	if err := writer.Write(os.Stdout, graph, config); err != nil {
-		log.Fatalf("Failed to write output: %v", err)
+	output := writtenGraph(graph, config, *maxNodesPtr)
+	if format.FileExtension(*formatPtr, config) == "html" {
+		output = previewGraph(output, config)
+	}
+	endFormat := timings.track("format")
+	if err := writer.Write(os.Stdout, output, config); err != nil {
+		fatalf("Failed to write output: %v", err)
 	}
+	endFormat()
 
-	log.Printf("Analysis complete.")
-	log.Printf("  Nodes: %d", len(graph.Nodes))
-	log.Printf("  Edges: %d", graph.CountEdges())
+	slog.Info("Analysis complete", "nodes", len(graph.Nodes), "edges", graph.CountEdges())
+	finishRun(profile)
+	enforceRules(graph, config.CheckOptions())
+}
+
+// writtenGraph returns the graph to write: the selected part of the analyzed graph, kept
+// within what the visualizations can render. Checks and reports still see the full graph.
+func writtenGraph(g *graph.DependencyGraph, config format.Config, maxNodes int) *graph.DependencyGraph {
+	output, err := filterOutput(g, config)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if limited, truncation := output.Limit(maxNodes, config.ScoringOptions()); truncation != nil {
+		slog.Warn("Graph truncated", "strategy", truncation.Strategy, "reason", truncation.String())
+		output = limited
+	}
+	if err := layoutOutput(output, config); err != nil {
+		fatalf("%v", err)
+	}
+	output.UpdateMetadataCounts()
+	return output
 }
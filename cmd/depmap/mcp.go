@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"time"
+
+	"go-depmap/pkg/analyzer"
+	"go-depmap/pkg/server"
+)
+
+// runMCP analyzes a project and serves its graph to coding assistants over the Model Context
+// Protocol on stdin and stdout, until stdin is closed. It is meant to be started by the
+// assistant, with -watch to follow the edits:
+//
+//	depmap mcp -watch ./...
+func runMCP(args []string) {
+	fs := flag.NewFlagSet("depmap mcp", flag.ExitOnError)
+	load := addLoadFlags(fs)
+	include := addIncludeFlags(fs)
+	edgesPtr := fs.String("edges", "all", "Function dependencies to record: all, signature (API surface) or body (implementation)")
+	configPtr := fs.String("config", "{}", "JSON configuration object, for the subgraph scoring options")
+	watchPtr := fs.Bool("watch", false, "Analyze the project again when its Go files change")
+	watchIntervalPtr := fs.Duration("watch-interval", time.Second, "How often -watch checks the files for changes")
+	logging := addLogFlags(fs)
+	parseLoadFlags(fs, load, args)
+	logging.setup()
+
+	config := parseConfig(*configPtr)
+	options := include.apply(analyzer.Options{
+		Scoring: config.ScoringOptions(),
+		Scope:   parseEdgeScope(*edgesPtr),
+	})
+	var watcher *fileWatcher
+	if *watchPtr {
+		var err error
+		if watcher, err = newFileWatcher(load.Source); err != nil {
+			fatalf("Failed to watch %s: %v", load.Source, err)
+		}
+	}
+	project, graph := loadProject(*load, options)
+	graph.UpdateMetadataCounts()
+
+	srv, err := server.New(graph)
+	if err != nil {
+		fatalf("Failed to create server: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if watcher != nil {
+		go watchProject(ctx, watcher, *watchIntervalPtr, srv, project)
+	}
+
+	slog.Info("Serving MCP on stdio", "nodes", len(graph.Nodes), "edges", graph.CountEdges())
+	if err := srv.ServeMCP(os.Stdin, os.Stdout, readBuildInfo().Version); err != nil {
+		fatalf("Failed to serve: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"go-depmap/pkg/format"
+	"go-depmap/pkg/graph"
+)
+
+// runMerge unions graphs written by the json format, such as those of the repositories of a
+// multi-repo system, into one dependency map written in any output format (see graph.Merge).
+// The nodes are tagged with the repository they come from, and unless -stitch=false the
+// repositories are linked through the libraries they share (see graph.StitchSharedPackages):
+//
+//	depmap merge api.json billing.json web.json -output combined.json
+func runMerge(args []string) {
+	fs := flag.NewFlagSet("depmap merge", flag.ExitOnError)
+	outputPtr := fs.String("output", "", "File to write the merged graph to (default: standard output)")
+	formatPtr := fs.String("format", "json", "Output format of the merged graph, as for the analysis")
+	configPtr := fs.String("config", "{}", "JSON configuration object for the formatter and the subgraph scoring")
+	stitchPtr := fs.Bool("stitch", true, "Replace the external symbols of each library used by several repositories with one package node, linking the repositories")
+	logging := addLogFlags(fs)
+	files := parseInterleaved(fs, args)
+	logging.setup()
+	if len(files) < 2 {
+		fatalf("Usage: depmap merge [flags] <graph.json> <graph.json> [<graph.json>...]")
+	}
+
+	start := time.Now()
+	config := parseConfig(*configPtr)
+	checkLayout(config)
+	checkSample(config)
+	checkFormats([]string{*formatPtr})
+	graphs := make([]*graph.DependencyGraph, 0, len(files))
+	for _, file := range files {
+		g, err := readGraph(file)
+		if err != nil {
+			fatalf("Failed to read %s: %v", file, err)
+		}
+		g.TagRepo(repoName(file, g))
+		slog.Info("Read graph", "file", file, "nodes", len(g.Nodes), "edges", g.CountEdges())
+		graphs = append(graphs, g)
+	}
+
+	merged := graph.Merge(graphs...)
+	if *stitchPtr {
+		merged = merged.StitchSharedPackages()
+	}
+	merged.Metadata = mergedMetadata(fs, graphs, start)
+	merged.ComputeSubgraphsWithScoring(config.ScoringOptions())
+	output, err := filterOutput(merged, config)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if err := layoutOutput(output, config); err != nil {
+		fatalf("%v", err)
+	}
+	output.UpdateMetadataCounts()
+	slog.Info("Merged graphs", "graphs", len(graphs), "nodes", len(output.Nodes), "edges", output.CountEdges())
+
+	if format.FileExtension(*formatPtr, config) == "html" {
+		output = previewGraph(output, config)
+	}
+
+	var w io.WriteCloser = os.Stdout
+	if *outputPtr != "" {
+		if w, err = os.Create(*outputPtr); err != nil {
+			fatalf("Failed to create output: %v", err)
+		}
+	}
+	if err := format.GetFormatWriter(*formatPtr).Write(w, output, config); err != nil {
+		fatalf("Failed to write output: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		fatalf("Failed to write output: %v", err)
+	}
+}
+
+// parseInterleaved parses flags given before, between or after the positional arguments,
+// and returns the positional arguments
+func parseInterleaved(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// readGraph reads a graph written by the json format
+func readGraph(path string) (*graph.DependencyGraph, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - the graphs are explicitly chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	g := graph.NewDependencyGraph()
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// repoName names the repository a graph was analyzed in: its module, or without metadata the
+// name of its file
+func repoName(path string, g *graph.DependencyGraph) string {
+	if g.Metadata != nil && g.Metadata.Module != "" {
+		return g.Metadata.Module
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// mergedMetadata describes a merge: the binary, the flags set on the command line, and as
+// patterns those of the modules merged
+func mergedMetadata(fs *flag.FlagSet, graphs []*graph.DependencyGraph, start time.Time) *graph.Metadata {
+	build := readBuildInfo()
+	metadata := &graph.Metadata{
+		Tool:      "go-depmap",
+		Version:   build.Version,
+		Commit:    build.Commit,
+		BuildDate: build.Date,
+		GoVersion: runtime.Version(),
+		Timestamp: start.UTC().Truncate(time.Second),
+	}
+	for _, g := range graphs {
+		if g.Metadata != nil && g.Metadata.Module != "" && !slices.Contains(metadata.Patterns, g.Metadata.Module+"/...") {
+			metadata.Patterns = append(metadata.Patterns, g.Metadata.Module+"/...")
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if metadata.Flags == nil {
+			metadata.Flags = make(map[string]string)
+		}
+		metadata.Flags[f.Name] = f.Value.String()
+	})
+	return metadata
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"runtime"
+	"time"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// newMetadata describes an analysis started at the given time: the binary, the analyzed
+// module, and the flags set on the command line. The counts are set by UpdateMetadataCounts.
+func newMetadata(load loadOptions, pkgs []*packages.Package, start time.Time) *graph.Metadata {
+	build := readBuildInfo()
+	metadata := &graph.Metadata{
+		Tool:      "go-depmap",
+		Version:   build.Version,
+		Commit:    build.Commit,
+		BuildDate: build.Date,
+		Module:    mainModule(pkgs),
+		GoVersion: runtime.Version(),
+		Timestamp: start.UTC().Truncate(time.Second),
+		Patterns:  load.patterns(),
+	}
+	if load.Flags != nil {
+		load.Flags.Visit(func(f *flag.Flag) {
+			if metadata.Flags == nil {
+				metadata.Flags = make(map[string]string)
+			}
+			metadata.Flags[f.Name] = f.Value.String()
+		})
+	}
+	return metadata
+}
+
+// mainModule returns the path of the main module of the loaded packages, or "" outside of
+// module mode
+func mainModule(pkgs []*packages.Package) string {
+	for _, pkg := range pkgs {
+		if pkg.Module != nil && pkg.Module.Main {
+			return pkg.Module.Path
+		}
+	}
+	return ""
+}
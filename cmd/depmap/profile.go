@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+)
+
+// profileFlags holds the profiling flags shared by all commands, which write the same
+// profiles as go test
+type profileFlags struct {
+	cpu    string
+	memory string
+	trace  string
+
+	cpuFile   *os.File
+	traceFile *os.File
+}
+
+// addProfileFlags registers the profiling flags on a command's flag set
+func addProfileFlags(fs *flag.FlagSet) *profileFlags {
+	f := &profileFlags{}
+	fs.StringVar(&f.cpu, "cpuprofile", "", "Write a CPU profile of the run to this file, for go tool pprof")
+	fs.StringVar(&f.memory, "memprofile", "", "Write a heap profile to this file at the end of the run, for go tool pprof")
+	fs.StringVar(&f.trace, "trace", "", "Write an execution trace of the run to this file, for go tool trace")
+	return f
+}
+
+// start starts the CPU profile and the execution trace, if requested
+func (f *profileFlags) start() {
+	if f.cpu != "" {
+		f.cpuFile = createProfile(f.cpu)
+		if err := pprof.StartCPUProfile(f.cpuFile); err != nil {
+			fatalf("Failed to start the CPU profile: %v", err)
+		}
+	}
+	if f.trace != "" {
+		f.traceFile = createProfile(f.trace)
+		if err := trace.Start(f.traceFile); err != nil {
+			fatalf("Failed to start the execution trace: %v", err)
+		}
+	}
+}
+
+// stop stops the CPU profile and the execution trace, and writes the heap profile
+func (f *profileFlags) stop() {
+	if f.cpuFile != nil {
+		pprof.StopCPUProfile()
+		closeProfile(f.cpuFile)
+	}
+	if f.traceFile != nil {
+		trace.Stop()
+		closeProfile(f.traceFile)
+	}
+	if f.memory != "" {
+		file := createProfile(f.memory)
+		runtime.GC() // Up-to-date statistics
+		if err := pprof.WriteHeapProfile(file); err != nil {
+			fatalf("Failed to write the heap profile: %v", err)
+		}
+		closeProfile(file)
+	}
+}
+
+// createProfile creates a profile output file
+func createProfile(filename string) *os.File {
+	file, err := os.Create(filename) // #nosec G304 - the profile path is explicitly chosen by the user
+	if err != nil {
+		fatalf("Failed to create profile: %v", err)
+	}
+	return file
+}
+
+// closeProfile closes a profile output file
+func closeProfile(file *os.File) {
+	if err := file.Close(); err != nil {
+		fatalf("Failed to write profile: %v", err)
+	}
+	slog.Info("Wrote profile", "file", file.Name())
+}
+
+// phaseTimer records the durations of the phases of a run, for the timing summary
+type phaseTimer struct {
+	phases []string
+	totals map[string]time.Duration
+}
+
+// timings are the phase durations of the current run
+var timings = &phaseTimer{totals: make(map[string]time.Duration)}
+
+// add adds a duration to a phase; phases are summarized in the order they are first added
+func (t *phaseTimer) add(phase string, duration time.Duration) {
+	if _, exists := t.totals[phase]; !exists {
+		t.phases = append(t.phases, phase)
+	}
+	t.totals[phase] += duration
+}
+
+// track starts timing a phase, and returns the function that ends it:
+//
+//	defer timings.track("format")()
+func (t *phaseTimer) track(phase string) func() {
+	start := time.Now()
+	return func() { t.add(phase, time.Since(start)) }
+}
+
+// log logs the phase durations and their total on one line
+func (t *phaseTimer) log() {
+	attrs := make([]any, 0, 2*len(t.phases)+2)
+	var total time.Duration
+	for _, phase := range t.phases {
+		attrs = append(attrs, phase, t.totals[phase].Round(time.Millisecond))
+		total += t.totals[phase]
+	}
+	attrs = append(attrs, "total", total.Round(time.Millisecond))
+	slog.Info("Phase timings", attrs...)
+}
+
+// finishRun stops the profiles and logs the phase timings. Commands call it once their
+// output is written, before rules may exit with a failure status.
+func finishRun(profile *profileFlags) {
+	profile.stop()
+	timings.log()
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go-depmap/pkg/analyzer"
+)
+
+// Progress modes of the -progress flag
+const (
+	progressAuto = "auto" // bar on terminals, log lines otherwise
+	progressBar  = "bar"
+	progressLog  = "log"
+	progressOff  = "off"
+)
+
+// phaseLoad is the progress phase of go/packages loading, which reports no counts
+const phaseLoad = "load"
+
+// Redraw period of the progress bar, and period of the progress log lines
+const (
+	progressBarInterval = 200 * time.Millisecond
+	progressLogInterval = 10 * time.Second
+)
+
+// progressReporter shows the progress of a long analysis on stderr, so that a long run does
+// not look hung: a bar redrawn in place on terminals, or periodic log lines otherwise. Its
+// methods may be called on a nil reporter, which reports nothing.
+type progressReporter struct {
+	bar bool
+
+	mu         sync.Mutex
+	progress   analyzer.Progress // Phase is empty between phases
+	phaseStart time.Time
+	drawn      bool // The bar is on screen and must be cleared before logs are written
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newProgressReporter starts a reporter in the given mode, or returns nil when progress is
+// off. In auto mode, progress is off when info messages are not logged (-quiet).
+func newProgressReporter(mode string) *progressReporter {
+	switch mode {
+	case progressOff:
+		return nil
+	case progressAuto:
+		if !slog.Default().Enabled(context.Background(), slog.LevelInfo) {
+			return nil
+		}
+		mode = progressLog
+		if stat, err := os.Stderr.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+			mode = progressBar
+		}
+	case progressBar, progressLog:
+	default:
+		fatalf("Unknown progress mode: %s (available: auto, bar, log, off)", mode)
+	}
+
+	p := &progressReporter{
+		bar:  mode == progressBar,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	interval := progressLogInterval
+	if p.bar {
+		interval = progressBarInterval
+	}
+	go p.run(interval)
+	return p
+}
+
+// run renders the progress periodically until the reporter is closed
+func (p *progressReporter) run(interval time.Duration) {
+	defer close(p.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.render()
+		}
+	}
+}
+
+// startPhase marks the start of a phase without counts, such as loading
+func (p *progressReporter) startPhase(phase string) {
+	p.update(analyzer.Progress{Phase: phase})
+}
+
+// update records the progress of the analysis; it is the analyzer's progress callback. The
+// bar is cleared when a phase ends, as log messages follow.
+func (p *progressReporter) update(progress analyzer.Progress) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if progress.Phase != p.progress.Phase {
+		p.phaseStart = time.Now()
+	}
+	p.progress = progress
+	if progress.Phase == "" || (progress.Total > 0 && progress.Packages == progress.Total) {
+		p.progress = analyzer.Progress{}
+		p.clear()
+	}
+}
+
+// endPhase marks the end of a phase without counts, and clears the bar
+func (p *progressReporter) endPhase() {
+	p.update(analyzer.Progress{})
+}
+
+// close stops the reporter and clears the bar
+func (p *progressReporter) close() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+// render draws the bar or logs a progress line for the current phase, if any
+func (p *progressReporter) render() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	progress := p.progress
+	if progress.Phase == "" {
+		return
+	}
+	elapsed := time.Since(p.phaseStart).Round(time.Second)
+
+	if !p.bar {
+		attrs := []any{"phase", progress.Phase, "elapsed", elapsed}
+		if progress.Total > 0 {
+			attrs = append(attrs, "packages", progress.Packages, "total", progress.Total, "files", progress.Files)
+		}
+		slog.Info("Progress", attrs...)
+		return
+	}
+
+	line := fmt.Sprintf("Loading packages... %s", elapsed)
+	if progress.Total > 0 {
+		const width = 30
+		filled := width * progress.Packages / progress.Total
+		line = fmt.Sprintf("%-12s [%s%s] %d/%d packages, %d files  %s", progress.Phase,
+			strings.Repeat("#", filled), strings.Repeat(".", width-filled),
+			progress.Packages, progress.Total, progress.Files, elapsed)
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+	p.drawn = true
+}
+
+// clear erases the bar, if drawn; the caller holds the lock
+func (p *progressReporter) clear() {
+	if p.drawn {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		p.drawn = false
+	}
+}
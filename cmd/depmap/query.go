@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"go-depmap/pkg/analyzer"
+	"go-depmap/pkg/format"
+	"go-depmap/pkg/graph"
+	"go-depmap/pkg/report"
+)
+
+// queryRequest is a reachability query about a single symbol, or a filter expression
+// selecting symbols or edges, answered from a freshly loaded graph or by a daemon keeping the
+// graph in memory
+type queryRequest struct {
+	Query  string        `json:"query"`  // reachable, dependents, or empty for -expr alone
+	Symbol string        `json:"symbol"` // Symbol reference, see graph.ResolveNode
+	Expr   string        `json:"expr"`   // Filter expression, see graph.Expr
+	Top    int           `json:"top"`    // Maximum number of symbols to list (0 = unlimited)
+	Format string        `json:"format"` // Output style of the symbol list
+	Graph  string        `json:"graph"`  // Output format of the graph, instead of a list
+	Config format.Config `json:"config"` // Configuration of the graph formatter
+}
+
+// runQuery answers reachability questions about a single symbol, and filter expressions:
+//
+//	depmap query reachable -from cmd/server::main   everything the symbol transitively depends on
+//	depmap query dependents -to pkg/db::Open        everything that transitively depends on the symbol
+//	depmap query -expr 'kind=function && fanin>10'  the symbols (or edges) matching the expression
+//
+// An expression also filters the symbols of the reachability queries. The result is listed as
+// a report, or written as a graph with -graph <format>. When a daemon analyzing the project
+// with the same options is running, it answers the query (see runDaemon); otherwise the
+// project is loaded.
+func runQuery(args []string) {
+	query, name := "", "depmap query"
+	if len(args) > 0 && (args[0] == "reachable" || args[0] == "dependents") {
+		query, name, args = args[0], "depmap query "+args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	load := addLoadFlags(fs)
+	include := addIncludeFlags(fs)
+	edgesPtr := fs.String("edges", "all", "Function dependencies to record: all, signature (API surface) or body (implementation)")
+	fromPtr := fs.String("from", "", "Entry point for the reachable query, e.g. cmd/server::main")
+	toPtr := fs.String("to", "", "Symbol for the dependents query, e.g. pkg/db::Open")
+	exprPtr := fs.String("expr", "", "Filter expression selecting symbols or edges, e.g. 'kind=function && fanin>10'")
+	topPtr := fs.Int("top", 0, "Maximum number of symbols to list (0 = unlimited)")
+	formatPtr := fs.String("format", "table", "Output style of the symbol list: table, json, markdown")
+	graphPtr := fs.String("graph", "", "Write the matching symbols as a graph in this output format instead of a list")
+	configPtr := fs.String("config", "{}", "JSON configuration object for the -graph formatter")
+	socketPtr := fs.String("socket", "", "Unix socket of the daemon to ask (default: the one of the project and analysis options)")
+	noDaemonPtr := fs.Bool("no-daemon", false, "Load the project even when a daemon is running")
+	logging := addLogFlags(fs)
+	profile := addProfileFlags(fs)
+	parseLoadFlags(fs, load, args)
+	logging.setup()
+	profile.start()
+
+	request := queryRequest{Query: query, Symbol: *fromPtr, Expr: *exprPtr, Top: *topPtr, Format: *formatPtr, Graph: *graphPtr}
+	symbolFlag := "from"
+	switch query {
+	case "":
+		if request.Expr == "" {
+			fatalf("Usage: depmap query reachable -from <symbol> [packages] | depmap query dependents -to <symbol> [packages] | depmap query -expr <expression> [packages]")
+		}
+		request.Symbol = ""
+	case "dependents":
+		request.Symbol, symbolFlag = *toPtr, "to"
+	}
+	if query != "" && request.Symbol == "" {
+		fatalf("The %s query requires -%s", query, symbolFlag)
+	}
+	if request.Expr != "" {
+		if _, err := graph.ParseExpr(request.Expr); err != nil {
+			fatalf("Invalid -expr: %v", err)
+		}
+	}
+
+	request.Config = parseConfig(*configPtr)
+	options := include.apply(analyzer.Options{
+		Scoring: request.Config.ScoringOptions(),
+		Scope:   parseEdgeScope(*edgesPtr),
+	})
+
+	phase := "report"
+	if request.Graph != "" {
+		phase = "format"
+	}
+	if !*noDaemonPtr {
+		socket := *socketPtr
+		var err error
+		if socket == "" {
+			socket, err = daemonSocket(*load, options)
+		}
+		answered := false
+		if err != nil {
+			slog.Debug("No socket directory, loading the project", "error", err)
+		} else {
+			endQuery := timings.track(phase)
+			answered, err = askDaemon(socket, request, os.Stdout)
+			endQuery()
+			if err != nil {
+				fatalf("%v", err)
+			}
+		}
+		if answered {
+			finishRun(profile)
+			return
+		}
+	}
+
+	g := loadGraph(*load, options)
+	endQuery := timings.track(phase)
+	if err := request.answer(g, os.Stdout); err != nil {
+		fatalf("%v", err)
+	}
+	endQuery()
+	finishRun(profile)
+}
+
+// answer writes the result of the query on a graph
+func (q queryRequest) answer(g *graph.DependencyGraph, w io.Writer) error {
+	var expr *graph.Expr
+	if q.Expr != "" {
+		var err error
+		if expr, err = graph.ParseExpr(q.Expr); err != nil {
+			return fmt.Errorf("invalid expression: %w", err)
+		}
+	}
+	var root string
+	if q.Query != "" {
+		var err error
+		if root, err = g.ResolveNode(q.Symbol); err != nil {
+			return fmt.Errorf("invalid symbol: %w", err)
+		}
+	}
+
+	if q.Graph == "" {
+		generate := report.Matching
+		switch q.Query {
+		case "reachable":
+			generate = report.Reachable
+		case "dependents":
+			generate = report.Dependents
+		}
+		opts := report.DefaultOptions()
+		opts.Root = root
+		opts.Expr = expr
+		opts.Limit = q.Top
+		if err := generate(g, opts).Render(w, q.Format); err != nil {
+			return fmt.Errorf("failed to write query result: %w", err)
+		}
+		return nil
+	}
+
+	// The expression is evaluated on the whole graph, so that fan-in and fan-out count every
+	// edge, and the selection is narrowed to the symbols reachable from or depending on root
+	extracted := g
+	if expr != nil {
+		extracted = g.Select(expr)
+	}
+	if q.Query != "" {
+		distances := g.Reachable(root)
+		if q.Query == "dependents" {
+			distances = g.Dependents(root)
+		}
+		nodeIDs := make([]string, 0, len(distances))
+		for nodeID := range distances {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+		extracted = extracted.Extract(nodeIDs)
+	}
+	extracted.ComputeSubgraphsWithScoring(q.Config.ScoringOptions())
+	extracted, err := filterOutput(extracted, q.Config)
+	if err != nil {
+		return err
+	}
+	extracted.UpdateMetadataCounts()
+
+	if err := format.GetFormatWriter(q.Graph).Write(w, extracted, q.Config); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	slog.Info("Query complete", "nodes", len(extracted.Nodes), "edges", extracted.CountEdges())
+	return nil
+}
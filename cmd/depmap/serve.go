@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-depmap/pkg/analyzer"
+	"go-depmap/pkg/server"
+)
+
+// shutdownTimeout is how long requests in progress may take to finish when the server stops
+const shutdownTimeout = 5 * time.Second
+
+// runServe analyzes a project and serves the graph over HTTP until interrupted. With -watch,
+// the project is analyzed again when its files change, and the changes are pushed to the
+// live clients:
+//
+//	depmap serve -addr localhost:8080 ./...          GraphQL on /graphql, REST on /api, the live view on /
+//	depmap serve -watch -watch-interval 2s ./...
+func runServe(args []string) {
+	fs := flag.NewFlagSet("depmap serve", flag.ExitOnError)
+	load := addLoadFlags(fs)
+	include := addIncludeFlags(fs)
+	edgesPtr := fs.String("edges", "all", "Function dependencies to record: all, signature (API surface) or body (implementation)")
+	configPtr := fs.String("config", "{}", "JSON configuration object, for the subgraph scoring options")
+	addrPtr := fs.String("addr", "localhost:8080", "Address to listen on")
+	watchPtr := fs.Bool("watch", false, "Analyze the project again when its Go files change, and push the changes to the live view")
+	watchIntervalPtr := fs.Duration("watch-interval", time.Second, "How often -watch checks the files for changes")
+	logging := addLogFlags(fs)
+	parseLoadFlags(fs, load, args)
+	logging.setup()
+
+	config := parseConfig(*configPtr)
+	options := include.apply(analyzer.Options{
+		Scoring: config.ScoringOptions(),
+		Scope:   parseEdgeScope(*edgesPtr),
+	})
+	var watcher *fileWatcher
+	if *watchPtr {
+		// Snapshot the files before the analysis, so that changes during it are not missed
+		var err error
+		if watcher, err = newFileWatcher(load.Source); err != nil {
+			fatalf("Failed to watch %s: %v", load.Source, err)
+		}
+	}
+	project, graph := loadProject(*load, options)
+	graph.UpdateMetadataCounts()
+
+	srv, err := server.New(graph)
+	if err != nil {
+		fatalf("Failed to create server: %v", err)
+	}
+	httpServer := &http.Server{
+		Addr:              *addrPtr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Stop accepting requests on interrupt, and let the ones in progress finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("Failed to shut down cleanly", "error", err)
+		}
+	}()
+
+	if watcher != nil {
+		go watchProject(ctx, watcher, *watchIntervalPtr, srv, project)
+	}
+
+	slog.Info("Serving", "addr", *addrPtr, "graphql", "http://"+*addrPtr+"/graphql", "nodes", len(graph.Nodes), "edges", graph.CountEdges())
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fatalf("Failed to serve: %v", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// watchProject updates the analysis whenever the watcher sees changes, and the served graph.
+// Only the changed packages and their importers are analyzed again, see analyzer.Update.
+// Updates failing, such as on syntax errors while editing, keep the previous graph.
+func watchProject(ctx context.Context, watcher *fileWatcher, interval time.Duration, srv *server.Server, a *analyzer.Analyzer) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := watcher.changes()
+		if err != nil {
+			slog.Warn("Failed to check for changes", "error", err)
+			continue
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		slog.Info("Files changed, analyzing again", "files", len(changed), "first", changed[0])
+
+		start := time.Now()
+		delta, err := a.Update(changed)
+		if err != nil {
+			slog.Error("Analysis failed, serving the previous graph", "error", err)
+			continue
+		}
+		graph := a.Graph()
+		if graph.Metadata != nil {
+			// The metadata is shared with the served graph
+			metadata := *graph.Metadata
+			metadata.Timestamp = start.UTC().Truncate(time.Second)
+			graph.Metadata = &metadata
+		}
+		graph.UpdateMetadataCounts()
+		srv.Publish(graph, delta)
+		slog.Info("Graph updated", "duration", time.Since(start).Round(time.Millisecond),
+			"added_nodes", len(delta.AddedNodes), "changed_nodes", len(delta.ChangedNodes), "removed_nodes", len(delta.RemovedNodes),
+			"added_edges", len(delta.AddedEdges), "changed_edges", len(delta.ChangedEdges), "removed_edges", len(delta.RemovedEdges))
+	}
+}
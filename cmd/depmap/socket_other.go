@@ -0,0 +1,20 @@
+//go:build !unix
+
+package main
+
+import (
+	"net"
+	"os"
+)
+
+// listenPrivate listens on a unix socket; the platform has no umask, the socket directory
+// restricts access instead
+func listenPrivate(socket string) (net.Listener, error) {
+	return net.Listen("unix", socket)
+}
+
+// ownedByUser reports whether a file belongs to the current user; files have no owner uid on
+// the platform, the socket directory restricts access instead
+func ownedByUser(os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// listenPrivate listens on a unix socket only its user can connect to. The socket is created
+// under a umask denying access to others, so that it is never open to them, even briefly.
+func listenPrivate(socket string) (net.Listener, error) {
+	umask := syscall.Umask(0o077)
+	defer syscall.Umask(umask)
+	return net.Listen("unix", socket)
+}
+
+// ownedByUser reports whether a file belongs to the current user
+func ownedByUser(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
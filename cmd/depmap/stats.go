@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"go-depmap/pkg/analyzer"
+	"go-depmap/pkg/graph"
+	"go-depmap/pkg/report"
+)
+
+// statsReports are the reports printed by the stats subcommand, in order
+var statsReports = []report.Generator{
+	report.TopDependedOn,
+	report.TopFanOut,
+	report.MostComplex,
+	report.BiggestPackages,
+	report.LongestChain,
+}
+
+// defaultStatsTop is the number of entries per stats report, unless set with -top
+const defaultStatsTop = 20
+
+// runStats prints top-N reports about the project's dependency graph
+func runStats(args []string) {
+	fs := flag.NewFlagSet("depmap stats", flag.ExitOnError)
+	load := addLoadFlags(fs)
+	include := addIncludeFlags(fs)
+	edgesPtr := fs.String("edges", "all", "Function dependencies to record: all, signature (API surface) or body (implementation)")
+	topPtr := fs.Int("top", defaultStatsTop, "Number of entries per report (0 = unlimited)")
+	formatPtr := fs.String("format", "table", "Output style: table, json, markdown")
+	chainsPtr := fs.Int("chains", 1, "Number of longest chains to list (0 = one per root)")
+	fullChainPtr := fs.Bool("full-chain", false, "List every node of each chain")
+	logging := addLogFlags(fs)
+	profile := addProfileFlags(fs)
+	parseLoadFlags(fs, load, args)
+	logging.setup()
+	profile.start()
+
+	options := analyzer.DefaultOptions()
+	options.Scope = parseEdgeScope(*edgesPtr)
+	graph := loadGraph(*load, include.apply(options))
+
+	opts := report.DefaultOptions()
+	opts.Limit = *topPtr
+	opts.Chains = *chainsPtr
+	opts.FullChain = *fullChainPtr
+
+	endReport := timings.track("report")
+	reports := generateAll(graph, statsReports, opts)
+
+	if err := report.RenderAll(os.Stdout, reports, *formatPtr); err != nil {
+		fatalf("Failed to write stats: %v", err)
+	}
+	endReport()
+	finishRun(profile)
+}
+
+// generateAll generates the reports of the generators, in order
+func generateAll(g *graph.DependencyGraph, generators []report.Generator, opts report.Options) []*report.Report {
+	reports := make([]*report.Report, 0, len(generators))
+	for _, generate := range generators {
+		reports = append(reports, generate(g, opts))
+	}
+	return reports
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, set by release builds with -ldflags "-X main.version=v1.2.3 -X main.commit=...
+// -X main.date=...". When unset, they are read from the build info that go build embeds.
+var (
+	version = ""
+	commit  = ""
+	date    = ""
+)
+
+// buildInfo identifies the running binary
+type buildInfo struct {
+	Version  string // Semantic version, or "dev" for unreleased builds
+	Commit   string // VCS revision the binary was built from
+	Date     string // Build date: the commit time for go build, RFC 3339
+	Modified bool   // Built from a working tree with uncommitted changes
+}
+
+// readBuildInfo combines the -ldflags values with the module version and VCS settings
+// embedded by the go command
+func readBuildInfo() buildInfo {
+	info := buildInfo{Version: version, Commit: commit, Date: date}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// String formats the build info on one line, e.g.
+// "go-depmap v1.2.3 (commit 0123abc, built 2026-01-02T03:04:05Z, go1.24.5)"
+func (b buildInfo) String() string {
+	s := "go-depmap " + b.Version + " ("
+	if b.Commit != "" {
+		s += "commit " + shortCommit(b.Commit)
+		if b.Modified {
+			s += "-dirty"
+		}
+		s += ", "
+	}
+	if b.Date != "" {
+		s += "built " + b.Date + ", "
+	}
+	return s + runtime.Version() + ")"
+}
+
+// shortCommit abbreviates a commit hash to 12 characters, like go version -m
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// runVersion prints the version, commit and build date of the binary
+func runVersion() {
+	fmt.Println(readBuildInfo())
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// fileStamp identifies a version of a file by its modification time and size
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// fileWatcher detects changes to the files of a Go project by polling: .go files, go.mod and
+// go.sum, outside of directories ignored by the go command (hidden, _-prefixed and testdata)
+type fileWatcher struct {
+	root  string
+	files map[string]fileStamp
+}
+
+// newFileWatcher records the current state of the project's files
+func newFileWatcher(root string) (*fileWatcher, error) {
+	files, err := scanProjectFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	return &fileWatcher{root: root, files: files}, nil
+}
+
+// changes returns the paths of the files added, modified or removed since the previous call,
+// sorted
+func (w *fileWatcher) changes() ([]string, error) {
+	files, err := scanProjectFiles(w.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for path, stamp := range files {
+		if previous, exists := w.files[path]; !exists || previous != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.files {
+		if _, exists := files[path]; !exists {
+			changed = append(changed, path)
+		}
+	}
+	w.files = files
+	slices.Sort(changed)
+	return changed, nil
+}
+
+// scanProjectFiles stamps the watched files below a directory
+func scanProjectFiles(root string) (map[string]fileStamp, error) {
+	files := make(map[string]fileStamp)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files removed during the walk are picked up by the next scan
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") && name != "go.mod" && name != "go.sum" {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		files[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files, err
+}
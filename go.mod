@@ -2,9 +2,19 @@ module go-depmap
 
 go 1.24.5
 
-require golang.org/x/tools v0.40.0
+require (
+	github.com/graphql-go/graphql v0.8.1
+	github.com/parquet-go/parquet-go v0.25.1
+	golang.org/x/net v0.48.0
+	golang.org/x/tools v0.40.0
+)
 
 require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
 	golang.org/x/mod v0.31.0 // indirect
 	golang.org/x/sync v0.19.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
 )
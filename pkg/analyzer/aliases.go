@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"go/types"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// addAlias registers the node of a type alias declaration (type Foo = Bar). Its signature is
+// the aliased type; the alias-of edge is added by linkAliases once all definitions are known.
+func (a *Analyzer) addAlias(pkg *packages.Package, obj types.Object, doc string, tags fileTags) {
+	rhs := obj.Type()
+	if alias, ok := rhs.(*types.Alias); ok {
+		rhs = alias.Rhs()
+	}
+
+	qualifier := types.RelativeTo(pkg.Types)
+	node := graph.CreateNode(pkg, obj, obj.Name(), graph.KindAlias, types.TypeString(rhs, qualifier))
+	node.Doc = doc
+	tags.apply(node)
+	a.addNode(pkg, obj, node)
+
+	if target := aliasTarget(rhs); target != nil {
+		a.aliases[a.projectObjects[obj]] = target
+	}
+}
+
+// aliasTarget returns the named type or alias an alias points to, or nil when it aliases an
+// unnamed type such as []string
+func aliasTarget(rhs types.Type) *types.TypeName {
+	switch t := rhs.(type) {
+	case *types.Alias:
+		return t.Obj()
+	case *types.Named:
+		return t.Origin().Obj()
+	}
+	return nil
+}
+
+// linkAliases adds an alias-of edge from every alias to the type it aliases. Aliased types
+// outside the project get an external node, so the edge is never silently dropped.
+func (a *Analyzer) linkAliases() {
+	for aliasNode, target := range a.aliases {
+		targetNode, isLocal := a.projectNode(target)
+		if !isLocal {
+			// Predeclared types like error have no package and are not worth a node
+			if target.Pkg() == nil {
+				continue
+			}
+			targetNode = a.externalNode(target)
+		}
+		a.graph.AddEdge(aliasNode.ID, targetNode.ID, graph.EdgeAliasOf)
+	}
+}
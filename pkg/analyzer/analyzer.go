@@ -7,47 +7,246 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
-	"log"
+	"log/slog"
+	"path/filepath"
+	"strings"
 
 	"go-depmap/pkg/graph"
 
 	"golang.org/x/tools/go/packages"
 )
 
+// Options configures the analysis
+type Options struct {
+	Scoring  graph.ScoringOptions // How subgraphs are scored
+	Scope    EdgeScope            // Which function dependencies are recorded
+	Snippets SnippetOptions       // How much function source is embedded in the nodes
+	External ExternalOptions      // Which third-party symbols become nodes
+	Stdlib   StdlibOptions        // Which standard library packages become nodes
+	Mocks    MockOptions          // Which files hold mocks and test doubles
+	Globals  bool                 // Add nodes for package-level variables, with read and write edges
+
+	// Occurrences records the positions of the definitions of the symbols and of their uses
+	// in functions, see graph.Occurrence
+	Occurrences bool
+
+	// ExcludeGenerated leaves out the symbols of generated files, recognized by the standard
+	// "// Code generated ... DO NOT EDIT." header. Otherwise they are tagged as generated.
+	ExcludeGenerated bool
+
+	// Progress, when set, is called as packages are analyzed, e.g. to render a progress bar
+	Progress func(Progress)
+
+	// Load loads packages again for Update, with the configuration of the initial load. The
+	// patterns are import paths, and "file=" queries for new files. Update fails without it.
+	Load func(patterns []string) ([]*packages.Package, error)
+}
+
+// EdgeScope selects the part of a function whose dependencies are recorded
+type EdgeScope string
+
+// Edge scope constants
+const (
+	ScopeAll       EdgeScope = "all"       // Signature and body (default)
+	ScopeSignature EdgeScope = "signature" // API surface: receiver, parameter and result types
+	ScopeBody      EdgeScope = "body"      // Implementation only
+)
+
+// IsValid reports whether the scope is one of the known edge scopes
+func (s EdgeScope) IsValid() bool {
+	switch s {
+	case ScopeAll, ScopeSignature, ScopeBody, "":
+		return true
+	}
+	return false
+}
+
+// Includes reports whether dependencies of the given kind are recorded in this scope.
+// An empty scope means ScopeAll.
+func (s EdgeScope) Includes(kind graph.EdgeKind) bool {
+	switch s {
+	case ScopeSignature:
+		return kind == graph.EdgeSignature
+	case ScopeBody:
+		return kind == graph.EdgeBody
+	}
+	return true
+}
+
+// DefaultOptions returns the options used by New
+func DefaultOptions() Options {
+	return Options{
+		Scoring: graph.DefaultScoringOptions(),
+		Scope:   ScopeAll,
+		Mocks:   MockOptions{Patterns: DefaultMockPatterns},
+	}
+}
+
 // Analyzer performs dependency analysis on Go packages
 type Analyzer struct {
 	packages       []*packages.Package
+	options        Options
 	projectObjects map[types.Object]*graph.Node
+	aliases        map[*graph.Node]*types.TypeName // Alias nodes -> aliased named type, linked once all definitions are known
+	sources        map[string][]byte               // File contents read for snippets, by filename
+	modules        map[string]bool                 // Paths of the analyzed modules
+	inits          map[string][]*graph.Node        // init functions by package path, in execution order
+	initVariants   map[string]*packages.Package    // Variant of each package its init functions were recorded from
+	timings        []PhaseTiming                   // Durations of the phases of the last analysis
+	recursive      map[string]bool                 // IDs of the functions calling themselves directly
+	reloaded       bool                            // Set by Update, see projectNode
 	graph          *graph.DependencyGraph
 }
 
-// New creates a new Analyzer for the given packages
+// New creates a new Analyzer for the given packages with the default options
 func New(pkgs []*packages.Package) *Analyzer {
+	return NewWithOptions(pkgs, DefaultOptions())
+}
+
+// NewWithOptions creates a new Analyzer for the given packages with the given options
+func NewWithOptions(pkgs []*packages.Package, options Options) *Analyzer {
 	return &Analyzer{
 		packages:       pkgs,
+		options:        options,
 		projectObjects: make(map[types.Object]*graph.Node),
+		aliases:        make(map[*graph.Node]*types.TypeName),
+		sources:        make(map[string][]byte),
+		modules:        make(map[string]bool),
+		inits:          make(map[string][]*graph.Node),
+		initVariants:   make(map[string]*packages.Package),
+		recursive:      make(map[string]bool),
 		graph:          graph.NewDependencyGraph(),
 	}
 }
 
-// Analyze performs the full dependency analysis
+// Analyze performs the full dependency analysis. The duration of its phases is available
+// from Timings afterwards.
 func (a *Analyzer) Analyze() *graph.DependencyGraph {
-	a.collectDefinitions()
-	a.analyzeDependencies()
+	a.timings = nil
+	a.timePhase(PhaseDefinitions, func() {
+		a.collectModules()
+		a.collectDefinitions(a.packages)
+		a.linkAliases()
+		a.linkInits()
+	})
+	a.timePhase(PhaseDependencies, func() {
+		a.analyzeDependencies(a.packages)
+		a.graph.SortOccurrences()
+		a.graph.Compact()
+	})
+	a.timePhase(PhaseSubgraphs, a.computeSubgraphs)
 	return a.graph
 }
 
-// collectDefinitions scans all packages and collects function and type definitions
-func (a *Analyzer) collectDefinitions() {
-	log.Println("Scanning definitions...")
+// skipPackage reports whether a package is not part of the analyzed project.
+// When tests are loaded, go/packages also returns a package and its test variant
+// ("p [p.test]"), which share their non-test files; both are analyzed and their
+// definitions merged by node ID. The generated test main packages ("p.test") are skipped.
+func skipPackage(pkg *packages.Package) bool {
+	// Skip if it's not part of the main module being analyzed
+	return pkg.Module == nil || strings.HasSuffix(pkg.PkgPath, ".test")
+}
+
+// addNode registers the node for a definition, reusing the node already created for the same
+// symbol by another variant of the package. The node's doc comment must be set, as the
+// deprecation notice is derived from it.
+func (a *Analyzer) addNode(pkg *packages.Package, obj types.Object, node *graph.Node) {
+	node.Deprecated = deprecationNotice(node.Doc)
+	if existing, exists := a.graph.Nodes[node.ID]; exists {
+		node = existing
+	}
+	a.projectObjects[obj] = node
+	a.graph.Nodes[node.ID] = node
+	a.addOccurrence(pkg, obj.Pos(), len(obj.Name()), node, true)
+}
+
+// projectNode returns the node of a project symbol. After an Update, the packages that were
+// not loaded again are also loaded as dependencies of the others, with new objects: their
+// symbols are then found by node ID.
+func (a *Analyzer) projectNode(obj types.Object) (*graph.Node, bool) {
+	if node, ok := a.projectObjects[obj]; ok {
+		return node, true
+	}
+	if !a.reloaded || obj.Pkg() == nil || !a.inModule(obj.Pkg().Path()) {
+		return nil, false
+	}
+	switch x := obj.(type) {
+	case *types.Func:
+		if x.Origin() != x {
+			return nil, false
+		}
+	case *types.Var:
+		if x.Parent() != x.Pkg().Scope() {
+			return nil, false
+		}
+	case *types.TypeName:
+	default:
+		return nil, false
+	}
+	name, _ := externalName(obj)
+	node, ok := a.graph.Nodes[obj.Pkg().Path()+"::"+name]
+	return node, ok && !node.External
+}
+
+// typeKind derives the node kind of a declared type from its underlying type
+func typeKind(t types.Type) graph.NodeKind {
+	switch t.Underlying().(type) {
+	case *types.Interface:
+		return graph.KindInterface
+	case *types.Struct:
+		return graph.KindStruct
+	default:
+		return graph.KindNamed
+	}
+}
 
-	for _, pkg := range a.packages {
-		// Skip if it's not part of the main module being analyzed
-		if pkg.Module == nil {
+// typeDoc returns the doc comment of a type spec. An ungrouped declaration (type Foo struct{})
+// carries its comment on the GenDecl rather than the spec.
+func typeDoc(decl *ast.GenDecl, spec *ast.TypeSpec) string {
+	if spec.Doc != nil {
+		return spec.Doc.Text()
+	}
+	if !decl.Lparen.IsValid() {
+		return decl.Doc.Text()
+	}
+	return ""
+}
+
+// fileTags are the properties of a source file that carry over to the symbols it declares
+type fileTags struct {
+	generated bool
+	mock      bool
+}
+
+// apply tags a node declared in the file
+func (t fileTags) apply(node *graph.Node) {
+	node.Generated = t.generated
+	node.Mock = t.mock
+}
+
+// collectDefinitions scans packages and collects function and type definitions
+func (a *Analyzer) collectDefinitions(pkgs []*packages.Package) {
+	slog.Debug("Scanning definitions")
+
+	progress := a.startProgress(PhaseDefinitions, pkgs)
+	for _, pkg := range pkgs {
+		if skipPackage(pkg) {
 			continue
 		}
 
 		for _, file := range pkg.Syntax {
+			filename := pkg.Fset.Position(file.Pos()).Filename
+			isTestFile := strings.HasSuffix(filename, "_test.go")
+			tags := fileTags{
+				generated: ast.IsGenerated(file),
+				mock:      a.options.Mocks.Matches(pkg.PkgPath, filepath.Base(filename)),
+			}
+			if (tags.generated && a.options.ExcludeGenerated) || (tags.mock && a.options.Mocks.Exclude) {
+				continue
+			}
+			linked := linknames(file)
+			inits := 0
+
 			ast.Inspect(file, func(n ast.Node) bool {
 				switch x := n.(type) {
 
@@ -75,11 +274,34 @@ func (a *Analyzer) collectDefinitions() {
 						} else if ident, ok := recvType.(*ast.Ident); ok {
 							name = fmt.Sprintf("%s.%s", ident.Name, name)
 						}
+					} else if name == "init" {
+						// init functions may be declared several times per package, even per file
+						inits++
+						kind = graph.KindInit
+						name = initName(filename, inits)
+					} else if isTestFile {
+						if testKind, ok := testFunctionKind(name, obj.Type().(*types.Signature)); ok {
+							kind = testKind
+						}
 					}
 
 					node := graph.CreateNode(pkg, obj, name, kind, sig)
-					a.projectObjects[obj] = node
-					a.graph.Nodes[node.ID] = node
+					node.Doc = x.Doc.Text()
+					node.Lines = linesOfCode(pkg.Fset, x)
+					node.Complexity = cyclomaticComplexity(x)
+					node.Unsafe, node.Reflect = riskyUses(pkg.TypesInfo, x)
+					node.ReturnsError = returnsError(obj.Type().(*types.Signature))
+					node.TakesContext = takesContext(obj.Type().(*types.Signature))
+					node.Receiver = receiverID(obj.Type().(*types.Signature))
+					node.Linkname = x.Recv == nil && linked[x.Name.Name]
+					tags.apply(node)
+					if a.options.Snippets.Enabled() {
+						node.Snippet = a.snippet(pkg, x)
+					}
+					a.addNode(pkg, obj, node)
+					if kind == graph.KindInit {
+						a.addInit(pkg, a.projectObjects[obj])
+					}
 
 				// Case B: Type Declarations (GenDecl with TypeSpec)
 				case *ast.GenDecl:
@@ -94,26 +316,36 @@ func (a *Analyzer) collectDefinitions() {
 								continue
 							}
 
-							node := graph.CreateNode(pkg, obj, typeSpec.Name.Name, graph.KindType, obj.Type().String())
-							a.projectObjects[obj] = node
-							a.graph.Nodes[node.ID] = node
+							doc := typeDoc(x, typeSpec)
+							if typeSpec.Assign.IsValid() {
+								a.addAlias(pkg, obj, doc, tags)
+								continue
+							}
+							node := graph.CreateNode(pkg, obj, typeSpec.Name.Name, typeKind(obj.Type()), obj.Type().String())
+							node.Doc = doc
+							tags.apply(node)
+							a.addNode(pkg, obj, node)
 						}
+					} else if x.Tok == token.VAR && a.options.Globals {
+						a.addGlobals(pkg, x, tags)
 					}
 				}
 				return true
 			})
 		}
+		a.packageDone(progress, len(pkg.Syntax))
 	}
 
-	log.Printf("Found %d definitions inside the project.", len(a.projectObjects))
+	slog.Info("Found definitions inside the project", "definitions", len(a.graph.Nodes))
 }
 
-// analyzeDependencies analyzes function bodies to find dependencies
-func (a *Analyzer) analyzeDependencies() {
-	log.Println("Analyzing function dependencies...")
+// analyzeDependencies analyzes the function bodies of packages to find dependencies
+func (a *Analyzer) analyzeDependencies(pkgs []*packages.Package) {
+	slog.Debug("Analyzing function dependencies")
 
-	for _, pkg := range a.packages {
-		if pkg.Module == nil {
+	progress := a.startProgress(PhaseDependencies, pkgs)
+	for _, pkg := range pkgs {
+		if skipPackage(pkg) {
 			continue
 		}
 
@@ -131,51 +363,95 @@ func (a *Analyzer) analyzeDependencies() {
 					return true
 				}
 
-				// Track unique dependencies to avoid duplicates
-				seenDeps := make(map[string]bool)
-
-				// Helper to record a dependency
-				addDep := func(targetObj types.Object) {
+				// Helper to record a dependency; AddEdge ignores duplicates, also across package variants
+				addDep := func(ident *ast.Ident, targetObj types.Object, kinds []graph.EdgeKind) {
 					// Ignore if target is not in our project definitions
-					// This automatically filters out stdlib, vendor, etc.
-					if targetNode, isLocal := a.projectObjects[targetObj]; isLocal {
-						// Don't depend on self
-						if targetNode.ID == sourceNode.ID {
-							return
-						}
-						if !seenDeps[targetNode.ID] {
-							a.graph.Edges[sourceNode.ID] = append(a.graph.Edges[sourceNode.ID], targetNode.ID)
-							seenDeps[targetNode.ID] = true
+					// This automatically filters out stdlib, vendor, etc., unless third-party symbols are included
+					targetNode, ok := a.projectNode(targetObj)
+					if !ok {
+						targetNode, ok = a.externalTarget(targetObj)
+					}
+					if !ok {
+						targetNode, ok = a.stdlibTarget(targetObj)
+					}
+					if !ok {
+						return
+					}
+					a.addOccurrence(pkg, ident.Pos(), len(ident.Name), targetNode, false)
+					// Don't depend on self, but remember that a function calls itself
+					if targetNode.ID == sourceNode.ID {
+						if kinds[0] == graph.EdgeBody && sourceNode.Kind.IsCallable() {
+							sourceNode.Recursive = true
+							a.recursive[sourceNode.ID] = true
 						}
+						return
+					}
+					a.graph.AddEdge(sourceNode.ID, targetNode.ID, kinds...)
+					if targetNode.Deprecated != "" {
+						a.addDeprecatedUse(pkg, ident, sourceNode.ID, targetNode.ID)
 					}
 				}
 
-				// Walk the function body and signature
-				ast.Inspect(fn, func(subNode ast.Node) bool {
-					ident, ok := subNode.(*ast.Ident)
-					if !ok {
-						return true
+				// Walk the parts of the signature and the body separately, tagging the edges found with
+				// the given kinds. The first kind (signature or body) decides whether the part is in scope.
+				// Functions used as values rather than called are additionally tagged as references.
+				walk := func(root ast.Node, kinds ...graph.EdgeKind) {
+					if !a.options.Scope.Includes(kinds[0]) {
+						return
 					}
+					called := calledIdents(root)
+					written := writtenIdents(pkg.TypesInfo, root)
+					ast.Inspect(root, func(subNode ast.Node) bool {
+						ident, ok := subNode.(*ast.Ident)
+						if !ok {
+							return true
+						}
 
-					// Resolve the identifier using TypeInfo
-					// Uses maps identifiers to the objects they denote
-					if usedObj, ok := pkg.TypesInfo.Uses[ident]; ok {
-						addDep(usedObj)
-					}
-					return true
-				})
+						// Resolve the identifier using TypeInfo
+						// Uses maps identifiers to the objects they denote
+						if usedObj, ok := pkg.TypesInfo.Uses[ident]; ok {
+							switch {
+							case isFuncValue(usedObj, ident, called):
+								addDep(ident, usedObj, append(kinds[:len(kinds):len(kinds)], graph.EdgeReference))
+							case a.isGlobal(usedObj):
+								addDep(ident, usedObj, append(kinds[:len(kinds):len(kinds)], accessKind(ident, written)))
+							default:
+								addDep(ident, usedObj, kinds)
+							}
+						}
+						return true
+					})
+				}
+				if fn.Recv != nil {
+					walk(fn.Recv, graph.EdgeSignature, graph.EdgeReceiver)
+				}
+				if fn.Type.TypeParams != nil {
+					walk(fn.Type.TypeParams, graph.EdgeSignature)
+				}
+				walk(fn.Type.Params, graph.EdgeSignature, graph.EdgeParam)
+				if fn.Type.Results != nil {
+					walk(fn.Type.Results, graph.EdgeSignature, graph.EdgeResult)
+				}
+				if fn.Body != nil {
+					walk(fn.Body, graph.EdgeBody)
+				}
 
 				return true
 			})
 		}
+		a.packageDone(progress, len(pkg.Syntax))
 	}
+}
+
+// computeSubgraphs marks recursive functions, then computes and scores the subgraphs
+func (a *Analyzer) computeSubgraphs() {
+	a.graph.MarkRecursion()
 
-	log.Println("Computing subgraphs...")
-	a.graph.ComputeSubgraphs()
-	log.Printf("Found %d subgraph(s)", len(a.graph.Subgraphs))
+	slog.Debug("Computing subgraphs")
+	a.graph.ComputeSubgraphsWithScoring(a.options.Scoring)
+	slog.Info("Found subgraphs", "subgraphs", len(a.graph.Subgraphs))
 	if len(a.graph.Subgraphs) > 0 {
 		largest := a.graph.GetLargestSubgraph()
-		log.Printf("Largest subgraph: %d nodes, %d edges, score: %.2f",
-			len(largest.NodeIDs), largest.EdgeCount, largest.Score)
+		slog.Info("Largest subgraph", "nodes", len(largest.NodeIDs), "edges", largest.EdgeCount, "score", largest.Score)
 	}
 }
@@ -4,6 +4,10 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"go-depmap/pkg/graph"
@@ -105,7 +109,7 @@ func Test_Analyzer_CollectDefinitions_SkipsNonModulePackages(t *testing.T) {
 	}
 
 	a := New(pkgs)
-	a.collectDefinitions()
+	a.collectDefinitions(a.packages)
 
 	if len(a.projectObjects) != 0 {
 		t.Errorf("Expected 0 objects from non-module package, got %d", len(a.projectObjects))
@@ -260,3 +264,564 @@ func Test_Analyzer_EmptyAnalysis(t *testing.T) {
 		t.Errorf("Expected 0 edges, got %d", result.CountEdges())
 	}
 }
+
+// loadSource writes the given files into a temporary module and loads its packages
+func loadSource(t *testing.T, files map[string]string, tests bool) []*packages.Package {
+	t.Helper()
+
+	pkgs := loadSourceWithErrors(t, files, tests)
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("Packages contained errors")
+	}
+	return pkgs
+}
+
+// loadSourceWithErrors is loadSource for sources that are expected not to compile
+func loadSourceWithErrors(t *testing.T, files map[string]string, tests bool) []*packages.Package {
+	t.Helper()
+
+	dir := t.TempDir()
+	if _, ok := files["go.mod"]; !ok {
+		files["go.mod"] = "module example.com/app\n\ngo 1.21\n"
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps | packages.NeedModule,
+		Dir:   dir,
+		Tests: tests,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("Failed to load packages: %v", err)
+	}
+	return pkgs
+}
+
+func Test_Analyzer_Tests(t *testing.T) {
+	files := map[string]string{
+		"lib/lib.go": `package lib
+
+func Run() int { return helper() }
+
+func helper() int { return 1 }
+
+func Fixture() int { return 2 }
+`,
+		"lib/lib_test.go": `package lib
+
+import "testing"
+
+func TestRun(t *testing.T) {
+	if Run() != helper() {
+		t.Fail()
+	}
+}
+`,
+		"lib/external_test.go": `package lib_test
+
+import (
+	"testing"
+
+	"example.com/app/lib"
+)
+
+func TestFixture(t *testing.T) {
+	_ = lib.Fixture()
+}
+`,
+	}
+
+	withoutTests := New(loadSource(t, files, false)).Analyze()
+	if _, exists := withoutTests.Nodes["example.com/app/lib::TestRun"]; exists {
+		t.Error("Test functions should not be analyzed unless tests are loaded")
+	}
+
+	result := New(loadSource(t, files, true)).Analyze()
+
+	for _, id := range []string{
+		"example.com/app/lib::Run",
+		"example.com/app/lib::TestRun",
+		"example.com/app/lib_test::TestFixture",
+	} {
+		if _, exists := result.Nodes[id]; !exists {
+			t.Errorf("Expected node %s", id)
+		}
+	}
+	for id := range result.Nodes {
+		if strings.HasSuffix(id, ".test::main") {
+			t.Errorf("Generated test main should be skipped, found %s", id)
+		}
+	}
+
+	// The package and its test variant share lib.go; its edges must not be duplicated
+	if edges := result.Edges["example.com/app/lib::Run"]; len(edges) != 1 {
+		t.Errorf("Expected a single edge from Run, got %v", edges)
+	}
+	expected := []string{"example.com/app/lib::Fixture"}
+	if edges := result.Edges["example.com/app/lib_test::TestFixture"]; !reflect.DeepEqual(edges, expected) {
+		t.Errorf("TestFixture edges = %v, want %v", edges, expected)
+	}
+	if !result.Nodes["example.com/app/lib::TestRun"].IsTest() {
+		t.Error("TestRun should be a test node")
+	}
+	if kind := result.Nodes["example.com/app/lib::TestRun"].Kind; kind != graph.KindTest {
+		t.Errorf("TestRun kind = %s, want %s", kind, graph.KindTest)
+	}
+	if kind := result.Nodes["example.com/app/lib::Run"].Kind; kind != graph.KindFunction {
+		t.Errorf("Run kind = %s, want %s", kind, graph.KindFunction)
+	}
+}
+
+func Test_Analyzer_TypeKinds(t *testing.T) {
+	files := map[string]string{
+		"shapes/shapes.go": `package shapes
+
+type Shape interface{ Area() float64 }
+
+type Square struct{ Side float64 }
+
+type Meters float64
+
+type Handler func()
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	expected := map[string]graph.NodeKind{
+		"example.com/app/shapes::Shape":   graph.KindInterface,
+		"example.com/app/shapes::Square":  graph.KindStruct,
+		"example.com/app/shapes::Meters":  graph.KindNamed,
+		"example.com/app/shapes::Handler": graph.KindNamed,
+	}
+	for id, kind := range expected {
+		node, exists := result.Nodes[id]
+		if !exists {
+			t.Errorf("Expected node %s", id)
+			continue
+		}
+		if node.Kind != kind {
+			t.Errorf("%s kind = %s, want %s", id, node.Kind, kind)
+		}
+	}
+}
+
+func Test_Analyzer_Aliases(t *testing.T) {
+	files := map[string]string{
+		"model/model.go": `package model
+
+import "io"
+
+type User struct{ Name string }
+
+type Person = User
+
+type Reader = io.Reader
+
+type Names = []string
+
+func Greet(p Person) string { return p.Name }
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	person := result.Nodes["example.com/app/model::Person"]
+	if person == nil || person.Kind != graph.KindAlias || person.Signature != "User" {
+		t.Fatalf("Unexpected alias node: %+v", person)
+	}
+	if kinds := result.EdgeKindsOf(person.ID, "example.com/app/model::User"); len(kinds) != 1 || kinds[0] != graph.EdgeAliasOf {
+		t.Errorf("Expected an alias-of edge to User, got %v", kinds)
+	}
+
+	external := result.Nodes["io::Reader"]
+	if external == nil || !external.External || external.Kind != graph.KindInterface {
+		t.Fatalf("Expected an external interface node for io.Reader, got %+v", external)
+	}
+	if !result.HasEdge("example.com/app/model::Reader", external.ID) {
+		t.Error("Expected an alias-of edge to the external type")
+	}
+
+	if names := result.Nodes["example.com/app/model::Names"]; names == nil || len(result.Edges[names.ID]) != 0 {
+		t.Errorf("Aliases of unnamed types should have no alias-of edge, got %v", result.Edges["example.com/app/model::Names"])
+	}
+
+	// Uses of the alias point at the alias node, not a duplicate of the aliased type
+	if !result.HasEdge("example.com/app/model::Greet", person.ID) {
+		t.Errorf("Expected Greet to depend on the alias, got %v", result.Edges["example.com/app/model::Greet"])
+	}
+}
+
+func Test_Analyzer_SignatureAndBodyEdges(t *testing.T) {
+	files := map[string]string{
+		"svc/svc.go": `package svc
+
+type Request struct{}
+
+type Response struct{}
+
+type Store struct{}
+
+func (s *Store) Load() {}
+
+func validate(r *Request) bool { return r != nil }
+
+func Handle(r *Request) *Response {
+	validate(r)
+	var s Store
+	s.Load()
+	return &Response{}
+}
+`,
+	}
+	handle := "example.com/app/svc::Handle"
+	kinds := func(g *graph.DependencyGraph, target string) []graph.EdgeKind {
+		return g.EdgeKindsOf(handle, "example.com/app/svc::"+target)
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	tests := []struct {
+		target   string
+		expected []graph.EdgeKind
+	}{
+		{target: "Request", expected: []graph.EdgeKind{graph.EdgeSignature, graph.EdgeParam}},
+		{target: "Response", expected: []graph.EdgeKind{graph.EdgeSignature, graph.EdgeResult, graph.EdgeBody}},
+		{target: "validate", expected: []graph.EdgeKind{graph.EdgeBody}},
+		{target: "Store", expected: []graph.EdgeKind{graph.EdgeBody}},
+	}
+	for _, tt := range tests {
+		if got := kinds(result, tt.target); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Kinds of Handle -> %s = %v, want %v", tt.target, got, tt.expected)
+		}
+	}
+
+	receiverKinds := result.EdgeKindsOf("example.com/app/svc::(*Store).Load", "example.com/app/svc::Store")
+	if !reflect.DeepEqual(receiverKinds, []graph.EdgeKind{graph.EdgeSignature, graph.EdgeReceiver}) {
+		t.Errorf("Kinds of Load -> Store = %v, want [signature receiver]", receiverKinds)
+	}
+
+	signatureOnly := NewWithOptions(loadSource(t, files, false), Options{
+		Scoring: graph.DefaultScoringOptions(),
+		Scope:   ScopeSignature,
+	}).Analyze()
+	if len(signatureOnly.Edges[handle]) != 2 {
+		t.Errorf("Expected only the signature edges, got %v", signatureOnly.Edges[handle])
+	}
+	if signatureOnly.HasEdge(handle, "example.com/app/svc::validate") {
+		t.Error("Body edges should not be recorded in the signature scope")
+	}
+}
+
+func Test_EdgeScope(t *testing.T) {
+	tests := []struct {
+		scope     EdgeScope
+		signature bool
+		body      bool
+	}{
+		{scope: ScopeAll, signature: true, body: true},
+		{scope: "", signature: true, body: true},
+		{scope: ScopeSignature, signature: true, body: false},
+		{scope: ScopeBody, signature: false, body: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.scope), func(t *testing.T) {
+			if !tt.scope.IsValid() {
+				t.Errorf("%q should be valid", tt.scope)
+			}
+			if got := tt.scope.Includes(graph.EdgeSignature); got != tt.signature {
+				t.Errorf("Includes(signature) = %v, want %v", got, tt.signature)
+			}
+			if got := tt.scope.Includes(graph.EdgeBody); got != tt.body {
+				t.Errorf("Includes(body) = %v, want %v", got, tt.body)
+			}
+		})
+	}
+
+	if EdgeScope("bogus").IsValid() {
+		t.Error("Unknown scopes should be invalid")
+	}
+}
+
+func Test_Analyzer_DocComments(t *testing.T) {
+	files := map[string]string{
+		"docs/docs.go": `package docs
+
+// Config holds the settings.
+type Config struct{}
+
+type (
+	// ID identifies a user.
+	ID string
+
+	Name string
+)
+
+// Alias is another name for Config.
+type Alias = Config
+
+// Load reads the config.
+// It never fails.
+func Load() Config { return Config{} }
+
+// Validate checks the config.
+func (c Config) Validate() {}
+
+func undocumented() {}
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	expected := map[string]string{
+		"Config":          "Config holds the settings.\n",
+		"ID":              "ID identifies a user.\n",
+		"Name":            "",
+		"Alias":           "Alias is another name for Config.\n",
+		"Load":            "Load reads the config.\nIt never fails.\n",
+		"Config.Validate": "Validate checks the config.\n",
+		"undocumented":    "",
+	}
+	for name, doc := range expected {
+		node := result.Nodes["example.com/app/docs::"+name]
+		if node == nil {
+			t.Errorf("Expected node %s", name)
+			continue
+		}
+		if node.Doc != doc {
+			t.Errorf("%s doc = %q, want %q", name, node.Doc, doc)
+		}
+	}
+}
+
+func Test_Analyzer_Complexity(t *testing.T) {
+	files := map[string]string{
+		"calc/calc.go": `package calc
+
+func Straight() int {
+	return 1
+}
+
+func Branchy(values []int, strict bool) int {
+	total := 0
+	for _, v := range values {
+		if v > 0 && strict {
+			total += v
+		}
+	}
+	switch {
+	case total > 10:
+		return 10
+	case total < 0 || strict:
+		return 0
+	default:
+		return total
+	}
+}
+
+type Calc struct{}
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	tests := []struct {
+		name       string
+		lines      int
+		complexity int
+	}{
+		{name: "Straight", lines: 3, complexity: 1},
+		// range, if, &&, two cases and || on top of the base complexity
+		{name: "Branchy", lines: 16, complexity: 7},
+		{name: "Calc", lines: 0, complexity: 0},
+	}
+	for _, tt := range tests {
+		node := result.Nodes["example.com/app/calc::"+tt.name]
+		if node == nil {
+			t.Errorf("Expected node %s", tt.name)
+			continue
+		}
+		if node.Lines != tt.lines || node.Complexity != tt.complexity {
+			t.Errorf("%s lines = %d, complexity = %d, want %d, %d", tt.name, node.Lines, node.Complexity, tt.lines, tt.complexity)
+		}
+	}
+}
+
+func Test_Analyzer_Recursion(t *testing.T) {
+	files := map[string]string{
+		"calc/calc.go": `package calc
+
+type Tree struct{ Left, Right *Tree }
+
+func (t *Tree) Size() int {
+	if t == nil {
+		return 0
+	}
+	return 1 + t.Left.Size() + t.Right.Size()
+}
+
+func Even(n int) bool { return n == 0 || Odd(n-1) }
+
+func Odd(n int) bool { return n != 0 && Even(n-1) }
+
+func Sum(n int) int { return n + Even2(n) }
+
+func Even2(n int) int { return n * 2 }
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	for name, recursive := range map[string]bool{
+		"(*Tree).Size": true,
+		"Even":         true,
+		"Odd":          true,
+		"Sum":          false,
+		"Even2":        false,
+	} {
+		if got := result.Nodes["example.com/app/calc::"+name].Recursive; got != recursive {
+			t.Errorf("%s: expected recursive %v, got %v", name, recursive, got)
+		}
+	}
+	if result.HasEdge("example.com/app/calc::(*Tree).Size", "example.com/app/calc::(*Tree).Size") {
+		t.Error("Self-recursion should not add a self edge")
+	}
+}
+
+func Test_Analyzer_ReturnsError(t *testing.T) {
+	files := map[string]string{
+		"io/io.go": `package io
+
+type Err struct{}
+
+func (Err) Error() string { return "" }
+
+func Open() error { return nil }
+
+func Read() (int, error) { return 0, nil }
+
+func Custom() *Err { return nil }
+
+func Close() {}
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	for name, expected := range map[string]bool{
+		"Open":   true,
+		"Read":   true,
+		"Custom": false, // Concrete error types are not the error interface
+		"Close":  false,
+	} {
+		if got := result.Nodes["example.com/app/io::"+name].ReturnsError; got != expected {
+			t.Errorf("%s: expected returns error %v, got %v", name, expected, got)
+		}
+	}
+}
+
+func Test_Analyzer_TakesContext(t *testing.T) {
+	files := map[string]string{
+		"svc/svc.go": `package svc
+
+import "context"
+
+type Ctx = context.Context
+
+func Fetch(ctx context.Context, id int) error { return nil }
+
+func FetchAlias(ctx Ctx) {}
+
+func Legacy(id int) { _ = Fetch(context.TODO(), id) }
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	for name, expected := range map[string]bool{
+		"Fetch":      true,
+		"FetchAlias": true,
+		"Legacy":     false,
+	} {
+		if got := result.Nodes["example.com/app/svc::"+name].TakesContext; got != expected {
+			t.Errorf("%s: expected takes context %v, got %v", name, expected, got)
+		}
+	}
+}
+
+func Test_Analyzer_Receivers(t *testing.T) {
+	files := map[string]string{
+		"svc/svc.go": `package svc
+
+type Store struct{}
+
+func (s *Store) Load() {}
+
+func (s Store) Len() int { return 0 }
+
+type Stack[T any] struct{ items []T }
+
+func (s *Stack[T]) Push(item T) { s.items = append(s.items, item) }
+
+func Helper() {}
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	for id, expected := range map[string]string{
+		"(*Store).Load": "example.com/app/svc::Store",
+		"Store.Len":     "example.com/app/svc::Store",
+		"Push":          "example.com/app/svc::Stack",
+		"Helper":        "",
+	} {
+		node := result.Nodes["example.com/app/svc::"+id]
+		if node == nil {
+			t.Fatalf("Missing node %s", id)
+		}
+		if node.Receiver != expected {
+			t.Errorf("%s: expected receiver %q, got %q", id, expected, node.Receiver)
+		}
+		if node.Module != "example.com/app" {
+			t.Errorf("%s: expected module example.com/app, got %q", id, node.Module)
+		}
+	}
+}
+
+func Test_Analyzer_EntryPoints(t *testing.T) {
+	files := map[string]string{
+		"cmd/app/main.go": `package main
+
+import "example.com/app/lib"
+
+func main() { lib.Run() }
+`,
+		"lib/lib.go": `package lib
+
+// main is an ordinary function outside of main packages
+func main() {}
+
+func Run() { main() }
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	if name := result.Nodes["example.com/app/lib::main"].PackageName; name != "lib" {
+		t.Errorf("Expected package name lib, got %q", name)
+	}
+	expected := []string{"example.com/app/cmd/app::main"}
+	if entries := result.EntryPoints(); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("EntryPoints() = %v, want %v", entries, expected)
+	}
+}
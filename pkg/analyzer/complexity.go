@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// cyclomaticComplexity returns the cyclomatic complexity of a function: one plus the number of
+// decision points in its body. Decision points are if, for and range statements, non-default
+// case and select clauses, and the && and || operators. Function literals count towards the
+// function that contains them.
+func cyclomaticComplexity(fn *ast.FuncDecl) int {
+	complexity := 1
+	if fn.Body == nil {
+		return complexity
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if x.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if x.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if x.Op == token.LAND || x.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// linesOfCode returns the number of lines spanned by a function declaration, from the func
+// keyword to the closing brace. The doc comment is not counted.
+func linesOfCode(fset *token.FileSet, fn *ast.FuncDecl) int {
+	return fset.Position(fn.End()).Line - fset.Position(fn.Pos()).Line + 1
+}
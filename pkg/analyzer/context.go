@@ -0,0 +1,19 @@
+package analyzer
+
+import "go/types"
+
+// takesContext reports whether any parameter of the function has the type context.Context
+func takesContext(sig *types.Signature) bool {
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		named, ok := types.Unalias(params.At(i).Type()).(*types.Named)
+		if !ok {
+			continue
+		}
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context" {
+			return true
+		}
+	}
+	return false
+}
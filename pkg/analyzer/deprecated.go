@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"go/ast"
+	"slices"
+	"strings"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// deprecationNotice returns the deprecation notice of a doc comment: by convention, a
+// paragraph starting with "Deprecated: ". The notice is returned without that prefix,
+// joined into a single line.
+func deprecationNotice(doc string) string {
+	for _, paragraph := range strings.Split(doc, "\n\n") {
+		if notice, ok := strings.CutPrefix(strings.TrimSpace(paragraph), "Deprecated: "); ok {
+			return strings.Join(strings.Fields(notice), " ")
+		}
+	}
+	return ""
+}
+
+// addDeprecatedUse records the line of the identifier using a deprecated symbol in the uses
+// attribute of the edge, once per line also across package variants
+func (a *Analyzer) addDeprecatedUse(pkg *packages.Package, ident *ast.Ident, sourceID, targetID string) {
+	line := pkg.Fset.Position(ident.Pos()).Line
+	lines := a.graph.EdgeUses(sourceID, targetID)
+	if slices.Contains(lines, line) {
+		return
+	}
+	lines = append(slices.Clone(lines), line)
+	slices.Sort(lines)
+	a.graph.SetEdgeAttr(sourceID, targetID, graph.UsesAttr, lines)
+}
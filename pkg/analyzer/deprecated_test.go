@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"slices"
+	"testing"
+)
+
+func Test_deprecationNotice(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{"none", "Open opens the database.\n", ""},
+		{"own paragraph", "Open opens the database.\n\nDeprecated: Use Connect instead,\nwhich pools connections.\n", "Use Connect instead, which pools connections."},
+		{"whole comment", "Deprecated: Use Connect.\n", "Use Connect."},
+		{"not a paragraph start", "Open opens the database. Deprecated: no.\n", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deprecationNotice(tt.doc); got != tt.expected {
+				t.Errorf("deprecationNotice(%q) = %q, want %q", tt.doc, got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_Analyzer_Deprecated(t *testing.T) {
+	files := map[string]string{
+		"db/db.go": `package db
+
+// Conn is a connection.
+//
+// Deprecated: Use Pool.
+type Conn struct{}
+
+// Open opens a connection.
+//
+// Deprecated: Use Connect.
+func Open() *Conn { return nil }
+
+func Connect() {}
+`,
+		"app/app.go": `package app
+
+import "example.com/app/db"
+
+func Run() {
+	db.Open()
+	db.Connect()
+	db.Open()
+}
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	expected := map[string]string{
+		"Conn":    "Use Pool.",
+		"Open":    "Use Connect.",
+		"Connect": "",
+	}
+	for name, notice := range expected {
+		if got := result.Nodes["example.com/app/db::"+name].Deprecated; got != notice {
+			t.Errorf("%s: expected deprecation %q, got %q", name, notice, got)
+		}
+	}
+
+	// Each use is recorded at its own line, not at Run's declaration
+	if uses := result.EdgeUses("example.com/app/app::Run", "example.com/app/db::Open"); !slices.Equal(uses, []int{6, 8}) {
+		t.Errorf("Expected the uses of Open at lines 6 and 8, got %v", uses)
+	}
+	if uses := result.EdgeUses("example.com/app/app::Run", "example.com/app/db::Connect"); uses != nil {
+		t.Errorf("Expected no uses recorded for a symbol that is not deprecated, got %v", uses)
+	}
+}
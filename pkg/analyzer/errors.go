@@ -0,0 +1,17 @@
+package analyzer
+
+import "go/types"
+
+// errorType is the predeclared error interface
+var errorType = types.Universe.Lookup("error").Type()
+
+// returnsError reports whether any result of the function has the type error
+func returnsError(sig *types.Signature) bool {
+	results := sig.Results()
+	for i := 0; i < results.Len(); i++ {
+		if types.Identical(results.At(i).Type(), errorType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"go/types"
+
+	"go-depmap/pkg/graph"
+)
+
+// ExternalOptions selects the third-party symbols that become nodes. By default, uses of
+// symbols outside the project are dropped; standard library packages are never included.
+type ExternalOptions struct {
+	Enabled  bool     // Add nodes for the third-party symbols used by project functions
+	Packages []string // Package patterns to include, e.g. github.com/aws/...; all third-party packages when empty
+}
+
+// Includes reports whether uses of symbols from the package are recorded
+func (o ExternalOptions) Includes(pkgPath string) bool {
+	if !o.Enabled || !graph.IsPublicPackagePath(pkgPath) {
+		return false
+	}
+	if len(o.Packages) == 0 {
+		return true
+	}
+	for _, pattern := range o.Packages {
+		if graph.MatchPackagePattern(pattern, pkgPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// externalTarget returns the node of a third-party symbol used by the project, when external
+// symbols are included. Only functions, methods and type names become nodes.
+func (a *Analyzer) externalTarget(obj types.Object) (*graph.Node, bool) {
+	if obj.Pkg() == nil || !a.options.External.Includes(obj.Pkg().Path()) {
+		return nil, false
+	}
+	switch x := obj.(type) {
+	case *types.Func:
+		return a.externalNode(x.Origin()), true
+	case *types.TypeName:
+		return a.externalNode(x), true
+	}
+	return nil, false
+}
+
+// externalNode returns the node for a function, method or type defined outside the project,
+// creating it if needed
+func (a *Analyzer) externalNode(obj types.Object) *graph.Node {
+	name, kind := externalName(obj)
+	id := obj.Pkg().Path() + "::" + name
+	if node, exists := a.graph.Nodes[id]; exists {
+		return node
+	}
+
+	node := &graph.Node{
+		ID:          id,
+		Name:        name,
+		Kind:        kind,
+		Package:     obj.Pkg().Path(),
+		PackageName: obj.Pkg().Name(),
+		Signature:   obj.Type().String(),
+		External:    true,
+		DocURL:      graph.PkgGoDevURL(obj.Pkg().Path(), name),
+	}
+	if fn, ok := obj.(*types.Func); ok {
+		node.Receiver = receiverID(fn.Type().(*types.Signature))
+	}
+	a.graph.Nodes[id] = node
+	return node
+}
+
+// externalName names an external symbol the way the analyzer names project symbols:
+// methods are qualified by their receiver, as T.M or (*T).M
+func externalName(obj types.Object) (string, graph.NodeKind) {
+	switch x := obj.(type) {
+	case *types.TypeName:
+		if x.IsAlias() {
+			return x.Name(), graph.KindAlias
+		}
+		return x.Name(), typeKind(x.Type())
+	case *types.Func:
+		recv := x.Type().(*types.Signature).Recv()
+		if recv == nil {
+			return x.Name(), graph.KindFunction
+		}
+		recvType, pointer := types.Unalias(recv.Type()), false
+		if ptr, ok := recvType.(*types.Pointer); ok {
+			recvType, pointer = types.Unalias(ptr.Elem()), true
+		}
+		named, ok := recvType.(*types.Named)
+		if !ok {
+			return x.Name(), graph.KindMethod
+		}
+		if pointer {
+			return "(*" + named.Obj().Name() + ")." + x.Name(), graph.KindMethod
+		}
+		return named.Obj().Name() + "." + x.Name(), graph.KindMethod
+	}
+	return obj.Name(), graph.KindType
+}
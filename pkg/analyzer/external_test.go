@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+// externalTestFiles is a project using a third-party module, replaced by a local directory
+var externalTestFiles = map[string]string{
+	"go.mod":     "module example.com/app\n\ngo 1.21\n\nrequire example.org/sdk v0.0.0\n\nreplace example.org/sdk => ./sdk\n",
+	"sdk/go.mod": "module example.org/sdk\n\ngo 1.21\n",
+	"sdk/s3/s3.go": `package s3
+
+type Client struct{}
+
+func New() *Client { return &Client{} }
+
+func (c *Client) Put(key string) error { return nil }
+`,
+	"sdk/log/log.go": `package log
+
+func Print(msg string) {}
+`,
+	"store/store.go": `package store
+
+import (
+	"fmt"
+
+	"example.org/sdk/log"
+	"example.org/sdk/s3"
+)
+
+func Save(c *s3.Client, key string) error {
+	log.Print(fmt.Sprint(key))
+	return c.Put(key)
+}
+`,
+}
+
+func Test_Analyzer_External(t *testing.T) {
+	pkgs := loadSource(t, externalTestFiles, false)
+
+	tests := []struct {
+		name     string
+		options  ExternalOptions
+		expected []string
+	}{
+		{
+			name:     "disabled",
+			options:  ExternalOptions{},
+			expected: nil,
+		},
+		{
+			name:     "all third-party packages",
+			options:  ExternalOptions{Enabled: true},
+			expected: []string{"example.org/sdk/s3::Client", "example.org/sdk/s3::(*Client).Put", "example.org/sdk/log::Print"},
+		},
+		{
+			name:     "pattern",
+			options:  ExternalOptions{Enabled: true, Packages: []string{"example.org/sdk/s3/..."}},
+			expected: []string{"example.org/sdk/s3::Client", "example.org/sdk/s3::(*Client).Put"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.External = tt.options
+			g := NewWithOptions(pkgs, options).Analyze()
+
+			targets := g.Edges["example.com/app/store::Save"]
+			if len(targets) != len(tt.expected) {
+				t.Fatalf("Expected edges to %v, got %v", tt.expected, targets)
+			}
+			for _, id := range tt.expected {
+				if !g.HasEdge("example.com/app/store::Save", id) {
+					t.Errorf("Missing edge to %s", id)
+				}
+				if node := g.Nodes[id]; node == nil || !node.External {
+					t.Errorf("Expected an external node %s, got %+v", id, node)
+				}
+			}
+		})
+	}
+
+	g := NewWithOptions(pkgs, Options{External: ExternalOptions{Enabled: true}}).Analyze()
+	if node := g.Nodes["example.org/sdk/s3::(*Client).Put"]; node.Kind != graph.KindMethod {
+		t.Errorf("Expected a method node, got %+v", node)
+	}
+	if _, ok := g.Nodes["fmt::Sprint"]; ok {
+		t.Error("Standard library symbols should not be included")
+	}
+}
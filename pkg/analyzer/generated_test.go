@@ -0,0 +1,46 @@
+package analyzer
+
+import "testing"
+
+func Test_Analyzer_Generated(t *testing.T) {
+	files := map[string]string{
+		"api/api.pb.go": `// Code generated by protoc-gen-go. DO NOT EDIT.
+
+package api
+
+type Request struct{}
+
+type Alias = Request
+
+func (r *Request) Reset() {}
+`,
+		"api/server.go": `package api
+
+func Handle(r *Request) { r.Reset() }
+`,
+	}
+	pkgs := loadSource(t, files, false)
+
+	tagged := New(pkgs).Analyze()
+	for _, name := range []string{"Request", "Alias", "(*Request).Reset"} {
+		if node := tagged.Nodes["example.com/app/api::"+name]; node == nil || !node.Generated {
+			t.Errorf("Expected %s to be tagged as generated, got %+v", name, node)
+		}
+	}
+	if tagged.Nodes["example.com/app/api::Handle"].Generated {
+		t.Error("Handle is not generated")
+	}
+	if !tagged.HasEdge("example.com/app/api::Handle", "example.com/app/api::Request") {
+		t.Error("Dependencies on generated symbols should be kept when tagging")
+	}
+
+	options := DefaultOptions()
+	options.ExcludeGenerated = true
+	excluded := NewWithOptions(pkgs, options).Analyze()
+	if len(excluded.Nodes) != 1 || excluded.Nodes["example.com/app/api::Handle"] == nil {
+		t.Errorf("Expected only Handle, got %v", excluded.Nodes)
+	}
+	if len(excluded.Edges["example.com/app/api::Handle"]) != 0 {
+		t.Errorf("Expected no edges to excluded symbols, got %v", excluded.Edges)
+	}
+}
@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// addGlobals registers the nodes of the package-level variables of a var declaration. Local
+// variable declarations, found while walking function bodies, are ignored.
+func (a *Analyzer) addGlobals(pkg *packages.Package, decl *ast.GenDecl, tags fileTags) {
+	for _, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		doc := valueSpec.Doc.Text()
+		if doc == "" && !decl.Lparen.IsValid() {
+			doc = decl.Doc.Text()
+		}
+		for _, name := range valueSpec.Names {
+			obj := pkg.TypesInfo.Defs[name]
+			if obj == nil || name.Name == "_" || obj.Parent() != pkg.Types.Scope() {
+				continue
+			}
+			node := graph.CreateNode(pkg, obj, name.Name, graph.KindVar, obj.Type().String())
+			node.Doc = doc
+			tags.apply(node)
+			a.addNode(pkg, obj, node)
+		}
+	}
+}
+
+// isGlobal reports whether the object is a package-level variable of the project
+func (a *Analyzer) isGlobal(obj types.Object) bool {
+	if _, ok := obj.(*types.Var); !ok {
+		return false
+	}
+	node, ok := a.projectNode(obj)
+	return ok && node.Kind == graph.KindVar
+}
+
+// accessKind classifies a use of a variable as a read or a write
+func accessKind(ident *ast.Ident, written map[*ast.Ident]bool) graph.EdgeKind {
+	if written[ident] {
+		return graph.EdgeWrites
+	}
+	return graph.EdgeReads
+}
+
+// writtenIdents returns the identifiers of the variables that are modified: assigned to
+// (also through a field, index or pointer), incremented, decremented, passed by address, or
+// used as the receiver of a pointer method
+func writtenIdents(info *types.Info, root ast.Node) map[*ast.Ident]bool {
+	written := make(map[*ast.Ident]bool)
+	mark := func(expr ast.Expr) {
+		if ident := rootIdent(info, expr); ident != nil {
+			written[ident] = true
+		}
+	}
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.AssignStmt:
+			if x.Tok != token.DEFINE {
+				for _, lhs := range x.Lhs {
+					mark(lhs)
+				}
+			}
+		case *ast.IncDecStmt:
+			mark(x.X)
+		case *ast.UnaryExpr:
+			if x.Op == token.AND {
+				mark(x.X)
+			}
+		case *ast.RangeStmt:
+			if x.Tok == token.ASSIGN {
+				if x.Key != nil {
+					mark(x.Key)
+				}
+				if x.Value != nil {
+					mark(x.Value)
+				}
+			}
+		case *ast.SelectorExpr:
+			// Pointer methods may modify their receiver, e.g. mu.Lock()
+			if sel, ok := info.Selections[x]; ok && sel.Kind() == types.MethodVal && pointerReceiver(sel.Obj()) {
+				mark(x.X)
+			}
+		}
+		return true
+	})
+	return written
+}
+
+// pointerReceiver reports whether a method is declared on a pointer receiver
+func pointerReceiver(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	_, isPointer := recv.Type().(*types.Pointer)
+	return isPointer
+}
+
+// rootIdent returns the variable identifier an expression modifies, looking through field
+// selectors, indexing, dereferences and parentheses: counter in counter.hits[key]. Writes
+// through a pointer or into a slice modify state shared through the variable, so they count
+// as writes too. Qualified identifiers (pkg.Var) resolve to the variable.
+func rootIdent(info *types.Info, expr ast.Expr) *ast.Ident {
+	for {
+		switch x := expr.(type) {
+		case *ast.Ident:
+			return x
+		case *ast.ParenExpr:
+			expr = x.X
+		case *ast.IndexExpr:
+			expr = x.X
+		case *ast.StarExpr:
+			expr = x.X
+		case *ast.SelectorExpr:
+			if pkgIdent, ok := x.X.(*ast.Ident); ok {
+				if _, isPkg := info.Uses[pkgIdent].(*types.PkgName); isPkg {
+					return x.Sel
+				}
+			}
+			expr = x.X
+		default:
+			return nil
+		}
+	}
+}
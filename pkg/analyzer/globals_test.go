@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"slices"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_Analyzer_Globals(t *testing.T) {
+	files := map[string]string{
+		"state/state.go": `package state
+
+import "sync"
+
+// Count is the number of requests served
+var Count int
+
+var (
+	mu    sync.Mutex
+	names = map[string]int{}
+	_     = Count
+)
+
+func Inc() {
+	mu.Lock()
+	defer mu.Unlock()
+	Count++
+	names["x"] = 1
+}
+
+func Get() int {
+	local := Count
+	return local
+}
+`,
+		"web/web.go": `package web
+
+import "example.com/app/state"
+
+func Reset() { state.Count = 0 }
+
+func Peek() int { return state.Get() + state.Count }
+
+func Ptr() *int { return &state.Count }
+`,
+	}
+
+	options := DefaultOptions()
+	options.Globals = true
+	result := NewWithOptions(loadSource(t, files, false), options).Analyze()
+
+	count := result.Nodes["example.com/app/state::Count"]
+	if count == nil || count.Kind != graph.KindVar {
+		t.Fatalf("Expected a var node for Count, got %+v", count)
+	}
+	if count.Doc == "" {
+		t.Errorf("Expected the doc comment of Count to be kept")
+	}
+	if _, exists := result.Nodes["example.com/app/state::_"]; exists {
+		t.Errorf("Blank variables should not become nodes")
+	}
+
+	tests := []struct {
+		source, target string
+		kind           graph.EdgeKind
+	}{
+		{"example.com/app/state::Inc", "example.com/app/state::Count", graph.EdgeWrites},
+		{"example.com/app/state::Inc", "example.com/app/state::mu", graph.EdgeWrites},
+		{"example.com/app/state::Inc", "example.com/app/state::names", graph.EdgeWrites},
+		{"example.com/app/state::Get", "example.com/app/state::Count", graph.EdgeReads},
+		{"example.com/app/web::Reset", "example.com/app/state::Count", graph.EdgeWrites},
+		{"example.com/app/web::Peek", "example.com/app/state::Count", graph.EdgeReads},
+		{"example.com/app/web::Ptr", "example.com/app/state::Count", graph.EdgeWrites},
+	}
+	for _, tt := range tests {
+		if got := result.EdgeKindsOf(tt.source, tt.target); !slices.Contains(got, tt.kind) {
+			t.Errorf("Expected %s -> %s to be %s, got %v", tt.source, tt.target, tt.kind, got)
+		}
+	}
+
+	without := New(loadSource(t, files, false)).Analyze()
+	if _, exists := without.Nodes["example.com/app/state::Count"]; exists {
+		t.Errorf("Variables should only become nodes with the globals option")
+	}
+}
@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"path/filepath"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// initName qualifies the n-th init function of a file by the file name, as init functions
+// cannot be referred to and share the name init
+func initName(filename string, n int) string {
+	name := "init@" + filepath.Base(filename)
+	if n > 1 {
+		name += fmt.Sprintf("#%d", n)
+	}
+	return name
+}
+
+// addInit records an init function declared in a variant of a package. Files are scanned in
+// the order of CompiledGoFiles, the order the compiler initializes them in, so the list of a
+// variant is in execution order. With tests, a package has several variants: its list is
+// rebuilt from the one with the most files, the test variant, whose _test.go files follow
+// the others, rather than appended to across variants.
+func (a *Analyzer) addInit(pkg *packages.Package, node *graph.Node) {
+	if variant := a.initVariants[pkg.PkgPath]; variant != pkg {
+		if variant != nil && len(variant.Syntax) >= len(pkg.Syntax) {
+			return
+		}
+		a.initVariants[pkg.PkgPath] = pkg
+		a.inits[pkg.PkgPath] = nil
+	}
+	a.inits[pkg.PkgPath] = append(a.inits[pkg.PkgPath], node)
+}
+
+// linkInits adds init-order edges, which chain the init functions in the order they run:
+// each init function depends on the previous one of its package, and the first one of a
+// package on the last one of each package it imports. Imported packages without init
+// functions are looked through, so that chains are not broken by them.
+func (a *Analyzer) linkInits() {
+	for _, pkg := range a.packages {
+		if skipPackage(pkg) {
+			continue
+		}
+		inits := a.inits[pkg.PkgPath]
+		if len(inits) == 0 {
+			continue
+		}
+		for i := 1; i < len(inits); i++ {
+			a.graph.AddEdge(inits[i].ID, inits[i-1].ID, graph.EdgeInitOrder)
+		}
+		for _, last := range a.importedInits(pkg.Types, make(map[string]bool)) {
+			a.graph.AddEdge(inits[0].ID, last.ID, graph.EdgeInitOrder)
+		}
+	}
+}
+
+// importedInits returns the last init function of each project package imported by pkg,
+// looking through imported project packages that have none
+func (a *Analyzer) importedInits(pkg *types.Package, visited map[string]bool) []*graph.Node {
+	var result []*graph.Node
+	for _, imported := range pkg.Imports() {
+		path := imported.Path()
+		if visited[path] || !a.inModule(path) {
+			continue
+		}
+		visited[path] = true
+		if inits := a.inits[path]; len(inits) > 0 {
+			result = append(result, inits[len(inits)-1])
+		} else {
+			result = append(result, a.importedInits(imported, visited)...)
+		}
+	}
+	return result
+}
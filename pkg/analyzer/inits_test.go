@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_Analyzer_Inits(t *testing.T) {
+	files := map[string]string{
+		"config/a.go": `package config
+
+var loaded bool
+
+func init() { load() }
+
+func init() {}
+
+func load() { loaded = true }
+`,
+		"config/b.go": `package config
+
+func init() {}
+`,
+		"plain/plain.go": `package plain
+
+import _ "example.com/app/config"
+`,
+		"server/server.go": `package server
+
+import _ "example.com/app/plain"
+
+func init() {}
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	for _, id := range []string{
+		"example.com/app/config::init@a.go",
+		"example.com/app/config::init@a.go#2",
+		"example.com/app/config::init@b.go",
+		"example.com/app/server::init@server.go",
+	} {
+		if node := result.Nodes[id]; node == nil || node.Kind != graph.KindInit {
+			t.Errorf("Expected init node %s, got %+v", id, node)
+		}
+	}
+	if _, ok := result.Nodes["example.com/app/config::init"]; ok {
+		t.Error("init functions should not share an ID")
+	}
+
+	expected := [][2]string{
+		{"example.com/app/config::init@a.go#2", "example.com/app/config::init@a.go"},
+		{"example.com/app/config::init@b.go", "example.com/app/config::init@a.go#2"},
+		// plain has no init function, so server's runs right after config's last one
+		{"example.com/app/server::init@server.go", "example.com/app/config::init@b.go"},
+	}
+	for _, edge := range expected {
+		kinds := result.EdgeKindsOf(edge[0], edge[1])
+		if len(kinds) != 1 || kinds[0] != graph.EdgeInitOrder {
+			t.Errorf("Expected an init-order edge %s -> %s, got %v", edge[0], edge[1], kinds)
+		}
+	}
+	if !result.HasEdge("example.com/app/config::init@a.go", "example.com/app/config::load") {
+		t.Error("Dependencies of init functions should be recorded")
+	}
+}
+
+func Test_Analyzer_Inits_TestVariant(t *testing.T) {
+	// go test compiles the test files of a package after its other files
+	files := map[string]string{
+		"config/a.go": `package config
+
+func init() {}
+`,
+		"config/m_test.go": `package config
+
+func init() {}
+`,
+		"config/z.go": `package config
+
+func init() {}
+`,
+	}
+
+	result := New(loadSource(t, files, true)).Analyze()
+
+	expected := [][2]string{
+		{"example.com/app/config::init@z.go", "example.com/app/config::init@a.go"},
+		{"example.com/app/config::init@m_test.go", "example.com/app/config::init@z.go"},
+	}
+	for _, edge := range expected {
+		if kinds := result.EdgeKindsOf(edge[0], edge[1]); len(kinds) != 1 || kinds[0] != graph.EdgeInitOrder {
+			t.Errorf("Expected an init-order edge %s -> %s, got %v", edge[0], edge[1], kinds)
+		}
+	}
+	if edges := len(result.Edges["example.com/app/config::init@m_test.go"]); edges != 1 {
+		t.Errorf("Expected the init functions in a single chain, got %v", result.Edges)
+	}
+}
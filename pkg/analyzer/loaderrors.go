@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// SplitBroken separates the packages that loaded cleanly from those with errors, for analyses
+// that continue past broken packages. It returns the healthy packages and the errors of all
+// loaded packages, including dependencies, in load order.
+func SplitBroken(pkgs []*packages.Package) ([]*packages.Package, []graph.LoadError) {
+	healthy := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 {
+			healthy = append(healthy, pkg)
+		}
+	}
+
+	loadErrors := make([]graph.LoadError, 0)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			loadErrors = append(loadErrors, graph.LoadError{
+				Package:  pkg.PkgPath,
+				Kind:     loadErrorKind(err.Kind),
+				Position: err.Pos,
+				Message:  err.Msg,
+			})
+		}
+	})
+	return healthy, loadErrors
+}
+
+// loadErrorKind names the kind of a go/packages error
+func loadErrorKind(kind packages.ErrorKind) string {
+	switch kind {
+	case packages.ListError:
+		return "list"
+	case packages.ParseError:
+		return "parse"
+	case packages.TypeError:
+		return "type"
+	}
+	return "unknown"
+}
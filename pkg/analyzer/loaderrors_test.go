@@ -0,0 +1,39 @@
+package analyzer
+
+import "testing"
+
+func Test_SplitBroken(t *testing.T) {
+	pkgs := loadSourceWithErrors(t, map[string]string{
+		"good/good.go": `package good
+
+func Run() int { return helper() }
+
+func helper() int { return 1 }
+`,
+		"bad/bad.go": `package bad
+
+func Broken() int { return undefined }
+`,
+	}, false)
+
+	healthy, loadErrors := SplitBroken(pkgs)
+
+	if len(healthy) != 1 || healthy[0].PkgPath != "example.com/app/good" {
+		t.Fatalf("Expected only the good package to be healthy, got %v", healthy)
+	}
+	if len(loadErrors) != 1 {
+		t.Fatalf("Expected one load error, got %+v", loadErrors)
+	}
+	loadError := loadErrors[0]
+	if loadError.Package != "example.com/app/bad" || loadError.Kind != "type" || loadError.Position == "" {
+		t.Errorf("Unexpected load error: %+v", loadError)
+	}
+
+	g := NewWithOptions(healthy, DefaultOptions()).Analyze()
+	if _, ok := g.Nodes["example.com/app/good::Run"]; !ok {
+		t.Error("The healthy package should be analyzed")
+	}
+	if _, ok := g.Nodes["example.com/app/bad::Broken"]; ok {
+		t.Error("The broken package should be left out")
+	}
+}
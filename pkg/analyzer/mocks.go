@@ -0,0 +1,32 @@
+package analyzer
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultMockPatterns are the file and package patterns of common mock generators:
+// mockgen's mock_*.go, mockery's mocks/ packages and hand-written *_mock.go files
+var DefaultMockPatterns = []string{"*_mock.go", "mock_*.go", "mocks/"}
+
+// MockOptions selects the files holding mocks and test doubles, whose symbols are tagged as mocks
+type MockOptions struct {
+	// Patterns ending in / match package directories anywhere in the import path, e.g. mocks/
+	// or internal/fakes/; other patterns match file names, e.g. *_mock.go
+	Patterns []string
+	Exclude  bool // Leave out the symbols of mock files instead of tagging them
+}
+
+// Matches reports whether a file of the package holds mocks
+func (o MockOptions) Matches(pkgPath, filename string) bool {
+	for _, pattern := range o.Patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+			if strings.Contains("/"+pkgPath+"/", "/"+dir+"/") {
+				return true
+			}
+		} else if matched, _ := path.Match(pattern, filename); matched {
+			return true
+		}
+	}
+	return false
+}
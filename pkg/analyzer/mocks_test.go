@@ -0,0 +1,70 @@
+package analyzer
+
+import "testing"
+
+func TestMockOptions_Matches(t *testing.T) {
+	options := MockOptions{Patterns: []string{"*_mock.go", "mock_*.go", "mocks/", "internal/fakes/"}}
+
+	tests := []struct {
+		pkgPath  string
+		filename string
+		expected bool
+	}{
+		{"example.com/app/db", "db_mock.go", true},
+		{"example.com/app/db", "mock_db.go", true},
+		{"example.com/app/db", "db.go", false},
+		{"example.com/app/mocks", "db.go", true},
+		{"example.com/app/db/mocks", "db.go", true},
+		{"example.com/app/mocksupport", "db.go", false},
+		{"example.com/app/internal/fakes", "clock.go", true},
+		{"example.com/app/fakes", "clock.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := options.Matches(tt.pkgPath, tt.filename); got != tt.expected {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.pkgPath, tt.filename, got, tt.expected)
+		}
+	}
+}
+
+func Test_Analyzer_Mocks(t *testing.T) {
+	files := map[string]string{
+		"db/db.go": `package db
+
+type Store interface{ Get() int }
+
+func Open() Store { return &FakeStore{} }
+`,
+		"db/store_mock.go": `package db
+
+type FakeStore struct{}
+
+func (f *FakeStore) Get() int { return 0 }
+`,
+		"db/mocks/mocks.go": `package mocks
+
+type Clock struct{}
+`,
+	}
+	pkgs := loadSource(t, files, false)
+
+	tagged := New(pkgs).Analyze()
+	for id, mock := range map[string]bool{
+		"example.com/app/db::Store":            false,
+		"example.com/app/db::Open":             false,
+		"example.com/app/db::FakeStore":        true,
+		"example.com/app/db::(*FakeStore).Get": true,
+		"example.com/app/db/mocks::Clock":      true,
+	} {
+		if node := tagged.Nodes[id]; node == nil || node.Mock != mock {
+			t.Errorf("Expected %s to have mock %v, got %+v", id, mock, node)
+		}
+	}
+
+	options := DefaultOptions()
+	options.Mocks.Exclude = true
+	excluded := NewWithOptions(pkgs, options).Analyze()
+	if len(excluded.Nodes) != 2 {
+		t.Errorf("Expected only Store and Open, got %v", excluded.Nodes)
+	}
+}
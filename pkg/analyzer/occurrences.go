@@ -0,0 +1,35 @@
+package analyzer
+
+import (
+	"go/token"
+	"path/filepath"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// addOccurrence records the definition or use of a symbol by the identifier of the given
+// length at pos, with Options.Occurrences. The duplicates recorded by several variants of a
+// package are removed by graph.SortOccurrences once the analysis is done.
+func (a *Analyzer) addOccurrence(pkg *packages.Package, pos token.Pos, length int, node *graph.Node, definition bool) {
+	if !a.options.Occurrences || node.Kind == graph.KindPackage || !pos.IsValid() {
+		return
+	}
+	position := pkg.Fset.Position(pos)
+	path := filepath.Base(position.Filename)
+	if pkg.Module != nil && pkg.Module.Dir != "" {
+		if rel, err := filepath.Rel(pkg.Module.Dir, position.Filename); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+	}
+	a.graph.Occurrences = append(a.graph.Occurrences, graph.Occurrence{
+		Symbol:     node.ID,
+		Package:    pkg.PkgPath,
+		Path:       path,
+		Line:       position.Line,
+		Column:     position.Column,
+		EndColumn:  position.Column + length,
+		Definition: definition,
+	})
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_Analyzer_Occurrences(t *testing.T) {
+	files := map[string]string{
+		"db/db.go": `package db
+
+type Conn struct{}
+
+func Open() *Conn { return &Conn{} }
+`,
+		"db/db_test.go": `package db
+
+import "testing"
+
+func TestOpen(t *testing.T) { Open() }
+`,
+		"web/web.go": `package web
+
+import "example.com/app/db"
+
+func Serve() { _ = db.Open() }
+`,
+	}
+
+	options := DefaultOptions()
+	options.Occurrences = true
+	result := NewWithOptions(loadSource(t, files, true), options).Analyze()
+
+	expected := []graph.Occurrence{
+		{Symbol: "example.com/app/db::Conn", Package: "example.com/app/db", Path: "db/db.go", Line: 3, Column: 6, EndColumn: 10, Definition: true},
+		{Symbol: "example.com/app/db::Open", Package: "example.com/app/db", Path: "db/db.go", Line: 5, Column: 6, EndColumn: 10, Definition: true},
+		{Symbol: "example.com/app/db::Conn", Package: "example.com/app/db", Path: "db/db.go", Line: 5, Column: 14, EndColumn: 18},
+		{Symbol: "example.com/app/db::Conn", Package: "example.com/app/db", Path: "db/db.go", Line: 5, Column: 29, EndColumn: 33},
+		{Symbol: "example.com/app/db::TestOpen", Package: "example.com/app/db", Path: "db/db_test.go", Line: 5, Column: 6, EndColumn: 14, Definition: true},
+		{Symbol: "example.com/app/db::Open", Package: "example.com/app/db", Path: "db/db_test.go", Line: 5, Column: 31, EndColumn: 35},
+		{Symbol: "example.com/app/web::Serve", Package: "example.com/app/web", Path: "web/web.go", Line: 5, Column: 6, EndColumn: 11, Definition: true},
+		{Symbol: "example.com/app/db::Open", Package: "example.com/app/web", Path: "web/web.go", Line: 5, Column: 23, EndColumn: 27},
+	}
+	if len(result.Occurrences) != len(expected) {
+		t.Fatalf("Expected %d occurrences, once despite the test variant of db, got %d: %+v", len(expected), len(result.Occurrences), result.Occurrences)
+	}
+	for i, want := range expected {
+		if result.Occurrences[i] != want {
+			t.Errorf("Occurrence %d = %+v, want %+v", i, result.Occurrences[i], want)
+		}
+	}
+
+	if plain := New(loadSource(t, files, false)).Analyze(); len(plain.Occurrences) != 0 {
+		t.Errorf("Expected no occurrences without the option, got %d", len(plain.Occurrences))
+	}
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import "golang.org/x/tools/go/packages"
+
+// Analysis phases, in order. All are listed by Timings; the subgraphs phase, which does not
+// go through packages, is not reported to Options.Progress.
+const (
+	PhaseDefinitions  = "definitions"  // Collecting the definitions of functions, types and variables
+	PhaseDependencies = "dependencies" // Walking function signatures and bodies
+	PhaseSubgraphs    = "subgraphs"    // Marking recursion, computing and scoring subgraphs
+)
+
+// Progress reports how far an analysis phase has come. A phase is complete when Packages
+// reaches Total.
+type Progress struct {
+	Phase    string // PhaseDefinitions or PhaseDependencies
+	Packages int    // Packages of the phase done so far
+	Total    int    // Packages of the phase
+	Files    int    // Files scanned by the phase so far
+}
+
+// startProgress reports the start of a phase over packages and returns its progress, to be
+// advanced with packageDone
+func (a *Analyzer) startProgress(phase string, pkgs []*packages.Package) *Progress {
+	progress := &Progress{Phase: phase}
+	for _, pkg := range pkgs {
+		if !skipPackage(pkg) {
+			progress.Total++
+		}
+	}
+	a.reportProgress(*progress)
+	return progress
+}
+
+// packageDone advances the progress of a phase by one package and reports it
+func (a *Analyzer) packageDone(progress *Progress, files int) {
+	progress.Packages++
+	progress.Files += files
+	a.reportProgress(*progress)
+}
+
+// reportProgress calls the progress callback, if any
+func (a *Analyzer) reportProgress(progress Progress) {
+	if a.options.Progress != nil {
+		a.options.Progress(progress)
+	}
+}
@@ -0,0 +1,33 @@
+package analyzer
+
+import "testing"
+
+func Test_Analyzer_Progress(t *testing.T) {
+	files := map[string]string{
+		"a/a.go": "package a\n\nfunc A() {}\n",
+		"a/b.go": "package a\n\nfunc B() { A() }\n",
+		"c/c.go": "package c\n\nimport \"example.com/app/a\"\n\nfunc C() { a.B() }\n",
+	}
+
+	var reports []Progress
+	options := DefaultOptions()
+	options.Progress = func(p Progress) { reports = append(reports, p) }
+	NewWithOptions(loadSource(t, files, false), options).Analyze()
+
+	last := make(map[string]Progress)
+	for _, p := range reports {
+		if previous, seen := last[p.Phase]; seen && p.Packages != previous.Packages+1 {
+			t.Errorf("Expected %s progress to advance one package at a time, got %+v after %+v", p.Phase, p, previous)
+		}
+		last[p.Phase] = p
+	}
+	for _, phase := range []string{PhaseDefinitions, PhaseDependencies} {
+		want := Progress{Phase: phase, Packages: 2, Total: 2, Files: 3}
+		if last[phase] != want {
+			t.Errorf("Expected the %s phase to end at %+v, got %+v", phase, want, last[phase])
+		}
+	}
+	if reports[0].Phase != PhaseDefinitions || reports[0].Packages != 0 {
+		t.Errorf("Expected the start of the definitions phase first, got %+v", reports[0])
+	}
+}
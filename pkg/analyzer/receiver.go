@@ -0,0 +1,21 @@
+package analyzer
+
+import "go/types"
+
+// receiverID returns the node ID of the receiver type of a method, the way the analyzer
+// identifies types, or "" for functions
+func receiverID(sig *types.Signature) string {
+	recv := sig.Recv()
+	if recv == nil {
+		return ""
+	}
+	recvType := types.Unalias(recv.Type())
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		recvType = types.Unalias(ptr.Elem())
+	}
+	named, ok := recvType.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+	return named.Obj().Pkg().Path() + "::" + named.Obj().Name()
+}
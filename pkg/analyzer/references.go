@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// calledIdents returns the identifiers that name the function of a call expression, e.g.
+// handler in handler(w, r), Handle in s.Handle(w, r) and Map in Map[int](xs)
+func calledIdents(root ast.Node) map[*ast.Ident]bool {
+	called := make(map[*ast.Ident]bool)
+	ast.Inspect(root, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fun := call.Fun
+		for {
+			switch x := fun.(type) {
+			case *ast.ParenExpr:
+				fun = x.X
+				continue
+			case *ast.IndexExpr:
+				fun = x.X
+				continue
+			case *ast.IndexListExpr:
+				fun = x.X
+				continue
+			case *ast.SelectorExpr:
+				called[x.Sel] = true
+			case *ast.Ident:
+				called[x] = true
+			}
+			break
+		}
+		return true
+	})
+	return called
+}
+
+// isFuncValue reports whether an identifier uses a function or method as a value instead of
+// calling it, such as a handler passed to http.HandleFunc or a method value s.Handle
+func isFuncValue(obj types.Object, ident *ast.Ident, called map[*ast.Ident]bool) bool {
+	_, isFunc := obj.(*types.Func)
+	return isFunc && !called[ident]
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"slices"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_Analyzer_References(t *testing.T) {
+	files := map[string]string{
+		"web/web.go": `package web
+
+import (
+	"net/http"
+	"sort"
+)
+
+type Server struct{}
+
+func (s *Server) Status(w http.ResponseWriter, r *http.Request) {}
+
+func handler(w http.ResponseWriter, r *http.Request) {}
+
+func less(a, b int) bool { return a < b }
+
+func Map[T any](xs []T, f func(T) T) []T { return xs }
+
+func double(x int) int { return 2 * x }
+
+func Register(s *Server, xs []int) {
+	http.HandleFunc("/", handler)
+	http.HandleFunc("/status", s.Status)
+	sort.Slice(xs, func(i, j int) bool { return less(xs[i], xs[j]) })
+	Map[int](xs, double)
+	handler(nil, nil)
+}
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	source := "example.com/app/web::Register"
+	expected := map[string][]graph.EdgeKind{
+		"example.com/app/web::handler":          {graph.EdgeBody, graph.EdgeReference}, // Called and registered
+		"example.com/app/web::(*Server).Status": {graph.EdgeBody, graph.EdgeReference},
+		"example.com/app/web::less":             {graph.EdgeBody},
+		"example.com/app/web::Map":              {graph.EdgeBody},
+		"example.com/app/web::double":           {graph.EdgeBody, graph.EdgeReference},
+	}
+	for target, kinds := range expected {
+		got := result.EdgeKindsOf(source, target)
+		for _, kind := range kinds {
+			if !slices.Contains(got, kind) {
+				t.Errorf("Expected edge to %s to be %s, got %v", target, kind, got)
+			}
+		}
+		if len(kinds) == 1 && slices.Contains(got, graph.EdgeReference) {
+			t.Errorf("Direct calls of %s should not be references, got %v", target, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// riskyUses reports whether a function uses the unsafe or reflect packages, in its body or
+// its signature
+func riskyUses(info *types.Info, fn *ast.FuncDecl) (usesUnsafe, usesReflect bool) {
+	ast.Inspect(fn, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj, ok := info.Uses[ident]
+		if !ok || obj.Pkg() == nil {
+			return true
+		}
+		switch obj.Pkg().Path() {
+		case "unsafe":
+			usesUnsafe = true
+		case "reflect":
+			usesReflect = true
+		}
+		return true
+	})
+	return usesUnsafe, usesReflect
+}
+
+// linknames returns the local names of the symbols a file links to another package's
+// symbols with //go:linkname, which bypasses the type system like unsafe does
+func linknames(file *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			directive, ok := strings.CutPrefix(comment.Text, "//go:linkname ")
+			if !ok {
+				continue
+			}
+			if fields := strings.Fields(directive); len(fields) > 0 {
+				names[fields[0]] = true
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,48 @@
+package analyzer
+
+import "testing"
+
+func Test_Analyzer_RiskyUsage(t *testing.T) {
+	files := map[string]string{
+		"low/low.go": `package low
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func Bytes(s string) []byte { return unsafe.Slice(unsafe.StringData(s), len(s)) }
+
+func Name(v any) string { return reflect.TypeOf(v).Name() }
+
+func Pointer(p unsafe.Pointer) uintptr { return uintptr(p) }
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+func Safe() int { return 1 }
+`,
+	}
+
+	result := New(loadSource(t, files, false)).Analyze()
+
+	expected := map[string][3]bool{ // unsafe, reflect, linkname
+		"Bytes":    {true, false, false},
+		"Name":     {false, true, false},
+		"Pointer":  {true, false, false},
+		"nanotime": {false, false, true},
+		"Safe":     {false, false, false},
+	}
+	for name, flags := range expected {
+		node := result.Nodes["example.com/app/low::"+name]
+		if node == nil {
+			t.Fatalf("Missing node %s", name)
+		}
+		if got := [3]bool{node.Unsafe, node.Reflect, node.Linkname}; got != flags {
+			t.Errorf("%s: expected unsafe, reflect, linkname = %v, got %v", name, flags, got)
+		}
+		if node.IsRisky() != (flags != [3]bool{}) {
+			t.Errorf("%s: unexpected IsRisky %v", name, node.IsRisky())
+		}
+	}
+}
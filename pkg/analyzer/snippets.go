@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"go/ast"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// SnippetOptions selects how much of each function's source is embedded in its node
+type SnippetOptions struct {
+	Lines    int // Leading lines of each function to embed; 0 disables snippets, -1 embeds the full function
+	MaxBytes int // Size cap of a snippet; 0 means no cap
+}
+
+// Enabled reports whether snippets are embedded at all
+func (o SnippetOptions) Enabled() bool {
+	return o.Lines != 0
+}
+
+// snippet returns the source of a function declaration, limited by the snippet options.
+// Unreadable files yield no snippet rather than failing the analysis.
+func (a *Analyzer) snippet(pkg *packages.Package, decl *ast.FuncDecl) string {
+	start := pkg.Fset.Position(decl.Pos())
+	end := pkg.Fset.Position(decl.End())
+
+	src, ok := a.sources[start.Filename]
+	if !ok {
+		src, _ = os.ReadFile(start.Filename)
+		a.sources[start.Filename] = src
+	}
+	if end.Offset > len(src) || start.Offset > end.Offset {
+		return ""
+	}
+
+	return truncateSnippet(string(src[start.Offset:end.Offset]), a.options.Snippets)
+}
+
+// truncateSnippet cuts the source down to the configured number of lines, then to the size cap.
+// The size cap is applied at a line boundary where possible, and never splits a rune.
+func truncateSnippet(src string, options SnippetOptions) string {
+	if options.Lines > 0 {
+		lines := strings.SplitAfter(src, "\n")
+		if len(lines) > options.Lines {
+			src = strings.TrimSuffix(strings.Join(lines[:options.Lines], ""), "\n")
+		}
+	}
+
+	if options.MaxBytes > 0 && len(src) > options.MaxBytes {
+		cut := options.MaxBytes
+		for cut > 0 && !utf8.RuneStart(src[cut]) {
+			cut--
+		}
+		src = src[:cut]
+		if newline := strings.LastIndexByte(src, '\n'); newline > 0 {
+			src = src[:newline]
+		}
+	}
+
+	return src
+}
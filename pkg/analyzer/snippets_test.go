@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_TruncateSnippet(t *testing.T) {
+	src := "func Run() {\n\tstart()\n\tstop()\n}"
+
+	tests := []struct {
+		name     string
+		options  SnippetOptions
+		expected string
+	}{
+		{name: "full function", options: SnippetOptions{Lines: -1}, expected: src},
+		{name: "leading lines", options: SnippetOptions{Lines: 2}, expected: "func Run() {\n\tstart()"},
+		{name: "more lines than the function", options: SnippetOptions{Lines: 10}, expected: src},
+		{name: "size cap at a line boundary", options: SnippetOptions{Lines: -1, MaxBytes: 20}, expected: "func Run() {"},
+		{name: "size cap within the first line", options: SnippetOptions{Lines: -1, MaxBytes: 4}, expected: "func"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateSnippet(src, tt.options); got != tt.expected {
+				t.Errorf("truncateSnippet() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	if got := truncateSnippet("// ü", SnippetOptions{Lines: -1, MaxBytes: 4}); got != "// " {
+		t.Errorf("Expected the cut to move before the multi-byte rune, got %q", got)
+	}
+}
+
+func Test_Analyzer_Snippets(t *testing.T) {
+	files := map[string]string{
+		"lib/lib.go": `package lib
+
+// Sum adds the numbers.
+func Sum(a, b int) int {
+	return a + b
+}
+
+type Counter struct{}
+`,
+	}
+
+	withoutSnippets := New(loadSource(t, files, false)).Analyze()
+	if snippet := withoutSnippets.Nodes["example.com/app/lib::Sum"].Snippet; snippet != "" {
+		t.Errorf("Snippets should be disabled by default, got %q", snippet)
+	}
+
+	result := NewWithOptions(loadSource(t, files, false), Options{
+		Scoring:  graph.DefaultScoringOptions(),
+		Snippets: SnippetOptions{Lines: -1},
+	}).Analyze()
+
+	expected := "func Sum(a, b int) int {\n\treturn a + b\n}"
+	if snippet := result.Nodes["example.com/app/lib::Sum"].Snippet; snippet != expected {
+		t.Errorf("Sum snippet = %q, want %q", snippet, expected)
+	}
+	if snippet := result.Nodes["example.com/app/lib::Counter"].Snippet; snippet != "" {
+		t.Errorf("Types should have no snippet, got %q", snippet)
+	}
+}
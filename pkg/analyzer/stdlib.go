@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"go/types"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// StdlibOptions selects the standard library packages whose use is recorded. Each package
+// becomes a single collapsed node, with an edge from every project function using any of
+// its symbols.
+type StdlibOptions struct {
+	Enabled  bool     // Add package nodes for the standard library packages used by project functions
+	Packages []string // Package patterns to include, e.g. unsafe, reflect, net/...; all packages when empty
+}
+
+// Includes reports whether uses of the standard library package are recorded
+func (o StdlibOptions) Includes(pkgPath string) bool {
+	if !o.Enabled {
+		return false
+	}
+	if len(o.Packages) == 0 {
+		return true
+	}
+	for _, pattern := range o.Packages {
+		if graph.MatchPackagePattern(pattern, pkgPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectModules records the paths of the analyzed modules, which stdlib paths are told apart from
+func (a *Analyzer) collectModules() {
+	for _, pkg := range a.packages {
+		if !skipPackage(pkg) {
+			a.modules[pkg.Module.Path] = true
+		}
+	}
+}
+
+// isStdlib reports whether an import path belongs to the standard library: its first path
+// element has no dot, and it is not in one of the analyzed modules, whose paths may lack one too
+func (a *Analyzer) isStdlib(pkgPath string) bool {
+	return !graph.IsPublicPackagePath(pkgPath) && pkgPath != "C" && !a.inModule(pkgPath)
+}
+
+// inModule reports whether an import path belongs to one of the analyzed modules
+func (a *Analyzer) inModule(pkgPath string) bool {
+	for module := range a.modules {
+		if pkgPath == module || strings.HasPrefix(pkgPath, module+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// stdlibTarget returns the package node of a standard library symbol used by the project,
+// when standard library usage is recorded. Builtins such as len have no package.
+func (a *Analyzer) stdlibTarget(obj types.Object) (*graph.Node, bool) {
+	if obj.Pkg() == nil {
+		return nil, false
+	}
+	pkgPath := obj.Pkg().Path()
+	if !a.isStdlib(pkgPath) || !a.options.Stdlib.Includes(pkgPath) {
+		return nil, false
+	}
+	return a.packageNode(pkgPath), true
+}
+
+// packageNode returns the collapsed node of a standard library package, creating it if needed
+func (a *Analyzer) packageNode(pkgPath string) *graph.Node {
+	if node, exists := a.graph.Nodes[pkgPath]; exists {
+		return node
+	}
+	node := &graph.Node{
+		ID:        pkgPath,
+		Name:      pkgPath,
+		Kind:      graph.KindPackage,
+		Package:   pkgPath,
+		Signature: "package " + pkgPath,
+		External:  true,
+		DocURL:    graph.PkgGoDevPackageURL(pkgPath),
+	}
+	a.graph.Nodes[pkgPath] = node
+	return node
+}
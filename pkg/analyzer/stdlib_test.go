@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_Analyzer_Stdlib(t *testing.T) {
+	pkgs := loadSource(t, map[string]string{
+		"run/run.go": `package run
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+func Run(name string) error {
+	return exec.Command(strings.TrimSpace(name)).Run()
+}
+
+func Size(v any) uintptr {
+	_ = reflect.TypeOf(v)
+	return unsafe.Sizeof(v) + uintptr(len(name()))
+}
+
+func name() string { return "" }
+`,
+	}, false)
+
+	tests := []struct {
+		name     string
+		options  StdlibOptions
+		expected map[string][]string
+	}{
+		{
+			name:    "disabled",
+			options: StdlibOptions{},
+			expected: map[string][]string{
+				"example.com/app/run::Run":  nil,
+				"example.com/app/run::Size": {"example.com/app/run::name"},
+			},
+		},
+		{
+			name:    "all packages",
+			options: StdlibOptions{Enabled: true},
+			expected: map[string][]string{
+				"example.com/app/run::Run":  {"os/exec", "strings"},
+				"example.com/app/run::Size": {"reflect", "unsafe", "example.com/app/run::name"},
+			},
+		},
+		{
+			name:    "patterns",
+			options: StdlibOptions{Enabled: true, Packages: []string{"unsafe", "os/..."}},
+			expected: map[string][]string{
+				"example.com/app/run::Run":  {"os/exec"},
+				"example.com/app/run::Size": {"unsafe", "example.com/app/run::name"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions()
+			options.Stdlib = tt.options
+			g := NewWithOptions(pkgs, options).Analyze()
+
+			for source, targets := range tt.expected {
+				if len(g.Edges[source]) != len(targets) {
+					t.Errorf("Expected %s to depend on %v, got %v", source, targets, g.Edges[source])
+				}
+				for _, target := range targets {
+					if !g.HasEdge(source, target) {
+						t.Errorf("Missing edge %s -> %s", source, target)
+					}
+				}
+			}
+		})
+	}
+
+	g := NewWithOptions(pkgs, Options{Stdlib: StdlibOptions{Enabled: true}}).Analyze()
+	node := g.Nodes["os/exec"]
+	if node == nil || node.Kind != graph.KindPackage || !node.External || node.DocURL != "https://pkg.go.dev/os/exec" {
+		t.Errorf("Unexpected package node: %+v", node)
+	}
+}
@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go-depmap/pkg/graph"
+)
+
+// testFunctionKinds maps the go test name prefixes to their node kind and the parameter
+// type required by go test. Examples take no parameters.
+var testFunctionKinds = []struct {
+	prefix string
+	kind   graph.NodeKind
+	param  string
+}{
+	{prefix: "Benchmark", kind: graph.KindBenchmark, param: "*testing.B"},
+	{prefix: "Example", kind: graph.KindExample, param: ""},
+	{prefix: "Fuzz", kind: graph.KindFuzz, param: "*testing.F"},
+	{prefix: "Test", kind: graph.KindTest, param: "*testing.T"},
+}
+
+// testFunctionKind classifies a function declared in a _test.go file the way go test does:
+// by name prefix, where the prefix must not be followed by a lower case letter, and by
+// signature. TestMain and other helpers are not classified.
+func testFunctionKind(name string, sig *types.Signature) (graph.NodeKind, bool) {
+	for _, candidate := range testFunctionKinds {
+		if !isTestName(name, candidate.prefix) {
+			continue
+		}
+		if sig.Results().Len() != 0 {
+			return "", false
+		}
+		if candidate.param == "" && sig.Params().Len() == 0 {
+			return candidate.kind, true
+		}
+		if candidate.param != "" && sig.Params().Len() == 1 && sig.Params().At(0).Type().String() == candidate.param {
+			return candidate.kind, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// isTestName reports whether name is prefix followed by nothing or a non-lower-case rune,
+// e.g. "Test" and "TestFoo" but not "Testify"
+func isTestName(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
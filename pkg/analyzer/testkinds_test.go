@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_testFunctionKind(t *testing.T) {
+	testingPkg := types.NewPackage("testing", "testing")
+	param := func(typeName string) *types.Var {
+		named := types.NewNamed(types.NewTypeName(token.NoPos, testingPkg, typeName, nil), types.NewStruct(nil, nil), nil)
+		return types.NewVar(token.NoPos, nil, "x", types.NewPointer(named))
+	}
+	signature := func(params ...*types.Var) *types.Signature {
+		return types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), nil, false)
+	}
+
+	tests := []struct {
+		name     string
+		sig      *types.Signature
+		expected graph.NodeKind
+		ok       bool
+	}{
+		{name: "TestServe", sig: signature(param("T")), expected: graph.KindTest, ok: true},
+		{name: "Test", sig: signature(param("T")), expected: graph.KindTest, ok: true},
+		{name: "Test_helper", sig: signature(param("T")), expected: graph.KindTest, ok: true},
+		{name: "Testify", sig: signature(param("T")), ok: false},
+		{name: "TestMain", sig: signature(param("M")), ok: false},
+		{name: "BenchmarkServe", sig: signature(param("B")), expected: graph.KindBenchmark, ok: true},
+		{name: "FuzzParse", sig: signature(param("F")), expected: graph.KindFuzz, ok: true},
+		{name: "ExampleServe", sig: signature(), expected: graph.KindExample, ok: true},
+		{name: "ExampleServe", sig: signature(param("T")), ok: false},
+		{name: "helper", sig: signature(), ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, ok := testFunctionKind(tt.name, tt.sig)
+			if ok != tt.ok || kind != tt.expected {
+				t.Errorf("testFunctionKind(%s) = %q, %v, want %q, %v", tt.name, kind, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package analyzer
+
+import "time"
+
+// PhaseTiming is the duration of an analysis phase
+type PhaseTiming struct {
+	Phase    string
+	Duration time.Duration
+}
+
+// Timings returns the durations of the phases of the last Analyze call, in order
+func (a *Analyzer) Timings() []PhaseTiming {
+	return a.timings
+}
+
+// timePhase runs a phase and records its duration
+func (a *Analyzer) timePhase(phase string, run func()) {
+	start := time.Now()
+	run()
+	a.timings = append(a.timings, PhaseTiming{Phase: phase, Duration: time.Since(start)})
+}
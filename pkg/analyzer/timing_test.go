@@ -0,0 +1,28 @@
+package analyzer
+
+import "testing"
+
+func Test_Analyzer_Timings(t *testing.T) {
+	files := map[string]string{
+		"a/a.go": "package a\n\nfunc A() {}\n\nfunc B() { A() }\n",
+	}
+
+	a := New(loadSource(t, files, false))
+	a.Analyze()
+
+	timings := a.Timings()
+	want := []string{PhaseDefinitions, PhaseDependencies, PhaseSubgraphs}
+	if len(timings) != len(want) {
+		t.Fatalf("Expected %d phases, got %+v", len(want), timings)
+	}
+	for i, timing := range timings {
+		if timing.Phase != want[i] || timing.Duration <= 0 {
+			t.Errorf("Expected phase %s with a duration, got %+v", want[i], timing)
+		}
+	}
+
+	a.Analyze()
+	if len(a.Timings()) != len(want) {
+		t.Errorf("Timings should only cover the last analysis, got %+v", a.Timings())
+	}
+}
@@ -0,0 +1,252 @@
+package analyzer
+
+import (
+	"errors"
+	"go/types"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Graph returns the graph of the last Analyze or Update call
+func (a *Analyzer) Graph() *graph.DependencyGraph {
+	return a.graph
+}
+
+// Update analyzes again the packages affected by changes to files since the last analysis:
+// the packages of the changed Go files, and the project packages importing them, directly or
+// not. They are loaded again with Options.Load, and the rest of the graph is kept; a change
+// to any other file, such as go.mod, loads every package again. Removed packages are dropped.
+//
+// Update returns the changes to the graph, whose new version Graph returns. The previous
+// version is left untouched, so that it can still be served while the update runs. When
+// loading fails, the analyzer keeps its previous state.
+func (a *Analyzer) Update(changedFiles []string) (*graph.Delta, error) {
+	if a.options.Load == nil {
+		return nil, errors.New("the analyzer has no Load option to load the changed packages with")
+	}
+	affected, patterns := a.affectedPackages(changedFiles)
+	if len(affected) == 0 && len(patterns) == 0 {
+		return &graph.Delta{}, nil
+	}
+	slog.Debug("Loading the changed packages", "affected", len(affected), "patterns", patterns)
+	var loaded []*packages.Package
+	if len(patterns) > 0 {
+		var err error
+		if loaded, err = a.options.Load(patterns); err != nil {
+			return nil, err
+		}
+	}
+
+	// Drop the affected packages, whose nodes are declared anew from the loaded ones
+	removed := make(map[string]bool)
+	kept := make([]*packages.Package, 0, len(a.packages)+len(loaded))
+	for _, pkg := range a.packages {
+		if affected[loadPath(pkg)] {
+			removed[pkg.PkgPath] = true
+		} else {
+			kept = append(kept, pkg)
+		}
+	}
+	previous := a.graph
+	a.graph = a.keptGraph(previous, removed)
+	a.packages = append(kept, loaded...)
+	for _, filename := range changedFiles {
+		if abs, err := filepath.Abs(filename); err == nil {
+			delete(a.sources, abs)
+		}
+	}
+	a.aliases = make(map[*graph.Node]*types.TypeName)
+	a.reloaded = true
+
+	a.timings = nil
+	a.timePhase(PhaseDefinitions, func() {
+		a.collectModules()
+		a.collectDefinitions(loaded)
+		a.linkAliases()
+		a.linkInits()
+	})
+	a.timePhase(PhaseDependencies, func() {
+		a.analyzeDependencies(loaded)
+		a.pruneUnusedExternal()
+		a.graph.SortOccurrences()
+		a.graph.Compact()
+	})
+	a.timePhase(PhaseSubgraphs, a.computeSubgraphs)
+	return graph.Diff(previous, a.graph), nil
+}
+
+// affectedPackages returns the load paths (see loadPath) of the packages affected by changes
+// to files, and the patterns loading them again. Packages whose files were all removed are
+// not loaded again, and new files outside the known packages are loaded with "file=" queries.
+func (a *Analyzer) affectedPackages(changedFiles []string) (map[string]bool, []string) {
+	byFile := make(map[string]string)
+	byDir := make(map[string]string)
+	importers := make(map[string][]string) // PkgPath -> load paths of the packages importing it
+	for _, pkg := range a.packages {
+		if skipPackage(pkg) || pkg.Types == nil {
+			continue
+		}
+		for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+			for _, filename := range files {
+				byFile[filename] = loadPath(pkg)
+				byDir[filepath.Dir(filename)] = loadPath(pkg)
+			}
+		}
+		// The loads do not need Imports: the type-checked package has them
+		for _, imported := range pkg.Types.Imports() {
+			importers[imported.Path()] = append(importers[imported.Path()], loadPath(pkg))
+		}
+	}
+
+	affected := make(map[string]bool)
+	var queries []string
+	for _, filename := range changedFiles {
+		abs, err := filepath.Abs(filename)
+		if err != nil {
+			continue
+		}
+		if !strings.HasSuffix(abs, ".go") {
+			// Module changes may change any package
+			for _, path := range byFile {
+				affected[path] = true
+			}
+			continue
+		}
+		if path, known := byFile[abs]; known {
+			affected[path] = true
+		} else if path, known := byDir[filepath.Dir(abs)]; known {
+			affected[path] = true
+		} else if _, err := os.Stat(abs); err == nil {
+			queries = append(queries, "file="+abs)
+		}
+	}
+
+	// Importers refer to the objects of the packages they import, which are replaced
+	pending := slices.Collect(maps.Keys(affected))
+	for len(pending) > 0 {
+		path := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, importer := range importers[path] {
+			if !affected[importer] {
+				affected[importer] = true
+				pending = append(pending, importer)
+			}
+		}
+	}
+
+	var patterns []string
+	for path := range affected {
+		if a.hasFiles(path) {
+			patterns = append(patterns, path)
+		}
+	}
+	slices.Sort(patterns)
+	return affected, append(patterns, queries...)
+}
+
+// hasFiles reports whether a file of the package with the given load path still exists
+func (a *Analyzer) hasFiles(path string) bool {
+	for _, pkg := range a.packages {
+		if loadPath(pkg) != path {
+			continue
+		}
+		for _, filename := range pkg.GoFiles {
+			if _, err := os.Stat(filename); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadPath returns the import path loading a package: test variants and external test
+// packages are loaded with the package they test
+func loadPath(pkg *packages.Package) string {
+	if pkg.ForTest != "" {
+		return pkg.ForTest
+	}
+	return strings.TrimSuffix(pkg.PkgPath, ".test")
+}
+
+// keptGraph copies the graph without the nodes and occurrences of the removed packages, and
+// points the analyzer's state to the copied nodes. The copies are not marked recursive through
+// other functions, which computeSubgraphs marks again.
+func (a *Analyzer) keptGraph(previous *graph.DependencyGraph, removed map[string]bool) *graph.DependencyGraph {
+	next := graph.NewDependencyGraph()
+	next.Metadata = previous.Metadata
+	next.LoadErrors = previous.LoadErrors
+	for _, occurrence := range previous.Occurrences {
+		if !removed[occurrence.Package] {
+			next.Occurrences = append(next.Occurrences, occurrence)
+		}
+	}
+	copies := make(map[*graph.Node]*graph.Node, len(previous.Nodes))
+	for nodeID, node := range previous.Nodes {
+		if removed[node.Package] && !node.External {
+			delete(a.recursive, nodeID)
+			continue
+		}
+		nodeCopy := *node
+		nodeCopy.Attrs = maps.Clone(node.Attrs)
+		nodeCopy.Recursive = a.recursive[nodeID]
+		next.Nodes[nodeID] = &nodeCopy
+		copies[node] = &nodeCopy
+	}
+
+	for sourceID, targets := range previous.Edges {
+		if _, exists := next.Nodes[sourceID]; !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if _, exists := next.Nodes[targetID]; !exists {
+				continue
+			}
+			next.Edges[sourceID] = append(next.Edges[sourceID], targetID)
+			if kinds := previous.EdgeKindsOf(sourceID, targetID); len(kinds) > 0 {
+				next.AddEdge(sourceID, targetID, kinds...)
+			}
+			for key, value := range previous.EdgeAttrsOf(sourceID, targetID) {
+				next.SetEdgeAttr(sourceID, targetID, key, value)
+			}
+		}
+	}
+
+	for obj, node := range a.projectObjects {
+		if nodeCopy, exists := copies[node]; exists {
+			a.projectObjects[obj] = nodeCopy
+		} else {
+			delete(a.projectObjects, obj)
+		}
+	}
+	for pkgPath, inits := range a.inits {
+		if removed[pkgPath] {
+			delete(a.inits, pkgPath)
+			delete(a.initVariants, pkgPath)
+			continue
+		}
+		for i, node := range inits {
+			inits[i] = copies[node]
+		}
+	}
+	return next
+}
+
+// pruneUnusedExternal removes the external and standard library nodes no longer used by the
+// project, as a new analysis would not create them
+func (a *Analyzer) pruneUnusedExternal() {
+	fanIn := a.graph.FanIn()
+	for nodeID, node := range a.graph.Nodes {
+		if node.External && fanIn[nodeID] == 0 {
+			delete(a.graph.Nodes, nodeID)
+			delete(a.graph.Edges, nodeID)
+		}
+	}
+}
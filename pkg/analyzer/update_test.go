@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// updateTestModule is a module on disk whose packages are loaded like the command does
+type updateTestModule struct {
+	t   *testing.T
+	dir string
+}
+
+// write writes files into the module, removing those with empty contents
+func (m updateTestModule) write(files map[string]string) []string {
+	m.t.Helper()
+	var changed []string
+	for name, content := range files {
+		path := filepath.Join(m.dir, name)
+		changed = append(changed, path)
+		if content == "" {
+			if err := os.Remove(path); err != nil {
+				m.t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			m.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			m.t.Fatal(err)
+		}
+	}
+	return changed
+}
+
+// load loads packages of the module, failing on errors
+func (m updateTestModule) load(patterns []string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps | packages.NeedModule,
+		Dir:  m.dir,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("packages contained errors")
+	}
+	return pkgs, nil
+}
+
+// analyze analyzes the whole module anew
+func (m updateTestModule) analyze() *Analyzer {
+	m.t.Helper()
+	pkgs, err := m.load([]string{"./..."})
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	options := DefaultOptions()
+	options.Load = m.load
+	options.Occurrences = true
+	a := NewWithOptions(pkgs, options)
+	a.Analyze()
+	return a
+}
+
+// graphEdges lists the edges of a graph with their kinds, sorted
+func graphEdges(g *graph.DependencyGraph) []string {
+	var edges []string
+	for sourceID, targets := range g.Edges {
+		for _, targetID := range targets {
+			edges = append(edges, fmt.Sprintf("%s -> %s %v", sourceID, targetID, g.EdgeKindsOf(sourceID, targetID)))
+		}
+	}
+	slices.Sort(edges)
+	return edges
+}
+
+// nodeIDsOf lists the node IDs of a graph, sorted
+func nodeIDsOf(g *graph.DependencyGraph) []string {
+	var ids []string
+	for nodeID := range g.Nodes {
+		ids = append(ids, nodeID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func Test_Analyzer_Update(t *testing.T) {
+	m := updateTestModule{t: t, dir: t.TempDir()}
+	m.write(map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.21\n",
+		"db/db.go":    "package db\n\ntype Conn struct{}\n\nfunc Open() *Conn { return &Conn{} }\n\nfunc (c *Conn) Close() {}\n",
+		"api/api.go":  "package api\n\nimport \"example.com/app/db\"\n\nfunc Serve() { db.Open().Close() }\n",
+		"util/fmt.go": "package util\n\nfunc Even(n int) bool { return n == 0 || Odd(n-1) }\n\nfunc Odd(n int) bool { return n != 0 && Even(n-1) }\n",
+	})
+	a := m.analyze()
+	before := a.Graph()
+	beforeEdges := graphEdges(before)
+
+	// A new function in db, used by api, and a new package; Odd stops calling Even
+	changed := m.write(map[string]string{
+		"db/db.go":      "package db\n\ntype Conn struct{}\n\nfunc Open() *Conn { return &Conn{} }\n\nfunc (c *Conn) Close() {}\n\nfunc Ping() {}\n",
+		"api/health.go": "package api\n\nimport \"example.com/app/db\"\n\nfunc Health() { db.Ping() }\n",
+		"cli/cli.go":    "package cli\n\nimport \"example.com/app/api\"\n\nfunc Main() { api.Serve() }\n",
+		"util/fmt.go":   "package util\n\nfunc Even(n int) bool { return n == 0 || Odd(n-1) }\n\nfunc Odd(n int) bool { return n != 0 }\n",
+	})
+	delta, err := a.Update(changed)
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+
+	fresh := m.analyze().Graph()
+	if got, want := nodeIDsOf(a.Graph()), nodeIDsOf(fresh); !reflect.DeepEqual(got, want) {
+		t.Errorf("Nodes after Update = %v, want %v", got, want)
+	}
+	if got, want := graphEdges(a.Graph()), graphEdges(fresh); !reflect.DeepEqual(got, want) {
+		t.Errorf("Edges after Update = %v, want %v", got, want)
+	}
+	if got, want := a.Graph().Occurrences, fresh.Occurrences; !reflect.DeepEqual(got, want) {
+		t.Errorf("Occurrences after Update = %v, want %v", got, want)
+	}
+	if a.Graph().Nodes["example.com/app/util::Even"].Recursive {
+		t.Error("Even is still marked recursive")
+	}
+
+	var added []string
+	for _, node := range delta.AddedNodes {
+		added = append(added, node.ID)
+	}
+	wantAdded := []string{"example.com/app/api::Health", "example.com/app/cli::Main", "example.com/app/db::Ping"}
+	if !reflect.DeepEqual(added, wantAdded) {
+		t.Errorf("Added nodes = %v, want %v", added, wantAdded)
+	}
+	if len(delta.RemovedEdges) != 1 || delta.RemovedEdges[0].Source != "example.com/app/util::Odd" {
+		t.Errorf("Removed edges = %v, want Odd -> Even", delta.RemovedEdges)
+	}
+
+	// The previous graph is left untouched
+	if !reflect.DeepEqual(graphEdges(before), beforeEdges) || before.Nodes["example.com/app/db::Ping"] != nil {
+		t.Error("Update changed the previous graph")
+	}
+
+	// Symbols of the packages that are not loaded again are found by ID
+	changed = m.write(map[string]string{"api/api.go": "package api\n\nimport \"example.com/app/db\"\n\nfunc Serve() { db.Open() }\n"})
+	if delta, err = a.Update(changed); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	fresh = m.analyze().Graph()
+	if got, want := graphEdges(a.Graph()), graphEdges(fresh); !reflect.DeepEqual(got, want) {
+		t.Errorf("Edges after updating api = %v, want %v", got, want)
+	}
+	if len(delta.RemovedEdges) != 1 || delta.RemovedEdges[0].Target != "example.com/app/db::(*Conn).Close" {
+		t.Errorf("Removed edges = %v, want Serve -> Close", delta.RemovedEdges)
+	}
+
+	// Removing a package drops its nodes and the edges to them
+	changed = m.write(map[string]string{"cli/cli.go": ""})
+	if delta, err = a.Update(changed); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if !reflect.DeepEqual(delta.RemovedNodes, []string{"example.com/app/cli::Main"}) {
+		t.Errorf("Removed nodes = %v, want cli::Main", delta.RemovedNodes)
+	}
+
+	// Broken code fails the update, and keeps the graph
+	current := a.Graph()
+	changed = m.write(map[string]string{"db/db.go": "package db\n\nfunc Open( {\n"})
+	if _, err := a.Update(changed); err == nil {
+		t.Error("Update() of broken code succeeded")
+	}
+	if a.Graph() != current {
+		t.Error("Failed Update() replaced the graph")
+	}
+}
+
+func Test_Analyzer_Update_WithoutLoad(t *testing.T) {
+	a := New(nil)
+	a.Analyze()
+	if _, err := a.Update([]string{"main.go"}); err == nil {
+		t.Error("Update() without a Load option succeeded")
+	}
+}
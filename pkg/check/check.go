@@ -0,0 +1,222 @@
+// Package check evaluates rules against a dependency graph. Rule violations are reported by
+// the junit and gh-summary formats and can fail a CI build.
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// Violation is a single finding of a rule
+type Violation struct {
+	Rule    string `json:"rule"`    // Name of the violated rule
+	Subject string `json:"subject"` // What violates the rule, e.g. a node ID or package
+	Message string `json:"message"` // Human readable explanation
+}
+
+// Rule is a named check of the graph
+type Rule struct {
+	Name        string                                     // Short identifier, e.g. "cycles"
+	Description string                                     // What the rule requires
+	Evaluate    func(g *graph.DependencyGraph) []Violation // Returns the violations, if any
+	Enforced    bool                                       // Whether violations fail the build
+}
+
+// Result holds the violations of a single rule
+type Result struct {
+	Rule        string      `json:"rule"`
+	Description string      `json:"description"`
+	Violations  []Violation `json:"violations"`
+	Enforced    bool        `json:"enforced"`
+}
+
+// Passed reports whether the rule found no violations
+func (r Result) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+// Failed reports whether the rule is enforced and has violations
+func (r Result) Failed() bool {
+	return r.Enforced && !r.Passed()
+}
+
+// AnyFailed reports whether any enforced rule has violations
+func AnyFailed(results []Result) bool {
+	for _, result := range results {
+		if result.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Options selects the rules and thresholds to check
+type Options struct {
+	FailOnCycles   bool // Enforce the cycles rule, which is otherwise only reported
+	MaxFanIn       int  // Maximum number of dependents of a symbol (0 = unchecked)
+	MaxPackageDeps int  // Maximum number of packages a package depends on (0 = unchecked)
+
+	// NoRecursion lists the package patterns, e.g. example.com/app/domain/..., whose functions
+	// must not be recursive
+	NoRecursion []string
+}
+
+// DefaultRules returns the rules that are always checked
+func DefaultRules() []Rule {
+	return Rules(Options{})
+}
+
+// Rules returns the cycles rule, plus a rule for every threshold or restriction that is set.
+// These rules are always enforced.
+func Rules(opts Options) []Rule {
+	cycles := NoCycles()
+	cycles.Enforced = opts.FailOnCycles
+	rules := []Rule{cycles}
+	if opts.MaxFanIn > 0 {
+		rules = append(rules, MaxFanIn(opts.MaxFanIn))
+	}
+	if opts.MaxPackageDeps > 0 {
+		rules = append(rules, MaxPackageDeps(opts.MaxPackageDeps))
+	}
+	if len(opts.NoRecursion) > 0 {
+		rules = append(rules, NoRecursion(opts.NoRecursion))
+	}
+	return rules
+}
+
+// Run evaluates the rules in order
+func Run(g *graph.DependencyGraph, rules []Rule) []Result {
+	results := make([]Result, 0, len(rules))
+	for _, rule := range rules {
+		violations := rule.Evaluate(g)
+		if violations == nil {
+			violations = make([]Violation, 0)
+		}
+		results = append(results, Result{
+			Rule:        rule.Name,
+			Description: rule.Description,
+			Violations:  violations,
+			Enforced:    rule.Enforced,
+		})
+	}
+	return results
+}
+
+// NoCycles requires the graph to be free of dependency cycles; every cycle is a violation
+func NoCycles() Rule {
+	return Rule{
+		Name:        "cycles",
+		Description: "No dependency cycles",
+		Evaluate: func(g *graph.DependencyGraph) []Violation {
+			cycles := g.FindCycles()
+			violations := make([]Violation, 0, len(cycles))
+			for _, cycle := range cycles {
+				violations = append(violations, Violation{
+					Rule:    "cycles",
+					Subject: strings.Join(cycle, ", "),
+					Message: fmt.Sprintf("%d symbol(s) depend on each other: %s", len(cycle), strings.Join(cycle, " <-> ")),
+				})
+			}
+			return violations
+		},
+	}
+}
+
+// MaxFanIn limits the number of dependents of every symbol
+func MaxFanIn(limit int) Rule {
+	return Rule{
+		Name:        "max-fan-in",
+		Description: fmt.Sprintf("At most %d dependents per symbol", limit),
+		Enforced:    true,
+		Evaluate: func(g *graph.DependencyGraph) []Violation {
+			violations := make([]Violation, 0)
+			for nodeID, fanIn := range g.FanIn() {
+				if fanIn > limit {
+					violations = append(violations, Violation{
+						Rule:    "max-fan-in",
+						Subject: nodeID,
+						Message: fmt.Sprintf("%d dependents, limit is %d", fanIn, limit),
+					})
+				}
+			}
+			sortViolations(violations)
+			return violations
+		},
+	}
+}
+
+// MaxPackageDeps limits the number of other packages every package depends on (efferent coupling)
+func MaxPackageDeps(limit int) Rule {
+	return Rule{
+		Name:        "max-package-deps",
+		Description: fmt.Sprintf("At most %d package dependencies per package", limit),
+		Enforced:    true,
+		Evaluate: func(g *graph.DependencyGraph) []Violation {
+			violations := make([]Violation, 0)
+			for _, m := range g.ComputePackageMetrics() {
+				if m.Efferent > limit {
+					violations = append(violations, Violation{
+						Rule:    "max-package-deps",
+						Subject: m.Package,
+						Message: fmt.Sprintf("depends on %d packages, limit is %d", m.Efferent, limit),
+					})
+				}
+			}
+			return violations
+		},
+	}
+}
+
+// NoRecursion bans recursion, direct or mutual, in the packages matching the patterns. A group
+// of mutually recursive functions is a violation when any of its members is in such a package.
+func NoRecursion(patterns []string) Rule {
+	return Rule{
+		Name:        "no-recursion",
+		Description: "No recursion in " + strings.Join(patterns, ", "),
+		Enforced:    true,
+		Evaluate: func(g *graph.DependencyGraph) []Violation {
+			violations := make([]Violation, 0)
+			for _, group := range g.FindRecursion() {
+				if !anyInPackages(g, group, patterns) {
+					continue
+				}
+				message := "calls itself"
+				if len(group) > 1 {
+					message = fmt.Sprintf("%d functions call each other", len(group))
+				}
+				violations = append(violations, Violation{
+					Rule:    "no-recursion",
+					Subject: strings.Join(group, ", "),
+					Message: message,
+				})
+			}
+			return violations
+		},
+	}
+}
+
+// anyInPackages reports whether any of the nodes is in a package matching one of the patterns
+func anyInPackages(g *graph.DependencyGraph, nodeIDs, patterns []string) bool {
+	for _, nodeID := range nodeIDs {
+		node, exists := g.Nodes[nodeID]
+		if !exists {
+			continue
+		}
+		for _, pattern := range patterns {
+			if graph.MatchPackagePattern(pattern, node.Package) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortViolations orders violations by subject for stable output
+func sortViolations(violations []Violation) {
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Subject < violations[j].Subject
+	})
+}
@@ -0,0 +1,99 @@
+package check
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func newCheckTestGraph() *graph.DependencyGraph {
+	g := graph.NewDependencyGraph()
+	for _, id := range []string{"a::F", "a::G", "b::H"} {
+		g.Nodes[id] = &graph.Node{ID: id, Kind: graph.KindFunction, Package: id[:1]}
+	}
+	g.AddEdge("a::F", "a::G")
+	g.AddEdge("a::G", "a::F")
+	g.AddEdge("a::G", "b::H")
+	return g
+}
+
+func TestNoCycles(t *testing.T) {
+	results := Run(newCheckTestGraph(), []Rule{NoCycles()})
+
+	if len(results) != 1 || results[0].Passed() {
+		t.Fatalf("Expected a failed cycles result, got %+v", results)
+	}
+	violation := results[0].Violations[0]
+	if violation.Rule != "cycles" || violation.Subject != "a::F, a::G" {
+		t.Errorf("Unexpected violation: %+v", violation)
+	}
+}
+
+func TestRun_Passed(t *testing.T) {
+	g := newCheckTestGraph()
+	g.Edges["a::G"] = []string{"b::H"}
+
+	results := Run(g, DefaultRules())
+
+	if len(results) != 1 || !results[0].Passed() {
+		t.Errorf("Expected the cycles rule to pass, got %+v", results)
+	}
+	if results[0].Violations == nil {
+		t.Error("Violations should be an empty slice, not nil, for stable JSON output")
+	}
+}
+
+func TestRules_Thresholds(t *testing.T) {
+	results := Run(newCheckTestGraph(), Rules(Options{MaxFanIn: 1, MaxPackageDeps: 1}))
+
+	if len(results) != 3 {
+		t.Fatalf("Expected cycles, max-fan-in and max-package-deps results, got %+v", results)
+	}
+	if results[0].Failed() {
+		t.Error("Cycles should only be reported without FailOnCycles")
+	}
+	if !results[1].Passed() || !results[2].Passed() {
+		t.Errorf("Thresholds of 1 should pass, got %+v", results[1:])
+	}
+	if AnyFailed(results) {
+		t.Error("AnyFailed should be false")
+	}
+}
+
+func TestRules_Exceeded(t *testing.T) {
+	g := newCheckTestGraph()
+	g.AddEdge("b::H", "a::G")
+	g.Nodes["c::K"] = &graph.Node{ID: "c::K", Kind: graph.KindFunction, Package: "c"}
+	g.AddEdge("a::F", "c::K")
+
+	results := Run(g, Rules(Options{FailOnCycles: true, MaxFanIn: 1, MaxPackageDeps: 1}))
+
+	for _, result := range results {
+		if !result.Failed() {
+			t.Errorf("Rule %s should fail, got %+v", result.Rule, result)
+		}
+	}
+	if v := results[1].Violations; len(v) != 1 || v[0].Subject != "a::G" {
+		t.Errorf("Expected a::G to exceed the fan-in limit, got %+v", v)
+	}
+	if v := results[2].Violations; len(v) != 1 || v[0].Subject != "a" {
+		t.Errorf("Expected package a to exceed the package dependency limit, got %+v", v)
+	}
+}
+
+func TestNoRecursion(t *testing.T) {
+	g := newCheckTestGraph()
+	g.AddEdge("a::F", "a::G", graph.EdgeBody)
+	g.AddEdge("a::G", "a::F", graph.EdgeBody)
+	g.Nodes["b::H"].Recursive = true
+
+	results := Run(g, []Rule{NoRecursion([]string{"b"})})
+	if v := results[0].Violations; len(v) != 1 || v[0].Subject != "b::H" || v[0].Message != "calls itself" {
+		t.Errorf("Expected only b::H to violate, got %+v", v)
+	}
+
+	results = Run(g, []Rule{NoRecursion([]string{"a/...", "b"})})
+	if v := results[0].Violations; len(v) != 2 || v[0].Subject != "a::F, a::G" {
+		t.Errorf("Expected both groups to violate, got %+v", v)
+	}
+}
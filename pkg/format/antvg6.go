@@ -1,10 +1,13 @@
 package format
 
 import (
+	"cmp"
 	"embed"
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"io"
+	"strings"
 
 	"go-depmap/pkg/graph"
 )
@@ -15,12 +18,26 @@ var antvg6TemplateFS embed.FS
 // AntVG6Writer implements the Writer interface for AntV G6 visualization
 type AntVG6Writer struct{}
 
+// antvg6NodeSize is the default node size of the page, which sizeBy scales
+const antvg6NodeSize = 30.0
+
+// Layouts the page runs, selected by the antvg6Layout config key: a force-directed layout, or
+// the layered layout of dagre, which reads better on mostly acyclic graphs
+const (
+	AntVG6LayoutForce = "force"
+	AntVG6LayoutDagre = "dagre"
+)
+
 // AntVG6Node represents a node in AntV G6 v4 format
 type AntVG6Node struct {
 	ID      string                 `json:"id"`
 	Label   string                 `json:"label,omitempty"`
 	ComboID string                 `json:"comboId,omitempty"`
 	Data    map[string]interface{} `json:"data,omitempty"`
+	Style   map[string]interface{} `json:"style,omitempty"` // Shape style of the node, e.g. its palette or colorBy fill
+	Size    float64                `json:"size,omitempty"`  // Diameter of the node's value of the sizeBy metric, replacing the default size
+
+	*graph.Position // Coordinates x and y of a precomputed layout, which the template keeps
 }
 
 // AntVG6Edge represents an edge in AntV G6 format
@@ -31,32 +48,86 @@ type AntVG6Edge struct {
 	Data   map[string]interface{} `json:"data"`
 }
 
-// AntVG6Combo represents a combo (package container) in AntV G6 v4 format
+// AntVG6Combo represents a combo (package container, or type container nested in it) in AntV G6
+// v4 format
 type AntVG6Combo struct {
-	ID    string                 `json:"id"`
-	Label string                 `json:"label,omitempty"`
-	Data  map[string]interface{} `json:"data,omitempty"`
+	ID        string                 `json:"id"`
+	Label     string                 `json:"label,omitempty"`
+	ParentID  string                 `json:"parentId,omitempty"` // Package combo of a type combo
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Collapsed bool                   `json:"collapsed,omitempty"` // Drawn as a single node until expanded
 }
 
 // AntVG6Graph is the complete data structure for AntV G6
 type AntVG6Graph struct {
-	Nodes  []AntVG6Node  `json:"nodes"`
-	Edges  []AntVG6Edge  `json:"edges"`
-	Combos []AntVG6Combo `json:"combos,omitempty"`
+	Nodes    []AntVG6Node    `json:"nodes"`
+	Edges    []AntVG6Edge    `json:"edges"`
+	Combos   []AntVG6Combo   `json:"combos,omitempty"`
+	Cycles   int             `json:"cycles,omitempty"`   // Number of dependency cycles, whose members have a "cycle" index in their data
+	ColorBy  *MetricScale    `json:"color_by,omitempty"` // Metric the nodes are colored by, for the legend
+	SizeBy   *MetricScale    `json:"size_by,omitempty"`  // Metric the nodes are sized by, for the legend
+	Layout   string          `json:"layout,omitempty"`   // Layout the page runs, unless the nodes are positioned
+	Metadata *graph.Metadata `json:"metadata,omitempty"` // Provenance of the graph
 }
 
 // Write generates AntV G6-compatible JSON or HTML output
 func (w *AntVG6Writer) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
 	antvg6Graph := convertToAntVG6Format(depGraph, config)
 
+	layout, err := antvg6Layout(config)
+	if err != nil {
+		return err
+	}
+	antvg6Graph.Layout = layout
+
+	// Palette colors of the packages and kinds, which the heat coloring replaces
+	palette, err := paletteOf(config)
+	if err != nil {
+		return err
+	}
+	for i, node := range antvg6Graph.Nodes {
+		if color := palette.nodeColor(depGraph.Nodes[strings.TrimPrefix(node.ID, "type:")]); color != "" {
+			antvg6Graph.Nodes[i].Style = map[string]interface{}{"fill": color}
+		}
+	}
+
+	// Heat coloring by a metric, type nodes taking the color of their type
+	colors, colorBy, err := nodeHeatColors(depGraph, config)
+	if err != nil {
+		return err
+	}
+	if colors != nil {
+		antvg6Graph.ColorBy = colorBy
+		for i, node := range antvg6Graph.Nodes {
+			antvg6Graph.Nodes[i].Style = map[string]interface{}{
+				"fill": cmp.Or(colors[strings.TrimPrefix(node.ID, "type:")], noMetricColor),
+			}
+		}
+	}
+
+	// Sizing by a metric, type nodes taking the size of their type
+	sizes, sizeBy, err := nodeMetricSizes(depGraph, config)
+	if err != nil {
+		return err
+	}
+	if sizes != nil {
+		antvg6Graph.SizeBy = sizeBy
+		for i, node := range antvg6Graph.Nodes {
+			antvg6Graph.Nodes[i].Size = antvg6NodeSize * cmp.Or(sizes[strings.TrimPrefix(node.ID, "type:")], minMetricSize)
+		}
+	}
+
 	// Check if HTML page should be generated
 	if config.GetBool("htmlPage", false) {
-		return writeAntVG6HTML(writer, antvg6Graph)
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeAntVG6HTML(writer, antvg6Graph, theme)
 	}
 
 	// Otherwise, output JSON
 	var jsonData []byte
-	var err error
 
 	if config.GetBool("pretty", true) {
 		jsonData, err = json.MarshalIndent(antvg6Graph, "", "  ")
@@ -72,12 +143,30 @@ func (w *AntVG6Writer) Write(writer io.Writer, depGraph *graph.DependencyGraph,
 	return err
 }
 
-// convertToAntVG6Format converts DependencyGraph to AntV G6 format with package combos
-func convertToAntVG6Format(depGraph *graph.DependencyGraph, _ Config) *AntVG6Graph {
+// antvg6Layout returns the layout the page runs, force-directed by default
+func antvg6Layout(config Config) (string, error) {
+	switch layout := config.GetString("antvg6Layout", AntVG6LayoutForce); layout {
+	case AntVG6LayoutForce, AntVG6LayoutDagre:
+		return layout, nil
+	default:
+		return "", fmt.Errorf("invalid antvg6Layout %q: expected %q or %q", layout, AntVG6LayoutForce, AntVG6LayoutDagre)
+	}
+}
+
+// convertToAntVG6Format converts DependencyGraph to AntV G6 format with package combos, and
+// with groupByType type combos nesting the methods of a type in its package combo
+func convertToAntVG6Format(depGraph *graph.DependencyGraph, config Config) *AntVG6Graph {
 	antvg6Graph := &AntVG6Graph{
-		Nodes:  make([]AntVG6Node, 0),
-		Edges:  make([]AntVG6Edge, 0),
-		Combos: make([]AntVG6Combo, 0),
+		Metadata: depGraph.Metadata,
+		Nodes:    make([]AntVG6Node, 0),
+		Edges:    make([]AntVG6Edge, 0),
+		Combos:   make([]AntVG6Combo, 0),
+	}
+
+	// Cycle membership, which the page highlights
+	cycles := depGraph.CycleMembership()
+	for _, cycle := range cycles {
+		antvg6Graph.Cycles = max(antvg6Graph.Cycles, cycle)
 	}
 
 	// Track which package combos we've created
@@ -114,16 +203,56 @@ func convertToAntVG6Format(depGraph *graph.DependencyGraph, _ Config) *AntVG6Gra
 				ID:    "pkg:" + node.Package,
 				Label: node.Package,
 				Data: map[string]interface{}{
+					"package":     node.Package,
 					"color":       "rgba(100, 100, 200, 0.05)",
 					"strokeColor": lightenColor(pkgColor, 20),
 				},
+				Collapsed: config.GetBool("collapseCombos", false),
+			})
+		}
+	}
+
+	// Type combos hold a type having methods and its methods, like the WebCola type groups of
+	// the d3js format
+	typeCombos := make(map[string]string) // Type node ID to the ID of its combo
+	if config.GetBool("groupByType", true) {
+		for _, node := range depGraph.Nodes {
+			if node.Kind != graph.KindMethod {
+				continue
+			}
+			typeID := node.ReceiverID()
+			receiverType, exists := depGraph.Nodes[typeID]
+			if !exists || typeCombos[typeID] != "" {
+				continue
+			}
+			typeCombos[typeID] = "combo:" + typeID
+			antvg6Graph.Combos = append(antvg6Graph.Combos, AntVG6Combo{
+				ID:       "combo:" + typeID,
+				Label:    receiverType.Name,
+				ParentID: "pkg:" + node.Package,
+				Data: map[string]interface{}{
+					"package":     node.Package,
+					"type":        receiverType.Name,
+					"color":       "rgba(100, 100, 200, 0.05)",
+					"strokeColor": lightenColor(getPackageColor(node.Package), 35),
+				},
 			})
 		}
 	}
 
+	// Helper to find the combo of a node: the combo of its type, or of the receiver type of a
+	// method, else that of its package
+	comboOf := func(node *graph.Node) string {
+		typeID := node.ID
+		if node.Kind == graph.KindMethod {
+			typeID = node.ReceiverID()
+		}
+		return cmp.Or(typeCombos[typeID], "pkg:"+node.Package)
+	}
+
 	// Phase 2: Create type nodes (not as combos, but as regular nodes)
 	for _, node := range depGraph.Nodes {
-		if node.Kind == graph.KindType {
+		if node.Kind.IsType() {
 			typeID := "type:" + node.ID
 			if !typeHubs[typeID] {
 				typeHubs[typeID] = true
@@ -131,14 +260,23 @@ func convertToAntVG6Format(depGraph *graph.DependencyGraph, _ Config) *AntVG6Gra
 				antvg6Graph.Nodes = append(antvg6Graph.Nodes, AntVG6Node{
 					ID:      typeID,
 					Label:   node.Name,
-					ComboID: "pkg:" + node.Package,
+					ComboID: comboOf(node),
 					Data: map[string]interface{}{
-						"type":  "type",
-						"group": node.Package,
-						"color": lightenColor(pkgColor, 15),
-						"size":  8.0,
+						"type":      string(node.Kind),
+						"group":     node.Package,
+						"color":     lightenColor(pkgColor, 15),
+						"size":      8.0,
+						"doc":       node.Summary(),
+						"doc_url":   node.DocURL,
+						"signature": node.Signature,
+						"file":      node.File,
+						"line":      node.Line,
 					},
+					Position: node.Position,
 				})
+				if cycle := cycles[node.ID]; cycle != 0 {
+					antvg6Graph.Nodes[len(antvg6Graph.Nodes)-1].Data["cycle"] = cycle
+				}
 				// Note: No structural edge to package - combo provides visual grouping
 			}
 		}
@@ -157,9 +295,12 @@ func convertToAntVG6Format(depGraph *graph.DependencyGraph, _ Config) *AntVG6Gra
 		case graph.KindMethod:
 			nodeType = "method"
 			nodeSize = 4.0
-		case graph.KindType:
+		case graph.KindType, graph.KindInterface, graph.KindStruct, graph.KindNamed, graph.KindAlias:
 			// Already added, skip
 			continue
+		case graph.KindTest, graph.KindBenchmark, graph.KindFuzz, graph.KindExample:
+			nodeType = string(node.Kind)
+			nodeSize = 4.0
 		default:
 			nodeType = "unknown"
 			nodeSize = 4.0
@@ -168,21 +309,53 @@ func convertToAntVG6Format(depGraph *graph.DependencyGraph, _ Config) *AntVG6Gra
 		antvg6Graph.Nodes = append(antvg6Graph.Nodes, AntVG6Node{
 			ID:      node.ID,
 			Label:   node.Name,
-			ComboID: "pkg:" + node.Package,
+			ComboID: comboOf(node),
 			Data: map[string]interface{}{
-				"type":  nodeType,
-				"group": node.Package,
-				"color": pkgColor,
-				"size":  nodeSize,
+				"type":      nodeType,
+				"group":     node.Package,
+				"color":     pkgColor,
+				"size":      nodeSize,
+				"doc":       node.Summary(),
+				"snippet":   node.Snippet,
+				"doc_url":   node.DocURL,
+				"signature": node.Signature,
+				"file":      node.File,
+				"line":      node.Line,
 			},
+			Position: node.Position,
 		})
+		if len(node.Attrs) > 0 {
+			antvg6Graph.Nodes[len(antvg6Graph.Nodes)-1].Data["attrs"] = node.Attrs
+		}
+		// Metrics of the symbol, for the details panel
+		for name, value := range map[string]int{"lines": node.Lines, "complexity": node.Complexity, "churn": node.Churn} {
+			if value != 0 {
+				antvg6Graph.Nodes[len(antvg6Graph.Nodes)-1].Data[name] = value
+			}
+		}
+		if cycle := cycles[node.ID]; cycle != 0 {
+			antvg6Graph.Nodes[len(antvg6Graph.Nodes)-1].Data["cycle"] = cycle
+		}
 		// Note: No structural edges - combo provides visual grouping
 	}
 
 	// Phase 4: Add dependency edges (only between actual nodes that exist)
+	comboParents := make(map[string]string)
+	for _, combo := range antvg6Graph.Combos {
+		comboParents[combo.ID] = combo.ParentID
+	}
 	nodeExists := make(map[string]bool)
+	comboNodes := make(map[string]int)
 	for _, node := range antvg6Graph.Nodes {
 		nodeExists[node.ID] = true
+		for comboID := node.ComboID; comboID != ""; comboID = comboParents[comboID] {
+			comboNodes[comboID]++
+		}
+	}
+
+	// Collapsed combos show the number of their nodes, those of their nested combos included
+	for _, combo := range antvg6Graph.Combos {
+		combo.Data["nodes"] = comboNodes[combo.ID]
 	}
 
 	// Track edges to prevent duplicates
@@ -215,6 +388,12 @@ func convertToAntVG6Format(depGraph *graph.DependencyGraph, _ Config) *AntVG6Gra
 					"linkType": "dependency",
 				},
 			})
+			if attrs := depGraph.EdgeAttrsOf(sourceID, targetID); len(attrs) > 0 {
+				antvg6Graph.Edges[len(antvg6Graph.Edges)-1].Data["attrs"] = attrs
+			}
+			if cycles[sourceID] != 0 && cycles[sourceID] == cycles[targetID] {
+				antvg6Graph.Edges[len(antvg6Graph.Edges)-1].Data["cyclic"] = true
+			}
 		}
 	}
 
@@ -222,7 +401,7 @@ func convertToAntVG6Format(depGraph *graph.DependencyGraph, _ Config) *AntVG6Gra
 }
 
 // writeAntVG6HTML generates a self-contained HTML page with embedded AntV G6
-func writeAntVG6HTML(writer io.Writer, antvg6Graph *AntVG6Graph) error {
+func writeAntVG6HTML(writer io.Writer, antvg6Graph *AntVG6Graph, theme string) error {
 	// Parse the embedded template
 	tmpl, err := template.ParseFS(antvg6TemplateFS, "templates/antvg6.html")
 	if err != nil {
@@ -237,9 +416,11 @@ func writeAntVG6HTML(writer io.Writer, antvg6Graph *AntVG6Graph) error {
 
 	// Prepare template data
 	data := struct {
-		Data template.JS
+		Data  template.JS
+		Theme string
 	}{
-		Data: template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
 	}
 
 	// Execute the template
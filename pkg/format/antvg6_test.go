@@ -2,6 +2,8 @@ package format
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"testing"
 
 	"go-depmap/pkg/graph"
@@ -131,3 +133,156 @@ func TestConvertToAntVG6Format(t *testing.T) {
 		}
 	}
 }
+
+func TestAntVG6Writer_Positions(t *testing.T) {
+	depGraph := graph.NewDependencyGraph()
+	depGraph.Nodes["pkg::F"] = &graph.Node{ID: "pkg::F", Name: "F", Package: "pkg", Kind: graph.KindFunction, Position: &graph.Position{X: 1, Y: 2}}
+	depGraph.Nodes["pkg::T"] = &graph.Node{ID: "pkg::T", Name: "T", Package: "pkg", Kind: graph.KindStruct, Position: &graph.Position{X: 3, Y: 4}}
+
+	var buf bytes.Buffer
+	if err := (&AntVG6Writer{}).Write(&buf, depGraph, Config{"pretty": false}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for _, want := range []string{`"id":"pkg::F"`, `"x":1,"y":2`, `"id":"type:pkg::T"`, `"x":3,"y":4`} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("Expected %s in the output, got %s", want, buf.String())
+		}
+	}
+}
+
+func TestAntVG6Writer_Cycles(t *testing.T) {
+	depGraph := graph.NewDependencyGraph()
+	for _, nodeID := range []string{"pkg::A", "pkg::B", "pkg::C"} {
+		depGraph.Nodes[nodeID] = &graph.Node{ID: nodeID, Name: nodeID, Package: "pkg", Kind: graph.KindFunction}
+	}
+	depGraph.AddEdge("pkg::A", "pkg::B")
+	depGraph.AddEdge("pkg::B", "pkg::A")
+	depGraph.AddEdge("pkg::B", "pkg::C")
+
+	antvg6Graph := convertToAntVG6Format(depGraph, Config{})
+
+	if antvg6Graph.Cycles != 1 {
+		t.Errorf("Expected 1 cycle, got %d", antvg6Graph.Cycles)
+	}
+	for _, node := range antvg6Graph.Nodes {
+		if _, inCycle := node.Data["cycle"]; inCycle != (node.ID != "pkg::C") {
+			t.Errorf("Unexpected cycle membership of %s: %v", node.ID, node.Data)
+		}
+	}
+	for _, edge := range antvg6Graph.Edges {
+		if cyclic := edge.Data["cyclic"] == true; cyclic != (edge.Target != "pkg::C") {
+			t.Errorf("Unexpected cyclic flag of %s: %v", edge.ID, edge.Data)
+		}
+	}
+}
+
+func TestAntVG6Writer_CollapseCombos(t *testing.T) {
+	depGraph := graph.NewDependencyGraph()
+	depGraph.Nodes["a::A1"] = &graph.Node{ID: "a::A1", Name: "A1", Package: "a", Kind: graph.KindFunction}
+	depGraph.Nodes["a::A2"] = &graph.Node{ID: "a::A2", Name: "A2", Package: "a", Kind: graph.KindFunction}
+	depGraph.Nodes["b::B"] = &graph.Node{ID: "b::B", Name: "B", Package: "b", Kind: graph.KindStruct}
+
+	for _, collapse := range []bool{false, true} {
+		antvg6Graph := convertToAntVG6Format(depGraph, Config{"collapseCombos": collapse})
+		if len(antvg6Graph.Combos) != 2 {
+			t.Fatalf("Expected 2 combos, got %d", len(antvg6Graph.Combos))
+		}
+		for _, combo := range antvg6Graph.Combos {
+			if combo.Collapsed != collapse {
+				t.Errorf("Expected collapsed %v for %s with collapseCombos %v", collapse, combo.ID, collapse)
+			}
+			if want := map[string]int{"pkg:a": 2, "pkg:b": 1}[combo.ID]; combo.Data["nodes"] != want {
+				t.Errorf("Expected %d nodes in %s, got %v", want, combo.ID, combo.Data["nodes"])
+			}
+		}
+	}
+}
+
+func TestAntVG6Writer_TypeCombos(t *testing.T) {
+	depGraph := graph.NewDependencyGraph()
+	depGraph.Nodes["a::T"] = &graph.Node{ID: "a::T", Name: "T", Package: "a", Kind: graph.KindStruct}
+	depGraph.Nodes["a::(*T).M"] = &graph.Node{ID: "a::(*T).M", Name: "(*T).M", Package: "a", Kind: graph.KindMethod}
+	depGraph.Nodes["a::U"] = &graph.Node{ID: "a::U", Name: "U", Package: "a", Kind: graph.KindStruct}
+	depGraph.Nodes["a::F"] = &graph.Node{ID: "a::F", Name: "F", Package: "a", Kind: graph.KindFunction}
+
+	antvg6Graph := convertToAntVG6Format(depGraph, Config{})
+
+	// Only T has methods, so only T has a combo, nested in the package combo
+	combos := make(map[string]AntVG6Combo)
+	for _, combo := range antvg6Graph.Combos {
+		combos[combo.ID] = combo
+	}
+	if len(combos) != 2 || combos["combo:a::T"].ParentID != "pkg:a" {
+		t.Fatalf("Expected the package combo and the combo of T in it, got %+v", antvg6Graph.Combos)
+	}
+	if combos["combo:a::T"].Data["nodes"] != 2 || combos["pkg:a"].Data["nodes"] != 4 {
+		t.Errorf("Expected 2 nodes in the combo of T and 4 in the package, got %v and %v",
+			combos["combo:a::T"].Data["nodes"], combos["pkg:a"].Data["nodes"])
+	}
+	want := map[string]string{"type:a::T": "combo:a::T", "a::(*T).M": "combo:a::T", "type:a::U": "pkg:a", "a::F": "pkg:a"}
+	for _, node := range antvg6Graph.Nodes {
+		if node.ComboID != want[node.ID] {
+			t.Errorf("Expected %s in %s, got %s", node.ID, want[node.ID], node.ComboID)
+		}
+	}
+
+	// Without groupByType, everything is in the package combo
+	antvg6Graph = convertToAntVG6Format(depGraph, Config{"groupByType": false})
+	if len(antvg6Graph.Combos) != 1 {
+		t.Errorf("Expected the package combo only, got %+v", antvg6Graph.Combos)
+	}
+	for _, node := range antvg6Graph.Nodes {
+		if node.ComboID != "pkg:a" {
+			t.Errorf("Expected %s in the package combo, got %s", node.ID, node.ComboID)
+		}
+	}
+}
+
+func TestAntVG6Writer_Layout(t *testing.T) {
+	depGraph := graph.NewDependencyGraph()
+	depGraph.Nodes["a::A"] = &graph.Node{ID: "a::A", Name: "A", Package: "a", Kind: graph.KindFunction}
+
+	tests := []struct {
+		config Config
+		want   string
+	}{
+		{Config{}, AntVG6LayoutForce},
+		{Config{"antvg6Layout": "dagre"}, AntVG6LayoutDagre},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := (&AntVG6Writer{}).Write(&buf, depGraph, tt.config); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var result AntVG6Graph
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to parse output: %v", err)
+		}
+		if result.Layout != tt.want {
+			t.Errorf("Expected the %s layout, got %q", tt.want, result.Layout)
+		}
+	}
+
+	if err := (&AntVG6Writer{}).Write(io.Discard, depGraph, Config{"antvg6Layout": "elk"}); err == nil {
+		t.Error("Expected an error for an unknown layout")
+	}
+}
+
+func TestAntVG6Writer_Details(t *testing.T) {
+	depGraph := graph.NewDependencyGraph()
+	depGraph.Nodes["pkg::A"] = &graph.Node{ID: "pkg::A", Name: "A", Package: "pkg", Kind: graph.KindFunction,
+		File: "a.go", Line: 3, Signature: "func A()", Complexity: 4}
+
+	antvg6Graph := convertToAntVG6Format(depGraph, Config{})
+
+	if len(antvg6Graph.Nodes) != 1 {
+		t.Fatalf("Expected 1 node, got %d", len(antvg6Graph.Nodes))
+	}
+	data := antvg6Graph.Nodes[0].Data
+	if data["signature"] != "func A()" || data["file"] != "a.go" || data["line"] != 3 || data["complexity"] != 4 {
+		t.Errorf("Expected the source and metrics in the node data, got %v", data)
+	}
+	if _, exists := data["churn"]; exists {
+		t.Errorf("Expected no churn without commits, got %v", data)
+	}
+}
@@ -0,0 +1,155 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// backstageEntityName matches the entity names Backstage accepts
+var backstageEntityName = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]{0,61}[a-zA-Z0-9])?$`)
+
+// BackstageWriter writes Backstage catalog entities, one Component per component of the
+// "backstageComponents" config key, an object mapping package patterns (a trailing /...
+// matches the packages below) to component names; the most specific pattern wins. Each
+// component lists the other components its packages depend on in spec.dependsOn, so that
+// Backstage's dependency graph shows them, and the packages and edge counts in annotations.
+// Packages matched by no pattern and external packages are left out.
+//
+// Backstage requires a type, a lifecycle and an owner: they come from the "backstageType"
+// (default service) and "backstageLifecycle" (default production) keys, and the owner from
+// the most common CODEOWNERS owner of the component's symbols, or the "backstageOwner" key
+// (default unknown).
+type BackstageWriter struct{}
+
+// backstageComponent is a component of the catalog and what it is made of
+type backstageComponent struct {
+	name      string
+	packages  map[string]bool
+	owners    map[string]int // Owner -> number of symbols
+	dependsOn map[string]int // Component name -> number of edges
+}
+
+// Write renders the catalog entities as a multi-document YAML stream
+func (w *BackstageWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	patterns, err := backstagePatterns(config)
+	if err != nil {
+		return err
+	}
+
+	components := make(map[string]*backstageComponent)
+	componentOf := make(map[string]*backstageComponent) // Node ID -> component
+	for nodeID, node := range depGraph.Nodes {
+		if node.External {
+			continue
+		}
+		name := packagePatternValue(patterns, node.Package)
+		if name == "" {
+			continue
+		}
+		component := components[name]
+		if component == nil {
+			component = &backstageComponent{name: name, packages: make(map[string]bool), owners: make(map[string]int), dependsOn: make(map[string]int)}
+			components[name] = component
+		}
+		component.packages[node.Package] = true
+		for _, owner := range strings.Fields(node.Owner) {
+			component.owners[owner]++
+		}
+		componentOf[nodeID] = component
+	}
+	for sourceID, targets := range depGraph.Edges {
+		source := componentOf[sourceID]
+		if source == nil {
+			continue
+		}
+		for _, targetID := range targets {
+			if target := componentOf[targetID]; target != nil && target != source {
+				source.dependsOn[target.name] += depGraph.EdgeWeight(sourceID, targetID)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	if depGraph.Metadata != nil {
+		fmt.Fprintf(&sb, "# Generated by %s\n", depGraph.Metadata)
+	}
+	for _, name := range slices.Sorted(maps.Keys(components)) {
+		component := components[name]
+		sb.WriteString("---\n")
+		sb.WriteString("apiVersion: backstage.io/v1alpha1\nkind: Component\nmetadata:\n")
+		fmt.Fprintf(&sb, "  name: %s\n", name)
+		sb.WriteString("  annotations:\n")
+		fmt.Fprintf(&sb, "    go-depmap/packages: %s\n", strconv.Quote(strings.Join(slices.Sorted(maps.Keys(component.packages)), ",")))
+		if len(component.dependsOn) > 0 {
+			edges := make([]string, 0, len(component.dependsOn))
+			for _, target := range slices.Sorted(maps.Keys(component.dependsOn)) {
+				edges = append(edges, fmt.Sprintf("%s=%d", target, component.dependsOn[target]))
+			}
+			fmt.Fprintf(&sb, "    go-depmap/dependency-edges: %s\n", strconv.Quote(strings.Join(edges, ",")))
+		}
+		sb.WriteString("spec:\n")
+		fmt.Fprintf(&sb, "  type: %s\n", strconv.Quote(config.GetString("backstageType", "service")))
+		fmt.Fprintf(&sb, "  lifecycle: %s\n", strconv.Quote(config.GetString("backstageLifecycle", "production")))
+		fmt.Fprintf(&sb, "  owner: %s\n", strconv.Quote(backstageOwner(component, config)))
+		if len(component.dependsOn) > 0 {
+			sb.WriteString("  dependsOn:\n")
+			for _, target := range slices.Sorted(maps.Keys(component.dependsOn)) {
+				fmt.Fprintf(&sb, "    - component:%s\n", target)
+			}
+		}
+	}
+
+	_, err = io.WriteString(writer, sb.String())
+	return err
+}
+
+// backstagePatterns reads the package patterns of the components from the config, checking
+// that the component names are valid entity names
+func backstagePatterns(config Config) (map[string]string, error) {
+	raw, ok := config["backstageComponents"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("the backstage format needs the backstageComponents config key, an object mapping package patterns to component names")
+	}
+	patterns := make(map[string]string, len(raw))
+	for pattern, value := range raw {
+		name, ok := value.(string)
+		if !ok || !backstageEntityName.MatchString(name) {
+			return nil, fmt.Errorf("invalid Backstage component name for %s: %v", pattern, value)
+		}
+		patterns[pattern] = name
+	}
+	return patterns, nil
+}
+
+// backstageOwner returns the most common owner of the component's symbols, the first in
+// sorted order among equals, or the configured default owner
+func backstageOwner(component *backstageComponent, config Config) string {
+	owner, count := "", 0
+	for _, candidate := range slices.Sorted(maps.Keys(component.owners)) {
+		if component.owners[candidate] > count {
+			owner, count = candidate, component.owners[candidate]
+		}
+	}
+	if owner == "" {
+		return config.GetString("backstageOwner", "unknown")
+	}
+	// CODEOWNERS owners become entity references: group:payments for @org/payments, and
+	// user:jane for @jane or jane@example.com
+	if team, ok := strings.CutPrefix(owner, "@"); ok {
+		if _, name, found := strings.Cut(team, "/"); found {
+			return "group:" + name
+		}
+		return "user:" + team
+	}
+	if user, _, found := strings.Cut(owner, "@"); found {
+		return "user:" + user
+	}
+	return owner
+}
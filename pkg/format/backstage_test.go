@@ -0,0 +1,80 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestBackstageWriter_Write(t *testing.T) {
+	g := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"example.com/shop/api::Serve":     {ID: "example.com/shop/api::Serve", Package: "example.com/shop/api", Owner: "@acme/web"},
+			"example.com/shop/billing::Pay":   {ID: "example.com/shop/billing::Pay", Package: "example.com/shop/billing", Owner: "@acme/payments"},
+			"example.com/shop/billing/db::Tx": {ID: "example.com/shop/billing/db::Tx", Package: "example.com/shop/billing/db", Owner: "@acme/payments @acme/dba"},
+			"example.com/shop/internal::Log":  {ID: "example.com/shop/internal::Log", Package: "example.com/shop/internal"},
+			"github.com/lib/pq::Open":         {ID: "github.com/lib/pq::Open", Package: "github.com/lib/pq", External: true},
+		},
+		Edges: map[string][]string{
+			"example.com/shop/api::Serve":   {"example.com/shop/billing::Pay", "example.com/shop/billing/db::Tx", "example.com/shop/internal::Log"},
+			"example.com/shop/billing::Pay": {"example.com/shop/billing/db::Tx", "github.com/lib/pq::Open"},
+		},
+	}
+	config := Config{"backstageComponents": map[string]any{
+		"example.com/shop/api":         "storefront",
+		"example.com/shop/billing/...": "billing",
+		"example.com/shop/billing/db":  "billing-db",
+	}}
+
+	var buf bytes.Buffer
+	if err := (&BackstageWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := buf.String()
+	if count := strings.Count(output, "kind: Component"); count != 3 {
+		t.Errorf("Expected 3 components, got %d:\n%s", count, output)
+	}
+	storefront := output[strings.Index(output, "name: storefront"):]
+	for _, want := range []string{
+		`go-depmap/dependency-edges: "billing=1,billing-db=1"`,
+		`owner: "group:web"`,
+		"  dependsOn:\n    - component:billing\n    - component:billing-db\n",
+	} {
+		if !strings.Contains(storefront, want) {
+			t.Errorf("Storefront should contain %q, got:\n%s", want, storefront)
+		}
+	}
+	billing := output[strings.Index(output, "name: billing\n"):strings.Index(output, "name: billing-db")]
+	if !strings.Contains(billing, `go-depmap/packages: "example.com/shop/billing"`) || !strings.Contains(billing, "- component:billing-db") {
+		t.Errorf("Expected the exact pattern to win over billing/..., got:\n%s", billing)
+	}
+	if strings.Contains(output, "internal") || strings.Contains(output, "github.com") {
+		t.Errorf("Unmapped and external packages should be left out, got:\n%s", output)
+	}
+
+	config["backstageComponents"] = map[string]any{"example.com/shop/api": "Not a name!"}
+	if err := (&BackstageWriter{}).Write(&buf, g, config); err == nil {
+		t.Error("Expected an error for an invalid component name")
+	}
+	if err := (&BackstageWriter{}).Write(&buf, g, Config{}); err == nil {
+		t.Error("Expected an error without backstageComponents")
+	}
+}
+
+func TestBackstageOwner(t *testing.T) {
+	for owner, want := range map[string]string{
+		"@acme/payments":   "group:payments",
+		"@jane":            "user:jane",
+		"jane@example.com": "user:jane",
+	} {
+		component := &backstageComponent{owners: map[string]int{owner: 1}}
+		if got := backstageOwner(component, Config{}); got != want {
+			t.Errorf("Owner %s = %s, want %s", owner, got, want)
+		}
+	}
+	if got := backstageOwner(&backstageComponent{}, Config{"backstageOwner": "group:platform"}); got != "group:platform" {
+		t.Errorf("Expected the configured owner, got %s", got)
+	}
+}
@@ -1,5 +1,14 @@
 package format
 
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"go-depmap/pkg/check"
+	"go-depmap/pkg/graph"
+)
+
 // Config represents configuration options for formatters
 type Config map[string]any
 
@@ -52,3 +61,73 @@ func (c Config) Has(key string) bool {
 	_, ok := c[key]
 	return ok
 }
+
+// GroupOf returns the group a node is colored by in formats with a single grouping attribute,
+// selected by the "groupBy" key: "package" (default), "owner" or "repo", the repository of
+// merged graphs (see graph.TagRepo). Nodes without an owner are grouped as "(unowned)", and
+// nodes without a repository as "(external)".
+func (c Config) GroupOf(node *graph.Node) string {
+	switch c.GetString("groupBy", "package") {
+	case "owner":
+		if node.Owner == "" {
+			return "(unowned)"
+		}
+		return node.Owner
+	case "repo":
+		if repo, ok := node.Attrs[graph.RepoAttr].(string); ok {
+			return repo
+		}
+		return "(external)"
+	}
+	return node.Package
+}
+
+// packagePatternValue returns the value of the most specific package pattern matching a
+// package, such as its Backstage component or palette color, where an exact pattern beats a
+// /... pattern with the same prefix, or "" if no pattern matches
+func packagePatternValue(patterns map[string]string, pkgPath string) string {
+	best, bestLength := "", -1
+	for _, pattern := range slices.Sorted(maps.Keys(patterns)) {
+		if !graph.MatchPackagePattern(pattern, pkgPath) {
+			continue
+		}
+		length := 2 * len(strings.TrimSuffix(pattern, "/..."))
+		if !strings.HasSuffix(pattern, "/...") {
+			length++
+		}
+		if length > bestLength {
+			best, bestLength = patterns[pattern], length
+		}
+	}
+	return best
+}
+
+// ScoringOptions returns the subgraph scoring options described by the config.
+// Supported keys are "scoring" (weighted, size, density, pagerank) and, for the
+// weighted strategy, "scoreNodeWeight", "scoreEdgeWeight" and "scoreDensityWeight".
+func (c Config) ScoringOptions() graph.ScoringOptions {
+	defaults := graph.DefaultScoringOptions()
+	return graph.ScoringOptions{
+		Strategy:      graph.ScoringStrategy(c.GetString("scoring", string(defaults.Strategy))),
+		NodeWeight:    c.GetFloat("scoreNodeWeight", defaults.NodeWeight),
+		EdgeWeight:    c.GetFloat("scoreEdgeWeight", defaults.EdgeWeight),
+		DensityWeight: c.GetFloat("scoreDensityWeight", defaults.DensityWeight),
+	}
+}
+
+// CheckOptions returns the rule checks described by the config, used by the junit and
+// gh-summary formats. Supported keys are "failOnCycles", "maxFanIn", "maxPackageDeps" and
+// "noRecursion", a comma-separated list of package patterns.
+func (c Config) CheckOptions() check.Options {
+	opts := check.Options{
+		FailOnCycles:   c.GetBool("failOnCycles", false),
+		MaxFanIn:       c.GetInt("maxFanIn", 0),
+		MaxPackageDeps: c.GetInt("maxPackageDeps", 0),
+	}
+	for _, pattern := range strings.Split(c.GetString("noRecursion", ""), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			opts.NoRecursion = append(opts.NoRecursion, pattern)
+		}
+	}
+	return opts
+}
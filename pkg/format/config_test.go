@@ -1,6 +1,10 @@
 package format
 
-import "testing"
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
 
 func TestConfig_GetString(t *testing.T) {
 	config := Config{
@@ -106,3 +110,51 @@ func TestConfig_Has(t *testing.T) {
 		t.Error("Has() = true, want false for missing key")
 	}
 }
+
+func TestConfig_ScoringOptions(t *testing.T) {
+	defaults := Config{}.ScoringOptions()
+	if defaults != graph.DefaultScoringOptions() {
+		t.Errorf("ScoringOptions() = %+v, want defaults", defaults)
+	}
+
+	config := Config{
+		"scoring":         "pagerank",
+		"scoreEdgeWeight": 3.5,
+	}
+	opts := config.ScoringOptions()
+	if opts.Strategy != graph.ScorePageRank {
+		t.Errorf("Strategy = %s, want pagerank", opts.Strategy)
+	}
+	if opts.EdgeWeight != 3.5 {
+		t.Errorf("EdgeWeight = %f, want 3.5", opts.EdgeWeight)
+	}
+	if opts.NodeWeight != 1.0 {
+		t.Errorf("NodeWeight = %f, want default 1.0", opts.NodeWeight)
+	}
+}
+
+func TestConfig_GroupOf(t *testing.T) {
+	owned := &graph.Node{Package: "example.com/db", Owner: "@org/storage"}
+	unowned := &graph.Node{Package: "example.com/api"}
+
+	if group := (Config{}).GroupOf(owned); group != "example.com/db" {
+		t.Errorf("Default group = %s, want the package", group)
+	}
+
+	config := Config{"groupBy": "owner"}
+	if group := config.GroupOf(owned); group != "@org/storage" {
+		t.Errorf("Owner group = %s, want @org/storage", group)
+	}
+	if group := config.GroupOf(unowned); group != "(unowned)" {
+		t.Errorf("Unowned group = %s, want (unowned)", group)
+	}
+
+	config = Config{"groupBy": "repo"}
+	owned.SetAttr(graph.RepoAttr, "example.com/db")
+	if group := config.GroupOf(owned); group != "example.com/db" {
+		t.Errorf("Repo group = %s, want example.com/db", group)
+	}
+	if group := config.GroupOf(unowned); group != "(external)" {
+		t.Errorf("Untagged group = %s, want (external)", group)
+	}
+}
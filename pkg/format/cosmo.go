@@ -1,10 +1,15 @@
 package format
 
 import (
+	"cmp"
 	"embed"
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"io"
+	"math"
+	"strconv"
+	"strings"
 
 	"go-depmap/pkg/graph"
 )
@@ -15,41 +20,114 @@ var cosmoTemplateFS embed.FS
 // CosmoWriter implements the Writer interface for Cosmograph visualization
 type CosmoWriter struct{}
 
+// cosmoNodeSize is the size of function and method nodes, which sizeBy scales
+const cosmoNodeSize = 4.0
+
+// Topologies of the Cosmograph graph, selected by the topology config key: the nodes attached
+// to synthetic package and type hubs, or the symbols and their dependencies only
+const (
+	CosmoTopologyHubs = "hubs"
+	CosmoTopologyFlat = "flat"
+)
+
+// Kinds of Cosmograph links: the hub-and-spoke links attaching the nodes to their package and
+// type hubs, and the dependency edges of the graph
+const (
+	CosmoLinkStructural = "structural"
+	CosmoLinkDependency = "dependency"
+)
+
 // CosmoNode represents a node in Cosmograph format
 type CosmoNode struct {
-	ID    string  `json:"id"`
-	Type  string  `json:"type"` // "package", "type", "function", "method"
-	Label string  `json:"label"`
-	Group string  `json:"group"` // Fully qualified package name for grouping
-	Color string  `json:"color"`
-	Size  float64 `json:"size"`
+	ID      string         `json:"id"`
+	Type    string         `json:"type"` // "package", "type", "function", "method"
+	Label   string         `json:"label"`
+	Group   string         `json:"group"` // Fully qualified package name for grouping
+	Color   string         `json:"color"`
+	Size    float64        `json:"size"`
+	Doc     string         `json:"doc,omitempty"`     // First sentence of the doc comment
+	Snippet string         `json:"snippet,omitempty"` // Embedded function source, when enabled
+	DocURL  string         `json:"doc_url,omitempty"` // pkg.go.dev URL of exported symbols
+	Attrs   map[string]any `json:"attrs,omitempty"`   // Custom attributes of the graph node
+
+	// Source and metrics of the symbol, for the details panel
+	Signature  string `json:"signature,omitempty"`
+	File       string `json:"file,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Lines      int    `json:"lines,omitempty"`
+	Complexity int    `json:"complexity,omitempty"`
+	Churn      int    `json:"churn,omitempty"`
+
+	*graph.Position // Coordinates x and y of a precomputed layout, which the template keeps
 }
 
 // CosmoLink represents a link in Cosmograph format
 type CosmoLink struct {
-	Source   string `json:"source"`
-	Target   string `json:"target"`
-	LinkType string `json:"linkType"` // "structural-package", "structural-type", "dependency"
+	Source   string         `json:"source"`
+	Target   string         `json:"target"`
+	Kind     string         `json:"kind"`            // CosmoLinkStructural or CosmoLinkDependency
+	LinkType string         `json:"linkType"`        // "structural-package", "structural-type", "dependency"
+	Attrs    map[string]any `json:"attrs,omitempty"` // Custom attributes of dependency links
 }
 
 // CosmoGraph is the complete data structure for Cosmograph
 type CosmoGraph struct {
-	Nodes []CosmoNode `json:"nodes"`
-	Links []CosmoLink `json:"links"`
+	Nodes    []CosmoNode     `json:"nodes"`
+	Links    []CosmoLink     `json:"links"`
+	ColorBy  *MetricScale    `json:"color_by,omitempty"` // Metric the nodes are colored by, for the legend
+	SizeBy   *MetricScale    `json:"size_by,omitempty"`  // Metric the nodes are sized by, for the legend
+	Topology string          `json:"topology"`           // CosmoTopologyHubs or CosmoTopologyFlat
+	Metadata *graph.Metadata `json:"metadata,omitempty"` // Provenance of the graph
 }
 
 // Write generates Cosmograph-compatible JSON or HTML output
 func (w *CosmoWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if _, err := paletteOf(config); err != nil {
+		return err
+	}
+	if _, err := cosmoTopology(config); err != nil {
+		return err
+	}
 	cosmoGraph := convertToCosmoFormat(depGraph, config)
 
+	// Heat coloring by a metric: type hubs take the color of their type, package hubs have none
+	colors, colorBy, err := nodeHeatColors(depGraph, config)
+	if err != nil {
+		return err
+	}
+	if colors != nil {
+		cosmoGraph.ColorBy = colorBy
+		for i, node := range cosmoGraph.Nodes {
+			cosmoGraph.Nodes[i].Color = cmp.Or(colors[strings.TrimPrefix(node.ID, "type:")], noMetricColor)
+		}
+	}
+
+	// Sizing by a metric instead of the hub sizes: package hubs keep theirs, type hubs take the
+	// size of their type, and the nodes without a value the smallest size
+	sizes, sizeBy, err := nodeMetricSizes(depGraph, config)
+	if err != nil {
+		return err
+	}
+	if sizes != nil {
+		cosmoGraph.SizeBy = sizeBy
+		for i, node := range cosmoGraph.Nodes {
+			if node.Type != "package" {
+				cosmoGraph.Nodes[i].Size = cosmoNodeSize * cmp.Or(sizes[strings.TrimPrefix(node.ID, "type:")], minMetricSize)
+			}
+		}
+	}
+
 	// Check if HTML page should be generated
 	if config.GetBool("htmlPage", false) {
-		return writeCosmographHTML(writer, cosmoGraph)
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeCosmographHTML(writer, cosmoGraph, theme)
 	}
 
 	// Otherwise, output JSON
 	var jsonData []byte
-	var err error
 
 	if config.GetBool("pretty", true) {
 		jsonData, err = json.MarshalIndent(cosmoGraph, "", "  ")
@@ -65,18 +143,37 @@ func (w *CosmoWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, c
 	return err
 }
 
-// convertToCosmoFormat converts DependencyGraph to Cosmograph format using Hub & Spoke model
-func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph {
+// cosmoTopology returns the topology of the Cosmograph graph, hubs by default
+func cosmoTopology(config Config) (string, error) {
+	switch topology := config.GetString("topology", CosmoTopologyHubs); topology {
+	case CosmoTopologyHubs, CosmoTopologyFlat:
+		return topology, nil
+	default:
+		return "", fmt.Errorf("invalid topology %q: expected %q or %q", topology, CosmoTopologyHubs, CosmoTopologyFlat)
+	}
+}
+
+// convertToCosmoFormat converts DependencyGraph to Cosmograph format using Hub & Spoke model,
+// or with the flat topology to the symbols and their dependencies only, so that the synthetic
+// hubs distort neither the degrees of the nodes nor the forces of the layout; Write reports
+// invalid topologies
+func convertToCosmoFormat(depGraph *graph.DependencyGraph, config Config) *CosmoGraph {
+	topology, _ := cosmoTopology(config)
+	hubs := topology != CosmoTopologyFlat
 	cosmoGraph := &CosmoGraph{
-		Nodes: make([]CosmoNode, 0),
-		Links: make([]CosmoLink, 0),
+		Topology: cmp.Or(topology, CosmoTopologyHubs),
+		Metadata: depGraph.Metadata,
+		Nodes:    make([]CosmoNode, 0),
+		Links:    make([]CosmoLink, 0),
 	}
 
 	// Track which hub nodes we've created
 	packageHubs := make(map[string]bool)
 	typeHubs := make(map[string]bool)
 
-	// Color palette for packages (using HSL to generate distinct colors)
+	// Color palette for packages (using HSL to generate distinct colors), unless the
+	// packageColors config sets them; Write reports invalid palettes
+	palette, _ := paletteOf(config)
 	packageColors := make(map[string]string)
 	colorIndex := 0
 
@@ -85,6 +182,10 @@ func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph
 		if color, exists := packageColors[pkgName]; exists {
 			return color
 		}
+		if color := palette.packageColor(pkgName); color != "" {
+			packageColors[pkgName] = color
+			return color
+		}
 		// Generate distinct hues across the spectrum
 		hue := (colorIndex * 137) % 360 // Golden angle for better distribution
 		colorIndex++
@@ -99,14 +200,25 @@ func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph
 		return hslToHex(h, s, l+amount)
 	}
 
+	// Nodes of a kind in the kindColors config take its color, unless their package has one
+	kindOrShade := func(node *graph.Node, shade string) string {
+		if palette.packageColor(node.Package) != "" {
+			return shade
+		}
+		return cmp.Or(palette.kindColor(node.Kind), shade)
+	}
+
 	// Helper to add node
 	addNode := func(node CosmoNode) {
 		cosmoGraph.Nodes = append(cosmoGraph.Nodes, node)
 	}
 
+	// Package hubs sit at the center of their nodes in a precomputed layout
+	packageCenters := centersByPackage(depGraph)
+
 	// Phase 1: Create package hub nodes
 	for _, node := range depGraph.Nodes {
-		if !packageHubs[node.Package] {
+		if hubs && !packageHubs[node.Package] {
 			packageHubs[node.Package] = true
 			pkgColor := getPackageColor(node.Package)
 			addNode(CosmoNode{
@@ -116,33 +228,53 @@ func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph
 				Group: node.Package,               // Package is its own group
 				Color: lightenColor(pkgColor, 35), // Very light color for packages
 				Size:  15.0,                       // Very large hub node
+
+				Position: packageCenters[node.Package],
 			})
 		}
 	}
 
-	// Phase 2: Create type hub nodes and link to package hubs
+	// Phase 2: Create type hub nodes and link to package hubs; the flat topology has plain type
+	// nodes instead, like those of functions
 	for _, node := range depGraph.Nodes {
-		if node.Kind == graph.KindType {
+		if node.Kind.IsType() {
 			typeID := "type:" + node.ID
 			if !typeHubs[typeID] {
 				typeHubs[typeID] = true
 				pkgColor := getPackageColor(node.Package)
+				typeColor := lightenColor(pkgColor, 15) // Moderately colored
+				typeSize := 8.0                         // Medium hub node
+				if !hubs {
+					typeID, typeColor, typeSize = node.ID, pkgColor, cosmoNodeSize
+				}
 				addNode(CosmoNode{
-					ID:    typeID,
-					Type:  "type",
-					Label: node.Name,
-					Group: node.Package,               // Group by package
-					Color: lightenColor(pkgColor, 15), // Moderately colored
-					Size:  8.0,                        // Medium hub node
+					ID:     typeID,
+					Type:   string(node.Kind),
+					Label:  node.Name,
+					Group:  node.Package, // Group by package
+					Color:  kindOrShade(node, typeColor),
+					Size:   typeSize,
+					Doc:    node.Summary(),
+					DocURL: node.DocURL,
+
+					Signature: node.Signature,
+					File:      node.File,
+					Line:      node.Line,
+					Churn:     node.Churn,
+
+					Position: node.Position,
 				})
 
 				// Link type to its package (structural link - thin)
-				pkgHubID := "pkg:" + node.Package
-				cosmoGraph.Links = append(cosmoGraph.Links, CosmoLink{
-					Source:   typeID,
-					Target:   pkgHubID,
-					LinkType: "structural-package",
-				})
+				if hubs {
+					pkgHubID := "pkg:" + node.Package
+					cosmoGraph.Links = append(cosmoGraph.Links, CosmoLink{
+						Source:   typeID,
+						Target:   pkgHubID,
+						Kind:     CosmoLinkStructural,
+						LinkType: "structural-package",
+					})
+				}
 			}
 		}
 	}
@@ -165,9 +297,8 @@ func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph
 			nodeType = "method"
 			nodeSize = 4.0 // Same as function
 			// Try to find the receiver type
-			receiverType := extractReceiverType(node.Name)
-			if receiverType != "" {
-				parentHub = "type:" + node.Package + "::" + receiverType
+			if receiverID := node.ReceiverID(); receiverID != "" {
+				parentHub = "type:" + receiverID
 				// If type hub doesn't exist, fall back to package
 				if !typeHubs[parentHub] {
 					parentHub = "pkg:" + node.Package
@@ -179,9 +310,14 @@ func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph
 				parentHub = "pkg:" + node.Package
 				structuralLinkType = "structural-package"
 			}
-		case graph.KindType:
+		case graph.KindType, graph.KindInterface, graph.KindStruct, graph.KindNamed, graph.KindAlias:
 			// Already added as hub, skip
 			continue
+		case graph.KindTest, graph.KindBenchmark, graph.KindFuzz, graph.KindExample:
+			nodeType = string(node.Kind)
+			nodeSize = 4.0
+			parentHub = "pkg:" + node.Package
+			structuralLinkType = "structural-package"
 		default:
 			nodeType = "unknown"
 			nodeSize = 4.0
@@ -190,20 +326,36 @@ func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph
 		}
 
 		addNode(CosmoNode{
-			ID:    node.ID,
-			Type:  nodeType,
-			Label: node.Name,
-			Group: node.Package, // Group by package
-			Color: pkgColor,     // Bright, full color for functions
-			Size:  nodeSize,
+			ID:      node.ID,
+			Type:    nodeType,
+			Label:   node.Name,
+			Group:   node.Package,                // Group by package
+			Color:   kindOrShade(node, pkgColor), // Bright, full color for functions
+			Size:    nodeSize,
+			Doc:     node.Summary(),
+			Snippet: node.Snippet,
+			DocURL:  node.DocURL,
+			Attrs:   node.Attrs,
+
+			Signature:  node.Signature,
+			File:       node.File,
+			Line:       node.Line,
+			Lines:      node.Lines,
+			Complexity: node.Complexity,
+			Churn:      node.Churn,
+
+			Position: node.Position,
 		})
 
 		// Link to parent hub (structural edge)
-		cosmoGraph.Links = append(cosmoGraph.Links, CosmoLink{
-			Source:   node.ID,
-			Target:   parentHub,
-			LinkType: structuralLinkType,
-		})
+		if hubs {
+			cosmoGraph.Links = append(cosmoGraph.Links, CosmoLink{
+				Source:   node.ID,
+				Target:   parentHub,
+				Kind:     CosmoLinkStructural,
+				LinkType: structuralLinkType,
+			})
+		}
 	}
 
 	// Phase 4: Add dependency edges (function -> function, function -> type, type -> type)
@@ -217,7 +369,9 @@ func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph
 			cosmoGraph.Links = append(cosmoGraph.Links, CosmoLink{
 				Source:   sourceID,
 				Target:   targetID,
+				Kind:     CosmoLinkDependency,
 				LinkType: "dependency",
+				Attrs:    depGraph.EdgeAttrsOf(sourceID, targetID),
 			})
 		}
 	}
@@ -225,8 +379,33 @@ func convertToCosmoFormat(depGraph *graph.DependencyGraph, _ Config) *CosmoGraph
 	return cosmoGraph
 }
 
+// centersByPackage returns the center of the nodes of each package in a precomputed layout,
+// or nil without a layout
+func centersByPackage(depGraph *graph.DependencyGraph) map[string]*graph.Position {
+	sums := make(map[string]*graph.Position)
+	counts := make(map[string]float64)
+	for _, node := range depGraph.Nodes {
+		if node.Position == nil {
+			return nil
+		}
+		sum := sums[node.Package]
+		if sum == nil {
+			sum = &graph.Position{}
+			sums[node.Package] = sum
+		}
+		sum.X += node.Position.X
+		sum.Y += node.Position.Y
+		counts[node.Package]++
+	}
+	for pkgPath, sum := range sums {
+		sum.X /= counts[pkgPath]
+		sum.Y /= counts[pkgPath]
+	}
+	return sums
+}
+
 // writeCosmographHTML generates a self-contained HTML page with embedded Cosmograph
-func writeCosmographHTML(writer io.Writer, cosmoGraph *CosmoGraph) error {
+func writeCosmographHTML(writer io.Writer, cosmoGraph *CosmoGraph, theme string) error {
 	// Parse the embedded template
 	tmpl, err := template.ParseFS(cosmoTemplateFS, "templates/cosmo.html")
 	if err != nil {
@@ -241,9 +420,11 @@ func writeCosmographHTML(writer io.Writer, cosmoGraph *CosmoGraph) error {
 
 	// Prepare template data
 	data := struct {
-		Data template.JS
+		Data  template.JS
+		Theme string
 	}{
-		Data: template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
 	}
 
 	// Execute the template
@@ -251,13 +432,19 @@ func writeCosmographHTML(writer io.Writer, cosmoGraph *CosmoGraph) error {
 }
 
 // Color conversion helpers
+
+// hslToHex converts a hue in degrees and a saturation and lightness in percent to a #rrggbb
+// color; the hue wraps around and the percentages are clamped to 0-100, so that lightening a
+// light color gives white
 func hslToHex(h, s, l int) string {
+	h = (h%360 + 360) % 360
+
 	// Convert HSL to RGB
-	sF := float64(s) / 100.0
-	lF := float64(l) / 100.0
+	sF := float64(min(max(s, 0), 100)) / 100.0
+	lF := float64(min(max(l, 0), 100)) / 100.0
 
 	c := (1 - abs(2*lF-1)) * sF
-	x := c * (1 - abs(float64((h/60)%2)-1))
+	x := c * (1 - abs(math.Mod(float64(h)/60, 2)-1))
 	m := lF - c/2
 
 	var r, g, b float64
@@ -277,18 +464,44 @@ func hslToHex(h, s, l int) string {
 	}
 
 	// Convert to 0-255 range
-	rInt := int((r + m) * 255)
-	gInt := int((g + m) * 255)
-	bInt := int((b + m) * 255)
+	rInt := int(math.Round((r + m) * 255))
+	gInt := int(math.Round((g + m) * 255))
+	bInt := int(math.Round((b + m) * 255))
 
 	// Format as hex
 	return rgbToHex(rInt, gInt, bInt)
 }
 
-func hexToHSL(_ string) (h, s, l int) {
-	// Simple approximation - just return some values
-	// In production, would parse hex and convert properly
-	return 0, 70, 50
+// hexToHSL converts a #rrggbb color to a hue in degrees and a saturation and lightness in
+// percent, rounded; a color that is not #rrggbb is black
+func hexToHSL(hexColor string) (h, s, l int) {
+	rgb, err := strconv.ParseUint(strings.TrimPrefix(hexColor, "#"), 16, 32)
+	if err != nil || len(hexColor) != 7 || hexColor[0] != '#' {
+		return 0, 0, 0
+	}
+	r := float64(rgb>>16&0xff) / 255
+	g := float64(rgb>>8&0xff) / 255
+	b := float64(rgb&0xff) / 255
+
+	hi := max(r, g, b)
+	lo := min(r, g, b)
+	lF := (hi + lo) / 2
+	if hi == lo {
+		return 0, 0, int(math.Round(lF * 100)) // A gray has no hue
+	}
+
+	c := hi - lo
+	sF := c / (1 - abs(2*lF-1))
+	var hF float64
+	switch hi {
+	case r:
+		hF = math.Mod((g-b)/c+6, 6)
+	case g:
+		hF = (b-r)/c + 2
+	default:
+		hF = (r-g)/c + 4
+	}
+	return int(math.Round(hF*60)) % 360, int(math.Round(sF * 100)), int(math.Round(lF * 100))
 }
 
 func rgbToHex(r, g, b int) string {
@@ -313,3 +313,198 @@ func TestCosmoWriter_ColorCoding(t *testing.T) {
 		}
 	}
 }
+
+func TestHexToHSL(t *testing.T) {
+	tests := []struct {
+		hex     string
+		h, s, l int
+	}{
+		{"#ff0000", 0, 100, 50},
+		{"#00ff00", 120, 100, 50},
+		{"#0000ff", 240, 100, 50},
+		{"#ffffff", 0, 0, 100},
+		{"#808080", 0, 0, 50},
+		{"#d9262a", 359, 70, 50},
+		{"#26d9a8", 164, 70, 50},
+		{"red", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		if h, s, l := hexToHSL(tt.hex); h != tt.h || s != tt.s || l != tt.l {
+			t.Errorf("Expected %s to be hsl(%d, %d, %d), got hsl(%d, %d, %d)", tt.hex, tt.h, tt.s, tt.l, h, s, l)
+		}
+	}
+}
+
+func TestHSLToHex_RoundTrip(t *testing.T) {
+	// The package hues of the writers, and the lightness of their hubs
+	for hue := 0; hue < 360; hue++ {
+		for _, l := range []int{50, 65, 85} {
+			color := hslToHex(hue, 70, l)
+			h, s, gotL := hexToHSL(color)
+			if hueDiff := (h - hue + 360) % 360; (hueDiff > 1 && hueDiff < 359) || s < 69 || s > 71 || gotL != l {
+				t.Errorf("Expected %s to be about hsl(%d, 70, %d), got hsl(%d, %d, %d)", color, hue, l, h, s, gotL)
+			}
+			if back := hslToHex(h, s, gotL); back != color && l == 50 {
+				t.Errorf("Expected hsl(%d, %d, %d) to be %s again, got %s", h, s, gotL, color, back)
+			}
+		}
+	}
+
+	if got := hslToHex(120, 70, 130); got != "#ffffff" {
+		t.Errorf("Expected a lightness above 100 to give white, got %s", got)
+	}
+}
+
+func TestCosmoWriter_InheritedHue(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg1::A"] = &graph.Node{ID: "pkg1::A", Name: "A", Kind: graph.KindFunction, Package: "pkg1"}
+	g.Nodes["pkg1::B"] = &graph.Node{ID: "pkg1::B", Name: "B", Kind: graph.KindStruct, Package: "pkg1"}
+
+	colors := make(map[string]string)
+	for _, node := range convertToCosmoFormat(g, Config{}).Nodes {
+		colors[node.ID] = node.Color
+	}
+	hue, _, l := hexToHSL(colors["pkg1::A"])
+	for _, id := range []string{"pkg:pkg1", "type:pkg1::B"} {
+		if h, _, hubL := hexToHSL(colors[id]); h < hue-1 || h > hue+1 || hubL <= l {
+			t.Errorf("Expected %s in a lighter %s, got %s", id, colors["pkg1::A"], colors[id])
+		}
+	}
+}
+
+func TestCosmoWriter_TestKinds(t *testing.T) {
+	g := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"pkg1::BenchmarkRun": {
+				ID:      "pkg1::BenchmarkRun",
+				Name:    "BenchmarkRun",
+				Kind:    graph.KindBenchmark,
+				Package: "example.com/pkg1",
+			},
+		},
+		Edges: map[string][]string{},
+	}
+
+	w := &CosmoWriter{}
+	var buf bytes.Buffer
+	if err := w.Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result CosmoGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+
+	for _, node := range result.Nodes {
+		if node.ID == "pkg1::BenchmarkRun" && node.Type != "benchmark" {
+			t.Errorf("Type = %s, want benchmark", node.Type)
+		}
+	}
+}
+
+func TestCosmoWriter_Positions(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg1::A"] = &graph.Node{ID: "pkg1::A", Name: "A", Kind: graph.KindFunction, Package: "pkg1", Position: &graph.Position{X: 0, Y: 10}}
+	g.Nodes["pkg1::B"] = &graph.Node{ID: "pkg1::B", Name: "B", Kind: graph.KindStruct, Package: "pkg1", Position: &graph.Position{X: 20, Y: 30}}
+
+	cosmoGraph := convertToCosmoFormat(g, Config{})
+
+	want := map[string]graph.Position{
+		"pkg:pkg1":     {X: 10, Y: 20}, // Package hubs at the center of their nodes
+		"type:pkg1::B": {X: 20, Y: 30},
+		"pkg1::A":      {X: 0, Y: 10},
+	}
+	for _, node := range cosmoGraph.Nodes {
+		if node.Position == nil || *node.Position != want[node.ID] {
+			t.Errorf("Expected node %s at %v, got %v", node.ID, want[node.ID], node.Position)
+		}
+	}
+
+	// Without a layout, no node is positioned
+	g.Nodes["pkg1::C"] = &graph.Node{ID: "pkg1::C", Name: "C", Kind: graph.KindFunction, Package: "pkg1"}
+	for _, node := range convertToCosmoFormat(g, Config{}).Nodes {
+		if node.ID == "pkg:pkg1" && node.Position != nil {
+			t.Errorf("Expected no package hub position with unpositioned nodes, got %v", node.Position)
+		}
+	}
+}
+
+func TestCosmoWriter_LinkKinds(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg1::A"] = &graph.Node{ID: "pkg1::A", Name: "A", Kind: graph.KindFunction, Package: "pkg1"}
+	g.Nodes["pkg1::B"] = &graph.Node{ID: "pkg1::B", Name: "B", Kind: graph.KindStruct, Package: "pkg1"}
+	g.AddEdge("pkg1::A", "pkg1::B")
+
+	kinds := make(map[string]int)
+	for _, link := range convertToCosmoFormat(g, Config{}).Links {
+		kinds[link.Kind]++
+		if (link.Kind == CosmoLinkDependency) != (link.LinkType == "dependency") {
+			t.Errorf("Expected the %s link from %s to %s to be of kind %s", link.LinkType, link.Source, link.Target, link.Kind)
+		}
+	}
+	// A to its package hub, the type hub of B to it, and the edge from A to B
+	if kinds[CosmoLinkStructural] != 2 || kinds[CosmoLinkDependency] != 1 {
+		t.Errorf("Expected 2 structural links and 1 dependency, got %v", kinds)
+	}
+}
+
+func TestCosmoWriter_FlatTopology(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg1::A"] = &graph.Node{ID: "pkg1::A", Name: "A", Kind: graph.KindFunction, Package: "pkg1"}
+	g.Nodes["pkg1::B"] = &graph.Node{ID: "pkg1::B", Name: "B", Kind: graph.KindStruct, Package: "pkg1"}
+	g.Nodes["pkg1::B.M"] = &graph.Node{ID: "pkg1::B.M", Name: "B.M", Kind: graph.KindMethod, Package: "pkg1"}
+	g.AddEdge("pkg1::A", "pkg1::B")
+
+	var buf bytes.Buffer
+	if err := (&CosmoWriter{}).Write(&buf, g, Config{"topology": "flat"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var result CosmoGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if result.Topology != CosmoTopologyFlat {
+		t.Errorf("Expected the flat topology, got %q", result.Topology)
+	}
+
+	// No hubs: the nodes are the symbols, the links their dependencies, which reach the types
+	if len(result.Nodes) != 3 {
+		t.Errorf("Expected the 3 symbols only, got %d nodes", len(result.Nodes))
+	}
+	for _, node := range result.Nodes {
+		if _, ok := g.Nodes[node.ID]; !ok || node.Size != cosmoNodeSize {
+			t.Errorf("Expected %s to be a symbol of size %v, got %v", node.ID, cosmoNodeSize, node.Size)
+		}
+	}
+	if len(result.Links) != 1 || result.Links[0].Kind != CosmoLinkDependency || result.Links[0].Target != "pkg1::B" {
+		t.Errorf("Expected the dependency from A to B only, got %+v", result.Links)
+	}
+
+	if convertToCosmoFormat(g, Config{}).Topology != CosmoTopologyHubs {
+		t.Error("Expected the hubs topology by default")
+	}
+	if err := (&CosmoWriter{}).Write(&buf, g, Config{"topology": "tree"}); err == nil {
+		t.Error("Expected an error for an unknown topology")
+	}
+}
+
+func TestCosmoWriter_Details(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg1::A"] = &graph.Node{ID: "pkg1::A", Name: "A", Kind: graph.KindFunction, Package: "pkg1",
+		File: "a.go", Line: 3, Signature: "func A() error", Lines: 12, Complexity: 4, Churn: 2}
+	g.Nodes["pkg1::B"] = &graph.Node{ID: "pkg1::B", Name: "B", Kind: graph.KindStruct, Package: "pkg1", File: "b.go", Line: 7}
+
+	for _, node := range convertToCosmoFormat(g, Config{}).Nodes {
+		switch node.ID {
+		case "pkg1::A":
+			if node.Signature != "func A() error" || node.File != "a.go" || node.Line != 3 || node.Lines != 12 || node.Complexity != 4 || node.Churn != 2 {
+				t.Errorf("Expected the source and metrics of A, got %+v", node)
+			}
+		case "type:pkg1::B":
+			if node.File != "b.go" || node.Line != 7 {
+				t.Errorf("Expected the source of the type hub, got %+v", node)
+			}
+		}
+	}
+}
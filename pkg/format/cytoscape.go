@@ -0,0 +1,230 @@
+package format
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/cytoscape.html
+var cytoscapeTemplateFS embed.FS
+
+// CytoscapeWriter implements the Writer interface for Cytoscape.js visualization
+type CytoscapeWriter struct{}
+
+// CytoscapeNodeData holds the data fields of a Cytoscape.js node element
+type CytoscapeNodeData struct {
+	ID        string         `json:"id"`
+	Label     string         `json:"label"`
+	Parent    string         `json:"parent,omitempty"` // Compound parent (package, receiver type or, with hierarchy, another container)
+	Kind      string         `json:"kind"`             // "package", "type", "function", "method"; "module", "directory" and "file" with hierarchy
+	Package   string         `json:"package"`
+	Color     string         `json:"color,omitempty"` // Palette color of the package or kind, replacing the kind color
+	File      string         `json:"file,omitempty"`
+	Line      int            `json:"line,omitempty"`
+	Signature string         `json:"signature,omitempty"`
+	Doc       string         `json:"doc,omitempty"`     // First sentence of the doc comment
+	Snippet   string         `json:"snippet,omitempty"` // Embedded function source, when enabled
+	DocURL    string         `json:"doc_url,omitempty"` // pkg.go.dev URL of exported symbols
+	Attrs     map[string]any `json:"attrs,omitempty"`   // Custom attributes of the graph node
+
+	// Metrics of the symbol, for the details panel
+	Lines      int `json:"lines,omitempty"`
+	Complexity int `json:"complexity,omitempty"`
+	Churn      int `json:"churn,omitempty"`
+}
+
+// CytoscapeEdgeData holds the data fields of a Cytoscape.js edge element
+type CytoscapeEdgeData struct {
+	ID     string         `json:"id"`
+	Source string         `json:"source"`
+	Target string         `json:"target"`
+	Attrs  map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph edge
+}
+
+// CytoscapeNode represents a node element in Cytoscape.js format
+type CytoscapeNode struct {
+	Data CytoscapeNodeData `json:"data"`
+}
+
+// CytoscapeEdge represents an edge element in Cytoscape.js format
+type CytoscapeEdge struct {
+	Data CytoscapeEdgeData `json:"data"`
+}
+
+// CytoscapeElements is the Cytoscape.js elements structure
+type CytoscapeElements struct {
+	Nodes    []CytoscapeNode `json:"nodes"`
+	Edges    []CytoscapeEdge `json:"edges"`
+	Metadata *graph.Metadata `json:"metadata,omitempty"` // Provenance of the graph
+}
+
+// Write generates Cytoscape.js elements JSON or HTML output
+func (w *CytoscapeWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if _, err := paletteOf(config); err != nil {
+		return err
+	}
+	elements := convertToCytoscapeFormat(depGraph, config)
+
+	// Check if HTML page should be generated
+	if config.GetBool("htmlPage", false) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeCytoscapeHTML(writer, elements, theme)
+	}
+
+	// Otherwise output JSON
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(elements)
+}
+
+// convertToCytoscapeFormat converts a DependencyGraph to Cytoscape.js elements.
+// Packages become compound nodes; with groupByType enabled, methods are nested
+// inside their receiver type, which is itself nested inside its package. With the
+// hierarchy config key, the compound nodes are those of graph.Hierarchy instead:
+// modules, the directories of the package tree, packages and files.
+func convertToCytoscapeFormat(depGraph *graph.DependencyGraph, config Config) *CytoscapeElements {
+	groupByType := config.GetBool("groupByType", true)
+	palette, _ := paletteOf(config) // Write reports invalid palettes
+
+	elements := &CytoscapeElements{
+		Metadata: depGraph.Metadata,
+		Nodes:    make([]CytoscapeNode, 0, len(depGraph.Nodes)),
+		Edges:    make([]CytoscapeEdge, 0),
+	}
+
+	// Sort node IDs so that the output is stable across runs (diff-friendly)
+	nodeIDs := slices.Sorted(maps.Keys(depGraph.Nodes))
+
+	// Phase 1: Create package compound nodes, or the containers of the hierarchy
+	var hierarchy *graph.Hierarchy
+	if config.GetBool("hierarchy", false) {
+		hierarchy = depGraph.Hierarchy(groupByType)
+		for _, containerID := range slices.Sorted(maps.Keys(hierarchy.Containers)) {
+			container := hierarchy.Containers[containerID]
+			data := CytoscapeNodeData{
+				ID:     container.ID,
+				Label:  container.Name,
+				Parent: container.Parent,
+				Kind:   string(container.Level),
+			}
+			if container.Level == graph.LevelPackage {
+				data.Package = container.Name
+				data.Color = palette.packageColor(container.Name)
+			}
+			elements.Nodes = append(elements.Nodes, CytoscapeNode{Data: data})
+		}
+	} else {
+		packageCompounds := make(map[string]bool)
+		for _, nodeID := range nodeIDs {
+			node := depGraph.Nodes[nodeID]
+			if !packageCompounds[node.Package] {
+				packageCompounds[node.Package] = true
+				elements.Nodes = append(elements.Nodes, CytoscapeNode{
+					Data: CytoscapeNodeData{
+						ID:      "pkg:" + node.Package,
+						Label:   node.Package,
+						Kind:    "package",
+						Package: node.Package,
+						Color:   palette.packageColor(node.Package),
+					},
+				})
+			}
+		}
+	}
+
+	// Phase 2: Create symbol nodes, nested in their package or receiver type
+	for _, nodeID := range nodeIDs {
+		node := depGraph.Nodes[nodeID]
+		parent := "pkg:" + node.Package
+
+		if hierarchy != nil {
+			parent = hierarchy.Parents[node.ID]
+		} else if groupByType && node.Kind == graph.KindMethod {
+			if _, exists := depGraph.Nodes[node.ReceiverID()]; exists {
+				parent = node.ReceiverID()
+			}
+		}
+
+		elements.Nodes = append(elements.Nodes, CytoscapeNode{
+			Data: CytoscapeNodeData{
+				ID:        node.ID,
+				Label:     node.Name,
+				Parent:    parent,
+				Kind:      string(node.Kind),
+				Package:   node.Package,
+				Color:     palette.nodeColor(node),
+				File:      node.File,
+				Line:      node.Line,
+				Signature: node.Signature,
+				Doc:       node.Summary(),
+				Snippet:   node.Snippet,
+				DocURL:    node.DocURL,
+				Attrs:     node.Attrs,
+
+				Lines:      node.Lines,
+				Complexity: node.Complexity,
+				Churn:      node.Churn,
+			},
+		})
+	}
+
+	// Phase 3: Add dependency edges (only between nodes that exist), by source ID
+	for _, sourceID := range nodeIDs {
+		for _, targetID := range depGraph.Edges[sourceID] {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			elements.Edges = append(elements.Edges, CytoscapeEdge{
+				Data: CytoscapeEdgeData{
+					ID:     sourceID + "->" + targetID,
+					Source: sourceID,
+					Target: targetID,
+					Attrs:  depGraph.EdgeAttrsOf(sourceID, targetID),
+				},
+			})
+		}
+	}
+
+	return elements
+}
+
+// writeCytoscapeHTML generates a self-contained HTML page with embedded Cytoscape.js
+func writeCytoscapeHTML(writer io.Writer, elements *CytoscapeElements, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(cytoscapeTemplateFS, "templates/cytoscape.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the graph data to JSON
+	jsonData, err := json.Marshal(elements)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
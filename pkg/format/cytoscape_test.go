@@ -0,0 +1,230 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestCytoscapeWriter_Write_JSON(t *testing.T) {
+	depGraph := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"pkg1::func1": {ID: "pkg1::func1", Name: "func1", Kind: graph.KindFunction, Package: "pkg1"},
+			"pkg1::Type1": {ID: "pkg1::Type1", Name: "Type1", Kind: graph.KindType, Package: "pkg1"},
+			"pkg1::(*Type1).Method1": {
+				ID:      "pkg1::(*Type1).Method1",
+				Name:    "(*Type1).Method1",
+				Kind:    graph.KindMethod,
+				Package: "pkg1",
+			},
+		},
+		Edges: map[string][]string{
+			"pkg1::func1":            {"pkg1::Type1"},
+			"pkg1::(*Type1).Method1": {"pkg1::func1", "pkg1::missing"},
+		},
+	}
+	w := &CytoscapeWriter{}
+	var buf bytes.Buffer
+
+	if err := w.Write(&buf, depGraph, Config{"pretty": true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result CytoscapeElements
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	// 3 symbols + 1 package compound
+	if len(result.Nodes) != 4 {
+		t.Errorf("Expected 4 nodes, got %d", len(result.Nodes))
+	}
+
+	// Edge to the missing node must be dropped
+	if len(result.Edges) != 2 {
+		t.Errorf("Expected 2 edges, got %d", len(result.Edges))
+	}
+}
+
+func TestConvertToCytoscapeFormat_CompoundNodes(t *testing.T) {
+	depGraph := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"pkg1::func1": {ID: "pkg1::func1", Name: "func1", Kind: graph.KindFunction, Package: "pkg1"},
+			"pkg1::Type1": {ID: "pkg1::Type1", Name: "Type1", Kind: graph.KindType, Package: "pkg1"},
+			"pkg1::(*Type1).Method1": {
+				ID:      "pkg1::(*Type1).Method1",
+				Name:    "(*Type1).Method1",
+				Kind:    graph.KindMethod,
+				Package: "pkg1",
+			},
+		},
+		Edges: map[string][]string{},
+	}
+
+	tests := []struct {
+		name         string
+		config       Config
+		methodParent string
+	}{
+		{
+			name:         "methods nested in receiver type",
+			config:       Config{},
+			methodParent: "pkg1::Type1",
+		},
+		{
+			name:         "methods nested in package",
+			config:       Config{"groupByType": false},
+			methodParent: "pkg:pkg1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := convertToCytoscapeFormat(depGraph, tt.config)
+
+			parents := make(map[string]string)
+			for _, node := range result.Nodes {
+				parents[node.Data.ID] = node.Data.Parent
+			}
+
+			if parent, ok := parents["pkg:pkg1"]; !ok || parent != "" {
+				t.Errorf("Expected top-level package compound, got parent %q", parent)
+			}
+			if parents["pkg1::func1"] != "pkg:pkg1" {
+				t.Errorf("Expected function parent pkg:pkg1, got %q", parents["pkg1::func1"])
+			}
+			if parents["pkg1::Type1"] != "pkg:pkg1" {
+				t.Errorf("Expected type parent pkg:pkg1, got %q", parents["pkg1::Type1"])
+			}
+			if parents["pkg1::(*Type1).Method1"] != tt.methodParent {
+				t.Errorf("Expected method parent %s, got %q", tt.methodParent, parents["pkg1::(*Type1).Method1"])
+			}
+		})
+	}
+}
+
+func TestCytoscapeWriter_Write_HTML(t *testing.T) {
+	depGraph := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"pkg1::func1": {ID: "pkg1::func1", Name: "func1", Kind: graph.KindFunction, Package: "pkg1"},
+		},
+		Edges: map[string][]string{},
+	}
+	w := &CytoscapeWriter{}
+	var buf bytes.Buffer
+
+	if err := w.Write(&buf, depGraph, Config{"htmlPage": true}); err != nil {
+		t.Fatalf("Write HTML failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") {
+		t.Error("Output should contain HTML doctype")
+	}
+	if !strings.Contains(output, "cose-bilkent") {
+		t.Error("Output should use the cose-bilkent layout")
+	}
+	if !strings.Contains(output, "pkg1::func1") {
+		t.Error("Output should embed the graph data")
+	}
+}
+
+func TestConvertToCytoscapeFormat_Metrics(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg::F"] = &graph.Node{ID: "pkg::F", Name: "F", Kind: graph.KindFunction, Package: "pkg", Lines: 20, Complexity: 5, Churn: 3}
+
+	for _, node := range convertToCytoscapeFormat(g, Config{}).Nodes {
+		if node.Data.ID == "pkg::F" && (node.Data.Lines != 20 || node.Data.Complexity != 5 || node.Data.Churn != 3) {
+			t.Errorf("Expected the metrics of F, got %+v", node.Data)
+		}
+	}
+}
+
+func TestConvertToCytoscapeFormat_RecordedReceiver(t *testing.T) {
+	// Methods of generic types are named without their receiver, which only the recorded
+	// receiver gives
+	depGraph := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"pkg1::Stack": {ID: "pkg1::Stack", Name: "Stack", Kind: graph.KindStruct, Package: "pkg1"},
+			"pkg1::Push":  {ID: "pkg1::Push", Name: "Push", Kind: graph.KindMethod, Package: "pkg1", Receiver: "pkg1::Stack"},
+		},
+		Edges: map[string][]string{},
+	}
+
+	for _, node := range convertToCytoscapeFormat(depGraph, Config{}).Nodes {
+		if node.Data.ID == "pkg1::Push" && node.Data.Parent != "pkg1::Stack" {
+			t.Errorf("Expected method parent pkg1::Stack, got %q", node.Data.Parent)
+		}
+	}
+}
+
+func TestConvertToCytoscapeFormat_Hierarchy(t *testing.T) {
+	depGraph := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"example.com/app/pkg/db::Conn": {ID: "example.com/app/pkg/db::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "example.com/app/pkg/db", Module: "example.com/app", File: "conn.go"},
+			"example.com/app/pkg/db::Conn.Close": {
+				ID: "example.com/app/pkg/db::Conn.Close", Name: "Conn.Close", Kind: graph.KindMethod, Package: "example.com/app/pkg/db", Module: "example.com/app", File: "close.go",
+				Receiver: "example.com/app/pkg/db::Conn",
+			},
+		},
+		Edges: map[string][]string{},
+	}
+
+	parents := make(map[string]string)
+	kinds := make(map[string]string)
+	for _, node := range convertToCytoscapeFormat(depGraph, Config{"hierarchy": true}).Nodes {
+		parents[node.Data.ID] = node.Data.Parent
+		kinds[node.Data.ID] = node.Data.Kind
+	}
+	expected := map[string]string{
+		"example.com/app/pkg/db::Conn.Close":  "example.com/app/pkg/db::Conn",
+		"example.com/app/pkg/db::Conn":        "file:example.com/app/pkg/db/conn.go",
+		"file:example.com/app/pkg/db/conn.go": "pkg:example.com/app/pkg/db",
+		"pkg:example.com/app/pkg/db":          "dir:example.com/app/pkg",
+		"dir:example.com/app/pkg":             "module:example.com/app",
+		"module:example.com/app":              "",
+	}
+	for nodeID, parent := range expected {
+		if got, exists := parents[nodeID]; !exists || got != parent {
+			t.Errorf("Expected %s in %q, got %q (exists: %v)", nodeID, parent, got, exists)
+		}
+	}
+	if kinds["file:example.com/app/pkg/db/conn.go"] != "file" || kinds["dir:example.com/app/pkg"] != "directory" {
+		t.Errorf("Expected the containers to have their level as kind, got %v", kinds)
+	}
+	if _, exists := parents["file:example.com/app/pkg/db/close.go"]; exists {
+		t.Error("Expected no container for the file of a method nested in its type")
+	}
+}
+
+func TestCytoscapeWriter_Write_Deterministic(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	for _, name := range []string{"E", "D", "C", "B", "A"} {
+		g.Nodes["pkg"+name+"::"+name] = &graph.Node{ID: "pkg" + name + "::" + name, Name: name, Kind: graph.KindFunction, Package: "pkg" + name}
+	}
+	g.AddEdge("pkgE::E", "pkgA::A")
+	g.AddEdge("pkgC::C", "pkgB::B")
+	g.AddEdge("pkgA::A", "pkgD::D")
+
+	var first bytes.Buffer
+	if err := (&CytoscapeWriter{}).Write(&first, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		var buf bytes.Buffer
+		if err := (&CytoscapeWriter{}).Write(&buf, g, Config{}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if buf.String() != first.String() {
+			t.Fatalf("Expected the same output on every run, got:\n%s\nthen:\n%s", first.String(), buf.String())
+		}
+	}
+
+	elements := convertToCytoscapeFormat(g, Config{})
+	if first, last := elements.Edges[0].Data.ID, elements.Edges[2].Data.ID; first != "pkgA::A->pkgD::D" || last != "pkgE::E->pkgA::A" {
+		t.Errorf("Expected the edges sorted by source, got %s first and %s last", first, last)
+	}
+}
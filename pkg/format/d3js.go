@@ -1,10 +1,13 @@
 package format
 
 import (
+	"cmp"
 	"embed"
 	"encoding/json"
 	"html/template"
 	"io"
+	"slices"
+	"strings"
 
 	"go-depmap/pkg/graph"
 )
@@ -14,39 +17,60 @@ var templateFS embed.FS
 
 // D3JSNode represents a node in D3.js force-directed graph format
 type D3JSNode struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Kind      string `json:"kind"`
-	Package   string `json:"package"`
-	File      string `json:"file"`
-	Line      int    `json:"line"`
-	Signature string `json:"signature"`
-	Group     int    `json:"group"`      // For coloring by kind
-	PackageID string `json:"package_id"` // Fully qualified package name for grouping
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Kind       string         `json:"kind"`
+	Package    string         `json:"package"`
+	File       string         `json:"file"`
+	Line       int            `json:"line"`
+	Signature  string         `json:"signature"`
+	Doc        string         `json:"doc,omitempty"`        // First sentence of the doc comment
+	Snippet    string         `json:"snippet,omitempty"`    // Embedded function source, when enabled
+	DocURL     string         `json:"doc_url,omitempty"`    // pkg.go.dev URL of exported symbols
+	Lines      int            `json:"lines,omitempty"`      // Lines of code of functions
+	Complexity int            `json:"complexity,omitempty"` // Cyclomatic complexity of functions
+	Churn      int            `json:"churn,omitempty"`      // Commits that changed the source file
+	Spans      int            `json:"spans,omitempty"`      // Trace spans mapped onto the node
+	Cycle      int            `json:"cycle,omitempty"`      // 1-based index of the dependency cycle of the node, see graph.CycleMembership
+	Color      string         `json:"color,omitempty"`      // Palette color of the node's package or kind, or of its value of the colorBy metric, replacing the kind color
+	Size       float64        `json:"size,omitempty"`       // Radius of the node's value of the sizeBy metric, as a factor of the default radius
+	Group      int            `json:"group"`                // For coloring by kind
+	PackageID  string         `json:"package_id"`           // Fully qualified package name for grouping
+	Attrs      map[string]any `json:"attrs,omitempty"`      // Custom attributes of the graph node
+
+	*graph.Position // Coordinates x and y of a precomputed layout, which the template keeps
 }
 
 // D3JSLink represents an edge in D3.js force-directed graph format
 type D3JSLink struct {
-	Source string `json:"source"`
-	Target string `json:"target"`
-	Value  int    `json:"value"` // Weight of the edge (can be used for styling)
+	Source string         `json:"source"`
+	Target string         `json:"target"`
+	Value  int            `json:"value"`            // Weight of the edge (can be used for styling)
+	Traced bool           `json:"traced,omitempty"` // Observed in a trace
+	Cyclic bool           `json:"cyclic,omitempty"` // Part of a dependency cycle
+	Attrs  map[string]any `json:"attrs,omitempty"`  // Custom attributes of the graph edge
 }
 
 // D3JSGroup represents a hierarchical group for WebCola constraint-based layout
 type D3JSGroup struct {
-	ID      string `json:"id"`               // Unique identifier for the group
-	Label   string `json:"label"`            // Display label
-	Leaves  []int  `json:"leaves,omitempty"` // Indices of nodes in this group
-	Groups  []int  `json:"groups,omitempty"` // Indices of nested groups
-	Level   string `json:"level"`            // "package" or "type"
-	Padding int    `json:"padding"`          // Padding around the group in pixels
+	ID        string `json:"id"`                  // Unique identifier for the group
+	Label     string `json:"label"`               // Display label
+	Leaves    []int  `json:"leaves,omitempty"`    // Indices of nodes in this group
+	Groups    []int  `json:"groups,omitempty"`    // Indices of nested groups
+	Level     string `json:"level"`               // "package" or "type"
+	Padding   int    `json:"padding"`             // Padding around the group in pixels
+	Collapsed bool   `json:"collapsed,omitempty"` // Drawn as a single meta-node until expanded
 }
 
 // D3JSGraph is the D3.js compatible graph structure with hierarchical grouping
 type D3JSGraph struct {
-	Nodes  []D3JSNode  `json:"nodes"`
-	Links  []D3JSLink  `json:"links"`
-	Groups []D3JSGroup `json:"groups,omitempty"` // Hierarchical groups for WebCola layout
+	Nodes    []D3JSNode      `json:"nodes"`
+	Links    []D3JSLink      `json:"links"`
+	Groups   []D3JSGroup     `json:"groups,omitempty"`   // Hierarchical groups for WebCola layout
+	Cycles   int             `json:"cycles,omitempty"`   // Number of dependency cycles
+	ColorBy  *MetricScale    `json:"color_by,omitempty"` // Metric the nodes are colored by, for the legend
+	SizeBy   *MetricScale    `json:"size_by,omitempty"`  // Metric the nodes are sized by, for the legend
+	Metadata *graph.Metadata `json:"metadata,omitempty"` // Provenance of the graph
 }
 
 // D3JSWriter writes the graph in D3.js force-directed graph format
@@ -59,9 +83,53 @@ func (w *D3JSWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, co
 
 	d3Graph := convertToD3Format(depGraph, groupByPackage, groupByType)
 
+	// Package groups start collapsed into meta-nodes, as an overview of the packages
+	if config.GetBool("collapseGroups", false) {
+		for i := range d3Graph.Groups {
+			d3Graph.Groups[i].Collapsed = d3Graph.Groups[i].Level == "package"
+		}
+	}
+
+	// Palette colors of the packages and kinds, which the heat coloring replaces
+	palette, err := paletteOf(config)
+	if err != nil {
+		return err
+	}
+	for i := range d3Graph.Nodes {
+		d3Graph.Nodes[i].Color = palette.nodeColor(depGraph.Nodes[d3Graph.Nodes[i].ID])
+	}
+
+	// Heat coloring by a metric
+	colors, colorBy, err := nodeHeatColors(depGraph, config)
+	if err != nil {
+		return err
+	}
+	if colors != nil {
+		d3Graph.ColorBy = colorBy
+		for i := range d3Graph.Nodes {
+			d3Graph.Nodes[i].Color = cmp.Or(colors[d3Graph.Nodes[i].ID], noMetricColor)
+		}
+	}
+
+	// Sizing by a metric, nodes without a value taking the smallest size
+	sizes, sizeBy, err := nodeMetricSizes(depGraph, config)
+	if err != nil {
+		return err
+	}
+	if sizes != nil {
+		d3Graph.SizeBy = sizeBy
+		for i := range d3Graph.Nodes {
+			d3Graph.Nodes[i].Size = cmp.Or(sizes[d3Graph.Nodes[i].ID], minMetricSize)
+		}
+	}
+
 	// Check if HTML page output is requested
 	if config.GetBool("htmlPage", false) {
-		return writeHTMLPage(writer, d3Graph)
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeHTMLPage(writer, d3Graph, theme)
 	}
 
 	// Otherwise output JSON
@@ -78,16 +146,31 @@ func (w *D3JSWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, co
 // convertToD3Format converts a DependencyGraph to D3.js format with optional package grouping
 func convertToD3Format(depGraph *graph.DependencyGraph, groupByPackage bool, groupByType bool) *D3JSGraph {
 	d3Graph := &D3JSGraph{
-		Nodes:  make([]D3JSNode, 0, len(depGraph.Nodes)),
-		Links:  make([]D3JSLink, 0),
-		Groups: make([]D3JSGroup, 0),
+		Metadata: depGraph.Metadata,
+		Nodes:    make([]D3JSNode, 0, len(depGraph.Nodes)),
+		Links:    make([]D3JSLink, 0),
+		Groups:   make([]D3JSGroup, 0),
+	}
+
+	// Cycle membership, which the page highlights
+	cycles := depGraph.CycleMembership()
+	for _, cycle := range cycles {
+		d3Graph.Cycles = max(d3Graph.Cycles, cycle)
 	}
 
 	// Map to assign group numbers based on kind
 	kindToGroup := map[string]int{
-		"function": 1,
-		"method":   2,
-		"type":     3,
+		"function":  1,
+		"method":    2,
+		"type":      3,
+		"test":      4,
+		"benchmark": 5,
+		"fuzz":      6,
+		"example":   7,
+		"interface": 8,
+		"struct":    9,
+		"named":     10,
+		"alias":     11,
 	}
 
 	// Maps for tracking grouping
@@ -100,15 +183,25 @@ func convertToD3Format(depGraph *graph.DependencyGraph, groupByPackage bool, gro
 	for _, node := range depGraph.Nodes {
 		group := kindToGroup[string(node.Kind)]
 		d3Node := D3JSNode{
-			ID:        node.ID,
-			Name:      node.Name,
-			Kind:      string(node.Kind),
-			Package:   node.Package,
-			File:      node.File,
-			Line:      node.Line,
-			Signature: node.Signature,
-			Group:     group,
-			PackageID: node.Package,
+			ID:         node.ID,
+			Name:       node.Name,
+			Kind:       string(node.Kind),
+			Package:    node.Package,
+			File:       node.File,
+			Line:       node.Line,
+			Signature:  node.Signature,
+			Doc:        node.Summary(),
+			Snippet:    node.Snippet,
+			DocURL:     node.DocURL,
+			Lines:      node.Lines,
+			Complexity: node.Complexity,
+			Churn:      node.Churn,
+			Spans:      node.Spans,
+			Cycle:      cycles[node.ID],
+			Group:      group,
+			PackageID:  node.Package,
+			Attrs:      node.Attrs,
+			Position:   node.Position,
 		}
 
 		nodeIndex := len(d3Graph.Nodes)
@@ -120,9 +213,8 @@ func convertToD3Format(depGraph *graph.DependencyGraph, groupByPackage bool, gro
 
 		// Track methods by their receiver type
 		if node.Kind == graph.KindMethod {
-			// Extract receiver type from method name (format: "(*Type).method" or "Type.method")
-			receiverType := extractReceiverType(node.Name)
-			if receiverType != "" {
+			if receiverID := node.ReceiverID(); receiverID != "" {
+				receiverType := strings.TrimPrefix(receiverID, node.Package+"::")
 				if packageTypeNodes[node.Package] == nil {
 					packageTypeNodes[node.Package] = make(map[string][]string)
 				}
@@ -132,7 +224,7 @@ func convertToD3Format(depGraph *graph.DependencyGraph, groupByPackage bool, gro
 		}
 
 		// Track type declarations
-		if node.Kind == graph.KindType {
+		if node.Kind.IsType() {
 			typeToPackage[node.Name] = node.Package
 		}
 	}
@@ -143,7 +235,10 @@ func convertToD3Format(depGraph *graph.DependencyGraph, groupByPackage bool, gro
 			d3Graph.Links = append(d3Graph.Links, D3JSLink{
 				Source: sourceID,
 				Target: targetID,
-				Value:  1,
+				Value:  depGraph.EdgeWeight(sourceID, targetID),
+				Traced: slices.Contains(depGraph.EdgeKindsOf(sourceID, targetID), graph.EdgeTraced),
+				Cyclic: cycles[sourceID] != 0 && cycles[sourceID] == cycles[targetID],
+				Attrs:  depGraph.EdgeAttrsOf(sourceID, targetID),
 			})
 		}
 	}
@@ -217,53 +312,8 @@ func convertToD3Format(depGraph *graph.DependencyGraph, groupByPackage bool, gro
 	return d3Graph
 }
 
-// extractReceiverType extracts the receiver type name from a method name
-// Handles formats: "(*Type).method" or "Type.method"
-func extractReceiverType(methodName string) string {
-	// Look for pattern: (optional *)(Type).method
-	if idx := len(methodName); idx > 0 {
-		// Find the first dot (method separator)
-		dotIdx := -1
-		parenDepth := 0
-		for i, ch := range methodName {
-			if ch == '(' {
-				parenDepth++
-			} else if ch == ')' {
-				parenDepth--
-			} else if ch == '.' && parenDepth == 0 {
-				dotIdx = i
-				break
-			}
-		}
-
-		if dotIdx > 0 {
-			receiver := methodName[:dotIdx]
-			// Remove (*  or (
-			receiver = trimReceiverParens(receiver)
-			return receiver
-		}
-	}
-	return ""
-}
-
-// trimReceiverParens removes parentheses and pointer markers from receiver
-func trimReceiverParens(receiver string) string {
-	// Remove leading (* or (
-	if len(receiver) > 0 && receiver[0] == '(' {
-		receiver = receiver[1:]
-		if len(receiver) > 0 && receiver[0] == '*' {
-			receiver = receiver[1:]
-		}
-	}
-	// Remove trailing )
-	if len(receiver) > 0 && receiver[len(receiver)-1] == ')' {
-		receiver = receiver[:len(receiver)-1]
-	}
-	return receiver
-}
-
 // writeHTMLPage generates a self-contained HTML page with embedded D3.js/WebCola visualization
-func writeHTMLPage(writer io.Writer, d3Graph *D3JSGraph) error {
+func writeHTMLPage(writer io.Writer, d3Graph *D3JSGraph, theme string) error {
 	// Parse the embedded template
 	tmpl, err := template.ParseFS(templateFS, "templates/d3js.html")
 	if err != nil {
@@ -278,9 +328,11 @@ func writeHTMLPage(writer io.Writer, d3Graph *D3JSGraph) error {
 
 	// Prepare template data
 	data := struct {
-		Data template.JS
+		Data  template.JS
+		Theme string
 	}{
-		Data: template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
 	}
 
 	// Execute the template
@@ -221,6 +221,13 @@ func Test_D3JSNode_GroupAssignment(t *testing.T) {
 		{graph.KindFunction, 1},
 		{graph.KindMethod, 2},
 		{graph.KindType, 3},
+		{graph.KindTest, 4},
+		{graph.KindBenchmark, 5},
+		{graph.KindFuzz, 6},
+		{graph.KindExample, 7},
+		{graph.KindInterface, 8},
+		{graph.KindStruct, 9},
+		{graph.KindNamed, 10},
 	}
 
 	for _, tt := range tests {
@@ -431,29 +438,6 @@ func Test_ConvertToD3Format_HierarchicalGrouping(t *testing.T) {
 	}
 }
 
-func Test_ExtractReceiverType(t *testing.T) {
-	tests := []struct {
-		methodName string
-		expected   string
-	}{
-		{"(*Type).Method", "Type"},
-		{"(Type).Method", "Type"},
-		{"(*MyStruct).DoSomething", "MyStruct"},
-		{"(MyStruct).DoSomething", "MyStruct"},
-		{"func1", ""}, // Not a method
-		{"", ""},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.methodName, func(t *testing.T) {
-			result := extractReceiverType(tt.methodName)
-			if result != tt.expected {
-				t.Errorf("extractReceiverType(%q) = %q, want %q", tt.methodName, result, tt.expected)
-			}
-		})
-	}
-}
-
 func Test_ConvertToD3Format_GroupingOptions(t *testing.T) {
 	graph := &graph.DependencyGraph{
 		Nodes: map[string]*graph.Node{
@@ -484,3 +468,113 @@ func Test_ConvertToD3Format_GroupingOptions(t *testing.T) {
 		}
 	})
 }
+
+func Test_D3JSWriter_Attrs(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["test::func1"] = &graph.Node{ID: "test::func1", Name: "func1", Kind: graph.KindFunction, Package: "test"}
+	g.Nodes["test::func2"] = &graph.Node{ID: "test::func2", Name: "func2", Kind: graph.KindFunction, Package: "test"}
+	g.Nodes["test::func1"].SetAttr("team", "payments")
+	g.SetEdgeAttr("test::func1", "test::func2", "calls", 2)
+
+	d3Graph := convertToD3Format(g, false, false)
+
+	for _, node := range d3Graph.Nodes {
+		if node.ID == "test::func1" && node.Attrs["team"] != "payments" {
+			t.Errorf("Expected node attributes to be passed through, got %v", node.Attrs)
+		}
+	}
+	if len(d3Graph.Links) != 1 || d3Graph.Links[0].Attrs["calls"] != 2 {
+		t.Errorf("Expected edge attributes to be passed through, got %+v", d3Graph.Links)
+	}
+}
+
+func Test_D3JSWriter_EdgeWeight(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["a"] = &graph.Node{ID: "a", Name: "a", Kind: graph.KindPackage, Package: "a"}
+	g.Nodes["b"] = &graph.Node{ID: "b", Name: "b", Kind: graph.KindPackage, Package: "b"}
+	g.AddEdge("a", "b", graph.EdgeBundled)
+	g.SetEdgeAttr("a", "b", graph.WeightAttr, 7)
+
+	d3Graph := convertToD3Format(g, false, false)
+
+	if len(d3Graph.Links) != 1 || d3Graph.Links[0].Value != 7 {
+		t.Errorf("Expected the link value to be the edge weight, got %+v", d3Graph.Links)
+	}
+}
+
+func Test_D3JSWriter_Positions(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["test::func1"] = &graph.Node{ID: "test::func1", Name: "func1", Kind: graph.KindFunction, Package: "test", Position: &graph.Position{X: 12.5, Y: -3}}
+	g.Nodes["test::func2"] = &graph.Node{ID: "test::func2", Name: "func2", Kind: graph.KindFunction, Package: "test"}
+
+	var buf bytes.Buffer
+	if err := (&D3JSWriter{}).Write(&buf, g, Config{"pretty": false}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"x":12.5,"y":-3`)) {
+		t.Errorf("Expected the position in the node payload, got %s", buf.String())
+	}
+	var result struct {
+		Nodes []map[string]any `json:"nodes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range result.Nodes {
+		if _, exists := node["x"]; node["id"] == "test::func2" && exists {
+			t.Errorf("Expected no position without a layout, got %v", node)
+		}
+	}
+}
+
+func Test_ConvertToD3Format_Cycles(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	for _, nodeID := range []string{"a::A", "a::B", "a::C"} {
+		g.Nodes[nodeID] = &graph.Node{ID: nodeID, Name: nodeID, Kind: graph.KindFunction, Package: "a"}
+	}
+	g.AddEdge("a::A", "a::B")
+	g.AddEdge("a::B", "a::A")
+	g.AddEdge("a::B", "a::C")
+
+	d3Graph := convertToD3Format(g, false, false)
+
+	if d3Graph.Cycles != 1 {
+		t.Errorf("Expected 1 cycle, got %d", d3Graph.Cycles)
+	}
+	for _, node := range d3Graph.Nodes {
+		if want := map[string]int{"a::A": 1, "a::B": 1}[node.ID]; node.Cycle != want {
+			t.Errorf("Expected cycle %d for %s, got %d", want, node.ID, node.Cycle)
+		}
+	}
+	for _, link := range d3Graph.Links {
+		if want := link.Target != "a::C"; link.Cyclic != want {
+			t.Errorf("Expected cyclic %v for %s -> %s", want, link.Source, link.Target)
+		}
+	}
+}
+
+func Test_D3JSWriter_CollapseGroups(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["test::T"] = &graph.Node{ID: "test::T", Name: "T", Kind: graph.KindStruct, Package: "test"}
+	g.Nodes["test::T.M"] = &graph.Node{ID: "test::T.M", Name: "T.M", Kind: graph.KindMethod, Package: "test"}
+	g.Nodes["test::F"] = &graph.Node{ID: "test::F", Name: "F", Kind: graph.KindFunction, Package: "test"}
+
+	for _, collapse := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := (&D3JSWriter{}).Write(&buf, g, Config{"collapseGroups": collapse}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		var d3Graph D3JSGraph
+		if err := json.Unmarshal(buf.Bytes(), &d3Graph); err != nil {
+			t.Fatalf("Failed to parse output: %v", err)
+		}
+		if len(d3Graph.Groups) == 0 {
+			t.Fatal("Expected groups")
+		}
+		for _, group := range d3Graph.Groups {
+			if want := collapse && group.Level == "package"; group.Collapsed != want {
+				t.Errorf("Expected collapsed %v for the %s group %s with collapseGroups %v", want, group.Level, group.ID, collapse)
+			}
+		}
+	}
+}
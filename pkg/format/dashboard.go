@@ -0,0 +1,219 @@
+package format
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"sort"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/dashboard.html
+var dashboardTemplateFS embed.FS
+
+// DashboardWriter writes an HTML report with a graph view, package metrics,
+// cycles, and the subgraph ranking computed by the analyzer
+type DashboardWriter struct{}
+
+// DashboardSummary holds the headline numbers shown at the top of the dashboard
+type DashboardSummary struct {
+	Nodes     int `json:"nodes"`
+	Edges     int `json:"edges"`
+	Packages  int `json:"packages"`
+	Cycles    int `json:"cycles"`
+	Subgraphs int `json:"subgraphs"`
+}
+
+// DashboardNode represents a node in the dashboard graph view
+type DashboardNode struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Kind       string         `json:"kind"`
+	Package    string         `json:"package"`
+	File       string         `json:"file"`
+	Line       int            `json:"line"`
+	Doc        string         `json:"doc,omitempty"`        // First sentence of the doc comment
+	DocURL     string         `json:"doc_url,omitempty"`    // pkg.go.dev URL of exported symbols
+	Lines      int            `json:"lines,omitempty"`      // Lines of code of functions
+	Complexity int            `json:"complexity,omitempty"` // Cyclomatic complexity of functions
+	Churn      int            `json:"churn,omitempty"`      // Commits that changed the source file
+	Spans      int            `json:"spans,omitempty"`      // Trace spans mapped onto the node
+	Color      string         `json:"color,omitempty"`      // Palette color of the package or kind, replacing the kind color
+	Subgraph   int            `json:"subgraph"`
+	FanIn      int            `json:"fan_in"`
+	FanOut     int            `json:"fan_out"`
+	Attrs      map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph node
+}
+
+// DashboardLink represents an edge in the dashboard graph view
+type DashboardLink struct {
+	Source string         `json:"source"`
+	Target string         `json:"target"`
+	Attrs  map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph edge
+}
+
+// DashboardSubgraph is a row of the subgraph ranking table
+type DashboardSubgraph struct {
+	ID       int      `json:"id"`
+	Nodes    int      `json:"nodes"`
+	Edges    int      `json:"edges"`
+	Score    float64  `json:"score"`
+	Packages []string `json:"packages"` // Packages the subgraph spans
+}
+
+// DashboardData is the complete data embedded into the dashboard page
+type DashboardData struct {
+	Summary   DashboardSummary       `json:"summary"`
+	Nodes     []DashboardNode        `json:"nodes"`
+	Links     []DashboardLink        `json:"links"`
+	Packages  []graph.PackageMetrics `json:"packages"`
+	Cycles    [][]string             `json:"cycles"`
+	Subgraphs []DashboardSubgraph    `json:"subgraphs"`
+	Metadata  *graph.Metadata        `json:"metadata,omitempty"` // Provenance of the graph
+}
+
+// Write generates the dashboard HTML page, or its JSON data when htmlPage is disabled.
+// Like the 3d format, htmlPage defaults to true since the page is the point of this format.
+func (w *DashboardWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	dashboard := buildDashboardData(depGraph)
+
+	palette, err := paletteOf(config)
+	if err != nil {
+		return err
+	}
+	for i := range dashboard.Nodes {
+		dashboard.Nodes[i].Color = palette.nodeColor(depGraph.Nodes[dashboard.Nodes[i].ID])
+	}
+
+	if config.GetBool("htmlPage", true) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeDashboardHTML(writer, dashboard, theme)
+	}
+
+	// Otherwise output JSON
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(dashboard)
+}
+
+// buildDashboardData collects the graph topology and the computed analysis into a DashboardData
+func buildDashboardData(depGraph *graph.DependencyGraph) *DashboardData {
+	fanIn := depGraph.FanIn()
+	fanOut := depGraph.FanOut()
+
+	dashboard := &DashboardData{
+		Metadata:  depGraph.Metadata,
+		Nodes:     make([]DashboardNode, 0, len(depGraph.Nodes)),
+		Links:     make([]DashboardLink, 0),
+		Packages:  depGraph.ComputePackageMetrics(),
+		Cycles:    depGraph.FindCycles(),
+		Subgraphs: make([]DashboardSubgraph, 0, len(depGraph.Subgraphs)),
+	}
+
+	for _, node := range depGraph.Nodes {
+		dashboard.Nodes = append(dashboard.Nodes, DashboardNode{
+			ID:         node.ID,
+			Name:       node.Name,
+			Kind:       string(node.Kind),
+			Package:    node.Package,
+			File:       node.File,
+			Line:       node.Line,
+			Doc:        node.Summary(),
+			DocURL:     node.DocURL,
+			Lines:      node.Lines,
+			Complexity: node.Complexity,
+			Churn:      node.Churn,
+			Spans:      node.Spans,
+			Subgraph:   node.SubgraphID,
+			FanIn:      fanIn[node.ID],
+			FanOut:     fanOut[node.ID],
+			Attrs:      node.Attrs,
+		})
+	}
+
+	for sourceID, targets := range depGraph.Edges {
+		if _, exists := depGraph.Nodes[sourceID]; !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			dashboard.Links = append(dashboard.Links, DashboardLink{
+				Source: sourceID,
+				Target: targetID,
+				Attrs:  depGraph.EdgeAttrsOf(sourceID, targetID),
+			})
+		}
+	}
+
+	// Subgraphs are already sorted by score (descending) by ComputeSubgraphs
+	for _, subgraph := range depGraph.Subgraphs {
+		packageSet := make(map[string]bool)
+		for _, nodeID := range subgraph.NodeIDs {
+			if node, exists := depGraph.Nodes[nodeID]; exists {
+				packageSet[node.Package] = true
+			}
+		}
+		packageNames := make([]string, 0, len(packageSet))
+		for pkgName := range packageSet {
+			packageNames = append(packageNames, pkgName)
+		}
+		sort.Strings(packageNames)
+
+		dashboard.Subgraphs = append(dashboard.Subgraphs, DashboardSubgraph{
+			ID:       subgraph.ID,
+			Nodes:    len(subgraph.NodeIDs),
+			Edges:    subgraph.EdgeCount,
+			Score:    subgraph.Score,
+			Packages: packageNames,
+		})
+	}
+
+	dashboard.Summary = DashboardSummary{
+		Nodes:     len(dashboard.Nodes),
+		Edges:     len(dashboard.Links),
+		Packages:  len(dashboard.Packages),
+		Cycles:    len(dashboard.Cycles),
+		Subgraphs: len(dashboard.Subgraphs),
+	}
+
+	return dashboard
+}
+
+// writeDashboardHTML generates the self-contained dashboard HTML page
+func writeDashboardHTML(writer io.Writer, dashboard *DashboardData, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(dashboardTemplateFS, "templates/dashboard.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the dashboard data to JSON
+	jsonData, err := json.Marshal(dashboard)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
@@ -0,0 +1,79 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func newDashboardTestGraph() *graph.DependencyGraph {
+	g := graph.NewDependencyGraph()
+	g.Nodes["a::F"] = &graph.Node{ID: "a::F", Name: "F", Kind: graph.KindFunction, Package: "a"}
+	g.Nodes["a::G"] = &graph.Node{ID: "a::G", Name: "G", Kind: graph.KindFunction, Package: "a"}
+	g.Nodes["b::T"] = &graph.Node{ID: "b::T", Name: "T", Kind: graph.KindType, Package: "b"}
+	g.Nodes["c::Lonely"] = &graph.Node{ID: "c::Lonely", Name: "Lonely", Kind: graph.KindFunction, Package: "c"}
+	g.Edges["a::F"] = []string{"a::G", "b::T"}
+	g.Edges["a::G"] = []string{"a::F"}
+	g.ComputeSubgraphs()
+	return g
+}
+
+func TestBuildDashboardData(t *testing.T) {
+	dashboard := buildDashboardData(newDashboardTestGraph())
+
+	expected := DashboardSummary{Nodes: 4, Edges: 3, Packages: 3, Cycles: 1, Subgraphs: 2}
+	if dashboard.Summary != expected {
+		t.Errorf("Summary = %+v, want %+v", dashboard.Summary, expected)
+	}
+
+	if len(dashboard.Cycles) != 1 || len(dashboard.Cycles[0]) != 2 {
+		t.Errorf("Expected one 2-node cycle, got %v", dashboard.Cycles)
+	}
+
+	// The highest-scoring subgraph spans packages a and b
+	top := dashboard.Subgraphs[0]
+	if top.Nodes != 3 || len(top.Packages) != 2 || top.Packages[0] != "a" || top.Packages[1] != "b" {
+		t.Errorf("Unexpected top subgraph: %+v", top)
+	}
+
+	for _, node := range dashboard.Nodes {
+		if node.ID == "b::T" && node.FanIn != 1 {
+			t.Errorf("Expected fan-in 1 for b::T, got %d", node.FanIn)
+		}
+		if node.ID == "a::F" && node.FanOut != 2 {
+			t.Errorf("Expected fan-out 2 for a::F, got %d", node.FanOut)
+		}
+	}
+}
+
+func TestDashboardWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&DashboardWriter{}).Write(&buf, newDashboardTestGraph(), Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") {
+		t.Error("Output should be an HTML page by default")
+	}
+	for _, tab := range []string{"graph-tab", "packages-tab", "cycles-tab", "subgraphs-tab"} {
+		if !strings.Contains(output, tab) {
+			t.Errorf("Output should contain tab %s", tab)
+		}
+	}
+
+	buf.Reset()
+	if err := (&DashboardWriter{}).Write(&buf, newDashboardTestGraph(), Config{"htmlPage": false}); err != nil {
+		t.Fatalf("Write JSON failed: %v", err)
+	}
+	var result DashboardData
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if result.Summary.Nodes != 4 {
+		t.Errorf("Expected 4 nodes in JSON output, got %d", result.Summary.Nodes)
+	}
+}
@@ -0,0 +1,226 @@
+package format
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"maps"
+	"math"
+	"slices"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/deckgl.html
+var deckglTemplateFS embed.FS
+
+// DeckGLWriter implements the Writer interface for an experimental deck.gl visualization of
+// very large graphs: the page uploads the nodes and edges to the GPU as binary attributes, and
+// picks the nodes there
+type DeckGLWriter struct{}
+
+// deckglSpacing is the distance between neighboring nodes of the grouped placement
+const deckglSpacing = 10.0
+
+// DeckGLGraph is the complete data structure for deck.gl, in columns rather than objects, which
+// keeps the payload of hundreds of thousands of edges small and maps onto the typed arrays of
+// the page
+type DeckGLGraph struct {
+	Nodes      DeckGLNodes     `json:"nodes"`
+	Edges      DeckGLEdges     `json:"edges"`
+	Groups     []DeckGLGroup   `json:"groups"`
+	Positioned bool            `json:"positioned"`         // Positions of -layout, rather than the grouped placement
+	Metadata   *graph.Metadata `json:"metadata,omitempty"` // Provenance of the graph
+}
+
+// DeckGLNodes holds a column per node field, the nodes sorted by ID
+type DeckGLNodes struct {
+	ID      []string  `json:"id"`
+	Label   []string  `json:"label"`
+	Kind    []string  `json:"kind"`
+	Package []string  `json:"package"`
+	Group   []int     `json:"group"` // Index into the groups
+	X       []float64 `json:"x"`
+	Y       []float64 `json:"y"`
+	Size    []float64 `json:"size"`            // Radius, growing with the fan-in
+	FanIn   []int     `json:"fanin"`           // Number of incoming dependencies
+	Color   []string  `json:"color,omitempty"` // Palette colors, "" for the group color; only with a palette
+}
+
+// DeckGLEdges holds the source and target node indexes of the edges
+type DeckGLEdges struct {
+	Source []int `json:"source"`
+	Target []int `json:"target"`
+}
+
+// DeckGLGroup is a group of nodes (see Config.GroupOf) and its color
+type DeckGLGroup struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// Write generates deck.gl-compatible JSON or HTML output
+func (w *DeckGLWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if _, err := paletteOf(config); err != nil {
+		return err
+	}
+	deckGraph := convertToDeckGLFormat(depGraph, config)
+
+	// Check if HTML page should be generated
+	if config.GetBool("htmlPage", false) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeDeckGLHTML(writer, deckGraph, theme)
+	}
+
+	// Otherwise output JSON
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(deckGraph)
+}
+
+// convertToDeckGLFormat converts a DependencyGraph to the columns of deck.gl. Nodes keep the
+// positions of a precomputed layout when they all have one; otherwise every group is a disc
+// of its nodes, the largest groups in the middle, which is no layout but costs nothing on
+// graphs far too large for one in the browser
+func convertToDeckGLFormat(depGraph *graph.DependencyGraph, config Config) *DeckGLGraph {
+	ids := slices.Sorted(maps.Keys(depGraph.Nodes))
+	n := len(ids)
+	deckGraph := &DeckGLGraph{
+		Nodes: DeckGLNodes{
+			ID: ids, Label: make([]string, n), Kind: make([]string, n), Package: make([]string, n),
+			Group: make([]int, n), X: make([]float64, n), Y: make([]float64, n), Size: make([]float64, n), FanIn: make([]int, n),
+		},
+		Edges:    DeckGLEdges{Source: make([]int, 0), Target: make([]int, 0)},
+		Groups:   make([]DeckGLGroup, 0),
+		Metadata: depGraph.Metadata,
+	}
+
+	// Groups sorted by name, a hue each; Write reports invalid palettes
+	palette, _ := paletteOf(config)
+	groupIndex := make(map[string]int)
+	for _, node := range depGraph.Nodes {
+		groupIndex[config.GroupOf(node)] = 0
+	}
+	for i, group := range slices.Sorted(maps.Keys(groupIndex)) {
+		groupIndex[group] = i
+		deckGraph.Groups = append(deckGraph.Groups, DeckGLGroup{Name: group, Color: hslToHex(i*137, 70, 55)})
+	}
+
+	fanIn := depGraph.FanIn()
+	nodeIndex := make(map[string]int, n)
+	for i, id := range ids {
+		node := depGraph.Nodes[id]
+		nodeIndex[id] = i
+		deckGraph.Nodes.Label[i] = node.Name
+		deckGraph.Nodes.Kind[i] = string(node.Kind)
+		deckGraph.Nodes.Package[i] = node.Package
+		deckGraph.Nodes.Group[i] = groupIndex[config.GroupOf(node)]
+		deckGraph.Nodes.Size[i] = deckglNodeSize(fanIn[id])
+		deckGraph.Nodes.FanIn[i] = fanIn[id]
+		if palette != nil {
+			deckGraph.Nodes.Color = append(deckGraph.Nodes.Color, palette.nodeColor(node))
+		}
+	}
+
+	for i, id := range ids {
+		targets := slices.Clone(depGraph.Edges[id])
+		slices.Sort(targets)
+		for _, targetID := range slices.Compact(targets) {
+			if target, exists := nodeIndex[targetID]; exists {
+				deckGraph.Edges.Source = append(deckGraph.Edges.Source, i)
+				deckGraph.Edges.Target = append(deckGraph.Edges.Target, target)
+			}
+		}
+	}
+
+	deckGraph.Positioned = n > 0
+	for _, node := range depGraph.Nodes {
+		deckGraph.Positioned = deckGraph.Positioned && node.Position != nil
+	}
+	if deckGraph.Positioned {
+		for i, id := range ids {
+			deckGraph.Nodes.X[i] = depGraph.Nodes[id].Position.X
+			deckGraph.Nodes.Y[i] = depGraph.Nodes[id].Position.Y
+		}
+	} else {
+		deckglPlace(deckGraph)
+	}
+	return deckGraph
+}
+
+// goldenAngle spreads the points of a Vogel spiral evenly
+var goldenAngle = math.Pi * (3 - math.Sqrt(5))
+
+// deckglPlace places the nodes of each group on a Vogel spiral around the center of the group,
+// and the centers on a spiral too, by decreasing group size, far enough from the middle for
+// the groups before them to fit
+func deckglPlace(deckGraph *DeckGLGraph) {
+	members := make([][]int, len(deckGraph.Groups))
+	for i, group := range deckGraph.Nodes.Group {
+		members[group] = append(members[group], i)
+	}
+	order := make([]int, len(members))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(len(members[b]), len(members[a])) })
+
+	placed := 0 // Nodes in the groups placed so far, whose area the next center stays out of
+	for k, group := range order {
+		radius := 0.0
+		if k > 0 {
+			radius = deckglSpacing * math.Sqrt(float64(placed)+float64(len(members[group]))/2) * 1.5
+		}
+		centerX := radius * math.Cos(float64(k)*goldenAngle)
+		centerY := radius * math.Sin(float64(k)*goldenAngle)
+		for j, i := range members[group] {
+			r := deckglSpacing * math.Sqrt(float64(j))
+			deckGraph.Nodes.X[i] = math.Round((centerX+r*math.Cos(float64(j)*goldenAngle))*100) / 100
+			deckGraph.Nodes.Y[i] = math.Round((centerY+r*math.Sin(float64(j)*goldenAngle))*100) / 100
+		}
+		placed += len(members[group])
+	}
+}
+
+// deckglNodeSize scales the node radius with the square root of its fan-in, capped to half the
+// spacing of the grouped placement so that hubs do not cover their neighbors
+func deckglNodeSize(fanIn int) float64 {
+	return math.Min(1.5+math.Sqrt(float64(fanIn))/2, deckglSpacing/2)
+}
+
+// writeDeckGLHTML generates a self-contained HTML page with embedded deck.gl
+func writeDeckGLHTML(writer io.Writer, deckGraph *DeckGLGraph, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(deckglTemplateFS, "templates/deckgl.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the graph data to JSON
+	jsonData, err := json.Marshal(deckGraph)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
@@ -0,0 +1,136 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestDeckGLWriter_Write_JSON(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["b::Func"] = &graph.Node{ID: "b::Func", Name: "Func", Kind: graph.KindFunction, Package: "b"}
+	g.Nodes["a::Type"] = &graph.Node{ID: "a::Type", Name: "Type", Kind: graph.KindStruct, Package: "a"}
+	g.AddEdge("b::Func", "a::Type")
+	g.Edges["b::Func"] = append(g.Edges["b::Func"], "a::missing")
+
+	var buf bytes.Buffer
+	if err := (&DeckGLWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result DeckGLGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	if len(result.Nodes.ID) != 2 || result.Nodes.ID[0] != "a::Type" || result.Nodes.Label[1] != "Func" {
+		t.Fatalf("Expected the node columns sorted by ID, got %+v", result.Nodes)
+	}
+	if len(result.Groups) != 2 || result.Groups[0].Name != "a" || result.Nodes.Group[1] != 1 {
+		t.Errorf("Expected a group per package, got %+v and %v", result.Groups, result.Nodes.Group)
+	}
+	if len(result.Edges.Source) != 1 || result.Edges.Source[0] != 1 || result.Edges.Target[0] != 0 {
+		t.Errorf("Expected the one edge by node index, got %+v", result.Edges)
+	}
+	if result.Nodes.FanIn[0] != 1 || result.Nodes.Color != nil || result.Positioned {
+		t.Errorf("Expected a fan-in of 1, no palette colors and the grouped placement, got %+v", result)
+	}
+}
+
+func TestDeckGLWriter_Positions(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["a::A"] = &graph.Node{ID: "a::A", Name: "A", Kind: graph.KindFunction, Package: "a", Position: &graph.Position{X: 1, Y: 2}}
+	g.Nodes["a::B"] = &graph.Node{ID: "a::B", Name: "B", Kind: graph.KindFunction, Package: "a", Position: &graph.Position{X: 3, Y: 4}}
+
+	deckGraph := convertToDeckGLFormat(g, Config{})
+	if !deckGraph.Positioned || deckGraph.Nodes.X[1] != 3 || deckGraph.Nodes.Y[1] != 4 {
+		t.Errorf("Expected the precomputed positions, got %+v", deckGraph.Nodes)
+	}
+
+	// A node without a position falls back to the grouped placement
+	g.Nodes["a::B"].Position = nil
+	deckGraph = convertToDeckGLFormat(g, Config{})
+	if deckGraph.Positioned {
+		t.Error("Expected the grouped placement when a node has no position")
+	}
+}
+
+func TestDeckGLPlace(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	for _, pkg := range []string{"a", "b", "c"} {
+		for _, name := range []string{"A", "B", "C", "D", "E", "F", "G", "H"} {
+			id := pkg + "::" + name
+			g.Nodes[id] = &graph.Node{ID: id, Name: name, Kind: graph.KindFunction, Package: pkg}
+		}
+	}
+
+	deckGraph := convertToDeckGLFormat(g, Config{})
+	seen := make(map[[2]float64]bool)
+	for i := range deckGraph.Nodes.ID {
+		point := [2]float64{deckGraph.Nodes.X[i], deckGraph.Nodes.Y[i]}
+		if seen[point] {
+			t.Fatalf("Expected distinct positions, %v is taken twice", point)
+		}
+		seen[point] = true
+	}
+
+	// The nodes of a group stay closer to each other than to the other groups
+	centers := make([][2]float64, len(deckGraph.Groups))
+	for i, group := range deckGraph.Nodes.Group {
+		centers[group][0] += deckGraph.Nodes.X[i] / 8
+		centers[group][1] += deckGraph.Nodes.Y[i] / 8
+	}
+	for i, group := range deckGraph.Nodes.Group {
+		own := math.Hypot(deckGraph.Nodes.X[i]-centers[group][0], deckGraph.Nodes.Y[i]-centers[group][1])
+		for other, center := range centers {
+			if other != group && math.Hypot(deckGraph.Nodes.X[i]-center[0], deckGraph.Nodes.Y[i]-center[1]) <= own {
+				t.Errorf("Expected %s nearest to the center of its group", deckGraph.Nodes.ID[i])
+			}
+		}
+	}
+}
+
+func TestDeckGLWriter_Palette(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["a::Type"] = &graph.Node{ID: "a::Type", Name: "Type", Kind: graph.KindStruct, Package: "a"}
+	g.Nodes["a::Func"] = &graph.Node{ID: "a::Func", Name: "Func", Kind: graph.KindFunction, Package: "a"}
+
+	deckGraph := convertToDeckGLFormat(g, Config{"kindColors": map[string]any{"struct": "#123456"}})
+	if len(deckGraph.Nodes.Color) != 2 || deckGraph.Nodes.Color[0] != "" || deckGraph.Nodes.Color[1] != "#123456" {
+		t.Errorf("Expected the palette color of the struct only, got %v", deckGraph.Nodes.Color)
+	}
+}
+
+func TestDeckGLWriter_Write_HTML(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg::Func"] = &graph.Node{ID: "pkg::Func", Name: "Func", Kind: graph.KindFunction, Package: "pkg"}
+
+	var buf bytes.Buffer
+	if err := (&DeckGLWriter{}).Write(&buf, g, Config{"htmlPage": true}); err != nil {
+		t.Fatalf("Write HTML failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") || !strings.Contains(output, "deck.gl") {
+		t.Error("Expected a deck.gl HTML page")
+	}
+	if !strings.Contains(output, `"id":["pkg::Func"]`) {
+		t.Error("Expected the node embedded in the page")
+	}
+}
+
+func TestDeckGLNodeSize(t *testing.T) {
+	if got := deckglNodeSize(0); got != 1.5 {
+		t.Errorf("deckglNodeSize(0) = %v, want 1.5", got)
+	}
+	if got := deckglNodeSize(4); got != 2.5 {
+		t.Errorf("deckglNodeSize(4) = %v, want 2.5", got)
+	}
+	if got := deckglNodeSize(10000); got != deckglSpacing/2 {
+		t.Errorf("deckglNodeSize(10000) = %v, want %v", got, deckglSpacing/2)
+	}
+}
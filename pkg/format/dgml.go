@@ -0,0 +1,219 @@
+package format
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// dgmlNamespace is the XML namespace of Microsoft's Directed Graph Markup Language
+const dgmlNamespace = "http://schemas.microsoft.com/vs/2009/dgml"
+
+// DGMLWriter writes the graph as Microsoft DGML, viewable in Visual Studio
+type DGMLWriter struct{}
+
+// DGMLNode represents a <Node> element; packages are emitted as group (container) nodes
+type DGMLNode struct {
+	ID        string     `xml:"Id,attr"`
+	Label     string     `xml:"Label,attr"`
+	Category  string     `xml:"Category,attr"`
+	Group     string     `xml:"Group,attr,omitempty"` // "Expanded" or "Collapsed" for containers
+	Package   string     `xml:"Package,attr,omitempty"`
+	File      string     `xml:"File,attr,omitempty"`
+	Line      int        `xml:"Line,attr,omitempty"`
+	Signature string     `xml:"Signature,attr,omitempty"`
+	Attrs     []xml.Attr `xml:",any,attr"` // Custom attributes of the graph node
+}
+
+// DGMLLink represents a <Link> element
+type DGMLLink struct {
+	Source   string     `xml:"Source,attr"`
+	Target   string     `xml:"Target,attr"`
+	Category string     `xml:"Category,attr,omitempty"` // "Contains" for container membership
+	Attrs    []xml.Attr `xml:",any,attr"`               // Custom attributes of the graph edge
+}
+
+// DGMLCategory represents a <Category> element used for per-kind styling
+type DGMLCategory struct {
+	ID         string `xml:"Id,attr"`
+	Label      string `xml:"Label,attr,omitempty"`
+	Background string `xml:"Background,attr,omitempty"`
+}
+
+// DGMLProperty declares a custom node attribute so Visual Studio shows it in the properties window
+type DGMLProperty struct {
+	ID       string `xml:"Id,attr"`
+	DataType string `xml:"DataType,attr"`
+}
+
+// DGMLGraph is the root <DirectedGraph> element
+type DGMLGraph struct {
+	XMLName        xml.Name       `xml:"DirectedGraph"`
+	Comment        string         `xml:",comment"` // Provenance of the graph, see graph.Metadata
+	Namespace      string         `xml:"xmlns,attr"`
+	GraphDirection string         `xml:"GraphDirection,attr"`
+	Nodes          []DGMLNode     `xml:"Nodes>Node"`
+	Links          []DGMLLink     `xml:"Links>Link"`
+	Categories     []DGMLCategory `xml:"Categories>Category"`
+	Properties     []DGMLProperty `xml:"Properties>Property"`
+}
+
+// dgmlCategories maps node kinds to DGML categories, using the same colors as the D3.js template
+var dgmlCategories = []DGMLCategory{
+	{ID: "Package", Label: "Package", Background: "#0078D4"},
+	{ID: string(graph.KindFunction), Label: "Function", Background: "#FF9800"},
+	{ID: string(graph.KindMethod), Label: "Method", Background: "#2196F3"},
+	{ID: string(graph.KindType), Label: "Type", Background: "#4CAF50"},
+	{ID: string(graph.KindInterface), Label: "Interface", Background: "#009688"},
+	{ID: string(graph.KindStruct), Label: "Struct", Background: "#4CAF50"},
+	{ID: string(graph.KindNamed), Label: "Named type", Background: "#8BC34A"},
+	{ID: string(graph.KindAlias), Label: "Alias", Background: "#CDDC39"},
+	{ID: string(graph.KindTest), Label: "Test", Background: "#9C27B0"},
+	{ID: string(graph.KindBenchmark), Label: "Benchmark", Background: "#E91E63"},
+	{ID: string(graph.KindFuzz), Label: "Fuzz", Background: "#795548"},
+	{ID: string(graph.KindExample), Label: "Example", Background: "#00BCD4"},
+}
+
+// defaultKindColor colors the node kinds without a DGML category color
+const defaultKindColor = "#9E9E9E"
+
+// kindColor returns the color of a node kind, the background of its DGML category, for the
+// other formats coloring nodes by kind
+func kindColor(kind graph.NodeKind) string {
+	for _, category := range dgmlCategories {
+		if strings.EqualFold(category.ID, string(kind)) {
+			return category.Background
+		}
+	}
+	return defaultKindColor
+}
+
+// Write formats the graph as DGML
+func (w *DGMLWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	dgmlGraph := convertToDGMLFormat(depGraph, config)
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.Indent("", "  ")
+	}
+
+	if err := enc.Encode(dgmlGraph); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// convertToDGMLFormat converts a DependencyGraph to DGML with one container per package
+func convertToDGMLFormat(depGraph *graph.DependencyGraph, config Config) *DGMLGraph {
+	dgmlGraph := &DGMLGraph{
+		Namespace:      dgmlNamespace,
+		GraphDirection: config.GetString("graphDirection", "LeftToRight"),
+		Nodes:          make([]DGMLNode, 0, len(depGraph.Nodes)),
+		Links:          make([]DGMLLink, 0),
+		Categories:     dgmlCategories,
+		Properties: []DGMLProperty{
+			{ID: "Package", DataType: "System.String"},
+			{ID: "File", DataType: "System.String"},
+			{ID: "Line", DataType: "System.Int32"},
+			{ID: "Signature", DataType: "System.String"},
+		},
+	}
+
+	if depGraph.Metadata != nil {
+		// "--" may not appear in XML comments
+		dgmlGraph.Comment = " " + strings.ReplaceAll(depGraph.Metadata.String(), "--", "- -") + " "
+	}
+
+	// Sort node IDs so that the output is stable across runs (diff-friendly)
+	nodeIDs := make([]string, 0, len(depGraph.Nodes))
+	for nodeID := range depGraph.Nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+
+	// Phase 1: Package containers
+	packageGroups := make(map[string]bool)
+	for _, nodeID := range nodeIDs {
+		node := depGraph.Nodes[nodeID]
+		if !packageGroups[node.Package] {
+			packageGroups[node.Package] = true
+			dgmlGraph.Nodes = append(dgmlGraph.Nodes, DGMLNode{
+				ID:       "pkg:" + node.Package,
+				Label:    node.Package,
+				Category: "Package",
+				Group:    "Expanded",
+			})
+		}
+	}
+
+	// Phase 2: Symbol nodes and their containment links
+	for _, nodeID := range nodeIDs {
+		node := depGraph.Nodes[nodeID]
+		dgmlGraph.Nodes = append(dgmlGraph.Nodes, DGMLNode{
+			ID:        node.ID,
+			Label:     node.Name,
+			Category:  string(node.Kind),
+			Package:   node.Package,
+			File:      node.File,
+			Line:      node.Line,
+			Signature: node.Signature,
+			Attrs:     dgmlAttrs(node.Attrs),
+		})
+		declareDGMLProperties(dgmlGraph, node.Attrs)
+		dgmlGraph.Links = append(dgmlGraph.Links, DGMLLink{
+			Source:   "pkg:" + node.Package,
+			Target:   node.ID,
+			Category: "Contains",
+		})
+	}
+
+	// Phase 3: Dependency links
+	for _, sourceID := range nodeIDs {
+		for _, targetID := range depGraph.Edges[sourceID] {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			attrs := depGraph.EdgeAttrsOf(sourceID, targetID)
+			dgmlGraph.Links = append(dgmlGraph.Links, DGMLLink{
+				Source: sourceID,
+				Target: targetID,
+				Attrs:  dgmlAttrs(attrs),
+			})
+			declareDGMLProperties(dgmlGraph, attrs)
+		}
+	}
+
+	return dgmlGraph
+}
+
+// dgmlAttrs converts custom attributes to XML attributes, sorted by name for stable output
+func dgmlAttrs(attrs map[string]any) []xml.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	xmlAttrs := make([]xml.Attr, 0, len(attrs))
+	for _, key := range graph.AttrKeys(attrs) {
+		xmlAttrs = append(xmlAttrs, xml.Attr{Name: xml.Name{Local: key}, Value: fmt.Sprint(attrs[key])})
+	}
+	return xmlAttrs
+}
+
+// declareDGMLProperties declares the custom attributes not declared yet, as strings
+func declareDGMLProperties(dgmlGraph *DGMLGraph, attrs map[string]any) {
+	for _, key := range graph.AttrKeys(attrs) {
+		if !slices.ContainsFunc(dgmlGraph.Properties, func(p DGMLProperty) bool { return p.ID == key }) {
+			dgmlGraph.Properties = append(dgmlGraph.Properties, DGMLProperty{ID: key, DataType: "System.String"})
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestDGMLWriter_Write(t *testing.T) {
+	g := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"pkg1::func1": {ID: "pkg1::func1", Name: "func1", Kind: graph.KindFunction, Package: "pkg1", File: "a.go", Line: 3},
+			"pkg1::Type1": {ID: "pkg1::Type1", Name: "Type1", Kind: graph.KindType, Package: "pkg1"},
+			"pkg2::func2": {ID: "pkg2::func2", Name: "func2", Kind: graph.KindFunction, Package: "pkg2"},
+		},
+		Edges: map[string][]string{
+			"pkg1::func1": {"pkg1::Type1", "pkg2::func2", "pkg2::missing"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&DGMLWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "<?xml") {
+		t.Error("Output should start with an XML header")
+	}
+	if !strings.Contains(output, dgmlNamespace) {
+		t.Error("Output should declare the DGML namespace")
+	}
+
+	var result DGMLGraph
+	if err := xml.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse DGML output: %v", err)
+	}
+
+	// 3 symbols + 2 package containers
+	if len(result.Nodes) != 5 {
+		t.Errorf("Expected 5 nodes, got %d", len(result.Nodes))
+	}
+
+	containers := 0
+	for _, node := range result.Nodes {
+		if node.Group == "Expanded" {
+			containers++
+			if node.Category != "Package" {
+				t.Errorf("Container %s should have Package category, got %s", node.ID, node.Category)
+			}
+		}
+	}
+	if containers != 2 {
+		t.Errorf("Expected 2 package containers, got %d", containers)
+	}
+
+	contains, deps := 0, 0
+	for _, link := range result.Links {
+		if link.Category == "Contains" {
+			contains++
+		} else {
+			deps++
+		}
+	}
+	if contains != 3 {
+		t.Errorf("Expected 3 containment links, got %d", contains)
+	}
+	if deps != 2 {
+		t.Errorf("Expected 2 dependency links (dangling dropped), got %d", deps)
+	}
+
+	if len(result.Categories) != len(dgmlCategories) {
+		t.Errorf("Expected %d categories, got %d", len(dgmlCategories), len(result.Categories))
+	}
+}
+
+func TestDGMLWriter_Attrs(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg1::func1"] = &graph.Node{ID: "pkg1::func1", Name: "func1", Kind: graph.KindFunction, Package: "pkg1"}
+	g.Nodes["pkg1::func2"] = &graph.Node{ID: "pkg1::func2", Name: "func2", Kind: graph.KindFunction, Package: "pkg1"}
+	g.Nodes["pkg1::func1"].SetAttr("Coverage", 0.5)
+	g.SetEdgeAttr("pkg1::func1", "pkg1::func2", "Calls", 2)
+
+	var buf bytes.Buffer
+	if err := (&DGMLWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{`Coverage="0.5"`, `Calls="2"`, `<Property Id="Coverage" DataType="System.String">`, `<Property Id="Calls" DataType="System.String">`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %s", want)
+		}
+	}
+}
+
+func TestDGMLWriter_Metadata(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Metadata = &graph.Metadata{Tool: "go-depmap", Version: "v1.2.3", Flags: map[string]string{"config": `{"x":"--"}`}}
+
+	var buf bytes.Buffer
+	if err := (&DGMLWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<!-- go-depmap v1.2.3") {
+		t.Errorf("Expected the metadata as a comment, got %s", buf.String())
+	}
+}
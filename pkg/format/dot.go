@@ -0,0 +1,108 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// dotThemes holds the background, label and edge colors of the DOT graph for each theme,
+// matching the HTML pages
+var dotThemes = map[string]struct{ background, text, edge string }{
+	ThemeDark:  {background: "#1a1a1a", text: "#e0e0e0", edge: "#999999"},
+	ThemeLight: {background: "#f5f5f5", text: "#222222", edge: "#999999"},
+}
+
+// DOTWriter writes the graph in the DOT language of Graphviz: a cluster per group (see
+// Config.GroupOf) holding its nodes, labeled with their name, and the edges with their kinds
+// as the tooltip. The theme colors the background, labels and edges; nodes are filled with
+// their colorBy color, else their palette color, else the DGML color of their kind. The
+// provenance is a leading comment.
+type DOTWriter struct{}
+
+// Write renders the DOT graph
+func (w *DOTWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	theme, err := pageTheme(config)
+	if err != nil {
+		return err
+	}
+	palette, err := paletteOf(config)
+	if err != nil {
+		return err
+	}
+	heat, _, err := nodeHeatColors(depGraph, config)
+	if err != nil {
+		return err
+	}
+	colors := dotThemes[theme]
+
+	var sb strings.Builder
+	if depGraph.Metadata != nil {
+		fmt.Fprintf(&sb, "// Generated by %s\n", singleLine(depGraph.Metadata.String()))
+	}
+	sb.WriteString("digraph depmap {\n")
+	fmt.Fprintf(&sb, "  graph [rankdir=LR, bgcolor=%s, fontcolor=%s, color=%s];\n",
+		dotString(colors.background), dotString(colors.text), dotString(colors.edge))
+	sb.WriteString("  node [shape=box, style=\"rounded,filled\", fontcolor=\"#000000\"];\n")
+	fmt.Fprintf(&sb, "  edge [color=%s];\n", dotString(colors.edge))
+
+	// Sort node IDs so that the output is stable across runs (diff-friendly)
+	nodeIDs := slices.Sorted(maps.Keys(depGraph.Nodes))
+	groups := make(map[string][]string)
+	for _, nodeID := range nodeIDs {
+		name := config.GroupOf(depGraph.Nodes[nodeID])
+		groups[name] = append(groups[name], nodeID)
+	}
+	for i, name := range slices.Sorted(maps.Keys(groups)) {
+		fmt.Fprintf(&sb, "  subgraph %s {\n", dotString("cluster_"+strconv.Itoa(i)))
+		fmt.Fprintf(&sb, "    label=%s;\n", dotString(name))
+		for _, nodeID := range groups[name] {
+			node := depGraph.Nodes[nodeID]
+			color := heat[nodeID]
+			if color == "" {
+				color = palette.nodeColor(node)
+			}
+			if color == "" {
+				color = kindColor(node.Kind)
+			}
+			fmt.Fprintf(&sb, "    %s [label=%s, tooltip=%s, fillcolor=%s];\n",
+				dotString(nodeID), dotString(node.Name), dotString(string(node.Kind)), dotString(color))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	for _, sourceID := range nodeIDs {
+		for _, targetID := range depGraph.Edges[sourceID] {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			fmt.Fprintf(&sb, "  %s -> %s", dotString(sourceID), dotString(targetID))
+			if kinds := depGraph.EdgeKindsOf(sourceID, targetID); len(kinds) > 0 {
+				names := make([]string, len(kinds))
+				for i, kind := range kinds {
+					names[i] = string(kind)
+				}
+				fmt.Fprintf(&sb, " [tooltip=%s]", dotString(strings.Join(names, ",")))
+			}
+			sb.WriteString(";\n")
+		}
+	}
+	sb.WriteString("}\n")
+
+	_, err = io.WriteString(writer, sb.String())
+	return err
+}
+
+// dotEscaper escapes the quotes of DOT strings, and the backslashes that would escape them
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// dotString quotes an ID or attribute value for DOT; line breaks are replaced, as in the other
+// line-based formats
+func dotString(value string) string {
+	return `"` + dotEscaper.Replace(singleLine(value)) + `"`
+}
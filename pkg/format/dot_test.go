@@ -0,0 +1,76 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestDOTWriter_Write(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Metadata = &graph.Metadata{Tool: "go-depmap", Module: "example.com/app"}
+	g.Nodes["pkg::Serve"] = &graph.Node{ID: "pkg::Serve", Name: "Serve", Kind: graph.KindFunction, Package: "pkg"}
+	g.Nodes["pkg::Conn"] = &graph.Node{ID: "pkg::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "pkg"}
+	g.Nodes[`db::"quoted"`] = &graph.Node{ID: `db::"quoted"`, Name: `"quoted"`, Kind: graph.KindFunction, Package: "db"}
+	g.AddEdge("pkg::Serve", "pkg::Conn", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("pkg::Serve", `db::"quoted"`)
+	g.AddEdge("pkg::Serve", "pkg::missing")
+
+	var buf bytes.Buffer
+	if err := (&DOTWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := `// Generated by ` + g.Metadata.String() + `
+digraph depmap {
+  graph [rankdir=LR, bgcolor="#1a1a1a", fontcolor="#e0e0e0", color="#999999"];
+  node [shape=box, style="rounded,filled", fontcolor="#000000"];
+  edge [color="#999999"];
+  subgraph "cluster_0" {
+    label="db";
+    "db::\"quoted\"" [label="\"quoted\"", tooltip="function", fillcolor="` + kindColor(graph.KindFunction) + `"];
+  }
+  subgraph "cluster_1" {
+    label="pkg";
+    "pkg::Conn" [label="Conn", tooltip="struct", fillcolor="` + kindColor(graph.KindStruct) + `"];
+    "pkg::Serve" [label="Serve", tooltip="function", fillcolor="` + kindColor(graph.KindFunction) + `"];
+  }
+  "pkg::Serve" -> "pkg::Conn" [tooltip="signature,param"];
+  "pkg::Serve" -> "db::\"quoted\"";
+}
+`
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestDOTWriter_ThemeAndPalette(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg::Serve"] = &graph.Node{ID: "pkg::Serve", Name: "Serve", Kind: graph.KindFunction, Package: "example.com/shop/pkg"}
+	g.Nodes["lib::Conn"] = &graph.Node{ID: "lib::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "lib"}
+
+	var buf bytes.Buffer
+	config := Config{
+		"theme":         ThemeLight,
+		"kindColors":    map[string]any{"struct": "#e4002b"},
+		"packageColors": map[string]any{"example.com/shop/...": "#00205b"},
+	}
+	if err := (&DOTWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		`bgcolor="#f5f5f5"`,
+		`"pkg::Serve" [label="Serve", tooltip="function", fillcolor="#00205b"]`,
+		`"lib::Conn" [label="Conn", tooltip="struct", fillcolor="#e4002b"]`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected the output to contain %s, got:\n%s", want, output)
+		}
+	}
+
+	if err := (&DOTWriter{}).Write(&buf, g, Config{"theme": "sepia"}); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
@@ -0,0 +1,204 @@
+package format
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"sort"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/echarts.html
+var echartsTemplateFS embed.FS
+
+// EChartsWriter implements the Writer interface for Apache ECharts graph series visualization
+type EChartsWriter struct{}
+
+// EChartsNode represents a node in the ECharts graph series
+type EChartsNode struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Category   int            `json:"category"` // Index into the categories array (one per package or owner)
+	Kind       string         `json:"kind"`
+	Package    string         `json:"package"`
+	Owner      string         `json:"owner,omitempty"`
+	File       string         `json:"file,omitempty"`
+	Line       int            `json:"line,omitempty"`
+	Doc        string         `json:"doc,omitempty"` // First sentence of the doc comment
+	SymbolSize float64        `json:"symbolSize"`
+	ItemStyle  *EChartsStyle  `json:"itemStyle,omitempty"` // Palette color of the package or kind, replacing the category color
+	Value      int            `json:"value"`               // Number of incoming dependencies
+	Attrs      map[string]any `json:"attrs,omitempty"`     // Custom attributes of the graph node
+}
+
+// EChartsLink represents an edge in the ECharts graph series
+type EChartsLink struct {
+	Source string         `json:"source"`
+	Target string         `json:"target"`
+	Attrs  map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph edge
+}
+
+// EChartsCategory represents a legend category (one per package)
+type EChartsCategory struct {
+	Name      string        `json:"name"`
+	ItemStyle *EChartsStyle `json:"itemStyle,omitempty"` // Palette color of the package
+}
+
+// EChartsStyle is the item style of a node or category
+type EChartsStyle struct {
+	Color string `json:"color"`
+}
+
+// EChartsGraph is the complete data structure for the ECharts graph series
+type EChartsGraph struct {
+	Nodes      []EChartsNode     `json:"nodes"`
+	Links      []EChartsLink     `json:"links"`
+	Categories []EChartsCategory `json:"categories"`
+	Metadata   *graph.Metadata   `json:"metadata,omitempty"` // Provenance of the graph
+}
+
+// Write generates ECharts-compatible JSON or HTML output
+func (w *EChartsWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if _, err := paletteOf(config); err != nil {
+		return err
+	}
+	echartsGraph := convertToEChartsFormat(depGraph, config)
+
+	// Check if HTML page should be generated
+	if config.GetBool("htmlPage", false) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeEChartsHTML(writer, echartsGraph, theme)
+	}
+
+	// Otherwise output JSON
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(echartsGraph)
+}
+
+// convertToEChartsFormat converts a DependencyGraph to the ECharts graph series format,
+// with one category per group (package or owner, see Config.GroupOf)
+func convertToEChartsFormat(depGraph *graph.DependencyGraph, config Config) *EChartsGraph {
+	echartsGraph := &EChartsGraph{
+		Metadata:   depGraph.Metadata,
+		Nodes:      make([]EChartsNode, 0, len(depGraph.Nodes)),
+		Links:      make([]EChartsLink, 0),
+		Categories: make([]EChartsCategory, 0),
+	}
+
+	// Assign one category per group, sorted for stable legend ordering
+	groupSet := make(map[string]bool)
+	for _, node := range depGraph.Nodes {
+		groupSet[config.GroupOf(node)] = true
+	}
+	groupNames := make([]string, 0, len(groupSet))
+	for groupName := range groupSet {
+		groupNames = append(groupNames, groupName)
+	}
+	sort.Strings(groupNames)
+
+	// Package categories take the palette color of their package; Write reports invalid palettes
+	palette, _ := paletteOf(config)
+	styleOf := func(color string) *EChartsStyle {
+		if color == "" {
+			return nil
+		}
+		return &EChartsStyle{Color: color}
+	}
+	byPackage := config.GetString("groupBy", "package") == "package"
+
+	groupCategory := make(map[string]int, len(groupNames))
+	for i, groupName := range groupNames {
+		groupCategory[groupName] = i
+		category := EChartsCategory{Name: groupName}
+		if byPackage {
+			category.ItemStyle = styleOf(palette.packageColor(groupName))
+		}
+		echartsGraph.Categories = append(echartsGraph.Categories, category)
+	}
+
+	// Count incoming edges so that heavily used symbols render larger
+	fanIn := make(map[string]int)
+	for sourceID, targets := range depGraph.Edges {
+		if _, exists := depGraph.Nodes[sourceID]; !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			fanIn[targetID]++
+			echartsGraph.Links = append(echartsGraph.Links, EChartsLink{
+				Source: sourceID,
+				Target: targetID,
+				Attrs:  depGraph.EdgeAttrsOf(sourceID, targetID),
+			})
+		}
+	}
+
+	for _, node := range depGraph.Nodes {
+		echartsGraph.Nodes = append(echartsGraph.Nodes, EChartsNode{
+			ID:         node.ID,
+			Name:       node.Name,
+			Category:   groupCategory[config.GroupOf(node)],
+			Kind:       string(node.Kind),
+			Package:    node.Package,
+			Owner:      node.Owner,
+			File:       node.File,
+			Line:       node.Line,
+			Doc:        node.Summary(),
+			SymbolSize: echartsSymbolSize(fanIn[node.ID]),
+			ItemStyle:  styleOf(palette.nodeColor(node)),
+			Value:      fanIn[node.ID],
+			Attrs:      node.Attrs,
+		})
+	}
+
+	return echartsGraph
+}
+
+// echartsSymbolSize scales the node size with its fan-in, capped to keep hubs readable
+func echartsSymbolSize(fanIn int) float64 {
+	size := 4.0 + float64(fanIn)
+	if size > 30.0 {
+		return 30.0
+	}
+	return size
+}
+
+// writeEChartsHTML generates a self-contained HTML page with embedded Apache ECharts
+func writeEChartsHTML(writer io.Writer, echartsGraph *EChartsGraph, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(echartsTemplateFS, "templates/echarts.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the graph data to JSON
+	jsonData, err := json.Marshal(echartsGraph)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
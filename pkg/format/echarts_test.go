@@ -0,0 +1,90 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestEChartsWriter_Write_JSON(t *testing.T) {
+	g := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"b::func1": {ID: "b::func1", Name: "func1", Kind: graph.KindFunction, Package: "b"},
+			"a::Type1": {ID: "a::Type1", Name: "Type1", Kind: graph.KindType, Package: "a"},
+			"a::func2": {ID: "a::func2", Name: "func2", Kind: graph.KindFunction, Package: "a"},
+		},
+		Edges: map[string][]string{
+			"b::func1": {"a::Type1", "a::missing"},
+			"a::func2": {"a::Type1"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&EChartsWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result EChartsGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	if len(result.Categories) != 2 || result.Categories[0].Name != "a" || result.Categories[1].Name != "b" {
+		t.Errorf("Expected sorted categories [a b], got %v", result.Categories)
+	}
+	if len(result.Links) != 2 {
+		t.Errorf("Expected 2 links, got %d", len(result.Links))
+	}
+
+	for _, node := range result.Nodes {
+		switch node.ID {
+		case "a::Type1":
+			if node.Value != 2 {
+				t.Errorf("Expected Type1 fan-in 2, got %d", node.Value)
+			}
+			if node.Category != 0 {
+				t.Errorf("Expected Type1 category 0, got %d", node.Category)
+			}
+		case "b::func1":
+			if node.Category != 1 {
+				t.Errorf("Expected func1 category 1, got %d", node.Category)
+			}
+		}
+	}
+}
+
+func TestEChartsSymbolSize(t *testing.T) {
+	if got := echartsSymbolSize(0); got != 4.0 {
+		t.Errorf("echartsSymbolSize(0) = %v, want 4", got)
+	}
+	if got := echartsSymbolSize(1000); got != 30.0 {
+		t.Errorf("echartsSymbolSize(1000) = %v, want 30", got)
+	}
+}
+
+func TestEChartsWriter_Write_HTML(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg::Func"] = &graph.Node{ID: "pkg::Func", Name: "Func", Kind: graph.KindFunction, Package: "pkg"}
+
+	var buf bytes.Buffer
+	if err := (&EChartsWriter{}).Write(&buf, g, Config{"htmlPage": true}); err != nil {
+		t.Fatalf("Write HTML failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") {
+		t.Error("Output should contain HTML doctype")
+	}
+	if !strings.Contains(output, "echarts") {
+		t.Error("Output should load ECharts")
+	}
+	if !strings.Contains(output, "pkg::Func") {
+		t.Error("Output should embed the graph data")
+	}
+	if !strings.Contains(output, "escapeHTML(d.doc)") {
+		t.Error("Tooltips should escape doc comments")
+	}
+}
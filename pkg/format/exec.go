@@ -0,0 +1,67 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// ExecConfigEnv is the environment variable through which the formatter
+// config is passed to subprocess writers as a JSON object
+const ExecConfigEnv = "DEPMAP_CONFIG"
+
+// ExecWriter pipes the canonical JSON graph to an external command on STDIN
+// and copies the command's STDOUT to the output. This allows formats to be
+// implemented outside of this repository without forking it.
+type ExecWriter struct {
+	Command string   // Executable to run
+	Args    []string // Arguments passed to the executable
+}
+
+// NewExecWriter creates an ExecWriter from a command line such as "./my-writer --flag"
+func NewExecWriter(commandLine string) *ExecWriter {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return &ExecWriter{}
+	}
+	return &ExecWriter{
+		Command: fields[0],
+		Args:    fields[1:],
+	}
+}
+
+// Write runs the external command with the graph as input
+func (w *ExecWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if w.Command == "" {
+		return errors.New("exec writer: no command given")
+	}
+
+	// The canonical JSON is always minified; the plugin can reformat as needed
+	var input bytes.Buffer
+	if err := (&JSONWriter{}).Write(&input, depGraph, Config{"pretty": false}); err != nil {
+		return err
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(w.Command, w.Args...) // #nosec G204 - the command is explicitly chosen by the user
+	cmd.Stdin = &input
+	cmd.Stdout = writer
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), ExecConfigEnv+"="+string(configJSON))
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("exec writer %q: %w", w.Command, err)
+	}
+	return nil
+}
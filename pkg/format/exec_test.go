@@ -0,0 +1,58 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_ExecWriter_Write(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	g := graph.NewDependencyGraph()
+	g.Nodes["test::func1"] = &graph.Node{ID: "test::func1", Name: "func1", Kind: graph.KindFunction}
+
+	var buf bytes.Buffer
+	if err := NewExecWriter("cat").Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var result graph.DependencyGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("plugin did not receive valid JSON: %v", err)
+	}
+	if _, ok := result.Nodes["test::func1"]; !ok {
+		t.Error("expected node test::func1 to be piped to the plugin")
+	}
+}
+
+func Test_ExecWriter_ReceivesConfig(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	writer := &ExecWriter{Command: "sh", Args: []string{"-c", "cat > /dev/null; printf '%s' \"$" + ExecConfigEnv + "\""}}
+
+	var buf bytes.Buffer
+	if err := writer.Write(&buf, graph.NewDependencyGraph(), Config{"theme": "dark"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if buf.String() != `{"theme":"dark"}` {
+		t.Errorf("unexpected config passed to plugin: %s", buf.String())
+	}
+}
+
+func Test_ExecWriter_Errors(t *testing.T) {
+	if err := NewExecWriter("").Write(&bytes.Buffer{}, graph.NewDependencyGraph(), Config{}); err == nil {
+		t.Error("expected error for empty command")
+	}
+
+	if err := NewExecWriter("depmap-writer-that-does-not-exist").Write(&bytes.Buffer{}, graph.NewDependencyGraph(), Config{}); err == nil {
+		t.Error("expected error for missing command")
+	}
+}
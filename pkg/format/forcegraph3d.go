@@ -0,0 +1,144 @@
+package format
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/forcegraph3d.html
+var forceGraph3DTemplateFS embed.FS
+
+// ForceGraph3DWriter implements the Writer interface for three.js/3d-force-graph visualization
+type ForceGraph3DWriter struct{}
+
+// ForceGraph3DNode represents a node in 3d-force-graph format
+type ForceGraph3DNode struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Kind    string         `json:"kind"`
+	Package string         `json:"package"`
+	Group   string         `json:"group"`           // Package or owner, used for automatic coloring
+	Color   string         `json:"color,omitempty"` // Palette color of the package or kind, replacing the automatic one
+	Owner   string         `json:"owner,omitempty"`
+	File    string         `json:"file,omitempty"`
+	Line    int            `json:"line,omitempty"`
+	Doc     string         `json:"doc,omitempty"`   // First sentence of the doc comment
+	Attrs   map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph node
+}
+
+// ForceGraph3DLink represents a link in 3d-force-graph format
+type ForceGraph3DLink struct {
+	Source string         `json:"source"`
+	Target string         `json:"target"`
+	Attrs  map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph edge
+}
+
+// ForceGraph3DGraph is the complete data structure for 3d-force-graph
+type ForceGraph3DGraph struct {
+	Nodes    []ForceGraph3DNode `json:"nodes"`
+	Links    []ForceGraph3DLink `json:"links"`
+	Metadata *graph.Metadata    `json:"metadata,omitempty"` // Provenance of the graph
+}
+
+// Write generates a 3d-force-graph HTML page, or its JSON data when htmlPage is disabled.
+// Unlike the other formats, htmlPage defaults to true since the page is the point of this format.
+func (w *ForceGraph3DWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if _, err := paletteOf(config); err != nil {
+		return err
+	}
+	fgGraph := convertToForceGraph3DFormat(depGraph, config)
+
+	if config.GetBool("htmlPage", true) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeForceGraph3DHTML(writer, fgGraph, theme)
+	}
+
+	// Otherwise output JSON
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(fgGraph)
+}
+
+// convertToForceGraph3DFormat converts a DependencyGraph to 3d-force-graph format
+func convertToForceGraph3DFormat(depGraph *graph.DependencyGraph, config Config) *ForceGraph3DGraph {
+	fgGraph := &ForceGraph3DGraph{
+		Metadata: depGraph.Metadata,
+		Nodes:    make([]ForceGraph3DNode, 0, len(depGraph.Nodes)),
+		Links:    make([]ForceGraph3DLink, 0),
+	}
+	palette, _ := paletteOf(config) // Write reports invalid palettes
+
+	for _, node := range depGraph.Nodes {
+		fgGraph.Nodes = append(fgGraph.Nodes, ForceGraph3DNode{
+			ID:      node.ID,
+			Name:    node.Name,
+			Kind:    string(node.Kind),
+			Package: node.Package,
+			Group:   config.GroupOf(node),
+			Color:   palette.nodeColor(node),
+			Owner:   node.Owner,
+			File:    node.File,
+			Line:    node.Line,
+			Doc:     node.Summary(),
+			Attrs:   node.Attrs,
+		})
+	}
+
+	// 3d-force-graph fails on links to unknown nodes, so drop dangling edges
+	for sourceID, targets := range depGraph.Edges {
+		if _, exists := depGraph.Nodes[sourceID]; !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			fgGraph.Links = append(fgGraph.Links, ForceGraph3DLink{
+				Source: sourceID,
+				Target: targetID,
+				Attrs:  depGraph.EdgeAttrsOf(sourceID, targetID),
+			})
+		}
+	}
+
+	return fgGraph
+}
+
+// writeForceGraph3DHTML generates a self-contained HTML page with embedded 3d-force-graph
+func writeForceGraph3DHTML(writer io.Writer, fgGraph *ForceGraph3DGraph, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(forceGraph3DTemplateFS, "templates/forcegraph3d.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the graph data to JSON
+	jsonData, err := json.Marshal(fgGraph)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
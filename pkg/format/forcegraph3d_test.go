@@ -0,0 +1,67 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestForceGraph3DWriter_Write_HTMLByDefault(t *testing.T) {
+	depGraph := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"pkg::func1": {ID: "pkg::func1", Name: "func1", Kind: graph.KindFunction, Package: "pkg"},
+			"pkg::Type1": {ID: "pkg::Type1", Name: "Type1", Kind: graph.KindType, Package: "pkg"},
+		},
+		Edges: map[string][]string{
+			"pkg::func1": {"pkg::Type1", "pkg::missing"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&ForceGraph3DWriter{}).Write(&buf, depGraph, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") {
+		t.Error("Output should be an HTML page by default")
+	}
+	if !strings.Contains(output, "3d-force-graph") {
+		t.Error("Output should load 3d-force-graph")
+	}
+	if !strings.Contains(output, "escapeHTML(n.doc)") {
+		t.Error("Node labels should escape doc comments")
+	}
+}
+
+func TestForceGraph3DWriter_Write_JSON(t *testing.T) {
+	depGraph := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"pkg::func1": {ID: "pkg::func1", Name: "func1", Kind: graph.KindFunction, Package: "pkg"},
+			"pkg::Type1": {ID: "pkg::Type1", Name: "Type1", Kind: graph.KindType, Package: "pkg"},
+		},
+		Edges: map[string][]string{
+			"pkg::func1": {"pkg::Type1", "pkg::missing"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&ForceGraph3DWriter{}).Write(&buf, depGraph, Config{"htmlPage": false}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result ForceGraph3DGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	if len(result.Nodes) != 2 {
+		t.Errorf("Expected 2 nodes, got %d", len(result.Nodes))
+	}
+	if len(result.Links) != 1 {
+		t.Errorf("Expected dangling link to be dropped, got %d links", len(result.Links))
+	}
+}
@@ -0,0 +1,271 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-depmap/pkg/check"
+	"go-depmap/pkg/graph"
+)
+
+// ghSummaryMaxCycles caps the number of cycles listed in the job summary
+const ghSummaryMaxCycles = 10
+
+// GHSummaryWriter writes a Markdown job summary for GitHub Actions, meant to be appended to
+// $GITHUB_STEP_SUMMARY: headline stats, dependency cycles and a Mermaid diagram of the
+// package dependencies. With the "baseline" config key pointing at the JSON output of an
+// earlier run, stats show their change and only new cycles are listed. The thresholds of
+// Config.CheckOptions add a rule violations section.
+type GHSummaryWriter struct{}
+
+// Write renders the job summary
+func (w *GHSummaryWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	var baseline *graph.DependencyGraph
+	if path := config.GetString("baseline", ""); path != "" {
+		var err error
+		if baseline, err = loadBaselineGraph(path); err != nil {
+			return err
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Dependency map\n\n")
+	writeGHSummaryStats(&sb, depGraph, baseline)
+	writeGHSummaryCycles(&sb, depGraph, baseline)
+	writeGHSummaryViolations(&sb, depGraph, config.CheckOptions())
+	writeGHSummaryMermaid(&sb, depGraph, config.GetInt("mermaidMaxEdges", 50))
+	if depGraph.Metadata != nil {
+		fmt.Fprintf(&sb, "\n<sub>Generated by %s</sub>\n", depGraph.Metadata)
+	}
+
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+// loadBaselineGraph reads a graph written by the JSON writer
+func loadBaselineGraph(path string) (*graph.DependencyGraph, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - the baseline is explicitly chosen by the user
+	if err != nil {
+		return nil, fmt.Errorf("reading baseline: %w", err)
+	}
+	var baseline graph.DependencyGraph
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("parsing baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// ghSummaryStats are the headline numbers of a graph
+type ghSummaryStats struct {
+	nodes, edges, packages, cycles int
+}
+
+func computeGHSummaryStats(g *graph.DependencyGraph) ghSummaryStats {
+	packages := make(map[string]bool)
+	for _, node := range g.Nodes {
+		packages[node.Package] = true
+	}
+	return ghSummaryStats{
+		nodes:    len(g.Nodes),
+		edges:    g.CountEdges(),
+		packages: len(packages),
+		cycles:   len(g.FindCycles()),
+	}
+}
+
+// writeGHSummaryStats writes the stats table, with a change column when there is a baseline
+func writeGHSummaryStats(sb *strings.Builder, g, baseline *graph.DependencyGraph) {
+	current := computeGHSummaryStats(g)
+	rows := []struct {
+		label string
+		value int
+		base  func(ghSummaryStats) int
+	}{
+		{"Nodes", current.nodes, func(s ghSummaryStats) int { return s.nodes }},
+		{"Edges", current.edges, func(s ghSummaryStats) int { return s.edges }},
+		{"Packages", current.packages, func(s ghSummaryStats) int { return s.packages }},
+		{"Cycles", current.cycles, func(s ghSummaryStats) int { return s.cycles }},
+	}
+
+	sb.WriteString("## Stats\n\n")
+	if baseline == nil {
+		sb.WriteString("| Metric | Value |\n|---|---:|\n")
+		for _, row := range rows {
+			fmt.Fprintf(sb, "| %s | %d |\n", row.label, row.value)
+		}
+		sb.WriteString("\n")
+		return
+	}
+
+	base := computeGHSummaryStats(baseline)
+	sb.WriteString("| Metric | Value | Change |\n|---|---:|---:|\n")
+	for _, row := range rows {
+		fmt.Fprintf(sb, "| %s | %d | %+d |\n", row.label, row.value, row.value-row.base(base))
+	}
+	sb.WriteString("\n")
+}
+
+// writeGHSummaryCycles lists the cycles, or only those not in the baseline
+func writeGHSummaryCycles(sb *strings.Builder, g, baseline *graph.DependencyGraph) {
+	cycles := g.FindCycles()
+	title := "Cycles"
+	if baseline != nil {
+		cycles = newCycles(cycles, baseline.FindCycles())
+		title = "New cycles"
+	}
+
+	fmt.Fprintf(sb, "## %s\n\n", title)
+	if len(cycles) == 0 {
+		sb.WriteString(":white_check_mark: None\n\n")
+		return
+	}
+
+	fmt.Fprintf(sb, ":warning: %d cycle(s)\n\n", len(cycles))
+	for i, cycle := range cycles {
+		if i == ghSummaryMaxCycles {
+			fmt.Fprintf(sb, "\n…and %d more\n", len(cycles)-ghSummaryMaxCycles)
+			break
+		}
+		fmt.Fprintf(sb, "%d. %d nodes: `%s`\n", i+1, len(cycle), strings.Join(cycle, "`, `"))
+	}
+	sb.WriteString("\n")
+}
+
+// writeGHSummaryViolations lists the violations of the threshold rules. The cycles rule is
+// left out, as the cycles have their own section.
+func writeGHSummaryViolations(sb *strings.Builder, g *graph.DependencyGraph, opts check.Options) {
+	results := check.Run(g, check.Rules(opts))
+	results = slices.DeleteFunc(results, func(r check.Result) bool { return r.Rule == "cycles" })
+	if len(results) == 0 {
+		return
+	}
+
+	sb.WriteString("## Rule violations\n\n")
+	sb.WriteString("| Rule | Subject | Violation |\n|---|---|---|\n")
+	for _, result := range results {
+		if result.Passed() {
+			fmt.Fprintf(sb, "| %s | | :white_check_mark: %s |\n", result.Rule, result.Description)
+			continue
+		}
+		for _, v := range result.Violations {
+			fmt.Fprintf(sb, "| %s | `%s` | :x: %s |\n", result.Rule, v.Subject, v.Message)
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// newCycles returns the cycles whose members do not form a cycle of the baseline. A cycle
+// that grew or shrank counts as new.
+func newCycles(cycles, baselineCycles [][]string) [][]string {
+	known := make(map[string]bool, len(baselineCycles))
+	for _, cycle := range baselineCycles {
+		known[strings.Join(cycle, "\n")] = true
+	}
+
+	result := make([][]string, 0)
+	for _, cycle := range cycles {
+		if !known[strings.Join(cycle, "\n")] {
+			result = append(result, cycle)
+		}
+	}
+	return result
+}
+
+// packageEdge is a dependency between two packages with the number of symbol edges behind it
+type packageEdge struct {
+	source, target string
+	count          int
+}
+
+// writeGHSummaryMermaid writes a Mermaid flowchart of the heaviest package dependencies
+func writeGHSummaryMermaid(sb *strings.Builder, g *graph.DependencyGraph, maxEdges int) {
+	counts := make(map[[2]string]int)
+	for sourceID, targets := range g.Edges {
+		source, exists := g.Nodes[sourceID]
+		if !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			target, exists := g.Nodes[targetID]
+			if !exists || source.Package == target.Package {
+				continue
+			}
+			counts[[2]string{source.Package, target.Package}]++
+		}
+	}
+
+	edges := make([]packageEdge, 0, len(counts))
+	for pair, count := range counts {
+		edges = append(edges, packageEdge{source: pair[0], target: pair[1], count: count})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].count != edges[j].count {
+			return edges[i].count > edges[j].count
+		}
+		if edges[i].source != edges[j].source {
+			return edges[i].source < edges[j].source
+		}
+		return edges[i].target < edges[j].target
+	})
+
+	sb.WriteString("## Package dependencies\n\n")
+	if len(edges) == 0 {
+		sb.WriteString("No dependencies between packages.\n")
+		return
+	}
+	shown := edges
+	if maxEdges > 0 && len(shown) > maxEdges {
+		shown = shown[:maxEdges]
+	}
+
+	// Mermaid IDs must be simple identifiers; packages are labeled without the common prefix
+	packageIDs := make(map[string]string)
+	packageNames := make([]string, 0)
+	for _, e := range shown {
+		for _, pkgName := range []string{e.source, e.target} {
+			if _, exists := packageIDs[pkgName]; !exists {
+				packageIDs[pkgName] = ""
+				packageNames = append(packageNames, pkgName)
+			}
+		}
+	}
+	sort.Strings(packageNames)
+	prefix := commonPackagePrefix(packageNames)
+
+	sb.WriteString("```mermaid\nflowchart LR\n")
+	for i, pkgName := range packageNames {
+		packageIDs[pkgName] = fmt.Sprintf("p%d", i)
+		fmt.Fprintf(sb, "    p%d[\"%s\"]\n", i, strings.TrimPrefix(pkgName, prefix))
+	}
+	for _, e := range shown {
+		fmt.Fprintf(sb, "    %s -->|%d| %s\n", packageIDs[e.source], e.count, packageIDs[e.target])
+	}
+	sb.WriteString("```\n")
+
+	if len(edges) > len(shown) {
+		fmt.Fprintf(sb, "\nShowing the %d heaviest of %d package dependencies.\n", len(shown), len(edges))
+	}
+}
+
+// commonPackagePrefix returns the longest common path prefix of the packages, ending in a slash,
+// or "" when they share none or there is only one package
+func commonPackagePrefix(packages []string) string {
+	if len(packages) < 2 {
+		return ""
+	}
+	prefix := packages[0]
+	for _, pkgName := range packages[1:] {
+		for !strings.HasPrefix(pkgName, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		return prefix[:i+1]
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func newGHSummaryTestGraph() *graph.DependencyGraph {
+	g := graph.NewDependencyGraph()
+	for _, id := range []string{"example.com/app/api::Handle", "example.com/app/db::Open", "example.com/app/db::Close"} {
+		pkgName, name, _ := strings.Cut(id, "::")
+		g.Nodes[id] = &graph.Node{ID: id, Name: name, Kind: graph.KindFunction, Package: pkgName}
+	}
+	g.AddEdge("example.com/app/api::Handle", "example.com/app/db::Open")
+	g.AddEdge("example.com/app/db::Open", "example.com/app/db::Close")
+	g.AddEdge("example.com/app/db::Close", "example.com/app/db::Open")
+	return g
+}
+
+func TestGHSummaryWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&GHSummaryWriter{}).Write(&buf, newGHSummaryTestGraph(), Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := buf.String()
+
+	for _, expected := range []string{
+		"| Nodes | 3 |",
+		"| Cycles | 1 |",
+		"## Cycles",
+		"`example.com/app/db::Close`, `example.com/app/db::Open`",
+		"```mermaid\nflowchart LR\n",
+		`p0["api"]`,
+		"p0 -->|1| p1",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Output should contain %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestGHSummaryWriter_Baseline(t *testing.T) {
+	baseline := newGHSummaryTestGraph()
+	data, err := json.Marshal(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	g := newGHSummaryTestGraph()
+	g.Nodes["example.com/app/api::Route"] = &graph.Node{ID: "example.com/app/api::Route", Name: "Route", Package: "example.com/app/api"}
+	g.AddEdge("example.com/app/api::Handle", "example.com/app/api::Route")
+	g.AddEdge("example.com/app/api::Route", "example.com/app/api::Handle")
+
+	var buf bytes.Buffer
+	if err := (&GHSummaryWriter{}).Write(&buf, g, Config{"baseline": path}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := buf.String()
+
+	if !strings.Contains(output, "| Nodes | 4 | +1 |") {
+		t.Errorf("Expected the node count change:\n%s", output)
+	}
+	if !strings.Contains(output, "## New cycles") || strings.Contains(output, "db::Close") {
+		t.Errorf("Only the new api cycle should be listed:\n%s", output)
+	}
+
+	if err := (&GHSummaryWriter{}).Write(&buf, g, Config{"baseline": filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("Expected an error for a missing baseline")
+	}
+}
+
+func TestGHSummaryWriter_Violations(t *testing.T) {
+	var buf bytes.Buffer
+	config := Config{"maxFanIn": 1, "maxPackageDeps": 1}
+	if err := (&GHSummaryWriter{}).Write(&buf, newGHSummaryTestGraph(), config); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := buf.String()
+
+	for _, expected := range []string{
+		"## Rule violations",
+		"| max-fan-in | `example.com/app/db::Open` | :x: 2 dependents, limit is 1 |",
+		"| max-package-deps | | :white_check_mark: At most 1 package dependencies per package |",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Output should contain %q:\n%s", expected, output)
+		}
+	}
+
+	buf.Reset()
+	_ = (&GHSummaryWriter{}).Write(&buf, newGHSummaryTestGraph(), Config{})
+	if strings.Contains(buf.String(), "## Rule violations") {
+		t.Error("The rule violations section should be omitted without thresholds")
+	}
+}
+
+func TestCommonPackagePrefix(t *testing.T) {
+	tests := []struct {
+		packages []string
+		expected string
+	}{
+		{packages: []string{"example.com/app/api", "example.com/app/db"}, expected: "example.com/app/"},
+		{packages: []string{"example.com/app", "example.com/app/db"}, expected: "example.com/"},
+		{packages: []string{"api", "db"}, expected: ""},
+		{packages: []string{"example.com/app"}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		if got := commonPackagePrefix(tt.packages); got != tt.expected {
+			t.Errorf("commonPackagePrefix(%v) = %q, want %q", tt.packages, got, tt.expected)
+		}
+	}
+}
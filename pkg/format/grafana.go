@@ -0,0 +1,202 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// GrafanaNodeGraphWriter writes the graph as the two data frames of Grafana's Node Graph
+// panel, nodes and edges, in the data frame JSON of the TestData "Raw frames" scenario and
+// of data source plugins. Nodes show their fan-in and fan-out as stats and their kind as an
+// arc; the other node data and the custom attributes are detail fields.
+type GrafanaNodeGraphWriter struct{}
+
+// GrafanaFrame is a data frame: its schema and its values, one column per field
+type GrafanaFrame struct {
+	Schema GrafanaSchema `json:"schema"`
+	Data   GrafanaData   `json:"data"`
+}
+
+// GrafanaSchema names the frame and its fields
+type GrafanaSchema struct {
+	Name   string         `json:"name"` // "nodes" or "edges"
+	Meta   GrafanaMeta    `json:"meta"`
+	Fields []GrafanaField `json:"fields"`
+}
+
+// GrafanaMeta tells Grafana to show the frame in a Node Graph panel
+type GrafanaMeta struct {
+	PreferredVisualisationType string         `json:"preferredVisualisationType"`
+	Custom                     map[string]any `json:"custom,omitempty"` // Provenance of the graph, on the nodes frame
+}
+
+// GrafanaField is a column of a frame. The Node Graph panel recognizes its columns by name:
+// id, title, subtitle, mainstat, secondarystat, arc__*, detail__*, source and target.
+type GrafanaField struct {
+	Name   string              `json:"name"`
+	Type   string              `json:"type"` // "string" or "number"
+	Config *GrafanaFieldConfig `json:"config,omitempty"`
+}
+
+// GrafanaFieldConfig sets the display name of a field, and the color of an arc
+type GrafanaFieldConfig struct {
+	DisplayName string             `json:"displayName,omitempty"`
+	Color       *GrafanaFieldColor `json:"color,omitempty"`
+}
+
+// GrafanaFieldColor is a fixed color
+type GrafanaFieldColor struct {
+	Mode       string `json:"mode"` // Always "fixed"
+	FixedColor string `json:"fixedColor"`
+}
+
+// GrafanaData holds the values of a frame, one slice per field
+type GrafanaData struct {
+	Values [][]any `json:"values"`
+}
+
+// grafanaColumn is a field with its value for each row
+type grafanaColumn struct {
+	field GrafanaField
+	value func(index int) any
+}
+
+// Write generates the nodes and edges frames as a JSON array
+func (w *GrafanaNodeGraphWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(convertToGrafanaFrames(depGraph))
+}
+
+// convertToGrafanaFrames converts a DependencyGraph to the nodes and edges frames
+func convertToGrafanaFrames(depGraph *graph.DependencyGraph) []GrafanaFrame {
+	nodeIDs := make([]string, 0, len(depGraph.Nodes))
+	for nodeID := range depGraph.Nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+	nodes := make([]*graph.Node, len(nodeIDs))
+	nodeAttrs := make([]map[string]any, len(nodeIDs))
+	kinds := make(map[graph.NodeKind]bool)
+	for i, nodeID := range nodeIDs {
+		nodes[i] = depGraph.Nodes[nodeID]
+		nodeAttrs[i] = nodes[i].Attrs
+		kinds[nodes[i].Kind] = true
+	}
+	fanIn, fanOut := depGraph.FanIn(), depGraph.FanOut()
+
+	nodeColumns := []grafanaColumn{
+		{GrafanaField{Name: "id", Type: "string"}, func(i int) any { return nodes[i].ID }},
+		{GrafanaField{Name: "title", Type: "string"}, func(i int) any { return nodes[i].Name }},
+		{GrafanaField{Name: "subtitle", Type: "string"}, func(i int) any { return nodes[i].Package }},
+		{GrafanaField{Name: "mainstat", Type: "number", Config: &GrafanaFieldConfig{DisplayName: "Fan-in"}}, func(i int) any { return fanIn[nodes[i].ID] }},
+		{GrafanaField{Name: "secondarystat", Type: "number", Config: &GrafanaFieldConfig{DisplayName: "Fan-out"}}, func(i int) any { return fanOut[nodes[i].ID] }},
+	}
+	// One arc per kind, filled for the nodes of that kind, so that nodes are colored by kind
+	for _, kind := range slices.Sorted(maps.Keys(kinds)) {
+		nodeColumns = append(nodeColumns, grafanaColumn{
+			GrafanaField{Name: "arc__" + string(kind), Type: "number", Config: &GrafanaFieldConfig{
+				DisplayName: string(kind),
+				Color:       &GrafanaFieldColor{Mode: "fixed", FixedColor: kindColor(kind)},
+			}},
+			func(i int) any {
+				if nodes[i].Kind == kind {
+					return 1
+				}
+				return 0
+			},
+		})
+	}
+	nodeColumns = append(nodeColumns,
+		grafanaColumn{GrafanaField{Name: "detail__kind", Type: "string", Config: &GrafanaFieldConfig{DisplayName: "Kind"}}, func(i int) any { return string(nodes[i].Kind) }},
+		grafanaColumn{GrafanaField{Name: "detail__file", Type: "string", Config: &GrafanaFieldConfig{DisplayName: "File"}}, func(i int) any {
+			if nodes[i].File == "" {
+				return ""
+			}
+			return fmt.Sprintf("%s:%d", nodes[i].File, nodes[i].Line)
+		}},
+		grafanaColumn{GrafanaField{Name: "detail__signature", Type: "string", Config: &GrafanaFieldConfig{DisplayName: "Signature"}}, func(i int) any { return nodes[i].Signature }},
+		grafanaColumn{GrafanaField{Name: "detail__owner", Type: "string", Config: &GrafanaFieldConfig{DisplayName: "Owner"}}, func(i int) any { return nodes[i].Owner }},
+	)
+	nodeColumns = append(nodeColumns, grafanaAttrColumns(nodeAttrs)...)
+
+	type edge struct{ source, target string }
+	var edges []edge
+	var edgeAttrs []map[string]any
+	for _, sourceID := range nodeIDs {
+		for _, targetID := range depGraph.Edges[sourceID] {
+			if _, exists := depGraph.Nodes[targetID]; exists {
+				edges = append(edges, edge{sourceID, targetID})
+				edgeAttrs = append(edgeAttrs, depGraph.EdgeAttrsOf(sourceID, targetID))
+			}
+		}
+	}
+	edgeColumns := []grafanaColumn{
+		{GrafanaField{Name: "id", Type: "string"}, func(i int) any { return edges[i].source + "->" + edges[i].target }},
+		{GrafanaField{Name: "source", Type: "string"}, func(i int) any { return edges[i].source }},
+		{GrafanaField{Name: "target", Type: "string"}, func(i int) any { return edges[i].target }},
+		{GrafanaField{Name: "mainstat", Type: "string", Config: &GrafanaFieldConfig{DisplayName: "Kinds"}}, func(i int) any {
+			kinds := depGraph.EdgeKindsOf(edges[i].source, edges[i].target)
+			names := make([]string, len(kinds))
+			for j, kind := range kinds {
+				names[j] = string(kind)
+			}
+			return strings.Join(names, ", ")
+		}},
+	}
+	edgeColumns = append(edgeColumns, grafanaAttrColumns(edgeAttrs)...)
+
+	nodesFrame := grafanaFrame("nodes", nodeColumns, len(nodes))
+	if depGraph.Metadata != nil {
+		nodesFrame.Schema.Meta.Custom = map[string]any{"metadata": depGraph.Metadata}
+	}
+	return []GrafanaFrame{nodesFrame, grafanaFrame("edges", edgeColumns, len(edges))}
+}
+
+// grafanaAttrColumns returns a detail column per custom attribute key of the rows, empty
+// for the rows without the attribute
+func grafanaAttrColumns(attrs []map[string]any) []grafanaColumn {
+	keys := graph.AttrKeys(attrs...)
+	columns := make([]grafanaColumn, 0, len(keys))
+	for _, key := range keys {
+		columns = append(columns, grafanaColumn{
+			GrafanaField{Name: "detail__attr_" + key, Type: "string", Config: &GrafanaFieldConfig{DisplayName: key}},
+			func(i int) any {
+				if value, set := attrs[i][key]; set {
+					return fmt.Sprint(value)
+				}
+				return ""
+			},
+		})
+	}
+	return columns
+}
+
+// grafanaFrame builds a frame of the given columns and number of rows
+func grafanaFrame(name string, columns []grafanaColumn, rows int) GrafanaFrame {
+	frame := GrafanaFrame{
+		Schema: GrafanaSchema{Name: name, Meta: GrafanaMeta{PreferredVisualisationType: "nodeGraph"}},
+		Data:   GrafanaData{Values: make([][]any, 0, len(columns))},
+	}
+	for _, column := range columns {
+		frame.Schema.Fields = append(frame.Schema.Fields, column.field)
+		values := make([]any, rows)
+		for i := range values {
+			values[i] = column.value(i)
+		}
+		frame.Data.Values = append(frame.Data.Values, values)
+	}
+	return frame
+}
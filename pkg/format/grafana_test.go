@@ -0,0 +1,69 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestGrafanaNodeGraphWriter_Write(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Metadata = &graph.Metadata{Tool: "go-depmap", Module: "example.com/shop"}
+	g.Nodes["pkg::Serve"] = &graph.Node{ID: "pkg::Serve", Name: "Serve", Kind: graph.KindFunction, Package: "pkg", File: "serve.go", Line: 7}
+	g.Nodes["pkg::Conn"] = &graph.Node{ID: "pkg::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "pkg", Attrs: map[string]any{"team": "db"}}
+	g.AddEdge("pkg::Serve", "pkg::Conn", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("pkg::Serve", "pkg::missing")
+	g.SetEdgeAttr("pkg::Serve", "pkg::Conn", "weight", 2)
+
+	var buf bytes.Buffer
+	if err := (&GrafanaNodeGraphWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var frames []GrafanaFrame
+	if err := json.Unmarshal(buf.Bytes(), &frames); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if len(frames) != 2 || frames[0].Schema.Name != "nodes" || frames[1].Schema.Name != "edges" {
+		t.Fatalf("Expected the nodes and edges frames, got %+v", frames)
+	}
+	if frames[0].Schema.Meta.PreferredVisualisationType != "nodeGraph" || frames[0].Schema.Meta.Custom["metadata"] == nil {
+		t.Errorf("Expected the node graph visualization and the metadata, got %+v", frames[0].Schema.Meta)
+	}
+
+	// Columns by field name
+	columns := func(frame GrafanaFrame) map[string][]any {
+		byName := make(map[string][]any)
+		for i, field := range frame.Schema.Fields {
+			if len(frame.Data.Values[i]) != len(frame.Data.Values[0]) {
+				t.Errorf("Column %s of %s has %d values, want %d", field.Name, frame.Schema.Name, len(frame.Data.Values[i]), len(frame.Data.Values[0]))
+			}
+			byName[field.Name] = frame.Data.Values[i]
+		}
+		return byName
+	}
+	nodes := columns(frames[0])
+	expected := map[string][]any{
+		"id":                {"pkg::Conn", "pkg::Serve"},
+		"title":             {"Conn", "Serve"},
+		"mainstat":          {float64(1), float64(0)},
+		"secondarystat":     {float64(0), float64(1)},
+		"arc__function":     {float64(0), float64(1)},
+		"arc__struct":       {float64(1), float64(0)},
+		"detail__file":      {"", "serve.go:7"},
+		"detail__attr_team": {"db", ""},
+	}
+	for name, want := range expected {
+		if got := nodes[name]; !slices.Equal(got, want) {
+			t.Errorf("Nodes column %s = %v, want %v", name, got, want)
+		}
+	}
+
+	edges := columns(frames[1])
+	if !slices.Equal(edges["source"], []any{"pkg::Serve"}) || !slices.Equal(edges["target"], []any{"pkg::Conn"}) ||
+		!slices.Equal(edges["mainstat"], []any{"signature, param"}) || !slices.Equal(edges["detail__attr_weight"], []any{"2"}) {
+		t.Errorf("Unexpected edges frame: %v", edges)
+	}
+}
@@ -0,0 +1,362 @@
+package format
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+const (
+	// graphMLNamespace is the XML namespace of GraphML
+	graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+	// yEdNamespace is the XML namespace of yEd's graphics extensions, bound to the y prefix
+	yEdNamespace = "http://www.yworks.com/xml/graphml"
+)
+
+// GraphMLWriter writes the graph as GraphML, the node and edge data as GraphML data, for
+// graph tools such as Gephi, NetworkX and yEd. With the "yed" config key, the nodes also get
+// yEd's graphics: shapes filled with the color of their kind and labeled with their name,
+// inside a group node per package (or per group of "groupBy"), so that the graph needs no
+// restyling in yEd, only a layout (Layout > Hierarchical).
+type GraphMLWriter struct{}
+
+// GraphMLDocument is the root <graphml> element
+type GraphMLDocument struct {
+	XMLName    xml.Name     `xml:"graphml"`
+	Comment    string       `xml:",comment"` // Provenance of the graph, see graph.Metadata
+	Namespace  string       `xml:"xmlns,attr"`
+	YNamespace string       `xml:"xmlns:y,attr,omitempty"`
+	Keys       []GraphMLKey `xml:"key"`
+	Graph      GraphMLGraph `xml:"graph"`
+}
+
+// GraphMLKey declares a data key: a named attribute of the nodes or edges, or with a yfiles
+// type, where yEd keeps its graphics
+type GraphMLKey struct {
+	ID         string `xml:"id,attr"`
+	For        string `xml:"for,attr"` // "node" or "edge"
+	Name       string `xml:"attr.name,attr,omitempty"`
+	Type       string `xml:"attr.type,attr,omitempty"` // "string" or "int"
+	YFilesType string `xml:"yfiles.type,attr,omitempty"`
+}
+
+// GraphMLGraph is a <graph> element, the top-level graph or the content of a group node
+type GraphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []GraphMLNode `xml:"node"`
+	Edges       []GraphMLEdge `xml:"edge"`
+}
+
+// GraphMLNode is a <node> element; group nodes have a nested graph
+type GraphMLNode struct {
+	ID         string        `xml:"id,attr"`
+	FolderType string        `xml:"yfiles.foldertype,attr,omitempty"` // "group" for yEd group nodes
+	Data       []GraphMLData `xml:"data"`
+	Graph      *GraphMLGraph `xml:"graph"`
+}
+
+// GraphMLEdge is an <edge> element
+type GraphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []GraphMLData `xml:"data"`
+}
+
+// GraphMLData is a <data> element: a value, or the yEd graphics of a node or edge
+type GraphMLData struct {
+	Key       string                  `xml:"key,attr"`
+	Value     string                  `xml:",chardata"`
+	ShapeNode *YEdShapeNode           `xml:"y:ShapeNode"`
+	GroupNode *YEdProxyAutoBoundsNode `xml:"y:ProxyAutoBoundsNode"`
+	Edge      *YEdPolyLineEdge        `xml:"y:PolyLineEdge"`
+}
+
+// YEdShapeNode is the graphics of a symbol node
+type YEdShapeNode struct {
+	Geometry YEdGeometry    `xml:"y:Geometry"`
+	Fill     YEdFill        `xml:"y:Fill"`
+	Border   YEdBorderStyle `xml:"y:BorderStyle"`
+	Label    YEdNodeLabel   `xml:"y:NodeLabel"`
+	Shape    YEdShape       `xml:"y:Shape"`
+}
+
+// YEdProxyAutoBoundsNode is the graphics of a group node, sized by yEd to fit its content
+type YEdProxyAutoBoundsNode struct {
+	Realizers YEdRealizers `xml:"y:Realizers"`
+}
+
+// YEdRealizers holds the graphics of a group node; the active one is shown
+type YEdRealizers struct {
+	Active     int            `xml:"active,attr"`
+	GroupNodes []YEdGroupNode `xml:"y:GroupNode"`
+}
+
+// YEdGroupNode is the graphics of an open group node
+type YEdGroupNode struct {
+	Fill   YEdFill        `xml:"y:Fill"`
+	Border YEdBorderStyle `xml:"y:BorderStyle"`
+	Label  YEdNodeLabel   `xml:"y:NodeLabel"`
+	Shape  YEdShape       `xml:"y:Shape"`
+	State  YEdState       `xml:"y:State"`
+}
+
+// YEdGeometry is the size of a node; yEd places the nodes when a layout is run
+type YEdGeometry struct {
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+}
+
+// YEdFill is the background of a node
+type YEdFill struct {
+	Color       string `xml:"color,attr"`
+	Transparent bool   `xml:"transparent,attr"`
+}
+
+// YEdBorderStyle is the border of a node
+type YEdBorderStyle struct {
+	Color string  `xml:"color,attr"`
+	Type  string  `xml:"type,attr"` // "line" or "dashed"
+	Width float64 `xml:"width,attr"`
+}
+
+// YEdNodeLabel is the label of a node
+type YEdNodeLabel struct {
+	Text          string `xml:",chardata"`
+	FontSize      int    `xml:"fontSize,attr"`
+	TextColor     string `xml:"textColor,attr"`
+	ModelName     string `xml:"modelName,attr"`
+	ModelPosition string `xml:"modelPosition,attr"`
+	Alignment     string `xml:"alignment,attr,omitempty"`
+}
+
+// YEdShape is the shape of a node
+type YEdShape struct {
+	Type string `xml:"type,attr"`
+}
+
+// YEdState says whether a group node is closed
+type YEdState struct {
+	Closed bool `xml:"closed,attr"`
+}
+
+// YEdPolyLineEdge is the graphics of an edge
+type YEdPolyLineEdge struct {
+	LineStyle YEdBorderStyle `xml:"y:LineStyle"`
+	Arrows    YEdArrows      `xml:"y:Arrows"`
+}
+
+// YEdArrows are the arrowheads of an edge
+type YEdArrows struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+// graphMLNodeKeys are the data keys of the node fields, in the order of their data
+var graphMLNodeKeys = []GraphMLKey{
+	{ID: "id", For: "node", Name: "id", Type: "string"},
+	{ID: "name", For: "node", Name: "name", Type: "string"},
+	{ID: "kind", For: "node", Name: "kind", Type: "string"},
+	{ID: "package", For: "node", Name: "package", Type: "string"},
+	{ID: "file", For: "node", Name: "file", Type: "string"},
+	{ID: "line", For: "node", Name: "line", Type: "int"},
+	{ID: "signature", For: "node", Name: "signature", Type: "string"},
+}
+
+// Write formats the graph as GraphML
+func (w *GraphMLWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	document := convertToGraphMLFormat(depGraph, config)
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.Indent("", "  ")
+	}
+
+	if err := enc.Encode(document); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// convertToGraphMLFormat converts a DependencyGraph to GraphML, with yEd graphics and group
+// nodes when the "yed" config key is set
+func convertToGraphMLFormat(depGraph *graph.DependencyGraph, config Config) *GraphMLDocument {
+	yed := config.GetBool("yed", false)
+	document := &GraphMLDocument{
+		Namespace: graphMLNamespace,
+		Keys:      slices.Clone(graphMLNodeKeys),
+		Graph:     GraphMLGraph{ID: "G", EdgeDefault: "directed"},
+	}
+	if depGraph.Metadata != nil {
+		// "--" may not appear in XML comments
+		document.Comment = " " + strings.ReplaceAll(depGraph.Metadata.String(), "--", "- -") + " "
+	}
+
+	// Sort node IDs so that the output is stable across runs (diff-friendly); GraphML IDs
+	// are generated, since node IDs are not valid XML tokens
+	nodeIDs := slices.Sorted(maps.Keys(depGraph.Nodes))
+	xmlIDs := make(map[string]string, len(nodeIDs))
+	var nodeAttrs []map[string]any
+	for i, nodeID := range nodeIDs {
+		xmlIDs[nodeID] = "n" + strconv.Itoa(i)
+		nodeAttrs = append(nodeAttrs, depGraph.Nodes[nodeID].Attrs)
+	}
+	var edgeAttrs []map[string]any
+	for _, sourceID := range nodeIDs {
+		for _, targetID := range depGraph.Edges[sourceID] {
+			edgeAttrs = append(edgeAttrs, depGraph.EdgeAttrsOf(sourceID, targetID))
+		}
+	}
+	for _, key := range graph.AttrKeys(nodeAttrs...) {
+		document.Keys = append(document.Keys, GraphMLKey{ID: "attr_" + key, For: "node", Name: key, Type: "string"})
+	}
+	document.Keys = append(document.Keys, GraphMLKey{ID: "kinds", For: "edge", Name: "kinds", Type: "string"})
+	for _, key := range graph.AttrKeys(edgeAttrs...) {
+		document.Keys = append(document.Keys, GraphMLKey{ID: "edge_attr_" + key, For: "edge", Name: key, Type: "string"})
+	}
+	if yed {
+		document.YNamespace = yEdNamespace
+		document.Keys = append(document.Keys,
+			GraphMLKey{ID: "nodegraphics", For: "node", YFilesType: "nodegraphics"},
+			GraphMLKey{ID: "edgegraphics", For: "edge", YFilesType: "edgegraphics"},
+		)
+	}
+
+	// Symbol nodes, at the top level or in the group node of their group
+	groups := make(map[string]*GraphMLNode)
+	var groupNames []string
+	for _, nodeID := range nodeIDs {
+		node := depGraph.Nodes[nodeID]
+		graphMLNode := GraphMLNode{ID: xmlIDs[nodeID], Data: graphMLNodeData(node)}
+		if !yed {
+			document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode)
+			continue
+		}
+		graphMLNode.Data = append(graphMLNode.Data, GraphMLData{Key: "nodegraphics", ShapeNode: yEdShapeNodeOf(node)})
+		name := config.GroupOf(node)
+		group := groups[name]
+		if group == nil {
+			group = yEdGroupNode("g"+strconv.Itoa(len(groups)), name)
+			groups[name] = group
+			groupNames = append(groupNames, name)
+		}
+		group.Graph.Nodes = append(group.Graph.Nodes, graphMLNode)
+	}
+	slices.Sort(groupNames)
+	for _, name := range groupNames {
+		document.Graph.Nodes = append(document.Graph.Nodes, *groups[name])
+	}
+
+	// Dependency edges, at the top level, which may connect the nodes of nested graphs
+	for _, sourceID := range nodeIDs {
+		for _, targetID := range depGraph.Edges[sourceID] {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			edge := GraphMLEdge{
+				ID:     "e" + strconv.Itoa(len(document.Graph.Edges)),
+				Source: xmlIDs[sourceID],
+				Target: xmlIDs[targetID],
+			}
+			if kinds := depGraph.EdgeKindsOf(sourceID, targetID); len(kinds) > 0 {
+				names := make([]string, len(kinds))
+				for i, kind := range kinds {
+					names[i] = string(kind)
+				}
+				edge.Data = append(edge.Data, GraphMLData{Key: "kinds", Value: strings.Join(names, ",")})
+			}
+			attrs := depGraph.EdgeAttrsOf(sourceID, targetID)
+			for _, key := range graph.AttrKeys(attrs) {
+				edge.Data = append(edge.Data, GraphMLData{Key: "edge_attr_" + key, Value: fmt.Sprint(attrs[key])})
+			}
+			if yed {
+				edge.Data = append(edge.Data, GraphMLData{Key: "edgegraphics", Edge: &YEdPolyLineEdge{
+					LineStyle: YEdBorderStyle{Color: "#000000", Type: "line", Width: 1},
+					Arrows:    YEdArrows{Source: "none", Target: "standard"},
+				}})
+			}
+			document.Graph.Edges = append(document.Graph.Edges, edge)
+		}
+	}
+
+	return document
+}
+
+// graphMLNodeData returns the data of the fields of a node that are set, and of its custom
+// attributes
+func graphMLNodeData(node *graph.Node) []GraphMLData {
+	values := []string{node.ID, node.Name, string(node.Kind), node.Package, node.File, "", node.Signature}
+	if node.Line > 0 {
+		values[5] = strconv.Itoa(node.Line)
+	}
+	var data []GraphMLData
+	for i, value := range values {
+		if value != "" {
+			data = append(data, GraphMLData{Key: graphMLNodeKeys[i].ID, Value: value})
+		}
+	}
+	for _, key := range graph.AttrKeys(node.Attrs) {
+		data = append(data, GraphMLData{Key: "attr_" + key, Value: fmt.Sprint(node.Attrs[key])})
+	}
+	return data
+}
+
+// yEdShapeNodeOf returns the graphics of a symbol node: a shape by kind, filled with the
+// color of its kind, wide enough for its name, with a dashed border for external symbols
+func yEdShapeNodeOf(node *graph.Node) *YEdShapeNode {
+	shape := "roundrectangle"
+	switch {
+	case node.Kind == graph.KindInterface:
+		shape = "ellipse"
+	case node.Kind.IsType(), node.Kind == graph.KindPackage:
+		shape = "rectangle"
+	case node.Kind == graph.KindVar:
+		shape = "parallelogram"
+	}
+	border := YEdBorderStyle{Color: "#000000", Type: "line", Width: 1}
+	if node.External {
+		border.Type = "dashed"
+	}
+	return &YEdShapeNode{
+		Geometry: YEdGeometry{Width: float64(20 + 7*len(node.Name)), Height: 30},
+		Fill:     YEdFill{Color: kindColor(node.Kind)},
+		Border:   border,
+		Label:    YEdNodeLabel{Text: node.Name, FontSize: 12, TextColor: "#000000", ModelName: "internal", ModelPosition: "c"},
+		Shape:    YEdShape{Type: shape},
+	}
+}
+
+// yEdGroupNode returns an open group node labeled with the name of its group, its nested
+// graph still empty
+func yEdGroupNode(id, name string) *GraphMLNode {
+	return &GraphMLNode{
+		ID:         id,
+		FolderType: "group",
+		Data: []GraphMLData{{Key: "nodegraphics", GroupNode: &YEdProxyAutoBoundsNode{Realizers: YEdRealizers{
+			GroupNodes: []YEdGroupNode{{
+				Fill:   YEdFill{Color: "#F5F5F5"},
+				Border: YEdBorderStyle{Color: kindColor(graph.KindPackage), Type: "dashed", Width: 1},
+				Label: YEdNodeLabel{
+					Text: name, FontSize: 14, TextColor: "#000000",
+					ModelName: "internal", ModelPosition: "t", Alignment: "left",
+				},
+				Shape: YEdShape{Type: "roundrectangle"},
+			}},
+		}}}},
+		Graph: &GraphMLGraph{ID: id + ":", EdgeDefault: "directed"},
+	}
+}
@@ -0,0 +1,127 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+// graphMLData returns the data of a node or edge by key
+func graphMLData(data []GraphMLData) map[string]string {
+	byKey := make(map[string]string)
+	for _, d := range data {
+		byKey[d.Key] = strings.TrimSpace(d.Value)
+	}
+	return byKey
+}
+
+func TestGraphMLWriter_Write(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg1::Func"] = &graph.Node{ID: "pkg1::Func", Name: "Func", Kind: graph.KindFunction, Package: "pkg1", File: "a.go", Line: 3, Attrs: map[string]any{"team": "core"}}
+	g.Nodes["pkg1::Iface"] = &graph.Node{ID: "pkg1::Iface", Name: "Iface", Kind: graph.KindInterface, Package: "pkg1"}
+	g.Nodes["pkg2::Type"] = &graph.Node{ID: "pkg2::Type", Name: "Type", Kind: graph.KindStruct, Package: "pkg2", External: true}
+	g.AddEdge("pkg1::Func", "pkg1::Iface", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("pkg1::Func", "pkg2::Type")
+	g.AddEdge("pkg1::Func", "pkg2::missing")
+	g.SetEdgeAttr("pkg1::Func", "pkg2::Type", "weight", 2)
+
+	var buf bytes.Buffer
+	if err := (&GraphMLWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "y:") {
+		t.Error("Plain GraphML should not have yEd graphics")
+	}
+
+	var result GraphMLDocument
+	if err := xml.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse GraphML output: %v", err)
+	}
+	keys := make(map[string]GraphMLKey)
+	for _, key := range result.Keys {
+		keys[key.ID] = key
+	}
+	if keys["attr_team"].Name != "team" || keys["attr_team"].For != "node" || keys["edge_attr_weight"].For != "edge" {
+		t.Errorf("Expected keys for the custom attributes, got %+v", result.Keys)
+	}
+
+	if len(result.Graph.Nodes) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(result.Graph.Nodes))
+	}
+	xmlIDs := make(map[string]string)
+	for _, node := range result.Graph.Nodes {
+		data := graphMLData(node.Data)
+		xmlIDs[data["id"]] = node.ID
+		if data["id"] == "pkg1::Func" && (data["kind"] != "function" || data["line"] != "3" || data["attr_team"] != "core") {
+			t.Errorf("Unexpected data of pkg1::Func: %v", data)
+		}
+	}
+
+	// The dangling edge is dropped
+	if len(result.Graph.Edges) != 2 {
+		t.Fatalf("Expected 2 edges, got %d", len(result.Graph.Edges))
+	}
+	for _, edge := range result.Graph.Edges {
+		data := graphMLData(edge.Data)
+		switch {
+		case edge.Source == xmlIDs["pkg1::Func"] && edge.Target == xmlIDs["pkg1::Iface"]:
+			if data["kinds"] != "signature,param" {
+				t.Errorf("Expected the edge kinds, got %v", data)
+			}
+		case edge.Source == xmlIDs["pkg1::Func"] && edge.Target == xmlIDs["pkg2::Type"]:
+			if data["edge_attr_weight"] != "2" {
+				t.Errorf("Expected the edge weight, got %v", data)
+			}
+		default:
+			t.Errorf("Unexpected edge %+v", edge)
+		}
+	}
+}
+
+func TestGraphMLWriter_YEd(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg1::Func"] = &graph.Node{ID: "pkg1::Func", Name: "Func", Kind: graph.KindFunction, Package: "pkg1"}
+	g.Nodes["pkg1::Iface"] = &graph.Node{ID: "pkg1::Iface", Name: "Iface", Kind: graph.KindInterface, Package: "pkg1"}
+	g.Nodes["pkg2::Type"] = &graph.Node{ID: "pkg2::Type", Name: "Type", Kind: graph.KindStruct, Package: "pkg2", External: true}
+	g.AddEdge("pkg1::Func", "pkg1::Iface", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("pkg1::Func", "pkg2::Type")
+
+	var buf bytes.Buffer
+	if err := (&GraphMLWriter{}).Write(&buf, g, Config{"yed": true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		`xmlns:y="` + yEdNamespace + `"`,
+		`yfiles.type="nodegraphics"`,
+		`<y:Fill color="#FF9800"`,  // Function color
+		`<y:Shape type="ellipse">`, // Interface shape
+		`type="dashed"`,            // External symbol
+		`<y:Arrows source="none" target="standard">`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output should contain %s", want)
+		}
+	}
+
+	// Symbols are nested in the group node of their package
+	var result GraphMLDocument
+	if err := xml.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse GraphML output: %v", err)
+	}
+	if len(result.Graph.Nodes) != 2 {
+		t.Fatalf("Expected 2 group nodes, got %d", len(result.Graph.Nodes))
+	}
+	for i, want := range []int{2, 1} {
+		group := result.Graph.Nodes[i]
+		if group.FolderType != "group" || group.Graph == nil || len(group.Graph.Nodes) != want {
+			t.Errorf("Expected group node %d with %d nodes, got %+v", i, want, group)
+		}
+	}
+	if !strings.Contains(output, ">pkg1</y:NodeLabel>") {
+		t.Error("Group nodes should be labeled with their package")
+	}
+}
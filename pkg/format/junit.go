@@ -0,0 +1,125 @@
+package format
+
+import (
+	"encoding/xml"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"go-depmap/pkg/check"
+	"go-depmap/pkg/graph"
+)
+
+// JUnitWriter writes the results of the rule checks as JUnit XML, which most CI systems
+// display natively. Every violation is a failing test case; a rule without violations is a
+// single passing test case.
+type JUnitWriter struct{}
+
+// JUnitTestSuites is the root <testsuites> element
+type JUnitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Properties []JUnitProperty  `xml:"properties>property,omitempty"` // Provenance of the graph
+	Suites     []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitProperty is a name/value <property> element
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// JUnitTestSuite holds the test cases of one rule
+type JUnitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase is a single check; it carries a failure for a violation
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+}
+
+// JUnitFailure describes a violation
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Write runs the checks and writes their results
+func (w *JUnitWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	suites := convertToJUnit(check.Run(depGraph, check.Rules(config.CheckOptions())))
+	suites.Properties = junitProperties(depGraph.Metadata)
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(writer)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// convertToJUnit builds one test suite per rule
+func convertToJUnit(results []check.Result) *JUnitTestSuites {
+	suites := &JUnitTestSuites{Suites: make([]JUnitTestSuite, 0, len(results))}
+
+	for _, result := range results {
+		className := "depmap." + result.Rule
+		suite := JUnitTestSuite{Name: className}
+		if result.Passed() {
+			suite.Cases = append(suite.Cases, JUnitTestCase{Name: result.Description, ClassName: className})
+		}
+		for _, violation := range result.Violations {
+			suite.Cases = append(suite.Cases, JUnitTestCase{
+				Name:      violation.Subject,
+				ClassName: className,
+				Failure: &JUnitFailure{
+					Message: violation.Message,
+					Type:    result.Rule,
+					Text:    result.Description + ": " + violation.Message,
+				},
+			})
+			suite.Failures++
+		}
+		suite.Tests = len(suite.Cases)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+	return suites
+}
+
+// junitProperties lists the metadata fields as properties, flags as "flag.<name>"
+func junitProperties(metadata *graph.Metadata) []JUnitProperty {
+	if metadata == nil {
+		return nil
+	}
+	properties := []JUnitProperty{
+		{Name: "tool", Value: metadata.Tool},
+		{Name: "version", Value: metadata.Version},
+		{Name: "commit", Value: metadata.Commit},
+		{Name: "build_date", Value: metadata.BuildDate},
+		{Name: "module", Value: metadata.Module},
+		{Name: "go_version", Value: metadata.GoVersion},
+		{Name: "timestamp", Value: metadata.Timestamp.Format(time.RFC3339)},
+		{Name: "nodes", Value: strconv.Itoa(metadata.Nodes)},
+		{Name: "edges", Value: strconv.Itoa(metadata.Edges)},
+	}
+	for _, name := range slices.Sorted(maps.Keys(metadata.Flags)) {
+		properties = append(properties, JUnitProperty{Name: "flag." + name, Value: metadata.Flags[name]})
+	}
+	return properties
+}
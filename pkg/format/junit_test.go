@@ -0,0 +1,85 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestJUnitWriter_Write(t *testing.T) {
+	g := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"a::F": {ID: "a::F", Name: "F", Kind: graph.KindFunction, Package: "a"},
+			"a::G": {ID: "a::G", Name: "G", Kind: graph.KindFunction, Package: "a"},
+		},
+		Edges: map[string][]string{
+			"a::F": {"a::G"},
+			"a::G": {"a::F"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&JUnitWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Error("Output should start with an XML header")
+	}
+
+	var result JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JUnit output: %v", err)
+	}
+	if result.Tests != 1 || result.Failures != 1 {
+		t.Fatalf("Expected 1 failing test, got %d tests and %d failures", result.Tests, result.Failures)
+	}
+	testCase := result.Suites[0].Cases[0]
+	if testCase.ClassName != "depmap.cycles" || testCase.Name != "a::F, a::G" || testCase.Failure == nil {
+		t.Errorf("Unexpected test case: %+v", testCase)
+	}
+}
+
+func TestJUnitWriter_Passing(t *testing.T) {
+	g := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{"a::F": {ID: "a::F", Name: "F", Kind: graph.KindFunction, Package: "a"}},
+		Edges: map[string][]string{},
+	}
+
+	var buf bytes.Buffer
+	if err := (&JUnitWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JUnit output: %v", err)
+	}
+	if result.Tests != 1 || result.Failures != 0 || result.Suites[0].Cases[0].Failure != nil {
+		t.Errorf("Expected a single passing test case, got %+v", result)
+	}
+}
+
+func TestJUnitWriter_Metadata(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Metadata = &graph.Metadata{Tool: "go-depmap", Version: "v1.2.3", Flags: map[string]string{"format": "junit"}}
+
+	var buf bytes.Buffer
+	if err := (&JUnitWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JUnit output: %v", err)
+	}
+	properties := make(map[string]string)
+	for _, property := range result.Properties {
+		properties[property.Name] = property.Value
+	}
+	if properties["version"] != "v1.2.3" || properties["flag.format"] != "junit" {
+		t.Errorf("Expected the metadata as properties, got %v", result.Properties)
+	}
+}
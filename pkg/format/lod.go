@@ -0,0 +1,174 @@
+package format
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"maps"
+	"path"
+	"slices"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/lod.html
+var lodTemplateFS embed.FS
+
+// LODWriter implements the Writer interface for a multi-resolution graph: the graph at the
+// package, type and function resolutions (see graph.DependencyGraph.Resolutions) in a single
+// artifact, whose page swaps the resolution as the user zooms
+type LODWriter struct{}
+
+// LODGraph is the complete data structure of the multi-resolution graph
+type LODGraph struct {
+	Levels   []LODLevel      `json:"levels"`             // From the coarsest to the finest
+	Metadata *graph.Metadata `json:"metadata,omitempty"` // Provenance of the graph
+}
+
+// LODLevel is the graph at one resolution
+type LODLevel struct {
+	Name  string    `json:"name"` // package, type or function
+	Nodes []LODNode `json:"nodes"`
+	Edges []LODEdge `json:"edges"`
+}
+
+// LODNode is a node of one resolution
+type LODNode struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Kind    string `json:"kind"`
+	Package string `json:"package"`
+	Parent  string `json:"parent,omitempty"`  // ID of the node standing for it in the next coarser level
+	Symbols int    `json:"symbols"`           // Number of symbols it stands for, 1 for a symbol
+	Color   string `json:"color"`             // Hue of the package, or the palette color
+	DocURL  string `json:"doc_url,omitempty"` // pkg.go.dev URL of exported symbols and packages
+
+	*graph.Position // Coordinates x and y of a precomputed layout, the center of the members for coarser levels
+}
+
+// LODEdge is an edge of one resolution
+type LODEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Weight int    `json:"weight"` // Number of edges between symbols it stands for
+}
+
+// Write generates the multi-resolution graph as JSON, or an HTML page zooming through it
+func (w *LODWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if _, err := paletteOf(config); err != nil {
+		return err
+	}
+	lodGraph := convertToLODFormat(depGraph, config)
+
+	// Check if HTML page should be generated
+	if config.GetBool("htmlPage", false) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeLODHTML(writer, lodGraph, theme)
+	}
+
+	// Otherwise output JSON
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(lodGraph)
+}
+
+// convertToLODFormat converts a DependencyGraph to its levels, with the nodes and edges
+// sorted, and a hue per package shared by the levels
+func convertToLODFormat(depGraph *graph.DependencyGraph, config Config) *LODGraph {
+	lodGraph := &LODGraph{Levels: make([]LODLevel, 0, 3), Metadata: depGraph.Metadata}
+
+	packages := make(map[string]string)
+	for _, node := range depGraph.Nodes {
+		packages[node.Package] = ""
+	}
+	for i, pkg := range slices.Sorted(maps.Keys(packages)) {
+		packages[pkg] = hslToHex(i*137, 70, 55) // Golden angle for distinct hues
+	}
+
+	// Nodes take the palette color of their package or kind; Write reports invalid palettes
+	palette, _ := paletteOf(config)
+
+	for _, resolution := range depGraph.Resolutions() {
+		g := resolution.Graph
+		level := LODLevel{Name: resolution.Name, Nodes: make([]LODNode, 0, len(g.Nodes)), Edges: make([]LODEdge, 0)}
+		for _, id := range slices.Sorted(maps.Keys(g.Nodes)) {
+			node := g.Nodes[id]
+			symbols, ok := node.Attrs["symbols"].(int)
+			if !ok {
+				symbols = 1
+			}
+			color := palette.nodeColor(node)
+			if color == "" {
+				color = packages[node.Package]
+			}
+			level.Nodes = append(level.Nodes, LODNode{
+				ID:       node.ID,
+				Label:    lodLabel(node),
+				Kind:     string(node.Kind),
+				Package:  node.Package,
+				Parent:   resolution.Parents[id],
+				Symbols:  symbols,
+				Color:    color,
+				DocURL:   node.DocURL,
+				Position: node.Position,
+			})
+
+			targets := slices.Clone(g.Edges[id])
+			slices.Sort(targets)
+			for _, targetID := range slices.Compact(targets) {
+				if _, exists := g.Nodes[targetID]; exists {
+					level.Edges = append(level.Edges, LODEdge{Source: id, Target: targetID, Weight: g.EdgeWeight(id, targetID)})
+				}
+			}
+		}
+		lodGraph.Levels = append(lodGraph.Levels, level)
+	}
+
+	return lodGraph
+}
+
+// lodLabel returns the label of a node: the last element of the import path for packages, the
+// name otherwise
+func lodLabel(node *graph.Node) string {
+	if node.Kind == graph.KindPackage {
+		return path.Base(node.Package)
+	}
+	return node.Name
+}
+
+// writeLODHTML generates a self-contained HTML page with embedded D3.js, drawing the level of
+// the zoom
+func writeLODHTML(writer io.Writer, lodGraph *LODGraph, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(lodTemplateFS, "templates/lod.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the graph data to JSON
+	jsonData, err := json.Marshal(lodGraph)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
@@ -0,0 +1,73 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestLODWriter_Write_JSON(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["a::T"] = &graph.Node{ID: "a::T", Name: "T", Kind: graph.KindStruct, Package: "a"}
+	g.Nodes["a::(*T).M"] = &graph.Node{ID: "a::(*T).M", Name: "(*T).M", Kind: graph.KindMethod, Package: "a"}
+	g.Nodes["example.com/b::F"] = &graph.Node{ID: "example.com/b::F", Name: "F", Kind: graph.KindFunction, Package: "example.com/b"}
+	g.Nodes["example.com/b::G"] = &graph.Node{ID: "example.com/b::G", Name: "G", Kind: graph.KindFunction, Package: "example.com/b"}
+	g.AddEdge("example.com/b::F", "a::(*T).M")
+	g.AddEdge("example.com/b::G", "a::T")
+	g.AddEdge("example.com/b::G", "example.com/b::F")
+
+	var buf bytes.Buffer
+	if err := (&LODWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result LODGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if len(result.Levels) != 3 || result.Levels[0].Name != graph.ResolutionPackage || result.Levels[2].Name != graph.ResolutionFunction {
+		t.Fatalf("Expected the package, type and function levels, got %+v", result.Levels)
+	}
+
+	packages := result.Levels[0]
+	if len(packages.Nodes) != 2 || packages.Nodes[1].Label != "b" || packages.Nodes[1].Symbols != 2 || packages.Nodes[1].Parent != "" {
+		t.Errorf("Unexpected package nodes: %+v", packages.Nodes)
+	}
+	if len(packages.Edges) != 1 || packages.Edges[0] != (LODEdge{Source: "example.com/b", Target: "a", Weight: 2}) {
+		t.Errorf("Expected one edge of weight 2 between the packages, got %+v", packages.Edges)
+	}
+
+	types := result.Levels[1]
+	if len(types.Nodes) != 2 || types.Nodes[0].ID != "a::T" || types.Nodes[0].Symbols != 2 || types.Nodes[0].Parent != "a" {
+		t.Errorf("Unexpected type nodes: %+v", types.Nodes)
+	}
+
+	functions := result.Levels[2]
+	if len(functions.Nodes) != 4 || len(functions.Edges) != 3 || functions.Nodes[0].Parent != "a::T" || functions.Nodes[0].Symbols != 1 {
+		t.Errorf("Unexpected function level: %+v", functions)
+	}
+	if functions.Nodes[2].Color != packages.Nodes[1].Color || functions.Nodes[2].Color == packages.Nodes[0].Color {
+		t.Errorf("Expected a color per package, shared by the levels")
+	}
+}
+
+func TestLODWriter_Write_HTML(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg::Func"] = &graph.Node{ID: "pkg::Func", Name: "Func", Kind: graph.KindFunction, Package: "pkg"}
+
+	var buf bytes.Buffer
+	if err := (&LODWriter{}).Write(&buf, g, Config{"htmlPage": true}); err != nil {
+		t.Fatalf("Write HTML failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") || !strings.Contains(output, "Levels of Detail") {
+		t.Error("Expected a levels of detail HTML page")
+	}
+	if !strings.Contains(output, `"id":"pkg::Func"`) {
+		t.Error("Expected the node embedded in the page")
+	}
+}
@@ -0,0 +1,262 @@
+package format
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// lsifVersion is the version of the LSIF specification the dump follows
+const lsifVersion = "0.6.0"
+
+// LSIFWriter writes the occurrences of the symbols (see graph.Occurrence, recorded with
+// -occurrences) as an LSIF dump, one JSON vertex or edge per line, for code navigation tools
+// such as Sourcegraph's: every range gets a result set with the definitions and references of
+// its symbol, a hover with its signature and doc comment, and a moniker with its node ID,
+// exported by the project or imported from external packages.
+//
+// Occurrence paths are relative to the module root, resolved against the "projectRoot" config
+// key, a directory or file URI, by default the -source directory or the working directory.
+// Columns are written as recorded, in bytes, which matches LSIF's UTF-16 characters on lines
+// that are ASCII up to the symbol.
+type LSIFWriter struct{}
+
+// LSIFPosition is a zero-based position in a document
+type LSIFPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// LSIFElement is a vertex or an edge of the dump; the fields used depend on the label
+type LSIFElement struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"` // "vertex" or "edge"
+	Label string `json:"label"`
+
+	// Vertices
+	Version          string        `json:"version,omitempty"`          // metaData
+	ProjectRoot      string        `json:"projectRoot,omitempty"`      // metaData
+	PositionEncoding string        `json:"positionEncoding,omitempty"` // metaData
+	ToolInfo         *LSIFToolInfo `json:"toolInfo,omitempty"`         // metaData
+	Kind             string        `json:"kind,omitempty"`             // project, moniker
+	URI              string        `json:"uri,omitempty"`              // document
+	LanguageID       string        `json:"languageId,omitempty"`       // document
+	Start            *LSIFPosition `json:"start,omitempty"`            // range
+	End              *LSIFPosition `json:"end,omitempty"`              // range
+	Result           *LSIFHover    `json:"result,omitempty"`           // hoverResult
+	Scheme           string        `json:"scheme,omitempty"`           // moniker
+	Identifier       string        `json:"identifier,omitempty"`       // moniker
+
+	// Edges
+	OutV     int    `json:"outV,omitempty"`
+	InV      int    `json:"inV,omitempty"`
+	InVs     []int  `json:"inVs,omitempty"`
+	Document int    `json:"document,omitempty"` // item
+	Property string `json:"property,omitempty"` // item of a referenceResult: definitions or references
+}
+
+// LSIFToolInfo names the tool that produced the dump
+type LSIFToolInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// LSIFHover is the content of a hoverResult: the signature, then the doc comment
+type LSIFHover struct {
+	Contents []LSIFMarkedString `json:"contents"`
+}
+
+// LSIFMarkedString is a piece of hover content, source code when it has a language
+type LSIFMarkedString struct {
+	Language string `json:"language,omitempty"`
+	Value    string `json:"value"`
+}
+
+// lsifDump assigns the IDs of the elements as they are written
+type lsifDump struct {
+	enc    *json.Encoder
+	nextID int
+	err    error
+}
+
+// emit writes an element with the next ID and returns the ID
+func (d *lsifDump) emit(element LSIFElement) int {
+	d.nextID++
+	element.ID = d.nextID
+	if d.err == nil {
+		d.err = d.enc.Encode(element)
+	}
+	return element.ID
+}
+
+func (d *lsifDump) vertex(element LSIFElement) int {
+	element.Type = "vertex"
+	return d.emit(element)
+}
+
+func (d *lsifDump) edge(label string, outV int, inVs ...int) {
+	element := LSIFElement{Type: "edge", Label: label, OutV: outV}
+	if len(inVs) == 1 && label != "contains" && label != "item" {
+		element.InV = inVs[0]
+	} else {
+		element.InVs = inVs
+	}
+	d.emit(element)
+}
+
+// lsifSymbol holds the vertices of a symbol
+type lsifSymbol struct {
+	resultSet, definitions, references int
+	definitionRanges                   map[int][]int // Document ID -> ranges
+	referenceRanges                    map[int][]int
+}
+
+// Write renders the LSIF dump
+func (w *LSIFWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if len(depGraph.Occurrences) == 0 {
+		return errors.New("the lsif format needs the occurrences of the symbols, recorded with -occurrences")
+	}
+	root, err := lsifProjectRoot(depGraph, config)
+	if err != nil {
+		return err
+	}
+
+	dump := &lsifDump{enc: json.NewEncoder(writer)}
+	toolInfo := &LSIFToolInfo{Name: "go-depmap"}
+	if depGraph.Metadata != nil {
+		toolInfo.Version = depGraph.Metadata.Version
+	}
+	dump.vertex(LSIFElement{Label: "metaData", Version: lsifVersion, ProjectRoot: root, PositionEncoding: "utf-16", ToolInfo: toolInfo})
+	project := dump.vertex(LSIFElement{Label: "project", Kind: "go"})
+
+	// A result set per symbol, with its hover, moniker and result vertices
+	symbols := make(map[string]*lsifSymbol)
+	for _, occurrence := range depGraph.Occurrences {
+		symbols[occurrence.Symbol] = &lsifSymbol{definitionRanges: make(map[int][]int), referenceRanges: make(map[int][]int)}
+	}
+	for _, symbolID := range slices.Sorted(maps.Keys(symbols)) {
+		symbol := symbols[symbolID]
+		symbol.resultSet = dump.vertex(LSIFElement{Label: "resultSet"})
+		if node := depGraph.Nodes[symbolID]; node != nil {
+			hover := &LSIFHover{Contents: []LSIFMarkedString{{Language: "go", Value: lsifHoverSignature(node)}}}
+			if node.Doc != "" {
+				hover.Contents = append(hover.Contents, LSIFMarkedString{Value: strings.TrimSpace(node.Doc)})
+			}
+			dump.edge("textDocument/hover", symbol.resultSet, dump.vertex(LSIFElement{Label: "hoverResult", Result: hover}))
+			kind := "export"
+			if node.External {
+				kind = "import"
+			}
+			dump.edge("moniker", symbol.resultSet, dump.vertex(LSIFElement{Label: "moniker", Kind: kind, Scheme: "go-depmap", Identifier: symbolID}))
+		}
+		symbol.definitions = dump.vertex(LSIFElement{Label: "definitionResult"})
+		dump.edge("textDocument/definition", symbol.resultSet, symbol.definitions)
+		symbol.references = dump.vertex(LSIFElement{Label: "referenceResult"})
+		dump.edge("textDocument/references", symbol.resultSet, symbol.references)
+	}
+
+	// Documents and their ranges, in the order of the occurrences, sorted by file (see
+	// graph.SortOccurrences)
+	var documents []int
+	occurrences := depGraph.Occurrences
+	for start := 0; start < len(occurrences); {
+		end := start
+		for end < len(occurrences) && occurrences[end].Package == occurrences[start].Package && occurrences[end].Path == occurrences[start].Path {
+			end++
+		}
+		document := dump.vertex(LSIFElement{Label: "document", URI: root + "/" + lsifEscapePath(occurrences[start].Path), LanguageID: "go"})
+		documents = append(documents, document)
+		ranges := make([]int, 0, end-start)
+		for _, occurrence := range occurrences[start:end] {
+			symbol := symbols[occurrence.Symbol]
+			rangeID := dump.vertex(LSIFElement{
+				Label: "range",
+				Start: &LSIFPosition{Line: occurrence.Line - 1, Character: occurrence.Column - 1},
+				End:   &LSIFPosition{Line: occurrence.Line - 1, Character: occurrence.EndColumn - 1},
+			})
+			dump.edge("next", rangeID, symbol.resultSet)
+			ranges = append(ranges, rangeID)
+			if occurrence.Definition {
+				symbol.definitionRanges[document] = append(symbol.definitionRanges[document], rangeID)
+			} else {
+				symbol.referenceRanges[document] = append(symbol.referenceRanges[document], rangeID)
+			}
+		}
+		dump.edge("contains", document, ranges...)
+		start = end
+	}
+	dump.edge("contains", project, documents...)
+
+	// The ranges of the results, per document
+	for _, symbolID := range slices.Sorted(maps.Keys(symbols)) {
+		symbol := symbols[symbolID]
+		for _, document := range slices.Sorted(maps.Keys(symbol.definitionRanges)) {
+			dump.emit(LSIFElement{Type: "edge", Label: "item", OutV: symbol.definitions, InVs: symbol.definitionRanges[document], Document: document})
+			dump.emit(LSIFElement{Type: "edge", Label: "item", OutV: symbol.references, InVs: symbol.definitionRanges[document], Document: document, Property: "definitions"})
+		}
+		for _, document := range slices.Sorted(maps.Keys(symbol.referenceRanges)) {
+			dump.emit(LSIFElement{Type: "edge", Label: "item", OutV: symbol.references, InVs: symbol.referenceRanges[document], Document: document, Property: "references"})
+		}
+	}
+	return dump.err
+}
+
+// lsifProjectRoot returns the file URI of the project root, without a trailing slash
+func lsifProjectRoot(depGraph *graph.DependencyGraph, config Config) (string, error) {
+	root := config.GetString("projectRoot", "")
+	if strings.HasPrefix(root, "file://") {
+		return strings.TrimSuffix(root, "/"), nil
+	}
+	if root == "" && depGraph.Metadata != nil {
+		root = depGraph.Metadata.Flags["source"]
+	}
+	if root == "" {
+		root = "."
+	}
+	dir, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix((&url.URL{Scheme: "file", Path: filepath.ToSlash(dir)}).String(), "/"), nil
+}
+
+// lsifEscapePath escapes the elements of a slash-separated relative path for a URI
+func lsifEscapePath(relPath string) string {
+	elements := strings.Split(path.Clean(relPath), "/")
+	for i, element := range elements {
+		elements[i] = url.PathEscape(element)
+	}
+	return strings.Join(elements, "/")
+}
+
+// lsifHoverSignature returns the Go source shown on hover, e.g. func Open(name string) error
+// or type Conn struct
+func lsifHoverSignature(node *graph.Node) string {
+	switch node.Kind {
+	case graph.KindStruct, graph.KindInterface:
+		return "type " + node.Name + " " + string(node.Kind)
+	case graph.KindAlias:
+		return "type " + node.Name + " = " + node.Signature
+	case graph.KindVar:
+		return "var " + node.Name + " " + node.Signature
+	}
+	if node.Kind.IsType() {
+		return "type " + node.Name
+	}
+	if node.Kind.IsCallable() {
+		return "func " + node.Name + strings.TrimPrefix(node.Signature, "func")
+	}
+	return node.Signature
+}
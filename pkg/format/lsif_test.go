@@ -0,0 +1,84 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestLSIFWriter_Write(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["example.com/app/db::Open"] = &graph.Node{ID: "example.com/app/db::Open", Name: "Open", Kind: graph.KindFunction, Signature: "func() error", Doc: "Open connects.\n"}
+	g.Nodes["example.com/app/web::Serve"] = &graph.Node{ID: "example.com/app/web::Serve", Name: "Serve", Kind: graph.KindFunction, Signature: "func()"}
+	g.Occurrences = []graph.Occurrence{
+		{Symbol: "example.com/app/db::Open", Package: "example.com/app/db", Path: "db/db.go", Line: 3, Column: 6, EndColumn: 10, Definition: true},
+		{Symbol: "example.com/app/web::Serve", Package: "example.com/app/web", Path: "web/web.go", Line: 5, Column: 6, EndColumn: 11, Definition: true},
+		{Symbol: "example.com/app/db::Open", Package: "example.com/app/web", Path: "web/web.go", Line: 5, Column: 19, EndColumn: 23},
+	}
+
+	var buf bytes.Buffer
+	if err := (&LSIFWriter{}).Write(&buf, g, Config{"projectRoot": "file:///src/app/"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	elements := make(map[int]LSIFElement)
+	byLabel := make(map[string][]LSIFElement) // Vertices and item edges by label
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var element LSIFElement
+		if err := json.Unmarshal(scanner.Bytes(), &element); err != nil {
+			t.Fatalf("Invalid line %s: %v", scanner.Text(), err)
+		}
+		if _, exists := elements[element.ID]; exists {
+			t.Fatalf("Duplicate ID %d", element.ID)
+		}
+		// Edges only refer to the elements written before them
+		for _, id := range append([]int{element.OutV, element.InV, element.Document}, element.InVs...) {
+			if _, exists := elements[id]; id != 0 && !exists {
+				t.Fatalf("Edge %d refers to %d before it is written", element.ID, id)
+			}
+		}
+		elements[element.ID] = element
+		if element.Type == "vertex" || element.Label == "item" {
+			byLabel[element.Label] = append(byLabel[element.Label], element)
+		}
+	}
+
+	if meta := byLabel["metaData"]; len(meta) != 1 || meta[0].ProjectRoot != "file:///src/app" {
+		t.Errorf("Unexpected metaData: %+v", meta)
+	}
+	documents := byLabel["document"]
+	if len(documents) != 2 || documents[0].URI != "file:///src/app/db/db.go" || documents[1].URI != "file:///src/app/web/web.go" {
+		t.Errorf("Unexpected documents: %+v", documents)
+	}
+	ranges := byLabel["range"]
+	if len(ranges) != 3 || *ranges[2].Start != (LSIFPosition{Line: 4, Character: 18}) || *ranges[2].End != (LSIFPosition{Line: 4, Character: 22}) {
+		t.Errorf("Unexpected ranges: %+v", ranges)
+	}
+	if len(byLabel["resultSet"]) != 2 || len(byLabel["moniker"]) != 2 || len(byLabel["hoverResult"]) != 2 {
+		t.Errorf("Expected a result set, moniker and hover per symbol, got %d, %d and %d",
+			len(byLabel["resultSet"]), len(byLabel["moniker"]), len(byLabel["hoverResult"]))
+	}
+	hover := byLabel["hoverResult"][0].Result.Contents
+	if len(hover) != 2 || hover[0].Value != "func Open() error" || hover[1].Value != "Open connects." {
+		t.Errorf("Unexpected hover of Open: %+v", hover)
+	}
+
+	// The use of Open in web.go is a reference of the result set of Open's definition
+	var references []LSIFElement
+	for _, item := range byLabel["item"] {
+		if item.Property == "references" {
+			references = append(references, item)
+		}
+	}
+	if len(references) != 1 || len(references[0].InVs) != 1 || references[0].InVs[0] != ranges[2].ID || references[0].Document != documents[1].ID {
+		t.Errorf("Expected the use in web.go as the only reference, got %+v", references)
+	}
+
+	if err := (&LSIFWriter{}).Write(&buf, graph.NewDependencyGraph(), Config{}); err == nil {
+		t.Error("Expected an error without occurrences")
+	}
+}
@@ -0,0 +1,98 @@
+package format
+
+import (
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/matrix.html
+var matrixTemplateFS embed.FS
+
+// MatrixWriter writes the package-to-package dependency counts of the graph (see
+// graph.PackageMatrix) as a CSV adjacency matrix, or with htmlPage as a heatmap page. For
+// large projects a matrix shows the coupling between packages better than a node-link
+// diagram: each row lists the packages a package depends on.
+//
+// The packages are sequenced as a design structure matrix (see DependencyMatrix.Sequence),
+// dependencies below the diagonal and cycles in blocks that the page outlines, or sorted by
+// import path with the "matrixOrder" config key set to "name".
+type MatrixWriter struct{}
+
+// MatrixData is the data embedded into the heatmap page
+type MatrixData struct {
+	*graph.DependencyMatrix
+	AboveDiagonal int             `json:"above_diagonal"`     // Dependencies on later packages
+	Metadata      *graph.Metadata `json:"metadata,omitempty"` // Provenance of the graph
+}
+
+// Write generates the CSV matrix, or the heatmap HTML page
+func (w *MatrixWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	matrix := depGraph.PackageMatrix()
+	switch order := config.GetString("matrixOrder", "sequence"); order {
+	case "sequence":
+		matrix = matrix.Sequence()
+	case "name":
+	default:
+		return fmt.Errorf("unknown matrixOrder %q, expected sequence or name", order)
+	}
+
+	if config.GetBool("htmlPage", false) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeMatrixHTML(writer, &MatrixData{DependencyMatrix: matrix, AboveDiagonal: matrix.AboveDiagonal(), Metadata: depGraph.Metadata}, theme)
+	}
+
+	// A header row of the packages, then a row per package, headed by its path
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(append([]string{""}, matrix.Packages...)); err != nil {
+		return err
+	}
+	for i, pkgPath := range matrix.Packages {
+		row := make([]string, 0, len(matrix.Packages)+1)
+		row = append(row, pkgPath)
+		for _, count := range matrix.Counts[i] {
+			row = append(row, strconv.Itoa(count))
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeMatrixHTML generates a self-contained HTML page with the heatmap of the matrix
+func writeMatrixHTML(writer io.Writer, matrix *MatrixData, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(matrixTemplateFS, "templates/matrix.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the matrix data to JSON
+	jsonData, err := json.Marshal(matrix)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
@@ -0,0 +1,82 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func newMatrixTestGraph() *graph.DependencyGraph {
+	g := graph.NewDependencyGraph()
+	g.Nodes["app/web::Serve"] = &graph.Node{ID: "app/web::Serve", Name: "Serve", Kind: graph.KindFunction, Package: "app/web"}
+	g.Nodes["app/db::Open"] = &graph.Node{ID: "app/db::Open", Name: "Open", Kind: graph.KindFunction, Package: "app/db"}
+	g.Nodes["app/db::Conn"] = &graph.Node{ID: "app/db::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "app/db"}
+	g.AddEdge("app/web::Serve", "app/db::Open")
+	g.AddEdge("app/web::Serve", "app/db::Conn")
+	g.AddEdge("app/db::Open", "app/db::Conn")
+	return g
+}
+
+func TestMatrixWriter_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&MatrixWriter{}).Write(&buf, newMatrixTestGraph(), Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	want := [][]string{
+		{"", "app/db", "app/web"},
+		{"app/db", "1", "0"},
+		{"app/web", "2", "0"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Expected %v, got %v", want, records)
+	}
+}
+
+func TestMatrixWriter_HTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&MatrixWriter{}).Write(&buf, newMatrixTestGraph(), Config{"htmlPage": true}); err != nil {
+		t.Fatalf("Write HTML failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") {
+		t.Error("Output should contain HTML doctype")
+	}
+	if !strings.Contains(output, `"packages":["app/db","app/web"]`) || !strings.Contains(output, `"counts":[[1,0],[2,0]]`) {
+		t.Error("Output should embed the matrix data")
+	}
+}
+
+func TestMatrixWriter_Order(t *testing.T) {
+	g := newMatrixTestGraph()
+	g.Nodes["app/auth::Check"] = &graph.Node{ID: "app/auth::Check", Name: "Check", Kind: graph.KindFunction, Package: "app/auth"}
+	g.AddEdge("app/auth::Check", "app/web::Serve")
+
+	for order, want := range map[string][]string{
+		"sequence": {"", "app/db", "app/web", "app/auth"},
+		"name":     {"", "app/auth", "app/db", "app/web"},
+	} {
+		var buf bytes.Buffer
+		if err := (&MatrixWriter{}).Write(&buf, g, Config{"matrixOrder": order}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		header, err := csv.NewReader(&buf).Read()
+		if err != nil {
+			t.Fatalf("Failed to parse output: %v", err)
+		}
+		if !reflect.DeepEqual(header, want) {
+			t.Errorf("Expected the %s order %v, got %v", order, want, header)
+		}
+	}
+
+	if err := (&MatrixWriter{}).Write(&bytes.Buffer{}, g, Config{"matrixOrder": "random"}); err == nil {
+		t.Error("Expected an error for an unknown order")
+	}
+}
@@ -0,0 +1,121 @@
+package format
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"go-depmap/pkg/graph"
+)
+
+// heatColors are the stops of the gradient of the colorBy config key, from the lowest value
+// of the metric to the highest (ColorBrewer's RdYlBu, reversed)
+var heatColors = []string{"#313695", "#4575b4", "#74add1", "#abd9e9", "#fee090", "#fdae61", "#f46d43", "#d73027"}
+
+// noMetricColor colors the nodes without a value of the colorBy metric
+const noMetricColor = "#555555"
+
+// Range of the node sizes of the sizeBy config key, as factors of the default size of a node
+const (
+	minMetricSize = 0.5
+	maxMetricSize = 3.0
+)
+
+// MetricScale describes the node metric a page colors or sizes the nodes by, for its legend
+type MetricScale struct {
+	Metric string   `json:"metric"`           // Name of the metric, as set in the config
+	Min    float64  `json:"min"`              // Lowest value of the metric
+	Max    float64  `json:"max"`              // Highest value of the metric
+	Colors []string `json:"colors,omitempty"` // Stops of the color gradient, from Min to Max
+}
+
+// nodeMetric reads the node metric named by a config key, such as colorBy, and returns its
+// values by node ID with their range (see graph.NodeMetric), or nil without the key
+func nodeMetric(depGraph *graph.DependencyGraph, config Config, key string) (map[string]float64, *MetricScale, error) {
+	name := config.GetString(key, "")
+	if name == "" {
+		return nil, nil, nil
+	}
+	values, err := depGraph.NodeMetric(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	scale := &MetricScale{Metric: name}
+	first := true
+	for _, value := range values {
+		if first || value < scale.Min {
+			scale.Min = value
+		}
+		if first || value > scale.Max {
+			scale.Max = value
+		}
+		first = false
+	}
+	return values, scale, nil
+}
+
+// nodeHeatColors returns the color of every node with a value of the colorBy metric, on the
+// heatColors gradient, and the scale for the legend, or nil without colorBy
+func nodeHeatColors(depGraph *graph.DependencyGraph, config Config) (map[string]string, *MetricScale, error) {
+	values, scale, err := nodeMetric(depGraph, config, "colorBy")
+	if values == nil {
+		return nil, nil, err
+	}
+	scale.Colors = heatColors
+	colors := make(map[string]string, len(values))
+	for nodeID, value := range values {
+		colors[nodeID] = scale.heatColor(value)
+	}
+	return colors, scale, nil
+}
+
+// nodeMetricSizes returns the size of every node with a value of the sizeBy metric, as a
+// factor of the default size of the node, and the scale for the legend, or nil without sizeBy
+func nodeMetricSizes(depGraph *graph.DependencyGraph, config Config) (map[string]float64, *MetricScale, error) {
+	values, scale, err := nodeMetric(depGraph, config, "sizeBy")
+	if values == nil {
+		return nil, nil, err
+	}
+	sizes := make(map[string]float64, len(values))
+	for nodeID, value := range values {
+		sizes[nodeID] = scale.size(value)
+	}
+	return sizes, scale, nil
+}
+
+// size returns the size factor of a value, between minMetricSize and maxMetricSize. The area
+// of the node, rather than its size, grows with the value.
+func (s *MetricScale) size(value float64) float64 {
+	t := 0.0
+	if s.Max > s.Min {
+		t = (value - s.Min) / (s.Max - s.Min)
+	}
+	return minMetricSize + (maxMetricSize-minMetricSize)*math.Sqrt(t)
+}
+
+// heatColor interpolates the color of a value between the stops of the gradient
+func (s *MetricScale) heatColor(value float64) string {
+	t := 0.0
+	if s.Max > s.Min {
+		t = (value - s.Min) / (s.Max - s.Min)
+	}
+	position := t * float64(len(heatColors)-1)
+	i := min(int(position), len(heatColors)-2)
+	low, high := parseHexColor(heatColors[i]), parseHexColor(heatColors[i+1])
+	f := position - float64(i)
+	var rgb [3]int
+	for c := range rgb {
+		rgb[c] = int(float64(low[c]) + (float64(high[c])-float64(low[c]))*f + 0.5)
+	}
+	return rgbToHex(rgb[0], rgb[1], rgb[2])
+}
+
+// parseHexColor parses a #rrggbb color
+func parseHexColor(color string) [3]int {
+	var rgb [3]int
+	for c := range rgb {
+		value, _ := strconv.ParseUint(color[1+2*c:3+2*c], 16, 8)
+		rgb[c] = int(value)
+	}
+	return rgb
+}
@@ -0,0 +1,200 @@
+package format
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+// newMetricTestGraph returns a hub with a fan-in of 2 and two callers, one of them with coverage
+func newMetricTestGraph() *graph.DependencyGraph {
+	g := graph.NewDependencyGraph()
+	for _, name := range []string{"Hub", "A", "B"} {
+		g.Nodes["p::"+name] = &graph.Node{ID: "p::" + name, Name: name, Kind: graph.KindFunction, Package: "p"}
+	}
+	g.AddEdge("p::A", "p::Hub")
+	g.AddEdge("p::B", "p::Hub")
+	g.Nodes["p::A"].SetAttr("coverage", 0.8)
+	return g
+}
+
+func TestNodeHeatColors(t *testing.T) {
+	g := newMetricTestGraph()
+
+	colors, scale, err := nodeHeatColors(g, Config{"colorBy": "fanin"})
+	if err != nil {
+		t.Fatalf("nodeHeatColors failed: %v", err)
+	}
+	if scale.Metric != "fanin" || scale.Min != 0 || scale.Max != 2 || len(scale.Colors) != len(heatColors) {
+		t.Errorf("Expected the fanin scale from 0 to 2, got %+v", scale)
+	}
+	if colors["p::Hub"] != heatColors[len(heatColors)-1] || colors["p::A"] != heatColors[0] {
+		t.Errorf("Expected the ends of the gradient on the hub and the callers, got %v", colors)
+	}
+
+	// Custom attributes only color the nodes having them
+	colors, _, err = nodeHeatColors(g, Config{"colorBy": "coverage"})
+	if err != nil {
+		t.Fatalf("nodeHeatColors failed: %v", err)
+	}
+	if len(colors) != 1 || colors["p::A"] == "" {
+		t.Errorf("Expected only p::A colored by coverage, got %v", colors)
+	}
+
+	if _, _, err := nodeHeatColors(g, Config{"colorBy": "package"}); err == nil {
+		t.Error("Expected an error for a field that is not a number")
+	}
+	if colors, scale, err := nodeHeatColors(g, Config{}); colors != nil || scale != nil || err != nil {
+		t.Errorf("Expected nothing without colorBy, got %v, %v, %v", colors, scale, err)
+	}
+}
+
+func TestMetricScale_HeatColor(t *testing.T) {
+	scale := &MetricScale{Min: 0, Max: 7}
+	for i, want := range heatColors {
+		if got := scale.heatColor(float64(i)); got != want {
+			t.Errorf("Expected stop %d to be %s, got %s", i, want, got)
+		}
+	}
+	// Halfway between #313695 and #4575b4
+	if got := scale.heatColor(0.5); got != "#3b56a5" {
+		t.Errorf("Expected an interpolated color, got %s", got)
+	}
+	// A constant metric takes the low end
+	if got := (&MetricScale{Min: 3, Max: 3}).heatColor(3); got != heatColors[0] {
+		t.Errorf("Expected %s for a constant metric, got %s", heatColors[0], got)
+	}
+}
+
+func TestWriters_ColorBy(t *testing.T) {
+	g := newMetricTestGraph()
+	config := Config{"colorBy": "coverage", "pretty": false}
+
+	var buf bytes.Buffer
+	if err := (&D3JSWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("D3JS Write failed: %v", err)
+	}
+	var d3Graph D3JSGraph
+	if err := json.Unmarshal(buf.Bytes(), &d3Graph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if d3Graph.ColorBy == nil || d3Graph.ColorBy.Metric != "coverage" {
+		t.Errorf("Expected the coverage scale, got %+v", d3Graph.ColorBy)
+	}
+	for _, node := range d3Graph.Nodes {
+		if want := cmp.Or(map[string]string{"p::A": heatColors[0]}[node.ID], noMetricColor); node.Color != want {
+			t.Errorf("Expected color %q for %s, got %q", want, node.ID, node.Color)
+		}
+	}
+
+	buf.Reset()
+	if err := (&CosmoWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("Cosmo Write failed: %v", err)
+	}
+	var cosmoGraph CosmoGraph
+	if err := json.Unmarshal(buf.Bytes(), &cosmoGraph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range cosmoGraph.Nodes {
+		if node.ID == "p::B" && node.Color != noMetricColor {
+			t.Errorf("Expected %s without coverage in %s, got %s", node.ID, noMetricColor, node.Color)
+		}
+	}
+
+	buf.Reset()
+	if err := (&AntVG6Writer{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("AntV G6 Write failed: %v", err)
+	}
+	var antGraph AntVG6Graph
+	if err := json.Unmarshal(buf.Bytes(), &antGraph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range antGraph.Nodes {
+		if node.ID == "p::A" && node.Style["fill"] != heatColors[0] {
+			t.Errorf("Expected %s filled with %s, got %v", node.ID, heatColors[0], node.Style)
+		}
+	}
+
+	if err := (&D3JSWriter{}).Write(&buf, g, Config{"colorBy": "name"}); err == nil {
+		t.Error("Expected an error for a field that is not a number")
+	}
+}
+
+func TestNodeMetricSizes(t *testing.T) {
+	g := newMetricTestGraph()
+
+	sizes, scale, err := nodeMetricSizes(g, Config{"sizeBy": "fanin"})
+	if err != nil {
+		t.Fatalf("nodeMetricSizes failed: %v", err)
+	}
+	if scale.Metric != "fanin" || scale.Colors != nil {
+		t.Errorf("Expected the fanin scale without colors, got %+v", scale)
+	}
+	if sizes["p::Hub"] != maxMetricSize || sizes["p::A"] != minMetricSize {
+		t.Errorf("Expected the ends of the size range on the hub and the callers, got %v", sizes)
+	}
+	// The area grows with the value: a quarter of the range is half of the size range
+	if got := (&MetricScale{Min: 0, Max: 4}).size(1); got != (minMetricSize+maxMetricSize)/2 {
+		t.Errorf("Expected the middle size for a quarter of the range, got %v", got)
+	}
+
+	if _, _, err := nodeMetricSizes(g, Config{"sizeBy": "kind"}); err == nil {
+		t.Error("Expected an error for a field that is not a number")
+	}
+}
+
+func TestWriters_SizeBy(t *testing.T) {
+	g := newMetricTestGraph()
+	config := Config{"sizeBy": "fanin", "pretty": false}
+
+	var buf bytes.Buffer
+	if err := (&D3JSWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("D3JS Write failed: %v", err)
+	}
+	var d3Graph D3JSGraph
+	if err := json.Unmarshal(buf.Bytes(), &d3Graph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if d3Graph.SizeBy == nil || d3Graph.SizeBy.Max != 2 {
+		t.Errorf("Expected the fanin scale, got %+v", d3Graph.SizeBy)
+	}
+	for _, node := range d3Graph.Nodes {
+		if want := cmp.Or(map[string]float64{"p::Hub": maxMetricSize}[node.ID], minMetricSize); node.Size != want {
+			t.Errorf("Expected size %v for %s, got %v", want, node.ID, node.Size)
+		}
+	}
+
+	buf.Reset()
+	if err := (&CosmoWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("Cosmo Write failed: %v", err)
+	}
+	var cosmoGraph CosmoGraph
+	if err := json.Unmarshal(buf.Bytes(), &cosmoGraph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range cosmoGraph.Nodes {
+		if node.ID == "p::Hub" && node.Size != cosmoNodeSize*maxMetricSize {
+			t.Errorf("Expected %s sized %v, got %v", node.ID, cosmoNodeSize*maxMetricSize, node.Size)
+		}
+		if node.Type == "package" && node.Size != 15 {
+			t.Errorf("Expected the package hub to keep its size, got %v", node.Size)
+		}
+	}
+
+	buf.Reset()
+	if err := (&AntVG6Writer{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("AntV G6 Write failed: %v", err)
+	}
+	var antGraph AntVG6Graph
+	if err := json.Unmarshal(buf.Bytes(), &antGraph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range antGraph.Nodes {
+		if node.ID == "p::A" && node.Size != antvg6NodeSize*minMetricSize {
+			t.Errorf("Expected %s sized %v, got %v", node.ID, antvg6NodeSize*minMetricSize, node.Size)
+		}
+	}
+}
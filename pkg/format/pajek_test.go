@@ -0,0 +1,34 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestPajekWriter_Write(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Metadata = &graph.Metadata{Tool: "go-depmap", Module: "example.com/app"}
+	g.Nodes["pkg::Serve"] = &graph.Node{ID: "pkg::Serve", Name: "Serve", Kind: graph.KindFunction, Package: "pkg"}
+	g.Nodes["pkg::Conn"] = &graph.Node{ID: "pkg::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "pkg"}
+	g.Nodes["pkg::Open"] = &graph.Node{ID: "pkg::Open", Name: "Open", Kind: graph.KindFunction, Package: "pkg"}
+	g.AddEdge("pkg::Serve", "pkg::Conn", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("pkg::Serve", "pkg::Open")
+	g.AddEdge("pkg::Serve", "pkg::missing")
+	g.SetEdgeAttr("pkg::Serve", "pkg::Open", graph.WeightAttr, 3)
+
+	var buf bytes.Buffer
+	if err := (&PajekWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	comment, network, found := strings.Cut(buf.String(), "\n")
+	if !found || !strings.HasPrefix(comment, "% Generated by go-depmap") {
+		t.Errorf("Expected the provenance as a leading comment, got %q", comment)
+	}
+	want := "*Vertices 3\n1 \"pkg::Conn\"\n2 \"pkg::Open\"\n3 \"pkg::Serve\"\n*Arcs\n3 1 1\n3 2 3\n"
+	if network != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, network)
+	}
+}
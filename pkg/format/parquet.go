@@ -0,0 +1,169 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/parquet-go/parquet-go"
+
+	"go-depmap/pkg/graph"
+)
+
+// ParquetWriter writes a table of the graph as a zstd-compressed Parquet file, for DuckDB,
+// Spark or pandas: the nodes, or the edges with the "parquetTable" config key set to "edges"
+// (a Parquet file holds one table, so the two tables are written by two runs). Every row
+// carries the module and start of the analysis, so that the files of many runs can be queried
+// together, e.g. read_parquet('runs/*-nodes.parquet') in DuckDB; the whole provenance is in
+// the file's key-value metadata under "go-depmap.metadata".
+type ParquetWriter struct{}
+
+// ParquetNode is a row of the nodes table
+type ParquetNode struct {
+	RunTimestamp int64   `parquet:"run_timestamp,optional,timestamp(millisecond:utc)"` // Start of the analysis in Unix milliseconds, null (0) when unknown
+	Module       string  `parquet:"module"`
+	ID           string  `parquet:"id"`
+	Name         string  `parquet:"name"`
+	Kind         string  `parquet:"kind"`
+	Package      string  `parquet:"package"`
+	File         string  `parquet:"file"`
+	Path         string  `parquet:"path"`
+	Line         int64   `parquet:"line"`
+	Signature    string  `parquet:"signature"`
+	External     bool    `parquet:"external"`
+	Lines        int64   `parquet:"lines"`
+	Complexity   int64   `parquet:"complexity"`
+	Churn        int64   `parquet:"churn"`
+	Owner        string  `parquet:"owner"`
+	Deprecated   string  `parquet:"deprecated"`
+	SubgraphID   int64   `parquet:"subgraph_id"`
+	FanIn        int64   `parquet:"fan_in"`
+	FanOut       int64   `parquet:"fan_out"`
+	Attrs        *string `parquet:"attrs,optional"` // Custom attributes as a JSON object
+}
+
+// ParquetEdge is a row of the edges table
+type ParquetEdge struct {
+	RunTimestamp  int64    `parquet:"run_timestamp,optional,timestamp(millisecond:utc)"`
+	Module        string   `parquet:"module"`
+	Source        string   `parquet:"source"`
+	Target        string   `parquet:"target"`
+	SourcePackage string   `parquet:"source_package"`
+	TargetPackage string   `parquet:"target_package"`
+	Kinds         []string `parquet:"kinds,list"`
+	Weight        int64    `parquet:"weight"` // Number of edges the edge stands for, see graph.EdgeWeight
+	Attrs         *string  `parquet:"attrs,optional"`
+}
+
+// Write generates the Parquet file of the configured table
+func (w *ParquetWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	options := []parquet.WriterOption{parquet.Compression(&parquet.Zstd)}
+	var runTimestamp int64
+	var module string
+	if depGraph.Metadata != nil {
+		metadata, err := json.Marshal(depGraph.Metadata)
+		if err != nil {
+			return err
+		}
+		options = append(options, parquet.KeyValueMetadata("go-depmap.metadata", string(metadata)))
+		if !depGraph.Metadata.Timestamp.IsZero() {
+			runTimestamp = depGraph.Metadata.Timestamp.UnixMilli()
+		}
+		module = depGraph.Metadata.Module
+	}
+
+	nodeIDs := slices.Sorted(maps.Keys(depGraph.Nodes))
+	switch table := config.GetString("parquetTable", "nodes"); table {
+	case "nodes":
+		fanIn, fanOut := depGraph.FanIn(), depGraph.FanOut()
+		rows := make([]ParquetNode, 0, len(nodeIDs))
+		for _, nodeID := range nodeIDs {
+			node := depGraph.Nodes[nodeID]
+			attrs, err := parquetAttrs(node.Attrs)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, ParquetNode{
+				RunTimestamp: runTimestamp,
+				Module:       module,
+				ID:           node.ID,
+				Name:         node.Name,
+				Kind:         string(node.Kind),
+				Package:      node.Package,
+				File:         node.File,
+				Path:         node.Path,
+				Line:         int64(node.Line),
+				Signature:    node.Signature,
+				External:     node.External,
+				Lines:        int64(node.Lines),
+				Complexity:   int64(node.Complexity),
+				Churn:        int64(node.Churn),
+				Owner:        node.Owner,
+				Deprecated:   node.Deprecated,
+				SubgraphID:   int64(node.SubgraphID),
+				FanIn:        int64(fanIn[nodeID]),
+				FanOut:       int64(fanOut[nodeID]),
+				Attrs:        attrs,
+			})
+		}
+		return writeParquetRows(writer, rows, options)
+
+	case "edges":
+		var rows []ParquetEdge
+		for _, sourceID := range nodeIDs {
+			for _, targetID := range depGraph.Edges[sourceID] {
+				target, exists := depGraph.Nodes[targetID]
+				if !exists {
+					continue
+				}
+				attrs, err := parquetAttrs(depGraph.EdgeAttrsOf(sourceID, targetID))
+				if err != nil {
+					return err
+				}
+				kinds := make([]string, 0)
+				for _, kind := range depGraph.EdgeKindsOf(sourceID, targetID) {
+					kinds = append(kinds, string(kind))
+				}
+				rows = append(rows, ParquetEdge{
+					RunTimestamp:  runTimestamp,
+					Module:        module,
+					Source:        sourceID,
+					Target:        targetID,
+					SourcePackage: depGraph.Nodes[sourceID].Package,
+					TargetPackage: target.Package,
+					Kinds:         kinds,
+					Weight:        int64(depGraph.EdgeWeight(sourceID, targetID)),
+					Attrs:         attrs,
+				})
+			}
+		}
+		return writeParquetRows(writer, rows, options)
+
+	default:
+		return fmt.Errorf("unknown parquetTable %q, expected nodes or edges", table)
+	}
+}
+
+// writeParquetRows writes the rows as a Parquet file
+func writeParquetRows[T any](writer io.Writer, rows []T, options []parquet.WriterOption) error {
+	parquetWriter := parquet.NewGenericWriter[T](writer, options...)
+	if _, err := parquetWriter.Write(rows); err != nil {
+		return err
+	}
+	return parquetWriter.Close()
+}
+
+// parquetAttrs encodes custom attributes as a JSON object, or nil without attributes
+func parquetAttrs(attrs map[string]any) (*string, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, err
+	}
+	encoded := string(data)
+	return &encoded, nil
+}
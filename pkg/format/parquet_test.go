@@ -0,0 +1,97 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"go-depmap/pkg/graph"
+)
+
+func newParquetTestGraph() *graph.DependencyGraph {
+	g := graph.NewDependencyGraph()
+	g.Metadata = &graph.Metadata{Tool: "go-depmap", Module: "example.com/app", Timestamp: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	g.Nodes["pkg::Serve"] = &graph.Node{ID: "pkg::Serve", Name: "Serve", Kind: graph.KindFunction, Package: "pkg", Line: 7, Attrs: map[string]any{"team": "web"}}
+	g.Nodes["pkg::Conn"] = &graph.Node{ID: "pkg::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "pkg"}
+	g.AddEdge("pkg::Serve", "pkg::Conn", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("pkg::Serve", "pkg::missing")
+	g.SetEdgeAttr("pkg::Serve", "pkg::Conn", graph.WeightAttr, 2)
+	return g
+}
+
+func TestParquetWriter_Nodes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&ParquetWriter{}).Write(&buf, newParquetTestGraph(), Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	rows, err := parquet.Read[ParquetNode](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read the nodes: %v", err)
+	}
+	if len(rows) != 2 || rows[0].ID != "pkg::Conn" || rows[1].ID != "pkg::Serve" {
+		t.Fatalf("Expected the nodes sorted by ID, got %+v", rows)
+	}
+	serve := rows[1]
+	if serve.Module != "example.com/app" || serve.RunTimestamp != newParquetTestGraph().Metadata.Timestamp.UnixMilli() {
+		t.Errorf("Expected the module and start of the run, got %q and %v", serve.Module, serve.RunTimestamp)
+	}
+	if serve.Kind != "function" || serve.Line != 7 || serve.FanOut != 1 || serve.Attrs == nil || *serve.Attrs != `{"team":"web"}` {
+		t.Errorf("Unexpected row of Serve: %+v", serve)
+	}
+	if rows[0].Attrs != nil || rows[0].FanIn != 1 {
+		t.Errorf("Unexpected row of Conn: %+v", rows[0])
+	}
+
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to open the file: %v", err)
+	}
+	value, ok := file.Lookup("go-depmap.metadata")
+	var metadata graph.Metadata
+	if !ok || json.Unmarshal([]byte(value), &metadata) != nil || metadata.Module != "example.com/app" {
+		t.Errorf("Expected the provenance in the key-value metadata, got %q", value)
+	}
+}
+
+func TestParquetWriter_Edges(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&ParquetWriter{}).Write(&buf, newParquetTestGraph(), Config{"parquetTable": "edges"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	rows, err := parquet.Read[ParquetEdge](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read the edges: %v", err)
+	}
+	// The dangling edge is dropped
+	if len(rows) != 1 {
+		t.Fatalf("Expected 1 edge, got %+v", rows)
+	}
+	edge := rows[0]
+	if edge.Source != "pkg::Serve" || edge.Target != "pkg::Conn" || edge.TargetPackage != "pkg" || edge.Weight != 2 ||
+		!slices.Equal(edge.Kinds, []string{"signature", "param"}) {
+		t.Errorf("Unexpected edge row: %+v", edge)
+	}
+
+	// Without metadata the start of the run is null
+	g := newParquetTestGraph()
+	g.Metadata = nil
+	buf.Reset()
+	if err := (&ParquetWriter{}).Write(&buf, g, Config{"parquetTable": "edges"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to open the file: %v", err)
+	}
+	if nulls := file.Metadata().RowGroups[0].Columns[0].MetaData.Statistics.NullCount; nulls != 1 {
+		t.Errorf("Expected a null run_timestamp, got %d nulls", nulls)
+	}
+
+	if err := (&ParquetWriter{}).Write(&buf, newParquetTestGraph(), Config{"parquetTable": "packages"}); err == nil {
+		t.Error("Expected an error for an unknown table")
+	}
+}
@@ -0,0 +1,207 @@
+package format
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"maps"
+	"math"
+	"slices"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/sigma.html
+var sigmaTemplateFS embed.FS
+
+// SigmaWriter implements the Writer interface for Sigma.js visualization, in the serialized
+// graph format of graphology, which Graph.from imports as is
+type SigmaWriter struct{}
+
+// SigmaGraph is a serialized graphology graph
+type SigmaGraph struct {
+	Attributes SigmaGraphAttributes `json:"attributes"`
+	Options    SigmaOptions         `json:"options"`
+	Nodes      []SigmaNode          `json:"nodes"`
+	Edges      []SigmaEdge          `json:"edges"`
+}
+
+// SigmaGraphAttributes are the attributes of the graph itself
+type SigmaGraphAttributes struct {
+	Metadata *graph.Metadata `json:"metadata,omitempty"` // Provenance of the graph
+}
+
+// SigmaOptions are the graphology options of the graph
+type SigmaOptions struct {
+	Type           string `json:"type"` // "directed"
+	Multi          bool   `json:"multi"`
+	AllowSelfLoops bool   `json:"allowSelfLoops"`
+}
+
+// SigmaNode is a node of a serialized graphology graph
+type SigmaNode struct {
+	Key        string              `json:"key"`
+	Attributes SigmaNodeAttributes `json:"attributes"`
+}
+
+// SigmaNodeAttributes are the attributes of a node, those Sigma.js draws and those of the page
+type SigmaNodeAttributes struct {
+	Label   string         `json:"label"`
+	Size    float64        `json:"size"`  // Radius in pixels, growing with the fan-in
+	Color   string         `json:"color"` // Hue of the group (see Config.GroupOf), or the palette color
+	Kind    string         `json:"kind"`
+	Package string         `json:"package"`
+	Group   string         `json:"group"`
+	File    string         `json:"file,omitempty"`
+	Line    int            `json:"line,omitempty"`
+	Doc     string         `json:"doc,omitempty"`     // First sentence of the doc comment
+	DocURL  string         `json:"doc_url,omitempty"` // pkg.go.dev URL of exported symbols
+	FanIn   int            `json:"fanin"`
+	FanOut  int            `json:"fanout"`
+	Attrs   map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph node
+
+	*graph.Position // Coordinates x and y of a precomputed layout, which the page keeps
+}
+
+// SigmaEdge is an edge of a serialized graphology graph
+type SigmaEdge struct {
+	Key        string              `json:"key"`
+	Source     string              `json:"source"`
+	Target     string              `json:"target"`
+	Attributes SigmaEdgeAttributes `json:"attributes"`
+}
+
+// SigmaEdgeAttributes are the attributes of an edge
+type SigmaEdgeAttributes struct {
+	Attrs map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph edge
+}
+
+// Write generates a serialized graphology graph as JSON, or a Sigma.js HTML page
+func (w *SigmaWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if _, err := paletteOf(config); err != nil {
+		return err
+	}
+	sigmaGraph := convertToSigmaFormat(depGraph, config)
+
+	// Check if HTML page should be generated
+	if config.GetBool("htmlPage", false) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeSigmaHTML(writer, sigmaGraph, theme)
+	}
+
+	// Otherwise output JSON
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(sigmaGraph)
+}
+
+// convertToSigmaFormat converts a DependencyGraph to a serialized graphology graph, with the
+// nodes sorted by key and a hue per group, in the order of the sorted groups
+func convertToSigmaFormat(depGraph *graph.DependencyGraph, config Config) *SigmaGraph {
+	sigmaGraph := &SigmaGraph{
+		Attributes: SigmaGraphAttributes{Metadata: depGraph.Metadata},
+		Options:    SigmaOptions{Type: "directed", AllowSelfLoops: true},
+		Nodes:      make([]SigmaNode, 0, len(depGraph.Nodes)),
+		Edges:      make([]SigmaEdge, 0),
+	}
+
+	groups := make(map[string]string)
+	for _, node := range depGraph.Nodes {
+		groups[config.GroupOf(node)] = ""
+	}
+	for i, group := range slices.Sorted(maps.Keys(groups)) {
+		groups[group] = hslToHex(i*137, 70, 55) // Golden angle for distinct hues
+	}
+
+	// Nodes take the palette color of their package or kind; Write reports invalid palettes
+	palette, _ := paletteOf(config)
+	fanIn := depGraph.FanIn()
+	fanOut := depGraph.FanOut()
+
+	for _, id := range slices.Sorted(maps.Keys(depGraph.Nodes)) {
+		node := depGraph.Nodes[id]
+		group := config.GroupOf(node)
+		color := palette.nodeColor(node)
+		if color == "" {
+			color = groups[group]
+		}
+		sigmaGraph.Nodes = append(sigmaGraph.Nodes, SigmaNode{
+			Key: node.ID,
+			Attributes: SigmaNodeAttributes{
+				Label:   node.Name,
+				Size:    sigmaNodeSize(fanIn[node.ID]),
+				Color:   color,
+				Kind:    string(node.Kind),
+				Package: node.Package,
+				Group:   group,
+				File:    node.File,
+				Line:    node.Line,
+				Doc:     node.Summary(),
+				DocURL:  node.DocURL,
+				FanIn:   fanIn[node.ID],
+				FanOut:  fanOut[node.ID],
+				Attrs:   node.Attrs,
+
+				Position: node.Position,
+			},
+		})
+
+		targets := slices.Clone(depGraph.Edges[id])
+		slices.Sort(targets)
+		for _, targetID := range slices.Compact(targets) {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			sigmaGraph.Edges = append(sigmaGraph.Edges, SigmaEdge{
+				Key:        id + "->" + targetID,
+				Source:     id,
+				Target:     targetID,
+				Attributes: SigmaEdgeAttributes{Attrs: depGraph.EdgeAttrsOf(id, targetID)},
+			})
+		}
+	}
+
+	return sigmaGraph
+}
+
+// sigmaNodeSize scales the node radius with the square root of its fan-in, so that the area
+// grows with it, capped to keep hubs readable
+func sigmaNodeSize(fanIn int) float64 {
+	return math.Min(2+1.5*math.Sqrt(float64(fanIn)), 15)
+}
+
+// writeSigmaHTML generates a self-contained HTML page with embedded Sigma.js and graphology
+func writeSigmaHTML(writer io.Writer, sigmaGraph *SigmaGraph, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(sigmaTemplateFS, "templates/sigma.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the graph data to JSON
+	jsonData, err := json.Marshal(sigmaGraph)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
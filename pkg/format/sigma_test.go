@@ -0,0 +1,79 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestSigmaWriter_Write_JSON(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["b::Func"] = &graph.Node{ID: "b::Func", Name: "Func", Kind: graph.KindFunction, Package: "b"}
+	g.Nodes["a::Type"] = &graph.Node{ID: "a::Type", Name: "Type", Kind: graph.KindStruct, Package: "a", Position: &graph.Position{X: 1, Y: 2}}
+	g.AddEdge("b::Func", "a::Type")
+	g.Edges["b::Func"] = append(g.Edges["b::Func"], "a::missing")
+
+	var buf bytes.Buffer
+	if err := (&SigmaWriter{}).Write(&buf, g, Config{"kindColors": map[string]any{"struct": "#123456"}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result SigmaGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	if result.Options.Type != "directed" || result.Options.Multi {
+		t.Errorf("Expected a directed simple graph, got %+v", result.Options)
+	}
+	if len(result.Nodes) != 2 || result.Nodes[0].Key != "a::Type" {
+		t.Fatalf("Expected the nodes sorted by key, got %+v", result.Nodes)
+	}
+	if len(result.Edges) != 1 || result.Edges[0].Key != "b::Func->a::Type" {
+		t.Errorf("Expected the edge to the existing node only, got %+v", result.Edges)
+	}
+
+	typeNode, funcNode := result.Nodes[0].Attributes, result.Nodes[1].Attributes
+	if typeNode.Color != "#123456" || typeNode.FanIn != 1 || typeNode.Size != sigmaNodeSize(1) {
+		t.Errorf("Expected the palette color and a fan-in of 1, got %+v", typeNode)
+	}
+	if typeNode.Position == nil || *typeNode.Position != (graph.Position{X: 1, Y: 2}) {
+		t.Errorf("Expected the precomputed position, got %v", typeNode.Position)
+	}
+	if funcNode.Color == "" || funcNode.FanOut != 1 || funcNode.Position != nil {
+		t.Errorf("Expected the group color, a fan-out of 1 and no position, got %+v", funcNode)
+	}
+}
+
+func TestSigmaNodeSize(t *testing.T) {
+	if got := sigmaNodeSize(0); got != 2 {
+		t.Errorf("sigmaNodeSize(0) = %v, want 2", got)
+	}
+	if got := sigmaNodeSize(4); got != 5 {
+		t.Errorf("sigmaNodeSize(4) = %v, want 5", got)
+	}
+	if got := sigmaNodeSize(10000); got != 15 {
+		t.Errorf("sigmaNodeSize(10000) = %v, want 15", got)
+	}
+}
+
+func TestSigmaWriter_Write_HTML(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg::Func"] = &graph.Node{ID: "pkg::Func", Name: "Func", Kind: graph.KindFunction, Package: "pkg"}
+
+	var buf bytes.Buffer
+	if err := (&SigmaWriter{}).Write(&buf, g, Config{"htmlPage": true}); err != nil {
+		t.Fatalf("Write HTML failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") || !strings.Contains(output, "sigma") {
+		t.Error("Expected a Sigma.js HTML page")
+	}
+	if !strings.Contains(output, `"key":"pkg::Func"`) {
+		t.Error("Expected the node embedded in the page")
+	}
+}
@@ -0,0 +1,260 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// StructurizrWriter writes the graph as a C4 model in the Structurizr DSL, for architecture
+// documentation pipelines. Modules become software systems and packages their containers, or
+// with the "c4Level" config key set to "component", components of one container per module;
+// the dependencies between the symbols of two packages become one relationship. Analyzed
+// modules are named by the repo attribute of merged graphs, or the module of the metadata;
+// the modules of external packages are guessed from their import paths (see externalModule).
+type StructurizrWriter struct{}
+
+// c4System is a software system of the C4 model: a module and its packages
+type c4System struct {
+	name     string
+	id       string
+	external bool
+	packages []string
+}
+
+// Write renders the Structurizr DSL workspace
+func (w *StructurizrWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	components := config.GetString("c4Level", "container") == "component"
+	systems := c4Systems(depGraph)
+	ids := newDSLIdentifiers()
+	for _, system := range systems {
+		system.id = ids.add("system", system.name)
+	}
+	elementIDs := make(map[string]string) // Package path -> identifier of its container or component
+	containerIDs := make(map[*c4System]string)
+	for _, system := range systems {
+		if components && !system.external {
+			containerIDs[system] = ids.add("module", system.name)
+		}
+		for _, pkgPath := range system.packages {
+			elementIDs[pkgPath] = ids.add("pkg", pkgPath)
+		}
+	}
+
+	name := "Go dependency map"
+	if depGraph.Metadata != nil && depGraph.Metadata.Module != "" {
+		name = depGraph.Metadata.Module
+	}
+	symbols := make(map[string]int) // Package path -> number of symbols
+	for _, node := range depGraph.Nodes {
+		if node.Kind != graph.KindPackage {
+			symbols[node.Package]++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "workspace %s %s {\n", dslString(name), dslString(structurizrDescription(depGraph)))
+	sb.WriteString("    model {\n")
+	for _, system := range systems {
+		fmt.Fprintf(&sb, "        %s = softwareSystem %s {\n", system.id, dslString(system.name))
+		if system.external {
+			sb.WriteString("            tags \"External\"\n")
+		}
+		indent := "            "
+		if containerID := containerIDs[system]; containerID != "" {
+			fmt.Fprintf(&sb, "            %s = container %s \"\" \"Go module\" {\n", containerID, dslString(system.name))
+			indent += "    "
+		}
+		for _, pkgPath := range system.packages {
+			element := "container"
+			if containerIDs[system] != "" {
+				element = "component"
+			}
+			fmt.Fprintf(&sb, "%s%s = %s %s %s \"Go package\"\n", indent, elementIDs[pkgPath], element,
+				dslString(pkgPath), dslString(c4PackageDescription(symbols[pkgPath])))
+		}
+		if containerIDs[system] != "" {
+			sb.WriteString("            }\n")
+		}
+		sb.WriteString("        }\n")
+	}
+
+	weights := packageDependencies(depGraph)
+	if len(weights) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, from := range slices.Sorted(maps.Keys(weights)) {
+		for _, to := range slices.Sorted(maps.Keys(weights[from])) {
+			fmt.Fprintf(&sb, "        %s -> %s %s\n", elementIDs[from], elementIDs[to], dslString(usesDescription(weights[from][to])))
+		}
+	}
+	sb.WriteString("    }\n\n")
+
+	sb.WriteString("    views {\n")
+	sb.WriteString("        systemLandscape \"Landscape\" {\n            include *\n            autoLayout lr\n        }\n")
+	for _, system := range systems {
+		if system.external {
+			continue
+		}
+		if containerID := containerIDs[system]; containerID != "" {
+			fmt.Fprintf(&sb, "        component %s %s {\n", containerID, dslString(system.id+"-components"))
+		} else {
+			fmt.Fprintf(&sb, "        container %s %s {\n", system.id, dslString(system.id+"-containers"))
+		}
+		sb.WriteString("            include *\n            autoLayout lr\n        }\n")
+	}
+	sb.WriteString("        styles {\n")
+	sb.WriteString("            element \"External\" {\n                background #999999\n                color #ffffff\n            }\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("    }\n}\n")
+
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+// c4Systems groups the packages of the graph into software systems, analyzed ones first, each
+// sorted by name
+func c4Systems(depGraph *graph.DependencyGraph) []*c4System {
+	project := "Project"
+	if depGraph.Metadata != nil && depGraph.Metadata.Module != "" {
+		project = depGraph.Metadata.Module
+	}
+	byName := make(map[string]*c4System)
+	systemOf := make(map[string]*c4System)
+	for _, nodeID := range slices.Sorted(maps.Keys(depGraph.Nodes)) {
+		node := depGraph.Nodes[nodeID]
+		if systemOf[node.Package] != nil {
+			continue
+		}
+		name := project
+		if repo, ok := node.Attrs[graph.RepoAttr].(string); ok {
+			name = repo
+		} else if node.External {
+			name = externalModule(node.Package)
+		}
+		key := fmt.Sprintf("%t %s", node.External, name)
+		system := byName[key]
+		if system == nil {
+			system = &c4System{name: name, external: node.External}
+			byName[key] = system
+		}
+		system.packages = append(system.packages, node.Package)
+		systemOf[node.Package] = system
+	}
+
+	systems := slices.Collect(maps.Values(byName))
+	slices.SortFunc(systems, func(a, b *c4System) int {
+		if a.external != b.external {
+			if a.external {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	for _, system := range systems {
+		slices.Sort(system.packages)
+	}
+	return systems
+}
+
+// externalModule guesses the module of an external package from its import path: the
+// standard library for paths whose first element has no dot, the first three elements on
+// hosts with owner/repository paths, and the path itself otherwise
+func externalModule(pkgPath string) string {
+	elements := strings.Split(pkgPath, "/")
+	if !strings.Contains(elements[0], ".") {
+		return "Go standard library"
+	}
+	switch elements[0] {
+	case "github.com", "gitlab.com", "bitbucket.org", "golang.org", "google.golang.org", "go.googlesource.com":
+		if len(elements) >= 3 {
+			return strings.Join(elements[:3], "/")
+		}
+	}
+	return pkgPath
+}
+
+// packageDependencies counts the edges between the symbols of different packages, by source
+// and target package
+func packageDependencies(depGraph *graph.DependencyGraph) map[string]map[string]int {
+	weights := make(map[string]map[string]int)
+	for sourceID, targets := range depGraph.Edges {
+		source := depGraph.Nodes[sourceID]
+		if source == nil {
+			continue
+		}
+		for _, targetID := range targets {
+			target := depGraph.Nodes[targetID]
+			if target == nil || target.Package == source.Package {
+				continue
+			}
+			if weights[source.Package] == nil {
+				weights[source.Package] = make(map[string]int)
+			}
+			weights[source.Package][target.Package] += depGraph.EdgeWeight(sourceID, targetID)
+		}
+	}
+	return weights
+}
+
+// c4PackageDescription describes a package by the number of its symbols, none for the
+// packages collapsed into a node of kind package
+func c4PackageDescription(count int) string {
+	switch count {
+	case 0:
+		return ""
+	case 1:
+		return "1 symbol"
+	}
+	return fmt.Sprintf("%d symbols", count)
+}
+
+// usesDescription describes a relationship standing for a number of symbol dependencies
+func usesDescription(weight int) string {
+	if weight == 1 {
+		return "Uses 1 symbol"
+	}
+	return fmt.Sprintf("Uses %d symbols", weight)
+}
+
+// structurizrDescription describes the workspace by the provenance of the graph
+func structurizrDescription(depGraph *graph.DependencyGraph) string {
+	if depGraph.Metadata != nil {
+		return "Generated by " + depGraph.Metadata.String()
+	}
+	return "Generated by go-depmap"
+}
+
+// dslString quotes a string for the Structurizr DSL
+func dslString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+}
+
+// dslIdentifiers hands out unique Structurizr DSL identifiers derived from names
+type dslIdentifiers map[string]bool
+
+func newDSLIdentifiers() dslIdentifiers {
+	return make(dslIdentifiers)
+}
+
+// add returns a new identifier for a name: the prefix and the name with the characters other
+// than letters, digits and underscores replaced, numbered if taken
+func (ids dslIdentifiers) add(prefix, name string) string {
+	base := prefix + "_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	id := base
+	for n := 2; ids[id]; n++ {
+		id = fmt.Sprintf("%s_%d", base, n)
+	}
+	ids[id] = true
+	return id
+}
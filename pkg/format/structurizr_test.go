@@ -0,0 +1,84 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestStructurizrWriter_Write(t *testing.T) {
+	g := &graph.DependencyGraph{
+		Metadata: &graph.Metadata{Tool: "go-depmap", Module: "example.com/shop"},
+		Nodes: map[string]*graph.Node{
+			"example.com/shop/api::Serve":     {ID: "example.com/shop/api::Serve", Name: "Serve", Kind: graph.KindFunction, Package: "example.com/shop/api"},
+			"example.com/shop/db::Open":       {ID: "example.com/shop/db::Open", Name: "Open", Kind: graph.KindFunction, Package: "example.com/shop/db"},
+			"example.com/shop/db::Conn":       {ID: "example.com/shop/db::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "example.com/shop/db"},
+			"github.com/lib/pq/oid::T":        {ID: "github.com/lib/pq/oid::T", Name: "T", Kind: graph.KindNamed, Package: "github.com/lib/pq/oid", External: true},
+			"github.com/lib/pq::Open":         {ID: "github.com/lib/pq::Open", Name: "Open", Kind: graph.KindFunction, Package: "github.com/lib/pq", External: true},
+			"fmt":                             {ID: "fmt", Name: "fmt", Kind: graph.KindPackage, Package: "fmt", External: true},
+			"example.com/shop/db::(*Conn).Do": {ID: "example.com/shop/db::(*Conn).Do", Name: "Do", Kind: graph.KindMethod, Package: "example.com/shop/db"},
+		},
+		Edges: map[string][]string{
+			"example.com/shop/api::Serve": {"example.com/shop/db::Open", "example.com/shop/db::Conn", "fmt"},
+			"example.com/shop/db::Open":   {"example.com/shop/db::Conn", "github.com/lib/pq::Open", "github.com/lib/pq/oid::T"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&StructurizrWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		`workspace "example.com/shop" "Generated by go-depmap`,
+		`system_example_com_shop = softwareSystem "example.com/shop" {`,
+		`pkg_example_com_shop_db = container "example.com/shop/db" "3 symbols" "Go package"`,
+		`system_github_com_lib_pq = softwareSystem "github.com/lib/pq" {`,
+		`pkg_github_com_lib_pq_oid = container "github.com/lib/pq/oid" "1 symbol" "Go package"`,
+		`system_Go_standard_library = softwareSystem "Go standard library" {`,
+		`pkg_fmt = container "fmt" "" "Go package"`,
+		`pkg_example_com_shop_api -> pkg_example_com_shop_db "Uses 2 symbols"`,
+		`pkg_example_com_shop_db -> pkg_github_com_lib_pq "Uses 1 symbol"`,
+		`container system_example_com_shop "system_example_com_shop-containers" {`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Count(output, `tags "External"`) != 2 {
+		t.Errorf("Expected the two external systems to be tagged, got:\n%s", output)
+	}
+	if strings.Contains(output, "pkg_example_com_shop_db -> pkg_example_com_shop_db") {
+		t.Errorf("Edges within a package should not become relationships")
+	}
+
+	buf.Reset()
+	if err := (&StructurizrWriter{}).Write(&buf, g, Config{"c4Level": "component"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	output = buf.String()
+	for _, want := range []string{
+		`module_example_com_shop = container "example.com/shop" "" "Go module" {`,
+		`pkg_example_com_shop_api = component "example.com/shop/api" "1 symbol" "Go package"`,
+		`component module_example_com_shop "system_example_com_shop-components" {`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Component output should contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestDSLIdentifiers(t *testing.T) {
+	ids := newDSLIdentifiers()
+	if id := ids.add("pkg", "example.com/a-b"); id != "pkg_example_com_a_b" {
+		t.Errorf("Got %s, want pkg_example_com_a_b", id)
+	}
+	if id := ids.add("pkg", "example.com/a.b"); id != "pkg_example_com_a_b_2" {
+		t.Errorf("Got %s for a colliding name, want pkg_example_com_a_b_2", id)
+	}
+	if quoted := dslString(`say "hi"`); quoted != `"say \"hi\""` {
+		t.Errorf("Got %s", quoted)
+	}
+}
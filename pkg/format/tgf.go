@@ -0,0 +1,54 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// TGFWriter writes the graph in the Trivial Graph Format: a line per node, its number and its
+// ID as the label, then "#" and a line per edge, the numbers of its nodes and its edge kinds
+// as the label. yEd and most network analysis tools read it; it carries no provenance.
+type TGFWriter struct{}
+
+// Write renders the TGF lines
+func (w *TGFWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	nodeIDs := slices.Sorted(maps.Keys(depGraph.Nodes))
+	numbers := make(map[string]int, len(nodeIDs))
+
+	var sb strings.Builder
+	for i, nodeID := range nodeIDs {
+		numbers[nodeID] = i + 1
+		fmt.Fprintf(&sb, "%d %s\n", i+1, singleLine(nodeID))
+	}
+	sb.WriteString("#\n")
+	for _, sourceID := range nodeIDs {
+		for _, targetID := range depGraph.Edges[sourceID] {
+			target, exists := numbers[targetID]
+			if !exists {
+				continue
+			}
+			fmt.Fprintf(&sb, "%d %d", numbers[sourceID], target)
+			if kinds := depGraph.EdgeKindsOf(sourceID, targetID); len(kinds) > 0 {
+				names := make([]string, len(kinds))
+				for i, kind := range kinds {
+					names[i] = string(kind)
+				}
+				sb.WriteString(" " + strings.Join(names, ","))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(writer, sb.String())
+	return err
+}
+
+// singleLine replaces the line breaks of a label, which would end its line
+func singleLine(label string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(label)
+}
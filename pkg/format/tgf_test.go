@@ -0,0 +1,29 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestTGFWriter_Write(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Metadata = &graph.Metadata{Tool: "go-depmap", Module: "example.com/app"}
+	g.Nodes["pkg::Serve"] = &graph.Node{ID: "pkg::Serve", Name: "Serve", Kind: graph.KindFunction, Package: "pkg"}
+	g.Nodes["pkg::Conn"] = &graph.Node{ID: "pkg::Conn", Name: "Conn", Kind: graph.KindStruct, Package: "pkg"}
+	g.Nodes["pkg::Open"] = &graph.Node{ID: "pkg::Open", Name: "Open", Kind: graph.KindFunction, Package: "pkg"}
+	g.AddEdge("pkg::Serve", "pkg::Conn", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("pkg::Serve", "pkg::Open")
+	g.AddEdge("pkg::Serve", "pkg::missing")
+	g.SetEdgeAttr("pkg::Serve", "pkg::Open", graph.WeightAttr, 3)
+
+	var buf bytes.Buffer
+	if err := (&TGFWriter{}).Write(&buf, g, Config{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "1 pkg::Conn\n2 pkg::Open\n3 pkg::Serve\n#\n3 1 signature,param\n3 2\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
@@ -0,0 +1,100 @@
+package format
+
+import (
+	"fmt"
+	"regexp"
+
+	"go-depmap/pkg/graph"
+)
+
+// Themes of the HTML pages, selected by the theme config key
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+)
+
+// hexColor matches the #rrggbb colors of the kindColors and packageColors config keys
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// pageTheme returns the theme of the HTML pages, dark by default, which the templates set as
+// the class of their body
+func pageTheme(config Config) (string, error) {
+	switch theme := config.GetString("theme", ThemeDark); theme {
+	case ThemeDark, ThemeLight:
+		return theme, nil
+	default:
+		return "", fmt.Errorf("invalid theme %q: expected %q or %q", theme, ThemeDark, ThemeLight)
+	}
+}
+
+// colorPalette holds the colors of the kindColors and packageColors config keys, which replace
+// the colors a format picks for the nodes of a kind or package
+type colorPalette struct {
+	kinds    map[string]string // Colors by node kind, e.g. "function"
+	packages map[string]string // Colors by package pattern, e.g. "example.com/app/..."
+}
+
+// paletteOf reads the palette from the config, or returns nil without one
+func paletteOf(config Config) (*colorPalette, error) {
+	kinds, err := paletteColors(config, "kindColors")
+	if err != nil {
+		return nil, err
+	}
+	packages, err := paletteColors(config, "packageColors")
+	if err != nil {
+		return nil, err
+	}
+	if kinds == nil && packages == nil {
+		return nil, nil
+	}
+	return &colorPalette{kinds: kinds, packages: packages}, nil
+}
+
+// paletteColors reads an object of #rrggbb colors from the config
+func paletteColors(config Config, key string) (map[string]string, error) {
+	if !config.Has(key) {
+		return nil, nil
+	}
+	raw, ok := config[key].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s: expected an object of colors", key)
+	}
+	colors := make(map[string]string, len(raw))
+	for name, value := range raw {
+		color, ok := value.(string)
+		if !ok || !hexColor.MatchString(color) {
+			return nil, fmt.Errorf("invalid %s color for %s: %v, expected #rrggbb", key, name, value)
+		}
+		colors[name] = color
+	}
+	return colors, nil
+}
+
+// kindColor returns the color of a node kind, or "" if the palette has none
+func (p *colorPalette) kindColor(kind graph.NodeKind) string {
+	if p == nil {
+		return ""
+	}
+	return p.kinds[string(kind)]
+}
+
+// packageColor returns the color of the most specific package pattern matching a package, or ""
+// if none matches
+func (p *colorPalette) packageColor(pkgPath string) string {
+	if p == nil || pkgPath == "" {
+		return ""
+	}
+	return packagePatternValue(p.packages, pkgPath)
+}
+
+// nodeColor returns the color of a node: that of its package, else of its kind, or "" if the
+// palette has neither
+func (p *colorPalette) nodeColor(node *graph.Node) string {
+	if node == nil {
+		return ""
+	}
+	if color := p.packageColor(node.Package); color != "" {
+		return color
+	}
+	return p.kindColor(node.Kind)
+}
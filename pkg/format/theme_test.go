@@ -0,0 +1,177 @@
+package format
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestPageTheme(t *testing.T) {
+	if theme, err := pageTheme(Config{}); theme != ThemeDark || err != nil {
+		t.Errorf("Expected the dark theme by default, got %q, %v", theme, err)
+	}
+	if theme, err := pageTheme(Config{"theme": "light"}); theme != ThemeLight || err != nil {
+		t.Errorf("Expected the light theme, got %q, %v", theme, err)
+	}
+	if _, err := pageTheme(Config{"theme": "solarized"}); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
+
+func TestPaletteOf(t *testing.T) {
+	palette, err := paletteOf(Config{
+		"kindColors":    map[string]any{"function": "#111111"},
+		"packageColors": map[string]any{"example.com/app/...": "#222222", "example.com/app/api": "#333333"},
+	})
+	if err != nil {
+		t.Fatalf("paletteOf failed: %v", err)
+	}
+
+	tests := []struct {
+		node *graph.Node
+		want string
+	}{
+		{&graph.Node{Kind: graph.KindFunction, Package: "example.com/app/api"}, "#333333"}, // The exact pattern beats the /... one
+		{&graph.Node{Kind: graph.KindFunction, Package: "example.com/app/db"}, "#222222"},  // The package beats the kind
+		{&graph.Node{Kind: graph.KindFunction, Package: "other.com/lib"}, "#111111"},
+		{&graph.Node{Kind: graph.KindMethod, Package: "other.com/lib"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := palette.nodeColor(tt.node); got != tt.want {
+			t.Errorf("Expected %q for %+v, got %q", tt.want, tt.node, got)
+		}
+	}
+
+	// Without a palette, nothing is colored
+	if palette, err := paletteOf(Config{}); palette != nil || err != nil {
+		t.Errorf("Expected no palette, got %v, %v", palette, err)
+	}
+	if got := (*colorPalette)(nil).nodeColor(&graph.Node{Kind: graph.KindFunction}); got != "" {
+		t.Errorf("Expected no color without a palette, got %q", got)
+	}
+
+	for _, config := range []Config{
+		{"kindColors": "#111111"},
+		{"kindColors": map[string]any{"function": "red"}},
+		{"packageColors": map[string]any{"example.com/app": "#12345"}},
+	} {
+		if _, err := paletteOf(config); err == nil {
+			t.Errorf("Expected an error for %v", config)
+		}
+	}
+}
+
+func TestWriters_Palette(t *testing.T) {
+	g := newMetricTestGraph()
+	g.Nodes["q::C"] = &graph.Node{ID: "q::C", Name: "C", Kind: graph.KindMethod, Package: "q"}
+	config := Config{
+		"kindColors":    map[string]any{"method": "#111111"},
+		"packageColors": map[string]any{"p": "#222222"},
+		"pretty":        false,
+	}
+
+	var buf bytes.Buffer
+	if err := (&D3JSWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("D3JS Write failed: %v", err)
+	}
+	var d3Graph D3JSGraph
+	if err := json.Unmarshal(buf.Bytes(), &d3Graph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range d3Graph.Nodes {
+		if want := cmp.Or(map[string]string{"q::C": "#111111"}[node.ID], "#222222"); node.Color != want {
+			t.Errorf("Expected the palette color %s of %s, got %q", want, node.ID, node.Color)
+		}
+	}
+
+	buf.Reset()
+	if err := (&CytoscapeWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("Cytoscape Write failed: %v", err)
+	}
+	var elements CytoscapeElements
+	if err := json.Unmarshal(buf.Bytes(), &elements); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range elements.Nodes {
+		if node.Data.ID == "pkg:p" && node.Data.Color != "#222222" {
+			t.Errorf("Expected the package compound in the palette color, got %q", node.Data.Color)
+		}
+	}
+
+	buf.Reset()
+	if err := (&CosmoWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("Cosmo Write failed: %v", err)
+	}
+	var cosmoGraph CosmoGraph
+	if err := json.Unmarshal(buf.Bytes(), &cosmoGraph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range cosmoGraph.Nodes {
+		if want := map[string]string{"p::A": "#222222", "q::C": "#111111"}[node.ID]; want != "" && node.Color != want {
+			t.Errorf("Expected %s in %s, got %s", node.ID, want, node.Color)
+		}
+	}
+
+	// The heat coloring replaces the palette
+	buf.Reset()
+	config["colorBy"] = "fanin"
+	if err := (&D3JSWriter{}).Write(&buf, g, config); err != nil {
+		t.Fatalf("D3JS Write failed: %v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &d3Graph); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	for _, node := range d3Graph.Nodes {
+		if node.Color == "#111111" || node.Color == "#222222" {
+			t.Errorf("Expected the heat color of %s, got the palette color %s", node.ID, node.Color)
+		}
+	}
+
+	if err := (&EChartsWriter{}).Write(&buf, g, Config{"kindColors": map[string]any{"method": "blue"}}); err == nil {
+		t.Error("Expected an error for an invalid palette color")
+	}
+}
+
+func TestWriters_Theme(t *testing.T) {
+	g := newMetricTestGraph()
+	writers := map[string]Writer{
+		"d3js":      &D3JSWriter{},
+		"cosmo":     &CosmoWriter{},
+		"antvg6":    &AntVG6Writer{},
+		"cytoscape": &CytoscapeWriter{},
+		"echarts":   &EChartsWriter{},
+		"visjs":     &VisJSWriter{},
+		"sigma":     &SigmaWriter{},
+		"deckgl":    &DeckGLWriter{},
+		"lod":       &LODWriter{},
+		"3d":        &ForceGraph3DWriter{},
+		"matrix":    &MatrixWriter{},
+		"dashboard": &DashboardWriter{},
+	}
+	for name, writer := range writers {
+		var buf bytes.Buffer
+		if err := writer.Write(&buf, g, Config{"htmlPage": true, "theme": "light"}); err != nil {
+			t.Fatalf("%s Write failed: %v", name, err)
+		}
+		if !strings.Contains(buf.String(), `<body class="light">`) {
+			t.Errorf("Expected the %s page in the light theme", name)
+		}
+
+		buf.Reset()
+		if err := writer.Write(&buf, g, Config{"htmlPage": true}); err != nil {
+			t.Fatalf("%s Write failed: %v", name, err)
+		}
+		if !strings.Contains(buf.String(), `<body class="dark">`) {
+			t.Errorf("Expected the %s page in the dark theme by default", name)
+		}
+
+		if err := writer.Write(&buf, g, Config{"htmlPage": true, "theme": "blue"}); err == nil {
+			t.Errorf("Expected an error for an unknown theme from %s", name)
+		}
+	}
+}
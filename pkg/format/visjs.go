@@ -0,0 +1,202 @@
+package format
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+//go:embed templates/visjs.html
+var visjsTemplateFS embed.FS
+
+// VisJSWriter implements the Writer interface for vis-network visualization
+type VisJSWriter struct{}
+
+// Layouts of the vis-network page, selected by the visjsLayout config key: a hierarchical
+// layout putting the dependencies below their dependents, or the force-directed physics
+const (
+	VisJSLayoutHierarchical = "hierarchical"
+	VisJSLayoutForce        = "force"
+)
+
+// VisJSNode represents a node of a vis-network DataSet
+type VisJSNode struct {
+	ID      string         `json:"id"`
+	Label   string         `json:"label"`
+	Title   string         `json:"title"` // Tooltip: kind, package and doc
+	Group   string         `json:"group"` // Package, owner or repo (see Config.GroupOf), which vis-network colors and clusters by
+	Shape   string         `json:"shape"` // "diamond" for types, "triangle" for tests, "dot" otherwise
+	Value   int            `json:"value"` // Number of incoming dependencies, which scales the node
+	Color   string         `json:"color,omitempty"`
+	Kind    string         `json:"kind"`
+	Package string         `json:"package"`
+	File    string         `json:"file,omitempty"`
+	Line    int            `json:"line,omitempty"`
+	DocURL  string         `json:"doc_url,omitempty"` // pkg.go.dev URL of exported symbols
+	Attrs   map[string]any `json:"attrs,omitempty"`   // Custom attributes of the graph node
+}
+
+// VisJSEdge represents an edge of a vis-network DataSet
+type VisJSEdge struct {
+	From  string         `json:"from"`
+	To    string         `json:"to"`
+	Attrs map[string]any `json:"attrs,omitempty"` // Custom attributes of the graph edge
+}
+
+// VisJSGraph is the complete data structure for vis-network
+type VisJSGraph struct {
+	Nodes           []VisJSNode     `json:"nodes"`
+	Edges           []VisJSEdge     `json:"edges"`
+	Layout          string          `json:"layout"`                     // Layout the page starts with
+	Direction       string          `json:"direction"`                  // Direction of the hierarchical layout: UD, DU, LR or RL
+	ClusterPackages bool            `json:"cluster_packages,omitempty"` // The page starts with every group clustered
+	Metadata        *graph.Metadata `json:"metadata,omitempty"`         // Provenance of the graph
+}
+
+// Write generates vis-network-compatible JSON or HTML output
+func (w *VisJSWriter) Write(writer io.Writer, depGraph *graph.DependencyGraph, config Config) error {
+	if _, err := paletteOf(config); err != nil {
+		return err
+	}
+	visGraph, err := convertToVisJSFormat(depGraph, config)
+	if err != nil {
+		return err
+	}
+
+	// Check if HTML page should be generated
+	if config.GetBool("htmlPage", false) {
+		theme, err := pageTheme(config)
+		if err != nil {
+			return err
+		}
+		return writeVisJSHTML(writer, visGraph, theme)
+	}
+
+	// Otherwise output JSON
+	enc := json.NewEncoder(writer)
+
+	// Check if pretty printing is enabled (defaults to true)
+	if config.GetBool("pretty", true) {
+		enc.SetIndent("", "  ")
+	}
+
+	return enc.Encode(visGraph)
+}
+
+// convertToVisJSFormat converts a DependencyGraph to vis-network nodes and edges, sorted by ID so
+// that the hierarchical layout of the page is stable
+func convertToVisJSFormat(depGraph *graph.DependencyGraph, config Config) (*VisJSGraph, error) {
+	layout := config.GetString("visjsLayout", VisJSLayoutHierarchical)
+	if layout != VisJSLayoutHierarchical && layout != VisJSLayoutForce {
+		return nil, fmt.Errorf("invalid visjsLayout %q: expected %q or %q", layout, VisJSLayoutHierarchical, VisJSLayoutForce)
+	}
+	direction := config.GetString("visjsDirection", "UD")
+	if !slices.Contains([]string{"UD", "DU", "LR", "RL"}, direction) {
+		return nil, fmt.Errorf("invalid visjsDirection %q: expected UD, DU, LR or RL", direction)
+	}
+
+	visGraph := &VisJSGraph{
+		Nodes:           make([]VisJSNode, 0, len(depGraph.Nodes)),
+		Edges:           make([]VisJSEdge, 0),
+		Layout:          layout,
+		Direction:       direction,
+		ClusterPackages: config.GetBool("clusterPackages", false),
+		Metadata:        depGraph.Metadata,
+	}
+
+	// Nodes take the palette color of their package or kind; Write reports invalid palettes
+	palette, _ := paletteOf(config)
+	fanIn := depGraph.FanIn()
+
+	for _, id := range slices.Sorted(maps.Keys(depGraph.Nodes)) {
+		node := depGraph.Nodes[id]
+		visGraph.Nodes = append(visGraph.Nodes, VisJSNode{
+			ID:      node.ID,
+			Label:   node.Name,
+			Title:   visjsTitle(node),
+			Group:   config.GroupOf(node),
+			Shape:   visjsShape(node.Kind),
+			Value:   fanIn[node.ID],
+			Color:   palette.nodeColor(node),
+			Kind:    string(node.Kind),
+			Package: node.Package,
+			File:    node.File,
+			Line:    node.Line,
+			DocURL:  node.DocURL,
+			Attrs:   node.Attrs,
+		})
+
+		targets := slices.Clone(depGraph.Edges[id])
+		slices.Sort(targets)
+		for _, targetID := range targets {
+			if _, exists := depGraph.Nodes[targetID]; !exists {
+				continue
+			}
+			visGraph.Edges = append(visGraph.Edges, VisJSEdge{
+				From:  id,
+				To:    targetID,
+				Attrs: depGraph.EdgeAttrsOf(id, targetID),
+			})
+		}
+	}
+
+	return visGraph, nil
+}
+
+// visjsShape returns the vis-network shape of a node kind
+func visjsShape(kind graph.NodeKind) string {
+	switch {
+	case kind.IsType():
+		return "diamond"
+	case kind.IsTestFunction():
+		return "triangle"
+	default:
+		return "dot"
+	}
+}
+
+// visjsTitle returns the tooltip of a node, which vis-network shows as plain text
+func visjsTitle(node *graph.Node) string {
+	lines := []string{node.Name, "Kind: " + string(node.Kind), "Package: " + node.Package}
+	if node.File != "" {
+		lines = append(lines, fmt.Sprintf("File: %s:%d", node.File, node.Line))
+	}
+	if doc := node.Summary(); doc != "" {
+		lines = append(lines, doc)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeVisJSHTML generates a self-contained HTML page with embedded vis-network
+func writeVisJSHTML(writer io.Writer, visGraph *VisJSGraph, theme string) error {
+	// Parse the embedded template
+	tmpl, err := template.ParseFS(visjsTemplateFS, "templates/visjs.html")
+	if err != nil {
+		return err
+	}
+
+	// Marshal the graph data to JSON
+	jsonData, err := json.Marshal(visGraph)
+	if err != nil {
+		return err
+	}
+
+	// Prepare template data
+	data := struct {
+		Data  template.JS
+		Theme string
+	}{
+		Data:  template.JS(jsonData), // #nosec G203 - JSON data is safe, we control the marshaling
+		Theme: theme,
+	}
+
+	// Execute the template
+	return tmpl.Execute(writer, data)
+}
@@ -0,0 +1,100 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestVisJSWriter_Write_JSON(t *testing.T) {
+	g := &graph.DependencyGraph{
+		Nodes: map[string]*graph.Node{
+			"b::func1":    {ID: "b::func1", Name: "func1", Kind: graph.KindFunction, Package: "b", File: "b.go", Line: 3},
+			"a::Type1":    {ID: "a::Type1", Name: "Type1", Kind: graph.KindStruct, Package: "a"},
+			"a::TestType": {ID: "a::TestType", Name: "TestType", Kind: graph.KindTest, Package: "a"},
+		},
+		Edges: map[string][]string{
+			"b::func1":    {"a::Type1", "a::missing"},
+			"a::TestType": {"a::Type1"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&VisJSWriter{}).Write(&buf, g, Config{"packageColors": map[string]any{"b": "#123456"}}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var result VisJSGraph
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	if result.Layout != VisJSLayoutHierarchical || result.Direction != "UD" || result.ClusterPackages {
+		t.Errorf("Expected the default top down hierarchical layout, got %q %q %v", result.Layout, result.Direction, result.ClusterPackages)
+	}
+	if len(result.Nodes) != 3 || result.Nodes[0].ID != "a::TestType" || result.Nodes[2].ID != "b::func1" {
+		t.Fatalf("Expected the nodes sorted by ID, got %+v", result.Nodes)
+	}
+	if len(result.Edges) != 2 {
+		t.Errorf("Expected 2 edges without the missing target, got %+v", result.Edges)
+	}
+
+	want := map[string]struct {
+		shape string
+		value int
+		color string
+	}{
+		"a::TestType": {"triangle", 0, ""},
+		"a::Type1":    {"diamond", 2, ""},
+		"b::func1":    {"dot", 0, "#123456"},
+	}
+	for _, node := range result.Nodes {
+		w := want[node.ID]
+		if node.Shape != w.shape || node.Value != w.value || node.Color != w.color || node.Group != node.Package {
+			t.Errorf("Expected %s to be a %s of value %d in %q, grouped by package, got %+v", node.ID, w.shape, w.value, w.color, node)
+		}
+	}
+	if title := result.Nodes[2].Title; !strings.Contains(title, "Package: b") || !strings.Contains(title, "File: b.go:3") {
+		t.Errorf("Expected the package and file in the tooltip, got %q", title)
+	}
+}
+
+func TestVisJSWriter_Config(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg::Func"] = &graph.Node{ID: "pkg::Func", Name: "Func", Kind: graph.KindFunction, Package: "pkg"}
+
+	visGraph, err := convertToVisJSFormat(g, Config{"visjsLayout": "force", "visjsDirection": "LR", "clusterPackages": true})
+	if err != nil {
+		t.Fatalf("convertToVisJSFormat failed: %v", err)
+	}
+	if visGraph.Layout != VisJSLayoutForce || visGraph.Direction != "LR" || !visGraph.ClusterPackages {
+		t.Errorf("Expected the config to carry over, got %q %q %v", visGraph.Layout, visGraph.Direction, visGraph.ClusterPackages)
+	}
+
+	for _, config := range []Config{{"visjsLayout": "circular"}, {"visjsDirection": "up"}} {
+		if err := (&VisJSWriter{}).Write(&bytes.Buffer{}, g, config); err == nil {
+			t.Errorf("Expected an error for %v", config)
+		}
+	}
+}
+
+func TestVisJSWriter_Write_HTML(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["pkg::Func"] = &graph.Node{ID: "pkg::Func", Name: "Func", Kind: graph.KindFunction, Package: "pkg"}
+
+	var buf bytes.Buffer
+	if err := (&VisJSWriter{}).Write(&buf, g, Config{"htmlPage": true}); err != nil {
+		t.Fatalf("Write HTML failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "<!DOCTYPE html>") || !strings.Contains(output, "vis-network") {
+		t.Error("Expected a vis-network HTML page")
+	}
+	if !strings.Contains(output, `"id":"pkg::Func"`) {
+		t.Error("Expected the node embedded in the page")
+	}
+}
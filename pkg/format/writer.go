@@ -4,6 +4,8 @@ package format
 
 import (
 	"io"
+	"strings"
+	"sync"
 
 	"go-depmap/pkg/graph"
 )
@@ -14,8 +16,62 @@ type Writer interface {
 	Write(w io.Writer, graph *graph.DependencyGraph, config Config) error
 }
 
+// WriterFactory creates a new Writer instance for a registered format
+type WriterFactory func() Writer
+
+// execFormatPrefix selects the subprocess writer, e.g. "exec:./my-writer --flag"
+const execFormatPrefix = "exec:"
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]WriterFactory)
+)
+
+// RegisterFormatWriter makes a Writer available under the given format name.
+// Registering a name that already exists replaces the previous factory,
+// which allows library users to override the built-in formats.
+func RegisterFormatWriter(name string, factory WriterFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
 // GetFormatWriter returns a Writer for the given format name
 func GetFormatWriter(format string) Writer {
+	// Subprocess plugins: pipe the canonical JSON to an external command
+	if strings.HasPrefix(format, execFormatPrefix) {
+		return NewExecWriter(strings.TrimPrefix(format, execFormatPrefix))
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[format]
+	registryMu.RUnlock()
+	if ok {
+		return factory()
+	}
+
+	if writer := builtinWriter(format); writer != nil {
+		return writer
+	}
+	// Default to JSON
+	return &JSONWriter{}
+}
+
+// IsFormat reports whether a format name selects a writer: a registered or built-in format,
+// or a subprocess plugin. GetFormatWriter writes JSON for the other names, which commands
+// taking a format name from the user should reject instead.
+func IsFormat(format string) bool {
+	if strings.HasPrefix(format, execFormatPrefix) {
+		return true
+	}
+	registryMu.RLock()
+	_, ok := registry[format]
+	registryMu.RUnlock()
+	return ok || builtinWriter(format) != nil
+}
+
+// builtinWriter returns the Writer of a built-in format, nil for other names
+func builtinWriter(format string) Writer {
 	switch format {
 	case "json":
 		return &JSONWriter{}
@@ -25,8 +81,88 @@ func GetFormatWriter(format string) Writer {
 		return &CosmoWriter{}
 	case "antvg6":
 		return &AntVG6Writer{}
-	default:
-		// Default to JSON
-		return &JSONWriter{}
+	case "cytoscape":
+		return &CytoscapeWriter{}
+	case "echarts":
+		return &EChartsWriter{}
+	case "visjs":
+		return &VisJSWriter{}
+	case "sigma":
+		return &SigmaWriter{}
+	case "deckgl":
+		return &DeckGLWriter{}
+	case "lod":
+		return &LODWriter{}
+	case "3d":
+		return &ForceGraph3DWriter{}
+	case "dgml":
+		return &DGMLWriter{}
+	case "graphml":
+		return &GraphMLWriter{}
+	case "dot":
+		return &DOTWriter{}
+	case "tgf":
+		return &TGFWriter{}
+	case "pajek":
+		return &PajekWriter{}
+	case "parquet":
+		return &ParquetWriter{}
+	case "structurizr":
+		return &StructurizrWriter{}
+	case "backstage":
+		return &BackstageWriter{}
+	case "grafana":
+		return &GrafanaNodeGraphWriter{}
+	case "lsif":
+		return &LSIFWriter{}
+	case "matrix":
+		return &MatrixWriter{}
+	case "dashboard":
+		return &DashboardWriter{}
+	case "gh-summary":
+		return &GHSummaryWriter{}
+	case "junit":
+		return &JUnitWriter{}
+	}
+	return nil
+}
+
+// FileExtension returns the extension, without the dot, of the files a built-in format
+// writes with the given config, for commands writing several outputs to a directory; "out"
+// for plugins and unknown formats
+func FileExtension(format string, config Config) string {
+	switch format {
+	case "d3js", "cosmo", "antvg6", "cytoscape", "echarts", "visjs", "sigma", "deckgl", "lod":
+		if config.GetBool("htmlPage", false) {
+			return "html"
+		}
+		return "json"
+	case "3d", "dashboard":
+		if config.GetBool("htmlPage", true) {
+			return "html"
+		}
+		return "json"
+	case "matrix":
+		if config.GetBool("htmlPage", false) {
+			return "html"
+		}
+		return "csv"
+	case "json", "grafana":
+		return "json"
+	case "dgml", "graphml", "tgf", "parquet", "lsif":
+		return format
+	case "pajek":
+		return "net"
+	case "dot":
+		return "gv"
+	case "structurizr":
+		return "dsl"
+	case "backstage":
+		return "yaml"
+	case "gh-summary":
+		return "md"
+	case "junit":
+		return "xml"
 	}
+	return "out"
 }
@@ -2,6 +2,7 @@ package format
 
 import (
 	"bytes"
+	"io"
 	"testing"
 
 	"go-depmap/pkg/graph"
@@ -76,3 +77,79 @@ func Test_GetFormatWriter_ImplementsInterface(t *testing.T) {
 		})
 	}
 }
+
+type stubWriter struct{}
+
+func (w *stubWriter) Write(writer io.Writer, _ *graph.DependencyGraph, _ Config) error {
+	_, err := writer.Write([]byte("stub"))
+	return err
+}
+
+func Test_RegisterFormatWriter(t *testing.T) {
+	RegisterFormatWriter("stub", func() Writer { return &stubWriter{} })
+
+	writer := GetFormatWriter("stub")
+	if _, ok := writer.(*stubWriter); !ok {
+		t.Fatalf("expected *stubWriter, got %T", writer)
+	}
+
+	var buf bytes.Buffer
+	if err := writer.Write(&buf, graph.NewDependencyGraph(), Config{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if buf.String() != "stub" {
+		t.Errorf("expected output %q, got %q", "stub", buf.String())
+	}
+}
+
+func Test_GetFormatWriter_Exec(t *testing.T) {
+	writer := GetFormatWriter("exec:./my-writer --flag value")
+
+	execWriter, ok := writer.(*ExecWriter)
+	if !ok {
+		t.Fatalf("expected *ExecWriter, got %T", writer)
+	}
+	if execWriter.Command != "./my-writer" {
+		t.Errorf("expected command ./my-writer, got %s", execWriter.Command)
+	}
+	if len(execWriter.Args) != 2 || execWriter.Args[0] != "--flag" || execWriter.Args[1] != "value" {
+		t.Errorf("unexpected args: %v", execWriter.Args)
+	}
+}
+
+func Test_IsFormat(t *testing.T) {
+	for format, expected := range map[string]bool{
+		"json":          true,
+		"lod":           true,
+		"exec:./writer": true,
+		"d3jss":         false,
+		"":              false,
+	} {
+		if got := IsFormat(format); got != expected {
+			t.Errorf("IsFormat(%q) = %v, want %v", format, got, expected)
+		}
+	}
+}
+
+func Test_FileExtension(t *testing.T) {
+	tests := []struct {
+		format   string
+		config   Config
+		expected string
+	}{
+		{"json", Config{}, "json"},
+		{"d3js", Config{}, "json"},
+		{"d3js", Config{"htmlPage": true}, "html"},
+		{"dashboard", Config{}, "html"},
+		{"dashboard", Config{"htmlPage": false}, "json"},
+		{"matrix", Config{}, "csv"},
+		{"pajek", Config{}, "net"},
+		{"dot", Config{}, "gv"},
+		{"exec:./writer", Config{}, "out"},
+	}
+	for _, tt := range tests {
+		if got := FileExtension(tt.format, tt.config); got != tt.expected {
+			t.Errorf("FileExtension(%q, %v) = %q, want %q", tt.format, tt.config, got, tt.expected)
+		}
+	}
+}
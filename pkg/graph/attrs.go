@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"maps"
+	"slices"
+)
+
+// SetAttr sets a custom attribute of the node. Attributes let analyzers, overlays and plugins
+// attach data to nodes without adding fields to Node; every writer passes them through.
+func (n *Node) SetAttr(key string, value any) {
+	if n.Attrs == nil {
+		n.Attrs = make(map[string]any)
+	}
+	n.Attrs[key] = value
+}
+
+// Attr returns a custom attribute of the node, and whether it is set
+func (n *Node) Attr(key string) (any, bool) {
+	value, exists := n.Attrs[key]
+	return value, exists
+}
+
+// SetEdgeAttr sets a custom attribute of the edge from sourceID to targetID, adding the edge
+// if it does not exist yet
+func (g *DependencyGraph) SetEdgeAttr(sourceID, targetID, key string, value any) {
+	g.AddEdge(sourceID, targetID)
+
+	if g.EdgeAttrs == nil {
+		g.EdgeAttrs = make(map[string]map[string]map[string]any)
+	}
+	if g.EdgeAttrs[sourceID] == nil {
+		g.EdgeAttrs[sourceID] = make(map[string]map[string]any)
+	}
+	if g.EdgeAttrs[sourceID][targetID] == nil {
+		g.EdgeAttrs[sourceID][targetID] = make(map[string]any)
+	}
+	g.EdgeAttrs[sourceID][targetID][key] = value
+}
+
+// EdgeAttrsOf returns the custom attributes of an edge, or nil when it has none
+func (g *DependencyGraph) EdgeAttrsOf(sourceID, targetID string) map[string]any {
+	return g.EdgeAttrs[sourceID][targetID]
+}
+
+// AttrKeys returns the keys of attribute maps in sorted order, each key once, for writers
+// declaring the attributes up front
+func AttrKeys(attrs ...map[string]any) []string {
+	keys := make(map[string]bool)
+	for _, m := range attrs {
+		for key := range m {
+			keys[key] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(keys))
+}
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestAttrs(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["a::F"] = &Node{ID: "a::F", Name: "F", Package: "a", Kind: KindFunction}
+	g.Nodes["a::G"] = &Node{ID: "a::G", Name: "G", Package: "a", Kind: KindFunction}
+
+	g.Nodes["a::F"].SetAttr("coverage", 0.75)
+	g.SetEdgeAttr("a::F", "a::G", "calls", 3)
+
+	if value, exists := g.Nodes["a::F"].Attr("coverage"); !exists || value != 0.75 {
+		t.Errorf("Expected coverage 0.75, got %v", value)
+	}
+	if _, exists := g.Nodes["a::G"].Attr("coverage"); exists {
+		t.Errorf("Unset attributes should not exist")
+	}
+	if !g.HasEdge("a::F", "a::G") || g.EdgeAttrsOf("a::F", "a::G")["calls"] != 3 {
+		t.Errorf("Expected the edge to be added with its attribute, got %v", g.EdgeAttrs)
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded DependencyGraph
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Nodes["a::F"].Attrs["coverage"] != 0.75 || decoded.EdgeAttrsOf("a::F", "a::G")["calls"] != 3.0 {
+		t.Errorf("Attributes should survive a JSON round trip, got %v and %v", decoded.Nodes["a::F"].Attrs, decoded.EdgeAttrs)
+	}
+
+	extracted := g.Extract([]string{"a::F", "a::G"})
+	extracted.Nodes["a::F"].SetAttr("coverage", 1.0)
+	if g.Nodes["a::F"].Attrs["coverage"] != 0.75 {
+		t.Errorf("Extracted nodes should not share attributes with the original graph")
+	}
+	if extracted.EdgeAttrsOf("a::F", "a::G")["calls"] != 3 {
+		t.Errorf("Extract should keep edge attributes")
+	}
+}
+
+func TestAttrKeys(t *testing.T) {
+	keys := AttrKeys(map[string]any{"b": 1, "a": 2}, nil, map[string]any{"a": 3, "c": 4})
+	if !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Errorf("Expected sorted unique keys, got %v", keys)
+	}
+}
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"maps"
+	"slices"
+)
+
+// WeightAttr is the edge attribute holding the number of edges an edge stands for, set on
+// bundled edges
+const WeightAttr = "weight"
+
+// BundlePackageEdges returns a hybrid graph for architecture reviews: the edges within a
+// package are kept, while all the edges from the symbols of one package to those of another
+// are replaced with a single edge between nodes of kind package. The package nodes are added
+// as needed, and the bundled edges have the bundled kind and the number of edges they replace
+// as their weight attribute. Subgraphs are not computed.
+func (g *DependencyGraph) BundlePackageEdges() *DependencyGraph {
+	nodeIDs := make([]string, 0, len(g.Nodes))
+	for nodeID := range g.Nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	bundled := g.Extract(nodeIDs)
+
+	// Edges between packages, by package node IDs, with the number of edges they replace
+	weights := make(map[string]map[string]int)
+	symbols := make(map[string]*Node) // A symbol of each package, by package node ID
+	for sourceID, targets := range bundled.Edges {
+		source := bundled.Nodes[sourceID]
+		kept := make([]string, 0, len(targets))
+		for _, targetID := range targets {
+			target := bundled.Nodes[targetID]
+			if target.Package == source.Package {
+				kept = append(kept, targetID)
+				continue
+			}
+			delete(bundled.EdgeKinds[sourceID], targetID)
+			delete(bundled.EdgeAttrs[sourceID], targetID)
+
+			from, to := packageNodeID(source), packageNodeID(target)
+			if weights[from] == nil {
+				weights[from] = make(map[string]int)
+			}
+			weights[from][to]++
+			symbols[from], symbols[to] = source, target
+		}
+		bundled.Edges[sourceID] = kept
+	}
+
+	for _, fromID := range slices.Sorted(maps.Keys(weights)) {
+		for _, toID := range slices.Sorted(maps.Keys(weights[fromID])) {
+			bundled.addPackageNode(symbols[fromID])
+			bundled.addPackageNode(symbols[toID])
+			bundled.AddEdge(fromID, toID, EdgeBundled)
+			bundled.SetEdgeAttr(fromID, toID, WeightAttr, weights[fromID][toID])
+		}
+	}
+	for sourceID, targets := range bundled.Edges {
+		if len(targets) == 0 {
+			delete(bundled.Edges, sourceID)
+		}
+	}
+	return bundled
+}
+
+// packageNodeID returns the ID of the node of kind package standing for the package of a
+// node: the import path. A node of kind package stands for itself.
+func packageNodeID(node *Node) string {
+	if node.Kind == KindPackage {
+		return node.ID
+	}
+	return node.Package
+}
+
+// addPackageNode adds the node of kind package standing for the package of a symbol, unless
+// the graph has it
+func (g *DependencyGraph) addPackageNode(symbol *Node) {
+	pkgPath := packageNodeID(symbol)
+	if _, exists := g.Nodes[pkgPath]; exists {
+		return
+	}
+	g.Nodes[pkgPath] = packageNode(symbol)
+}
+
+// EdgeWeight returns the number of edges an edge stands for: its weight attribute, or 1
+func (g *DependencyGraph) EdgeWeight(sourceID, targetID string) int {
+	switch weight := g.EdgeAttrsOf(sourceID, targetID)[WeightAttr].(type) {
+	case int:
+		return weight
+	case float64: // Decoded from JSON
+		return int(weight)
+	}
+	return 1
+}
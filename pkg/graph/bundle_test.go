@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBundlePackageEdges(t *testing.T) {
+	g := newLimitTestGraph()
+	g.AddEdge("b::B1", "a::A2", EdgeBody)
+	g.AddEdge("c::C1", "a::A1")
+	g.Nodes["os"] = &Node{ID: "os", Kind: KindPackage, Package: "os", External: true}
+	g.AddEdge("a::A1", "os")
+	g.AddEdge("a::A2", "os")
+	g.AddEdge("b::B1", "ext.X") // Dangling edges are dropped, as by Extract
+
+	bundled := g.BundlePackageEdges()
+
+	wantEdges := map[string][]string{
+		"a::A1": {"a::A2"},
+		"a::A2": {"a::A3"},
+		"a::A3": {"a::A1"},
+		"b::B1": {"b::B2"},
+		"b::B2": {"b::B1"},
+		"a":     {"os"},
+		"b":     {"a"},
+		"c":     {"a"},
+	}
+	if !reflect.DeepEqual(bundled.Edges, wantEdges) {
+		t.Errorf("Edges = %v, want %v", bundled.Edges, wantEdges)
+	}
+
+	weights := map[[2]string]int{{"a", "os"}: 2, {"b", "a"}: 2, {"c", "a"}: 1, {"a::A1", "a::A2"}: 1}
+	for edge, want := range weights {
+		if got := bundled.EdgeWeight(edge[0], edge[1]); got != want {
+			t.Errorf("EdgeWeight(%s, %s) = %d, want %d", edge[0], edge[1], got, want)
+		}
+	}
+	if kinds := bundled.EdgeKindsOf("b", "a"); !reflect.DeepEqual(kinds, []EdgeKind{EdgeBundled}) {
+		t.Errorf("EdgeKindsOf(b, a) = %v, want bundled", kinds)
+	}
+	if bundled.EdgeKindsOf("b::B1", "a::A2") != nil {
+		t.Errorf("Expected the kinds of bundled edges to be removed")
+	}
+
+	pkg := bundled.Nodes["a"]
+	if pkg == nil || pkg.Kind != KindPackage || pkg.Package != "a" || pkg.External {
+		t.Errorf("Unexpected package node: %+v", pkg)
+	}
+	if bundled.Nodes["os"] == nil || len(bundled.Nodes) != len(g.Nodes)+3 {
+		t.Errorf("Expected the existing os package node to be reused, got %d nodes", len(bundled.Nodes))
+	}
+	if len(g.Edges["b::B2"]) != 2 {
+		t.Errorf("Expected the original graph to be left unchanged")
+	}
+}
+
+func TestEdgeWeight(t *testing.T) {
+	g := NewDependencyGraph()
+	g.AddEdge("a", "b")
+	g.AddEdge("b", "c")
+	g.SetEdgeAttr("b", "c", WeightAttr, 3.0)
+
+	if weight := g.EdgeWeight("a", "b"); weight != 1 {
+		t.Errorf("EdgeWeight of a plain edge = %d, want 1", weight)
+	}
+	if weight := g.EdgeWeight("b", "c"); weight != 3 {
+		t.Errorf("EdgeWeight of a decoded weight = %d, want 3", weight)
+	}
+}
@@ -0,0 +1,99 @@
+package graph
+
+import "sort"
+
+// LongestChain returns the longest acyclic dependency path in the graph, from the
+// outermost dependent to the deepest dependency. Edges inside dependency cycles are
+// ignored, since any path through a cycle could be extended indefinitely; the edges
+// between different strongly connected components always form a DAG.
+// Ties are broken by node ID so the result is deterministic.
+func (g *DependencyGraph) LongestChain() []string {
+	chains := g.LongestChains(1)
+	if len(chains) == 0 {
+		return []string{}
+	}
+	return chains[0]
+}
+
+// LongestChains returns up to k of the longest acyclic dependency paths, longest first.
+// Only chains starting at a root (a node no other component depends on) are considered,
+// so no returned chain is merely the tail of another. Each root contributes its single
+// longest chain, with the same cycle handling and tie-breaking as LongestChain.
+// A k of zero or less returns the chains of all roots.
+func (g *DependencyGraph) LongestChains(k int) [][]string {
+	if len(g.Nodes) == 0 {
+		return [][]string{}
+	}
+
+	// Map every node to the strongly connected component it belongs to
+	component := make(map[string]int, len(g.Nodes))
+	for i, scc := range g.stronglyConnectedComponents() {
+		for _, nodeID := range scc {
+			component[nodeID] = i
+		}
+	}
+
+	// Nodes with an incoming edge from another component can never start a longest chain
+	hasDependents := make(map[string]bool, len(g.Nodes))
+	for sourceID, targets := range g.Edges {
+		if _, exists := g.Nodes[sourceID]; !exists {
+			continue
+		}
+		for _, target := range targets {
+			if _, exists := g.Nodes[target]; exists && component[target] != component[sourceID] {
+				hasDependents[target] = true
+			}
+		}
+	}
+
+	// depth[n] is the number of nodes on the longest chain starting at n; next[n] is its successor
+	depth := make(map[string]int, len(g.Nodes))
+	next := make(map[string]string, len(g.Nodes))
+
+	var visit func(nodeID string) int
+	visit = func(nodeID string) int {
+		if d, done := depth[nodeID]; done {
+			return d
+		}
+		best, bestNext := 1, ""
+		for _, target := range g.Edges[nodeID] {
+			if _, exists := g.Nodes[target]; !exists || component[target] == component[nodeID] {
+				continue
+			}
+			d := visit(target) + 1
+			if d > best || (d == best && target < bestNext) {
+				best, bestNext = d, target
+			}
+		}
+		depth[nodeID] = best
+		next[nodeID] = bestNext
+		return best
+	}
+
+	roots := make([]string, 0)
+	for nodeID := range g.Nodes {
+		if !hasDependents[nodeID] {
+			visit(nodeID)
+			roots = append(roots, nodeID)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if depth[roots[i]] != depth[roots[j]] {
+			return depth[roots[i]] > depth[roots[j]]
+		}
+		return roots[i] < roots[j]
+	})
+	if k > 0 && len(roots) > k {
+		roots = roots[:k]
+	}
+
+	chains := make([][]string, 0, len(roots))
+	for _, start := range roots {
+		chain := []string{start}
+		for current := next[start]; current != ""; current = next[current] {
+			chain = append(chain, current)
+		}
+		chains = append(chains, chain)
+	}
+	return chains
+}
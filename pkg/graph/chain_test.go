@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLongestChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []string
+		edges    map[string][]string
+		expected []string
+	}{
+		{
+			name:     "empty graph",
+			expected: []string{},
+		},
+		{
+			name:     "single node",
+			nodes:    []string{"A"},
+			expected: []string{"A"},
+		},
+		{
+			name:     "picks the deepest branch",
+			nodes:    []string{"A", "B", "C", "D", "E"},
+			edges:    map[string][]string{"A": {"B", "C"}, "C": {"D"}, "D": {"E"}},
+			expected: []string{"A", "C", "D", "E"},
+		},
+		{
+			name:  "cycles are not traversed",
+			nodes: []string{"A", "B", "C", "D"},
+			// B <-> C is a cycle; the chain may enter it but not loop through it
+			edges:    map[string][]string{"A": {"B"}, "B": {"C"}, "C": {"B", "D"}},
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "ties are broken by node ID",
+			nodes:    []string{"A", "B", "X", "Y"},
+			edges:    map[string][]string{"X": {"Y"}, "A": {"B"}},
+			expected: []string{"A", "B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewDependencyGraph()
+			for _, id := range tt.nodes {
+				g.Nodes[id] = &Node{ID: id}
+			}
+			if tt.edges != nil {
+				g.Edges = tt.edges
+			}
+
+			if chain := g.LongestChain(); !reflect.DeepEqual(chain, tt.expected) {
+				t.Errorf("LongestChain() = %v, want %v", chain, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLongestChains(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"A", "B", "C", "D", "X", "Y", "Z"} {
+		g.Nodes[id] = &Node{ID: id}
+	}
+	g.Edges = map[string][]string{
+		"A": {"B"},
+		"B": {"C"},
+		"C": {"D"},
+		"X": {"Y"},
+		"Z": {"C"},
+	}
+
+	tests := []struct {
+		name     string
+		k        int
+		expected [][]string
+	}{
+		{
+			name:     "top one",
+			k:        1,
+			expected: [][]string{{"A", "B", "C", "D"}},
+		},
+		{
+			name:     "tails of other chains are skipped",
+			k:        2,
+			expected: [][]string{{"A", "B", "C", "D"}, {"Z", "C", "D"}},
+		},
+		{
+			name:     "all roots",
+			k:        0,
+			expected: [][]string{{"A", "B", "C", "D"}, {"Z", "C", "D"}, {"X", "Y"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if chains := g.LongestChains(tt.k); !reflect.DeepEqual(chains, tt.expected) {
+				t.Errorf("LongestChains(%d) = %v, want %v", tt.k, chains, tt.expected)
+			}
+		})
+	}
+}
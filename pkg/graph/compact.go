@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"slices"
+	"unique"
+)
+
+// Compact reduces the memory held by a large graph without changing its content. Package,
+// package name, module, file, path, owner and receiver strings are interned, edge targets
+// share the string of their node's ID, the target lists are packed into a single array, and
+// identical edge kind lists are shared. Graphs decoded from JSON, where every occurrence of
+// an ID is a separate string, shrink the most. Edges added afterwards copy the target list
+// they grow, so it is safe to call at any point.
+func (g *DependencyGraph) Compact() {
+	intern := func(s string) string {
+		if s == "" {
+			return s
+		}
+		return unique.Make(s).Value()
+	}
+	for _, node := range g.Nodes {
+		node.Package = intern(node.Package)
+		node.PackageName = intern(node.PackageName)
+		node.Module = intern(node.Module)
+		node.File = intern(node.File)
+		node.Path = intern(node.Path)
+		node.Owner = intern(node.Owner)
+		node.Receiver = intern(node.Receiver)
+	}
+
+	// canonical returns the ID string held by the node, or an interned copy for dangling ends
+	canonical := func(id string) string {
+		if node, exists := g.Nodes[id]; exists {
+			return node.ID
+		}
+		return intern(id)
+	}
+
+	edgeCount := 0
+	for _, targets := range g.Edges {
+		edgeCount += len(targets)
+	}
+	packed := make([]string, 0, edgeCount)
+	for sourceID, targets := range g.Edges {
+		if len(targets) == 0 {
+			delete(g.Edges, sourceID)
+			continue
+		}
+		start := len(packed)
+		for _, targetID := range targets {
+			packed = append(packed, canonical(targetID))
+		}
+		// Capping the capacity makes appends copy instead of overwriting the next list
+		g.Edges[sourceID] = packed[start:len(packed):len(packed)]
+	}
+
+	shared := make(map[string][]EdgeKind)
+	for _, targets := range g.EdgeKinds {
+		for targetID, kinds := range targets {
+			key := edgeKindsKey(kinds)
+			if _, exists := shared[key]; !exists {
+				shared[key] = slices.Clip(slices.Clone(kinds))
+			}
+			targets[targetID] = shared[key]
+		}
+	}
+}
+
+// edgeKindsKey returns a string identifying a list of edge kinds
+func edgeKindsKey(kinds []EdgeKind) string {
+	key := ""
+	for _, kind := range kinds {
+		key += string(kind) + ","
+	}
+	return key
+}
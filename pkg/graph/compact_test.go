@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestCompact(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"pkg.A", "pkg.B", "pkg.C"} {
+		g.Nodes[id] = &Node{ID: id, Package: strings.Clone("pkg"), File: strings.Clone("a.go")}
+	}
+	g.AddEdge("pkg.A", strings.Clone("pkg.B"), EdgeBody)
+	g.AddEdge("pkg.A", strings.Clone("pkg.C"), EdgeBody)
+	g.AddEdge("pkg.B", strings.Clone("pkg.C"))
+	g.AddEdge("pkg.C", "ext.X", EdgeBody)
+	g.Edges["pkg.D"] = nil
+
+	g.Compact()
+
+	wantEdges := map[string][]string{
+		"pkg.A": {"pkg.B", "pkg.C"},
+		"pkg.B": {"pkg.C"},
+		"pkg.C": {"ext.X"},
+	}
+	if !reflect.DeepEqual(g.Edges, wantEdges) {
+		t.Errorf("Edges = %v, want %v", g.Edges, wantEdges)
+	}
+
+	// Targets share the string of their node's ID, and packages and files are interned
+	sameString := func(a, b string) bool {
+		return unsafe.StringData(a) == unsafe.StringData(b)
+	}
+	if !sameString(g.Edges["pkg.A"][0], g.Nodes["pkg.B"].ID) {
+		t.Errorf("Expected edge target to share the node ID string")
+	}
+	if !sameString(g.Nodes["pkg.A"].Package, g.Nodes["pkg.B"].Package) ||
+		!sameString(g.Nodes["pkg.A"].File, g.Nodes["pkg.C"].File) {
+		t.Errorf("Expected package and file strings to be interned")
+	}
+
+	// Identical kind lists are shared, and stay independent when one grows
+	if &g.EdgeKindsOf("pkg.A", "pkg.B")[0] != &g.EdgeKindsOf("pkg.C", "ext.X")[0] {
+		t.Errorf("Expected identical edge kinds to be shared")
+	}
+	g.AddEdge("pkg.A", "pkg.B", EdgeTraced)
+	if kinds := g.EdgeKindsOf("pkg.C", "ext.X"); !reflect.DeepEqual(kinds, []EdgeKind{EdgeBody}) {
+		t.Errorf("EdgeKindsOf(pkg.C, ext.X) = %v after growing another edge's kinds", kinds)
+	}
+
+	// Growing a packed target list must not overwrite the next one
+	g.AddEdge("pkg.A", "ext.Y")
+	g.AddEdge("pkg.B", "ext.Z")
+	if !reflect.DeepEqual(g.Edges["pkg.C"], []string{"ext.X"}) || len(g.Edges["pkg.A"]) != 3 {
+		t.Errorf("Edges = %v after adding edges to a compacted graph", g.Edges)
+	}
+}
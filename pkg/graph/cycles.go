@@ -0,0 +1,123 @@
+package graph
+
+import "sort"
+
+// FindCycles detects dependency cycles using Tarjan's strongly connected components algorithm.
+// Each returned cycle is a strongly connected component with more than one node, or a single
+// node that depends on itself. Node IDs within a cycle are sorted, and cycles are ordered by
+// size (largest first) and then by their first node ID.
+func (g *DependencyGraph) FindCycles() [][]string {
+	cycles := make([][]string, 0)
+	for _, component := range g.stronglyConnectedComponents() {
+		if len(component) == 1 && !g.hasSelfLoop(component[0]) {
+			continue
+		}
+		sort.Strings(component)
+		cycles = append(cycles, component)
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		if len(cycles[i]) != len(cycles[j]) {
+			return len(cycles[i]) > len(cycles[j])
+		}
+		return cycles[i][0] < cycles[j][0]
+	})
+
+	return cycles
+}
+
+// CycleMembership returns, for each node in a dependency cycle, the 1-based index of its
+// cycle in FindCycles, so that viewers can highlight the cycles: the edges in a cycle are
+// those between two nodes with the same index
+func (g *DependencyGraph) CycleMembership() map[string]int {
+	membership := make(map[string]int)
+	for i, cycle := range g.FindCycles() {
+		for _, nodeID := range cycle {
+			membership[nodeID] = i + 1
+		}
+	}
+	return membership
+}
+
+// stronglyConnectedComponents returns all strongly connected components, including single nodes
+func (g *DependencyGraph) stronglyConnectedComponents() [][]string {
+	return g.newNodeIndex().stronglyConnectedComponents()
+}
+
+// stronglyConnectedComponents returns the strongly connected components of the indexed
+// nodes, every component after the components it reaches
+func (x *nodeIndex) stronglyConnectedComponents() [][]string {
+	t := &tarjan{
+		graph:   x,
+		index:   make([]int32, x.nodes),
+		lowLink: make([]int32, x.nodes),
+		onStack: make([]bool, x.nodes),
+	}
+	for i := range t.index {
+		t.index[i] = -1
+	}
+	for i := range x.nodes {
+		if t.index[i] < 0 {
+			t.strongConnect(i)
+		}
+	}
+	return t.components
+}
+
+// hasSelfLoop reports whether the node has an edge to itself
+func (g *DependencyGraph) hasSelfLoop(nodeID string) bool {
+	for _, target := range g.Edges[nodeID] {
+		if target == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// tarjan holds the state of Tarjan's strongly connected components algorithm, by node index
+type tarjan struct {
+	graph      *nodeIndex
+	counter    int32
+	index      []int32 // Visit order, -1 for unvisited nodes
+	lowLink    []int32
+	onStack    []bool
+	stack      []int32
+	components [][]string
+}
+
+// strongConnect visits a node and emits the strongly connected component rooted at it, if any
+func (t *tarjan) strongConnect(node int32) {
+	t.index[node] = t.counter
+	t.lowLink[node] = t.counter
+	t.counter++
+	t.stack = append(t.stack, node)
+	t.onStack[node] = true
+
+	for _, target := range t.graph.targetsOf(node) {
+		// Ignore edges to nodes that are not part of the graph
+		if !t.graph.isNode(target) {
+			continue
+		}
+		if t.index[target] < 0 {
+			t.strongConnect(target)
+			t.lowLink[node] = min(t.lowLink[node], t.lowLink[target])
+		} else if t.onStack[target] {
+			t.lowLink[node] = min(t.lowLink[node], t.index[target])
+		}
+	}
+
+	// Root of a strongly connected component: pop it off the stack
+	if t.lowLink[node] == t.index[node] {
+		component := make([]string, 0)
+		for {
+			top := t.stack[len(t.stack)-1]
+			t.stack = t.stack[:len(t.stack)-1]
+			t.onStack[top] = false
+			component = append(component, t.graph.ids[top])
+			if top == node {
+				break
+			}
+		}
+		t.components = append(t.components, component)
+	}
+}
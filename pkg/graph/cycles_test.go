@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []string
+		edges    map[string][]string
+		expected [][]string
+	}{
+		{
+			name:     "acyclic graph",
+			nodes:    []string{"A", "B", "C"},
+			edges:    map[string][]string{"A": {"B"}, "B": {"C"}},
+			expected: [][]string{},
+		},
+		{
+			name:     "simple cycle",
+			nodes:    []string{"A", "B", "C"},
+			edges:    map[string][]string{"A": {"B"}, "B": {"C"}, "C": {"A"}},
+			expected: [][]string{{"A", "B", "C"}},
+		},
+		{
+			name:     "self loop",
+			nodes:    []string{"A", "B"},
+			edges:    map[string][]string{"A": {"A", "B"}},
+			expected: [][]string{{"A"}},
+		},
+		{
+			name:  "multiple cycles ordered by size",
+			nodes: []string{"A", "B", "C", "D", "E", "F"},
+			edges: map[string][]string{
+				"A": {"B"}, "B": {"A"},
+				"C": {"D"}, "D": {"E"}, "E": {"C", "F"},
+			},
+			expected: [][]string{{"C", "D", "E"}, {"A", "B"}},
+		},
+		{
+			name:     "edges to unknown nodes are ignored",
+			nodes:    []string{"A"},
+			edges:    map[string][]string{"A": {"X"}, "X": {"A"}},
+			expected: [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewDependencyGraph()
+			for _, id := range tt.nodes {
+				g.Nodes[id] = &Node{ID: id, Name: id}
+			}
+			g.Edges = tt.edges
+
+			cycles := g.FindCycles()
+			if !reflect.DeepEqual(cycles, tt.expected) {
+				t.Errorf("FindCycles() = %v, want %v", cycles, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCycleMembership(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, nodeID := range []string{"A", "B", "C", "D", "E", "F"} {
+		g.Nodes[nodeID] = &Node{ID: nodeID}
+	}
+	g.Edges = map[string][]string{
+		"A": {"B"}, "B": {"A"},
+		"C": {"D"}, "D": {"E"}, "E": {"C", "F"},
+	}
+
+	want := map[string]int{"C": 1, "D": 1, "E": 1, "A": 2, "B": 2}
+	if got := g.CycleMembership(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected membership %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// Edge is a single edge of a graph with its kinds, as listed in a Delta
+type Edge struct {
+	Source string     `json:"source"`
+	Target string     `json:"target"`
+	Kinds  []EdgeKind `json:"kinds,omitempty"`
+}
+
+// Delta is the difference between two versions of a graph. Nodes are compared field by
+// field, so that a node whose subgraph, position or attributes changed is listed as changed
+// with its new version. Edges are identified by their source and target; an edge whose
+// kinds changed is listed as changed.
+type Delta struct {
+	AddedNodes   []*Node  `json:"added_nodes,omitempty"`
+	ChangedNodes []*Node  `json:"changed_nodes,omitempty"`
+	RemovedNodes []string `json:"removed_nodes,omitempty"`
+	AddedEdges   []Edge   `json:"added_edges,omitempty"`
+	ChangedEdges []Edge   `json:"changed_edges,omitempty"`
+	RemovedEdges []Edge   `json:"removed_edges,omitempty"`
+}
+
+// Diff returns the changes turning the old graph into the new one, with nodes sorted by ID
+// and edges by source, then target
+func Diff(old, new *DependencyGraph) *Delta {
+	delta := &Delta{}
+	for nodeID, node := range new.Nodes {
+		if previous, exists := old.Nodes[nodeID]; !exists {
+			delta.AddedNodes = append(delta.AddedNodes, node)
+		} else if !reflect.DeepEqual(previous, node) {
+			delta.ChangedNodes = append(delta.ChangedNodes, node)
+		}
+	}
+	for nodeID := range old.Nodes {
+		if _, exists := new.Nodes[nodeID]; !exists {
+			delta.RemovedNodes = append(delta.RemovedNodes, nodeID)
+		}
+	}
+
+	for sourceID, targets := range new.Edges {
+		for _, targetID := range targets {
+			edge := Edge{Source: sourceID, Target: targetID, Kinds: new.EdgeKindsOf(sourceID, targetID)}
+			if !old.HasEdge(sourceID, targetID) {
+				delta.AddedEdges = append(delta.AddedEdges, edge)
+			} else if !slices.Equal(old.EdgeKindsOf(sourceID, targetID), edge.Kinds) {
+				delta.ChangedEdges = append(delta.ChangedEdges, edge)
+			}
+		}
+	}
+	for sourceID, targets := range old.Edges {
+		for _, targetID := range targets {
+			if !new.HasEdge(sourceID, targetID) {
+				delta.RemovedEdges = append(delta.RemovedEdges, Edge{Source: sourceID, Target: targetID})
+			}
+		}
+	}
+
+	for _, nodes := range [][]*Node{delta.AddedNodes, delta.ChangedNodes} {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	}
+	sort.Strings(delta.RemovedNodes)
+	for _, edges := range [][]Edge{delta.AddedEdges, delta.ChangedEdges, delta.RemovedEdges} {
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i].Source != edges[j].Source {
+				return edges[i].Source < edges[j].Source
+			}
+			return edges[i].Target < edges[j].Target
+		})
+	}
+	return delta
+}
+
+// IsEmpty reports whether the delta has no changes
+func (d *Delta) IsEmpty() bool {
+	return len(d.AddedNodes) == 0 && len(d.ChangedNodes) == 0 && len(d.RemovedNodes) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.ChangedEdges) == 0 && len(d.RemovedEdges) == 0
+}
@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := NewDependencyGraph()
+	for _, id := range []string{"A", "B", "C"} {
+		old.Nodes[id] = &Node{ID: id, Name: id, Line: 1}
+	}
+	old.AddEdge("A", "B", EdgeBody)
+	old.AddEdge("A", "C")
+	old.AddEdge("B", "C")
+
+	new := NewDependencyGraph()
+	for _, id := range []string{"A", "B", "D"} {
+		new.Nodes[id] = &Node{ID: id, Name: id, Line: 1}
+	}
+	new.Nodes["B"].Line = 2
+	new.AddEdge("A", "B", EdgeSignature)
+	new.AddEdge("A", "D")
+
+	delta := Diff(old, new)
+
+	if len(delta.AddedNodes) != 1 || delta.AddedNodes[0].ID != "D" {
+		t.Errorf("AddedNodes = %v", delta.AddedNodes)
+	}
+	if len(delta.ChangedNodes) != 1 || delta.ChangedNodes[0].Line != 2 {
+		t.Errorf("ChangedNodes = %v", delta.ChangedNodes)
+	}
+	if !reflect.DeepEqual(delta.RemovedNodes, []string{"C"}) {
+		t.Errorf("RemovedNodes = %v", delta.RemovedNodes)
+	}
+	if !reflect.DeepEqual(delta.AddedEdges, []Edge{{Source: "A", Target: "D"}}) {
+		t.Errorf("AddedEdges = %v", delta.AddedEdges)
+	}
+	if !reflect.DeepEqual(delta.ChangedEdges, []Edge{{Source: "A", Target: "B", Kinds: []EdgeKind{EdgeSignature}}}) {
+		t.Errorf("ChangedEdges = %v", delta.ChangedEdges)
+	}
+	if !reflect.DeepEqual(delta.RemovedEdges, []Edge{{Source: "A", Target: "C"}, {Source: "B", Target: "C"}}) {
+		t.Errorf("RemovedEdges = %v", delta.RemovedEdges)
+	}
+
+	if delta.IsEmpty() || !Diff(new, new).IsEmpty() {
+		t.Errorf("Expected only the diff of different graphs to have changes")
+	}
+}
@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"go/token"
+	"strings"
+)
+
+// pkgGoDevBase is the documentation site linked from exported symbols
+const pkgGoDevBase = "https://pkg.go.dev/"
+
+// PkgGoDevURL returns the pkg.go.dev anchor URL of a symbol, or "" when the symbol is not
+// documented there. name is a node name: Foo for functions and types, T.M or (*T).M for methods.
+// Methods are only documented when both the receiver type and the method are exported.
+func PkgGoDevURL(pkgPath, name string) string {
+	anchor := strings.NewReplacer("(*", "", ")", "").Replace(name)
+	for _, part := range strings.Split(anchor, ".") {
+		if !token.IsExported(part) {
+			return ""
+		}
+	}
+	return pkgGoDevBase + pkgPath + "#" + anchor
+}
+
+// PkgGoDevPackageURL returns the pkg.go.dev URL of a package's documentation
+func PkgGoDevPackageURL(pkgPath string) string {
+	return pkgGoDevBase + pkgPath
+}
+
+// packageDocURL returns the pkg.go.dev URL of the package of a node, for the package nodes
+// standing for it, or "" when the package is not documented there: its path is not public,
+// and the node is not an external symbol linked to pkg.go.dev, as those of the standard
+// library are
+func packageDocURL(node *Node) string {
+	if IsPublicPackagePath(node.Package) || node.External && node.DocURL != "" {
+		return PkgGoDevPackageURL(node.Package)
+	}
+	return ""
+}
+
+// IsPublicPackagePath reports whether an import path can be served by pkg.go.dev: the first
+// path element of a module hosted anywhere is a domain name. It is false for the standard
+// library, whose paths have no dot either; callers that know a package is in the standard
+// library link it regardless.
+func IsPublicPackagePath(pkgPath string) bool {
+	first, _, _ := strings.Cut(pkgPath, "/")
+	return strings.Contains(first, ".")
+}
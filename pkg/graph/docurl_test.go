@@ -0,0 +1,39 @@
+package graph
+
+import "testing"
+
+func Test_PkgGoDevURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Open", expected: "https://pkg.go.dev/example.com/db#Open"},
+		{name: "Conn", expected: "https://pkg.go.dev/example.com/db#Conn"},
+		{name: "Conn.Close", expected: "https://pkg.go.dev/example.com/db#Conn.Close"},
+		{name: "(*Conn).Query", expected: "https://pkg.go.dev/example.com/db#Conn.Query"},
+		{name: "open", expected: ""},
+		{name: "(*Conn).query", expected: ""},
+		{name: "(*conn).Query", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if got := PkgGoDevURL("example.com/db", tt.name); got != tt.expected {
+			t.Errorf("PkgGoDevURL(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func Test_IsPublicPackagePath(t *testing.T) {
+	tests := map[string]bool{
+		"github.com/user/repo/pkg": true,
+		"example.com":              true,
+		"go-depmap/pkg/graph":      false,
+		"net/http":                 false,
+	}
+
+	for path, expected := range tests {
+		if got := IsPublicPackagePath(path); got != expected {
+			t.Errorf("IsPublicPackagePath(%q) = %v, want %v", path, got, expected)
+		}
+	}
+}
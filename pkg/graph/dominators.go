@@ -0,0 +1,109 @@
+package graph
+
+import "sort"
+
+// Dominators computes the immediate dominator of every node reachable from root.
+// A node D dominates N when every path from root to N passes through D, so the
+// nodes dominated by D are reachable only through D: removing D makes them dead code
+// as far as root is concerned. The root itself has no immediate dominator and is not
+// included in the result. An unknown root yields an empty map.
+//
+// The implementation is the iterative algorithm by Cooper, Harvey and Kennedy
+// ("A Simple, Fast Dominance Algorithm").
+func (g *DependencyGraph) Dominators(root string) map[string]string {
+	idom := make(map[string]string)
+	if _, exists := g.Nodes[root]; !exists {
+		return idom
+	}
+
+	// Number the reachable nodes in post-order
+	postOrder := make([]string, 0)
+	postIndex := make(map[string]int)
+	visited := make(map[string]bool)
+	var visit func(nodeID string)
+	visit = func(nodeID string) {
+		visited[nodeID] = true
+		for _, target := range g.Edges[nodeID] {
+			if _, exists := g.Nodes[target]; exists && !visited[target] {
+				visit(target)
+			}
+		}
+		postIndex[nodeID] = len(postOrder)
+		postOrder = append(postOrder, nodeID)
+	}
+	visit(root)
+
+	predecessors := make(map[string][]string, len(postOrder))
+	for _, sourceID := range postOrder {
+		for _, target := range g.Edges[sourceID] {
+			if visited[target] {
+				predecessors[target] = append(predecessors[target], sourceID)
+			}
+		}
+	}
+
+	intersect := func(a, b string) string {
+		for a != b {
+			for postIndex[a] < postIndex[b] {
+				a = idom[a]
+			}
+			for postIndex[b] < postIndex[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+
+	idom[root] = root
+	for changed := true; changed; {
+		changed = false
+		// Reverse post-order, skipping the root (always last in post-order)
+		for i := len(postOrder) - 2; i >= 0; i-- {
+			nodeID := postOrder[i]
+			newIdom := ""
+			for _, pred := range predecessors[nodeID] {
+				if _, processed := idom[pred]; !processed {
+					continue
+				}
+				if newIdom == "" {
+					newIdom = pred
+				} else {
+					newIdom = intersect(pred, newIdom)
+				}
+			}
+			if idom[nodeID] != newIdom {
+				idom[nodeID] = newIdom
+				changed = true
+			}
+		}
+	}
+
+	delete(idom, root)
+	return idom
+}
+
+// DominatedSets inverts an immediate dominator map: for every node that dominates at least
+// one other node, it returns all nodes it (transitively) dominates, sorted by ID.
+func DominatedSets(idom map[string]string) map[string][]string {
+	children := make(map[string][]string)
+	for nodeID, dominator := range idom {
+		children[dominator] = append(children[dominator], nodeID)
+	}
+
+	var collect func(nodeID string, into *[]string)
+	collect = func(nodeID string, into *[]string) {
+		for _, child := range children[nodeID] {
+			*into = append(*into, child)
+			collect(child, into)
+		}
+	}
+
+	sets := make(map[string][]string, len(children))
+	for nodeID := range children {
+		dominated := make([]string, 0)
+		collect(nodeID, &dominated)
+		sort.Strings(dominated)
+		sets[nodeID] = dominated
+	}
+	return sets
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDominators(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []string
+		edges    map[string][]string
+		root     string
+		expected map[string]string
+	}{
+		{
+			name:     "unknown root",
+			nodes:    []string{"A"},
+			root:     "X",
+			expected: map[string]string{},
+		},
+		{
+			name:     "straight line",
+			nodes:    []string{"A", "B", "C"},
+			edges:    map[string][]string{"A": {"B"}, "B": {"C"}},
+			root:     "A",
+			expected: map[string]string{"B": "A", "C": "B"},
+		},
+		{
+			name:  "diamond joins at the root",
+			nodes: []string{"A", "B", "C", "D"},
+			edges: map[string][]string{"A": {"B", "C"}, "B": {"D"}, "C": {"D"}},
+			root:  "A",
+			// D is reachable through both B and C, so only A dominates it
+			expected: map[string]string{"B": "A", "C": "A", "D": "A"},
+		},
+		{
+			name:     "unreachable nodes are left out",
+			nodes:    []string{"A", "B", "Z"},
+			edges:    map[string][]string{"A": {"B"}, "Z": {"B"}},
+			root:     "A",
+			expected: map[string]string{"B": "A"},
+		},
+		{
+			name:     "cycles",
+			nodes:    []string{"A", "B", "C", "D"},
+			edges:    map[string][]string{"A": {"B"}, "B": {"C"}, "C": {"B", "D"}},
+			root:     "A",
+			expected: map[string]string{"B": "A", "C": "B", "D": "C"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewDependencyGraph()
+			for _, id := range tt.nodes {
+				g.Nodes[id] = &Node{ID: id}
+			}
+			if tt.edges != nil {
+				g.Edges = tt.edges
+			}
+
+			if idom := g.Dominators(tt.root); !reflect.DeepEqual(idom, tt.expected) {
+				t.Errorf("Dominators(%s) = %v, want %v", tt.root, idom, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDominatedSets(t *testing.T) {
+	idom := map[string]string{"B": "A", "C": "B", "D": "B", "E": "A"}
+
+	expected := map[string][]string{
+		"A": {"B", "C", "D", "E"},
+		"B": {"C", "D"},
+	}
+	if sets := DominatedSets(idom); !reflect.DeepEqual(sets, expected) {
+		t.Errorf("DominatedSets() = %v, want %v", sets, expected)
+	}
+}
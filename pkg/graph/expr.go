@@ -0,0 +1,727 @@
+package graph
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a compiled filter expression selecting nodes or edges by their fields, such as
+//
+//	kind=function && path~"^internal/" && fanin>10
+//	edge.kind=body && target.external
+//
+// Comparisons are field op value, where op is = (or ==), !=, ~ and !~ (regular expression
+// match), <, <=, > and >=. Values are numbers, true, false, bare words or double-quoted Go
+// strings; in edge expressions, they may also be fields of the edge, as in
+// source.package!=target.package. A field alone tests that it is set: true, non-zero or
+// non-empty. Comparisons are combined with &&, || and !, and grouped with parentheses.
+//
+// Node fields are those of Node in snake case (name, kind, package, path, lines, complexity,
+// churn, owner, recursive, returns_error, ...), plus subgraph, score, test, exported, fanin,
+// fanout, and attr.<key> for custom attributes. Fields listing several values, owner and
+// edge.kind, equal a value when one of theirs does. An expression using edge.kind,
+// edge.attr.<key>, or node fields prefixed with source. or target. selects edges instead of
+// nodes; it cannot use node fields without a prefix.
+type Expr struct {
+	source string
+	root   exprNode
+	edges  bool
+}
+
+// ParseExpr compiles a filter expression, see Expr
+func ParseExpr(source string) (*Expr, error) {
+	tokens, err := lexExpr(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, exprError(next.pos, "unexpected %q", next.text)
+	}
+	if p.nodeFields && p.edgeFields {
+		return nil, fmt.Errorf("expression mixes node fields with edge fields; prefix the node fields with source. or target.")
+	}
+	return &Expr{source: source, root: root, edges: p.edgeFields}, nil
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.source
+}
+
+// SelectsEdges reports whether the expression selects edges rather than nodes
+func (e *Expr) SelectsEdges() bool {
+	return e.edges
+}
+
+// MatchNodes returns the IDs of the nodes an expression selects, sorted: the matching nodes,
+// or for an edge expression the nodes of the matching edges
+func (g *DependencyGraph) MatchNodes(e *Expr) []string {
+	var nodeIDs []string
+	if e.edges {
+		seen := make(map[string]bool)
+		for _, edge := range g.MatchEdges(e) {
+			for _, nodeID := range []string{edge.Source, edge.Target} {
+				if !seen[nodeID] {
+					seen[nodeID] = true
+					nodeIDs = append(nodeIDs, nodeID)
+				}
+			}
+		}
+	} else {
+		env := &exprEnv{g: g}
+		for nodeID, node := range g.Nodes {
+			if e.root.eval(env, exprSubject{node: node}) {
+				nodeIDs = append(nodeIDs, nodeID)
+			}
+		}
+	}
+	slices.Sort(nodeIDs)
+	return nodeIDs
+}
+
+// MatchEdges returns the edges an expression selects, sorted by source, then target: the
+// matching edges, or for a node expression the edges between matching nodes
+func (g *DependencyGraph) MatchEdges(e *Expr) []Edge {
+	env := &exprEnv{g: g}
+	matched := make(map[string]bool)
+	var edges []Edge
+	for sourceID, targets := range g.Edges {
+		source, exists := g.Nodes[sourceID]
+		if !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			target, exists := g.Nodes[targetID]
+			if !exists {
+				continue
+			}
+			if e.edges {
+				if !e.root.eval(env, exprSubject{source: source, target: target}) {
+					continue
+				}
+			} else {
+				for _, node := range []*Node{source, target} {
+					if _, evaluated := matched[node.ID]; !evaluated {
+						matched[node.ID] = e.root.eval(env, exprSubject{node: node})
+					}
+				}
+				if !matched[sourceID] || !matched[targetID] {
+					continue
+				}
+			}
+			edges = append(edges, Edge{Source: sourceID, Target: targetID, Kinds: g.EdgeKindsOf(sourceID, targetID)})
+		}
+	}
+	slices.SortFunc(edges, func(a, b Edge) int {
+		if c := strings.Compare(a.Source, b.Source); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Target, b.Target)
+	})
+	return edges
+}
+
+// Select returns the part of the graph an expression selects: the matching nodes and the
+// edges between them, or for an edge expression the matching edges and their nodes.
+// Subgraphs are not copied, as for Extract.
+func (g *DependencyGraph) Select(e *Expr) *DependencyGraph {
+	selected := g.Extract(g.MatchNodes(e))
+	if !e.edges {
+		return selected
+	}
+
+	// Extract keeps every edge between the nodes, matching or not
+	keep := make(map[[2]string]bool)
+	for _, edge := range g.MatchEdges(e) {
+		keep[[2]string{edge.Source, edge.Target}] = true
+	}
+	selected.retainEdges(func(sourceID, targetID string) bool {
+		return keep[[2]string{sourceID, targetID}]
+	})
+	return selected
+}
+
+// exprEnv is the graph an expression is evaluated on, with the fan-in and fan-out of its
+// nodes computed when first used
+type exprEnv struct {
+	g      *DependencyGraph
+	fanIn  map[string]int
+	fanOut map[string]int
+}
+
+func (env *exprEnv) fanInOf(nodeID string) int {
+	if env.fanIn == nil {
+		env.fanIn = env.g.FanIn()
+	}
+	return env.fanIn[nodeID]
+}
+
+func (env *exprEnv) fanOutOf(nodeID string) int {
+	if env.fanOut == nil {
+		env.fanOut = env.g.FanOut()
+	}
+	return env.fanOut[nodeID]
+}
+
+// exprSubject is what an expression is evaluated against: a node, or the nodes of an edge
+type exprSubject struct {
+	node           *Node
+	source, target *Node
+}
+
+// valueType is the type of a field's values. Custom attributes are of any type, known only
+// when evaluated.
+type valueType int
+
+const (
+	anyValue valueType = iota
+	stringValue
+	numberValue
+	boolValue
+	listValue
+)
+
+// nodeField reads a field of a node, as a string, float64, bool or []string
+type nodeField struct {
+	typ valueType
+	get func(env *exprEnv, n *Node) any
+}
+
+// nodeFields are the node fields expressions can use
+var nodeFields = map[string]nodeField{
+	"id":            {stringValue, func(_ *exprEnv, n *Node) any { return n.ID }},
+	"name":          {stringValue, func(_ *exprEnv, n *Node) any { return n.Name }},
+	"kind":          {stringValue, func(_ *exprEnv, n *Node) any { return string(n.Kind) }},
+	"package":       {stringValue, func(_ *exprEnv, n *Node) any { return n.Package }},
+	"file":          {stringValue, func(_ *exprEnv, n *Node) any { return n.File }},
+	"path":          {stringValue, func(_ *exprEnv, n *Node) any { return n.Path }},
+	"line":          {numberValue, func(_ *exprEnv, n *Node) any { return float64(n.Line) }},
+	"signature":     {stringValue, func(_ *exprEnv, n *Node) any { return n.Signature }},
+	"subgraph":      {numberValue, func(_ *exprEnv, n *Node) any { return float64(n.SubgraphID) }},
+	"score":         {numberValue, func(_ *exprEnv, n *Node) any { return n.SubgraphScore }},
+	"external":      {boolValue, func(_ *exprEnv, n *Node) any { return n.External }},
+	"doc":           {stringValue, func(_ *exprEnv, n *Node) any { return n.Doc }},
+	"lines":         {numberValue, func(_ *exprEnv, n *Node) any { return float64(n.Lines) }},
+	"complexity":    {numberValue, func(_ *exprEnv, n *Node) any { return float64(n.Complexity) }},
+	"churn":         {numberValue, func(_ *exprEnv, n *Node) any { return float64(n.Churn) }},
+	"owner":         {listValue, func(_ *exprEnv, n *Node) any { return strings.Fields(n.Owner) }},
+	"spans":         {numberValue, func(_ *exprEnv, n *Node) any { return float64(n.Spans) }},
+	"unsafe":        {boolValue, func(_ *exprEnv, n *Node) any { return n.Unsafe }},
+	"reflect":       {boolValue, func(_ *exprEnv, n *Node) any { return n.Reflect }},
+	"linkname":      {boolValue, func(_ *exprEnv, n *Node) any { return n.Linkname }},
+	"deprecated":    {stringValue, func(_ *exprEnv, n *Node) any { return n.Deprecated }},
+	"generated":     {boolValue, func(_ *exprEnv, n *Node) any { return n.Generated }},
+	"mock":          {boolValue, func(_ *exprEnv, n *Node) any { return n.Mock }},
+	"recursive":     {boolValue, func(_ *exprEnv, n *Node) any { return n.Recursive }},
+	"returns_error": {boolValue, func(_ *exprEnv, n *Node) any { return n.ReturnsError }},
+	"takes_context": {boolValue, func(_ *exprEnv, n *Node) any { return n.TakesContext }},
+	"test":          {boolValue, func(_ *exprEnv, n *Node) any { return n.IsTest() }},
+	"exported":      {boolValue, func(_ *exprEnv, n *Node) any { return isExportedName(n.Name) }},
+	"fanin":         {numberValue, func(env *exprEnv, n *Node) any { return float64(env.fanInOf(n.ID)) }},
+	"fanout":        {numberValue, func(env *exprEnv, n *Node) any { return float64(env.fanOutOf(n.ID)) }},
+}
+
+// isExportedName reports whether a node name is exported, methods by their own name
+func isExportedName(name string) bool {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return token.IsExported(name)
+}
+
+// accessor reads a field from the subject of an expression
+type accessor func(env *exprEnv, s exprSubject) any
+
+// exprNode is a node of a compiled expression
+type exprNode interface {
+	eval(env *exprEnv, s exprSubject) bool
+}
+
+type andExpr struct{ left, right exprNode }
+
+func (e andExpr) eval(env *exprEnv, s exprSubject) bool {
+	return e.left.eval(env, s) && e.right.eval(env, s)
+}
+
+type orExpr struct{ left, right exprNode }
+
+func (e orExpr) eval(env *exprEnv, s exprSubject) bool {
+	return e.left.eval(env, s) || e.right.eval(env, s)
+}
+
+type notExpr struct{ operand exprNode }
+
+func (e notExpr) eval(env *exprEnv, s exprSubject) bool {
+	return !e.operand.eval(env, s)
+}
+
+// truthExpr tests that a field is set
+type truthExpr struct{ get accessor }
+
+func (e truthExpr) eval(env *exprEnv, s exprSubject) bool {
+	switch value := e.get(env, s).(type) {
+	case bool:
+		return value
+	case string:
+		return value != ""
+	case []string:
+		return len(value) > 0
+	case nil:
+		return false
+	default:
+		number, ok := toNumber(value)
+		return !ok || number != 0
+	}
+}
+
+// compareExpr compares a field with a literal, parsed for every type it may be compared as
+type compareExpr struct {
+	get     accessor
+	op      string
+	text    string
+	number  float64
+	isNum   bool
+	boolean bool
+	isBool  bool
+	pattern *regexp.Regexp
+}
+
+func (e compareExpr) eval(env *exprEnv, s exprSubject) bool {
+	switch value := e.get(env, s).(type) {
+	case string:
+		return e.compareString(value)
+	case []string:
+		matches := slices.ContainsFunc(value, func(item string) bool {
+			if e.pattern != nil {
+				return e.pattern.MatchString(item)
+			}
+			return item == e.text
+		})
+		return matches == (e.op == "=" || e.op == "~")
+	case bool:
+		if !e.isBool {
+			return false
+		}
+		return (value == e.boolean) == (e.op == "=")
+	case nil:
+		// An unset attribute differs from every value
+		return e.op == "!=" || e.op == "!~"
+	default:
+		number, ok := toNumber(value)
+		if !ok {
+			return e.compareString(fmt.Sprint(value))
+		}
+		if !e.isNum {
+			return e.op == "!="
+		}
+		switch e.op {
+		case "=":
+			return number == e.number
+		case "!=":
+			return number != e.number
+		case "<":
+			return number < e.number
+		case "<=":
+			return number <= e.number
+		case ">":
+			return number > e.number
+		case ">=":
+			return number >= e.number
+		}
+		return false
+	}
+}
+
+func (e compareExpr) compareString(value string) bool {
+	switch e.op {
+	case "=":
+		return value == e.text
+	case "!=":
+		return value != e.text
+	case "~":
+		return e.pattern.MatchString(value)
+	case "!~":
+		return !e.pattern.MatchString(value)
+	}
+	return false
+}
+
+// fieldCompareExpr compares two fields of an edge
+type fieldCompareExpr struct {
+	left, right accessor
+	op          string
+}
+
+func (e fieldCompareExpr) eval(env *exprEnv, s exprSubject) bool {
+	left, right := e.left(env, s), e.right(env, s)
+	leftNumber, leftOK := toNumber(left)
+	rightNumber, rightOK := toNumber(right)
+	if leftOK && rightOK {
+		switch e.op {
+		case "=":
+			return leftNumber == rightNumber
+		case "!=":
+			return leftNumber != rightNumber
+		case "<":
+			return leftNumber < rightNumber
+		case "<=":
+			return leftNumber <= rightNumber
+		case ">":
+			return leftNumber > rightNumber
+		case ">=":
+			return leftNumber >= rightNumber
+		}
+		return false
+	}
+	switch e.op {
+	case "=":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	}
+	return false
+}
+
+// toNumber converts the numbers of custom attributes, whose type depends on their writer
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Tokens of the expression language
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string // Unquoted for strings
+	pos  int
+}
+
+// comparisonOps are the comparison operators, longest first
+var comparisonOps = []string{"==", "!=", "!~", "<=", ">=", "=", "~", "<", ">"}
+
+// lexExpr splits an expression into tokens
+func lexExpr(source string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(source[i:], "&&"):
+			tokens = append(tokens, exprToken{tokenAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(source[i:], "||"):
+			tokens = append(tokens, exprToken{tokenOr, "||", i})
+			i += 2
+		case c == '(':
+			tokens = append(tokens, exprToken{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, exprToken{tokenRParen, ")", i})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(source) && source[end] != '"' {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) {
+				return nil, exprError(i, "unterminated string")
+			}
+			text, err := strconv.Unquote(source[i : end+1])
+			if err != nil {
+				return nil, exprError(i, "invalid string %s", source[i:end+1])
+			}
+			tokens = append(tokens, exprToken{tokenString, text, i})
+			i = end + 1
+		default:
+			if op := opAt(source[i:]); op != "" {
+				tokens = append(tokens, exprToken{tokenOp, op, i})
+				i += len(op)
+				continue
+			}
+			if c == '!' {
+				tokens = append(tokens, exprToken{tokenNot, "!", i})
+				i++
+				continue
+			}
+			end := i
+			for end < len(source) && isWordByte(source[end]) {
+				end++
+			}
+			if end == i {
+				return nil, exprError(i, "unexpected %q", string(c))
+			}
+			tokens = append(tokens, exprToken{tokenWord, source[i:end], i})
+			i = end
+		}
+	}
+	return append(tokens, exprToken{tokenEOF, "end of expression", len(source)}), nil
+}
+
+// opAt returns the comparison operator at the start of s, if any
+func opAt(s string) string {
+	for _, op := range comparisonOps {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// isWordByte reports whether a byte can be part of a bare word: a field name, or a value
+// such as a kind, a package path or a number
+func isWordByte(c byte) bool {
+	return c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || strings.IndexByte("_./-*:@#+", c) >= 0
+}
+
+func exprError(pos int, format string, args ...any) error {
+	return fmt.Errorf("column %d: %s", pos+1, fmt.Sprintf(format, args...))
+}
+
+// exprParser parses tokens by recursive descent, recording the kinds of fields used
+type exprParser struct {
+	tokens     []exprToken
+	pos        int
+	nodeFields bool
+	edgeFields bool
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+// parseOr parses a || b || ..., binding looser than &&
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd parses a && b && ...
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+// parseUnary parses a negation, a parenthesized expression, a comparison or a field alone
+func (p *exprParser) parseUnary() (exprNode, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenNot:
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{operand}, nil
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, exprError(closing.pos, "expected ) instead of %q", closing.text)
+		}
+		return inner, nil
+	case tokenWord:
+	default:
+		return nil, exprError(token.pos, "expected a field instead of %q", token.text)
+	}
+
+	get, typ, err := p.resolveField(token)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenOp {
+		return truthExpr{get}, nil
+	}
+	op := p.next()
+	literal := p.next()
+	if literal.kind != tokenWord && literal.kind != tokenString {
+		return nil, exprError(literal.pos, "expected a value after %s instead of %q", op.text, literal.text)
+	}
+	if literal.kind == tokenWord && isEdgeFieldName(literal.text) {
+		other, otherTyp, err := p.resolveField(literal)
+		if err != nil {
+			return nil, err
+		}
+		return compileFieldComparison(token.text, typ, get, op, literal.text, otherTyp, other)
+	}
+	return compileComparison(token.text, typ, get, op, literal)
+}
+
+// isEdgeFieldName reports whether a bare word names a field of an edge, which is compared
+// as a field rather than as a value, e.g. in source.package!=target.package
+func isEdgeFieldName(word string) bool {
+	return strings.HasPrefix(word, "source.") || strings.HasPrefix(word, "target.") || strings.HasPrefix(word, "edge.")
+}
+
+// resolveField returns the accessor of a field and the type of its values
+func (p *exprParser) resolveField(token exprToken) (accessor, valueType, error) {
+	name := token.text
+	if key, ok := strings.CutPrefix(name, "edge.attr."); ok {
+		p.edgeFields = true
+		return func(env *exprEnv, s exprSubject) any {
+			return env.g.EdgeAttrsOf(s.source.ID, s.target.ID)[key]
+		}, anyValue, nil
+	}
+	if name == "edge.kind" {
+		p.edgeFields = true
+		return func(env *exprEnv, s exprSubject) any {
+			kinds := env.g.EdgeKindsOf(s.source.ID, s.target.ID)
+			values := make([]string, len(kinds))
+			for i, kind := range kinds {
+				values[i] = string(kind)
+			}
+			return values
+		}, listValue, nil
+	}
+
+	endpoint := func(s exprSubject) *Node { return s.node }
+	if rest, ok := strings.CutPrefix(name, "source."); ok {
+		name, endpoint = rest, func(s exprSubject) *Node { return s.source }
+		p.edgeFields = true
+	} else if rest, ok := strings.CutPrefix(name, "target."); ok {
+		name, endpoint = rest, func(s exprSubject) *Node { return s.target }
+		p.edgeFields = true
+	} else {
+		p.nodeFields = true
+	}
+
+	if key, ok := strings.CutPrefix(name, "attr."); ok {
+		return func(_ *exprEnv, s exprSubject) any {
+			value, _ := endpoint(s).Attr(key)
+			return value
+		}, anyValue, nil
+	}
+	field, known := nodeFields[name]
+	if !known {
+		return nil, 0, exprError(token.pos, "unknown field %q", token.text)
+	}
+	return func(env *exprEnv, s exprSubject) any {
+		return field.get(env, endpoint(s))
+	}, field.typ, nil
+}
+
+// compileFieldComparison checks that two fields can be compared with each other
+func compileFieldComparison(field string, typ valueType, get accessor, op exprToken, otherField string, otherTyp valueType, other accessor) (exprNode, error) {
+	e := fieldCompareExpr{left: get, right: other, op: op.text}
+	if e.op == "==" {
+		e.op = "="
+	}
+	ordering := e.op == "<" || e.op == "<=" || e.op == ">" || e.op == ">="
+	switch {
+	case e.op == "~" || e.op == "!~":
+		return nil, exprError(op.pos, "%s cannot be matched with the field %s", field, otherField)
+	case typ != anyValue && otherTyp != anyValue && typ != otherTyp:
+		return nil, exprError(op.pos, "%s and %s hold different types of values", field, otherField)
+	case ordering && (typ != numberValue && typ != anyValue || otherTyp != numberValue && otherTyp != anyValue):
+		return nil, exprError(op.pos, "%s and %s cannot be compared with %s", field, otherField, op.text)
+	}
+	return e, nil
+}
+
+// compileComparison checks that a comparison suits the field's type, and parses its literal
+func compileComparison(field string, typ valueType, get accessor, op, literal exprToken) (exprNode, error) {
+	e := compareExpr{get: get, op: op.text, text: literal.text}
+	if e.op == "==" {
+		e.op = "="
+	}
+	if number, err := strconv.ParseFloat(literal.text, 64); err == nil && literal.kind == tokenWord {
+		e.number, e.isNum = number, true
+	}
+	if literal.kind == tokenWord && (literal.text == "true" || literal.text == "false") {
+		e.boolean, e.isBool = literal.text == "true", true
+	}
+
+	ordering := e.op == "<" || e.op == "<=" || e.op == ">" || e.op == ">="
+	matching := e.op == "~" || e.op == "!~"
+	switch {
+	case typ == numberValue && !e.isNum:
+		return nil, exprError(literal.pos, "%s is a number, not %q", field, literal.text)
+	case typ == numberValue && matching:
+		return nil, exprError(op.pos, "%s is a number and cannot be matched with %s", field, op.text)
+	case typ == boolValue && (!e.isBool || ordering || matching):
+		return nil, exprError(op.pos, "%s is true or false, and can only be compared with = or != true or false", field)
+	case (typ == stringValue || typ == listValue) && ordering:
+		return nil, exprError(op.pos, "%s is text and cannot be compared with %s", field, op.text)
+	}
+	if matching {
+		pattern, err := regexp.Compile(literal.text)
+		if err != nil {
+			return nil, exprError(literal.pos, "invalid regular expression: %v", err)
+		}
+		e.pattern = pattern
+	}
+	return e, nil
+}
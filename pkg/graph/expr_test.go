@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exprTestGraph is a small graph with a hub, types, an external node and custom attributes
+func exprTestGraph() *DependencyGraph {
+	g := NewDependencyGraph()
+	nodes := []*Node{
+		{ID: "app/internal/db::Open", Name: "Open", Kind: KindFunction, Package: "app/internal/db", Path: "internal/db/db.go", Lines: 40, ReturnsError: true},
+		{ID: "app/internal/db::Conn", Name: "Conn", Kind: KindStruct, Package: "app/internal/db", Path: "internal/db/db.go"},
+		{ID: "app/internal/db::(*Conn).close", Name: "(*Conn).close", Kind: KindMethod, Package: "app/internal/db", Path: "internal/db/db.go", Owner: "@db @infra"},
+		{ID: "app/api::Serve", Name: "Serve", Kind: KindFunction, Package: "app/api", Path: "api/api.go", Lines: 12, Complexity: 4},
+		{ID: "app/api::Health", Name: "Health", Kind: KindFunction, Package: "app/api", Path: "api/health.go", Lines: 3},
+		{ID: "fmt::Println", Name: "Println", Kind: KindFunction, Package: "fmt", External: true},
+	}
+	for _, node := range nodes {
+		g.Nodes[node.ID] = node
+	}
+	g.Nodes["app/api::Serve"].SetAttr("layer", "http")
+	g.Nodes["app/internal/db::Open"].SetAttr("calls", 7)
+
+	g.AddEdge("app/api::Serve", "app/internal/db::Open", EdgeBody)
+	g.AddEdge("app/api::Health", "app/internal/db::Open", EdgeBody)
+	g.AddEdge("app/api::Serve", "fmt::Println", EdgeBody)
+	g.AddEdge("app/internal/db::Open", "app/internal/db::Conn", EdgeResult)
+	g.AddEdge("app/internal/db::(*Conn).close", "app/internal/db::Conn", EdgeReceiver)
+	g.SetEdgeAttr("app/api::Serve", "app/internal/db::Open", "weight", 2.5)
+	return g
+}
+
+func TestMatchNodes(t *testing.T) {
+	g := exprTestGraph()
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`kind=function && path~"^internal/" && fanin>1`, []string{"app/internal/db::Open"}},
+		{`kind == function && !external && lines < 20`, []string{"app/api::Health", "app/api::Serve"}},
+		{`package=app/api || kind=struct`, []string{"app/api::Health", "app/api::Serve", "app/internal/db::Conn"}},
+		{`(kind=method || kind=struct) && !exported`, []string{"app/internal/db::(*Conn).close"}},
+		{`owner=@infra`, []string{"app/internal/db::(*Conn).close"}},
+		{`returns_error`, []string{"app/internal/db::Open"}},
+		{`complexity`, []string{"app/api::Serve"}},
+		{`fanout>=2`, []string{"app/api::Serve"}},
+		{`attr.layer=http`, []string{"app/api::Serve"}},
+		{`attr.calls>5`, []string{"app/internal/db::Open"}},
+		{`attr.layer!=http && package!~"^app/"`, []string{"fmt::Println"}},
+		{`name~"(?i)^s"`, []string{"app/api::Serve"}},
+		{`id="app/internal/db::(*Conn).close"`, []string{"app/internal/db::(*Conn).close"}},
+		{`edge.kind=receiver`, []string{"app/internal/db::(*Conn).close", "app/internal/db::Conn"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpr(%q) error: %v", tt.expr, err)
+			}
+			if got := g.MatchNodes(e); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchNodes(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchEdges(t *testing.T) {
+	g := exprTestGraph()
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`edge.kind=body && target.external`, []string{"app/api::Serve -> fmt::Println"}},
+		{`edge.kind=body && source.package!=target.package && !target.external`, []string{"app/api::Health -> app/internal/db::Open", "app/api::Serve -> app/internal/db::Open"}},
+		{`source.package=target.package`, []string{"app/internal/db::(*Conn).close -> app/internal/db::Conn", "app/internal/db::Open -> app/internal/db::Conn"}},
+		{`source.lines>target.lines`, []string{"app/api::Serve -> fmt::Println", "app/internal/db::Open -> app/internal/db::Conn"}},
+		{`edge.attr.weight>2`, []string{"app/api::Serve -> app/internal/db::Open"}},
+		{`edge.kind~"^(receiver|result)$"`, []string{"app/internal/db::(*Conn).close -> app/internal/db::Conn", "app/internal/db::Open -> app/internal/db::Conn"}},
+		{`package=app/internal/db`, []string{"app/internal/db::(*Conn).close -> app/internal/db::Conn", "app/internal/db::Open -> app/internal/db::Conn"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpr(%q) error: %v", tt.expr, err)
+			}
+			var got []string
+			for _, edge := range g.MatchEdges(e) {
+				got = append(got, edge.Source+" -> "+edge.Target)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchEdges(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	g := exprTestGraph()
+
+	e, _ := ParseExpr(`package=app/internal/db`)
+	selected := g.Select(e)
+	if len(selected.Nodes) != 3 || selected.CountEdges() != 2 {
+		t.Errorf("Select(%s) = %d nodes, %d edges, want 3 and 2", e, len(selected.Nodes), selected.CountEdges())
+	}
+
+	// Edges between the selected nodes that do not match are left out
+	e, _ = ParseExpr(`edge.kind=body`)
+	selected = g.Select(e)
+	if len(selected.Nodes) != 4 || selected.CountEdges() != 3 {
+		t.Errorf("Select(%s) = %d nodes, %d edges, want 4 and 3", e, len(selected.Nodes), selected.CountEdges())
+	}
+	e, _ = ParseExpr(`edge.kind=result`)
+	selected = g.Select(e)
+	if !selected.HasEdge("app/internal/db::Open", "app/internal/db::Conn") || selected.CountEdges() != 1 {
+		t.Errorf("Select(%s) edges = %v, want only Open -> Conn", e, selected.Edges)
+	}
+	if got := selected.EdgeKindsOf("app/internal/db::Open", "app/internal/db::Conn"); !reflect.DeepEqual(got, []EdgeKind{EdgeResult}) {
+		t.Errorf("Selected edge kinds = %v, want [result]", got)
+	}
+}
+
+func TestParseExpr_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		message string
+	}{
+		{``, "expected a field"},
+		{`kind=`, "expected a value"},
+		{`size>3`, `unknown field "size"`},
+		{`fanin>many`, "is a number"},
+		{`lines~"1"`, "is a number"},
+		{`external=yes`, "true or false"},
+		{`name>b`, "cannot be compared"},
+		{`name~"("`, "invalid regular expression"},
+		{`(kind=function`, "expected )"},
+		{`kind=function)`, `unexpected ")"`},
+		{`name="open`, "unterminated string"},
+		{`kind=function && edge.kind=body`, "mixes node fields with edge fields"},
+		{`source.package~target.package`, "cannot be matched"},
+		{`source.lines=target.package`, "different types"},
+		{`source.name<target.name`, "cannot be compared"},
+		{`kind=function & lines>3`, `column 15: unexpected "&"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseExpr(tt.expr)
+			if err == nil {
+				t.Fatalf("ParseExpr(%q) succeeded", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("ParseExpr(%q) error = %q, want it to contain %q", tt.expr, err, tt.message)
+			}
+		})
+	}
+}
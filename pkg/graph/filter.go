@@ -0,0 +1,128 @@
+package graph
+
+import "slices"
+
+// TopSubgraphs returns a graph with only the n highest-scoring subgraphs, which must have been
+// computed. Their nodes, edges, IDs and scores are kept as is. A graph with at most n
+// subgraphs, or n <= 0, is returned unchanged.
+func (g *DependencyGraph) TopSubgraphs(n int) *DependencyGraph {
+	if n <= 0 || len(g.Subgraphs) <= n {
+		return g
+	}
+
+	// Subgraphs are sorted by score, and their IDs follow that order
+	var nodeIDs []string
+	for _, subgraph := range g.Subgraphs[:n] {
+		nodeIDs = append(nodeIDs, subgraph.NodeIDs...)
+	}
+	top := g.Extract(nodeIDs)
+	top.Subgraphs = make([]Subgraph, n)
+	copy(top.Subgraphs, g.Subgraphs[:n])
+	return top
+}
+
+// PruneIsolated returns a graph without the isolated nodes, which have no edge to or from a
+// node of the graph, and without the subgraphs left empty. The remaining subgraphs keep their
+// IDs and scores. A graph without isolated nodes is returned unchanged.
+func (g *DependencyGraph) PruneIsolated() *DependencyGraph {
+	connected := make(map[string]bool, len(g.Nodes))
+	for sourceID, targets := range g.Edges {
+		if _, exists := g.Nodes[sourceID]; !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if _, exists := g.Nodes[targetID]; exists {
+				connected[sourceID] = true
+				connected[targetID] = true
+			}
+		}
+	}
+
+	nodeIDs := make([]string, 0, len(g.Nodes))
+	for nodeID := range g.Nodes {
+		if connected[nodeID] {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	if len(nodeIDs) == len(g.Nodes) {
+		return g
+	}
+
+	pruned := g.Extract(nodeIDs)
+	pruned.Subgraphs = make([]Subgraph, 0, len(g.Subgraphs))
+	for _, subgraph := range g.Subgraphs {
+		if slices.ContainsFunc(subgraph.NodeIDs, func(nodeID string) bool { return connected[nodeID] }) {
+			pruned.Subgraphs = append(pruned.Subgraphs, subgraph)
+		}
+	}
+	return pruned
+}
+
+// SubgraphForPackages returns the nodes of the packages matching any of the patterns (see
+// MatchPackagePattern) and the edges between them. With includeBoundary, the nodes one edge
+// away from them, in either direction, are kept too with the edges to and from the matching
+// nodes; they are marked External, with a boundary attribute. Subgraphs are not copied, as
+// for Extract.
+func (g *DependencyGraph) SubgraphForPackages(patterns []string, includeBoundary bool) *DependencyGraph {
+	inside := make(map[string]bool)
+	nodeIDs := make([]string, 0, len(g.Nodes))
+	for nodeID, node := range g.Nodes {
+		if slices.ContainsFunc(patterns, func(pattern string) bool { return MatchPackagePattern(pattern, node.Package) }) {
+			inside[nodeID] = true
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	if !includeBoundary {
+		return g.Extract(nodeIDs)
+	}
+
+	boundary := make(map[string]bool)
+	for sourceID, targets := range g.Edges {
+		if _, exists := g.Nodes[sourceID]; !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if _, exists := g.Nodes[targetID]; !exists {
+				continue
+			}
+			if inside[sourceID] && !inside[targetID] {
+				boundary[targetID] = true
+			} else if inside[targetID] && !inside[sourceID] {
+				boundary[sourceID] = true
+			}
+		}
+	}
+	for nodeID := range boundary {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+
+	sub := g.Extract(nodeIDs)
+	for nodeID := range boundary {
+		sub.Nodes[nodeID].External = true
+		sub.Nodes[nodeID].SetAttr("boundary", true)
+	}
+	// Extract keeps the edges between boundary nodes, which are outside the packages
+	sub.retainEdges(func(sourceID, targetID string) bool {
+		return inside[sourceID] || inside[targetID]
+	})
+	return sub
+}
+
+// retainEdges removes the edges for which keep returns false, with their kinds and attributes
+func (g *DependencyGraph) retainEdges(keep func(sourceID, targetID string) bool) {
+	for sourceID, targets := range g.Edges {
+		targets = slices.DeleteFunc(targets, func(targetID string) bool {
+			if keep(sourceID, targetID) {
+				return false
+			}
+			delete(g.EdgeKinds[sourceID], targetID)
+			delete(g.EdgeAttrs[sourceID], targetID)
+			return true
+		})
+		if len(targets) == 0 {
+			delete(g.Edges, sourceID)
+		} else {
+			g.Edges[sourceID] = targets
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTopSubgraphs(t *testing.T) {
+	g := newLimitTestGraph()
+	g.Nodes["d::D1"] = &Node{ID: "d::D1", Package: "d"}
+	g.ComputeSubgraphs()
+
+	top := g.TopSubgraphs(1)
+	if len(top.Subgraphs) != 1 || !reflect.DeepEqual(top.Subgraphs[0], g.Subgraphs[0]) {
+		t.Errorf("Expected the first subgraph to be kept as is, got %v", top.Subgraphs)
+	}
+	if len(top.Nodes) != 5 || top.CountEdges() != 6 {
+		t.Errorf("Expected the 5 nodes and 6 edges of packages a and b, got %d and %d", len(top.Nodes), top.CountEdges())
+	}
+	if top.Nodes["c::C1"] != nil || top.Nodes["d::D1"] != nil {
+		t.Errorf("Expected the isolated nodes to be dropped")
+	}
+	if top.Nodes["a::A1"].SubgraphID != 0 {
+		t.Errorf("Expected node subgraph IDs to be kept")
+	}
+
+	for _, n := range []int{0, 3, 10} {
+		if g.TopSubgraphs(n) != g {
+			t.Errorf("TopSubgraphs(%d) changed a graph with 3 subgraphs", n)
+		}
+	}
+}
+
+func TestPruneIsolated(t *testing.T) {
+	g := newLimitTestGraph()
+	g.Nodes["d::D1"] = &Node{ID: "d::D1", Package: "d"}
+	g.Nodes["e::E1"] = &Node{ID: "e::E1", Package: "e"}
+	g.AddEdge("d::D1", "ext.X") // Edges to IDs that are not nodes do not count
+	g.ComputeSubgraphs()
+
+	pruned := g.PruneIsolated()
+	if len(pruned.Nodes) != 5 || pruned.Nodes["c::C1"] != nil || pruned.Nodes["d::D1"] != nil {
+		t.Errorf("Expected c::C1, d::D1 and e::E1 to be pruned, got %d nodes", len(pruned.Nodes))
+	}
+	if pruned.CountEdges() != 6 {
+		t.Errorf("Expected the 6 edges between nodes to be kept, got %d", pruned.CountEdges())
+	}
+	if len(pruned.Subgraphs) != 1 {
+		t.Errorf("Expected only the subgraph of packages a and b to be kept, got %v", pruned.Subgraphs)
+	}
+	for _, subgraph := range pruned.Subgraphs {
+		if !reflect.DeepEqual(subgraph, *g.GetSubgraphByID(subgraph.ID)) {
+			t.Errorf("Expected subgraph %d to be kept as is", subgraph.ID)
+		}
+	}
+
+	if pruned.PruneIsolated() != pruned {
+		t.Errorf("Expected a graph without isolated nodes to be returned unchanged")
+	}
+}
+
+func TestSubgraphForPackages(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"app/api::Serve", "app/api/v2::Serve", "app/db::Open", "app/db::Conn", "app/cli::Main", "app/util::Log"} {
+		g.Nodes[id] = &Node{ID: id, Package: id[:strings.Index(id, "::")]}
+	}
+	g.AddEdge("app/cli::Main", "app/api::Serve")
+	g.AddEdge("app/api::Serve", "app/api/v2::Serve")
+	g.AddEdge("app/api/v2::Serve", "app/db::Open", EdgeBody)
+	g.AddEdge("app/db::Open", "app/db::Conn", EdgeResult)
+	g.AddEdge("app/cli::Main", "app/util::Log")
+	g.AddEdge("app/util::Log", "app/db::Conn")
+
+	sub := g.SubgraphForPackages([]string{"app/api/..."}, false)
+	if got := slices.Sorted(maps.Keys(sub.Nodes)); !reflect.DeepEqual(got, []string{"app/api/v2::Serve", "app/api::Serve"}) {
+		t.Errorf("Nodes = %v", got)
+	}
+	if sub.CountEdges() != 1 {
+		t.Errorf("Expected the edge within the packages only, got %v", sub.Edges)
+	}
+
+	sub = g.SubgraphForPackages([]string{"app/api", "app/api/v2"}, true)
+	if got := slices.Sorted(maps.Keys(sub.Nodes)); !reflect.DeepEqual(got, []string{"app/api/v2::Serve", "app/api::Serve", "app/cli::Main", "app/db::Open"}) {
+		t.Errorf("Nodes with boundary = %v", got)
+	}
+	main := sub.Nodes["app/cli::Main"]
+	if boundary, _ := main.Attr("boundary"); !main.External || boundary != true {
+		t.Errorf("Expected the boundary nodes to be marked external, got %+v", main)
+	}
+	if sub.Nodes["app/api::Serve"].External || g.Nodes["app/cli::Main"].External {
+		t.Errorf("Expected the package nodes and the original graph to be left as is")
+	}
+	if sub.CountEdges() != 3 || !reflect.DeepEqual(sub.EdgeKindsOf("app/api/v2::Serve", "app/db::Open"), []EdgeKind{EdgeBody}) {
+		t.Errorf("Expected the 3 edges touching the packages, got %v", sub.Edges)
+	}
+
+	// Edges between boundary nodes are left out
+	sub = g.SubgraphForPackages([]string{"app/util"}, true)
+	if sub.CountEdges() != 2 || sub.HasEdge("app/cli::Main", "app/api::Serve") {
+		t.Errorf("Expected the 2 edges of Log only, got %v", sub.Edges)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/types"
 	"path/filepath"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -19,13 +20,34 @@ func CreateNode(pkg *packages.Package, obj types.Object, name string, kind NodeK
 	// hashed or cleaned if necessary. Here we use a composite key.
 	id := fmt.Sprintf("%s::%s", pkg.PkgPath, name)
 
+	// Declarations in _test.go files are never part of the documentation, even when exported
+	var docURL string
+	if IsPublicPackagePath(pkg.PkgPath) && !strings.HasSuffix(pos.Filename, "_test.go") {
+		docURL = PkgGoDevURL(pkg.PkgPath, name)
+	}
+
+	// Path relative to the module root, for matching against version control data
+	var path, module string
+	if pkg.Module != nil {
+		module = pkg.Module.Path
+	}
+	if pkg.Module != nil && pkg.Module.Dir != "" {
+		if rel, err := filepath.Rel(pkg.Module.Dir, pos.Filename); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+	}
+
 	return &Node{
-		ID:        id,
-		Name:      name,
-		Kind:      kind,
-		Package:   pkg.PkgPath,
-		File:      filepath.Base(pos.Filename),
-		Line:      pos.Line,
-		Signature: signature,
+		ID:          id,
+		Name:        name,
+		Kind:        kind,
+		Package:     pkg.PkgPath,
+		PackageName: pkg.Name,
+		Module:      module,
+		File:        filepath.Base(pos.Filename),
+		Path:        path,
+		Line:        pos.Line,
+		Signature:   signature,
+		DocURL:      docURL,
 	}
 }
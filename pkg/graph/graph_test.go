@@ -236,3 +236,30 @@ func Test_CreateNode_SignaturePreserved(t *testing.T) {
 		})
 	}
 }
+
+func Test_CreateNode_DocURL(t *testing.T) {
+	fset := token.NewFileSet()
+	source := fset.AddFile("db.go", -1, 100)
+	testSource := fset.AddFile("db_test.go", -1, 100)
+
+	tests := []struct {
+		name     string
+		pkgPath  string
+		pos      token.Pos
+		expected string
+	}{
+		{name: "exported symbol", pkgPath: "example.com/db", pos: source.Pos(10), expected: "https://pkg.go.dev/example.com/db#Open"},
+		{name: "module without a domain", pkgPath: "db", pos: source.Pos(10), expected: ""},
+		{name: "test file", pkgPath: "example.com/db", pos: testSource.Pos(10), expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := types.NewFunc(tt.pos, types.NewPackage(tt.pkgPath, "db"), "Open", types.NewSignatureType(nil, nil, nil, nil, nil, false))
+			node := CreateNode(&packages.Package{PkgPath: tt.pkgPath, Fset: fset}, obj, "Open", KindFunction, "func()")
+			if node.DocURL != tt.expected {
+				t.Errorf("DocURL = %q, want %q", node.DocURL, tt.expected)
+			}
+		})
+	}
+}
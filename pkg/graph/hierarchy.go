@@ -0,0 +1,111 @@
+package graph
+
+import (
+	"path"
+	"strings"
+)
+
+// ContainerLevel is a level of the containment hierarchy above the symbols, see Hierarchy
+type ContainerLevel string
+
+// Container levels, from the outermost
+const (
+	LevelModule    ContainerLevel = "module"    // A module of the analyzed project
+	LevelDirectory ContainerLevel = "directory" // A path segment of the package tree, between a module and its packages
+	LevelPackage   ContainerLevel = "package"
+	LevelFile      ContainerLevel = "file"
+)
+
+// Container is a module, directory, package or file of the containment hierarchy
+type Container struct {
+	ID     string // The level's prefix ("module:", "dir:", "pkg:" or "file:") and the path
+	Level  ContainerLevel
+	Name   string // Path of the module, directory or package, base name of the file
+	Parent string // ID of the enclosing container, "" at the top
+}
+
+// Hierarchy is the containment tree of a graph: module > directories > package > file > type >
+// symbol. Packages are nested in the directory of their parent path below their module, so
+// that example.com/app/pkg/graph is in example.com/app/pkg, itself in the module
+// example.com/app; packages without a known module, such as external ones, are at the top.
+// Types are the nodes of the graph themselves, holding the methods of their receiver.
+type Hierarchy struct {
+	Containers map[string]Container // Containers by ID
+	Parents    map[string]string    // ID of the innermost container of each node, or of its receiver type node
+}
+
+// Hierarchy returns the containment tree of the graph's nodes. Methods are nested in their
+// receiver type when it is in the graph and groupByType is set, in their file otherwise;
+// nodes without a file are in their package, and package nodes in the parent of their package.
+func (g *DependencyGraph) Hierarchy(groupByType bool) *Hierarchy {
+	h := &Hierarchy{
+		Containers: make(map[string]Container),
+		Parents:    make(map[string]string, len(g.Nodes)),
+	}
+	for nodeID, node := range g.Nodes {
+		if node.Kind == KindPackage {
+			h.Parents[nodeID] = h.addPackageParent(node.Package, node.Module)
+			continue
+		}
+		if groupByType && node.Kind == KindMethod {
+			if _, exists := g.Nodes[node.ReceiverID()]; exists {
+				h.Parents[nodeID] = node.ReceiverID()
+				continue
+			}
+		}
+		parent := h.addPackage(node.Package, node.Module)
+		if node.File != "" {
+			parent = h.add(Container{ID: "file:" + node.Package + "/" + node.File, Level: LevelFile, Name: node.File, Parent: parent})
+		}
+		h.Parents[nodeID] = parent
+	}
+	return h
+}
+
+// addPackage adds the container of a package with its directories and module, returning its ID
+func (h *Hierarchy) addPackage(pkgPath, module string) string {
+	return h.add(Container{ID: "pkg:" + pkgPath, Level: LevelPackage, Name: pkgPath, Parent: h.addPackageParent(pkgPath, module)})
+}
+
+// addPackageParent adds the containers enclosing a package, returning the ID of the innermost:
+// the directory of its parent path, or its module for the module's root and its direct children
+func (h *Hierarchy) addPackageParent(pkgPath, module string) string {
+	if module == "" || (pkgPath != module && !strings.HasPrefix(pkgPath, module+"/")) {
+		return ""
+	}
+	parent := h.add(Container{ID: "module:" + module, Level: LevelModule, Name: module})
+	if pkgPath == module {
+		return parent
+	}
+	segments := strings.Split(strings.TrimPrefix(pkgPath, module+"/"), "/")
+	dir := module
+	for _, segment := range segments[:len(segments)-1] {
+		dir = path.Join(dir, segment)
+		parent = h.add(Container{ID: "dir:" + dir, Level: LevelDirectory, Name: dir, Parent: parent})
+	}
+	return parent
+}
+
+// add adds a container unless it exists, returning its ID
+func (h *Hierarchy) add(container Container) string {
+	if _, exists := h.Containers[container.ID]; !exists {
+		h.Containers[container.ID] = container
+	}
+	return container.ID
+}
+
+// Ancestors returns the IDs of the containers and type node enclosing a node, innermost first
+func (h *Hierarchy) Ancestors(nodeID string) []string {
+	ancestors := make([]string, 0)
+	current := h.Parents[nodeID]
+	// A receiver type node has its own parent, the file declaring it
+	if _, isNode := h.Parents[current]; isNode {
+		ancestors = append(ancestors, current)
+		current = h.Parents[current]
+	}
+	for current != "" {
+		ancestors = append(ancestors, current)
+		current = h.Containers[current].Parent
+	}
+	return ancestors
+}
@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHierarchy(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["example.com/app::main"] = &Node{ID: "example.com/app::main", Name: "main", Kind: KindFunction, Package: "example.com/app", Module: "example.com/app", File: "main.go"}
+	g.Nodes["example.com/app/pkg/graph::Graph"] = &Node{ID: "example.com/app/pkg/graph::Graph", Name: "Graph", Kind: KindStruct, Package: "example.com/app/pkg/graph", Module: "example.com/app", File: "graph.go"}
+	g.Nodes["example.com/app/pkg/graph::Graph.Walk"] = &Node{ID: "example.com/app/pkg/graph::Graph.Walk", Name: "Graph.Walk", Kind: KindMethod, Package: "example.com/app/pkg/graph", Module: "example.com/app", File: "walk.go", Receiver: "example.com/app/pkg/graph::Graph"}
+	g.Nodes["example.com/lib::Encode"] = &Node{ID: "example.com/lib::Encode", Name: "Encode", Kind: KindFunction, Package: "example.com/lib", External: true}
+	g.Nodes["example.com/app/pkg/report"] = &Node{ID: "example.com/app/pkg/report", Name: "example.com/app/pkg/report", Kind: KindPackage, Package: "example.com/app/pkg/report", Module: "example.com/app"}
+
+	h := g.Hierarchy(true)
+	tests := map[string][]string{
+		"example.com/app::main": {"file:example.com/app/main.go", "pkg:example.com/app", "module:example.com/app"},
+		"example.com/app/pkg/graph::Graph": {
+			"file:example.com/app/pkg/graph/graph.go", "pkg:example.com/app/pkg/graph", "dir:example.com/app/pkg", "module:example.com/app",
+		},
+		"example.com/app/pkg/graph::Graph.Walk": {
+			"example.com/app/pkg/graph::Graph", "file:example.com/app/pkg/graph/graph.go", "pkg:example.com/app/pkg/graph", "dir:example.com/app/pkg", "module:example.com/app",
+		},
+		"example.com/lib::Encode":    {"pkg:example.com/lib"},
+		"example.com/app/pkg/report": {"dir:example.com/app/pkg", "module:example.com/app"},
+	}
+	for nodeID, want := range tests {
+		if got := h.Ancestors(nodeID); !slices.Equal(got, want) {
+			t.Errorf("Ancestors(%s) = %v, want %v", nodeID, got, want)
+		}
+	}
+	if file := h.Containers["file:example.com/app/pkg/graph/graph.go"]; file.Level != LevelFile || file.Name != "graph.go" {
+		t.Errorf("Expected the file container to be named after the file, got %+v", file)
+	}
+	if dir := h.Containers["dir:example.com/app/pkg"]; dir.Level != LevelDirectory || dir.Parent != "module:example.com/app" {
+		t.Errorf("Expected the directory to be in the module, got %+v", dir)
+	}
+
+	// Without grouping by type, methods are in their own file
+	if got := g.Hierarchy(false).Parents["example.com/app/pkg/graph::Graph.Walk"]; got != "file:example.com/app/pkg/graph/walk.go" {
+		t.Errorf("Expected the method to be in its file, got %s", got)
+	}
+}
@@ -0,0 +1,101 @@
+package graph
+
+import "sort"
+
+// nodeIndex is an integer view of the graph for the algorithms that visit all of it. Node
+// IDs are numbered, and the edges become lists of indices packed in one array. On graphs
+// with millions of edges, this is several times smaller than maps and slices keyed by ID.
+type nodeIndex struct {
+	ids      []string         // ID of each index: the nodes in sorted order, then the dangling edge ends
+	nodes    int32            // Number of indices that are nodes of the graph
+	position map[string]int32 // Index of each ID
+	offsets  []int32          // The targets of index i are targets[offsets[i]:offsets[i+1]]
+	targets  []int32
+}
+
+// newNodeIndex numbers the nodes of the graph and the ends of its edges that are not nodes
+func (g *DependencyGraph) newNodeIndex() *nodeIndex {
+	ids := make([]string, 0, len(g.Nodes))
+	for nodeID := range g.Nodes {
+		ids = append(ids, nodeID)
+	}
+	sort.Strings(ids)
+
+	// Edges may start or end outside the graph's nodes
+	var dangling []string
+	seen := make(map[string]bool)
+	addDangling := func(id string) {
+		if _, exists := g.Nodes[id]; !exists && !seen[id] {
+			seen[id] = true
+			dangling = append(dangling, id)
+		}
+	}
+	edgeCount := 0
+	for sourceID, targets := range g.Edges {
+		addDangling(sourceID)
+		for _, targetID := range targets {
+			addDangling(targetID)
+		}
+		edgeCount += len(targets)
+	}
+	sort.Strings(dangling)
+
+	x := &nodeIndex{
+		ids:      append(ids, dangling...),
+		nodes:    int32(len(ids)),
+		position: make(map[string]int32, len(ids)+len(dangling)),
+	}
+	for i, id := range x.ids {
+		x.position[id] = int32(i)
+	}
+
+	x.offsets = make([]int32, len(x.ids)+1)
+	x.targets = make([]int32, 0, edgeCount)
+	for i, id := range x.ids {
+		x.offsets[i] = int32(len(x.targets))
+		for _, targetID := range g.Edges[id] {
+			x.targets = append(x.targets, x.position[targetID])
+		}
+	}
+	x.offsets[len(x.ids)] = int32(len(x.targets))
+	return x
+}
+
+// targetsOf returns the indices of the targets of an index
+func (x *nodeIndex) targetsOf(i int32) []int32 {
+	return x.targets[x.offsets[i]:x.offsets[i+1]]
+}
+
+// isNode reports whether an index is a node of the graph rather than a dangling edge end
+func (x *nodeIndex) isNode(i int32) bool {
+	return i < x.nodes
+}
+
+// undirected returns the neighbors of every index when edges are followed both ways, packed
+// like the targets
+func (x *nodeIndex) undirected() (offsets, neighbors []int32) {
+	degrees := make([]int32, len(x.ids)+1)
+	for source := range x.ids {
+		for _, target := range x.targetsOf(int32(source)) {
+			degrees[source]++
+			degrees[target]++
+		}
+	}
+
+	offsets = make([]int32, len(x.ids)+1)
+	for i := range x.ids {
+		offsets[i+1] = offsets[i] + degrees[i]
+	}
+	neighbors = make([]int32, offsets[len(x.ids)])
+	next := degrees[:len(x.ids)]
+	copy(next, offsets[:len(x.ids)])
+	for source := range x.ids {
+		for _, target := range x.targetsOf(int32(source)) {
+			neighbors[next[source]] = target
+			next[source]++
+			neighbors[next[target]] = int32(source)
+			next[target]++
+		}
+	}
+	return offsets, neighbors
+}
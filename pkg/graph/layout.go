@@ -0,0 +1,582 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Layout algorithms, see Layout
+const (
+	LayoutForce   = "force"   // Force-directed: springs along the edges, repulsion between all nodes
+	LayoutLayered = "layered" // Layered: dependencies below their dependents, cycles on one layer
+)
+
+// LayoutAlgorithms lists the algorithms of Layout
+var LayoutAlgorithms = []string{LayoutForce, LayoutLayered}
+
+// Position is a point of a layout, in pixels with y growing downwards like a browser's
+// coordinates. Only the relative positions matter, the viewers fit the layout to the screen.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Parameters of the force-directed layout, those of d3-force's defaults
+const (
+	forceIterations    = 300   // Ticks of the simulation, alpha decays from 1 to forceAlphaMin
+	forceLargeGraph    = 5000  // Nodes above which the simulation is shortened, keeping the number of node ticks
+	forceMinIterations = 60    // Ticks of the simulation of the largest graphs
+	forceAlphaMin      = 0.001 // Alpha of the last tick
+	forceLinkDistance  = 30.0  // Rest length of the edge springs
+	forceCharge        = -30.0 // Repulsion of every node, negative to push apart
+	forceTheta2        = 0.81  // Squared Barnes-Hut accuracy: cells seen under a smaller ratio are approximated
+	forceGravity       = 0.02  // Pull towards the origin, so that disconnected parts stay in view
+	forceVelocityKeep  = 0.6   // Velocity kept from one tick to the next
+)
+
+// Parameters of the layered layout
+const (
+	layeredNodeSpacing  = 60.0  // Horizontal distance between neighbors on a layer
+	layeredLayerSpacing = 120.0 // Vertical distance between layers
+	layeredSweeps       = 8     // Barycenter sweeps reducing the edge crossings
+)
+
+// Layout computes a position for every node of the graph with the given algorithm, so that
+// viewers can draw large graphs at once instead of simulating them in the browser. The layout
+// is deterministic: the same graph gets the same positions on every run. The nodes are copied
+// before their positions are set, so that graphs sharing them with this one are not changed.
+//
+// Previous positions, by node ID, such as those of the last run (see Positions), are kept:
+// the nodes that have one stay in place so that successive renders keep a stable mental map,
+// and only the other nodes are laid out, starting next to their neighbors and settled by the
+// force simulation whatever the algorithm (see incrementalLayout). Without previous positions
+// of any of the nodes, the graph is laid out from scratch.
+func (g *DependencyGraph) Layout(algorithm string, previous map[string]Position) error {
+	if !slices.Contains(LayoutAlgorithms, algorithm) {
+		return fmt.Errorf("unknown layout %q, expected one of %s", algorithm, strings.Join(LayoutAlgorithms, ", "))
+	}
+	x := g.newNodeIndex()
+
+	positions := make([]Position, x.nodes)
+	pinned := make([]bool, x.nodes)
+	kept := 0
+	for i := range x.nodes {
+		if position, exists := previous[x.ids[i]]; exists {
+			positions[i] = position
+			pinned[i] = true
+			kept++
+		}
+	}
+	switch {
+	case kept > 0:
+		x.incrementalLayout(positions, pinned)
+	case algorithm == LayoutForce:
+		positions = x.forceLayout()
+	default:
+		positions = x.layeredLayout()
+	}
+
+	for i, position := range positions {
+		nodeID := x.ids[i]
+		node := *g.Nodes[nodeID]
+		node.Position = &Position{X: round2(position.X), Y: round2(position.Y)}
+		g.Nodes[nodeID] = &node
+	}
+	return nil
+}
+
+// Positions returns the positions of the laid out nodes of the graph by ID, to keep them in
+// the next layout
+func (g *DependencyGraph) Positions() map[string]Position {
+	positions := make(map[string]Position)
+	for nodeID, node := range g.Nodes {
+		if node.Position != nil {
+			positions[nodeID] = *node.Position
+		}
+	}
+	return positions
+}
+
+// round2 rounds a coordinate to hundredths of a pixel, keeping the written payloads short
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// nodeNeighbors returns the neighbors of every node when edges are followed both ways,
+// leaving out dangling edge ends and self-loops
+func (x *nodeIndex) nodeNeighbors() [][]int32 {
+	offsets, neighbors := x.undirected()
+	result := make([][]int32, x.nodes)
+	for i := range x.nodes {
+		for _, neighbor := range neighbors[offsets[i]:offsets[i+1]] {
+			if x.isNode(neighbor) && neighbor != i {
+				result[i] = append(result[i], neighbor)
+			}
+		}
+	}
+	return result
+}
+
+// forceLayout simulates the graph like d3-force does: springs pull the ends of every edge
+// to forceLinkDistance, every node repels every other one, approximated with a Barnes-Hut
+// quadtree, and a weak gravity keeps disconnected parts together. The nodes start on a
+// phyllotaxis spiral in ID order, so that the packages start out in clusters. Large graphs
+// cool down in fewer ticks, bounding the time spent on them.
+func (x *nodeIndex) forceLayout() []Position {
+	positions := make([]Position, x.nodes)
+	for i := range positions {
+		positions[i] = spiral(Position{}, 10, i)
+	}
+	x.simulate(positions, nil, 1)
+	return positions
+}
+
+// spiral returns the i-th point of a phyllotaxis spiral around a center, spreading points
+// evenly at the given spacing
+func spiral(center Position, spacing float64, i int) Position {
+	radius := spacing * math.Sqrt(0.5+float64(i))
+	angle := float64(i) * math.Pi * (3 - math.Sqrt(5))
+	return Position{X: center.X + radius*math.Cos(angle), Y: center.Y + radius*math.Sin(angle)}
+}
+
+// simulate runs the force simulation on the positions from the given alpha, its temperature,
+// down to forceAlphaMin. Pinned nodes keep their positions, but still pull and push the others.
+func (x *nodeIndex) simulate(positions []Position, pinned []bool, alpha float64) {
+	n := int(x.nodes)
+	velocities := make([]Position, n)
+	moves := func(i int) bool {
+		return pinned == nil || !pinned[i]
+	}
+
+	// Each edge once, with d3's strength and bias: springs at hubs are weaker, and the end
+	// with fewer edges moves more
+	neighbors := x.nodeNeighbors()
+	type spring struct {
+		source, target int32
+		strength, bias float64
+	}
+	var springs []spring
+	for i := range x.nodes {
+		for _, j := range neighbors[i] {
+			if j <= i {
+				continue
+			}
+			si, sj := float64(len(neighbors[i])), float64(len(neighbors[j]))
+			springs = append(springs, spring{source: i, target: j, strength: 1 / min(si, sj), bias: si / (si + sj)})
+		}
+	}
+
+	iterations := forceIterations
+	if n > forceLargeGraph {
+		iterations = max(forceMinIterations, forceIterations*forceLargeGraph/n)
+	}
+	alphaDecay := 1 - math.Pow(forceAlphaMin/alpha, 1/float64(iterations))
+	tree := &quadtree{}
+	for range iterations {
+		alpha -= alpha * alphaDecay
+
+		for _, s := range springs {
+			source, target := &positions[s.source], &positions[s.target]
+			dx := target.X + velocities[s.target].X - source.X - velocities[s.source].X
+			dy := target.Y + velocities[s.target].Y - source.Y - velocities[s.source].Y
+			if dx == 0 && dy == 0 {
+				dx, dy = jiggle(int(s.source), int(s.target))
+			}
+			l := math.Sqrt(dx*dx + dy*dy)
+			l = (l - forceLinkDistance) / l * alpha * s.strength
+			dx, dy = dx*l, dy*l
+			velocities[s.target].X -= dx * s.bias
+			velocities[s.target].Y -= dy * s.bias
+			velocities[s.source].X += dx * (1 - s.bias)
+			velocities[s.source].Y += dy * (1 - s.bias)
+		}
+
+		tree.build(positions)
+		for i := range positions {
+			if !moves(i) {
+				continue
+			}
+			tree.repel(i, positions, &velocities[i], alpha)
+			velocities[i].X -= positions[i].X * forceGravity * alpha
+			velocities[i].Y -= positions[i].Y * forceGravity * alpha
+		}
+
+		for i := range positions {
+			if !moves(i) {
+				velocities[i] = Position{}
+				continue
+			}
+			velocities[i].X *= forceVelocityKeep
+			velocities[i].Y *= forceVelocityKeep
+			positions[i].X += velocities[i].X
+			positions[i].Y += velocities[i].Y
+		}
+	}
+}
+
+// incrementalAlpha is the temperature of the simulation settling the nodes new to a previous
+// layout: low enough that they stay next to the neighbors they start at
+const incrementalAlpha = 0.3
+
+// incrementalLayout lays out the nodes that are not pinned around the pinned ones. A new node
+// starts at the center of its neighbors already placed, nodes placed in turn so that chains of
+// new nodes grow out of the old ones, and the nodes without any placed neighbor start on a
+// spiral around the previous layout. A short simulation moving only the new nodes then
+// settles them, keeping them off the others.
+func (x *nodeIndex) incrementalLayout(positions []Position, pinned []bool) {
+	placed := slices.Clone(pinned)
+	moving := 0
+	for _, p := range pinned {
+		if !p {
+			moving++
+		}
+	}
+	if moving == 0 {
+		return
+	}
+
+	neighbors := x.nodeNeighbors()
+	for progress := true; progress; {
+		progress = false
+		for i := range x.nodes {
+			if placed[i] {
+				continue
+			}
+			var sum Position
+			count := 0
+			for _, neighbor := range neighbors[i] {
+				if placed[neighbor] {
+					sum.X += positions[neighbor].X
+					sum.Y += positions[neighbor].Y
+					count++
+				}
+			}
+			if count == 0 {
+				continue
+			}
+			// Off the center by a link length, so that the nodes of one neighbor fan out
+			center := Position{X: sum.X / float64(count), Y: sum.Y / float64(count)}
+			offset := spiral(Position{}, forceLinkDistance, int(i))
+			scale := forceLinkDistance / math.Hypot(offset.X, offset.Y)
+			positions[i] = Position{X: center.X + offset.X*scale, Y: center.Y + offset.Y*scale}
+			placed[i] = true
+			progress = true
+		}
+	}
+
+	// Disconnected new nodes go around the previous layout
+	var center Position
+	radius, count := 0.0, 0
+	for i := range x.nodes {
+		if pinned[i] {
+			center.X += positions[i].X
+			center.Y += positions[i].Y
+			count++
+		}
+	}
+	center.X /= float64(count)
+	center.Y /= float64(count)
+	for i := range x.nodes {
+		if pinned[i] {
+			radius = max(radius, math.Hypot(positions[i].X-center.X, positions[i].Y-center.Y))
+		}
+	}
+	k := 0
+	for i := range x.nodes {
+		if !placed[i] {
+			offset := spiral(Position{}, 10, k)
+			scale := (radius + forceLinkDistance + math.Hypot(offset.X, offset.Y)) / math.Hypot(offset.X, offset.Y)
+			positions[i] = Position{X: center.X + offset.X*scale, Y: center.Y + offset.Y*scale}
+			k++
+		}
+	}
+
+	x.simulate(positions, pinned, incrementalAlpha)
+}
+
+// jiggle separates two nodes at the same place by a tiny offset derived from their indices,
+// where d3 uses a random one, so that the layout stays deterministic
+func jiggle(i, j int) (float64, float64) {
+	angle := float64(i*31+j*17) * 0.618
+	return 1e-6 * math.Cos(angle), 1e-6 * math.Sin(angle)
+}
+
+// quadtreeMaxDepth bounds the depth of the quadtree: nodes closer than its smallest cell
+// share a leaf
+const quadtreeMaxDepth = 32
+
+// quadtree is the Barnes-Hut quadtree of the force-directed layout, rebuilt on every tick.
+// Its cells are kept in one slice, reused across ticks.
+type quadtree struct {
+	cells []quadCell
+	next  []int32 // Next node in the same leaf, -1 at the end
+	stack []int32 // Cells left to visit by repel
+}
+
+// quadCell is a square of the quadtree, with the number and center of mass of its nodes
+type quadCell struct {
+	x, y, size float64  // Top-left corner and side
+	children   [4]int32 // Indices of the child cells, 0 for none (the root is never a child)
+	first      int32    // First node of a leaf, -1 for none
+	count      float64  // Number of nodes in the cell
+	cx, cy     float64  // Center of mass
+	leaf       bool     // Holds nodes rather than cells
+}
+
+// build clears the tree and inserts all positions, then computes the centers of mass
+func (t *quadtree) build(positions []Position) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range positions {
+		minX, minY = min(minX, p.X), min(minY, p.Y)
+		maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+	}
+	t.cells = t.cells[:0]
+	if len(t.next) != len(positions) {
+		t.next = make([]int32, len(positions))
+	}
+	if len(positions) == 0 {
+		return
+	}
+	t.cells = append(t.cells, quadCell{x: minX, y: minY, size: max(maxX-minX, maxY-minY, 1), first: -1, leaf: true})
+	for i := range positions {
+		t.insert(0, int32(i), positions, 0)
+	}
+	t.accumulate(0, positions)
+}
+
+// insert adds a node to the cell, splitting leaves that already hold nodes
+func (t *quadtree) insert(cell int32, node int32, positions []Position, depth int) {
+	for {
+		c := &t.cells[cell]
+		if c.leaf {
+			if c.first < 0 || depth >= quadtreeMaxDepth {
+				t.next[node] = c.first
+				c.first = node
+				return
+			}
+			// Split the leaf: its nodes move down into the quadrants
+			moved := c.first
+			c.first = -1
+			c.leaf = false
+			for moved >= 0 {
+				following := t.next[moved]
+				t.insert(cell, moved, positions, depth)
+				moved = following
+			}
+			continue
+		}
+		half := c.size / 2
+		quadrant := 0
+		qx, qy := c.x, c.y
+		if positions[node].X >= c.x+half {
+			quadrant |= 1
+			qx += half
+		}
+		if positions[node].Y >= c.y+half {
+			quadrant |= 2
+			qy += half
+		}
+		if c.children[quadrant] == 0 {
+			t.cells = append(t.cells, quadCell{x: qx, y: qy, size: half, first: -1, leaf: true})
+			t.cells[cell].children[quadrant] = int32(len(t.cells) - 1)
+		}
+		cell = t.cells[cell].children[quadrant]
+		depth++
+	}
+}
+
+// accumulate computes the number and center of mass of the nodes of a cell and its children
+func (t *quadtree) accumulate(cell int32, positions []Position) {
+	c := &t.cells[cell]
+	var count, sx, sy float64
+	if c.leaf {
+		for node := c.first; node >= 0; node = t.next[node] {
+			count++
+			sx += positions[node].X
+			sy += positions[node].Y
+		}
+	} else {
+		for _, child := range c.children {
+			if child == 0 {
+				continue
+			}
+			t.accumulate(child, positions)
+			childCell := &t.cells[child]
+			count += childCell.count
+			sx += childCell.cx * childCell.count
+			sy += childCell.cy * childCell.count
+		}
+		c = &t.cells[cell]
+	}
+	c.count = count
+	if count > 0 {
+		c.cx, c.cy = sx/count, sy/count
+	}
+}
+
+// repel adds the repulsion of all other nodes on a node to its velocity
+func (t *quadtree) repel(node int, positions []Position, velocity *Position, alpha float64) {
+	p := positions[node]
+	var vx, vy float64
+
+	t.stack = t.stack[:0]
+	if len(t.cells) > 0 {
+		t.stack = append(t.stack, 0)
+	}
+	for len(t.stack) > 0 {
+		c := &t.cells[t.stack[len(t.stack)-1]]
+		t.stack = t.stack[:len(t.stack)-1]
+		if c.count == 0 {
+			continue
+		}
+		if !c.leaf {
+			dx, dy := c.cx-p.X, c.cy-p.Y
+			l2 := dx*dx + dy*dy
+			if c.size*c.size < forceTheta2*l2 {
+				strength := chargeStrength(l2, c.count*alpha)
+				vx += dx * strength
+				vy += dy * strength
+				continue
+			}
+			for _, child := range c.children {
+				if child != 0 {
+					t.stack = append(t.stack, child)
+				}
+			}
+			continue
+		}
+		for other := c.first; other >= 0; other = t.next[other] {
+			if int(other) == node {
+				continue
+			}
+			dx, dy := positions[other].X-p.X, positions[other].Y-p.Y
+			if dx == 0 && dy == 0 {
+				dx, dy = jiggle(node, int(other))
+			}
+			strength := chargeStrength(dx*dx+dy*dy, alpha)
+			vx += dx * strength
+			vy += dy * strength
+		}
+	}
+	velocity.X += vx
+	velocity.Y += vy
+}
+
+// chargeStrength is the factor of the offset to a mass at squared distance l2 in the
+// repulsion it exerts, with the distance bounded below like d3's distanceMin of 1
+func chargeStrength(l2, weight float64) float64 {
+	if l2 < 1 {
+		l2 = math.Sqrt(l2)
+	}
+	return forceCharge * weight / l2
+}
+
+// layeredLayout places the nodes on layers like a Sugiyama layout: every node is below the
+// nodes depending on it, the members of a cycle sharing a layer, and the order on each layer
+// is refined by sweeps moving the nodes to the barycenter of their neighbors on the layers
+// already placed, which reduces the edge crossings. The layers are centered on x = 0.
+func (x *nodeIndex) layeredLayout() []Position {
+	n := int(x.nodes)
+	components := x.nodeComponents()
+
+	// Tarjan's algorithm emits a component after the components it reaches, so visiting them
+	// backwards visits every component after its dependents
+	componentOf := make([]int, n)
+	for c, members := range components {
+		for _, member := range members {
+			componentOf[member] = c
+		}
+	}
+	layers := make([]int, len(components))
+	depth := 0
+	for c := len(components) - 1; c >= 0; c-- {
+		depth = max(depth, layers[c]+1)
+		for _, member := range components[c] {
+			for _, target := range x.targetsOf(member) {
+				if x.isNode(target) && componentOf[target] != c {
+					layers[componentOf[target]] = max(layers[componentOf[target]], layers[c]+1)
+				}
+			}
+		}
+	}
+
+	layerOf := make([]int, n)
+	rows := make([][]int32, depth)
+	for i := range x.nodes {
+		layerOf[i] = layers[componentOf[i]]
+		rows[layerOf[i]] = append(rows[layerOf[i]], i)
+	}
+
+	// Positions on the layers, relative to the layer's width so that wide and narrow layers
+	// pull each other evenly
+	rank := make([]float64, n)
+	setRanks := func(row []int32) {
+		for k, node := range row {
+			rank[node] = (float64(k) + 0.5) / float64(len(row))
+		}
+	}
+	for _, row := range rows {
+		setRanks(row)
+	}
+	neighbors := x.nodeNeighbors()
+	barycenters := make([]float64, n)
+	sweep := func(layer int, above bool) {
+		row := rows[layer]
+		for _, node := range row {
+			sum, count := 0.0, 0
+			for _, neighbor := range neighbors[node] {
+				if (above && layerOf[neighbor] < layer) || (!above && layerOf[neighbor] > layer) {
+					sum += rank[neighbor]
+					count++
+				}
+			}
+			barycenters[node] = rank[node]
+			if count > 0 {
+				barycenters[node] = sum / float64(count)
+			}
+		}
+		slices.SortStableFunc(row, func(a, b int32) int {
+			return cmp.Compare(barycenters[a], barycenters[b])
+		})
+		setRanks(row)
+	}
+	for s := range layeredSweeps {
+		if s%2 == 0 {
+			for layer := 1; layer < depth; layer++ {
+				sweep(layer, true)
+			}
+		} else {
+			for layer := depth - 2; layer >= 0; layer-- {
+				sweep(layer, false)
+			}
+		}
+	}
+
+	positions := make([]Position, n)
+	for layer, row := range rows {
+		for k, node := range row {
+			positions[node] = Position{
+				X: (float64(k) - float64(len(row)-1)/2) * layeredNodeSpacing,
+				Y: float64(layer) * layeredLayerSpacing,
+			}
+		}
+	}
+	return positions
+}
+
+// nodeComponents returns the strongly connected components of the nodes by index, every
+// component after the components it reaches
+func (x *nodeIndex) nodeComponents() [][]int32 {
+	components := x.stronglyConnectedComponents()
+	result := make([][]int32, len(components))
+	for c, members := range components {
+		for _, member := range members {
+			result[c] = append(result[c], x.position[member])
+		}
+	}
+	return result
+}
@@ -0,0 +1,179 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// newLayoutTestGraph returns two clusters of nodes connected by one edge, with a cycle
+// between b::B1 and b::B2 and an isolated node
+func newLayoutTestGraph() *DependencyGraph {
+	g := NewDependencyGraph()
+	for _, nodeID := range []string{"a::A1", "a::A2", "a::A3", "b::B1", "b::B2", "b::B3", "c::C"} {
+		g.Nodes[nodeID] = &Node{ID: nodeID}
+	}
+	g.AddEdge("a::A1", "a::A2")
+	g.AddEdge("a::A1", "a::A3")
+	g.AddEdge("a::A2", "a::A3")
+	g.AddEdge("a::A3", "b::B1")
+	g.AddEdge("b::B1", "b::B2")
+	g.AddEdge("b::B2", "b::B1")
+	g.AddEdge("b::B2", "b::B3")
+	g.AddEdge("b::B3", "missing::M")
+	return g
+}
+
+func distance(a, b *Node) float64 {
+	return math.Hypot(a.Position.X-b.Position.X, a.Position.Y-b.Position.Y)
+}
+
+func TestLayout_Force(t *testing.T) {
+	g := newLayoutTestGraph()
+	original := g.Nodes["a::A1"]
+	if err := g.Layout(LayoutForce, nil); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	for nodeID, node := range g.Nodes {
+		if node.Position == nil {
+			t.Fatalf("Node %s has no position", nodeID)
+		}
+		if math.IsNaN(node.Position.X) || math.IsNaN(node.Position.Y) {
+			t.Fatalf("Node %s has position %v", nodeID, *node.Position)
+		}
+	}
+	if original.Position != nil {
+		t.Error("Expected the layout to copy the nodes rather than change them")
+	}
+
+	// Springs keep the ends of edges closer than the nodes of different clusters
+	if near, far := distance(g.Nodes["a::A1"], g.Nodes["a::A2"]), distance(g.Nodes["a::A1"], g.Nodes["b::B3"]); near >= far {
+		t.Errorf("Expected a::A1 closer to a::A2 (%.1f) than to b::B3 (%.1f)", near, far)
+	}
+	// Repulsion keeps the nodes apart
+	for _, a := range g.Nodes {
+		for _, b := range g.Nodes {
+			if a != b && distance(a, b) < 5 {
+				t.Errorf("Expected %s and %s apart, got %.1f", a.ID, b.ID, distance(a, b))
+			}
+		}
+	}
+
+	// Deterministic
+	again := newLayoutTestGraph()
+	if err := again.Layout(LayoutForce, nil); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	for nodeID, node := range g.Nodes {
+		if *again.Nodes[nodeID].Position != *node.Position {
+			t.Errorf("Expected the same position of %s on every run, got %v and %v", nodeID, *node.Position, *again.Nodes[nodeID].Position)
+		}
+	}
+}
+
+func TestLayout_ForceLargeGraph(t *testing.T) {
+	// Enough nodes to split the quadtree cells, some of them starting at the same place
+	g := NewDependencyGraph()
+	for i := range 500 {
+		nodeID := fmt.Sprintf("p%d::N%d", i%10, i)
+		g.Nodes[nodeID] = &Node{ID: nodeID}
+		if i > 0 {
+			g.AddEdge(nodeID, fmt.Sprintf("p%d::N%d", (i-1)%10, i-1))
+		}
+	}
+	if err := g.Layout(LayoutForce, nil); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	for nodeID, node := range g.Nodes {
+		if node.Position == nil || math.IsNaN(node.Position.X) || math.IsInf(node.Position.X, 0) {
+			t.Fatalf("Node %s has an invalid position %v", nodeID, node.Position)
+		}
+	}
+}
+
+func TestLayout_Layered(t *testing.T) {
+	g := newLayoutTestGraph()
+	if err := g.Layout(LayoutLayered, nil); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	layers := make(map[string]float64)
+	for nodeID, node := range g.Nodes {
+		layers[nodeID] = node.Position.Y / layeredLayerSpacing
+	}
+	want := map[string]float64{
+		"a::A1": 0,
+		"a::A2": 1,
+		"a::A3": 2,
+		"b::B1": 3, // The cycle shares a layer
+		"b::B2": 3,
+		"b::B3": 4,
+		"c::C":  0,
+	}
+	if !reflect.DeepEqual(layers, want) {
+		t.Errorf("Expected layers %v, got %v", want, layers)
+	}
+
+	// Layers are centered
+	if x1, x2 := g.Nodes["b::B1"].Position.X, g.Nodes["b::B2"].Position.X; x1+x2 != 0 || math.Abs(x1-x2) != layeredNodeSpacing {
+		t.Errorf("Expected the cycle centered and %v apart, got x = %v and %v", layeredNodeSpacing, x1, x2)
+	}
+}
+
+func TestLayout_Unknown(t *testing.T) {
+	g := newLayoutTestGraph()
+	if err := g.Layout("circular", nil); err == nil {
+		t.Error("Expected an error for an unknown layout")
+	}
+	for nodeID, node := range g.Nodes {
+		if node.Position != nil {
+			t.Errorf("Expected no position on %s after a failed layout", nodeID)
+		}
+	}
+}
+
+func TestLayout_Previous(t *testing.T) {
+	g := newLayoutTestGraph()
+	if err := g.Layout(LayoutForce, nil); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	previous := g.Positions()
+	if len(previous) != len(g.Nodes) {
+		t.Fatalf("Expected the positions of all %d nodes, got %d", len(g.Nodes), len(previous))
+	}
+
+	// The next version of the graph drops a node and adds one depending on b::B3, and an
+	// isolated one
+	next := newLayoutTestGraph()
+	delete(next.Nodes, "c::C")
+	next.Nodes["b::B4"] = &Node{ID: "b::B4"}
+	next.AddEdge("b::B4", "b::B3")
+	next.Nodes["d::D"] = &Node{ID: "d::D"}
+	if err := next.Layout(LayoutLayered, previous); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	for nodeID, node := range next.Nodes {
+		if want, exists := previous[nodeID]; exists && *node.Position != want {
+			t.Errorf("Expected %s kept at %v, got %v", nodeID, want, *node.Position)
+		}
+	}
+	if d := distance(next.Nodes["b::B4"], next.Nodes["b::B3"]); d > 3*forceLinkDistance {
+		t.Errorf("Expected b::B4 placed near its neighbor b::B3, got %.1f away", d)
+	}
+	for nodeID, node := range next.Nodes {
+		if nodeID != "d::D" && distance(node, next.Nodes["d::D"]) < 5 {
+			t.Errorf("Expected d::D placed off %s, got %.1f away", nodeID, distance(node, next.Nodes["d::D"]))
+		}
+	}
+
+	// Unchanged graphs keep their layout
+	again := newLayoutTestGraph()
+	if err := again.Layout(LayoutForce, previous); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	if got := again.Positions(); !reflect.DeepEqual(got, previous) {
+		t.Errorf("Expected the previous positions %v, got %v", previous, got)
+	}
+}
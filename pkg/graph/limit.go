@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Truncation strategies used by Limit, from the least to the most lossy
+const (
+	TruncatePackages = "packages"  // Symbols collapsed into one node per package
+	TruncateSubgraph = "subgraphs" // Only the highest-scoring subgraphs kept
+	TruncateNodes    = "nodes"     // Only the most connected nodes kept
+)
+
+// Truncation describes how Limit reduced a graph that exceeded the node limit
+type Truncation struct {
+	Strategy      string `json:"strategy"`  // One of the Truncate constants
+	MaxNodes      int    `json:"max_nodes"` // The limit that was exceeded
+	Nodes         int    `json:"nodes"`     // Nodes of the graph before truncation
+	Edges         int    `json:"edges"`     // Edges of the graph before truncation
+	KeptNodes     int    `json:"kept_nodes"`
+	KeptEdges     int    `json:"kept_edges"`
+	Subgraphs     int    `json:"subgraphs,omitempty"`      // Subgraphs before truncation, with the subgraphs strategy
+	KeptSubgraphs int    `json:"kept_subgraphs,omitempty"` // Subgraphs kept, with the subgraphs strategy
+}
+
+// String describes the truncation in one sentence, for warnings
+func (t *Truncation) String() string {
+	switch t.Strategy {
+	case TruncatePackages:
+		return fmt.Sprintf("%d nodes exceed the limit of %d: collapsed into %d packages with %d edges between them",
+			t.Nodes, t.MaxNodes, t.KeptNodes, t.KeptEdges)
+	case TruncateSubgraph:
+		return fmt.Sprintf("%d nodes exceed the limit of %d: kept the %d highest-scoring of %d subgraphs, with %d nodes and %d edges",
+			t.Nodes, t.MaxNodes, t.KeptSubgraphs, t.Subgraphs, t.KeptNodes, t.KeptEdges)
+	default:
+		return fmt.Sprintf("%d nodes exceed the limit of %d: kept the %d most connected nodes, with %d of %d edges",
+			t.Nodes, t.MaxNodes, t.KeptNodes, t.KeptEdges, t.Edges)
+	}
+}
+
+// Limit returns a graph of at most maxNodes nodes, and how it was truncated. A graph within
+// the limit, or a limit of 0, is returned as is with a nil truncation. Otherwise the graph is
+// coarsened to packages if they fit, else reduced to its highest-scoring subgraphs, and as a
+// last resort to its most connected nodes. The subgraphs of the result are recomputed with
+// the given scoring options, and the truncation is recorded in its metadata, if any.
+func (g *DependencyGraph) Limit(maxNodes int, scoring ScoringOptions) (*DependencyGraph, *Truncation) {
+	if maxNodes <= 0 || len(g.Nodes) <= maxNodes {
+		return g, nil
+	}
+
+	truncation := &Truncation{MaxNodes: maxNodes, Nodes: len(g.Nodes), Edges: g.CountEdges()}
+	var limited *DependencyGraph
+	if packages := g.CollapsePackages(); len(packages.Nodes) <= maxNodes {
+		truncation.Strategy = TruncatePackages
+		limited = packages
+	} else if kept, count := g.topSubgraphs(maxNodes); count > 0 {
+		truncation.Strategy = TruncateSubgraph
+		truncation.Subgraphs = len(g.Subgraphs)
+		truncation.KeptSubgraphs = count
+		limited = g.Extract(kept)
+	} else {
+		truncation.Strategy = TruncateNodes
+		limited = g.Extract(g.mostConnected(maxNodes))
+	}
+
+	limited.ComputeSubgraphsWithScoring(scoring)
+	truncation.KeptNodes = len(limited.Nodes)
+	truncation.KeptEdges = limited.CountEdges()
+	if limited.Metadata != nil {
+		limited.Metadata.Truncation = truncation
+	}
+	return limited, truncation
+}
+
+// CollapsePackages returns a graph with one node per package, and an edge between two
+// packages when a symbol of one depends on a symbol of the other. The number of symbols of
+// each package is recorded in its "symbols" attribute. Subgraphs are not computed.
+func (g *DependencyGraph) CollapsePackages() *DependencyGraph {
+	collapsed := g.Extract(nil)
+	for _, node := range g.Nodes {
+		pkg, exists := collapsed.Nodes[node.Package]
+		if !exists {
+			pkg = &Node{
+				ID:        node.Package,
+				Name:      node.Package,
+				Kind:      KindPackage,
+				Package:   node.Package,
+				Signature: "package " + node.Package,
+				External:  true,
+			}
+			collapsed.Nodes[node.Package] = pkg
+		}
+		if pkg.DocURL == "" {
+			pkg.DocURL = packageDocURL(node)
+		}
+		pkg.External = pkg.External && node.External
+		pkg.Lines += node.Lines
+		symbols, _ := pkg.Attr("symbols")
+		count, _ := symbols.(int)
+		pkg.SetAttr("symbols", count+1)
+	}
+
+	for sourceID, targets := range g.Edges {
+		source, exists := g.Nodes[sourceID]
+		if !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if target, exists := g.Nodes[targetID]; exists && target.Package != source.Package {
+				collapsed.AddEdge(source.Package, target.Package)
+			}
+		}
+	}
+	return collapsed
+}
+
+// topSubgraphs returns the nodes of the highest-scoring subgraphs that fit in maxNodes
+// together, and the number of subgraphs they come from. Subgraphs too large to fit are
+// skipped in favor of the smaller ones after them.
+func (g *DependencyGraph) topSubgraphs(maxNodes int) ([]string, int) {
+	var kept []string
+	count := 0
+	for _, subgraph := range g.Subgraphs {
+		nodes := make([]string, 0, len(subgraph.NodeIDs))
+		for _, nodeID := range subgraph.NodeIDs {
+			if _, exists := g.Nodes[nodeID]; exists {
+				nodes = append(nodes, nodeID)
+			}
+		}
+		if len(nodes) == 0 || len(kept)+len(nodes) > maxNodes {
+			continue
+		}
+		kept = append(kept, nodes...)
+		count++
+	}
+	return kept, count
+}
+
+// mostConnected returns the maxNodes nodes with the most incoming and outgoing edges, ties
+// broken by ID
+func (g *DependencyGraph) mostConnected(maxNodes int) []string {
+	fanIn, fanOut := g.FanIn(), g.FanOut()
+	nodeIDs := make([]string, 0, len(g.Nodes))
+	for nodeID := range g.Nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool {
+		di := fanIn[nodeIDs[i]] + fanOut[nodeIDs[i]]
+		dj := fanIn[nodeIDs[j]] + fanOut[nodeIDs[j]]
+		if di != dj {
+			return di > dj
+		}
+		return nodeIDs[i] < nodeIDs[j]
+	})
+	return nodeIDs[:min(maxNodes, len(nodeIDs))]
+}
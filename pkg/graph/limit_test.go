@@ -0,0 +1,111 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+// newLimitTestGraph returns a graph of two packages: a with a cycle of three functions, and b
+// with two functions calling each other, one of them calling into a
+func newLimitTestGraph() *DependencyGraph {
+	g := NewDependencyGraph()
+	for _, id := range []string{"a::A1", "a::A2", "a::A3", "b::B1", "b::B2", "c::C1"} {
+		pkg, _, _ := strings.Cut(id, "::")
+		g.Nodes[id] = &Node{ID: id, Name: id, Kind: KindFunction, Package: pkg, Lines: 10}
+	}
+	g.AddEdge("a::A1", "a::A2")
+	g.AddEdge("a::A2", "a::A3")
+	g.AddEdge("a::A3", "a::A1")
+	g.AddEdge("b::B1", "b::B2")
+	g.AddEdge("b::B2", "b::B1")
+	g.AddEdge("b::B2", "a::A1")
+	g.Metadata = &Metadata{Tool: "go-depmap"}
+	g.ComputeSubgraphs()
+	return g
+}
+
+func TestLimit_WithinLimit(t *testing.T) {
+	g := newLimitTestGraph()
+	for _, maxNodes := range []int{0, 6, 100} {
+		limited, truncation := g.Limit(maxNodes, DefaultScoringOptions())
+		if limited != g || truncation != nil {
+			t.Errorf("Limit(%d) truncated a graph within the limit: %v", maxNodes, truncation)
+		}
+	}
+}
+
+func TestLimit_Packages(t *testing.T) {
+	g := newLimitTestGraph()
+	limited, truncation := g.Limit(3, DefaultScoringOptions())
+	if truncation == nil || truncation.Strategy != TruncatePackages {
+		t.Fatalf("Expected packages truncation, got %v", truncation)
+	}
+	if len(limited.Nodes) != 3 || !limited.HasEdge("b", "a") || limited.CountEdges() != 1 {
+		t.Errorf("Unexpected package graph: nodes %d, edges %v", len(limited.Nodes), limited.Edges)
+	}
+	a := limited.Nodes["a"]
+	if symbols, _ := a.Attr("symbols"); symbols != 3 || a.Lines != 30 || a.Kind != KindPackage {
+		t.Errorf("Unexpected package node: %+v", a)
+	}
+	if len(limited.Subgraphs) != 2 {
+		t.Errorf("Expected subgraphs to be recomputed, got %d", len(limited.Subgraphs))
+	}
+	if limited.Metadata.Truncation != truncation || g.Metadata.Truncation != nil {
+		t.Errorf("Expected the truncation in the metadata of the limited graph only")
+	}
+	if truncation.Nodes != 6 || truncation.KeptNodes != 3 || truncation.KeptEdges != 1 {
+		t.Errorf("Unexpected truncation counts: %+v", truncation)
+	}
+}
+
+func TestLimit_Subgraphs(t *testing.T) {
+	// Three packages do not fit, the lone C1 subgraph does
+	g := newLimitTestGraph()
+	limited, truncation := g.Limit(2, DefaultScoringOptions())
+	if truncation == nil || truncation.Strategy != TruncateSubgraph {
+		t.Fatalf("Expected subgraphs truncation, got %v", truncation)
+	}
+	if len(limited.Nodes) != 1 || limited.Nodes["c::C1"] == nil {
+		t.Errorf("Expected only c::C1 to be kept, got %d nodes", len(limited.Nodes))
+	}
+	if truncation.Subgraphs != 2 || truncation.KeptSubgraphs != 1 {
+		t.Errorf("Unexpected subgraph counts: %+v", truncation)
+	}
+	if !strings.Contains(truncation.String(), "kept the 1 highest-scoring of 2 subgraphs") {
+		t.Errorf("Unexpected description: %s", truncation)
+	}
+}
+
+func TestLimit_Nodes(t *testing.T) {
+	// Every subgraph is larger than the limit
+	g := newLimitTestGraph()
+	delete(g.Nodes, "c::C1")
+	g.ComputeSubgraphs()
+
+	limited, truncation := g.Limit(1, DefaultScoringOptions())
+	if truncation == nil || truncation.Strategy != TruncateNodes {
+		t.Fatalf("Expected nodes truncation, got %v", truncation)
+	}
+	// A1 has the most edges: two incoming and one outgoing
+	if len(limited.Nodes) != 1 || limited.Nodes["a::A1"] == nil {
+		t.Errorf("Expected only a::A1 to be kept, got %v", limited.Nodes)
+	}
+}
+
+func TestCollapsePackages_DocURL(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["go-depmap/pkg/graph::Merge"] = &Node{ID: "go-depmap/pkg/graph::Merge", Name: "Merge", Kind: KindFunction, Package: "go-depmap/pkg/graph"}
+	g.Nodes["example.com/app::Run"] = &Node{ID: "example.com/app::Run", Name: "Run", Kind: KindFunction, Package: "example.com/app"}
+	g.Nodes["os::Exit"] = &Node{ID: "os::Exit", Name: "Exit", Kind: KindFunction, Package: "os", External: true, DocURL: "https://pkg.go.dev/os#Exit"}
+
+	collapsed := g.CollapsePackages()
+	for pkg, expected := range map[string]string{
+		"go-depmap/pkg/graph": "",
+		"example.com/app":     "https://pkg.go.dev/example.com/app",
+		"os":                  "https://pkg.go.dev/os",
+	} {
+		if got := collapsed.Nodes[pkg].DocURL; got != expected {
+			t.Errorf("DocURL of %s = %q, want %q", pkg, got, expected)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveNode finds the node a user-supplied symbol reference points to. The query may be
+// a full node ID ("example.com/app/cmd/server::main"), a node ID with a shortened package
+// path ("cmd/server::main"), or a bare symbol name ("(*Server).Start") if it is unique.
+// An error is returned when nothing or more than one node matches.
+func (g *DependencyGraph) ResolveNode(query string) (string, error) {
+	if _, exists := g.Nodes[query]; exists {
+		return query, nil
+	}
+
+	matches := make([]string, 0)
+	for nodeID, node := range g.Nodes {
+		if strings.HasSuffix(nodeID, "/"+query) || node.Name == query {
+			matches = append(matches, nodeID)
+		}
+	}
+	sort.Strings(matches)
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no symbol matches %q", query)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%q is ambiguous, it matches: %s", query, strings.Join(matches, ", "))
+	}
+}
+
+// ResolvePackage finds the package a user-supplied import path points to. The query may be
+// the full import path ("example.com/app/pkg/db") or a shortened one ("pkg/db", "db") if it
+// is unique. An error is returned when no node or the nodes of more than one package match.
+func (g *DependencyGraph) ResolvePackage(query string) (string, error) {
+	matched := make(map[string]bool)
+	for _, node := range g.Nodes {
+		if node.Package == query {
+			return query, nil
+		}
+		if strings.HasSuffix(node.Package, "/"+query) {
+			matched[node.Package] = true
+		}
+	}
+
+	matches := make([]string, 0, len(matched))
+	for pkg := range matched {
+		matches = append(matches, pkg)
+	}
+	sort.Strings(matches)
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no package matches %q", query)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%q is ambiguous, it matches: %s", query, strings.Join(matches, ", "))
+	}
+}
+
+// MatchPackagePattern matches an import path against a go command style pattern, where a
+// trailing /... also matches the package itself and everything below it
+func MatchPackagePattern(pattern, pkgPath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkgPath == prefix || strings.HasPrefix(pkgPath, prefix+"/")
+	}
+	return pattern == "..." || pattern == pkgPath
+}
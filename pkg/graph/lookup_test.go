@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveNode(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["example.com/app/cmd/server::main"] = &Node{ID: "example.com/app/cmd/server::main", Name: "main"}
+	g.Nodes["example.com/app/cmd/worker::main"] = &Node{ID: "example.com/app/cmd/worker::main", Name: "main"}
+	g.Nodes["example.com/app/api::(*Server).Start"] = &Node{ID: "example.com/app/api::(*Server).Start", Name: "(*Server).Start"}
+
+	tests := []struct {
+		query    string
+		expected string
+		wantErr  bool
+	}{
+		{query: "example.com/app/cmd/server::main", expected: "example.com/app/cmd/server::main"},
+		{query: "cmd/server::main", expected: "example.com/app/cmd/server::main"},
+		{query: "(*Server).Start", expected: "example.com/app/api::(*Server).Start"},
+		{query: "main", wantErr: true},
+		{query: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			id, err := g.ResolveNode(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveNode(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if id != tt.expected {
+				t.Errorf("ResolveNode(%q) = %q, want %q", tt.query, id, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolvePackage(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"example.com/app/pkg/db::Open", "example.com/app/internal/db::Conn", "example.com/app/pkg/api::Serve"} {
+		pkg, name, _ := strings.Cut(id, "::")
+		g.Nodes[id] = &Node{ID: id, Name: name, Package: pkg}
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+		wantErr  bool
+	}{
+		{query: "example.com/app/pkg/db", expected: "example.com/app/pkg/db"},
+		{query: "pkg/db", expected: "example.com/app/pkg/db"},
+		{query: "api", expected: "example.com/app/pkg/api"},
+		{query: "db", wantErr: true},
+		{query: "pkg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			pkg, err := g.ResolvePackage(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolvePackage(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if pkg != tt.expected {
+				t.Errorf("ResolvePackage(%q) = %q, want %q", tt.query, pkg, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchPackagePattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		pkgPath  string
+		expected bool
+	}{
+		{"github.com/aws/...", "github.com/aws", true},
+		{"github.com/aws/...", "github.com/aws/aws-sdk-go-v2/service/s3", true},
+		{"github.com/aws/...", "github.com/awslabs/smithy-go", false},
+		{"github.com/aws/aws-sdk-go-v2", "github.com/aws/aws-sdk-go-v2", true},
+		{"github.com/aws/aws-sdk-go-v2", "github.com/aws/aws-sdk-go-v2/aws", false},
+		{"...", "example.org/anything", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPackagePattern(tt.pattern, tt.pkgPath); got != tt.expected {
+			t.Errorf("MatchPackagePattern(%q, %q) = %v, want %v", tt.pattern, tt.pkgPath, got, tt.expected)
+		}
+	}
+}
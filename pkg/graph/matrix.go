@@ -0,0 +1,173 @@
+package graph
+
+import (
+	"maps"
+	"slices"
+)
+
+// DependencyMatrix is the adjacency matrix of the packages of a graph: Counts[i][j] is the
+// number of symbol dependencies of package i on package j, so that a row lists what a package
+// uses and a column what uses it. Dependencies within a package are on the diagonal.
+type DependencyMatrix struct {
+	Packages []string      `json:"packages"`
+	Counts   [][]int       `json:"counts"`
+	Blocks   []MatrixBlock `json:"blocks,omitempty"` // Cyclic blocks, set by Sequence
+}
+
+// PackageMatrix counts the dependencies between the packages of the graph, each edge counted
+// by its weight (see EdgeWeight), with the packages sorted by import path
+func (g *DependencyGraph) PackageMatrix() *DependencyMatrix {
+	index := make(map[string]int)
+	for _, node := range g.Nodes {
+		index[node.Package] = 0
+	}
+	matrix := &DependencyMatrix{Packages: slices.Sorted(maps.Keys(index))}
+	matrix.Counts = make([][]int, len(matrix.Packages))
+	for i, pkgPath := range matrix.Packages {
+		index[pkgPath] = i
+		matrix.Counts[i] = make([]int, len(matrix.Packages))
+	}
+
+	for sourceID, targets := range g.Edges {
+		source := g.Nodes[sourceID]
+		if source == nil {
+			continue
+		}
+		for _, targetID := range targets {
+			if target := g.Nodes[targetID]; target != nil {
+				matrix.Counts[index[source.Package]][index[target.Package]] += g.EdgeWeight(sourceID, targetID)
+			}
+		}
+	}
+	return matrix
+}
+
+// MatrixBlock is a run of packages of a sequenced matrix that depend on each other in a
+// cycle: whatever their order, some of their dependencies stay above the diagonal
+type MatrixBlock struct {
+	Start int `json:"start"` // Index of the first package of the block
+	Size  int `json:"size"`
+}
+
+// Sequence returns the matrix with its packages reordered the way design structure matrices
+// are partitioned: every package comes after the packages it depends on, so that dependencies
+// are below the diagonal, except within the cycles between packages, which become contiguous
+// blocks. Packages without an order between them are sorted by import path, and the packages
+// of a block are ordered greedily, each one depending on the fewest of the block's packages
+// not placed yet, to keep few dependencies above the diagonal.
+func (m *DependencyMatrix) Sequence() *DependencyMatrix {
+	n := len(m.Packages)
+	packageGraph := NewDependencyGraph()
+	index := make(map[string]int, n)
+	for i, pkgPath := range m.Packages {
+		packageGraph.Nodes[pkgPath] = &Node{ID: pkgPath, Package: pkgPath, Kind: KindPackage}
+		index[pkgPath] = i
+		for j, count := range m.Counts[i] {
+			if count > 0 && i != j {
+				packageGraph.AddEdge(pkgPath, m.Packages[j])
+			}
+		}
+	}
+
+	// The cycles are the strongly connected components of the package graph
+	components := packageGraph.stronglyConnectedComponents()
+	componentOf := make([]int, n)
+	for c, component := range components {
+		slices.SortFunc(component, func(a, b string) int { return index[a] - index[b] })
+		for _, pkgPath := range component {
+			componentOf[index[pkgPath]] = c
+		}
+	}
+	// Number of components each component depends on, and those depending on it
+	pending := make([]int, len(components))
+	dependents := make([][]int, len(components))
+	for c, component := range components {
+		dependsOn := make(map[int]bool)
+		for _, pkgPath := range component {
+			for _, target := range packageGraph.Edges[pkgPath] {
+				if d := componentOf[index[target]]; d != c {
+					dependsOn[d] = true
+				}
+			}
+		}
+		pending[c] = len(dependsOn)
+		for d := range dependsOn {
+			dependents[d] = append(dependents[d], c)
+		}
+	}
+
+	// Place the components whose dependencies are placed, the first package path first
+	sequenced := &DependencyMatrix{Packages: make([]string, 0, n)}
+	order := make([]int, 0, n) // New position -> old index
+	var ready []int
+	for c := range components {
+		if pending[c] == 0 {
+			ready = append(ready, c)
+		}
+	}
+	for len(ready) > 0 {
+		slices.SortFunc(ready, func(a, b int) int { return index[components[a][0]] - index[components[b][0]] })
+		c := ready[0]
+		ready = ready[1:]
+		if len(components[c]) > 1 {
+			sequenced.Blocks = append(sequenced.Blocks, MatrixBlock{Start: len(order), Size: len(components[c])})
+		}
+		for _, i := range m.sequenceBlock(components[c], index) {
+			order = append(order, i)
+			sequenced.Packages = append(sequenced.Packages, m.Packages[i])
+		}
+		for _, d := range dependents[c] {
+			if pending[d]--; pending[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	sequenced.Counts = make([][]int, n)
+	for row, i := range order {
+		sequenced.Counts[row] = make([]int, n)
+		for col, j := range order {
+			sequenced.Counts[row][col] = m.Counts[i][j]
+		}
+	}
+	return sequenced
+}
+
+// sequenceBlock orders the packages of a cycle, given sorted by index: each next package is
+// the one with the fewest dependencies on the packages not placed yet, the first among equals
+func (m *DependencyMatrix) sequenceBlock(block []string, index map[string]int) []int {
+	remaining := make([]int, len(block))
+	for k, pkgPath := range block {
+		remaining[k] = index[pkgPath]
+	}
+	order := make([]int, 0, len(block))
+	for len(remaining) > 0 {
+		best, bestCount := 0, -1
+		for k, i := range remaining {
+			count := 0
+			for _, j := range remaining {
+				if j != i {
+					count += m.Counts[i][j]
+				}
+			}
+			if bestCount < 0 || count < bestCount {
+				best, bestCount = k, count
+			}
+		}
+		order = append(order, remaining[best])
+		remaining = slices.Delete(remaining, best, best+1)
+	}
+	return order
+}
+
+// AboveDiagonal returns the number of dependencies above the diagonal: those of a package on
+// a package after it, which a sequenced matrix only has within its blocks
+func (m *DependencyMatrix) AboveDiagonal() int {
+	total := 0
+	for i, row := range m.Counts {
+		for _, count := range row[i+1:] {
+			total += count
+		}
+	}
+	return total
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPackageMatrix(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["b::B1"] = &Node{ID: "b::B1", Package: "b"}
+	g.Nodes["b::B2"] = &Node{ID: "b::B2", Package: "b"}
+	g.Nodes["a::A"] = &Node{ID: "a::A", Package: "a"}
+	g.Nodes["c"] = &Node{ID: "c", Package: "c", Kind: KindPackage}
+	g.AddEdge("a::A", "b::B1")
+	g.AddEdge("a::A", "b::B2")
+	g.AddEdge("b::B1", "b::B2")
+	g.AddEdge("b::B2", "c")
+	g.SetEdgeAttr("b::B2", "c", WeightAttr, 3)
+	g.AddEdge("b::B2", "missing::M")
+
+	matrix := g.PackageMatrix()
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(matrix.Packages, want) {
+		t.Errorf("Expected packages %v, got %v", want, matrix.Packages)
+	}
+	want := [][]int{
+		{0, 2, 0},
+		{0, 1, 3},
+		{0, 0, 0},
+	}
+	if !reflect.DeepEqual(matrix.Counts, want) {
+		t.Errorf("Expected counts %v, got %v", want, matrix.Counts)
+	}
+}
+
+func TestDependencyMatrix_Sequence(t *testing.T) {
+	// a uses b and c, which use each other, e uses a, and d is on its own
+	m := &DependencyMatrix{
+		Packages: []string{"a", "b", "c", "d", "e"},
+		Counts: [][]int{
+			{0, 1, 1, 0, 0},
+			{0, 4, 2, 0, 0},
+			{0, 1, 0, 0, 0},
+			{0, 0, 0, 0, 0},
+			{3, 0, 0, 0, 0},
+		},
+	}
+	sequenced := m.Sequence()
+
+	// The cycle comes first, the package with fewer dependencies on the other one leading
+	if want := []string{"c", "b", "a", "d", "e"}; !reflect.DeepEqual(sequenced.Packages, want) {
+		t.Errorf("Expected packages %v, got %v", want, sequenced.Packages)
+	}
+	want := [][]int{
+		{0, 1, 0, 0, 0},
+		{2, 4, 0, 0, 0},
+		{1, 1, 0, 0, 0},
+		{0, 0, 0, 0, 0},
+		{0, 0, 3, 0, 0},
+	}
+	if !reflect.DeepEqual(sequenced.Counts, want) {
+		t.Errorf("Expected counts %v, got %v", want, sequenced.Counts)
+	}
+	if want := []MatrixBlock{{Start: 0, Size: 2}}; !reflect.DeepEqual(sequenced.Blocks, want) {
+		t.Errorf("Expected blocks %v, got %v", want, sequenced.Blocks)
+	}
+	if above := sequenced.AboveDiagonal(); above != 1 {
+		t.Errorf("Expected 1 dependency above the diagonal, got %d", above)
+	}
+	if above := m.AboveDiagonal(); above != 4 {
+		t.Errorf("Expected 4 dependencies above the diagonal before sequencing, got %d", above)
+	}
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"maps"
+	"slices"
+)
+
+// Merge returns the union of graphs analyzed separately, such as those of the repositories
+// of a multi-repo system. Nodes with the same ID are the same symbol, as the analyzer names
+// external symbols like project ones: a node analyzed in one graph replaces the external node
+// another graph has for it, so that the edges between repositories end at analyzed symbols,
+// and shared external nodes appear once. Otherwise the node of the first graph is kept, with
+// the custom attributes it lacks taken from the others; edge attributes are merged the same
+// way, and edge kinds, load errors and occurrences are unioned.
+//
+// The merged graph has no metadata and no subgraphs, which the caller computes for it; the
+// graphs are left untouched.
+func Merge(graphs ...*DependencyGraph) *DependencyGraph {
+	merged := NewDependencyGraph()
+	for _, g := range graphs {
+		for nodeID, node := range g.Nodes {
+			existing, exists := merged.Nodes[nodeID]
+			if exists && !(existing.External && !node.External) {
+				mergeAttrs(existing, node.Attrs)
+				continue
+			}
+			nodeCopy := *node
+			nodeCopy.Attrs = maps.Clone(node.Attrs)
+			if exists {
+				mergeAttrs(&nodeCopy, existing.Attrs)
+			}
+			merged.Nodes[nodeID] = &nodeCopy
+		}
+
+		for sourceID, targets := range g.Edges {
+			for _, targetID := range targets {
+				merged.AddEdge(sourceID, targetID, g.EdgeKindsOf(sourceID, targetID)...)
+				existing := merged.EdgeAttrsOf(sourceID, targetID)
+				for key, value := range g.EdgeAttrsOf(sourceID, targetID) {
+					if _, set := existing[key]; !set {
+						merged.SetEdgeAttr(sourceID, targetID, key, value)
+					}
+				}
+			}
+		}
+
+		for _, loadError := range g.LoadErrors {
+			if !slices.Contains(merged.LoadErrors, loadError) {
+				merged.LoadErrors = append(merged.LoadErrors, loadError)
+			}
+		}
+		merged.Occurrences = append(merged.Occurrences, g.Occurrences...)
+	}
+	merged.SortOccurrences()
+	return merged
+}
+
+// mergeAttrs sets the attributes the node lacks
+func mergeAttrs(node *Node, attrs map[string]any) {
+	for key, value := range attrs {
+		if _, set := node.Attr(key); !set {
+			node.SetAttr(key, value)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	// The api repository calls into the billing repository, which it sees as external
+	api := NewDependencyGraph()
+	api.Nodes["example.com/api::Serve"] = &Node{ID: "example.com/api::Serve", Package: "example.com/api", Line: 10}
+	api.Nodes["example.com/billing::Charge"] = &Node{ID: "example.com/billing::Charge", Package: "example.com/billing", External: true, Attrs: map[string]any{"seen": "api"}}
+	api.Nodes["fmt::Println"] = &Node{ID: "fmt::Println", Package: "fmt", External: true}
+	api.AddEdge("example.com/api::Serve", "example.com/billing::Charge", EdgeBody)
+	api.AddEdge("example.com/api::Serve", "fmt::Println")
+	api.LoadErrors = []LoadError{{Package: "example.com/api/broken", Kind: "type", Message: "undefined: x"}}
+
+	billing := NewDependencyGraph()
+	billing.Nodes["example.com/billing::Charge"] = &Node{ID: "example.com/billing::Charge", Package: "example.com/billing", Line: 42, Attrs: map[string]any{"team": "payments"}}
+	billing.Nodes["fmt::Println"] = &Node{ID: "fmt::Println", Package: "fmt", External: true, Attrs: map[string]any{"stdlib": true}}
+	billing.AddEdge("example.com/billing::Charge", "fmt::Println", EdgeBody)
+	billing.SetEdgeAttr("example.com/billing::Charge", "fmt::Println", "weight", 3)
+	billing.LoadErrors = api.LoadErrors
+
+	merged := Merge(api, billing)
+	if len(merged.Nodes) != 3 || merged.CountEdges() != 3 {
+		t.Fatalf("Expected 3 nodes and 3 edges, got %d and %d", len(merged.Nodes), merged.CountEdges())
+	}
+	charge := merged.Nodes["example.com/billing::Charge"]
+	if charge.External || charge.Line != 42 {
+		t.Errorf("Expected the analyzed Charge to replace the external one, got %+v", charge)
+	}
+	if !reflect.DeepEqual(charge.Attrs, map[string]any{"team": "payments", "seen": "api"}) {
+		t.Errorf("Expected the attributes of both Charge nodes, got %v", charge.Attrs)
+	}
+	if stdlib, _ := merged.Nodes["fmt::Println"].Attr("stdlib"); stdlib != true {
+		t.Errorf("Expected the shared external node to get the attributes of both graphs")
+	}
+	if !reflect.DeepEqual(merged.EdgeKindsOf("example.com/api::Serve", "example.com/billing::Charge"), []EdgeKind{EdgeBody}) ||
+		merged.EdgeAttrsOf("example.com/billing::Charge", "fmt::Println")["weight"] != 3 {
+		t.Errorf("Expected edge kinds and attributes to be kept, got %v and %v", merged.EdgeKinds, merged.EdgeAttrs)
+	}
+	if len(merged.LoadErrors) != 1 {
+		t.Errorf("Expected the shared load error once, got %v", merged.LoadErrors)
+	}
+
+	// The inputs are left untouched, whatever the order
+	if !api.Nodes["example.com/billing::Charge"].External || len(billing.Nodes["fmt::Println"].Attrs) != 1 {
+		t.Errorf("Merge changed its inputs")
+	}
+	if reversed := Merge(billing, api); reversed.Nodes["example.com/billing::Charge"].External {
+		t.Errorf("Expected the analyzed node to win in any order")
+	}
+}
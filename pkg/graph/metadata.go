@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Metadata records the provenance of a graph, so that outputs of different runs can be
+// compared: the tool that produced it, the analyzed module, when and how
+type Metadata struct {
+	Tool      string            `json:"tool"`                 // Name of the producing tool, go-depmap
+	Version   string            `json:"version"`              // Version of the producing tool
+	Commit    string            `json:"commit,omitempty"`     // VCS revision the tool was built from
+	BuildDate string            `json:"build_date,omitempty"` // When the tool was built, RFC 3339
+	Module    string            `json:"module,omitempty"`     // Path of the analyzed main module
+	GoVersion string            `json:"go_version"`           // Go version the tool was built with, e.g. go1.24.5
+	Timestamp time.Time         `json:"timestamp"`            // Start of the analysis, in UTC
+	Flags     map[string]string `json:"flags,omitempty"`      // Command-line flags set explicitly, by name
+	Patterns  []string          `json:"patterns"`             // Package patterns analyzed, e.g. ./...
+	Nodes     int               `json:"nodes"`                // Number of nodes of the written graph
+	Edges     int               `json:"edges"`                // Number of edges of the written graph
+
+	// How the graph was reduced to fit the node limit, see Limit
+	Truncation *Truncation `json:"truncation,omitempty"`
+
+	// How the graph was sampled for a preview page, see Sample
+	Sampling *Sampling `json:"sampling,omitempty"`
+}
+
+// UpdateMetadataCounts sets the node and edge counts of the graph's metadata, if it has
+// any, to its current size. Call it once filtering is done, before writing the graph.
+func (g *DependencyGraph) UpdateMetadataCounts() {
+	if g.Metadata == nil {
+		return
+	}
+	g.Metadata.Nodes = len(g.Nodes)
+	g.Metadata.Edges = g.CountEdges()
+}
+
+// String summarizes the metadata on one line, for the text-based formats
+func (m *Metadata) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s", m.Tool, m.Version)
+	if m.Commit != "" {
+		fmt.Fprintf(&sb, " (commit %.12s)", m.Commit)
+	}
+	if m.Module != "" {
+		fmt.Fprintf(&sb, " on %s", m.Module)
+	}
+	fmt.Fprintf(&sb, " [%s]", strings.Join(m.Patterns, " "))
+	fmt.Fprintf(&sb, " (%s) at %s: %d nodes, %d edges", m.GoVersion, m.Timestamp.Format(time.RFC3339), m.Nodes, m.Edges)
+	if len(m.Flags) > 0 {
+		flags := make([]string, 0, len(m.Flags))
+		for _, name := range slices.Sorted(maps.Keys(m.Flags)) {
+			flags = append(flags, fmt.Sprintf("-%s=%s", name, m.Flags[name]))
+		}
+		fmt.Fprintf(&sb, "; flags: %s", strings.Join(flags, " "))
+	}
+	if m.Truncation != nil {
+		fmt.Fprintf(&sb, "; truncated: %s", m.Truncation)
+	}
+	if m.Sampling != nil {
+		fmt.Fprintf(&sb, "; %s", m.Sampling)
+	}
+	return sb.String()
+}
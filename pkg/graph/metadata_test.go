@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateMetadataCounts(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["a::F"] = &Node{ID: "a::F", Name: "F", Package: "a", Kind: KindFunction}
+	g.Nodes["a::G"] = &Node{ID: "a::G", Name: "G", Package: "a", Kind: KindFunction}
+	g.Nodes["a::H"] = &Node{ID: "a::H", Name: "H", Package: "a", Kind: KindFunction}
+	g.AddEdge("a::F", "a::G")
+	g.AddEdge("a::G", "a::H")
+	g.Metadata = &Metadata{Module: "example.com/app", Flags: map[string]string{"tests": "true"}}
+	g.UpdateMetadataCounts()
+	if g.Metadata.Nodes != 3 || g.Metadata.Edges != 2 {
+		t.Errorf("Expected 3 nodes and 2 edges, got %d and %d", g.Metadata.Nodes, g.Metadata.Edges)
+	}
+
+	extracted := g.Extract([]string{"a::F", "a::G"})
+	extracted.UpdateMetadataCounts()
+	extracted.Metadata.Flags["tests"] = "false"
+	if extracted.Metadata.Nodes != 2 || extracted.Metadata.Edges != 1 || extracted.Metadata.Module != "example.com/app" {
+		t.Errorf("Unexpected metadata of the extracted graph: %+v", extracted.Metadata)
+	}
+	if g.Metadata.Nodes != 3 || g.Metadata.Flags["tests"] != "true" {
+		t.Errorf("Extract should copy the metadata, got %+v", g.Metadata)
+	}
+
+	NewDependencyGraph().UpdateMetadataCounts() // No metadata, no panic
+}
+
+func TestMetadata_String(t *testing.T) {
+	metadata := &Metadata{
+		Tool:      "go-depmap",
+		Version:   "v1.2.3",
+		Module:    "example.com/app",
+		GoVersion: "go1.24.5",
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Flags:     map[string]string{"tests": "true", "format": "d3js"},
+		Patterns:  []string{"./..."},
+		Nodes:     3,
+		Edges:     2,
+	}
+
+	want := "go-depmap v1.2.3 on example.com/app [./...] (go1.24.5) at 2026-01-02T03:04:05Z: 3 nodes, 2 edges; flags: -format=d3js -tests=true"
+	if got := metadata.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	metadata.Commit = "0123456789abcdef0123"
+	if got := metadata.String(); !strings.HasPrefix(got, "go-depmap v1.2.3 (commit 0123456789ab) on ") {
+		t.Errorf("Expected the abbreviated commit after the version, got %q", got)
+	}
+
+	metadata.Module, metadata.Flags = "", nil
+	if got := metadata.String(); strings.Contains(got, " on ") || strings.Contains(got, "flags") {
+		t.Errorf("Empty fields should be left out, got %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PackageMetrics summarizes the size and coupling of a single package
+type PackageMetrics struct {
+	Package       string  `json:"package"`
+	Nodes         int     `json:"nodes"`          // Total number of symbols
+	Functions     int     `json:"functions"`      // Number of functions
+	Methods       int     `json:"methods"`        // Number of methods
+	Types         int     `json:"types"`          // Number of types
+	InternalEdges int     `json:"internal_edges"` // Edges between symbols of this package
+	Afferent      int     `json:"afferent"`       // Ca: number of other packages depending on this package
+	Efferent      int     `json:"efferent"`       // Ce: number of other packages this package depends on
+	Instability   float64 `json:"instability"`    // Ce / (Ca + Ce), 0 = stable, 1 = unstable
+}
+
+// FanIn returns the number of incoming edges for every node in the graph
+func (g *DependencyGraph) FanIn() map[string]int {
+	fanIn := make(map[string]int, len(g.Nodes))
+	for _, targets := range g.Edges {
+		for _, target := range targets {
+			if _, exists := g.Nodes[target]; exists {
+				fanIn[target]++
+			}
+		}
+	}
+	return fanIn
+}
+
+// FanOut returns the number of outgoing edges for every node in the graph
+func (g *DependencyGraph) FanOut() map[string]int {
+	fanOut := make(map[string]int, len(g.Nodes))
+	for source, targets := range g.Edges {
+		if _, exists := g.Nodes[source]; !exists {
+			continue
+		}
+		for _, target := range targets {
+			if _, exists := g.Nodes[target]; exists {
+				fanOut[source]++
+			}
+		}
+	}
+	return fanOut
+}
+
+// NodeMetric returns a numeric metric of every node by ID, for coloring or sizing nodes by
+// it: a number field of the filter expressions (fanin, fanout, lines, complexity, churn,
+// spans, score, ...) or else a custom attribute, with or without the "attr." prefix, such as
+// coverage. The nodes without a numeric value of the attribute are left out.
+func (g *DependencyGraph) NodeMetric(name string) (map[string]float64, error) {
+	values := make(map[string]float64, len(g.Nodes))
+	if field, known := nodeFields[name]; known {
+		if field.typ != numberValue {
+			return nil, fmt.Errorf("field %q is not a number", name)
+		}
+		env := &exprEnv{g: g}
+		for nodeID, node := range g.Nodes {
+			values[nodeID] = field.get(env, node).(float64)
+		}
+		return values, nil
+	}
+
+	key := strings.TrimPrefix(name, "attr.")
+	for nodeID, node := range g.Nodes {
+		value, _ := node.Attr(key)
+		if number, ok := toNumber(value); ok {
+			values[nodeID] = number
+		}
+	}
+	return values, nil
+}
+
+// ComputePackageMetrics computes size and coupling metrics for every package, sorted by package name
+func (g *DependencyGraph) ComputePackageMetrics() []PackageMetrics {
+	metrics := make(map[string]*PackageMetrics)
+	dependsOn := make(map[string]map[string]bool)    // package -> packages it depends on
+	dependedOnBy := make(map[string]map[string]bool) // package -> packages depending on it
+
+	for _, node := range g.Nodes {
+		m, exists := metrics[node.Package]
+		if !exists {
+			m = &PackageMetrics{Package: node.Package}
+			metrics[node.Package] = m
+			dependsOn[node.Package] = make(map[string]bool)
+			dependedOnBy[node.Package] = make(map[string]bool)
+		}
+		m.Nodes++
+		switch {
+		case node.Kind == KindFunction:
+			m.Functions++
+		case node.Kind == KindMethod:
+			m.Methods++
+		case node.Kind.IsType():
+			m.Types++
+		}
+	}
+
+	for sourceID, targets := range g.Edges {
+		source, exists := g.Nodes[sourceID]
+		if !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			target, exists := g.Nodes[targetID]
+			if !exists {
+				continue
+			}
+			if source.Package == target.Package {
+				metrics[source.Package].InternalEdges++
+				continue
+			}
+			dependsOn[source.Package][target.Package] = true
+			dependedOnBy[target.Package][source.Package] = true
+		}
+	}
+
+	result := make([]PackageMetrics, 0, len(metrics))
+	for pkgName, m := range metrics {
+		m.Afferent = len(dependedOnBy[pkgName])
+		m.Efferent = len(dependsOn[pkgName])
+		if total := m.Afferent + m.Efferent; total > 0 {
+			m.Instability = float64(m.Efferent) / float64(total)
+		}
+		result = append(result, *m)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Package < result[j].Package
+	})
+
+	return result
+}
@@ -0,0 +1,118 @@
+package graph
+
+import "testing"
+
+func newMetricsTestGraph() *DependencyGraph {
+	g := NewDependencyGraph()
+	g.Nodes["a::F"] = &Node{ID: "a::F", Kind: KindFunction, Package: "a"}
+	g.Nodes["a::T"] = &Node{ID: "a::T", Kind: KindStruct, Package: "a"}
+	g.Nodes["b::G"] = &Node{ID: "b::G", Kind: KindFunction, Package: "b"}
+	g.Nodes["b::T.M"] = &Node{ID: "b::T.M", Kind: KindMethod, Package: "b"}
+	g.Nodes["c::H"] = &Node{ID: "c::H", Kind: KindFunction, Package: "c"}
+	g.Edges["a::F"] = []string{"a::T", "b::G", "missing"}
+	g.Edges["b::G"] = []string{"b::T.M", "c::H"}
+	g.Edges["c::H"] = []string{"a::T"}
+	return g
+}
+
+func TestFanInFanOut(t *testing.T) {
+	g := newMetricsTestGraph()
+
+	fanIn := g.FanIn()
+	if fanIn["a::T"] != 2 {
+		t.Errorf("Expected fan-in 2 for a::T, got %d", fanIn["a::T"])
+	}
+	if fanIn["missing"] != 0 {
+		t.Errorf("Unknown targets should not be counted, got %d", fanIn["missing"])
+	}
+
+	fanOut := g.FanOut()
+	if fanOut["a::F"] != 2 {
+		t.Errorf("Expected fan-out 2 for a::F, got %d", fanOut["a::F"])
+	}
+	if fanOut["b::T.M"] != 0 {
+		t.Errorf("Expected fan-out 0 for b::T.M, got %d", fanOut["b::T.M"])
+	}
+}
+
+func TestComputePackageMetrics(t *testing.T) {
+	metrics := newMetricsTestGraph().ComputePackageMetrics()
+
+	if len(metrics) != 3 {
+		t.Fatalf("Expected 3 packages, got %d", len(metrics))
+	}
+
+	a, b, c := metrics[0], metrics[1], metrics[2]
+	if a.Package != "a" || b.Package != "b" || c.Package != "c" {
+		t.Fatalf("Expected packages sorted by name, got %s %s %s", a.Package, b.Package, c.Package)
+	}
+
+	if a.Nodes != 2 || a.Functions != 1 || a.Types != 1 {
+		t.Errorf("Unexpected counts for a: %+v", a)
+	}
+	if b.Methods != 1 {
+		t.Errorf("Expected 1 method in b, got %d", b.Methods)
+	}
+	if a.InternalEdges != 1 || b.InternalEdges != 1 {
+		t.Errorf("Unexpected internal edges: a=%d b=%d", a.InternalEdges, b.InternalEdges)
+	}
+
+	// a depends on b; b depends on c; c depends on a
+	if a.Afferent != 1 || a.Efferent != 1 || a.Instability != 0.5 {
+		t.Errorf("Unexpected coupling for a: %+v", a)
+	}
+}
+
+func TestComputePackageMetrics_Instability(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["core::T"] = &Node{ID: "core::T", Package: "core"}
+	g.Nodes["app::F"] = &Node{ID: "app::F", Package: "app"}
+	g.Edges["app::F"] = []string{"core::T"}
+
+	for _, m := range g.ComputePackageMetrics() {
+		switch m.Package {
+		case "core":
+			if m.Instability != 0 {
+				t.Errorf("Expected core to be stable, got %f", m.Instability)
+			}
+		case "app":
+			if m.Instability != 1 {
+				t.Errorf("Expected app to be unstable, got %f", m.Instability)
+			}
+		}
+	}
+}
+
+func TestNodeMetric(t *testing.T) {
+	g := newMetricsTestGraph()
+	g.Nodes["a::F"].Complexity = 4
+	g.Nodes["a::F"].SetAttr("coverage", 0.5)
+	g.Nodes["b::G"].SetAttr("coverage", 1)
+	g.Nodes["c::H"].SetAttr("coverage", "n/a")
+
+	fanIn, err := g.NodeMetric("fanin")
+	if err != nil {
+		t.Fatalf("NodeMetric failed: %v", err)
+	}
+	if len(fanIn) != len(g.Nodes) || fanIn["a::T"] != 2 {
+		t.Errorf("Expected the fan-in of every node, got %v", fanIn)
+	}
+	if complexity, _ := g.NodeMetric("complexity"); complexity["a::F"] != 4 {
+		t.Errorf("Expected complexity 4 for a::F, got %v", complexity)
+	}
+
+	// Custom attributes, only where they are numbers
+	for _, name := range []string{"coverage", "attr.coverage"} {
+		coverage, err := g.NodeMetric(name)
+		if err != nil {
+			t.Fatalf("NodeMetric(%q) failed: %v", name, err)
+		}
+		if len(coverage) != 2 || coverage["a::F"] != 0.5 || coverage["b::G"] != 1 {
+			t.Errorf("Expected the numeric coverage of a::F and b::G for %q, got %v", name, coverage)
+		}
+	}
+
+	if _, err := g.NodeMetric("package"); err == nil {
+		t.Error("Expected an error for a field that is not a number")
+	}
+}
@@ -0,0 +1,21 @@
+package graph
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortOccurrences sorts the occurrences by file and position, and removes the duplicates
+// recorded by several variants of a package
+func (g *DependencyGraph) SortOccurrences() {
+	slices.SortFunc(g.Occurrences, func(a, b Occurrence) int {
+		return cmp.Or(
+			cmp.Compare(a.Package, b.Package),
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+			cmp.Compare(a.Symbol, b.Symbol),
+		)
+	})
+	g.Occurrences = slices.Compact(g.Occurrences)
+}
@@ -0,0 +1,153 @@
+package graph
+
+import (
+	"maps"
+	"slices"
+	"sort"
+)
+
+// Reachable returns every node transitively reachable from the given node, mapped to its
+// distance (number of edges on the shortest path). The start node is included at distance 0.
+// An unknown start node yields an empty map.
+func (g *DependencyGraph) Reachable(from string) map[string]int {
+	return g.breadthFirst(from, g.Edges)
+}
+
+// Dependents is the inverse of Reachable: it returns every node that transitively depends on
+// the given node, mapped to its distance. The given node is included at distance 0.
+func (g *DependencyGraph) Dependents(to string) map[string]int {
+	return g.breadthFirst(to, g.reverseEdges())
+}
+
+// Extract returns a new graph containing copies of the given nodes and the edges between
+// them, including their kinds and attributes, the occurrences of the nodes, and a copy of the
+// metadata. Unknown node IDs are ignored. Subgraphs are not computed.
+func (g *DependencyGraph) Extract(nodeIDs []string) *DependencyGraph {
+	extracted := NewDependencyGraph()
+	if g.Metadata != nil {
+		metadata := *g.Metadata
+		metadata.Flags = maps.Clone(g.Metadata.Flags)
+		metadata.Patterns = slices.Clone(g.Metadata.Patterns)
+		extracted.Metadata = &metadata
+	}
+	for _, nodeID := range nodeIDs {
+		if node, exists := g.Nodes[nodeID]; exists {
+			nodeCopy := *node
+			nodeCopy.Attrs = maps.Clone(node.Attrs)
+			extracted.Nodes[nodeID] = &nodeCopy
+		}
+	}
+
+	for sourceID := range extracted.Nodes {
+		for _, targetID := range g.Edges[sourceID] {
+			if _, exists := extracted.Nodes[targetID]; exists {
+				extracted.AddEdge(sourceID, targetID, g.EdgeKindsOf(sourceID, targetID)...)
+				for key, value := range g.EdgeAttrsOf(sourceID, targetID) {
+					extracted.SetEdgeAttr(sourceID, targetID, key, value)
+				}
+			}
+		}
+	}
+	for _, occurrence := range g.Occurrences {
+		if _, exists := extracted.Nodes[occurrence.Symbol]; exists {
+			extracted.Occurrences = append(extracted.Occurrences, occurrence)
+		}
+	}
+
+	return extracted
+}
+
+// breadthFirst computes the distance from start to every node reachable through adjacency
+func (g *DependencyGraph) breadthFirst(start string, adjacency map[string][]string) map[string]int {
+	distances := make(map[string]int)
+	if _, exists := g.Nodes[start]; !exists {
+		return distances
+	}
+
+	distances[start] = 0
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, target := range adjacency[current] {
+			if _, exists := g.Nodes[target]; !exists {
+				continue
+			}
+			if _, seen := distances[target]; !seen {
+				distances[target] = distances[current] + 1
+				queue = append(queue, target)
+			}
+		}
+	}
+
+	return distances
+}
+
+// reverseEdges returns the edges of the graph with their direction flipped (TargetID -> []SourceIDs)
+func (g *DependencyGraph) reverseEdges() map[string][]string {
+	reversed := make(map[string][]string, len(g.Edges))
+	for sourceID, targets := range g.Edges {
+		for _, targetID := range targets {
+			reversed[targetID] = append(reversed[targetID], sourceID)
+		}
+	}
+	return reversed
+}
+
+// EntryPoints returns the IDs of all main functions of main packages, one per binary, sorted
+// by ID. A function main of another package is an ordinary function; in graphs written
+// before the package name was recorded, every function main counts.
+func (g *DependencyGraph) EntryPoints() []string {
+	entries := make([]string, 0)
+	for nodeID, node := range g.Nodes {
+		if node.Kind == KindFunction && node.Name == "main" && (node.PackageName == "main" || node.PackageName == "") {
+			entries = append(entries, nodeID)
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// ShortestPaths returns up to limit shortest paths from one node to another, each listing
+// the node IDs from the first to the last, sorted. A limit of 0 or less returns all of them.
+// Unknown or unconnected nodes yield no paths.
+func (g *DependencyGraph) ShortestPaths(from, to string, limit int) [][]string {
+	distances := g.Reachable(from)
+	if _, reachable := distances[to]; !reachable {
+		return nil
+	}
+
+	// Walk back from the target through the predecessors one step closer to the start
+	reversed := g.reverseEdges()
+	var paths [][]string
+	path := []string{to}
+	var walk func(nodeID string) bool
+	walk = func(nodeID string) bool {
+		if nodeID == from {
+			found := slices.Clone(path)
+			slices.Reverse(found)
+			paths = append(paths, found)
+			return limit <= 0 || len(paths) < limit
+		}
+		predecessors := slices.Clone(reversed[nodeID])
+		sort.Strings(predecessors)
+		for _, predecessor := range slices.Compact(predecessors) {
+			if distance, exists := distances[predecessor]; !exists || distance != distances[nodeID]-1 {
+				continue
+			}
+			path = append(path, predecessor)
+			more := walk(predecessor)
+			path = path[:len(path)-1]
+			if !more {
+				return false
+			}
+		}
+		return true
+	}
+	walk(to)
+
+	sort.Slice(paths, func(i, j int) bool {
+		return slices.Compare(paths[i], paths[j]) < 0
+	})
+	return paths
+}
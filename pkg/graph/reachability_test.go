@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func newReachabilityTestGraph() *DependencyGraph {
+	g := NewDependencyGraph()
+	for _, id := range []string{"A", "B", "C", "D", "E"} {
+		g.Nodes[id] = &Node{ID: id}
+	}
+	g.Edges = map[string][]string{
+		"A": {"B", "C"},
+		"B": {"D"},
+		"C": {"D", "missing"},
+		"E": {"D"},
+	}
+	return g
+}
+
+func TestReachable(t *testing.T) {
+	g := newReachabilityTestGraph()
+
+	expected := map[string]int{"A": 0, "B": 1, "C": 1, "D": 2}
+	if reachable := g.Reachable("A"); !reflect.DeepEqual(reachable, expected) {
+		t.Errorf("Reachable(A) = %v, want %v", reachable, expected)
+	}
+	if reachable := g.Reachable("missing"); len(reachable) != 0 {
+		t.Errorf("Expected nothing reachable from an unknown node, got %v", reachable)
+	}
+}
+
+func TestDependents(t *testing.T) {
+	g := newReachabilityTestGraph()
+
+	expected := map[string]int{"D": 0, "B": 1, "C": 1, "E": 1, "A": 2}
+	if dependents := g.Dependents("D"); !reflect.DeepEqual(dependents, expected) {
+		t.Errorf("Dependents(D) = %v, want %v", dependents, expected)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	g := newReachabilityTestGraph()
+
+	extracted := g.Extract([]string{"A", "B", "D", "missing"})
+
+	ids := make([]string, 0, len(extracted.Nodes))
+	for id := range extracted.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"A", "B", "D"}) {
+		t.Errorf("Unexpected nodes: %v", ids)
+	}
+
+	expectedEdges := map[string][]string{"A": {"B"}, "B": {"D"}}
+	if !reflect.DeepEqual(extracted.Edges, expectedEdges) {
+		t.Errorf("Edges = %v, want %v", extracted.Edges, expectedEdges)
+	}
+
+	// Nodes are copies, so the original graph is left untouched
+	extracted.Nodes["A"].SubgraphID = 42
+	if g.Nodes["A"].SubgraphID == 42 {
+		t.Error("Extract should copy nodes")
+	}
+}
+
+func TestEntryPoints(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["app/cmd/b::main"] = &Node{ID: "app/cmd/b::main", Name: "main", Kind: KindFunction, PackageName: "main"}
+	g.Nodes["app/cmd/a::main"] = &Node{ID: "app/cmd/a::main", Name: "main", Kind: KindFunction, PackageName: "main"}
+	g.Nodes["app/cmd/a::run"] = &Node{ID: "app/cmd/a::run", Name: "run", Kind: KindFunction, PackageName: "main"}
+	g.Nodes["app/x::main"] = &Node{ID: "app/x::main", Name: "main", Kind: KindType, PackageName: "x"}
+	g.Nodes["app/lib::main"] = &Node{ID: "app/lib::main", Name: "main", Kind: KindFunction, PackageName: "lib"}
+	// Graphs written before the package name was recorded
+	g.Nodes["app/cmd/c::main"] = &Node{ID: "app/cmd/c::main", Name: "main", Kind: KindFunction}
+
+	expected := []string{"app/cmd/a::main", "app/cmd/b::main", "app/cmd/c::main"}
+	if entries := g.EntryPoints(); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("EntryPoints() = %v, want %v", entries, expected)
+	}
+}
+
+func TestShortestPaths(t *testing.T) {
+	g := newReachabilityTestGraph()
+
+	expected := [][]string{{"A", "B", "D"}, {"A", "C", "D"}}
+	if paths := g.ShortestPaths("A", "D", 0); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("ShortestPaths(A, D) = %v, want %v", paths, expected)
+	}
+	if paths := g.ShortestPaths("A", "D", 1); len(paths) != 1 {
+		t.Errorf("Expected the limit to be applied, got %v", paths)
+	}
+	if paths := g.ShortestPaths("A", "A", 0); !reflect.DeepEqual(paths, [][]string{{"A"}}) {
+		t.Errorf("ShortestPaths(A, A) = %v, want the node itself", paths)
+	}
+	if paths := g.ShortestPaths("D", "A", 0); paths != nil {
+		t.Errorf("Expected no path against the edges, got %v", paths)
+	}
+}
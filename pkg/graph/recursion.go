@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"slices"
+	"sort"
+)
+
+// IsCallable reports whether the kind is a function or method of any sort, whose body can call
+// other functions
+func (k NodeKind) IsCallable() bool {
+	return k == KindFunction || k == KindMethod || k == KindInit || k.IsTestFunction()
+}
+
+// FindRecursion returns the groups of functions and methods that call themselves: single
+// functions marked Recursive that call themselves directly, and groups of functions calling
+// each other. Only body dependencies between callable nodes count, so that types used in
+// signatures do not close the loop. Groups are sorted like FindCycles.
+func (g *DependencyGraph) FindRecursion() [][]string {
+	calls := NewDependencyGraph()
+	for nodeID, node := range g.Nodes {
+		if node.Kind.IsCallable() {
+			calls.Nodes[nodeID] = node
+		}
+	}
+	for sourceID, targets := range g.Edges {
+		if calls.Nodes[sourceID] == nil {
+			continue
+		}
+		for _, targetID := range targets {
+			if calls.Nodes[targetID] != nil && slices.Contains(g.EdgeKindsOf(sourceID, targetID), EdgeBody) {
+				calls.AddEdge(sourceID, targetID)
+			}
+		}
+	}
+
+	groups := calls.FindCycles()
+	mutual := make(map[string]bool)
+	for _, group := range groups {
+		for _, nodeID := range group {
+			mutual[nodeID] = true
+		}
+	}
+	for nodeID, node := range calls.Nodes {
+		if node.Recursive && !mutual[nodeID] {
+			groups = append(groups, []string{nodeID})
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i]) != len(groups[j]) {
+			return len(groups[i]) > len(groups[j])
+		}
+		return groups[i][0] < groups[j][0]
+	})
+	return groups
+}
+
+// MarkRecursion sets Recursive on every member of a group found by FindRecursion
+func (g *DependencyGraph) MarkRecursion() {
+	for _, group := range g.FindRecursion() {
+		for _, nodeID := range group {
+			g.Nodes[nodeID].Recursive = true
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindRecursion(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"a::Even", "a::Odd", "a::Fact", "a::Walk", "a::Node"} {
+		g.Nodes[id] = &Node{ID: id, Kind: KindFunction}
+	}
+	g.Nodes["a::Node"].Kind = KindStruct
+	g.Nodes["a::Fact"].Recursive = true // Calls itself; the analyzer records no self edge
+	g.AddEdge("a::Even", "a::Odd", EdgeBody)
+	g.AddEdge("a::Odd", "a::Even", EdgeBody)
+	// Walk and Node refer to each other, but only through a type, which is not recursion
+	g.AddEdge("a::Walk", "a::Node", EdgeSignature, EdgeParam)
+	g.AddEdge("a::Node", "a::Walk")
+
+	expected := [][]string{{"a::Even", "a::Odd"}, {"a::Fact"}}
+	if got := g.FindRecursion(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	g.MarkRecursion()
+	if !g.Nodes["a::Even"].Recursive || !g.Nodes["a::Odd"].Recursive || g.Nodes["a::Walk"].Recursive {
+		t.Error("Expected Even and Odd to be marked recursive, and Walk not")
+	}
+}
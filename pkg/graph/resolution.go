@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"maps"
+	"slices"
+)
+
+// Resolutions of a multi-resolution graph, see Resolutions
+const (
+	ResolutionPackage  = "package"  // A node per package
+	ResolutionType     = "type"     // A node per type with its methods, and per package for its other symbols
+	ResolutionFunction = "function" // The symbols themselves
+)
+
+// Resolution is the graph at one resolution of a multi-resolution graph
+type Resolution struct {
+	Name  string
+	Graph *DependencyGraph
+
+	// ID of the node of the next coarser resolution standing for each node; nil for the
+	// coarsest resolution
+	Parents map[string]string
+}
+
+// Resolutions returns the graph at three resolutions, from the coarsest to the finest: its
+// packages, its types, and the graph itself. A type node of the type resolution stands for
+// the type and its methods, and a package node for the other symbols of the package, its
+// functions and variables. A node of a coarser resolution has the number of symbols it stands
+// for in its "symbols" attribute, the sum of their lines, and the center of their positions,
+// if they have any. Edges stand for the edges between the symbols of different nodes, their
+// number as the weight attribute; the edges within a node are left out. Subgraphs are not
+// computed for the coarser resolutions.
+func (g *DependencyGraph) Resolutions() []Resolution {
+	types, typeParents := g.coarsen(func(node *Node) *Node {
+		if node.Kind.IsType() {
+			return node
+		}
+		if node.Kind == KindMethod {
+			if receiver, exists := g.Nodes[node.ReceiverID()]; exists {
+				return receiver
+			}
+		}
+		return packageNode(node)
+	})
+	packages, packageParents := types.coarsen(packageNode)
+
+	// The type resolution counts the symbols, the package resolution adds up those counts
+	for _, pkg := range packages.Nodes {
+		pkg.SetAttr("symbols", 0)
+	}
+	for typeID, pkgPath := range packageParents {
+		symbols, _ := types.Nodes[typeID].Attr("symbols")
+		count, _ := packages.Nodes[pkgPath].Attr("symbols")
+		packages.Nodes[pkgPath].SetAttr("symbols", count.(int)+symbols.(int))
+	}
+
+	return []Resolution{
+		{Name: ResolutionPackage, Graph: packages},
+		{Name: ResolutionType, Graph: types, Parents: packageParents},
+		{Name: ResolutionFunction, Graph: g, Parents: typeParents},
+	}
+}
+
+// coarsen returns a graph with a node per group of nodes, copied from the node groupOf returns
+// for them, and the ID of the group of each node
+func (g *DependencyGraph) coarsen(groupOf func(node *Node) *Node) (*DependencyGraph, map[string]string) {
+	coarse := g.Extract(nil)
+	parents := make(map[string]string, len(g.Nodes))
+	centers := make(map[string][]Position) // Positions of the members of each group
+	for _, nodeID := range slices.Sorted(maps.Keys(g.Nodes)) {
+		node := g.Nodes[nodeID]
+		group := groupOf(node)
+		parent, exists := coarse.Nodes[group.ID]
+		if !exists {
+			parentCopy := *group
+			parentCopy.Lines = 0
+			parentCopy.Position = nil
+			parentCopy.Attrs = nil
+			parent = &parentCopy
+			coarse.Nodes[group.ID] = parent
+		}
+		parents[nodeID] = group.ID
+		parent.External = parent.External && node.External
+		parent.Lines += node.Lines
+		symbols, _ := parent.Attr("symbols")
+		count, _ := symbols.(int)
+		parent.SetAttr("symbols", count+1)
+		if node.Position != nil {
+			centers[group.ID] = append(centers[group.ID], *node.Position)
+		}
+	}
+
+	for groupID, positions := range centers {
+		var center Position
+		for _, position := range positions {
+			center.X += position.X / float64(len(positions))
+			center.Y += position.Y / float64(len(positions))
+		}
+		coarse.Nodes[groupID].Position = &Position{X: round2(center.X), Y: round2(center.Y)}
+	}
+
+	weights := make(map[string]map[string]int)
+	for sourceID, targets := range g.Edges {
+		for _, targetID := range targets {
+			from, to := parents[sourceID], parents[targetID]
+			if from == "" || to == "" || from == to {
+				continue
+			}
+			if weights[from] == nil {
+				weights[from] = make(map[string]int)
+			}
+			weights[from][to] += g.EdgeWeight(sourceID, targetID)
+		}
+	}
+	for _, fromID := range slices.Sorted(maps.Keys(weights)) {
+		for _, toID := range slices.Sorted(maps.Keys(weights[fromID])) {
+			coarse.AddEdge(fromID, toID)
+			coarse.SetEdgeAttr(fromID, toID, WeightAttr, weights[fromID][toID])
+		}
+	}
+	return coarse, parents
+}
+
+// packageNode returns the node of kind package standing for the package of a node, a new node
+// unless the node is of kind package itself
+func packageNode(node *Node) *Node {
+	if node.Kind == KindPackage {
+		return node
+	}
+	return &Node{
+		ID:        node.Package,
+		Name:      node.Package,
+		Kind:      KindPackage,
+		Package:   node.Package,
+		Module:    node.Module,
+		Signature: "package " + node.Package,
+		External:  node.External,
+		DocURL:    packageDocURL(node),
+	}
+}
@@ -0,0 +1,69 @@
+package graph
+
+import "testing"
+
+func TestResolutions(t *testing.T) {
+	// Two packages: a with a type, its two methods and a function, and b with a function
+	// calling the function and a method of a
+	g := NewDependencyGraph()
+	for _, node := range []*Node{
+		{ID: "a::T", Name: "T", Kind: KindStruct, Package: "a", Position: &Position{X: 0, Y: 0}},
+		{ID: "a::(*T).M", Name: "(*T).M", Kind: KindMethod, Package: "a", Lines: 5, Position: &Position{X: 2, Y: 0}},
+		{ID: "a::T.N", Name: "T.N", Kind: KindMethod, Package: "a", Lines: 3, Position: &Position{X: 4, Y: 3}},
+		{ID: "a::F", Name: "F", Kind: KindFunction, Package: "a", Lines: 10},
+		{ID: "b::G", Name: "G", Kind: KindFunction, Package: "b", Lines: 7},
+	} {
+		g.Nodes[node.ID] = node
+	}
+	g.AddEdge("a::(*T).M", "a::T.N")
+	g.AddEdge("a::F", "a::T")
+	g.AddEdge("b::G", "a::F")
+	g.AddEdge("b::G", "a::(*T).M")
+	g.Metadata = &Metadata{Tool: "go-depmap"}
+
+	resolutions := g.Resolutions()
+	if len(resolutions) != 3 || resolutions[0].Name != ResolutionPackage || resolutions[2].Graph != g {
+		t.Fatalf("Expected the package, type and function resolutions, got %+v", resolutions)
+	}
+
+	types := resolutions[1]
+	if len(types.Graph.Nodes) != 3 || types.Graph.Nodes["a::T"] == nil || types.Graph.Nodes["a"] == nil || types.Graph.Nodes["b"] == nil {
+		t.Fatalf("Expected the type T and the packages a and b, got %v", types.Graph.Nodes)
+	}
+	functions := resolutions[2]
+	for id, want := range map[string]string{"a::T": "a::T", "a::(*T).M": "a::T", "a::T.N": "a::T", "a::F": "a", "b::G": "b"} {
+		if got := functions.Parents[id]; got != want {
+			t.Errorf("Parent of %s = %q, want %q", id, got, want)
+		}
+	}
+
+	typeNode := types.Graph.Nodes["a::T"]
+	if symbols, _ := typeNode.Attr("symbols"); symbols != 3 || typeNode.Lines != 8 || typeNode.Kind != KindStruct {
+		t.Errorf("Unexpected type node: %+v", typeNode)
+	}
+	if typeNode.Position == nil || *typeNode.Position != (Position{X: 2, Y: 1}) {
+		t.Errorf("Expected the center of the members, got %v", typeNode.Position)
+	}
+	if pkg := types.Graph.Nodes["a"]; pkg.Position != nil || pkg.Kind != KindPackage || pkg.DocURL != "" {
+		t.Errorf("Unexpected package node: %+v", types.Graph.Nodes["a"])
+	}
+
+	// The edge within T is left out, the others are weighted
+	if types.Graph.CountEdges() != 3 || types.Graph.EdgeWeight("b", "a::T") != 1 || !types.Graph.HasEdge("a", "a::T") {
+		t.Errorf("Unexpected type edges: %v", types.Graph.Edges)
+	}
+
+	packages := resolutions[0]
+	if len(packages.Graph.Nodes) != 2 || packages.Graph.CountEdges() != 1 || packages.Graph.EdgeWeight("b", "a") != 2 {
+		t.Errorf("Unexpected package graph: %v, %v", packages.Graph.Nodes, packages.Graph.EdgeAttrs)
+	}
+	if symbols, _ := packages.Graph.Nodes["a"].Attr("symbols"); symbols != 4 || packages.Graph.Nodes["a"].Lines != 18 {
+		t.Errorf("Unexpected package node: %+v", packages.Graph.Nodes["a"])
+	}
+	if types.Parents["a::T"] != "a" || types.Parents["a"] != "a" || packages.Parents != nil {
+		t.Errorf("Unexpected parents: %v", types.Parents)
+	}
+	if packages.Graph.Metadata == g.Metadata || packages.Graph.Metadata.Tool != "go-depmap" {
+		t.Error("Expected a copy of the metadata")
+	}
+}
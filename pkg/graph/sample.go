@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// Sampling describes the sample Sample took of a graph
+type Sampling struct {
+	Fraction  float64 `json:"fraction"` // Share of the edges kept
+	Nodes     int     `json:"nodes"`    // Nodes of the graph before sampling
+	Edges     int     `json:"edges"`    // Edges of the graph before sampling
+	KeptNodes int     `json:"kept_nodes"`
+	KeptEdges int     `json:"kept_edges"`
+}
+
+// String describes the sample in one sentence, for warnings
+func (s *Sampling) String() string {
+	return fmt.Sprintf("sampled %.3g of the edges: kept %d of %d nodes and %d of %d edges",
+		s.Fraction, s.KeptNodes, s.Nodes, s.KeptEdges, s.Edges)
+}
+
+// Sample returns a sample of about the given fraction of the edges, and how it was taken. A
+// fraction of 1 or more is the graph itself, returned with a nil sampling. Every node keeps
+// the fraction of its outgoing edges, rounded up or down, so that the degrees shrink in
+// proportion and hubs stay hubs; which edges it keeps is spread over the targets, so that
+// incoming edges shrink in proportion too. Nodes left without edges are left out, and the
+// nodes that had none are kept at the same fraction. The sample is deterministic: the same
+// graph gets the same sample. The subgraphs of the sample are recomputed with the given
+// scoring options, and the sampling is recorded in its metadata, if any.
+func (g *DependencyGraph) Sample(fraction float64, scoring ScoringOptions) (*DependencyGraph, *Sampling) {
+	if fraction >= 1 {
+		return g, nil
+	}
+
+	sampling := &Sampling{Fraction: fraction, Nodes: len(g.Nodes), Edges: g.CountEdges()}
+	kept := make(map[string]map[string]bool)
+	connected := make(map[string]bool)
+	for sourceID, targets := range g.Edges {
+		if len(targets) > 0 {
+			connected[sourceID] = true
+		}
+		targets = slices.DeleteFunc(slices.Clone(targets), func(targetID string) bool {
+			connected[targetID] = true
+			_, exists := g.Nodes[targetID]
+			return !exists
+		})
+
+		// The rounding of the quota and the kept targets are picked by hash, in place of a
+		// random draw
+		quota := fraction * float64(len(targets))
+		keep := int(quota)
+		if sampleHash(sourceID) < quota-float64(keep) {
+			keep++
+		}
+		slices.SortFunc(targets, func(a, b string) int {
+			return cmp.Compare(sampleHash(sourceID+"->"+a), sampleHash(sourceID+"->"+b))
+		})
+		for _, targetID := range targets[:keep] {
+			if kept[sourceID] == nil {
+				kept[sourceID] = make(map[string]bool)
+			}
+			kept[sourceID][targetID] = true
+		}
+	}
+
+	nodeIDs := make([]string, 0)
+	for nodeID := range g.Nodes {
+		if len(kept[nodeID]) > 0 || !connected[nodeID] && sampleHash(nodeID) < fraction {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	for _, targets := range kept {
+		for targetID := range targets {
+			nodeIDs = append(nodeIDs, targetID)
+		}
+	}
+	slices.Sort(nodeIDs)
+
+	sampled := g.Extract(slices.Compact(nodeIDs))
+	sampled.retainEdges(func(sourceID, targetID string) bool {
+		return kept[sourceID][targetID]
+	})
+	sampled.ComputeSubgraphsWithScoring(scoring)
+	sampling.KeptNodes = len(sampled.Nodes)
+	sampling.KeptEdges = sampled.CountEdges()
+	if sampled.Metadata != nil {
+		sampled.Metadata.Sampling = sampling
+	}
+	return sampled, sampling
+}
+
+// sampleHash maps a string to a number in [0, 1), evenly spread: the FNV hash of similar
+// strings, such as IDs differing in their last digit, differs in its low bits only, which the
+// splitmix64 finalizer spreads over the high bits
+func sampleHash(s string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11) / math.Exp2(53)
+}
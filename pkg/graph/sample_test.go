@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newSampleTestGraph returns a graph of a hub that every other node depends on, and a chain
+// through the other nodes, with a few isolated nodes
+func newSampleTestGraph() *DependencyGraph {
+	g := NewDependencyGraph()
+	g.Nodes["hub"] = &Node{ID: "hub", Name: "hub", Kind: KindFunction, Package: "p"}
+	for i := range 1000 {
+		id := fmt.Sprintf("n%d", i)
+		g.Nodes[id] = &Node{ID: id, Name: id, Kind: KindFunction, Package: "p"}
+		g.AddEdge(id, "hub")
+		if i > 0 {
+			g.AddEdge(fmt.Sprintf("n%d", i-1), id)
+		}
+	}
+	for i := range 100 {
+		id := fmt.Sprintf("isolated%d", i)
+		g.Nodes[id] = &Node{ID: id, Name: id, Kind: KindFunction, Package: "p"}
+	}
+	g.Metadata = &Metadata{Tool: "go-depmap"}
+	return g
+}
+
+func TestSample_Whole(t *testing.T) {
+	g := newSampleTestGraph()
+	if sampled, sampling := g.Sample(1, DefaultScoringOptions()); sampled != g || sampling != nil {
+		t.Errorf("Sample(1) sampled the graph: %v", sampling)
+	}
+}
+
+func TestSample(t *testing.T) {
+	g := newSampleTestGraph()
+	sampled, sampling := g.Sample(0.1, DefaultScoringOptions())
+	if sampling == nil || sampling.Edges != 1999 || sampling.Nodes != 1101 {
+		t.Fatalf("Unexpected sampling: %+v", sampling)
+	}
+
+	// About a tenth of the edges, and of the incoming edges of the hub
+	edges := sampled.CountEdges()
+	if math.Abs(float64(edges)-199.9) > 40 || sampling.KeptEdges != edges {
+		t.Errorf("Expected about 200 edges, got %d", edges)
+	}
+	if fanIn := sampled.FanIn()["hub"]; math.Abs(float64(fanIn)-100) > 30 {
+		t.Errorf("Expected the hub to keep about 100 incoming edges, got %d", fanIn)
+	}
+
+	// Every kept node has an edge, but the isolated nodes
+	isolated := 0
+	fanIn, fanOut := sampled.FanIn(), sampled.FanOut()
+	for id := range sampled.Nodes {
+		if strings.HasPrefix(id, "isolated") {
+			isolated++
+		} else if fanIn[id]+fanOut[id] == 0 {
+			t.Errorf("Expected %s to keep an edge", id)
+		}
+		if g.Nodes[id] == nil {
+			t.Errorf("Unexpected node %s", id)
+		}
+	}
+	if isolated > 30 {
+		t.Errorf("Expected about a tenth of the isolated nodes, got %d", isolated)
+	}
+	for sourceID, targets := range sampled.Edges {
+		for _, targetID := range targets {
+			if !g.HasEdge(sourceID, targetID) {
+				t.Errorf("Unexpected edge %s -> %s", sourceID, targetID)
+			}
+		}
+	}
+
+	if sampled.Metadata.Sampling != sampling || g.Metadata.Sampling != nil {
+		t.Error("Expected the sampling in the metadata of the sample only")
+	}
+	if len(g.Nodes) != 1101 || g.CountEdges() != 1999 {
+		t.Error("Expected the sampled graph to be left as is")
+	}
+
+	// The same graph gets the same sample
+	again, _ := newSampleTestGraph().Sample(0.1, DefaultScoringOptions())
+	if !reflect.DeepEqual(again.Edges, sampled.Edges) {
+		t.Error("Expected the sample to be deterministic")
+	}
+}
+
+func TestSampling_String(t *testing.T) {
+	sampling := &Sampling{Fraction: 0.1, Nodes: 10, Edges: 20, KeptNodes: 4, KeptEdges: 2}
+	if got, want := sampling.String(), "sampled 0.1 of the edges: kept 4 of 10 nodes and 2 of 20 edges"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
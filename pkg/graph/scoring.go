@@ -0,0 +1,149 @@
+package graph
+
+import "math"
+
+// ScoringStrategy selects how subgraph scores are computed
+type ScoringStrategy string
+
+// Scoring strategy constants define what an "important" subgraph means.
+const (
+	// ScoreWeighted combines size, connectivity and density using ScoringOptions weights (default)
+	ScoreWeighted ScoringStrategy = "weighted"
+	// ScoreSize ranks subgraphs purely by node count
+	ScoreSize ScoringStrategy = "size"
+	// ScoreDensity ranks subgraphs purely by edge density
+	ScoreDensity ScoringStrategy = "density"
+	// ScorePageRank ranks subgraphs by the PageRank mass of their nodes
+	ScorePageRank ScoringStrategy = "pagerank"
+)
+
+// IsValid reports whether the strategy is one of the known scoring strategies
+func (s ScoringStrategy) IsValid() bool {
+	switch s {
+	case ScoreWeighted, ScoreSize, ScoreDensity, ScorePageRank:
+		return true
+	}
+	return false
+}
+
+// ScoringOptions configures subgraph scoring
+type ScoringOptions struct {
+	Strategy      ScoringStrategy
+	NodeWeight    float64 // Multiplier for the log-scaled node count (weighted strategy)
+	EdgeWeight    float64 // Multiplier for the edge count (weighted strategy)
+	DensityWeight float64 // Multiplier for the density bonus (weighted strategy)
+}
+
+// DefaultScoringOptions returns the weighted strategy with the historical weights
+func DefaultScoringOptions() ScoringOptions {
+	return ScoringOptions{
+		Strategy:      ScoreWeighted,
+		NodeWeight:    1.0,
+		EdgeWeight:    2.0,
+		DensityWeight: 5.0,
+	}
+}
+
+// score calculates the score of a subgraph with the given properties.
+// pageRankMass is the summed PageRank of the subgraph's nodes and is only used by ScorePageRank.
+func (o ScoringOptions) score(nodeCount, edgeCount int, pageRankMass float64) float64 {
+	if nodeCount == 0 {
+		return 0.0
+	}
+
+	switch o.Strategy {
+	case ScoreSize:
+		return float64(nodeCount)
+	case ScoreDensity:
+		return density(nodeCount, edgeCount)
+	case ScorePageRank:
+		return pageRankMass
+	default:
+		return o.weightedScore(nodeCount, edgeCount)
+	}
+}
+
+// weightedScore calculates a score for a subgraph based on its properties
+// Score formula: NodeWeight * nodeCount * log2(nodeCount + 1) + EdgeWeight * edgeCount +
+// DensityWeight * density * nodeCount
+// This gives higher weight to:
+// - Larger subgraphs (more nodes)
+// - Better connected subgraphs (more edges)
+// - Uses logarithmic scaling for nodes to prevent huge subgraphs from dominating
+func (o ScoringOptions) weightedScore(nodeCount, edgeCount int) float64 {
+	// Base score from node count with logarithmic scaling
+	nodeScore := o.NodeWeight * float64(nodeCount) * math.Log2(float64(nodeCount+1))
+
+	// Edge score (edges indicate stronger connectivity)
+	edgeScore := o.EdgeWeight * float64(edgeCount)
+
+	// Density bonus: reward graphs that are well-connected relative to their size
+	densityBonus := o.DensityWeight * density(nodeCount, edgeCount) * float64(nodeCount)
+
+	return nodeScore + edgeScore + densityBonus
+}
+
+// density returns the ratio of edges to the maximum possible edges in a directed graph: n * (n - 1)
+func density(nodeCount, edgeCount int) float64 {
+	maxPossibleEdges := nodeCount * (nodeCount - 1)
+	if maxPossibleEdges <= 0 {
+		return 0.0
+	}
+	return float64(edgeCount) / float64(maxPossibleEdges)
+}
+
+// PageRank computes the PageRank of every node using the power iteration method.
+// Edges point from dependent to dependency, so heavily depended-on symbols rank highest.
+// The ranks of all nodes sum to 1.
+func (g *DependencyGraph) PageRank(damping float64, iterations int) map[string]float64 {
+	n := len(g.Nodes)
+	ranks := make(map[string]float64, n)
+	if n == 0 {
+		return ranks
+	}
+
+	outDegree := g.FanOut()
+	for nodeID := range g.Nodes {
+		ranks[nodeID] = 1.0 / float64(n)
+	}
+
+	for i := 0; i < iterations; i++ {
+		next := make(map[string]float64, n)
+
+		// Rank of nodes without outgoing edges is spread evenly across all nodes
+		danglingMass := 0.0
+		for nodeID := range g.Nodes {
+			if outDegree[nodeID] == 0 {
+				danglingMass += ranks[nodeID]
+			}
+		}
+		base := (1.0-damping)/float64(n) + damping*danglingMass/float64(n)
+		for nodeID := range g.Nodes {
+			next[nodeID] = base
+		}
+
+		for sourceID, targets := range g.Edges {
+			if outDegree[sourceID] == 0 {
+				continue
+			}
+			share := damping * ranks[sourceID] / float64(outDegree[sourceID])
+			for _, targetID := range targets {
+				if _, exists := g.Nodes[targetID]; exists {
+					next[targetID] += share
+				}
+			}
+		}
+
+		// Stop early once the ranks have converged
+		delta := 0.0
+		for nodeID, rank := range next {
+			delta += math.Abs(rank - ranks[nodeID])
+		}
+		ranks = next
+		if delta < 1e-9 {
+			break
+		}
+	}
+
+	return ranks
+}
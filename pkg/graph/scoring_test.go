@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScoringOptions_DefaultMatchesWeightedFormula(t *testing.T) {
+	opts := DefaultScoringOptions()
+
+	// 3 nodes, 3 edges: 3*log2(4) + 3*2 + (3/6)*3*5
+	expected := 3*2.0 + 6.0 + 7.5
+	if got := opts.score(3, 3, 0); math.Abs(got-expected) > 1e-9 {
+		t.Errorf("score() = %f, want %f", got, expected)
+	}
+	if got := computeSubgraphScore(3, 3); math.Abs(got-expected) > 1e-9 {
+		t.Errorf("computeSubgraphScore() = %f, want %f", got, expected)
+	}
+}
+
+func TestScoringOptions_Strategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     ScoringOptions
+		nodes    int
+		edges    int
+		prMass   float64
+		expected float64
+	}{
+		{name: "size", opts: ScoringOptions{Strategy: ScoreSize}, nodes: 7, edges: 3, expected: 7},
+		{name: "density", opts: ScoringOptions{Strategy: ScoreDensity}, nodes: 3, edges: 3, expected: 0.5},
+		{name: "density single node", opts: ScoringOptions{Strategy: ScoreDensity}, nodes: 1, edges: 0, expected: 0},
+		{name: "pagerank", opts: ScoringOptions{Strategy: ScorePageRank}, nodes: 3, edges: 3, prMass: 0.25, expected: 0.25},
+		{name: "custom weights", opts: ScoringOptions{Strategy: ScoreWeighted, EdgeWeight: 1}, nodes: 3, edges: 3, expected: 3},
+		{name: "empty", opts: DefaultScoringOptions(), nodes: 0, edges: 0, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.score(tt.nodes, tt.edges, tt.prMass); math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("score() = %f, want %f", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScoringStrategy_IsValid(t *testing.T) {
+	for _, s := range []ScoringStrategy{ScoreWeighted, ScoreSize, ScoreDensity, ScorePageRank} {
+		if !s.IsValid() {
+			t.Errorf("expected %s to be valid", s)
+		}
+	}
+	if ScoringStrategy("bogus").IsValid() {
+		t.Error("expected bogus strategy to be invalid")
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"A", "B", "C", "D"} {
+		g.Nodes[id] = &Node{ID: id}
+	}
+	// Everything depends on D
+	g.Edges["A"] = []string{"D"}
+	g.Edges["B"] = []string{"D"}
+	g.Edges["C"] = []string{"D"}
+
+	ranks := g.PageRank(0.85, 100)
+
+	total := 0.0
+	for _, rank := range ranks {
+		total += rank
+	}
+	if math.Abs(total-1.0) > 1e-6 {
+		t.Errorf("Expected ranks to sum to 1, got %f", total)
+	}
+	for _, id := range []string{"A", "B", "C"} {
+		if ranks["D"] <= ranks[id] {
+			t.Errorf("Expected D to outrank %s: %f <= %f", id, ranks["D"], ranks[id])
+		}
+	}
+}
+
+func TestComputeSubgraphsWithScoring_PageRank(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"A", "B", "C", "X", "Y"} {
+		g.Nodes[id] = &Node{ID: id}
+	}
+	g.Edges["A"] = []string{"B"}
+	g.Edges["B"] = []string{"C"}
+	g.Edges["X"] = []string{"Y"}
+
+	g.ComputeSubgraphsWithScoring(ScoringOptions{Strategy: ScorePageRank})
+
+	if len(g.Subgraphs) != 2 {
+		t.Fatalf("Expected 2 subgraphs, got %d", len(g.Subgraphs))
+	}
+	total := g.Subgraphs[0].Score + g.Subgraphs[1].Score
+	if math.Abs(total-1.0) > 1e-6 {
+		t.Errorf("Expected PageRank masses to sum to 1, got %f", total)
+	}
+	if len(g.Subgraphs[0].NodeIDs) != 3 {
+		t.Errorf("Expected the 3-node chain to rank first, got %v", g.Subgraphs[0].NodeIDs)
+	}
+}
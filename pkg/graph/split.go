@@ -0,0 +1,86 @@
+package graph
+
+import "sort"
+
+// PackageSplit describes a package whose symbols fall into several weakly connected clusters
+// when only the package's internal edges are considered. Each cluster is a candidate package.
+type PackageSplit struct {
+	Package  string     `json:"package"`
+	Clusters [][]string `json:"clusters"` // Node IDs of each cluster, largest first
+	Isolated []string   `json:"isolated"` // Symbols with no internal edges (too small to form a cluster)
+}
+
+// SuggestPackageSplits finds packages whose internal symbols form two or more weakly connected
+// clusters of at least minClusterSize nodes. Symbols in smaller clusters are reported as isolated.
+// Results are sorted by the number of clusters (descending) and then by package name.
+func (g *DependencyGraph) SuggestPackageSplits(minClusterSize int) []PackageSplit {
+	if minClusterSize < 1 {
+		minClusterSize = 1
+	}
+
+	// Build an undirected adjacency list restricted to intra-package edges
+	packageNodes := make(map[string][]string)
+	adjacency := make(map[string][]string)
+	for nodeID, node := range g.Nodes {
+		packageNodes[node.Package] = append(packageNodes[node.Package], nodeID)
+		adjacency[nodeID] = make([]string, 0)
+	}
+	for sourceID, targets := range g.Edges {
+		source, exists := g.Nodes[sourceID]
+		if !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if target, exists := g.Nodes[targetID]; exists && target.Package == source.Package {
+				adjacency[sourceID] = append(adjacency[sourceID], targetID)
+				adjacency[targetID] = append(adjacency[targetID], sourceID)
+			}
+		}
+	}
+
+	splits := make([]PackageSplit, 0)
+	for pkgName, nodeIDs := range packageNodes {
+		sort.Strings(nodeIDs)
+
+		visited := make(map[string]bool)
+		split := PackageSplit{
+			Package:  pkgName,
+			Clusters: make([][]string, 0),
+			Isolated: make([]string, 0),
+		}
+
+		for _, nodeID := range nodeIDs {
+			if visited[nodeID] {
+				continue
+			}
+			component := make([]string, 0)
+			dfs(nodeID, adjacency, visited, &component)
+			sort.Strings(component)
+
+			if len(component) >= minClusterSize && len(component) > 1 {
+				split.Clusters = append(split.Clusters, component)
+			} else {
+				split.Isolated = append(split.Isolated, component...)
+			}
+		}
+
+		if len(split.Clusters) < 2 {
+			continue
+		}
+
+		sort.SliceStable(split.Clusters, func(i, j int) bool {
+			return len(split.Clusters[i]) > len(split.Clusters[j])
+		})
+		sort.Strings(split.Isolated)
+		splits = append(splits, split)
+	}
+
+	sort.Slice(splits, func(i, j int) bool {
+		if len(splits[i].Clusters) != len(splits[j].Clusters) {
+			return len(splits[i].Clusters) > len(splits[j].Clusters)
+		}
+		return splits[i].Package < splits[j].Package
+	})
+
+	return splits
+}
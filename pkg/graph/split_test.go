@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggestPackageSplits(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"a::A1", "a::A2", "a::B1", "a::B2", "a::B3", "a::Lone"} {
+		g.Nodes[id] = &Node{ID: id, Package: "a"}
+	}
+	for _, id := range []string{"b::X", "b::Y"} {
+		g.Nodes[id] = &Node{ID: id, Package: "b"}
+	}
+
+	// Package a: two clusters {A1, A2} and {B1, B2, B3}, plus an isolated helper
+	g.Edges["a::A1"] = []string{"a::A2"}
+	g.Edges["a::B1"] = []string{"a::B2"}
+	g.Edges["a::B3"] = []string{"a::B2", "b::X"}
+	// Package b is cohesive; a cross-package edge must not join a's clusters
+	g.Edges["b::X"] = []string{"b::Y", "a::A1"}
+
+	splits := g.SuggestPackageSplits(2)
+
+	if len(splits) != 1 {
+		t.Fatalf("Expected 1 split suggestion, got %d: %+v", len(splits), splits)
+	}
+
+	expected := PackageSplit{
+		Package:  "a",
+		Clusters: [][]string{{"a::B1", "a::B2", "a::B3"}, {"a::A1", "a::A2"}},
+		Isolated: []string{"a::Lone"},
+	}
+	if !reflect.DeepEqual(splits[0], expected) {
+		t.Errorf("SuggestPackageSplits() = %+v, want %+v", splits[0], expected)
+	}
+}
+
+func TestSuggestPackageSplits_MinClusterSize(t *testing.T) {
+	g := NewDependencyGraph()
+	for _, id := range []string{"a::A1", "a::A2", "a::B1", "a::B2", "a::B3"} {
+		g.Nodes[id] = &Node{ID: id, Package: "a"}
+	}
+	g.Edges["a::A1"] = []string{"a::A2"}
+	g.Edges["a::B1"] = []string{"a::B2", "a::B3"}
+
+	if splits := g.SuggestPackageSplits(3); len(splits) != 0 {
+		t.Errorf("Expected no suggestions with min cluster size 3, got %+v", splits)
+	}
+	if splits := g.SuggestPackageSplits(2); len(splits) != 1 {
+		t.Errorf("Expected 1 suggestion with min cluster size 2, got %d", len(splits))
+	}
+}
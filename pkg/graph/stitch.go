@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"maps"
+	"slices"
+)
+
+// RepoAttr is the node attribute naming the repository a node was analyzed in, set by
+// TagRepo so that merged graphs can be colored by repository
+const RepoAttr = "repo"
+
+// SharedByAttr is the attribute of the package nodes added by StitchSharedPackages, listing
+// the repositories using the package
+const SharedByAttr = "shared_by"
+
+// TagRepo sets the repo attribute of the analyzed nodes that have none. External nodes are
+// left untagged, as they belong to no repository that was analyzed.
+func (g *DependencyGraph) TagRepo(repo string) {
+	for _, node := range g.Nodes {
+		if _, set := node.Attr(RepoAttr); !set && !node.External {
+			node.SetAttr(RepoAttr, repo)
+		}
+	}
+}
+
+// StitchSharedPackages returns a graph where repositories are linked through the libraries
+// they share. The external nodes of a package used by the nodes of at least two repositories,
+// per the repo attribute set by TagRepo, are replaced with one node of kind package for its
+// import path, whose shared_by attribute lists the repositories; the edges to and from them
+// are moved to the package node. Packages with analyzed nodes are left alone, as Merge
+// already joins the repositories at their symbols. Subgraphs are not computed.
+func (g *DependencyGraph) StitchSharedPackages() *DependencyGraph {
+	// Repositories using the external symbols of each package
+	users := make(map[string]map[string]bool)
+	analyzed := make(map[string]bool)
+	for _, node := range g.Nodes {
+		if !node.External {
+			analyzed[node.Package] = true
+		}
+	}
+	for sourceID, targets := range g.Edges {
+		source, exists := g.Nodes[sourceID]
+		if !exists {
+			continue
+		}
+		repo, _ := source.Attr(RepoAttr)
+		name, ok := repo.(string)
+		if !ok {
+			continue
+		}
+		for _, targetID := range targets {
+			target := g.Nodes[targetID]
+			if target == nil || !target.External || target.Kind == KindPackage {
+				continue
+			}
+			if users[target.Package] == nil {
+				users[target.Package] = make(map[string]bool)
+			}
+			users[target.Package][name] = true
+		}
+	}
+
+	// Stitched nodes, by ID, to the ID of the package node replacing them
+	stitched := make(map[string]string)
+	for nodeID, node := range g.Nodes {
+		if node.External && node.Kind != KindPackage && len(users[node.Package]) > 1 && !analyzed[node.Package] {
+			stitched[nodeID] = packageNodeID(node)
+		}
+	}
+	kept := make([]string, 0, len(g.Nodes))
+	for nodeID := range g.Nodes {
+		if _, replaced := stitched[nodeID]; !replaced {
+			kept = append(kept, nodeID)
+		}
+	}
+	result := g.Extract(kept)
+	result.LoadErrors = slices.Clone(g.LoadErrors)
+
+	for _, nodeID := range slices.Sorted(maps.Keys(stitched)) {
+		node := g.Nodes[nodeID]
+		result.addPackageNode(node)
+		result.Nodes[stitched[nodeID]].SetAttr(SharedByAttr, slices.Sorted(maps.Keys(users[node.Package])))
+	}
+	for _, sourceID := range slices.Sorted(maps.Keys(g.Edges)) {
+		for _, targetID := range g.Edges[sourceID] {
+			from, fromStitched := stitched[sourceID]
+			to, toStitched := stitched[targetID]
+			if !fromStitched && !toStitched {
+				continue
+			}
+			// Dangling edges of a foreign graph are dropped, like Extract does
+			if _, exists := g.Nodes[sourceID]; !exists {
+				continue
+			}
+			if _, exists := g.Nodes[targetID]; !exists {
+				continue
+			}
+			if !fromStitched {
+				from = sourceID
+			}
+			if !toStitched {
+				to = targetID
+			}
+			if from == to {
+				continue
+			}
+			result.AddEdge(from, to, g.EdgeKindsOf(sourceID, targetID)...)
+			existing := result.EdgeAttrsOf(from, to)
+			for key, value := range g.EdgeAttrsOf(sourceID, targetID) {
+				if _, set := existing[key]; !set {
+					result.SetEdgeAttr(from, to, key, value)
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestStitchSharedPackages(t *testing.T) {
+	api := NewDependencyGraph()
+	api.Nodes["example.com/api::Serve"] = &Node{ID: "example.com/api::Serve", Package: "example.com/api"}
+	api.Nodes["example.com/lib::Encode"] = &Node{ID: "example.com/lib::Encode", Package: "example.com/lib", External: true}
+	api.Nodes["example.com/once::Do"] = &Node{ID: "example.com/once::Do", Package: "example.com/once", External: true}
+	api.AddEdge("example.com/api::Serve", "example.com/lib::Encode", EdgeBody)
+	api.AddEdge("example.com/api::Serve", "example.com/once::Do")
+	api.TagRepo("example.com/api")
+
+	billing := NewDependencyGraph()
+	billing.Nodes["example.com/billing::Charge"] = &Node{ID: "example.com/billing::Charge", Package: "example.com/billing"}
+	billing.Nodes["example.com/lib::Decode"] = &Node{ID: "example.com/lib::Decode", Package: "example.com/lib", External: true}
+	billing.Nodes["example.com/lib::Encode"] = &Node{ID: "example.com/lib::Encode", Package: "example.com/lib", External: true}
+	billing.AddEdge("example.com/billing::Charge", "example.com/lib::Decode")
+	billing.AddEdge("example.com/billing::Charge", "example.com/lib::Encode", EdgeSignature)
+	billing.TagRepo("example.com/billing")
+
+	if repo, _ := api.Nodes["example.com/api::Serve"].Attr(RepoAttr); repo != "example.com/api" {
+		t.Errorf("Expected analyzed nodes to be tagged with their repository, got %v", repo)
+	}
+	if _, set := api.Nodes["example.com/lib::Encode"].Attr(RepoAttr); set {
+		t.Errorf("Expected external nodes to be left untagged")
+	}
+
+	stitched := Merge(api, billing).StitchSharedPackages()
+	lib := stitched.Nodes["example.com/lib"]
+	if lib == nil || lib.Kind != KindPackage || !lib.External {
+		t.Fatalf("Expected an external package node for the shared library, got %+v", lib)
+	}
+	if sharedBy, _ := lib.Attr(SharedByAttr); !reflect.DeepEqual(sharedBy, []string{"example.com/api", "example.com/billing"}) {
+		t.Errorf("Expected the library to be shared by both repositories, got %v", sharedBy)
+	}
+	if _, exists := stitched.Nodes["example.com/lib::Encode"]; exists {
+		t.Errorf("Expected the symbols of the shared library to be replaced")
+	}
+	if !slices.Equal(stitched.Edges["example.com/billing::Charge"], []string{"example.com/lib"}) ||
+		!slices.Equal(stitched.EdgeKindsOf("example.com/billing::Charge", "example.com/lib"), []EdgeKind{EdgeSignature}) ||
+		!stitched.HasEdge("example.com/api::Serve", "example.com/lib") {
+		t.Errorf("Expected the edges to the library to end at its package node, got %v", stitched.Edges)
+	}
+
+	// A library used by one repository keeps its symbols
+	if _, exists := stitched.Nodes["example.com/once::Do"]; !exists || !stitched.HasEdge("example.com/api::Serve", "example.com/once::Do") {
+		t.Errorf("Expected the library used by one repository to be left alone")
+	}
+}
+
+func TestStitchSharedPackages_DanglingEdges(t *testing.T) {
+	g := NewDependencyGraph()
+	g.Nodes["example.com/api::Serve"] = &Node{ID: "example.com/api::Serve", Package: "example.com/api", Attrs: map[string]any{RepoAttr: "example.com/api"}}
+	g.Nodes["example.com/billing::Charge"] = &Node{ID: "example.com/billing::Charge", Package: "example.com/billing", Attrs: map[string]any{RepoAttr: "example.com/billing"}}
+	g.Nodes["example.com/lib::Encode"] = &Node{ID: "example.com/lib::Encode", Package: "example.com/lib", External: true}
+	g.AddEdge("example.com/api::Serve", "example.com/lib::Encode")
+	g.AddEdge("example.com/billing::Charge", "example.com/lib::Encode")
+	// Edges of a hand-edited graph, from and to nodes it does not have
+	g.AddEdge("example.com/gone::Run", "example.com/lib::Encode")
+	g.AddEdge("example.com/lib::Encode", "example.com/gone::Run")
+
+	stitched := g.StitchSharedPackages()
+	if _, exists := stitched.Nodes["example.com/lib"]; !exists {
+		t.Fatalf("Expected the shared library to be stitched, got %v", slices.Sorted(maps.Keys(stitched.Nodes)))
+	}
+	for sourceID, targets := range stitched.Edges {
+		for _, targetID := range targets {
+			if stitched.Nodes[sourceID] == nil || stitched.Nodes[targetID] == nil {
+				t.Errorf("Expected dangling edges to be dropped, got %s -> %s", sourceID, targetID)
+			}
+		}
+	}
+}
@@ -1,82 +1,79 @@
 package graph
 
 import (
-	"math"
 	"sort"
 )
 
 // ComputeSubgraphs detects connected components in the dependency graph and computes scores
+// using the default scoring options
 func (g *DependencyGraph) ComputeSubgraphs() {
+	g.ComputeSubgraphsWithScoring(DefaultScoringOptions())
+}
+
+// ComputeSubgraphsWithScoring detects connected components in the dependency graph and
+// computes their scores using the given scoring options
+func (g *DependencyGraph) ComputeSubgraphsWithScoring(scoring ScoringOptions) {
 	if len(g.Nodes) == 0 {
 		return
 	}
 
-	// Build undirected adjacency list (treat edges as bidirectional for connectivity)
-	adjacency := make(map[string][]string)
-	for nodeID := range g.Nodes {
-		adjacency[nodeID] = make([]string, 0)
+	// PageRank is computed over the whole graph, so only do it when it is needed
+	var pageRank map[string]float64
+	if scoring.Strategy == ScorePageRank {
+		pageRank = g.PageRank(0.85, 100)
 	}
 
-	// Add forward edges
-	for source, targets := range g.Edges {
-		adjacency[source] = append(adjacency[source], targets...)
-		// Add reverse edges for connectivity detection
-		for _, target := range targets {
-			if _, exists := adjacency[target]; exists {
-				adjacency[target] = append(adjacency[target], source)
-			}
+	// Treat edges as bidirectional for connectivity, dangling edge ends included
+	x := g.newNodeIndex()
+	offsets, neighbors := x.undirected()
+
+	// Find connected components using DFS from each node in sorted order
+	componentOf := make([]int32, len(x.ids))
+	for i := range componentOf {
+		componentOf[i] = -1
+	}
+	var components [][]int32
+	for start := range x.nodes {
+		if componentOf[start] < 0 {
+			components = append(components, x.component(start, int32(len(components)), offsets, neighbors, componentOf))
 		}
 	}
 
-	// Find connected components using DFS
-	visited := make(map[string]bool)
-	subgraphID := 0
-	g.Subgraphs = make([]Subgraph, 0)
-
-	for nodeID := range g.Nodes {
-		if !visited[nodeID] {
-			// Start new subgraph
-			component := make([]string, 0)
-			dfs(nodeID, adjacency, visited, &component)
-
-			// Create subgraph
-			subgraph := Subgraph{
-				ID:      subgraphID,
-				NodeIDs: component,
-			}
-
-			// Count edges within this subgraph
-			nodeSet := make(map[string]bool)
-			for _, nid := range component {
-				nodeSet[nid] = true
+	// Count edges within each subgraph in one pass
+	edgeCounts := make([]int, len(components))
+	for source := range x.ids {
+		for _, target := range x.targetsOf(int32(source)) {
+			if componentOf[source] == componentOf[target] {
+				edgeCounts[componentOf[source]]++
 			}
+		}
+	}
 
-			edgeCount := 0
-			for _, nid := range component {
-				if targets, exists := g.Edges[nid]; exists {
-					for _, target := range targets {
-						if nodeSet[target] {
-							edgeCount++
-						}
-					}
-				}
-			}
-			subgraph.EdgeCount = edgeCount
+	g.Subgraphs = make([]Subgraph, 0, len(components))
+	for subgraphID, members := range components {
+		component := make([]string, len(members))
+		pageRankMass := 0.0
+		for i, member := range members {
+			component[i] = x.ids[member]
+			pageRankMass += pageRank[component[i]]
+		}
 
-			// Compute score
-			subgraph.Score = computeSubgraphScore(len(component), edgeCount)
+		subgraph := Subgraph{
+			ID:        subgraphID,
+			NodeIDs:   component,
+			EdgeCount: edgeCounts[subgraphID],
+		}
+		subgraph.Score = scoring.score(len(component), subgraph.EdgeCount, pageRankMass)
 
-			// Assign subgraph metadata to all nodes in this component
-			for _, nid := range component {
-				if node, exists := g.Nodes[nid]; exists {
-					node.SubgraphID = subgraphID
-					node.SubgraphScore = subgraph.Score
-				}
+		// Assign subgraph metadata to all nodes in this component
+		for _, nid := range component {
+			if node, exists := g.Nodes[nid]; exists {
+				node.SubgraphID = subgraphID
+				node.SubgraphScore = subgraph.Score
 			}
-
-			g.Subgraphs = append(g.Subgraphs, subgraph)
-			subgraphID++
 		}
+
+		g.Subgraphs = append(g.Subgraphs, subgraph)
 	}
 
 	// Sort subgraphs by score (descending) for easier identification of important clusters
@@ -95,6 +92,31 @@ func (g *DependencyGraph) ComputeSubgraphs() {
 	}
 }
 
+// component performs an iterative depth-first search from an index and returns the indices of
+// its connected component in visit order, marking each with the component number
+func (x *nodeIndex) component(start, number int32, offsets, neighbors []int32, componentOf []int32) []int32 {
+	type frame struct{ node, next int32 }
+
+	componentOf[start] = number
+	members := []int32{start}
+	stack := []frame{{start, offsets[start]}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next == offsets[top.node+1] {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		neighbor := neighbors[top.next]
+		top.next++
+		if componentOf[neighbor] < 0 {
+			componentOf[neighbor] = number
+			members = append(members, neighbor)
+			stack = append(stack, frame{neighbor, offsets[neighbor]})
+		}
+	}
+	return members
+}
+
 // dfs performs depth-first search to find all nodes in a connected component
 func dfs(nodeID string, adjacency map[string][]string, visited map[string]bool, component *[]string) {
 	visited[nodeID] = true
@@ -107,33 +129,9 @@ func dfs(nodeID string, adjacency map[string][]string, visited map[string]bool,
 	}
 }
 
-// computeSubgraphScore calculates a score for a subgraph based on its properties
-// Score formula: nodeCount * log2(nodeCount + 1) + edgeCount * 2
-// This gives higher weight to:
-// - Larger subgraphs (more nodes)
-// - Better connected subgraphs (more edges)
-// - Uses logarithmic scaling for nodes to prevent huge subgraphs from dominating
+// computeSubgraphScore calculates a score for a subgraph using the default weighted strategy
 func computeSubgraphScore(nodeCount, edgeCount int) float64 {
-	if nodeCount == 0 {
-		return 0.0
-	}
-
-	// Base score from node count with logarithmic scaling
-	nodeScore := float64(nodeCount) * math.Log2(float64(nodeCount+1))
-
-	// Edge score (edges indicate stronger connectivity)
-	edgeScore := float64(edgeCount) * 2.0
-
-	// Density bonus: reward graphs that are well-connected relative to their size
-	// Maximum possible edges in a directed graph: n * (n - 1)
-	maxPossibleEdges := nodeCount * (nodeCount - 1)
-	if maxPossibleEdges > 0 {
-		density := float64(edgeCount) / float64(maxPossibleEdges)
-		densityBonus := density * float64(nodeCount) * 5.0
-		return nodeScore + edgeScore + densityBonus
-	}
-
-	return nodeScore + edgeScore
+	return DefaultScoringOptions().score(nodeCount, edgeCount, 0)
 }
 
 // GetSubgraphByID returns a subgraph by its ID
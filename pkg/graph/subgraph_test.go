@@ -281,3 +281,30 @@ func TestSubgraphSorting(t *testing.T) {
 		t.Errorf("Expected largest subgraph first, got %d nodes", len(g.Subgraphs[0].NodeIDs))
 	}
 }
+
+func TestComputeSubgraphs_DanglingEdges(t *testing.T) {
+	// Edges to and from IDs that are not nodes still connect the nodes around them
+	g := NewDependencyGraph()
+	g.Nodes["A"] = &Node{ID: "A", Name: "A"}
+	g.Nodes["B"] = &Node{ID: "B", Name: "B"}
+	g.Nodes["C"] = &Node{ID: "C", Name: "C"}
+	g.Edges["A"] = []string{"X"}
+	g.Edges["Y"] = []string{"X", "B"}
+
+	g.ComputeSubgraphs()
+
+	if len(g.Subgraphs) != 2 {
+		t.Fatalf("Expected 2 subgraphs, got %d", len(g.Subgraphs))
+	}
+	if g.Nodes["A"].SubgraphID != g.Nodes["B"].SubgraphID {
+		t.Errorf("Expected A and B to share a subgraph through X and Y")
+	}
+	if g.Nodes["C"].SubgraphID == g.Nodes["A"].SubgraphID {
+		t.Errorf("Expected C to be in its own subgraph")
+	}
+
+	joined := g.GetNodeSubgraph("A")
+	if len(joined.NodeIDs) != 4 || joined.EdgeCount != 3 {
+		t.Errorf("Expected 4 nodes and 3 edges, got %v and %d", joined.NodeIDs, joined.EdgeCount)
+	}
+}
@@ -1,6 +1,12 @@
 // Package graph provides types and utilities for representing code dependency graphs.
 package graph
 
+import (
+	"go/doc"
+	"slices"
+	"strings"
+)
+
 // NodeKind represents the type of a code element (function, method, or type)
 type NodeKind string
 
@@ -8,20 +14,141 @@ type NodeKind string
 const (
 	KindFunction NodeKind = "function"
 	KindMethod   NodeKind = "method"
-	KindType     NodeKind = "type"
+	KindType     NodeKind = "type" // Type of unknown shape; the analyzer emits the specific kinds below
+
+	// Type kinds, derived from the underlying type
+	KindInterface NodeKind = "interface"
+	KindStruct    NodeKind = "struct"
+	KindNamed     NodeKind = "named" // Named non-struct, non-interface type, e.g. type ID string
+	KindAlias     NodeKind = "alias" // Type alias, e.g. type ID = string
+
+	// Functions run by go test; only present when test files are analyzed
+	KindTest      NodeKind = "test"
+	KindBenchmark NodeKind = "benchmark"
+	KindFuzz      NodeKind = "fuzz"
+	KindExample   NodeKind = "example"
+
+	// Package initialization function; there may be several per package, so the name is
+	// qualified by the declaring file, e.g. init@db.go or init@db.go#2
+	KindInit NodeKind = "init"
+
+	// Package-level variable; only present when globals are analyzed
+	KindVar NodeKind = "var"
+
+	// Collapsed standard library package; only present when standard library usage is recorded
+	KindPackage NodeKind = "package"
 )
 
 // Node represents a code element in the dependency graph
 type Node struct {
-	ID            string   `json:"id"`             // Unique signature
-	Name          string   `json:"name"`           // Short name
-	Kind          NodeKind `json:"kind"`           // function, method, or type
-	Package       string   `json:"package"`        // Import path
-	File          string   `json:"file"`           // Source filename
-	Line          int      `json:"line"`           // Line number
-	Signature     string   `json:"signature"`      // Human readable signature
-	SubgraphID    int      `json:"subgraph_id"`    // ID of the subgraph this node belongs to
-	SubgraphScore float64  `json:"subgraph_score"` // Score of the subgraph this node belongs to
+	ID            string    `json:"id"`                      // Unique signature
+	Name          string    `json:"name"`                    // Short name
+	Kind          NodeKind  `json:"kind"`                    // function, method, or type
+	Package       string    `json:"package"`                 // Import path
+	PackageName   string    `json:"package_name,omitempty"`  // Name of the package clause, e.g. main for commands
+	Module        string    `json:"module,omitempty"`        // Path of the module declaring the package, when known
+	Receiver      string    `json:"receiver,omitempty"`      // ID of the receiver type of a method, see ReceiverID
+	File          string    `json:"file"`                    // Source filename
+	Path          string    `json:"path,omitempty"`          // Source file path relative to the module root, slash-separated
+	Line          int       `json:"line"`                    // Line number
+	Signature     string    `json:"signature"`               // Human readable signature
+	SubgraphID    int       `json:"subgraph_id"`             // ID of the subgraph this node belongs to
+	SubgraphScore float64   `json:"subgraph_score"`          // Score of the subgraph this node belongs to
+	External      bool      `json:"external,omitempty"`      // Defined outside the analyzed project
+	Doc           string    `json:"doc,omitempty"`           // Doc comment, without comment markers
+	Snippet       string    `json:"snippet,omitempty"`       // Leading source of a function, when snippets are enabled
+	DocURL        string    `json:"doc_url,omitempty"`       // pkg.go.dev URL of exported symbols
+	Lines         int       `json:"lines,omitempty"`         // Lines of code of a function, including its signature
+	Complexity    int       `json:"complexity,omitempty"`    // Cyclomatic complexity of a function
+	Churn         int       `json:"churn,omitempty"`         // Commits that changed the source file, with -git-churn
+	Owner         string    `json:"owner,omitempty"`         // Owners of the source file from CODEOWNERS, space-separated
+	Spans         int       `json:"spans,omitempty"`         // Trace spans mapped onto the node, with -traces
+	Unsafe        bool      `json:"unsafe,omitempty"`        // Function uses the unsafe package
+	Reflect       bool      `json:"reflect,omitempty"`       // Function uses the reflect package
+	Linkname      bool      `json:"linkname,omitempty"`      // Function is the local side of a //go:linkname directive
+	Deprecated    string    `json:"deprecated,omitempty"`    // Deprecation notice from a "Deprecated: " doc paragraph
+	Generated     bool      `json:"generated,omitempty"`     // Declared in a generated file ("// Code generated ... DO NOT EDIT.")
+	Mock          bool      `json:"mock,omitempty"`          // Declared in a file matching the mock patterns, e.g. *_mock.go or mocks/
+	Recursive     bool      `json:"recursive,omitempty"`     // Function calls itself, directly or through other functions
+	ReturnsError  bool      `json:"returns_error,omitempty"` // Function has a result of type error
+	TakesContext  bool      `json:"takes_context,omitempty"` // Function has a parameter of type context.Context
+	Position      *Position `json:"position,omitempty"`      // Coordinates computed by Layout, nil without a layout
+
+	// Custom attributes set by analyzers, overlays and plugins, see SetAttr
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// IsRisky reports whether the node bypasses the type system: it uses unsafe or reflect, or
+// is linked to another package's symbol with //go:linkname
+func (n *Node) IsRisky() bool {
+	return n.Unsafe || n.Reflect || n.Linkname
+}
+
+// EdgeKind describes how a dependency arises. Plain uses of a symbol have no kind.
+type EdgeKind string
+
+// Edge kind constants
+const (
+	EdgeAliasOf   EdgeKind = "alias-of"   // From a type alias to the aliased type
+	EdgeSignature EdgeKind = "signature"  // Used in a function's receiver, parameter or result types
+	EdgeBody      EdgeKind = "body"       // Used in a function's body
+	EdgeTraced    EdgeKind = "traced"     // Observed in a trace: the source's span is the parent of the target's
+	EdgeInitOrder EdgeKind = "init-order" // From an init function to one that runs before it
+	EdgeReference EdgeKind = "references" // From a function to a function it uses as a value rather than calling it, e.g. a handler
+	EdgeReads     EdgeKind = "reads"      // From a function to a package-level variable it reads
+	EdgeWrites    EdgeKind = "writes"     // From a function to a package-level variable it assigns, increments, takes the address of or calls pointer methods on
+	EdgeBundled   EdgeKind = "bundled"    // Between packages, standing for the edges between their symbols, see BundlePackageEdges
+
+	// Signature edges are further classified by the part of the signature they come from
+	EdgeReceiver EdgeKind = "receiver" // Receiver type of a method
+	EdgeParam    EdgeKind = "param"    // Parameter type
+	EdgeResult   EdgeKind = "result"   // Result (return) type
+)
+
+// IsType reports whether the kind is a type declaration of any shape
+func (k NodeKind) IsType() bool {
+	switch k {
+	case KindType, KindInterface, KindStruct, KindNamed, KindAlias:
+		return true
+	}
+	return false
+}
+
+// IsTestFunction reports whether the kind is one of the go test function kinds
+func (k NodeKind) IsTestFunction() bool {
+	switch k {
+	case KindTest, KindBenchmark, KindFuzz, KindExample:
+		return true
+	}
+	return false
+}
+
+// Summary returns the first sentence of the node's doc comment, for tooltips and tables
+func (n *Node) Summary() string {
+	if n.Doc == "" {
+		return ""
+	}
+	return new(doc.Package).Synopsis(n.Doc)
+}
+
+// IsTest reports whether the node is defined in a _test.go file
+func (n *Node) IsTest() bool {
+	return strings.HasSuffix(n.File, "_test.go")
+}
+
+// ReceiverID returns the ID of the receiver type of a method, "" for other nodes. Graphs
+// written before the receiver was recorded have it parsed from the method name, e.g. Graph of
+// (*Graph).Walk or Graph.Len. The type node may not be part of the graph.
+func (n *Node) ReceiverID() string {
+	if n.Receiver != "" || n.Kind != KindMethod {
+		return n.Receiver
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(n.Name, "("), "*")
+	i := strings.IndexAny(name, "[).")
+	if i <= 0 {
+		return ""
+	}
+	return n.Package + "::" + name[:i]
 }
 
 // Subgraph represents a connected component in the dependency graph
@@ -32,11 +159,56 @@ type Subgraph struct {
 	Score     float64  `json:"score"`      // Computed score based on size and connectivity
 }
 
+// LoadError is an error reported while loading a package. With -continue-on-error the broken
+// package is left out of the analysis and its errors are recorded in the graph.
+type LoadError struct {
+	Package  string `json:"package"`            // Import path of the package reporting the error
+	Kind     string `json:"kind"`               // list, parse, type or unknown
+	Position string `json:"position,omitempty"` // file:line:column, when known
+	Message  string `json:"message"`
+}
+
+// Occurrence is a definition or use of a symbol in the source, recorded by the analyzer for
+// code navigation indexes such as the lsif format. Columns count bytes.
+type Occurrence struct {
+	Symbol     string `json:"symbol"`               // ID of the symbol's node
+	Package    string `json:"package"`              // Import path of the package of the file
+	Path       string `json:"path"`                 // Source file path relative to the module root, slash-separated
+	Line       int    `json:"line"`                 // 1-based
+	Column     int    `json:"column"`               // 1-based start of the identifier
+	EndColumn  int    `json:"end_column"`           // Column after the identifier
+	Definition bool   `json:"definition,omitempty"` // Declares the symbol rather than using it
+}
+
 // DependencyGraph represents the complete dependency graph with nodes and edges
 type DependencyGraph struct {
-	Nodes     map[string]*Node    `json:"nodes"`
-	Edges     map[string][]string `json:"edges"`     // SourceID -> []TargetIDs
-	Subgraphs []Subgraph          `json:"subgraphs"` // Connected components with scores
+	Metadata    *Metadata                            `json:"metadata,omitempty"` // Provenance of the graph, set by the CLI
+	Nodes       map[string]*Node                     `json:"nodes"`
+	Edges       map[string][]string                  `json:"edges"`                 // SourceID -> []TargetIDs
+	EdgeKinds   map[string]map[string][]EdgeKind     `json:"edge_kinds,omitempty"`  // SourceID -> TargetID -> kinds, for edges with a kind
+	EdgeAttrs   map[string]map[string]map[string]any `json:"edge_attrs,omitempty"`  // SourceID -> TargetID -> custom attributes, see SetEdgeAttr
+	Subgraphs   []Subgraph                           `json:"subgraphs"`             // Connected components with scores
+	LoadErrors  []LoadError                          `json:"load_errors,omitempty"` // Errors of the packages left out of the analysis
+	Occurrences []Occurrence                         `json:"occurrences,omitempty"` // Definitions and uses of the symbols in the source, when recorded
+
+	edgeIndex map[string]indexedTargets // Target sets of the sources with many edges, see AddEdge
+}
+
+// edgeIndexMin is the number of targets from which AddEdge looks edges up in a set rather than
+// scanning the list, so that adding the edges of a hub node isn't quadratic
+const edgeIndexMin = 32
+
+// indexedTargets is the set of the targets of a source, with the list it was built from. A
+// list changed without AddEdge, e.g. by retainEdges or Compact, no longer matches the set.
+type indexedTargets struct {
+	targets []string
+	set     map[string]struct{}
+}
+
+// matches reports whether the set was built from the current list of targets. Code changing
+// a list without AddEdge must change its length or replace it, as retainEdges and Compact do.
+func (t indexedTargets) matches(targets []string) bool {
+	return len(targets) >= edgeIndexMin && len(targets) == len(t.targets) && &targets[0] == &t.targets[0]
 }
 
 // NewDependencyGraph creates a new empty dependency graph
@@ -44,10 +216,72 @@ func NewDependencyGraph() *DependencyGraph {
 	return &DependencyGraph{
 		Nodes:     make(map[string]*Node),
 		Edges:     make(map[string][]string),
+		EdgeKinds: make(map[string]map[string][]EdgeKind),
 		Subgraphs: make([]Subgraph, 0),
 	}
 }
 
+// AddEdge adds a dependency edge unless it already exists, and records the given kinds for it.
+// Sources with many targets get a set of them beside the list, kept up to date as edges are
+// added, so that each addition takes constant time.
+func (g *DependencyGraph) AddEdge(sourceID, targetID string, kinds ...EdgeKind) {
+	targets := g.Edges[sourceID]
+	if len(targets) < edgeIndexMin {
+		if !slices.Contains(targets, targetID) {
+			g.Edges[sourceID] = append(targets, targetID)
+		}
+	} else {
+		index, indexed := g.edgeIndex[sourceID]
+		if !indexed || !index.matches(targets) {
+			index = indexedTargets{set: make(map[string]struct{}, len(targets))}
+			for _, existing := range targets {
+				index.set[existing] = struct{}{}
+			}
+		}
+		if _, exists := index.set[targetID]; !exists {
+			targets = append(targets, targetID)
+			g.Edges[sourceID] = targets
+			index.set[targetID] = struct{}{}
+		}
+		index.targets = targets
+		if g.edgeIndex == nil {
+			g.edgeIndex = make(map[string]indexedTargets)
+		}
+		g.edgeIndex[sourceID] = index
+	}
+	if len(kinds) == 0 {
+		return
+	}
+
+	if g.EdgeKinds == nil {
+		g.EdgeKinds = make(map[string]map[string][]EdgeKind)
+	}
+	if g.EdgeKinds[sourceID] == nil {
+		g.EdgeKinds[sourceID] = make(map[string][]EdgeKind)
+	}
+	for _, kind := range kinds {
+		if !slices.Contains(g.EdgeKinds[sourceID][targetID], kind) {
+			g.EdgeKinds[sourceID][targetID] = append(g.EdgeKinds[sourceID][targetID], kind)
+		}
+	}
+}
+
+// HasEdge reports whether the graph has an edge from sourceID to targetID. It only reads the
+// target sets of AddEdge, never builds them, so that readers may share the graph.
+func (g *DependencyGraph) HasEdge(sourceID, targetID string) bool {
+	targets := g.Edges[sourceID]
+	if index, indexed := g.edgeIndex[sourceID]; indexed && index.matches(targets) {
+		_, exists := index.set[targetID]
+		return exists
+	}
+	return slices.Contains(targets, targetID)
+}
+
+// EdgeKindsOf returns the kinds recorded for an edge, or nil for a plain edge
+func (g *DependencyGraph) EdgeKindsOf(sourceID, targetID string) []EdgeKind {
+	return g.EdgeKinds[sourceID][targetID]
+}
+
 // CountEdges returns the total number of edges in the graph
 func (g *DependencyGraph) CountEdges() int {
 	count := 0
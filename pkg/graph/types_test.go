@@ -1,6 +1,9 @@
 package graph
 
-import "testing"
+import (
+	"strconv"
+	"testing"
+)
 
 func Test_NewDependencyGraph(t *testing.T) {
 	g := NewDependencyGraph()
@@ -92,6 +95,13 @@ func Test_NodeKind_Constants(t *testing.T) {
 		{KindFunction, "function"},
 		{KindMethod, "method"},
 		{KindType, "type"},
+		{KindTest, "test"},
+		{KindBenchmark, "benchmark"},
+		{KindFuzz, "fuzz"},
+		{KindExample, "example"},
+		{KindInterface, "interface"},
+		{KindStruct, "struct"},
+		{KindNamed, "named"},
 	}
 
 	for _, tt := range tests {
@@ -195,3 +205,151 @@ func Test_DependencyGraph_NilEdges(t *testing.T) {
 		t.Errorf("Expected 0 edges for nil Edges map, got %d", count)
 	}
 }
+
+func Test_Node_IsTest(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected bool
+	}{
+		{file: "server.go", expected: false},
+		{file: "server_test.go", expected: true},
+		{file: "test.go", expected: false},
+		{file: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			node := &Node{File: tt.file}
+			if got := node.IsTest(); got != tt.expected {
+				t.Errorf("IsTest() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_NodeKind_IsTestFunction(t *testing.T) {
+	for _, kind := range []NodeKind{KindTest, KindBenchmark, KindFuzz, KindExample} {
+		if !kind.IsTestFunction() {
+			t.Errorf("%s should be a test function kind", kind)
+		}
+	}
+	for _, kind := range []NodeKind{KindFunction, KindMethod, KindType} {
+		if kind.IsTestFunction() {
+			t.Errorf("%s should not be a test function kind", kind)
+		}
+	}
+}
+
+func Test_NodeKind_IsType(t *testing.T) {
+	for _, kind := range []NodeKind{KindType, KindInterface, KindStruct, KindNamed} {
+		if !kind.IsType() {
+			t.Errorf("%s should be a type kind", kind)
+		}
+	}
+	for _, kind := range []NodeKind{KindFunction, KindMethod, KindTest} {
+		if kind.IsType() {
+			t.Errorf("%s should not be a type kind", kind)
+		}
+	}
+}
+
+func Test_DependencyGraph_AddEdge(t *testing.T) {
+	g := NewDependencyGraph()
+
+	g.AddEdge("A", "B")
+	g.AddEdge("A", "B")
+	g.AddEdge("A", "C", EdgeAliasOf)
+	g.AddEdge("A", "C", EdgeAliasOf)
+
+	if len(g.Edges["A"]) != 2 {
+		t.Errorf("Expected duplicate edges to be ignored, got %v", g.Edges["A"])
+	}
+	if !g.HasEdge("A", "C") || g.HasEdge("C", "A") {
+		t.Error("HasEdge should respect the edge direction")
+	}
+	if kinds := g.EdgeKindsOf("A", "B"); kinds != nil {
+		t.Errorf("Expected a plain edge, got kinds %v", kinds)
+	}
+	if kinds := g.EdgeKindsOf("A", "C"); len(kinds) != 1 || kinds[0] != EdgeAliasOf {
+		t.Errorf("Expected [alias-of], got %v", kinds)
+	}
+
+	// Graphs built as literals have no kind map yet
+	literal := &DependencyGraph{Nodes: map[string]*Node{}, Edges: map[string][]string{}}
+	literal.AddEdge("A", "B", EdgeAliasOf)
+	if kinds := literal.EdgeKindsOf("A", "B"); len(kinds) != 1 {
+		t.Errorf("Expected the kind map to be created, got %v", kinds)
+	}
+}
+
+func Test_DependencyGraph_AddEdge_Hub(t *testing.T) {
+	g := NewDependencyGraph()
+	for round := 0; round < 2; round++ {
+		for i := 0; i < 3*edgeIndexMin; i++ {
+			g.AddEdge("hub", "T"+strconv.Itoa(i))
+		}
+	}
+	if len(g.Edges["hub"]) != 3*edgeIndexMin {
+		t.Fatalf("Expected duplicate edges of a hub to be ignored, got %d edges", len(g.Edges["hub"]))
+	}
+	if !g.HasEdge("hub", "T0") || g.HasEdge("hub", "missing") {
+		t.Error("HasEdge should look the targets of a hub up")
+	}
+
+	// The set follows the list when it is changed without AddEdge
+	g.retainEdges(func(_, targetID string) bool { return targetID != "T0" })
+	if g.HasEdge("hub", "T0") {
+		t.Error("Expected the removed edge to be gone")
+	}
+	g.AddEdge("hub", "T0")
+	g.AddEdge("hub", "T1")
+	if !g.HasEdge("hub", "T0") || len(g.Edges["hub"]) != 3*edgeIndexMin {
+		t.Errorf("Expected the removed edge to be added back once, got %d edges", len(g.Edges["hub"]))
+	}
+	g.Compact()
+	g.AddEdge("hub", "T2")
+	if len(g.Edges["hub"]) != 3*edgeIndexMin {
+		t.Errorf("Expected the edges of a compacted hub to be kept unique, got %d edges", len(g.Edges["hub"]))
+	}
+}
+
+func Test_Node_Summary(t *testing.T) {
+	tests := []struct {
+		doc      string
+		expected string
+	}{
+		{doc: "", expected: ""},
+		{doc: "Helper returns a greeting.\n", expected: "Helper returns a greeting."},
+		{doc: "Run starts the server. It blocks until the context is done.\n", expected: "Run starts the server."},
+		{doc: "Load reads the file\nand parses it.\n\nDetails follow.\n", expected: "Load reads the file and parses it."},
+	}
+
+	for _, tt := range tests {
+		node := &Node{Doc: tt.doc}
+		if got := node.Summary(); got != tt.expected {
+			t.Errorf("Summary(%q) = %q, want %q", tt.doc, got, tt.expected)
+		}
+	}
+}
+
+func Test_Node_ReceiverID(t *testing.T) {
+	tests := []struct {
+		node     Node
+		expected string
+	}{
+		{node: Node{Name: "(*Graph).Walk", Kind: KindMethod, Package: "pkg"}, expected: "pkg::Graph"},
+		{node: Node{Name: "Graph.Len", Kind: KindMethod, Package: "pkg"}, expected: "pkg::Graph"},
+		{node: Node{Name: "(List[T]).Push", Kind: KindMethod, Package: "pkg"}, expected: "pkg::List"},
+		{node: Node{Name: "(*Map[K, V]).Load", Kind: KindMethod, Package: "pkg"}, expected: "pkg::Map"},
+		{node: Node{Name: "Push", Kind: KindMethod, Package: "pkg"}, expected: ""},
+		{node: Node{Name: "Graph.Len", Kind: KindFunction, Package: "pkg"}, expected: ""},
+		// The recorded receiver wins over the name, e.g. for generic receivers named Push
+		{node: Node{Name: "Push", Kind: KindMethod, Package: "pkg", Receiver: "pkg::Stack"}, expected: "pkg::Stack"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.node.ReceiverID(); got != tt.expected {
+			t.Errorf("ReceiverID() of %q = %q, want %q", tt.node.Name, got, tt.expected)
+		}
+	}
+}
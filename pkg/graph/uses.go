@@ -0,0 +1,24 @@
+package graph
+
+// UsesAttr is the edge attribute listing the lines of the source's file where it uses the
+// target, in ascending order. The analyzer sets it on the edges to deprecated symbols, so
+// that the deprecated report points at each use rather than at the caller's declaration.
+const UsesAttr = "uses"
+
+// EdgeUses returns the lines of the uses recorded on an edge by its uses attribute, or nil
+// when it has none
+func (g *DependencyGraph) EdgeUses(sourceID, targetID string) []int {
+	switch uses := g.EdgeAttrsOf(sourceID, targetID)[UsesAttr].(type) {
+	case []int:
+		return uses
+	case []any: // Decoded from JSON
+		lines := make([]int, 0, len(uses))
+		for _, line := range uses {
+			if number, ok := line.(float64); ok {
+				lines = append(lines, int(number))
+			}
+		}
+		return lines
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package overlay attaches information from outside the Go source, such as version control
+// history, to the nodes of a dependency graph.
+package overlay
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// GitChurn counts the commits that changed each file under dir, keyed by slash-separated path
+// relative to dir. since limits the history to commits newer than a git date such as
+// "6 months ago"; an empty since covers the whole history.
+func GitChurn(dir, since string) (map[string]int, error) {
+	args := []string{"log", "--numstat", "--format=", "--no-renames", "--relative"}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+
+	cmd := exec.Command("git", args...) // #nosec G204 - fixed command, since is passed as a single argument
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log in %s: %w", dir, err)
+	}
+	return parseNumstat(bytes.NewReader(out))
+}
+
+// parseNumstat counts the files listed in "git log --numstat --format=" output, one line per
+// file per commit: added and deleted line counts (or "-" for binary files) and the path
+func parseNumstat(r io.Reader) (map[string]int, error) {
+	churn := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		churn[fields[2]]++
+	}
+	return churn, scanner.Err()
+}
+
+// ApplyChurn sets the churn of every node whose source file has an entry in churn. Nodes
+// without a path, such as external nodes, are left alone.
+func ApplyChurn(g *graph.DependencyGraph, churn map[string]int) {
+	for _, node := range g.Nodes {
+		if node.Path != "" {
+			node.Churn = churn[node.Path]
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package overlay
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func Test_parseNumstat(t *testing.T) {
+	output := "3\t1\tpkg/db/db.go\n" +
+		"10\t0\tREADME.md\n" +
+		"\n" +
+		"1\t1\tpkg/db/db.go\n" +
+		"-\t-\tdocs/logo.png\n"
+
+	churn, err := parseNumstat(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{
+		"pkg/db/db.go":  2,
+		"README.md":     1,
+		"docs/logo.png": 1,
+	}
+	if !reflect.DeepEqual(churn, expected) {
+		t.Errorf("parseNumstat() = %v, want %v", churn, expected)
+	}
+}
+
+func Test_ApplyChurn(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["db::Open"] = &graph.Node{ID: "db::Open", Path: "pkg/db/db.go"}
+	g.Nodes["db::Close"] = &graph.Node{ID: "db::Close", Path: "pkg/db/close.go"}
+	g.Nodes["io::Reader"] = &graph.Node{ID: "io::Reader", External: true}
+
+	ApplyChurn(g, map[string]int{"pkg/db/db.go": 4})
+
+	if churn := g.Nodes["db::Open"].Churn; churn != 4 {
+		t.Errorf("Open churn = %d, want 4", churn)
+	}
+	if churn := g.Nodes["db::Close"].Churn; churn != 0 {
+		t.Errorf("Files without history should have no churn, got %d", churn)
+	}
+}
+
+func Test_GitChurn(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("a.go", "package a\n")
+	write("b.go", "package a\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	write("a.go", "package a\n\nfunc A() {}\n")
+	git("commit", "-q", "-am", "second")
+
+	churn, err := GitChurn(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(churn, map[string]int{"a.go": 2, "b.go": 1}) {
+		t.Errorf("GitChurn() = %v", churn)
+	}
+}
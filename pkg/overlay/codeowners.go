@@ -0,0 +1,140 @@
+package overlay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// CodeOwners is a parsed CODEOWNERS file
+type CodeOwners struct {
+	rules []ownerRule
+}
+
+// ownerRule is a single CODEOWNERS line: a path pattern and the owners of the matching files
+type ownerRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// ParseCodeOwners reads CODEOWNERS rules in the GitHub syntax: one gitignore-style pattern per
+// line followed by its owners. Blank lines and # comments are ignored.
+func ParseCodeOwners(r io.Reader) (*CodeOwners, error) {
+	codeOwners := &CodeOwners{}
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern, err := compileOwnerPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		codeOwners.rules = append(codeOwners.rules, ownerRule{pattern: pattern, owners: fields[1:]})
+	}
+	return codeOwners, scanner.Err()
+}
+
+// LoadCodeOwners parses a CODEOWNERS file
+func LoadCodeOwners(filename string) (*CodeOwners, error) {
+	file, err := os.Open(filename) // #nosec G304 - the file is explicitly chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return ParseCodeOwners(file)
+}
+
+// Owners returns the owners of a slash-separated path relative to the repository root. As on
+// GitHub, the last matching rule wins; a matching rule without owners leaves the path unowned.
+func (c *CodeOwners) Owners(filePath string) []string {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(filePath) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// compileOwnerPattern translates a gitignore-style pattern into a regular expression over
+// repository-relative paths. Patterns containing a slash other than a trailing one are anchored
+// at the root, other patterns match at any depth. A pattern also matches everything below a
+// matching directory, except for dir/* which only matches the files directly in dir.
+func compileOwnerPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if !strings.HasSuffix(pattern, "/*") {
+		expr.WriteString("(?:/.*)?")
+	}
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// CodeOwnersRoot returns the repository root a CODEOWNERS file applies to: the directory that
+// contains it, or its parent when the file is in .github or docs
+func CodeOwnersRoot(filename string) string {
+	dir := filepath.Dir(filename)
+	switch filepath.Base(dir) {
+	case ".github", "docs":
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// ApplyOwners sets the owner of every node with a path. Node paths are relative to moduleDir,
+// CODEOWNERS paths to repoRoot.
+func ApplyOwners(g *graph.DependencyGraph, codeOwners *CodeOwners, repoRoot, moduleDir string) error {
+	prefix, err := filepath.Rel(repoRoot, moduleDir)
+	if err != nil {
+		return err
+	}
+	prefix = filepath.ToSlash(prefix)
+	if strings.HasPrefix(prefix, "../") || prefix == ".." {
+		return fmt.Errorf("module %s is outside of the repository %s", moduleDir, repoRoot)
+	}
+
+	for _, node := range g.Nodes {
+		if node.Path == "" {
+			continue
+		}
+		node.Owner = strings.Join(codeOwners.Owners(path.Join(prefix, node.Path)), " ")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package overlay
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+const testCodeOwners = `# Default owners
+*                @org/platform
+
+*.md             @org/docs
+/pkg/db/         @org/storage @alice
+api/             @org/api
+/cmd/*           @org/cli
+**/testdata/**   @org/qa
+/pkg/db/vendored # unowned
+`
+
+func Test_CodeOwners_Owners(t *testing.T) {
+	codeOwners, err := ParseCodeOwners(strings.NewReader(testCodeOwners))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{path: "main.go", expected: []string{"@org/platform"}},
+		{path: "docs/guide.md", expected: []string{"@org/docs"}},
+		{path: "pkg/db/db.go", expected: []string{"@org/storage", "@alice"}},
+		{path: "pkg/db/sql/query.go", expected: []string{"@org/storage", "@alice"}},
+		{path: "internal/api/handler.go", expected: []string{"@org/api"}},
+		{path: "cmd/main.go", expected: []string{"@org/cli"}},
+		{path: "cmd/server/main.go", expected: []string{"@org/platform"}},
+		{path: "pkg/db/testdata/fixture.sql", expected: []string{"@org/qa"}},
+		{path: "pkg/db/vendored/lib.go", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		got := codeOwners.Owners(tt.path)
+		if len(got) == 0 && len(tt.expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func Test_CodeOwnersRoot(t *testing.T) {
+	tests := map[string]string{
+		"repo/.github/CODEOWNERS": "repo",
+		"repo/docs/CODEOWNERS":    "repo",
+		"repo/CODEOWNERS":         "repo",
+	}
+
+	for filename, expected := range tests {
+		if got := CodeOwnersRoot(filename); got != expected {
+			t.Errorf("CodeOwnersRoot(%q) = %q, want %q", filename, got, expected)
+		}
+	}
+}
+
+func Test_ApplyOwners(t *testing.T) {
+	codeOwners, err := ParseCodeOwners(strings.NewReader(testCodeOwners))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := graph.NewDependencyGraph()
+	g.Nodes["db::Open"] = &graph.Node{ID: "db::Open", Path: "db/db.go"}
+	g.Nodes["io::Reader"] = &graph.Node{ID: "io::Reader", External: true}
+
+	// The module lives in the pkg directory of the repository
+	if err := ApplyOwners(g, codeOwners, "/repo", "/repo/pkg"); err != nil {
+		t.Fatal(err)
+	}
+	if owner := g.Nodes["db::Open"].Owner; owner != "@org/storage @alice" {
+		t.Errorf("Open owner = %q, want %q", owner, "@org/storage @alice")
+	}
+	if owner := g.Nodes["io::Reader"].Owner; owner != "" {
+		t.Errorf("External nodes should have no owner, got %q", owner)
+	}
+
+	if err := ApplyOwners(g, codeOwners, "/repo", "/elsewhere"); err == nil {
+		t.Error("Expected an error for a module outside of the repository")
+	}
+}
@@ -0,0 +1,178 @@
+package overlay
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// Span is a single traced operation, reduced to what is needed to map it onto the graph
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string // Empty for root spans
+	Name     string // Operation name
+}
+
+// otlpTraces is the OTLP/JSON export format (ExportTraceServiceRequest), as written by the
+// OpenTelemetry collector's file exporter
+type otlpTraces struct {
+	ResourceSpans []struct {
+		ScopeSpans []struct {
+			Spans []otlpSpan `json:"spans"`
+		} `json:"scopeSpans"`
+		// Name used by OTLP versions before 0.15
+		InstrumentationLibrarySpans []struct {
+			Spans []otlpSpan `json:"spans"`
+		} `json:"instrumentationLibrarySpans"`
+	} `json:"resourceSpans"`
+}
+
+type otlpSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+}
+
+// jaegerTraces is the JSON format of the Jaeger query API and UI downloads
+type jaegerTraces struct {
+	Data []struct {
+		Spans []struct {
+			TraceID       string `json:"traceID"`
+			SpanID        string `json:"spanID"`
+			OperationName string `json:"operationName"`
+			References    []struct {
+				RefType string `json:"refType"`
+				SpanID  string `json:"spanID"`
+			} `json:"references"`
+		} `json:"spans"`
+	} `json:"data"`
+}
+
+// traceDocument holds either format; a document may only use one of them
+type traceDocument struct {
+	otlpTraces
+	jaegerTraces
+}
+
+// ParseTraces reads spans from OTLP/JSON or Jaeger JSON. The input may hold several JSON
+// documents in a row, as in the JSON lines written by the OpenTelemetry file exporter.
+func ParseTraces(r io.Reader) ([]Span, error) {
+	spans := make([]Span, 0)
+	decoder := json.NewDecoder(r)
+	for {
+		var doc traceDocument
+		if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+			return spans, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range doc.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				spans = appendOTLPSpans(spans, scope.Spans)
+			}
+			for _, library := range resource.InstrumentationLibrarySpans {
+				spans = appendOTLPSpans(spans, library.Spans)
+			}
+		}
+
+		for _, trace := range doc.Data {
+			for _, s := range trace.Spans {
+				span := Span{TraceID: s.TraceID, SpanID: s.SpanID, Name: s.OperationName}
+				for _, ref := range s.References {
+					if ref.RefType == "CHILD_OF" {
+						span.ParentID = ref.SpanID
+						break
+					}
+				}
+				spans = append(spans, span)
+			}
+		}
+	}
+}
+
+func appendOTLPSpans(spans []Span, otlp []otlpSpan) []Span {
+	for _, s := range otlp {
+		spans = append(spans, Span{TraceID: s.TraceID, SpanID: s.SpanID, ParentID: s.ParentSpanID, Name: s.Name})
+	}
+	return spans
+}
+
+// LoadTraces parses a trace export file
+func LoadTraces(filename string) ([]Span, error) {
+	file, err := os.Open(filename) // #nosec G304 - the file is explicitly chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return ParseTraces(file)
+}
+
+// TraceStats summarizes how well the spans matched the graph
+type TraceStats struct {
+	Spans     int // Spans read
+	Matched   int // Spans mapped onto a node
+	Unmatched int // Spans whose operation name matches no node, or more than one
+}
+
+// ApplyTraces counts the spans of every node whose function the span operation name denotes,
+// and marks the edges between the nodes of parent and child spans as traced. A traced edge is
+// added when the static graph has none, e.g. for calls through an interface.
+func ApplyTraces(g *graph.DependencyGraph, spans []Span) TraceStats {
+	stats := TraceStats{Spans: len(spans)}
+
+	resolved := make(map[string]string) // Operation name -> node ID, "" when unmatched
+	nodeOf := make(map[[2]string]string, len(spans))
+	for _, span := range spans {
+		nodeID, seen := resolved[span.Name]
+		if !seen {
+			nodeID = resolveSpanName(g, span.Name)
+			resolved[span.Name] = nodeID
+		}
+		if nodeID == "" {
+			stats.Unmatched++
+			continue
+		}
+		stats.Matched++
+		g.Nodes[nodeID].Spans++
+		nodeOf[[2]string{span.TraceID, span.SpanID}] = nodeID
+	}
+
+	for _, span := range spans {
+		if span.ParentID == "" {
+			continue
+		}
+		childID, childOK := nodeOf[[2]string{span.TraceID, span.SpanID}]
+		parentID, parentOK := nodeOf[[2]string{span.TraceID, span.ParentID}]
+		if childOK && parentOK && childID != parentID {
+			g.AddEdge(parentID, childID, graph.EdgeTraced)
+		}
+	}
+	return stats
+}
+
+// resolveSpanName maps an operation name onto a node ID. Besides the forms accepted by
+// ResolveNode, Go-qualified names as produced by most instrumentation are understood:
+// "db.Query", "example.com/app/db.Query" and "example.com/app/db.(*Conn).Query".
+func resolveSpanName(g *graph.DependencyGraph, name string) string {
+	if nodeID, err := g.ResolveNode(name); err == nil {
+		return nodeID
+	}
+
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot <= 0 {
+		return ""
+	}
+	qualified := name[:slash+1+dot] + "::" + name[slash+1+dot+1:]
+	if nodeID, err := g.ResolveNode(qualified); err == nil {
+		return nodeID
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package overlay
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+const testOTLPTraces = `{"resourceSpans":[{"scopeSpans":[{"spans":[
+	{"traceId":"t1","spanId":"a","name":"api.Handle"},
+	{"traceId":"t1","spanId":"b","parentSpanId":"a","name":"example.com/app/db.(*Conn).Query"},
+	{"traceId":"t1","spanId":"c","parentSpanId":"b","name":"SELECT users"}
+]}]}]}
+{"resourceSpans":[{"instrumentationLibrarySpans":[{"spans":[
+	{"traceId":"t2","spanId":"a","name":"example.com/app/api::Handle"}
+]}]}]}
+`
+
+const testJaegerTraces = `{"data":[{"traceID":"t1","spans":[
+	{"traceID":"t1","spanID":"a","operationName":"api.Handle","references":[]},
+	{"traceID":"t1","spanID":"b","operationName":"(*Conn).Query","references":[{"refType":"CHILD_OF","traceID":"t1","spanID":"a"}]}
+]}]}`
+
+func Test_ParseTraces(t *testing.T) {
+	spans, err := ParseTraces(strings.NewReader(testOTLPTraces))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 OTLP spans from both documents, got %d", len(spans))
+	}
+	expected := Span{TraceID: "t1", SpanID: "b", ParentID: "a", Name: "example.com/app/db.(*Conn).Query"}
+	if spans[1] != expected {
+		t.Errorf("spans[1] = %+v, want %+v", spans[1], expected)
+	}
+
+	spans, err = ParseTraces(strings.NewReader(testJaegerTraces))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = Span{TraceID: "t1", SpanID: "b", ParentID: "a", Name: "(*Conn).Query"}
+	if len(spans) != 2 || spans[1] != expected {
+		t.Errorf("Unexpected Jaeger spans: %+v", spans)
+	}
+
+	if _, err := ParseTraces(strings.NewReader("{")); err == nil {
+		t.Error("Expected an error for malformed JSON")
+	}
+}
+
+func Test_ApplyTraces(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["example.com/app/api::Handle"] = &graph.Node{ID: "example.com/app/api::Handle", Name: "Handle"}
+	g.Nodes["example.com/app/db::(*Conn).Query"] = &graph.Node{ID: "example.com/app/db::(*Conn).Query", Name: "(*Conn).Query"}
+
+	spans, err := ParseTraces(strings.NewReader(testOTLPTraces))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := ApplyTraces(g, spans)
+
+	if stats != (TraceStats{Spans: 4, Matched: 3, Unmatched: 1}) {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if n := g.Nodes["example.com/app/api::Handle"].Spans; n != 2 {
+		t.Errorf("Handle spans = %d, want 2", n)
+	}
+	kinds := g.EdgeKindsOf("example.com/app/api::Handle", "example.com/app/db::(*Conn).Query")
+	if !reflect.DeepEqual(kinds, []graph.EdgeKind{graph.EdgeTraced}) {
+		t.Errorf("Expected a traced edge from Handle to Query, got %v", kinds)
+	}
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"go/token"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// APISymbol lists the project types an exported function or method exposes in its signature
+type APISymbol struct {
+	ID      string   `json:"id"`
+	Params  []string `json:"params"`  // Node IDs of the parameter types
+	Results []string `json:"results"` // Node IDs of the result types
+}
+
+// ExposedTypes reports, for every exported function and method, the project types used as its
+// parameters and results: the types that are part of the API surface
+func ExposedTypes(g *graph.DependencyGraph, opts Options) *Report {
+	symbols := make([]APISymbol, 0)
+	for nodeID, node := range g.Nodes {
+		if (node.Kind != graph.KindFunction && node.Kind != graph.KindMethod) || !isExported(g, node) {
+			continue
+		}
+		symbol := APISymbol{ID: nodeID, Params: make([]string, 0), Results: make([]string, 0)}
+		for _, targetID := range g.Edges[nodeID] {
+			kinds := g.EdgeKindsOf(nodeID, targetID)
+			if slices.Contains(kinds, graph.EdgeParam) {
+				symbol.Params = append(symbol.Params, targetID)
+			}
+			if slices.Contains(kinds, graph.EdgeResult) {
+				symbol.Results = append(symbol.Results, targetID)
+			}
+		}
+		if len(symbol.Params) == 0 && len(symbol.Results) == 0 {
+			continue
+		}
+		sort.Strings(symbol.Params)
+		sort.Strings(symbol.Results)
+		symbols = append(symbols, symbol)
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		return symbols[i].ID < symbols[j].ID
+	})
+	symbols = limit(symbols, opts.Limit)
+
+	r := &Report{
+		Name:    "api",
+		Title:   "Types exposed by exported functions and methods",
+		Columns: []string{"Symbol", "Parameters", "Results"},
+		Rows:    make([][]string, 0, len(symbols)),
+		Data:    symbols,
+	}
+	for _, s := range symbols {
+		r.Rows = append(r.Rows, []string{
+			s.ID,
+			strings.Join(symbolNames(g, s.Params), ", "),
+			strings.Join(symbolNames(g, s.Results), ", "),
+		})
+	}
+	return r
+}
+
+// isExported reports whether a symbol is part of its package's API. Methods are exported
+// when both the method and its receiver type (see graph.Node.ReceiverID) are.
+func isExported(g *graph.DependencyGraph, node *graph.Node) bool {
+	if node.Kind != graph.KindMethod {
+		return token.IsExported(node.Name)
+	}
+	receiverID := node.ReceiverID()
+	if receiverID == "" {
+		return false
+	}
+	receiver := strings.TrimPrefix(receiverID, node.Package+"::")
+	if receiverNode, exists := g.Nodes[receiverID]; exists {
+		receiver = receiverNode.Name
+	}
+	// The method name follows the receiver, if any: "(*T).M", "T.M" or "M"
+	method := node.Name[strings.LastIndex(node.Name, ".")+1:]
+	return token.IsExported(receiver) && token.IsExported(method)
+}
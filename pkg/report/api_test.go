@@ -0,0 +1,70 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestExposedTypes(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	add := func(id, name string, kind graph.NodeKind) {
+		g.Nodes[id] = &graph.Node{ID: id, Name: name, Kind: kind, Package: "p"}
+	}
+	add("p::Handle", "Handle", graph.KindFunction)
+	add("p::handle", "handle", graph.KindFunction)
+	add("p::(*Server).Serve", "(*Server).Serve", graph.KindMethod)
+	add("p::Request", "Request", graph.KindStruct)
+	add("p::Response", "Response", graph.KindStruct)
+	add("p::Server", "Server", graph.KindStruct)
+	g.AddEdge("p::Handle", "p::Request", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("p::Handle", "p::Response", graph.EdgeSignature, graph.EdgeResult, graph.EdgeBody)
+	g.AddEdge("p::Handle", "p::handle", graph.EdgeBody)
+	g.AddEdge("p::handle", "p::Request", graph.EdgeSignature, graph.EdgeParam)
+	g.AddEdge("p::(*Server).Serve", "p::Server", graph.EdgeSignature, graph.EdgeReceiver)
+
+	r := ExposedTypes(g, Options{})
+
+	expected := []APISymbol{
+		{ID: "p::Handle", Params: []string{"p::Request"}, Results: []string{"p::Response"}},
+	}
+	if !reflect.DeepEqual(r.Data, expected) {
+		t.Errorf("Data = %+v, want %+v", r.Data, expected)
+	}
+	if !reflect.DeepEqual(r.Rows, [][]string{{"p::Handle", "Request", "Response"}}) {
+		t.Errorf("Unexpected rows: %v", r.Rows)
+	}
+}
+
+func TestIsExported(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	g.Nodes["p::Stack"] = &graph.Node{ID: "p::Stack", Name: "Stack", Kind: graph.KindStruct, Package: "p"}
+	g.Nodes["p::stack"] = &graph.Node{ID: "p::stack", Name: "stack", Kind: graph.KindStruct, Package: "p"}
+
+	tests := []struct {
+		name     string
+		kind     graph.NodeKind
+		receiver string
+		expected bool
+	}{
+		{name: "Run", kind: graph.KindFunction, expected: true},
+		{name: "run", kind: graph.KindFunction, expected: false},
+		{name: "(*Server).Serve", kind: graph.KindMethod, expected: true},
+		{name: "Server.Serve", kind: graph.KindMethod, expected: true},
+		{name: "(*server).Serve", kind: graph.KindMethod, expected: false},
+		{name: "Server.serve", kind: graph.KindMethod, expected: false},
+		// Methods of generic types are named without their receiver
+		{name: "Push", kind: graph.KindMethod, receiver: "p::Stack", expected: true},
+		{name: "Pop", kind: graph.KindMethod, receiver: "p::stack", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &graph.Node{Name: tt.name, Kind: tt.kind, Package: "p", Receiver: tt.receiver}
+			if got := isExported(g, node); got != tt.expected {
+				t.Errorf("isExported(%s) = %v, want %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	"go-depmap/pkg/graph"
+)
+
+// ContextGap is a call of a context-taking function from one that takes no context, which
+// has to make one up, typically with context.Background or context.TODO
+type ContextGap struct {
+	Caller string `json:"caller"` // ID of the function without a context parameter
+	Callee string `json:"callee"` // ID of the context-taking function
+	File   string `json:"file"`   // Caller's source file, relative to the module root when known
+	Line   int    `json:"line"`   // Line of the caller's declaration
+}
+
+// ContextGaps lists the calls where a function taking a context.Context is used in the body
+// of a function that does not take one: the places left to plumb a context through. The title
+// counts the functions that take a context, to track progress. Test functions and _test.go
+// files are left out, as they create their own contexts.
+func ContextGaps(g *graph.DependencyGraph, opts Options) *Report {
+	gaps := make([]ContextGap, 0)
+	functions, withContext := 0, 0
+	for nodeID, caller := range g.Nodes {
+		if caller.External || !caller.Kind.IsCallable() || caller.Kind.IsTestFunction() || caller.IsTest() {
+			continue
+		}
+		functions++
+		if caller.TakesContext {
+			withContext++
+			continue
+		}
+		for _, targetID := range g.Edges[nodeID] {
+			callee := g.Nodes[targetID]
+			if callee == nil || !callee.TakesContext || !slices.Contains(g.EdgeKindsOf(nodeID, targetID), graph.EdgeBody) {
+				continue
+			}
+			file := caller.Path
+			if file == "" {
+				file = caller.File
+			}
+			gaps = append(gaps, ContextGap{
+				Caller: nodeID,
+				Callee: targetID,
+				File:   file,
+				Line:   caller.Line,
+			})
+		}
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		if gaps[i].Caller != gaps[j].Caller {
+			return gaps[i].Caller < gaps[j].Caller
+		}
+		return gaps[i].Callee < gaps[j].Callee
+	})
+	gaps = limit(gaps, opts.Limit)
+
+	r := &Report{
+		Name:    "context",
+		Title:   fmt.Sprintf("Context-taking functions called without a context (%d of %d functions take a context)", withContext, functions),
+		Columns: []string{"#", "Caller", "Callee", "Location"},
+		Rows:    make([][]string, 0, len(gaps)),
+		Data:    gaps,
+	}
+	for i, gap := range gaps {
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			gap.Caller,
+			gap.Callee,
+			gap.File + ":" + strconv.Itoa(gap.Line),
+		})
+	}
+	return r
+}
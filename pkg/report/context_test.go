@@ -0,0 +1,28 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestContextGaps(t *testing.T) {
+	g := newStatsTestGraph()
+	g.AddEdge("a::F", "a::G", graph.EdgeBody)
+	g.Nodes["a::G"].TakesContext = true
+	g.Nodes["a::F"].File = "f.go"
+	g.Nodes["a::F"].Line = 5
+
+	r := ContextGaps(g, Options{})
+
+	gaps := r.Data.([]ContextGap)
+	if len(gaps) != 1 || gaps[0].Caller != "a::F" || gaps[0].Callee != "a::G" {
+		t.Fatalf("Expected F calling G, got %+v", gaps)
+	}
+	if r.Rows[0][3] != "f.go:5" {
+		t.Errorf("Unexpected row: %v", r.Rows[0])
+	}
+	if r.Title != "Context-taking functions called without a context (1 of 2 functions take a context)" {
+		t.Errorf("Unexpected title: %s", r.Title)
+	}
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// Cycle is a set of symbols depending on each other, directly or through each other
+type Cycle struct {
+	Members  []string `json:"members"`  // Node IDs, sorted
+	Packages []string `json:"packages"` // Packages of the members, sorted
+}
+
+// Cycles lists the dependency cycles of the graph (see graph.FindCycles), largest first
+func Cycles(g *graph.DependencyGraph, opts Options) *Report {
+	cycles := make([]Cycle, 0)
+	for _, members := range g.FindCycles() {
+		cycles = append(cycles, Cycle{Members: members, Packages: packagesOf(g, members)})
+	}
+	cycles = limit(cycles, opts.Limit)
+
+	r := &Report{
+		Name:    "cycles",
+		Title:   "Dependency cycles",
+		Columns: []string{"#", "Size", "Packages", "Symbols"},
+		Rows:    make([][]string, 0, len(cycles)),
+		Data:    cycles,
+	}
+	for i, cycle := range cycles {
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			strconv.Itoa(len(cycle.Members)),
+			strings.Join(cycle.Packages, ", "),
+			strings.Join(symbolNames(g, cycle.Members), ", "),
+		})
+	}
+	return r
+}
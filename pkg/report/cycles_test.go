@@ -0,0 +1,24 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestCycles(t *testing.T) {
+	g := newStatsTestGraph()
+	g.AddEdge("a::F", "a::G", graph.EdgeBody)
+	g.AddEdge("a::G", "a::F", graph.EdgeBody)
+	g.Nodes["a::G"].Package = "b"
+
+	r := Cycles(g, Options{})
+
+	cycles := r.Data.([]Cycle)
+	if len(cycles) != 1 || len(cycles[0].Members) != 2 {
+		t.Fatalf("Expected one cycle of two symbols, got %+v", cycles)
+	}
+	if r.Rows[0][1] != "2" || r.Rows[0][2] != "a, b" || r.Rows[0][3] != "F, G" {
+		t.Errorf("Unexpected row: %v", r.Rows[0])
+	}
+}
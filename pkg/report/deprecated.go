@@ -0,0 +1,83 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+
+	"go-depmap/pkg/graph"
+)
+
+// DeprecatedUse is a dependency on a deprecated symbol
+type DeprecatedUse struct {
+	Symbol string `json:"symbol"` // ID of the deprecated symbol
+	Caller string `json:"caller"` // ID of the symbol depending on it
+	File   string `json:"file"`   // Caller's source file, relative to the module root when known
+	Line   int    `json:"line"`   // Line of the use, or of the caller's declaration when not recorded
+	Notice string `json:"notice"` // Deprecation notice of the symbol
+}
+
+// DeprecatedUsage lists every dependency on a symbol whose doc comment has a "Deprecated: "
+// paragraph, grouped by the deprecated symbol: a checklist of the callers to migrate. Each
+// use recorded in the uses attribute of the edge is listed at its own line; edges without
+// one, as in graphs analyzed before it was recorded, are listed at the caller's declaration.
+func DeprecatedUsage(g *graph.DependencyGraph, opts Options) *Report {
+	uses := make([]DeprecatedUse, 0)
+	for sourceID, targets := range g.Edges {
+		caller := g.Nodes[sourceID]
+		if caller == nil {
+			continue
+		}
+		for _, targetID := range targets {
+			target := g.Nodes[targetID]
+			if target == nil || target.Deprecated == "" {
+				continue
+			}
+			file := caller.Path
+			if file == "" {
+				file = caller.File
+			}
+			lines := g.EdgeUses(sourceID, targetID)
+			if len(lines) == 0 {
+				lines = []int{caller.Line}
+			}
+			for _, line := range lines {
+				uses = append(uses, DeprecatedUse{
+					Symbol: targetID,
+					Caller: sourceID,
+					File:   file,
+					Line:   line,
+					Notice: target.Deprecated,
+				})
+			}
+		}
+	}
+
+	sort.Slice(uses, func(i, j int) bool {
+		if uses[i].Symbol != uses[j].Symbol {
+			return uses[i].Symbol < uses[j].Symbol
+		}
+		if uses[i].Caller != uses[j].Caller {
+			return uses[i].Caller < uses[j].Caller
+		}
+		return uses[i].Line < uses[j].Line
+	})
+	uses = limit(uses, opts.Limit)
+
+	r := &Report{
+		Name:    "deprecated",
+		Title:   "Uses of deprecated symbols",
+		Columns: []string{"#", "Deprecated", "Caller", "Location", "Notice"},
+		Rows:    make([][]string, 0, len(uses)),
+		Data:    uses,
+	}
+	for i, u := range uses {
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			u.Symbol,
+			u.Caller,
+			u.File + ":" + strconv.Itoa(u.Line),
+			u.Notice,
+		})
+	}
+	return r
+}
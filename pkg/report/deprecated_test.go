@@ -0,0 +1,37 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestDeprecatedUsage(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["a::T"].Deprecated = "Use U." // used by F and G
+	g.Nodes["a::F"].Path = "a/f.go"
+	g.Nodes["a::F"].Line = 3
+	g.Nodes["a::G"].File = "g.go"
+	g.Nodes["a::G"].Line = 9
+
+	r := DeprecatedUsage(g, Options{})
+
+	uses := r.Data.([]DeprecatedUse)
+	if len(uses) != 2 {
+		t.Fatalf("Expected 2 uses, got %+v", uses)
+	}
+	if uses[0].Caller != "a::F" || uses[0].Symbol != "a::T" || uses[0].Notice != "Use U." {
+		t.Errorf("Unexpected first use: %+v", uses[0])
+	}
+	if r.Rows[0][3] != "a/f.go:3" || r.Rows[1][3] != "g.go:9" {
+		t.Errorf("Expected the module-relative path, falling back to the file name, got %v", r.Rows)
+	}
+
+	// Recorded uses are listed at their own lines, also when decoded from JSON
+	g.SetEdgeAttr("a::F", "a::T", graph.UsesAttr, []int{5, 7})
+	g.SetEdgeAttr("a::G", "a::T", graph.UsesAttr, []any{float64(11)})
+	r = DeprecatedUsage(g, Options{})
+	if len(r.Rows) != 3 || r.Rows[0][3] != "a/f.go:5" || r.Rows[1][3] != "a/f.go:7" || r.Rows[2][3] != "g.go:11" {
+		t.Errorf("Expected a row per use, got %v", r.Rows)
+	}
+}
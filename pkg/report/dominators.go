@@ -0,0 +1,58 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// Gateway is a symbol that every path from the root to the dominated symbols passes through
+type Gateway struct {
+	ID        string   `json:"id"`
+	Kind      string   `json:"kind"`
+	Dominated []string `json:"dominated"` // Symbols reachable from the root only through this gateway
+}
+
+// Dominators reports, for the entry point in opts.Root, the symbols that act as gateways:
+// everything they dominate is reachable from the root only through them, and becomes dead
+// code when the gateway is removed. Gateways are ordered by the number of dominated symbols.
+func Dominators(g *graph.DependencyGraph, opts Options) *Report {
+	gateways := make([]Gateway, 0)
+	for nodeID, dominated := range graph.DominatedSets(g.Dominators(opts.Root)) {
+		if nodeID == opts.Root {
+			continue
+		}
+		gateways = append(gateways, Gateway{
+			ID:        nodeID,
+			Kind:      string(g.Nodes[nodeID].Kind),
+			Dominated: dominated,
+		})
+	}
+
+	sort.Slice(gateways, func(i, j int) bool {
+		if len(gateways[i].Dominated) != len(gateways[j].Dominated) {
+			return len(gateways[i].Dominated) > len(gateways[j].Dominated)
+		}
+		return gateways[i].ID < gateways[j].ID
+	})
+	gateways = limit(gateways, opts.Limit)
+
+	r := &Report{
+		Name:    "dominators",
+		Title:   "Symbols reachable from " + opts.Root + " only through a gateway",
+		Columns: []string{"Gateway", "Kind", "Dominated", "Symbols"},
+		Rows:    make([][]string, 0, len(gateways)),
+		Data:    gateways,
+	}
+	for _, gateway := range gateways {
+		r.Rows = append(r.Rows, []string{
+			gateway.ID,
+			gateway.Kind,
+			strconv.Itoa(len(gateway.Dominated)),
+			strings.Join(symbolNames(g, gateway.Dominated), ", "),
+		})
+	}
+	return r
+}
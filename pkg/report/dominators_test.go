@@ -0,0 +1,38 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestDominators(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	for _, id := range []string{"main", "a", "b", "c", "shared"} {
+		g.Nodes[id] = &graph.Node{ID: id, Name: id, Kind: graph.KindFunction}
+	}
+	g.Edges["main"] = []string{"a", "shared"}
+	g.Edges["a"] = []string{"b", "shared"}
+	g.Edges["b"] = []string{"c"}
+
+	r := Dominators(g, Options{Root: "main"})
+
+	gateways := r.Data.([]Gateway)
+	if len(gateways) != 2 {
+		t.Fatalf("Expected 2 gateways, got %+v", gateways)
+	}
+	if gateways[0].ID != "a" || !reflect.DeepEqual(gateways[0].Dominated, []string{"b", "c"}) {
+		t.Errorf("Unexpected first gateway: %+v", gateways[0])
+	}
+	if gateways[1].ID != "b" || !reflect.DeepEqual(gateways[1].Dominated, []string{"c"}) {
+		t.Errorf("Unexpected second gateway: %+v", gateways[1])
+	}
+	if r.Rows[0][2] != "2" || r.Rows[0][3] != "b, c" {
+		t.Errorf("Unexpected first row: %v", r.Rows[0])
+	}
+
+	if r := Dominators(g, Options{Root: "missing"}); len(r.Rows) != 0 {
+		t.Errorf("Expected no rows for an unknown root, got %v", r.Rows)
+	}
+}
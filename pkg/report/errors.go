@@ -0,0 +1,142 @@
+package report
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// maxErrorChainDepth bounds the number of calls followed below a swallow point
+const maxErrorChainDepth = 32
+
+// ErrorSwallowPoint is a call of an error-returning function from one that returns no error,
+// where the error must be handled, logged or dropped
+type ErrorSwallowPoint struct {
+	Caller string   `json:"caller"` // ID of the function without an error result
+	Callee string   `json:"callee"` // ID of the error-returning function
+	Chain  []string `json:"chain"`  // IDs of the call chain, from the caller down to where the error originates
+	File   string   `json:"file"`   // Caller's source file, relative to the module root when known
+	Line   int      `json:"line"`   // Line of the caller's declaration
+}
+
+// ErrorSwallowPoints lists the calls where an error-returning function is used in the body of
+// a function that does not return an error itself: the points where errors may be swallowed.
+// Each point carries the call chain the error travels up to it: from the callee, the shortest
+// path through error-returning functions calling each other down to one that calls no other,
+// where the error originates. Calls back into the chain are not followed; when only recursive
+// groups or calls deeper than maxErrorChainDepth remain, the chain ends at the deepest function.
+// Test functions and _test.go files are left out, as they report errors through testing.T.
+func ErrorSwallowPoints(g *graph.DependencyGraph, opts Options) *Report {
+	points := make([]ErrorSwallowPoint, 0)
+	for sourceID, targets := range g.Edges {
+		caller := g.Nodes[sourceID]
+		if caller == nil || caller.ReturnsError || !caller.Kind.IsCallable() || caller.Kind.IsTestFunction() || caller.IsTest() {
+			continue
+		}
+		for _, targetID := range targets {
+			callee := g.Nodes[targetID]
+			if callee == nil || !callee.ReturnsError || !slices.Contains(g.EdgeKindsOf(sourceID, targetID), graph.EdgeBody) {
+				continue
+			}
+			file := caller.Path
+			if file == "" {
+				file = caller.File
+			}
+			points = append(points, ErrorSwallowPoint{
+				Caller: sourceID,
+				Callee: targetID,
+				Chain:  append([]string{sourceID}, errorChain(g, targetID)...),
+				File:   file,
+				Line:   caller.Line,
+			})
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Caller != points[j].Caller {
+			return points[i].Caller < points[j].Caller
+		}
+		return points[i].Callee < points[j].Callee
+	})
+	points = limit(points, opts.Limit)
+
+	r := &Report{
+		Name:    "errors",
+		Title:   "Error-returning functions called by functions without an error result",
+		Columns: []string{"#", "Caller", "Callee", "Location", "Chain"},
+		Rows:    make([][]string, 0, len(points)),
+		Data:    points,
+	}
+	for i, p := range points {
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			p.Caller,
+			p.Callee,
+			p.File + ":" + strconv.Itoa(p.Line),
+			strings.Join(p.Chain, " -> "),
+		})
+	}
+	return r
+}
+
+// errorChain returns the shortest chain of error-returning functions called in each other's
+// body, from the given function down to one calling no other, walked breadth-first. Without
+// such a function in reach, the chain ends at the deepest function reached.
+func errorChain(g *graph.DependencyGraph, fromID string) []string {
+	parent := map[string]string{fromID: ""}
+	depth := map[string]int{fromID: 0}
+	queue := []string{fromID}
+	deepest := fromID
+	for len(queue) > 0 {
+		nodeID := queue[0]
+		queue = queue[1:]
+		if depth[nodeID] > depth[deepest] {
+			deepest = nodeID
+		}
+
+		callees := errorCallees(g, nodeID)
+		if len(callees) == 0 {
+			return chainTo(parent, nodeID)
+		}
+		if depth[nodeID] == maxErrorChainDepth {
+			continue
+		}
+		for _, targetID := range callees {
+			if _, seen := parent[targetID]; !seen {
+				parent[targetID] = nodeID
+				depth[targetID] = depth[nodeID] + 1
+				queue = append(queue, targetID)
+			}
+		}
+	}
+	return chainTo(parent, deepest)
+}
+
+// errorCallees returns the sorted IDs of the error-returning functions called in the body of
+// the given one, other than itself
+func errorCallees(g *graph.DependencyGraph, nodeID string) []string {
+	callees := make([]string, 0)
+	for _, targetID := range g.Edges[nodeID] {
+		target := g.Nodes[targetID]
+		if targetID == nodeID || target == nil || !target.ReturnsError || !slices.Contains(g.EdgeKindsOf(nodeID, targetID), graph.EdgeBody) {
+			continue
+		}
+		callees = append(callees, targetID)
+	}
+	slices.Sort(callees)
+	return callees
+}
+
+// chainTo follows the parents of the breadth-first walk back from the given node, returning
+// the chain from the start of the walk to it
+func chainTo(parent map[string]string, nodeID string) []string {
+	chain := make([]string, 0)
+	for current := nodeID; current != ""; current = parent[current] {
+		chain = append(chain, current)
+	}
+	slices.Reverse(chain)
+	return chain
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"slices"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestErrorSwallowPoints(t *testing.T) {
+	g := newStatsTestGraph()
+	g.AddEdge("a::F", "a::G", graph.EdgeBody)
+	g.Nodes["a::G"].ReturnsError = true
+	g.Nodes["a::F"].Path = "a/f.go"
+	g.Nodes["a::F"].Line = 12
+
+	r := ErrorSwallowPoints(g, Options{})
+
+	points := r.Data.([]ErrorSwallowPoint)
+	if len(points) != 1 || points[0].Caller != "a::F" || points[0].Callee != "a::G" {
+		t.Fatalf("Expected F calling G, got %+v", points)
+	}
+	if r.Rows[0][3] != "a/f.go:12" {
+		t.Errorf("Unexpected row: %v", r.Rows[0])
+	}
+
+	g.Nodes["a::F"].ReturnsError = true
+	if r := ErrorSwallowPoints(g, Options{}); len(r.Rows) != 0 {
+		t.Errorf("Callers returning an error propagate it, got %v", r.Rows)
+	}
+}
+
+func TestErrorSwallowPoints_Chain(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	for _, name := range []string{"Main", "Load", "Parse", "Scan", "Open", "Retry", "Again"} {
+		g.Nodes["a::"+name] = &graph.Node{ID: "a::" + name, Name: name, Kind: graph.KindFunction, Package: "a", ReturnsError: name != "Main"}
+	}
+	g.AddEdge("a::Main", "a::Load", graph.EdgeBody)
+	g.AddEdge("a::Main", "a::Retry", graph.EdgeBody)
+	// Load reaches Open directly and through Parse and the recursive Scan
+	g.AddEdge("a::Load", "a::Parse", graph.EdgeBody)
+	g.AddEdge("a::Load", "a::Open", graph.EdgeBody)
+	g.AddEdge("a::Parse", "a::Scan", graph.EdgeBody)
+	g.AddEdge("a::Scan", "a::Scan", graph.EdgeBody)
+	g.AddEdge("a::Scan", "a::Open", graph.EdgeBody)
+	// Retry and Again only call each other
+	g.AddEdge("a::Retry", "a::Again", graph.EdgeBody)
+	g.AddEdge("a::Again", "a::Retry", graph.EdgeBody)
+
+	points := ErrorSwallowPoints(g, Options{}).Data.([]ErrorSwallowPoint)
+	if len(points) != 2 {
+		t.Fatalf("Expected the calls of Load and Retry, got %+v", points)
+	}
+	if want := []string{"a::Main", "a::Load", "a::Open"}; !slices.Equal(points[0].Chain, want) {
+		t.Errorf("Expected the shortest chain to where the error originates %v, got %v", want, points[0].Chain)
+	}
+	if want := []string{"a::Main", "a::Retry", "a::Again"}; !slices.Equal(points[1].Chain, want) {
+		t.Errorf("Expected the chain to stop at the recursive group %v, got %v", want, points[1].Chain)
+	}
+}
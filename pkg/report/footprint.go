@@ -0,0 +1,144 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// BinaryFootprint is the number of symbols reachable from the main function of a binary
+type BinaryFootprint struct {
+	Binary  string `json:"binary"` // Package path of the main package
+	Entry   string `json:"entry"`  // Node ID of the main function
+	Symbols int    `json:"symbols"`
+}
+
+// FootprintIntersection groups the symbols reachable from exactly the same set of binaries
+type FootprintIntersection struct {
+	Binaries []string `json:"binaries"`
+	Symbols  []string `json:"symbols"`
+}
+
+// FootprintData compares the reachable sets of all binaries in the project
+type FootprintData struct {
+	Binaries      []BinaryFootprint       `json:"binaries"`
+	Overlap       [][]int                 `json:"overlap"`       // Overlap[i][j] = symbols reachable from both binary i and j
+	Intersections []FootprintIntersection `json:"intersections"` // Exclusive intersections, largest first
+}
+
+// Footprint reports how the code of a multi-binary project is shared, UpSet style: every
+// symbol reachable from a main function is counted once, under the exact set of binaries
+// that reach it. Large intersections of several binaries are candidates for shared libraries.
+func Footprint(g *graph.DependencyGraph, opts Options) *Report {
+	data := computeFootprints(g)
+
+	total := 0
+	for _, intersection := range data.Intersections {
+		total += len(intersection.Symbols)
+	}
+	data.Intersections = limit(data.Intersections, opts.Limit)
+
+	r := &Report{
+		Name:    "footprint",
+		Title:   "Code shared between binaries",
+		Columns: []string{"Binaries", "Symbols", "Share"},
+		Rows:    make([][]string, 0, len(data.Intersections)),
+		Data:    data,
+	}
+
+	for _, intersection := range data.Intersections {
+		r.Rows = append(r.Rows, []string{
+			strings.Join(intersection.Binaries, ", "),
+			strconv.Itoa(len(intersection.Symbols)),
+			fmt.Sprintf("%.1f%%", 100*float64(len(intersection.Symbols))/float64(total)),
+		})
+	}
+	return r
+}
+
+// Overlap reports the pairwise overlap matrix of the binaries' reachable sets.
+// The diagonal holds the footprint of each binary.
+func Overlap(g *graph.DependencyGraph, _ Options) *Report {
+	data := computeFootprints(g)
+
+	r := &Report{
+		Name:    "overlap",
+		Title:   "Symbols reachable from both binaries",
+		Columns: []string{"Binary"},
+		Rows:    make([][]string, 0, len(data.Binaries)),
+		Data:    data,
+	}
+	for i := range data.Binaries {
+		r.Columns = append(r.Columns, "#"+strconv.Itoa(i+1))
+	}
+	for i, binary := range data.Binaries {
+		row := []string{"#" + strconv.Itoa(i+1) + " " + binary.Binary}
+		for _, count := range data.Overlap[i] {
+			row = append(row, strconv.Itoa(count))
+		}
+		r.Rows = append(r.Rows, row)
+	}
+	return r
+}
+
+// computeFootprints computes the reachable set of every entry point and how they overlap
+func computeFootprints(g *graph.DependencyGraph) *FootprintData {
+	entries := g.EntryPoints()
+	data := &FootprintData{
+		Binaries:      make([]BinaryFootprint, 0, len(entries)),
+		Overlap:       make([][]int, len(entries)),
+		Intersections: make([]FootprintIntersection, 0),
+	}
+
+	// reachedBy maps every symbol to the indexes of the binaries that reach it
+	reachedBy := make(map[string][]int)
+	for i, entry := range entries {
+		reachable := g.Reachable(entry)
+		for nodeID := range reachable {
+			reachedBy[nodeID] = append(reachedBy[nodeID], i)
+		}
+		data.Binaries = append(data.Binaries, BinaryFootprint{
+			Binary:  g.Nodes[entry].Package,
+			Entry:   entry,
+			Symbols: len(reachable),
+		})
+		data.Overlap[i] = make([]int, len(entries))
+	}
+
+	groups := make(map[string]*FootprintIntersection)
+	for nodeID, binaries := range reachedBy {
+		sort.Ints(binaries)
+		for _, i := range binaries {
+			for _, j := range binaries {
+				data.Overlap[i][j]++
+			}
+		}
+
+		names := make([]string, 0, len(binaries))
+		for _, i := range binaries {
+			names = append(names, data.Binaries[i].Binary)
+		}
+		key := strings.Join(names, "\x00")
+		if groups[key] == nil {
+			groups[key] = &FootprintIntersection{Binaries: names, Symbols: make([]string, 0)}
+		}
+		groups[key].Symbols = append(groups[key].Symbols, nodeID)
+	}
+
+	for _, group := range groups {
+		sort.Strings(group.Symbols)
+		data.Intersections = append(data.Intersections, *group)
+	}
+	sort.Slice(data.Intersections, func(i, j int) bool {
+		a, b := data.Intersections[i], data.Intersections[j]
+		if len(a.Symbols) != len(b.Symbols) {
+			return len(a.Symbols) > len(b.Symbols)
+		}
+		return strings.Join(a.Binaries, ",") < strings.Join(b.Binaries, ",")
+	})
+
+	return data
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestFootprint(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	add := func(id, name, pkg string) {
+		g.Nodes[id] = &graph.Node{ID: id, Name: name, Kind: graph.KindFunction, Package: pkg}
+	}
+	add("app/cmd/api::main", "main", "app/cmd/api")
+	add("app/cmd/cli::main", "main", "app/cmd/cli")
+	add("app/lib::Shared", "Shared", "app/lib")
+	add("app/lib::Helper", "Helper", "app/lib")
+	add("app/api::Serve", "Serve", "app/api")
+	g.Edges["app/cmd/api::main"] = []string{"app/api::Serve", "app/lib::Shared"}
+	g.Edges["app/cmd/cli::main"] = []string{"app/lib::Shared"}
+	g.Edges["app/lib::Shared"] = []string{"app/lib::Helper"}
+
+	r := Footprint(g, Options{})
+
+	data := r.Data.(*FootprintData)
+	if len(data.Binaries) != 2 || data.Binaries[0].Symbols != 4 || data.Binaries[1].Symbols != 3 {
+		t.Fatalf("Unexpected binaries: %+v", data.Binaries)
+	}
+
+	expectedOverlap := [][]int{{4, 2}, {2, 3}}
+	if !reflect.DeepEqual(data.Overlap, expectedOverlap) {
+		t.Errorf("Overlap = %v, want %v", data.Overlap, expectedOverlap)
+	}
+
+	expectedRows := [][]string{
+		{"app/cmd/api", "2", "40.0%"},
+		{"app/cmd/api, app/cmd/cli", "2", "40.0%"},
+		{"app/cmd/cli", "1", "20.0%"},
+	}
+	if !reflect.DeepEqual(r.Rows, expectedRows) {
+		t.Errorf("Rows = %v, want %v", r.Rows, expectedRows)
+	}
+	if !reflect.DeepEqual(data.Intersections[1].Symbols, []string{"app/lib::Helper", "app/lib::Shared"}) {
+		t.Errorf("Unexpected shared symbols: %v", data.Intersections[1].Symbols)
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	add := func(id, name, pkg string) {
+		g.Nodes[id] = &graph.Node{ID: id, Name: name, Kind: graph.KindFunction, Package: pkg}
+	}
+	add("app/cmd/api::main", "main", "app/cmd/api")
+	add("app/cmd/cli::main", "main", "app/cmd/cli")
+	add("app/lib::Shared", "Shared", "app/lib")
+	add("app/lib::Helper", "Helper", "app/lib")
+	add("app/api::Serve", "Serve", "app/api")
+	g.Edges["app/cmd/api::main"] = []string{"app/api::Serve", "app/lib::Shared"}
+	g.Edges["app/cmd/cli::main"] = []string{"app/lib::Shared"}
+	g.Edges["app/lib::Shared"] = []string{"app/lib::Helper"}
+
+	r := Overlap(g, Options{})
+
+	expectedColumns := []string{"Binary", "#1", "#2"}
+	if !reflect.DeepEqual(r.Columns, expectedColumns) {
+		t.Errorf("Columns = %v, want %v", r.Columns, expectedColumns)
+	}
+	expectedRows := [][]string{
+		{"#1 app/cmd/api", "4", "2"},
+		{"#2 app/cmd/cli", "2", "3"},
+	}
+	if !reflect.DeepEqual(r.Rows, expectedRows) {
+		t.Errorf("Rows = %v, want %v", r.Rows, expectedRows)
+	}
+}
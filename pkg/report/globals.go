@@ -0,0 +1,95 @@
+package report
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// GlobalState is a package-level variable written outside of initialization, with the
+// functions accessing it
+type GlobalState struct {
+	ID       string   `json:"id"`
+	Package  string   `json:"package"`
+	Writers  []string `json:"writers"`  // Functions writing the variable, sorted
+	Readers  []string `json:"readers"`  // Functions only reading it, sorted
+	Packages []string `json:"packages"` // Packages of the accessing functions, sorted
+}
+
+// MutableGlobals lists the package-level variables that are written by functions other than
+// init functions: shared mutable state to audit for data races. Variables accessed from the
+// most packages come first. The graph needs globals (-globals), otherwise the report is empty.
+func MutableGlobals(g *graph.DependencyGraph, opts Options) *Report {
+	accesses := make(map[string]*GlobalState)
+	mutated := make(map[string]bool)
+	for sourceID, targets := range g.Edges {
+		source := g.Nodes[sourceID]
+		if source == nil {
+			continue
+		}
+		for _, targetID := range targets {
+			target := g.Nodes[targetID]
+			if target == nil || target.Kind != graph.KindVar {
+				continue
+			}
+			state := accesses[targetID]
+			if state == nil {
+				state = &GlobalState{ID: targetID, Package: target.Package, Writers: []string{}, Readers: []string{}}
+				accesses[targetID] = state
+			}
+			if slices.Contains(g.EdgeKindsOf(sourceID, targetID), graph.EdgeWrites) {
+				state.Writers = append(state.Writers, sourceID)
+				if source.Kind != graph.KindInit {
+					mutated[targetID] = true
+				}
+			} else {
+				state.Readers = append(state.Readers, sourceID)
+			}
+			if !slices.Contains(state.Packages, source.Package) {
+				state.Packages = append(state.Packages, source.Package)
+			}
+		}
+	}
+
+	globals := make([]GlobalState, 0, len(mutated))
+	for nodeID := range mutated {
+		state := accesses[nodeID]
+		sort.Strings(state.Writers)
+		sort.Strings(state.Readers)
+		sort.Strings(state.Packages)
+		globals = append(globals, *state)
+	}
+	sort.Slice(globals, func(i, j int) bool {
+		if len(globals[i].Packages) != len(globals[j].Packages) {
+			return len(globals[i].Packages) > len(globals[j].Packages)
+		}
+		return globals[i].ID < globals[j].ID
+	})
+	globals = limit(globals, opts.Limit)
+
+	r := &Report{
+		Name:    "globals",
+		Title:   "Mutable package-level variables",
+		Columns: []string{"#", "Variable", "Package", "Writers", "Readers", "Packages"},
+		Rows:    make([][]string, 0, len(globals)),
+		Data:    globals,
+	}
+	for i, state := range globals {
+		name := state.ID
+		if node, exists := g.Nodes[state.ID]; exists {
+			name = node.Name
+		}
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			name,
+			state.Package,
+			strings.Join(symbolNames(g, state.Writers), ", "),
+			strings.Join(symbolNames(g, state.Readers), ", "),
+			strconv.Itoa(len(state.Packages)),
+		})
+	}
+	return r
+}
@@ -0,0 +1,31 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestMutableGlobals(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["b::Count"] = &graph.Node{ID: "b::Count", Name: "Count", Package: "b", Kind: graph.KindVar}
+	g.Nodes["b::Table"] = &graph.Node{ID: "b::Table", Name: "Table", Package: "b", Kind: graph.KindVar}
+	g.Nodes["b::init@b.go"] = &graph.Node{ID: "b::init@b.go", Name: "init@b.go", Package: "b", Kind: graph.KindInit}
+	g.AddEdge("a::F", "b::Count", graph.EdgeWrites)
+	g.AddEdge("a::G", "b::Count", graph.EdgeReads)
+	g.AddEdge("b::init@b.go", "b::Table", graph.EdgeWrites)
+	g.AddEdge("a::F", "b::Table", graph.EdgeReads)
+
+	r := MutableGlobals(g, Options{})
+
+	globals := r.Data.([]GlobalState)
+	if len(globals) != 1 || globals[0].ID != "b::Count" {
+		t.Fatalf("Expected only Count, written outside of init, got %+v", globals)
+	}
+	if len(globals[0].Writers) != 1 || len(globals[0].Readers) != 1 || len(globals[0].Packages) != 1 {
+		t.Errorf("Unexpected accessors: %+v", globals[0])
+	}
+	if r.Rows[0][3] != "F" || r.Rows[0][4] != "G" {
+		t.Errorf("Unexpected row: %v", r.Rows[0])
+	}
+}
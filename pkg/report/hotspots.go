@@ -0,0 +1,71 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+
+	"go-depmap/pkg/graph"
+)
+
+// Hotspot is a symbol that changes often and that much of the code depends on
+type Hotspot struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Package string `json:"package"`
+	Churn   int    `json:"churn"`
+	FanIn   int    `json:"fan_in"`
+	Score   int    `json:"score"` // Churn × fan-in
+}
+
+// Hotspots reports the symbols with the highest churn × fan-in: frequently changed code with
+// many dependents is the riskiest to touch. The graph needs churn data (-git-churn), otherwise
+// the report is empty.
+func Hotspots(g *graph.DependencyGraph, opts Options) *Report {
+	fanIn := g.FanIn()
+
+	hotspots := make([]Hotspot, 0)
+	for nodeID, node := range g.Nodes {
+		score := node.Churn * fanIn[nodeID]
+		if score == 0 {
+			continue
+		}
+		hotspots = append(hotspots, Hotspot{
+			ID:      nodeID,
+			Name:    node.Name,
+			Kind:    string(node.Kind),
+			Package: node.Package,
+			Churn:   node.Churn,
+			FanIn:   fanIn[nodeID],
+			Score:   score,
+		})
+	}
+
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Score != hotspots[j].Score {
+			return hotspots[i].Score > hotspots[j].Score
+		}
+		return hotspots[i].ID < hotspots[j].ID
+	})
+	hotspots = limit(hotspots, opts.Limit)
+
+	r := &Report{
+		Name:    "hotspots",
+		Title:   "Hotspots by churn and fan-in",
+		Columns: []string{"#", "Symbol", "Kind", "Package", "Churn", "Fan-in", "Score"},
+		Rows:    make([][]string, 0, len(hotspots)),
+		Data:    hotspots,
+	}
+	for i, h := range hotspots {
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			h.Name,
+			h.Kind,
+			h.Package,
+			strconv.Itoa(h.Churn),
+			strconv.Itoa(h.FanIn),
+			strconv.Itoa(h.Score),
+		})
+	}
+	return r
+}
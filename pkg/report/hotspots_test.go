@@ -0,0 +1,29 @@
+package report
+
+import "testing"
+
+func TestHotspots(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["a::T"].Churn = 5 // fan-in 2
+	g.Nodes["b::U"].Churn = 1 // fan-in 2
+	g.Nodes["a::F"].Churn = 9 // no dependents
+
+	r := Hotspots(g, Options{})
+
+	hotspots := r.Data.([]Hotspot)
+	if len(hotspots) != 2 {
+		t.Fatalf("Expected 2 hotspots, got %+v", hotspots)
+	}
+	if hotspots[0].ID != "a::T" || hotspots[0].Score != 10 || hotspots[0].FanIn != 2 {
+		t.Errorf("Unexpected first hotspot: %+v", hotspots[0])
+	}
+	if r.Rows[1][1] != "U" || r.Rows[1][6] != "2" {
+		t.Errorf("Unexpected second row: %v", r.Rows[1])
+	}
+}
+
+func TestHotspots_WithoutChurn(t *testing.T) {
+	if r := Hotspots(newStatsTestGraph(), Options{}); len(r.Rows) != 0 {
+		t.Errorf("Expected no hotspots without churn data, got %v", r.Rows)
+	}
+}
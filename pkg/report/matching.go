@@ -0,0 +1,72 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// MatchingSymbol is a symbol selected by a filter expression, with its coupling
+type MatchingSymbol struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Package string `json:"package"`
+	FanIn   int    `json:"fan_in"`
+	FanOut  int    `json:"fan_out"`
+}
+
+// Matching reports the symbols selected by the filter expression in opts.Expr, sorted by ID,
+// or the edges for an edge expression, sorted by source. It is not registered, as it needs
+// an expression rather than a root.
+func Matching(g *graph.DependencyGraph, opts Options) *Report {
+	if opts.Expr.SelectsEdges() {
+		return matchingEdges(g, opts)
+	}
+
+	fanIn, fanOut := g.FanIn(), g.FanOut()
+	nodeIDs := limit(g.MatchNodes(opts.Expr), opts.Limit)
+	symbols := make([]MatchingSymbol, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		node := g.Nodes[nodeID]
+		symbols = append(symbols, MatchingSymbol{
+			ID:      node.ID,
+			Kind:    string(node.Kind),
+			Package: node.Package,
+			FanIn:   fanIn[nodeID],
+			FanOut:  fanOut[nodeID],
+		})
+	}
+
+	r := &Report{
+		Name:    "matching",
+		Title:   "Symbols matching " + opts.Expr.String(),
+		Columns: []string{"Symbol", "Kind", "Package", "Fan-in", "Fan-out"},
+		Rows:    make([][]string, 0, len(symbols)),
+		Data:    symbols,
+	}
+	for _, s := range symbols {
+		r.Rows = append(r.Rows, []string{s.ID, s.Kind, s.Package, strconv.Itoa(s.FanIn), strconv.Itoa(s.FanOut)})
+	}
+	return r
+}
+
+// matchingEdges lists the edges selected by an edge expression
+func matchingEdges(g *graph.DependencyGraph, opts Options) *Report {
+	edges := limit(g.MatchEdges(opts.Expr), opts.Limit)
+	r := &Report{
+		Name:    "matching",
+		Title:   "Edges matching " + opts.Expr.String(),
+		Columns: []string{"Source", "Target", "Kinds"},
+		Rows:    make([][]string, 0, len(edges)),
+		Data:    edges,
+	}
+	for _, edge := range edges {
+		kinds := make([]string, len(edge.Kinds))
+		for i, kind := range edge.Kinds {
+			kinds[i] = string(kind)
+		}
+		r.Rows = append(r.Rows, []string{edge.Source, edge.Target, strings.Join(kinds, ", ")})
+	}
+	return r
+}
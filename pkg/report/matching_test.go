@@ -0,0 +1,38 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestMatching(t *testing.T) {
+	g := newReachabilityTestGraph()
+	expr, err := graph.ParseExpr("fanin>0 || fanout>1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Matching(g, Options{Expr: expr})
+
+	symbols := r.Data.([]MatchingSymbol)
+	if len(symbols) != 2 || symbols[0].ID != "a" || symbols[1].ID != "b" {
+		t.Fatalf("Expected a and b, got %+v", symbols)
+	}
+	if symbols[1].FanIn != 2 || r.Rows[1][3] != "2" {
+		t.Errorf("Expected b to have a fan-in of 2, got %+v", symbols[1])
+	}
+}
+
+func TestMatching_Edges(t *testing.T) {
+	g := newReachabilityTestGraph()
+	g.AddEdge("main", "a", graph.EdgeBody)
+	expr, err := graph.ParseExpr("edge.kind=body || source.name=other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Matching(g, Options{Expr: expr, Limit: 1})
+
+	if len(r.Rows) != 1 || r.Rows[0][0] != "main" || r.Rows[0][1] != "a" || r.Rows[0][2] != "body" {
+		t.Errorf("Expected the main -> a body edge only, got %v", r.Rows)
+	}
+}
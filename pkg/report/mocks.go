@@ -0,0 +1,69 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+
+	"go-depmap/pkg/graph"
+)
+
+// MockDependency is a dependency of production code on a mock or test double
+type MockDependency struct {
+	Caller string `json:"caller"` // ID of the production symbol
+	Mock   string `json:"mock"`   // ID of the mock symbol it depends on
+	File   string `json:"file"`   // Caller's source file, relative to the module root when known
+	Line   int    `json:"line"`   // Line of the caller's declaration
+}
+
+// MockDependencies lists the production symbols that depend on mocks: symbols tagged as mocks
+// should only be used by tests and by other mocks. The graph needs mock tagging (the default
+// -mock-patterns), otherwise the report is empty.
+func MockDependencies(g *graph.DependencyGraph, opts Options) *Report {
+	deps := make([]MockDependency, 0)
+	for sourceID, targets := range g.Edges {
+		caller := g.Nodes[sourceID]
+		if caller == nil || caller.Mock || caller.IsTest() || caller.Kind.IsTestFunction() {
+			continue
+		}
+		for _, targetID := range targets {
+			if target := g.Nodes[targetID]; target == nil || !target.Mock {
+				continue
+			}
+			file := caller.Path
+			if file == "" {
+				file = caller.File
+			}
+			deps = append(deps, MockDependency{
+				Caller: sourceID,
+				Mock:   targetID,
+				File:   file,
+				Line:   caller.Line,
+			})
+		}
+	}
+
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Caller != deps[j].Caller {
+			return deps[i].Caller < deps[j].Caller
+		}
+		return deps[i].Mock < deps[j].Mock
+	})
+	deps = limit(deps, opts.Limit)
+
+	r := &Report{
+		Name:    "mocks",
+		Title:   "Production code depending on mocks",
+		Columns: []string{"#", "Caller", "Mock", "Location"},
+		Rows:    make([][]string, 0, len(deps)),
+		Data:    deps,
+	}
+	for i, d := range deps {
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			d.Caller,
+			d.Mock,
+			d.File + ":" + strconv.Itoa(d.Line),
+		})
+	}
+	return r
+}
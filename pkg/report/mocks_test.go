@@ -0,0 +1,21 @@
+package report
+
+import "testing"
+
+func TestMockDependencies(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["a::T"].Mock = true // used by F and G
+	g.Nodes["a::G"].File = "g_test.go"
+	g.Nodes["a::F"].Path = "a/f.go"
+	g.Nodes["a::F"].Line = 4
+
+	r := MockDependencies(g, Options{})
+
+	deps := r.Data.([]MockDependency)
+	if len(deps) != 1 {
+		t.Fatalf("Expected only the production dependency of F, got %+v", deps)
+	}
+	if deps[0].Caller != "a::F" || deps[0].Mock != "a::T" || r.Rows[0][3] != "a/f.go:4" {
+		t.Errorf("Unexpected dependency: %+v, row %v", deps[0], r.Rows[0])
+	}
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+
+	"go-depmap/pkg/graph"
+)
+
+// unownedLabel stands in for nodes whose file has no owner
+const unownedLabel = "(unowned)"
+
+// OwnerDependency counts the dependencies from the code of one owner to the code of another
+type OwnerDependency struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Edges int    `json:"edges"`
+}
+
+// CrossOwnerDependencies reports the dependencies that cross ownership boundaries, aggregated
+// per pair of owners and sorted by edge count. The graph needs CODEOWNERS data (-codeowners),
+// otherwise every node is unowned and the report is empty.
+func CrossOwnerDependencies(g *graph.DependencyGraph, opts Options) *Report {
+	counts := make(map[[2]string]int)
+	for sourceID, targets := range g.Edges {
+		source, exists := g.Nodes[sourceID]
+		if !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			target, exists := g.Nodes[targetID]
+			if !exists || target.External || source.Owner == target.Owner {
+				continue
+			}
+			counts[[2]string{ownerLabel(source), ownerLabel(target)}]++
+		}
+	}
+
+	dependencies := make([]OwnerDependency, 0, len(counts))
+	for pair, edges := range counts {
+		dependencies = append(dependencies, OwnerDependency{From: pair[0], To: pair[1], Edges: edges})
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		a, b := dependencies[i], dependencies[j]
+		if a.Edges != b.Edges {
+			return a.Edges > b.Edges
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	dependencies = limit(dependencies, opts.Limit)
+
+	r := &Report{
+		Name:    "owners",
+		Title:   "Cross-owner dependencies",
+		Columns: []string{"From", "To", "Edges"},
+		Rows:    make([][]string, 0, len(dependencies)),
+		Data:    dependencies,
+	}
+	for _, d := range dependencies {
+		r.Rows = append(r.Rows, []string{d.From, d.To, strconv.Itoa(d.Edges)})
+	}
+	return r
+}
+
+// ownerLabel returns the owner of a node for display
+func ownerLabel(node *graph.Node) string {
+	if node.Owner == "" {
+		return unownedLabel
+	}
+	return node.Owner
+}
@@ -0,0 +1,34 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCrossOwnerDependencies(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["a::F"].Owner = "@org/api"
+	g.Nodes["a::G"].Owner = "@org/api"
+	g.Nodes["a::T"].Owner = "@org/model"
+
+	r := CrossOwnerDependencies(g, Options{})
+
+	// F -> T and G -> T cross from api to model; F -> U and T -> U go to unowned code
+	expected := []OwnerDependency{
+		{From: "@org/api", To: "@org/model", Edges: 2},
+		{From: "@org/api", To: "(unowned)", Edges: 1},
+		{From: "@org/model", To: "(unowned)", Edges: 1},
+	}
+	if got := r.Data.([]OwnerDependency); !reflect.DeepEqual(got, expected) {
+		t.Errorf("CrossOwnerDependencies() = %+v, want %+v", got, expected)
+	}
+	if r.Rows[0][2] != "2" {
+		t.Errorf("Unexpected first row: %v", r.Rows[0])
+	}
+}
+
+func TestCrossOwnerDependencies_WithoutOwners(t *testing.T) {
+	if r := CrossOwnerDependencies(newStatsTestGraph(), Options{}); len(r.Rows) != 0 {
+		t.Errorf("Expected no cross-owner dependencies without owners, got %v", r.Rows)
+	}
+}
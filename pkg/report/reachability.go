@@ -0,0 +1,73 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+
+	"go-depmap/pkg/graph"
+)
+
+// ReachableSymbol is a symbol found by a reachability query, with its distance from the root
+type ReachableSymbol struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	Package  string `json:"package"`
+	Distance int    `json:"distance"`
+}
+
+// Reachable reports every symbol transitively reachable from the entry point in opts.Root,
+// i.e. the code footprint of that entry point, ordered by distance
+func Reachable(g *graph.DependencyGraph, opts Options) *Report {
+	return reachabilityReport(g, "reachable", "Symbols reachable from "+opts.Root, g.Reachable(opts.Root), opts)
+}
+
+// Dependents reports every symbol that transitively depends on opts.Root, ordered by distance
+func Dependents(g *graph.DependencyGraph, opts Options) *Report {
+	return reachabilityReport(g, "dependents", "Symbols depending on "+opts.Root, g.Dependents(opts.Root), opts)
+}
+
+// reachabilityReport lists the symbols of a distance map, leaving out the root itself
+func reachabilityReport(g *graph.DependencyGraph, name, title string, distances map[string]int, opts Options) *Report {
+	var matching map[string]bool
+	if opts.Expr != nil {
+		matching = make(map[string]bool)
+		for _, nodeID := range g.MatchNodes(opts.Expr) {
+			matching[nodeID] = true
+		}
+		title += " matching " + opts.Expr.String()
+	}
+
+	symbols := make([]ReachableSymbol, 0, len(distances))
+	for nodeID, distance := range distances {
+		if nodeID == opts.Root || (matching != nil && !matching[nodeID]) {
+			continue
+		}
+		node := g.Nodes[nodeID]
+		symbols = append(symbols, ReachableSymbol{
+			ID:       node.ID,
+			Kind:     string(node.Kind),
+			Package:  node.Package,
+			Distance: distance,
+		})
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Distance != symbols[j].Distance {
+			return symbols[i].Distance < symbols[j].Distance
+		}
+		return symbols[i].ID < symbols[j].ID
+	})
+	symbols = limit(symbols, opts.Limit)
+
+	r := &Report{
+		Name:    name,
+		Title:   title,
+		Columns: []string{"Symbol", "Kind", "Package", "Distance"},
+		Rows:    make([][]string, 0, len(symbols)),
+		Data:    symbols,
+	}
+	for _, s := range symbols {
+		r.Rows = append(r.Rows, []string{s.ID, s.Kind, s.Package, strconv.Itoa(s.Distance)})
+	}
+	return r
+}
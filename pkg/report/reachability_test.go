@@ -0,0 +1,53 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func newReachabilityTestGraph() *graph.DependencyGraph {
+	g := graph.NewDependencyGraph()
+	for _, id := range []string{"main", "a", "b", "other"} {
+		g.Nodes[id] = &graph.Node{ID: id, Name: id, Kind: graph.KindFunction, Package: "p"}
+	}
+	g.Edges["main"] = []string{"a"}
+	g.Edges["a"] = []string{"b"}
+	g.Edges["other"] = []string{"b"}
+	return g
+}
+
+func TestReachable(t *testing.T) {
+	r := Reachable(newReachabilityTestGraph(), Options{Root: "main"})
+
+	if len(r.Rows) != 2 {
+		t.Fatalf("Expected 2 reachable symbols, got %v", r.Rows)
+	}
+	if r.Rows[0][0] != "a" || r.Rows[0][3] != "1" || r.Rows[1][0] != "b" || r.Rows[1][3] != "2" {
+		t.Errorf("Unexpected rows: %v", r.Rows)
+	}
+}
+
+func TestReachable_Expr(t *testing.T) {
+	expr, err := graph.ParseExpr("name=b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Reachable(newReachabilityTestGraph(), Options{Root: "main", Expr: expr})
+
+	if len(r.Rows) != 1 || r.Rows[0][0] != "b" || r.Rows[0][3] != "2" {
+		t.Errorf("Expected b at distance 2 only, got %v", r.Rows)
+	}
+}
+
+func TestDependents(t *testing.T) {
+	r := Dependents(newReachabilityTestGraph(), Options{Root: "b", Limit: 2})
+
+	symbols := r.Data.([]ReachableSymbol)
+	if len(symbols) != 2 {
+		t.Fatalf("Expected 2 symbols with limit 2, got %+v", symbols)
+	}
+	if symbols[0].ID != "a" || symbols[1].ID != "other" {
+		t.Errorf("Expected the direct dependents first, got %+v", symbols)
+	}
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// RecursionGroup is a set of functions that call themselves, directly or through each other
+type RecursionGroup struct {
+	Members  []string `json:"members"`  // Node IDs, sorted
+	Packages []string `json:"packages"` // Packages of the members, sorted
+	Mutual   bool     `json:"mutual"`   // Several functions calling each other, rather than one calling itself
+}
+
+// Recursion lists the recursive functions: self-recursion and mutual recursion, largest
+// groups first
+func Recursion(g *graph.DependencyGraph, opts Options) *Report {
+	groups := make([]RecursionGroup, 0)
+	for _, members := range g.FindRecursion() {
+		groups = append(groups, RecursionGroup{
+			Members:  members,
+			Packages: packagesOf(g, members),
+			Mutual:   len(members) > 1,
+		})
+	}
+	groups = limit(groups, opts.Limit)
+
+	r := &Report{
+		Name:    "recursion",
+		Title:   "Recursive functions",
+		Columns: []string{"#", "Recursion", "Size", "Packages", "Functions"},
+		Rows:    make([][]string, 0, len(groups)),
+		Data:    groups,
+	}
+	for i, group := range groups {
+		recursion := "self"
+		if group.Mutual {
+			recursion = "mutual"
+		}
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			recursion,
+			strconv.Itoa(len(group.Members)),
+			strings.Join(group.Packages, ", "),
+			strings.Join(symbolNames(g, group.Members), ", "),
+		})
+	}
+	return r
+}
+
+// packagesOf returns the distinct packages of the nodes, sorted
+func packagesOf(g *graph.DependencyGraph, nodeIDs []string) []string {
+	packages := make([]string, 0)
+	for _, nodeID := range nodeIDs {
+		if node, exists := g.Nodes[nodeID]; exists && !slices.Contains(packages, node.Package) {
+			packages = append(packages, node.Package)
+		}
+	}
+	sort.Strings(packages)
+	return packages
+}
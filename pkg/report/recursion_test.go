@@ -0,0 +1,24 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestRecursion(t *testing.T) {
+	g := newStatsTestGraph()
+	g.AddEdge("a::F", "a::G", graph.EdgeBody)
+	g.AddEdge("a::G", "a::F", graph.EdgeBody)
+	g.Nodes["a::G"].Package = "b"
+
+	r := Recursion(g, Options{})
+
+	groups := r.Data.([]RecursionGroup)
+	if len(groups) != 1 || !groups[0].Mutual {
+		t.Fatalf("Expected one mutual recursion group, got %+v", groups)
+	}
+	if r.Rows[0][1] != "mutual" || r.Rows[0][3] != "a, b" || r.Rows[0][4] != "F, G" {
+		t.Errorf("Unexpected row: %v", r.Rows[0])
+	}
+}
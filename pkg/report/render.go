@@ -0,0 +1,106 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Render writes the report in the given style: "table" (default), "json", or "markdown"
+func (r *Report) Render(w io.Writer, style string) error {
+	switch style {
+	case "json":
+		return r.renderJSON(w)
+	case "markdown", "md":
+		return r.renderMarkdown(w)
+	case "table", "":
+		return r.renderTable(w)
+	default:
+		return fmt.Errorf("unknown report style: %s", style)
+	}
+}
+
+// RenderAll writes several reports in the given style. JSON output is a single array
+// so that it remains parseable; the other styles separate reports with a blank line.
+func RenderAll(w io.Writer, reports []*Report, style string) error {
+	if style == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	}
+
+	for i, r := range reports {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if err := r.Render(w, style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderTable writes the report as an aligned plain-text table
+func (r *Report) renderTable(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%s\n\n", r.Title); err != nil {
+		return err
+	}
+	if len(r.Rows) == 0 {
+		_, err := fmt.Fprintln(w, "(no results)")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.Join(r.Columns, "\t")); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// renderJSON writes the report as pretty-printed JSON
+func (r *Report) renderJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// renderMarkdown writes the report as a Markdown section with a table
+func (r *Report) renderMarkdown(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "## %s\n\n", r.Title); err != nil {
+		return err
+	}
+	if len(r.Rows) == 0 {
+		_, err := fmt.Fprintln(w, "_No results._")
+		return err
+	}
+
+	separators := make([]string, len(r.Columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+
+	lines := []string{markdownRow(r.Columns), markdownRow(separators)}
+	for _, row := range r.Rows {
+		lines = append(lines, markdownRow(row))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// markdownRow formats cells as a Markdown table row, escaping pipes
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = strings.ReplaceAll(cell, "|", "\\|")
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
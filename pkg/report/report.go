@@ -0,0 +1,96 @@
+// Package report provides analysis reports derived from dependency graphs,
+// rendered as aligned text tables, JSON, or Markdown.
+package report
+
+import (
+	"sort"
+
+	"go-depmap/pkg/graph"
+)
+
+// Report is the result of an analysis, as a table plus the structured data it was built from
+type Report struct {
+	Name    string     `json:"name"`           // Registry name of the report
+	Title   string     `json:"title"`          // Human readable title
+	Columns []string   `json:"columns"`        // Table column headers
+	Rows    [][]string `json:"rows"`           // Table rows, one cell per column
+	Data    any        `json:"data,omitempty"` // Structured result for JSON consumers
+}
+
+// Options configures report generation
+type Options struct {
+	Limit          int         // Maximum number of rows for top-N reports (0 = unlimited)
+	MinClusterSize int         // Minimum cluster size for the split report
+	Chains         int         // Number of chains for the chain report (0 = one per root)
+	FullChain      bool        // Include every node of each chain in the chain report
+	Root           string      // Node ID of the entry point for the dominators, reachable and dependents reports
+	Expr           *graph.Expr // Filter of the symbols listed by the reachable, dependents and matching reports
+}
+
+// DefaultOptions returns the options used when none are given
+func DefaultOptions() Options {
+	return Options{
+		Limit:          0,
+		MinClusterSize: 2,
+		Chains:         1,
+	}
+}
+
+// Generator builds a report from a dependency graph
+type Generator func(g *graph.DependencyGraph, opts Options) *Report
+
+// generators maps report names to their generators
+var generators = map[string]Generator{
+	"api":        ExposedTypes,
+	"chain":      LongestChain,
+	"complexity": MostComplex,
+	"context":    ContextGaps,
+	"cycles":     Cycles,
+	"dependents": Dependents,
+	"deprecated": DeprecatedUsage,
+	"dominators": Dominators,
+	"errors":     ErrorSwallowPoints,
+	"fan-in":     TopDependedOn,
+	"fan-out":    TopFanOut,
+	"footprint":  Footprint,
+	"globals":    MutableGlobals,
+	"hotspots":   Hotspots,
+	"loc":        LongestFunctions,
+	"mocks":      MockDependencies,
+	"overlap":    Overlap,
+	"owners":     CrossOwnerDependencies,
+	"packages":   BiggestPackages,
+	"reachable":  Reachable,
+	"recursion":  Recursion,
+	"risky":      RiskyUsage,
+	"split":      PackageSplits,
+	"test-only":  TestOnly,
+}
+
+// rootReports are the reports that analyze the symbol given in Options.Root
+var rootReports = map[string]bool{
+	"dependents": true,
+	"dominators": true,
+	"reachable":  true,
+}
+
+// RequiresRoot reports whether the named report needs Options.Root to be set
+func RequiresRoot(name string) bool {
+	return rootReports[name]
+}
+
+// Get returns the generator registered under the given name
+func Get(name string) (Generator, bool) {
+	generator, ok := generators[name]
+	return generator, ok
+}
+
+// Names returns the sorted names of all available reports
+func Names() []string {
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
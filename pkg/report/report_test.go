@@ -0,0 +1,136 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func newTestReport() *Report {
+	return &Report{
+		Name:    "test",
+		Title:   "Test report",
+		Columns: []string{"Name", "Value"},
+		Rows:    [][]string{{"a|b", "1"}, {"c", "22"}},
+		Data:    map[string]int{"a": 1},
+	}
+}
+
+func TestReport_Render_Table(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestReport().Render(&buf, "table"); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "Test report\n") {
+		t.Errorf("Expected title first, got %q", output)
+	}
+	if !strings.Contains(output, "Name  Value") || !strings.Contains(output, "a|b   1") {
+		t.Errorf("Expected aligned columns, got:\n%s", output)
+	}
+}
+
+func TestReport_Render_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestReport().Render(&buf, "markdown"); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{"## Test report", "| Name | Value |", "| --- | --- |", `| a\|b | 1 |`} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, output)
+		}
+	}
+}
+
+func TestReport_Render_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestReport().Render(&buf, "json"); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if result["name"] != "test" {
+		t.Errorf("Expected name test, got %v", result["name"])
+	}
+	if _, ok := result["data"]; !ok {
+		t.Error("Expected structured data in JSON output")
+	}
+}
+
+func TestReport_Render_EmptyAndUnknown(t *testing.T) {
+	empty := &Report{Title: "Empty", Columns: []string{"A"}}
+
+	var buf bytes.Buffer
+	if err := empty.Render(&buf, "table"); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "(no results)") {
+		t.Errorf("Expected empty marker, got %q", buf.String())
+	}
+
+	if err := empty.Render(&buf, "xml"); err == nil {
+		t.Error("Expected error for unknown style")
+	}
+}
+
+func TestGet(t *testing.T) {
+	for _, name := range Names() {
+		if _, ok := Get(name); !ok {
+			t.Errorf("Get(%q) returned false for a listed report", name)
+		}
+	}
+	if _, ok := Get("does-not-exist"); ok {
+		t.Error("Get() should fail for unknown reports")
+	}
+}
+
+func TestPackageSplits(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	for _, name := range []string{"A1", "A2", "B1", "B2"} {
+		g.Nodes["a::"+name] = &graph.Node{ID: "a::" + name, Name: name, Package: "a"}
+	}
+	g.Edges["a::A1"] = []string{"a::A2"}
+	g.Edges["a::B1"] = []string{"a::B2"}
+
+	r := PackageSplits(g, DefaultOptions())
+
+	if len(r.Rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(r.Rows))
+	}
+	if r.Rows[0][0] != "a" || r.Rows[0][3] != "A1, A2" {
+		t.Errorf("Unexpected first row: %v", r.Rows[0])
+	}
+}
+
+func TestRenderAll(t *testing.T) {
+	reports := []*Report{newTestReport(), newTestReport()}
+
+	var buf bytes.Buffer
+	if err := RenderAll(&buf, reports, "json"); err != nil {
+		t.Fatalf("RenderAll() error = %v", err)
+	}
+	var result []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Expected a JSON array: %v", err)
+	}
+	if len(result) != 2 {
+		t.Errorf("Expected 2 reports, got %d", len(result))
+	}
+
+	buf.Reset()
+	if err := RenderAll(&buf, reports, "markdown"); err != nil {
+		t.Fatalf("RenderAll() error = %v", err)
+	}
+	if strings.Count(buf.String(), "## Test report") != 2 {
+		t.Errorf("Expected both reports in output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// RiskySymbol is a function that bypasses the type system
+type RiskySymbol struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Package string   `json:"package"`
+	File    string   `json:"file"`
+	Line    int      `json:"line"`
+	Uses    []string `json:"uses"` // unsafe, reflect and/or linkname
+	FanIn   int      `json:"fan_in"`
+}
+
+// RiskyUsage lists the functions that use unsafe or reflect, or are linked with
+// //go:linkname, for security review. Functions with the most dependents come first, as
+// they expose the risky code to the most callers.
+func RiskyUsage(g *graph.DependencyGraph, opts Options) *Report {
+	fanIn := g.FanIn()
+
+	symbols := make([]RiskySymbol, 0)
+	for nodeID, node := range g.Nodes {
+		if !node.IsRisky() {
+			continue
+		}
+		uses := make([]string, 0, 3)
+		if node.Unsafe {
+			uses = append(uses, "unsafe")
+		}
+		if node.Reflect {
+			uses = append(uses, "reflect")
+		}
+		if node.Linkname {
+			uses = append(uses, "linkname")
+		}
+		symbols = append(symbols, RiskySymbol{
+			ID:      nodeID,
+			Name:    node.Name,
+			Package: node.Package,
+			File:    node.File,
+			Line:    node.Line,
+			Uses:    uses,
+			FanIn:   fanIn[nodeID],
+		})
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].FanIn != symbols[j].FanIn {
+			return symbols[i].FanIn > symbols[j].FanIn
+		}
+		return symbols[i].ID < symbols[j].ID
+	})
+	symbols = limit(symbols, opts.Limit)
+
+	r := &Report{
+		Name:    "risky",
+		Title:   "Functions using unsafe, reflect or go:linkname",
+		Columns: []string{"#", "Symbol", "Package", "Location", "Uses", "Fan-in"},
+		Rows:    make([][]string, 0, len(symbols)),
+		Data:    symbols,
+	}
+	for i, s := range symbols {
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			s.Name,
+			s.Package,
+			s.File + ":" + strconv.Itoa(s.Line),
+			strings.Join(s.Uses, ", "),
+			strconv.Itoa(s.FanIn),
+		})
+	}
+	return r
+}
@@ -0,0 +1,25 @@
+package report
+
+import "testing"
+
+func TestRiskyUsage(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["a::G"].Unsafe = true // fan-in 1
+	g.Nodes["a::F"].Reflect = true
+	g.Nodes["a::F"].Linkname = true
+	g.Nodes["a::G"].File = "g.go"
+	g.Nodes["a::G"].Line = 7
+
+	r := RiskyUsage(g, Options{})
+
+	symbols := r.Data.([]RiskySymbol)
+	if len(symbols) != 2 {
+		t.Fatalf("Expected 2 risky symbols, got %+v", symbols)
+	}
+	if symbols[0].ID != "a::G" || symbols[0].FanIn != 1 {
+		t.Errorf("Unexpected first symbol: %+v", symbols[0])
+	}
+	if r.Rows[0][3] != "g.go:7" || r.Rows[1][4] != "reflect, linkname" {
+		t.Errorf("Unexpected rows: %v", r.Rows)
+	}
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// PackageSplits reports packages whose symbols fall into multiple weakly connected
+// clusters, listing the symbols of each cluster as a candidate new package
+func PackageSplits(g *graph.DependencyGraph, opts Options) *Report {
+	splits := g.SuggestPackageSplits(opts.MinClusterSize)
+
+	r := &Report{
+		Name:    "split",
+		Title:   "Suggested package splits",
+		Columns: []string{"Package", "Cluster", "Symbols", "Members"},
+		Rows:    make([][]string, 0),
+		Data:    splits,
+	}
+
+	for _, split := range splits {
+		for i, cluster := range split.Clusters {
+			r.Rows = append(r.Rows, []string{
+				split.Package,
+				strconv.Itoa(i + 1),
+				strconv.Itoa(len(cluster)),
+				strings.Join(symbolNames(g, cluster), ", "),
+			})
+		}
+	}
+
+	return r
+}
+
+// symbolNames maps node IDs to their short names, falling back to the ID for unknown nodes
+func symbolNames(g *graph.DependencyGraph, nodeIDs []string) []string {
+	names := make([]string, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		if node, exists := g.Nodes[nodeID]; exists {
+			names = append(names, node.Name)
+		} else {
+			names = append(names, nodeID)
+		}
+	}
+	return names
+}
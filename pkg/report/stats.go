@@ -0,0 +1,176 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// SymbolCount pairs a symbol with a count, e.g. its fan-in
+type SymbolCount struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Package string `json:"package"`
+	Count   int    `json:"count"`
+}
+
+// TopDependedOn reports the symbols with the most incoming dependencies
+func TopDependedOn(g *graph.DependencyGraph, opts Options) *Report {
+	ranked := rankSymbols(g, g.FanIn(), opts.Limit, nil)
+
+	return symbolCountReport("fan-in", "Most depended-on symbols", "Dependents", ranked)
+}
+
+// TopFanOut reports the functions and methods with the most outgoing dependencies
+func TopFanOut(g *graph.DependencyGraph, opts Options) *Report {
+	isCallable := func(node *graph.Node) bool {
+		return node.Kind == graph.KindFunction || node.Kind == graph.KindMethod
+	}
+	ranked := rankSymbols(g, g.FanOut(), opts.Limit, isCallable)
+
+	return symbolCountReport("fan-out", "Largest fan-out functions", "Dependencies", ranked)
+}
+
+// MostComplex reports the functions and methods with the highest cyclomatic complexity,
+// the usual suspects for god-functions
+func MostComplex(g *graph.DependencyGraph, opts Options) *Report {
+	ranked := rankSymbols(g, nodeMetric(g, func(node *graph.Node) int { return node.Complexity }), opts.Limit, nil)
+
+	return symbolCountReport("complexity", "Most complex functions", "Complexity", ranked)
+}
+
+// LongestFunctions reports the functions and methods with the most lines of code
+func LongestFunctions(g *graph.DependencyGraph, opts Options) *Report {
+	ranked := rankSymbols(g, nodeMetric(g, func(node *graph.Node) int { return node.Lines }), opts.Limit, nil)
+
+	return symbolCountReport("loc", "Longest functions", "Lines", ranked)
+}
+
+// BiggestPackages reports the packages with the most nodes
+func BiggestPackages(g *graph.DependencyGraph, opts Options) *Report {
+	metrics := g.ComputePackageMetrics()
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Nodes > metrics[j].Nodes
+	})
+	metrics = limit(metrics, opts.Limit)
+
+	r := &Report{
+		Name:    "packages",
+		Title:   "Biggest packages by node count",
+		Columns: []string{"Package", "Nodes", "Functions", "Methods", "Types"},
+		Rows:    make([][]string, 0, len(metrics)),
+		Data:    metrics,
+	}
+	for _, m := range metrics {
+		r.Rows = append(r.Rows, []string{
+			m.Package,
+			strconv.Itoa(m.Nodes),
+			strconv.Itoa(m.Functions),
+			strconv.Itoa(m.Methods),
+			strconv.Itoa(m.Types),
+		})
+	}
+	return r
+}
+
+// LongestChain reports the longest acyclic dependency chains, longest first. The chain
+// length is the number of edges; with FullChain set, every node on the chain is listed.
+// Isolated symbols are not chains and are left out.
+func LongestChain(g *graph.DependencyGraph, opts Options) *Report {
+	chains := g.LongestChains(opts.Chains)
+
+	r := &Report{
+		Name:    "chain",
+		Title:   "Longest dependency chains",
+		Columns: []string{"Length", "From", "To"},
+		Rows:    make([][]string, 0, len(chains)),
+		Data:    chains,
+	}
+	if opts.FullChain {
+		r.Columns = append(r.Columns, "Chain")
+	}
+	for _, chain := range chains {
+		if len(chain) < 2 {
+			continue
+		}
+		row := []string{
+			strconv.Itoa(len(chain) - 1),
+			chain[0],
+			chain[len(chain)-1],
+		}
+		if opts.FullChain {
+			row = append(row, strings.Join(chain, " -> "))
+		}
+		r.Rows = append(r.Rows, row)
+	}
+	return r
+}
+
+// rankSymbols sorts the nodes accepted by the filter by count (descending, then by ID),
+// dropping nodes with a zero count and keeping at most limit entries
+func rankSymbols(g *graph.DependencyGraph, counts map[string]int, limitN int, filter func(*graph.Node) bool) []SymbolCount {
+	ranked := make([]SymbolCount, 0)
+	for nodeID, count := range counts {
+		node, exists := g.Nodes[nodeID]
+		if !exists || count == 0 || (filter != nil && !filter(node)) {
+			continue
+		}
+		ranked = append(ranked, SymbolCount{
+			ID:      node.ID,
+			Name:    node.Name,
+			Kind:    string(node.Kind),
+			Package: node.Package,
+			Count:   count,
+		})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	return limit(ranked, limitN)
+}
+
+// nodeMetric collects a per-node value, for ranking nodes by an attribute rather than by edges
+func nodeMetric(g *graph.DependencyGraph, value func(*graph.Node) int) map[string]int {
+	counts := make(map[string]int, len(g.Nodes))
+	for nodeID, node := range g.Nodes {
+		counts[nodeID] = value(node)
+	}
+	return counts
+}
+
+// symbolCountReport builds a ranked symbol table
+func symbolCountReport(name, title, countColumn string, ranked []SymbolCount) *Report {
+	r := &Report{
+		Name:    name,
+		Title:   title,
+		Columns: []string{"#", "Symbol", "Kind", "Package", countColumn},
+		Rows:    make([][]string, 0, len(ranked)),
+		Data:    ranked,
+	}
+	for i, s := range ranked {
+		r.Rows = append(r.Rows, []string{
+			strconv.Itoa(i + 1),
+			s.Name,
+			s.Kind,
+			s.Package,
+			strconv.Itoa(s.Count),
+		})
+	}
+	return r
+}
+
+// limit truncates the slice to at most n entries (n <= 0 means unlimited)
+func limit[T any](items []T, n int) []T {
+	if n > 0 && len(items) > n {
+		return items[:n]
+	}
+	return items
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func newStatsTestGraph() *graph.DependencyGraph {
+	g := graph.NewDependencyGraph()
+	g.Nodes["a::F"] = &graph.Node{ID: "a::F", Name: "F", Kind: graph.KindFunction, Package: "a"}
+	g.Nodes["a::G"] = &graph.Node{ID: "a::G", Name: "G", Kind: graph.KindFunction, Package: "a"}
+	g.Nodes["a::T"] = &graph.Node{ID: "a::T", Name: "T", Kind: graph.KindType, Package: "a"}
+	g.Nodes["b::U"] = &graph.Node{ID: "b::U", Name: "U", Kind: graph.KindType, Package: "b"}
+	g.Edges["a::F"] = []string{"a::G", "a::T", "b::U"}
+	g.Edges["a::G"] = []string{"a::T"}
+	g.Edges["a::T"] = []string{"b::U"}
+	return g
+}
+
+func TestTopDependedOn(t *testing.T) {
+	r := TopDependedOn(newStatsTestGraph(), Options{Limit: 2})
+
+	ranked := r.Data.([]SymbolCount)
+	if len(ranked) != 2 {
+		t.Fatalf("Expected 2 entries with limit 2, got %d", len(ranked))
+	}
+	// a::T and b::U both have fan-in 2; ties are broken by ID
+	if ranked[0].ID != "a::T" || ranked[1].ID != "b::U" || ranked[0].Count != 2 {
+		t.Errorf("Unexpected ranking: %+v", ranked)
+	}
+	if r.Rows[0][1] != "T" || r.Rows[0][4] != "2" {
+		t.Errorf("Unexpected first row: %v", r.Rows[0])
+	}
+}
+
+func TestTopFanOut_OnlyCallables(t *testing.T) {
+	r := TopFanOut(newStatsTestGraph(), Options{})
+
+	for _, s := range r.Data.([]SymbolCount) {
+		if s.Kind == string(graph.KindType) {
+			t.Errorf("Types should not appear in the fan-out report: %+v", s)
+		}
+	}
+	if len(r.Rows) != 2 || r.Rows[0][1] != "F" {
+		t.Errorf("Expected F to rank first among 2 functions, got %v", r.Rows)
+	}
+}
+
+func TestBiggestPackages(t *testing.T) {
+	r := BiggestPackages(newStatsTestGraph(), Options{Limit: 1})
+
+	if len(r.Rows) != 1 || r.Rows[0][0] != "a" || r.Rows[0][1] != "3" {
+		t.Errorf("Expected package a with 3 nodes, got %v", r.Rows)
+	}
+}
+
+func TestLongestChainReport(t *testing.T) {
+	r := LongestChain(newStatsTestGraph(), Options{Chains: 1})
+
+	if len(r.Rows) != 1 {
+		t.Fatalf("Expected 1 row, got %d", len(r.Rows))
+	}
+	if r.Rows[0][0] != "3" || r.Rows[0][1] != "a::F" || r.Rows[0][2] != "b::U" {
+		t.Errorf("Unexpected chain row: %v", r.Rows[0])
+	}
+}
+
+func TestLongestChainReport_FullChain(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["c::V"] = &graph.Node{ID: "c::V", Name: "V", Kind: graph.KindFunction, Package: "c"}
+	g.Edges["c::V"] = []string{"b::U"}
+
+	r := LongestChain(g, Options{Chains: 2, FullChain: true})
+
+	if len(r.Columns) != 4 || r.Columns[3] != "Chain" {
+		t.Fatalf("Expected a Chain column, got %v", r.Columns)
+	}
+	if len(r.Rows) != 2 {
+		t.Fatalf("Expected 2 chains, got %d", len(r.Rows))
+	}
+	if r.Rows[0][3] != "a::F -> a::G -> a::T -> b::U" {
+		t.Errorf("Unexpected full chain: %s", r.Rows[0][3])
+	}
+	if r.Rows[1][3] != "c::V -> b::U" {
+		t.Errorf("Unexpected second chain: %s", r.Rows[1][3])
+	}
+}
+
+func TestMostComplex(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["a::F"].Complexity = 3
+	g.Nodes["a::G"].Complexity = 12
+
+	r := MostComplex(g, Options{})
+
+	if len(r.Rows) != 2 || r.Rows[0][1] != "G" || r.Rows[0][4] != "12" {
+		t.Errorf("Expected G to rank first with complexity 12, got %v", r.Rows)
+	}
+}
+
+func TestLongestFunctions(t *testing.T) {
+	g := newStatsTestGraph()
+	g.Nodes["a::F"].Lines = 80
+	g.Nodes["a::G"].Lines = 5
+
+	r := LongestFunctions(g, Options{Limit: 1})
+
+	if len(r.Rows) != 1 || r.Rows[0][1] != "F" || r.Rows[0][4] != "80" {
+		t.Errorf("Expected only F with 80 lines, got %v", r.Rows)
+	}
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// TestOnlySymbol is a production symbol that is referenced exclusively from test code
+type TestOnlySymbol struct {
+	ID           string   `json:"id"`
+	Kind         string   `json:"kind"`
+	Package      string   `json:"package"`
+	ReferencedBy []string `json:"referenced_by"` // Test symbols referencing it
+}
+
+// TestOnly reports symbols defined in production files that are only referenced from _test.go
+// files: production code that exists only to serve tests, or helpers that should move into
+// test files. The graph must have been built with tests included, otherwise the report is empty.
+func TestOnly(g *graph.DependencyGraph, opts Options) *Report {
+	dependents := make(map[string][]string)
+	for sourceID, targets := range g.Edges {
+		for _, targetID := range targets {
+			dependents[targetID] = append(dependents[targetID], sourceID)
+		}
+	}
+
+	symbols := make([]TestOnlySymbol, 0)
+	for nodeID, node := range g.Nodes {
+		referencedBy := dependents[nodeID]
+		if node.IsTest() || len(referencedBy) == 0 || !allTests(g, referencedBy) {
+			continue
+		}
+		sort.Strings(referencedBy)
+		symbols = append(symbols, TestOnlySymbol{
+			ID:           nodeID,
+			Kind:         string(node.Kind),
+			Package:      node.Package,
+			ReferencedBy: referencedBy,
+		})
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		return symbols[i].ID < symbols[j].ID
+	})
+	symbols = limit(symbols, opts.Limit)
+
+	r := &Report{
+		Name:    "test-only",
+		Title:   "Production symbols referenced only from tests",
+		Columns: []string{"Symbol", "Kind", "Package", "Tests", "Referenced by"},
+		Rows:    make([][]string, 0, len(symbols)),
+		Data:    symbols,
+	}
+	for _, s := range symbols {
+		r.Rows = append(r.Rows, []string{
+			s.ID,
+			s.Kind,
+			s.Package,
+			strconv.Itoa(len(s.ReferencedBy)),
+			strings.Join(symbolNames(g, s.ReferencedBy), ", "),
+		})
+	}
+	return r
+}
+
+// allTests reports whether all the given nodes are defined in test files
+func allTests(g *graph.DependencyGraph, nodeIDs []string) bool {
+	for _, nodeID := range nodeIDs {
+		if node, exists := g.Nodes[nodeID]; !exists || !node.IsTest() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package report
+
+import (
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+func TestTestOnly(t *testing.T) {
+	g := graph.NewDependencyGraph()
+	add := func(id, file string) {
+		g.Nodes[id] = &graph.Node{ID: id, Name: id, Kind: graph.KindFunction, Package: "p", File: file}
+	}
+	add("Serve", "server.go")
+	add("helper", "server.go")
+	add("fixture", "fixture.go")
+	add("unused", "server.go")
+	add("TestServe", "server_test.go")
+	add("TestFixture", "fixture_test.go")
+	g.Edges["Serve"] = []string{"helper"}
+	g.Edges["TestServe"] = []string{"Serve", "helper", "fixture"}
+	g.Edges["TestFixture"] = []string{"fixture"}
+
+	r := TestOnly(g, Options{})
+
+	symbols := r.Data.([]TestOnlySymbol)
+	if len(symbols) != 2 {
+		t.Fatalf("Expected 2 test-only symbols, got %+v", symbols)
+	}
+	if symbols[0].ID != "Serve" || symbols[1].ID != "fixture" {
+		t.Errorf("Unexpected symbols: %+v", symbols)
+	}
+	if r.Rows[1][3] != "2" || r.Rows[1][4] != "TestFixture, TestServe" {
+		t.Errorf("Unexpected row: %v", r.Rows[1])
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// maxRequestBytes limits the size of request bodies
+const maxRequestBytes = 1 << 20
+
+// graphQLRequest is a GraphQL request, from the JSON body of a POST or the query string of a GET
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// serveGraphQL executes a GraphQL request against the graph being served. Requests are sent
+// as GET with query, variables and operationName parameters, as POST with a JSON body, or as
+// POST of the bare query with the application/graphql content type.
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	request, err := readGraphQLRequest(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &graphql.Result{Errors: []gqlerrors.FormattedError{{Message: err.Error()}}})
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        context.WithValue(r.Context(), snapshotKey{}, s.snapshot()),
+	})
+	if result.HasErrors() {
+		slog.Debug("GraphQL request failed", "operation", request.OperationName, "errors", len(result.Errors))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// readGraphQLRequest reads a GraphQL request in any of the forms accepted by serveGraphQL
+func readGraphQLRequest(r *http.Request) (*graphQLRequest, error) {
+	request := &graphQLRequest{}
+	if r.Method == http.MethodGet {
+		params := r.URL.Query()
+		request.Query = params.Get("query")
+		request.OperationName = params.Get("operationName")
+		if variables := params.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return nil, errors.New("invalid variables: " + err.Error())
+			}
+		}
+	} else {
+		body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequestBytes))
+		if err != nil {
+			return nil, errors.New("invalid body: " + err.Error())
+		}
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/graphql" {
+			request.Query = string(body)
+		} else if err := json.Unmarshal(body, request); err != nil {
+			return nil, errors.New("invalid body: " + err.Error())
+		}
+	}
+
+	if request.Query == "" {
+		return nil, errors.New("missing query")
+	}
+	return request, nil
+}
+
+// writeJSON writes a value as a JSON response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Warn("Failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-depmap/pkg/graph"
+)
+
+// newTestServer serves a graph where main calls Open and Query, Query calls Open, and Open
+// calls nothing
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	g := graph.NewDependencyGraph()
+	for _, id := range []string{"app/cmd::main", "app/db::Open", "app/db::Query", "app/util::Helper"} {
+		pkg, name, _ := strings.Cut(id, "::")
+		g.Nodes[id] = &graph.Node{ID: id, Name: name, Kind: graph.KindFunction, Package: pkg}
+	}
+	g.Nodes["app/db::Open"].SetAttr("team", "storage")
+	g.AddEdge("app/cmd::main", "app/db::Open", graph.EdgeBody)
+	g.AddEdge("app/cmd::main", "app/db::Query", graph.EdgeBody)
+	g.AddEdge("app/db::Query", "app/db::Open", graph.EdgeBody)
+	g.Metadata = &graph.Metadata{Tool: "go-depmap", Version: "v1.0.0"}
+	g.ComputeSubgraphs()
+
+	s, err := New(g)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	return s
+}
+
+// postGraphQL posts a query with variables and decodes the response
+func postGraphQL(t *testing.T, s *Server, query string, variables map[string]any) (data map[string]any, errors []any) {
+	t.Helper()
+	body, _ := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body %s", recorder.Code, recorder.Body)
+	}
+	var result struct {
+		Data   map[string]any `json:"data"`
+		Errors []any          `json:"errors"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	return result.Data, result.Errors
+}
+
+// ids returns the id fields of a list of nodes from a response
+func ids(nodes any) []string {
+	result := make([]string, 0)
+	for _, node := range nodes.([]any) {
+		result = append(result, node.(map[string]any)["id"].(string))
+	}
+	return result
+}
+
+func TestGraphQL_Nodes(t *testing.T) {
+	s := newTestServer(t)
+
+	data, errors := postGraphQL(t, s, `query($pkg: String) {
+		nodes(filter: {package: $pkg}) { id }
+		all: nodes(limit: 2, offset: 1) { id }
+		byName: nodes(filter: {name: "query"}) { id name kind attrs { key value } }
+	}`, map[string]any{"pkg": "app/db"})
+	if errors != nil {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	if got := ids(data["nodes"]); !reflect.DeepEqual(got, []string{"app/db::Open", "app/db::Query"}) {
+		t.Errorf("nodes(package) = %v", got)
+	}
+	if got := ids(data["all"]); !reflect.DeepEqual(got, []string{"app/db::Open", "app/db::Query"}) {
+		t.Errorf("nodes(limit, offset) = %v", got)
+	}
+	if got := ids(data["byName"]); !reflect.DeepEqual(got, []string{"app/db::Query"}) {
+		t.Errorf("nodes(name) = %v", got)
+	}
+}
+
+func TestGraphQL_Node(t *testing.T) {
+	s := newTestServer(t)
+
+	data, errors := postGraphQL(t, s, `{
+		node(id: "db::Open") {
+			id
+			attrs { key value }
+			dependents { id }
+			transitive: dependents(transitive: true) { id }
+		}
+	}`, nil)
+	if errors != nil {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	node := data["node"].(map[string]any)
+	if node["id"] != "app/db::Open" {
+		t.Errorf("Expected the shortened ID to be resolved, got %v", node["id"])
+	}
+	if attrs := node["attrs"].([]any); len(attrs) != 1 || attrs[0].(map[string]any)["value"] != `"storage"` {
+		t.Errorf("attrs = %v", attrs)
+	}
+	if got := ids(node["dependents"]); !reflect.DeepEqual(got, []string{"app/cmd::main", "app/db::Query"}) {
+		t.Errorf("dependents = %v", got)
+	}
+	if got := ids(node["transitive"]); !reflect.DeepEqual(got, []string{"app/cmd::main", "app/db::Query"}) {
+		t.Errorf("transitive dependents = %v", got)
+	}
+
+	_, errors = postGraphQL(t, s, `{ node(id: "missing") { id } }`, nil)
+	if len(errors) != 1 {
+		t.Errorf("Expected an error for an unknown node, got %v", errors)
+	}
+}
+
+func TestGraphQL_DependentsAndPaths(t *testing.T) {
+	s := newTestServer(t)
+
+	data, errors := postGraphQL(t, s, `{
+		direct: dependencies(id: "main") { id }
+		transitive: dependents(id: "Open", transitive: true) { id }
+		paths(from: "main", to: "Open") { id }
+		edges: node(id: "main") { edges { target { id } kinds weight } }
+		subgraphs(limit: 1) { id edgeCount nodes { id } }
+		metadata { tool version }
+	}`, nil)
+	if errors != nil {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	if got := ids(data["direct"]); !reflect.DeepEqual(got, []string{"app/db::Open", "app/db::Query"}) {
+		t.Errorf("dependencies = %v", got)
+	}
+	if got := ids(data["transitive"]); !reflect.DeepEqual(got, []string{"app/cmd::main", "app/db::Query"}) {
+		t.Errorf("transitive dependents = %v", got)
+	}
+	if paths := data["paths"].([]any); len(paths) != 1 || !reflect.DeepEqual(ids(paths[0]), []string{"app/cmd::main", "app/db::Open"}) {
+		t.Errorf("paths = %v", paths)
+	}
+	edges := data["edges"].(map[string]any)["edges"].([]any)
+	if len(edges) != 2 || !reflect.DeepEqual(edges[0].(map[string]any)["kinds"], []any{"body"}) {
+		t.Errorf("edges = %v", edges)
+	}
+	if subgraphs := data["subgraphs"].([]any); len(subgraphs) != 1 || subgraphs[0].(map[string]any)["edgeCount"] != 3.0 {
+		t.Errorf("subgraphs = %v", subgraphs)
+	}
+	if data["metadata"].(map[string]any)["version"] != "v1.0.0" {
+		t.Errorf("metadata = %v", data["metadata"])
+	}
+}
+
+func TestGraphQL_Requests(t *testing.T) {
+	s := newTestServer(t)
+	handler := s.Handler()
+
+	get := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`query($id: String!) { node(id: $id) { name } }`)+
+		"&variables="+url.QueryEscape(`{"id":"main"}`), nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, get)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"name":"main"`) {
+		t.Errorf("GET: status %d, body %s", recorder.Code, recorder.Body)
+	}
+
+	post := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{ cycles { id } }`))
+	post.Header.Set("Content-Type", "application/graphql")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, post)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"cycles":[]`) {
+		t.Errorf("POST application/graphql: status %d, body %s", recorder.Code, recorder.Body)
+	}
+
+	for _, body := range []string{"", "{not json", `{"query":""}`} {
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Body %q: status %d, want 400", body, recorder.Code)
+		}
+	}
+}
+
+func TestServer_SetGraph(t *testing.T) {
+	s := newTestServer(t)
+	g := graph.NewDependencyGraph()
+	g.Nodes["x::X"] = &graph.Node{ID: "x::X", Name: "X"}
+	s.SetGraph(g)
+
+	data, errors := postGraphQL(t, s, `{ nodes { id } }`, nil)
+	if errors != nil || !reflect.DeepEqual(ids(data["nodes"]), []string{"x::X"}) {
+		t.Errorf("Expected the replaced graph to be served, got %v %v", data, errors)
+	}
+}
@@ -0,0 +1,17 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// indexPage is the live view of the graph, which follows the /api/live updates
+//
+//go:embed templates/index.html
+var indexPage []byte
+
+// serveIndex serves the live view of the graph
+func serveIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexPage)
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/net/websocket"
+)
+
+// liveBuffer is the number of messages queued for a live client. A client falling further
+// behind is disconnected, and gets a new snapshot when it reconnects.
+const liveBuffer = 16
+
+// liveMessage is a message of the /api/live WebSocket. A client first receives a snapshot of
+// the graph, then a delta for every update of the served graph.
+type liveMessage struct {
+	Type    string                 `json:"type"`            // "snapshot" or "delta"
+	Version int                    `json:"version"`         // Number of updates of the served graph
+	Graph   *graph.DependencyGraph `json:"graph,omitempty"` // The whole graph, in snapshots
+	Delta   *graph.Delta           `json:"delta,omitempty"` // The changes from the previous version, in deltas
+}
+
+// Update replaces the graph being served, like SetGraph, and pushes the changes to the live
+// clients. It returns the changes, empty when only the metadata differs.
+func (s *Server) Update(g *graph.DependencyGraph) *graph.Delta {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+	delta := graph.Diff(s.Graph(), g)
+	s.publish(g, delta)
+	return delta
+}
+
+// Publish is Update for a graph whose changes from the graph being served are known, such
+// as those returned by analyzer.Update
+func (s *Server) Publish(g *graph.DependencyGraph, delta *graph.Delta) {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+	s.publish(g, delta)
+}
+
+// publish replaces the graph being served and pushes its changes to the live clients. A
+// client that falls behind is disconnected.
+func (s *Server) publish(g *graph.DependencyGraph, delta *graph.Delta) {
+	next := newSnapshot(g)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = next
+	if delta.IsEmpty() {
+		return
+	}
+
+	s.version++
+	message, err := json.Marshal(liveMessage{Type: "delta", Version: s.version, Delta: delta})
+	if err != nil {
+		slog.Warn("Failed to encode graph delta", "error", err)
+		return
+	}
+	for updates := range s.subscribers {
+		select {
+		case updates <- message:
+		default:
+			slog.Warn("Disconnecting a live client falling behind")
+			delete(s.subscribers, updates)
+			close(updates)
+		}
+	}
+}
+
+// subscribe registers a live client, returning the channel of its messages and the snapshot
+// of the graph they apply to
+func (s *Server) subscribe() (updates chan []byte, snapshot []byte, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot, err = json.Marshal(liveMessage{Type: "snapshot", Version: s.version, Graph: s.current.graph})
+	if err != nil {
+		return nil, nil, err
+	}
+	updates = make(chan []byte, liveBuffer)
+	s.subscribers[updates] = struct{}{}
+	return updates, snapshot, nil
+}
+
+// unsubscribe removes a live client, unless it was disconnected for falling behind
+func (s *Server) unsubscribe(updates chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.subscribers[updates]; exists {
+		delete(s.subscribers, updates)
+		close(updates)
+	}
+}
+
+// serveLive sends the snapshot and deltas of the served graph to a WebSocket client, as JSON
+// text messages, until either side disconnects
+func (s *Server) serveLive(ws *websocket.Conn) {
+	defer ws.Close()
+	updates, snapshot, err := s.subscribe()
+	if err != nil {
+		slog.Warn("Failed to encode graph snapshot", "error", err)
+		return
+	}
+	defer s.unsubscribe(updates)
+
+	// Clients send nothing; reading only detects them going away
+	closed := make(chan struct{})
+	go func() {
+		var discard string
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+		close(closed)
+	}()
+
+	if err := websocket.Message.Send(ws, string(snapshot)); err != nil {
+		return
+	}
+	for {
+		select {
+		case message, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := websocket.Message.Send(ws, string(message)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
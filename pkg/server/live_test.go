@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-depmap/pkg/graph"
+
+	"golang.org/x/net/websocket"
+)
+
+// receiveLive reads the next message of a live connection
+func receiveLive(t *testing.T, ws *websocket.Conn) liveMessage {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var data string
+	if err := websocket.Message.Receive(ws, &data); err != nil {
+		t.Fatalf("Receive() error: %v", err)
+	}
+	var message liveMessage
+	if err := json.Unmarshal([]byte(data), &message); err != nil {
+		t.Fatalf("Invalid message %s: %v", data, err)
+	}
+	return message
+}
+
+func TestLive(t *testing.T) {
+	s := newTestServer(t)
+	httpServer := httptest.NewServer(s.Handler())
+	defer httpServer.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/api/live", "", httpServer.URL)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	defer ws.Close()
+
+	snapshot := receiveLive(t, ws)
+	if snapshot.Type != "snapshot" || snapshot.Version != 0 || len(snapshot.Graph.Nodes) != 4 {
+		t.Fatalf("Unexpected snapshot: %+v", snapshot)
+	}
+
+	// Remove Helper and add a call from Open to a new Close
+	next := graph.NewDependencyGraph()
+	for nodeID, node := range s.Graph().Nodes {
+		if nodeID != "app/util::Helper" {
+			next.Nodes[nodeID] = node
+		}
+	}
+	for sourceID, targets := range s.Graph().Edges {
+		for _, targetID := range targets {
+			next.AddEdge(sourceID, targetID, s.Graph().EdgeKindsOf(sourceID, targetID)...)
+		}
+	}
+	next.Nodes["app/db::Close"] = &graph.Node{ID: "app/db::Close", Name: "Close", Package: "app/db"}
+	next.AddEdge("app/db::Open", "app/db::Close")
+
+	delta := s.Update(next)
+	if len(delta.AddedNodes) != 1 || len(delta.RemovedNodes) != 1 || len(delta.AddedEdges) != 1 {
+		t.Errorf("Unexpected delta: %+v", delta)
+	}
+
+	update := receiveLive(t, ws)
+	if update.Type != "delta" || update.Version != 1 || update.Delta == nil {
+		t.Fatalf("Unexpected update: %+v", update)
+	}
+	if update.Delta.AddedNodes[0].ID != "app/db::Close" || update.Delta.RemovedNodes[0] != "app/util::Helper" {
+		t.Errorf("Unexpected delta: %+v", update.Delta)
+	}
+
+	// Updates without changes are not pushed, and the next one follows the version
+	if delta := s.Update(next); !delta.IsEmpty() {
+		t.Errorf("Expected no changes, got %+v", delta)
+	}
+	s.Update(next.Extract([]string{"app/cmd::main", "app/db::Open", "app/db::Query"}))
+	if update := receiveLive(t, ws); update.Version != 2 || update.Delta.RemovedNodes[0] != "app/db::Close" {
+		t.Errorf("Unexpected update: %+v", update)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	s := newTestServer(t)
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	if recorder.Code != 200 || !strings.Contains(recorder.Body.String(), "/api/live") {
+		t.Errorf("Expected the live view, got status %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/missing", nil))
+	if recorder.Code != 404 {
+		t.Errorf("Expected 404 for unknown paths, got %d", recorder.Code)
+	}
+}
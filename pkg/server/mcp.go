@@ -0,0 +1,505 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// mcpProtocolVersions are the Model Context Protocol revisions the server speaks, latest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// defaultToolLimit is the number of symbols or cycles listed by the tools without a limit
+const defaultToolLimit = 50
+
+// mcpInstructions tells the assistants how to refer to symbols
+const mcpInstructions = `Queries the dependency graph of the Go project in the working directory. ` +
+	`Symbols are identified by "<import path>::<name>" IDs, e.g. "example.com/app/pkg/db::Open" or ` +
+	`"example.com/app/pkg/db::(*Conn).Query"; the tools also accept a shortened import path ` +
+	`("pkg/db::Open") or a unique bare name ("Open"). Use search_symbols to find the IDs.`
+
+// JSON-RPC error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// rpcRequest is a JSON-RPC 2.0 request, or a notification without an ID
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response, with either a result or an error
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error of a failed JSON-RPC request
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool is a tool offered to the assistants, with the JSON schema of its arguments
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	call        func(snap *snapshot, args toolArgs) (any, error)
+}
+
+// toolArgs holds the arguments of all tools; each tool reads the ones of its schema
+type toolArgs struct {
+	Symbol     string `json:"symbol"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Package    string `json:"package"`
+	Query      string `json:"query"`
+	Transitive bool   `json:"transitive"`
+	Limit      int    `json:"limit"`
+}
+
+// toolResult is the result of a tool call: its JSON as text, and as structured content for
+// the clients that support it. Failures, such as unknown symbols, are reported to the
+// assistant as error results rather than protocol errors.
+type toolResult struct {
+	Content           []toolContent `json:"content"`
+	StructuredContent any           `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
+}
+
+// toolContent is a text block of a tool result
+type toolContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// mcpSymbol is the short form of a node listed by the tools
+type mcpSymbol struct {
+	ID       string         `json:"id"`
+	Kind     graph.NodeKind `json:"kind"`
+	Location string         `json:"location,omitempty"` // Source path and line
+	Distance int            `json:"distance,omitempty"` // Number of edges from the queried symbol
+}
+
+// symbolList is the result of the tools listing symbols
+type symbolList struct {
+	Symbols []mcpSymbol `json:"symbols"`
+	Total   int         `json:"total"` // Number of symbols before the limit
+}
+
+// packageSummary is the result of get_package_summary
+type packageSummary struct {
+	graph.PackageMetrics
+	DependsOn    map[string]int `json:"depends_on"`     // Packages it depends on, with the number of edges to each
+	DependedOnBy map[string]int `json:"depended_on_by"` // Packages depending on it, with the number of edges from each
+	Cycles       int            `json:"cycles"`         // Dependency cycles through its symbols
+	Symbols      []mcpSymbol    `json:"symbols"`        // Its symbols, up to the limit
+}
+
+// cycleList is the result of find_cycles
+type cycleList struct {
+	Cycles [][]string `json:"cycles"`
+	Total  int        `json:"total"` // Number of cycles before the limit
+}
+
+// mcpTools are the tools of the MCP server, in the order they are listed
+var mcpTools = []mcpTool{
+	{
+		Name:        "search_symbols",
+		Description: "Find symbols (functions, methods, types) whose name or ID contains a text, ignoring case. Exact name matches come first.",
+		InputSchema: toolSchema([]string{"query"}, map[string]any{
+			"query": stringProperty("Text to search for in the symbol names and IDs"),
+			"limit": limitProperty(),
+		}),
+		call: callSearchSymbols,
+	},
+	{
+		Name:        "get_symbol",
+		Description: "Get the details of a symbol: its kind, package, source location, signature, doc comment and metrics.",
+		InputSchema: toolSchema([]string{"symbol"}, map[string]any{
+			"symbol": stringProperty("Symbol ID, shortened ID or unique name"),
+		}),
+		call: callGetSymbol,
+	},
+	{
+		Name:        "get_dependencies",
+		Description: "List the symbols a symbol uses, directly or transitively, sorted by distance.",
+		InputSchema: toolSchema([]string{"symbol"}, map[string]any{
+			"symbol":     stringProperty("Symbol ID, shortened ID or unique name"),
+			"transitive": map[string]any{"type": "boolean", "description": "Also list the indirect dependencies"},
+			"limit":      limitProperty(),
+		}),
+		call: func(snap *snapshot, args toolArgs) (any, error) {
+			return callRelated(snap, args, snap.graph.Reachable)
+		},
+	},
+	{
+		Name:        "get_dependents",
+		Description: "List the symbols using a symbol, directly or transitively, sorted by distance: what a change to it may break.",
+		InputSchema: toolSchema([]string{"symbol"}, map[string]any{
+			"symbol":     stringProperty("Symbol ID, shortened ID or unique name"),
+			"transitive": map[string]any{"type": "boolean", "description": "Also list the indirect dependents"},
+			"limit":      limitProperty(),
+		}),
+		call: func(snap *snapshot, args toolArgs) (any, error) {
+			return callRelated(snap, args, snap.graph.Dependents)
+		},
+	},
+	{
+		Name:        "get_path",
+		Description: "Find the shortest dependency paths from one symbol to another, as lists of symbol IDs: how one ends up using the other.",
+		InputSchema: toolSchema([]string{"from", "to"}, map[string]any{
+			"from":  stringProperty("Symbol the paths start from"),
+			"to":    stringProperty("Symbol the paths lead to"),
+			"limit": map[string]any{"type": "integer", "minimum": 1, "description": "Maximum number of paths (default 3)"},
+		}),
+		call: callGetPath,
+	},
+	{
+		Name:        "get_package_summary",
+		Description: "Summarize a package: its size, the packages it depends on and that depend on it, its instability, cycles and symbols.",
+		InputSchema: toolSchema([]string{"package"}, map[string]any{
+			"package": stringProperty("Import path, or a unique shortened import path such as pkg/db"),
+			"limit":   limitProperty(),
+		}),
+		call: callGetPackageSummary,
+	},
+	{
+		Name:        "find_cycles",
+		Description: "List the dependency cycles between symbols, largest first, as lists of symbol IDs.",
+		InputSchema: toolSchema(nil, map[string]any{
+			"package": stringProperty("Only list the cycles through this package's symbols"),
+			"limit":   limitProperty(),
+		}),
+		call: callFindCycles,
+	},
+}
+
+// ServeMCP serves the Model Context Protocol over a stdio transport, reading one JSON-RPC
+// message per line from in and writing the responses to out, until in is closed. The
+// version is reported to the clients with the server's name.
+func (s *Server) ServeMCP(in io.Reader, out io.Writer, version string) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxRequestBytes)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	for scanner.Scan() {
+		message := bytes.TrimSpace(scanner.Bytes())
+		if len(message) == 0 {
+			continue
+		}
+		if response := s.handleMCP(message, version); response != nil {
+			if err := encoder.Encode(response); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handleMCP handles a JSON-RPC message, returning nil for notifications
+func (s *Server) handleMCP(message []byte, version string) *rpcResponse {
+	var request rpcRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return rpcFailure(json.RawMessage("null"), rpcParseError, "invalid JSON: %v", err)
+	}
+	if len(request.ID) == 0 {
+		// Notifications, such as notifications/initialized, need no answer
+		return nil
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		return rpcFailure(request.ID, rpcInvalidRequest, "not a JSON-RPC 2.0 request")
+	}
+
+	switch request.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(request.Params, &params)
+		protocolVersion := mcpProtocolVersions[0]
+		if slices.Contains(mcpProtocolVersions, params.ProtocolVersion) {
+			protocolVersion = params.ProtocolVersion
+		}
+		return rpcSuccess(request.ID, map[string]any{
+			"protocolVersion": protocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "go-depmap", "version": version},
+			"instructions":    mcpInstructions,
+		})
+	case "ping":
+		return rpcSuccess(request.ID, struct{}{})
+	case "tools/list":
+		return rpcSuccess(request.ID, map[string]any{"tools": mcpTools})
+	case "tools/call":
+		return s.callTool(request)
+	default:
+		return rpcFailure(request.ID, rpcMethodNotFound, "unknown method %q", request.Method)
+	}
+}
+
+// callTool runs a tools/call request against the graph being served
+func (s *Server) callTool(request rpcRequest) *rpcResponse {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return rpcFailure(request.ID, rpcInvalidParams, "invalid params: %v", err)
+	}
+	index := slices.IndexFunc(mcpTools, func(tool mcpTool) bool { return tool.Name == params.Name })
+	if index < 0 {
+		return rpcFailure(request.ID, rpcInvalidParams, "unknown tool %q", params.Name)
+	}
+	tool := mcpTools[index]
+
+	// Check the required arguments of the schema, and decode them
+	var present map[string]json.RawMessage
+	var args toolArgs
+	if len(params.Arguments) > 0 {
+		if err := json.Unmarshal(params.Arguments, &present); err != nil {
+			return rpcFailure(request.ID, rpcInvalidParams, "invalid arguments: %v", err)
+		}
+		if err := json.Unmarshal(params.Arguments, &args); err != nil {
+			return rpcFailure(request.ID, rpcInvalidParams, "invalid arguments: %v", err)
+		}
+	}
+	for _, name := range tool.InputSchema["required"].([]string) {
+		if _, exists := present[name]; !exists {
+			return rpcFailure(request.ID, rpcInvalidParams, "missing argument %q", name)
+		}
+	}
+	if args.Limit < 0 {
+		return rpcFailure(request.ID, rpcInvalidParams, "invalid limit: %d", args.Limit)
+	}
+
+	result, err := tool.call(s.snapshot(), args)
+	if err != nil {
+		return rpcSuccess(request.ID, toolResult{
+			Content: []toolContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		})
+	}
+	text, err := json.Marshal(result)
+	if err != nil {
+		return rpcSuccess(request.ID, toolResult{
+			Content: []toolContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		})
+	}
+	return rpcSuccess(request.ID, toolResult{
+		Content:           []toolContent{{Type: "text", Text: string(text)}},
+		StructuredContent: result,
+	})
+}
+
+// callSearchSymbols lists the symbols matching the query, see searchNodes
+func callSearchSymbols(snap *snapshot, args toolArgs) (any, error) {
+	query := strings.ToLower(strings.TrimSpace(args.Query))
+	if query == "" {
+		return nil, fmt.Errorf("the query is empty")
+	}
+	nodes := searchNodes(snap.graph, query, 0)
+	return listSymbols(nodes, nil, args.Limit), nil
+}
+
+// callGetSymbol returns a node
+func callGetSymbol(snap *snapshot, args toolArgs) (any, error) {
+	nodeID, err := resolveSymbol(snap.graph, args.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	return snap.graph.Nodes[nodeID], nil
+}
+
+// callRelated lists the nodes at distance 1, or all of them with the transitive argument, of
+// the distances from a symbol computed by the traversal
+func callRelated(snap *snapshot, args toolArgs, traverse func(nodeID string) map[string]int) (any, error) {
+	nodeID, err := resolveSymbol(snap.graph, args.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	distances := traverse(nodeID)
+	if !args.Transitive {
+		for relatedID, distance := range distances {
+			if distance > 1 {
+				delete(distances, relatedID)
+			}
+		}
+	}
+	return listSymbols(snap.transitive(distances), distances, args.Limit), nil
+}
+
+// callGetPath lists the shortest paths between two symbols
+func callGetPath(snap *snapshot, args toolArgs) (any, error) {
+	from, err := resolveSymbol(snap.graph, args.From)
+	if err != nil {
+		return nil, fmt.Errorf("from: %w", err)
+	}
+	to, err := resolveSymbol(snap.graph, args.To)
+	if err != nil {
+		return nil, fmt.Errorf("to: %w", err)
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = 3
+	}
+	paths := snap.graph.ShortestPaths(from, to, limit)
+	if paths == nil {
+		paths = [][]string{}
+	}
+	return map[string]any{"paths": paths}, nil
+}
+
+// callGetPackageSummary summarizes a package's size and coupling
+func callGetPackageSummary(snap *snapshot, args toolArgs) (any, error) {
+	pkg, err := snap.graph.ResolvePackage(args.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &packageSummary{DependsOn: make(map[string]int), DependedOnBy: make(map[string]int)}
+	for _, metrics := range snap.graph.ComputePackageMetrics() {
+		if metrics.Package == pkg {
+			summary.PackageMetrics = metrics
+		}
+	}
+	var nodes []*graph.Node
+	for sourceID, targets := range snap.graph.Edges {
+		source, exists := snap.graph.Nodes[sourceID]
+		if !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			target, exists := snap.graph.Nodes[targetID]
+			if !exists || source.Package == target.Package {
+				continue
+			}
+			if source.Package == pkg {
+				summary.DependsOn[target.Package]++
+			} else if target.Package == pkg {
+				summary.DependedOnBy[source.Package]++
+			}
+		}
+	}
+	for _, node := range snap.graph.Nodes {
+		if node.Package == pkg {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	summary.Cycles = len(cyclesThrough(snap.graph, pkg))
+	summary.Symbols = listSymbols(nodes, nil, args.Limit).Symbols
+	return summary, nil
+}
+
+// callFindCycles lists the dependency cycles, optionally only those through a package
+func callFindCycles(snap *snapshot, args toolArgs) (any, error) {
+	cycles := snap.graph.FindCycles()
+	if args.Package != "" {
+		pkg, err := snap.graph.ResolvePackage(args.Package)
+		if err != nil {
+			return nil, err
+		}
+		cycles = cyclesThrough(snap.graph, pkg)
+	}
+	result := cycleList{Cycles: cycles, Total: len(cycles)}
+	if result.Cycles == nil {
+		result.Cycles = [][]string{}
+	}
+	if limit := toolLimit(args.Limit); len(result.Cycles) > limit {
+		result.Cycles = result.Cycles[:limit]
+	}
+	return result, nil
+}
+
+// resolveSymbol resolves a symbol argument, see graph.ResolveNode, hinting at search_symbols
+// when it fails
+func resolveSymbol(g *graph.DependencyGraph, query string) (string, error) {
+	nodeID, err := g.ResolveNode(query)
+	if err != nil {
+		return "", fmt.Errorf("%w (search_symbols finds the symbol IDs)", err)
+	}
+	return nodeID, nil
+}
+
+// cyclesThrough returns the dependency cycles with a symbol of a package, largest first
+func cyclesThrough(g *graph.DependencyGraph, pkg string) [][]string {
+	var cycles [][]string
+	for _, cycle := range g.FindCycles() {
+		if slices.ContainsFunc(cycle, func(nodeID string) bool {
+			node, exists := g.Nodes[nodeID]
+			return exists && node.Package == pkg
+		}) {
+			cycles = append(cycles, cycle)
+		}
+	}
+	return cycles
+}
+
+// listSymbols returns the short forms of up to limit nodes, with their distances if known
+func listSymbols(nodes []*graph.Node, distances map[string]int, limit int) symbolList {
+	result := symbolList{Symbols: make([]mcpSymbol, 0, min(len(nodes), toolLimit(limit))), Total: len(nodes)}
+	for _, node := range nodes[:min(len(nodes), toolLimit(limit))] {
+		symbol := mcpSymbol{ID: node.ID, Kind: node.Kind, Distance: distances[node.ID]}
+		if node.Path != "" && node.Line > 0 {
+			symbol.Location = fmt.Sprintf("%s:%d", node.Path, node.Line)
+		}
+		result.Symbols = append(result.Symbols, symbol)
+	}
+	return result
+}
+
+// toolLimit returns the limit argument of a tool, or the default without one
+func toolLimit(limit int) int {
+	if limit == 0 {
+		return defaultToolLimit
+	}
+	return limit
+}
+
+// toolSchema returns the JSON schema of a tool's arguments
+func toolSchema(required []string, properties map[string]any) map[string]any {
+	if required == nil {
+		required = []string{}
+	}
+	return map[string]any{"type": "object", "properties": properties, "required": required}
+}
+
+// stringProperty returns the JSON schema of a string argument
+func stringProperty(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// limitProperty returns the JSON schema of the limit argument of the listing tools
+func limitProperty() map[string]any {
+	return map[string]any{"type": "integer", "minimum": 1, "description": fmt.Sprintf("Maximum number of results (default %d)", defaultToolLimit)}
+}
+
+// rpcSuccess returns the response of a successful request
+func rpcSuccess(id json.RawMessage, result any) *rpcResponse {
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Result: result}
+}
+
+// rpcFailure returns the error response of a failed request
+func rpcFailure(id json.RawMessage, code int, format string, args ...any) *rpcResponse {
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mcpSession sends JSON-RPC messages to ServeMCP, one per line, and decodes the responses
+func mcpSession(t *testing.T, s *Server, messages ...string) []rpcResponse {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.ServeMCP(strings.NewReader(strings.Join(messages, "\n")+"\n"), &out, "v1.0.0"); err != nil {
+		t.Fatalf("ServeMCP() error: %v", err)
+	}
+	var responses []rpcResponse
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var response rpcResponse
+		if err := decoder.Decode(&response); err != nil {
+			t.Fatalf("Invalid response: %v", err)
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// callMCPTool calls a tool and decodes the JSON text of its result
+func callMCPTool(t *testing.T, s *Server, name string, arguments map[string]any) (result map[string]any, isError bool, text string) {
+	t.Helper()
+	params, _ := json.Marshal(map[string]any{"name": name, "arguments": arguments})
+	responses := mcpSession(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+string(params)+`}`)
+	if len(responses) != 1 || responses[0].Error != nil {
+		t.Fatalf("tools/call %s: responses %+v", name, responses)
+	}
+	var toolResult struct {
+		Content []toolContent `json:"content"`
+		IsError bool          `json:"isError"`
+	}
+	raw, _ := json.Marshal(responses[0].Result)
+	if err := json.Unmarshal(raw, &toolResult); err != nil || len(toolResult.Content) != 1 {
+		t.Fatalf("tools/call %s: invalid result %s", name, raw)
+	}
+	text = toolResult.Content[0].Text
+	if !toolResult.IsError {
+		if err := json.Unmarshal([]byte(text), &result); err != nil {
+			t.Fatalf("tools/call %s: invalid text %q", name, text)
+		}
+	}
+	return result, toolResult.IsError, text
+}
+
+// symbolIDs returns the IDs of the symbols of a tool result
+func symbolIDs(result map[string]any) []string {
+	ids := make([]string, 0)
+	for _, symbol := range result["symbols"].([]any) {
+		ids = append(ids, symbol.(map[string]any)["id"].(string))
+	}
+	return ids
+}
+
+func TestMCP_Session(t *testing.T) {
+	s := newTestServer(t)
+
+	responses := mcpSession(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":"three","method":"ping"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/list"}`,
+		`not json`,
+	)
+	if len(responses) != 5 {
+		t.Fatalf("Got %d responses, want 5: %+v", len(responses), responses)
+	}
+
+	initialize := responses[0].Result.(map[string]any)
+	if initialize["protocolVersion"] != "2025-03-26" {
+		t.Errorf("protocolVersion = %v, want the requested 2025-03-26", initialize["protocolVersion"])
+	}
+	if info := initialize["serverInfo"].(map[string]any); info["name"] != "go-depmap" || info["version"] != "v1.0.0" {
+		t.Errorf("serverInfo = %v", info)
+	}
+
+	var names []string
+	for _, tool := range responses[1].Result.(map[string]any)["tools"].([]any) {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	for _, want := range []string{"get_dependents", "get_path", "get_package_summary", "find_cycles"} {
+		if !strings.Contains(strings.Join(names, " "), want) {
+			t.Errorf("tools/list = %v, missing %s", names, want)
+		}
+	}
+
+	if string(responses[2].ID) != `"three"` || responses[2].Error != nil {
+		t.Errorf("ping = %+v", responses[2])
+	}
+	if responses[3].Error == nil || responses[3].Error.Code != rpcMethodNotFound {
+		t.Errorf("Unknown method error = %+v, want %d", responses[3].Error, rpcMethodNotFound)
+	}
+	if responses[4].Error == nil || responses[4].Error.Code != rpcParseError || string(responses[4].ID) != "null" {
+		t.Errorf("Invalid JSON = %+v, want a parse error", responses[4])
+	}
+}
+
+func TestMCP_Tools(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		want      []string
+	}{
+		{"get_dependents", map[string]any{"symbol": "Open"}, []string{"app/cmd::main", "app/db::Query"}},
+		{"get_dependents", map[string]any{"symbol": "Query", "transitive": true}, []string{"app/cmd::main"}},
+		{"get_dependencies", map[string]any{"symbol": "main", "limit": 1}, []string{"app/db::Open"}},
+		{"search_symbols", map[string]any{"query": "DB"}, []string{"app/db::Open", "app/db::Query"}},
+	}
+	for _, tt := range tests {
+		result, isError, text := callMCPTool(t, s, tt.name, tt.arguments)
+		if isError {
+			t.Errorf("%s(%v) failed: %s", tt.name, tt.arguments, text)
+			continue
+		}
+		if got := symbolIDs(result); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s(%v) = %v, want %v", tt.name, tt.arguments, got, tt.want)
+		}
+	}
+
+	result, _, _ := callMCPTool(t, s, "get_path", map[string]any{"from": "main", "to": "app/db::Open"})
+	if got, _ := json.Marshal(result["paths"]); string(got) != `[["app/cmd::main","app/db::Open"]]` {
+		t.Errorf("get_path = %s", got)
+	}
+
+	result, _, _ = callMCPTool(t, s, "get_package_summary", map[string]any{"package": "db"})
+	if result["package"] != "app/db" || result["nodes"] != 2.0 {
+		t.Errorf("get_package_summary = %v", result)
+	}
+	if got := result["depended_on_by"]; !reflect.DeepEqual(got, map[string]any{"app/cmd": 2.0}) {
+		t.Errorf("depended_on_by = %v", got)
+	}
+
+	result, _, _ = callMCPTool(t, s, "find_cycles", nil)
+	if result["total"] != 0.0 {
+		t.Errorf("find_cycles = %v, want no cycles", result)
+	}
+
+	if _, isError, text := callMCPTool(t, s, "get_dependents", map[string]any{"symbol": "Missing"}); !isError || !strings.Contains(text, "Missing") {
+		t.Errorf("Unknown symbol: isError %v, text %q", isError, text)
+	}
+
+	responses := mcpSession(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_path","arguments":{"from":"main"}}}`)
+	if responses[0].Error == nil || responses[0].Error.Code != rpcInvalidParams {
+		t.Errorf("Missing argument error = %+v, want %d", responses[0].Error, rpcInvalidParams)
+	}
+}
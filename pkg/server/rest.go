@@ -0,0 +1,265 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-depmap/pkg/graph"
+)
+
+// defaultSearchLimit is the number of search results returned without a limit parameter
+const defaultSearchLimit = 20
+
+// apiError is the body of REST error responses
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// selection is the part of the graph a filter expression selects
+type selection struct {
+	NodeIDs []string     `json:"node_ids"`
+	Edges   []graph.Edge `json:"edges"`
+}
+
+// serveNodes lists the nodes matching the filter parameters, which are those of the GraphQL
+// NodeFilter, with limit and offset
+func (s *Server) serveNodes(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	filter := make(map[string]any)
+	for _, key := range []string{"package", "kind", "name", "owner"} {
+		if value := params.Get(key); value != "" {
+			filter[key] = value
+		}
+	}
+	for _, key := range []string{"external", "test"} {
+		if params.Has(key) {
+			value, err := strconv.ParseBool(params.Get(key))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid %s: %v", key, err)
+				return
+			}
+			filter[key] = value
+		}
+	}
+	if params.Has("subgraph") {
+		value, err := strconv.Atoi(params.Get("subgraph"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid subgraph: %v", err)
+			return
+		}
+		filter["subgraph"] = value
+	}
+
+	nodes := filterNodes(s.snapshot().graph, filter)
+	offset, limit, ok := pageParams(w, r, 0)
+	if !ok {
+		return
+	}
+	nodes = nodes[min(offset, len(nodes)):]
+	if limit > 0 && limit < len(nodes) {
+		nodes = nodes[:limit]
+	}
+	writeJSON(w, http.StatusOK, nodes)
+}
+
+// serveNode returns a node, or with a /dependencies or /dependents suffix the nodes it depends
+// on or that depend on it, transitively with transitive=true. The node ID may be in any form
+// accepted by graph.ResolveNode, path-escaped or not.
+func (s *Server) serveNode(w http.ResponseWriter, r *http.Request) {
+	snap := s.snapshot()
+	query, relation := r.PathValue("node"), ""
+	for _, suffix := range []string{"dependencies", "dependents"} {
+		if prefix, ok := strings.CutSuffix(query, "/"+suffix); ok {
+			query, relation = prefix, suffix
+		}
+	}
+	nodeID, err := snap.graph.ResolveNode(query)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "%v", err)
+		return
+	}
+
+	transitive := false
+	if r.URL.Query().Has("transitive") {
+		if transitive, err = strconv.ParseBool(r.URL.Query().Get("transitive")); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid transitive: %v", err)
+			return
+		}
+	}
+	switch relation {
+	case "dependencies":
+		writeJSON(w, http.StatusOK, resolveDependencies(snap, nodeID, transitive))
+	case "dependents":
+		writeJSON(w, http.StatusOK, resolveDependents(snap, nodeID, transitive))
+	default:
+		writeJSON(w, http.StatusOK, snap.graph.Nodes[nodeID])
+	}
+}
+
+// servePaths lists the shortest paths between the from and to nodes, as lists of node IDs
+func (s *Server) servePaths(w http.ResponseWriter, r *http.Request) {
+	g := s.snapshot().graph
+	from, err := g.ResolveNode(r.URL.Query().Get("from"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "from: %v", err)
+		return
+	}
+	to, err := g.ResolveNode(r.URL.Query().Get("to"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "to: %v", err)
+		return
+	}
+	_, limit, ok := pageParams(w, r, 10)
+	if !ok {
+		return
+	}
+	paths := g.ShortestPaths(from, to, limit)
+	if paths == nil {
+		paths = [][]string{}
+	}
+	writeJSON(w, http.StatusOK, paths)
+}
+
+// serveCycles lists the dependency cycles as lists of node IDs, largest first
+func (s *Server) serveCycles(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.snapshot().graph.FindCycles())
+}
+
+// serveSubgraphs lists the subgraphs by descending score, up to the limit parameter
+func (s *Server) serveSubgraphs(w http.ResponseWriter, r *http.Request) {
+	subgraphs := s.snapshot().graph.Subgraphs
+	_, limit, ok := pageParams(w, r, 0)
+	if !ok {
+		return
+	}
+	if limit > 0 && limit < len(subgraphs) {
+		subgraphs = subgraphs[:limit]
+	}
+	writeJSON(w, http.StatusOK, subgraphs)
+}
+
+// serveSearch finds the nodes whose name or ID contains the q parameter, see searchNodes
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "missing q")
+		return
+	}
+	_, limit, ok := pageParams(w, r, defaultSearchLimit)
+	if !ok {
+		return
+	}
+
+	nodes := searchNodes(s.snapshot().graph, query, limit)
+	writeJSON(w, http.StatusOK, nodes)
+}
+
+// serveSelect returns the nodes and edges selected by the filter expression in the expr
+// parameter, see graph.Expr: the matching nodes and the edges between them, or the matching
+// edges and their nodes
+func (s *Server) serveSelect(w http.ResponseWriter, r *http.Request) {
+	expr, err := graph.ParseExpr(r.URL.Query().Get("expr"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid expr: %v", err)
+		return
+	}
+	g := s.snapshot().graph
+	result := selection{NodeIDs: g.MatchNodes(expr), Edges: g.MatchEdges(expr)}
+	if result.NodeIDs == nil {
+		result.NodeIDs = []string{}
+	}
+	if result.Edges == nil {
+		result.Edges = []graph.Edge{}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// serveMetadata returns the provenance of the graph, or null without metadata
+func (s *Server) serveMetadata(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.snapshot().graph.Metadata)
+}
+
+// serveGraph returns the whole graph in the standard JSON format, or with package parameters
+// the part of the graph in the packages matching them, with the nodes one edge away when
+// boundary=true (see graph.SubgraphForPackages)
+func (s *Server) serveGraph(w http.ResponseWriter, r *http.Request) {
+	g := s.snapshot().graph
+	params := r.URL.Query()
+	boundary := false
+	if params.Has("boundary") {
+		var err error
+		if boundary, err = strconv.ParseBool(params.Get("boundary")); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid boundary: %v", err)
+			return
+		}
+	}
+	if patterns := params["package"]; len(patterns) > 0 {
+		g = g.SubgraphForPackages(patterns, boundary)
+	}
+	writeJSON(w, http.StatusOK, g)
+}
+
+// searchNodes finds up to limit nodes (0 = all) whose name or ID contains a lowercase query.
+// Exact name matches come first, then name prefixes, then other name matches, then ID matches.
+func searchNodes(g *graph.DependencyGraph, query string, limit int) []*graph.Node {
+	type match struct {
+		node *graph.Node
+		rank int
+	}
+	var matches []match
+	for nodeID, node := range g.Nodes {
+		name := strings.ToLower(node.Name)
+		switch {
+		case name == query:
+			matches = append(matches, match{node, 0})
+		case strings.HasPrefix(name, query):
+			matches = append(matches, match{node, 1})
+		case strings.Contains(name, query):
+			matches = append(matches, match{node, 2})
+		case strings.Contains(strings.ToLower(nodeID), query):
+			matches = append(matches, match{node, 3})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return matches[i].node.ID < matches[j].node.ID
+	})
+
+	nodes := make([]*graph.Node, 0, len(matches))
+	for _, m := range matches {
+		if limit > 0 && len(nodes) == limit {
+			break
+		}
+		nodes = append(nodes, m.node)
+	}
+	return nodes
+}
+
+// pageParams reads the offset and limit parameters, writing an error response if they are
+// invalid. A missing limit is the given default, where 0 means no limit.
+func pageParams(w http.ResponseWriter, r *http.Request, defaultLimit int) (offset, limit int, ok bool) {
+	values := map[string]*int{"offset": &offset, "limit": &limit}
+	limit = defaultLimit
+	for key, target := range values {
+		if !r.URL.Query().Has(key) {
+			continue
+		}
+		value, err := strconv.Atoi(r.URL.Query().Get(key))
+		if err != nil || value < 0 {
+			writeError(w, http.StatusBadRequest, "invalid %s: %q", key, r.URL.Query().Get(key))
+			return 0, 0, false
+		}
+		*target = value
+	}
+	return offset, limit, true
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, apiError{Error: fmt.Sprintf(format, args...)})
+}
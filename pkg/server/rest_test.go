@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// getJSON requests a REST endpoint and decodes its response
+func getJSON(t *testing.T, s *Server, target string, wantStatus int, result any) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	if recorder.Code != wantStatus {
+		t.Fatalf("GET %s: status %d, want %d, body %s", target, recorder.Code, wantStatus, recorder.Body)
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), result); err != nil {
+		t.Fatalf("GET %s: invalid response: %v", target, err)
+	}
+}
+
+// nodeIDs returns the IDs of a list of nodes from a response
+func nodeIDs(nodes []map[string]any) []string {
+	result := make([]string, len(nodes))
+	for i, node := range nodes {
+		result[i] = node["id"].(string)
+	}
+	return result
+}
+
+func TestREST_Nodes(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/api/nodes", []string{"app/cmd::main", "app/db::Open", "app/db::Query", "app/util::Helper"}},
+		{"/api/nodes?package=app/db&limit=1&offset=1", []string{"app/db::Query"}},
+		{"/api/nodes?name=HELP&external=false", []string{"app/util::Helper"}},
+		{"/api/node/app/db::Open/dependents", []string{"app/cmd::main", "app/db::Query"}},
+		{"/api/node/main/dependencies?transitive=true", []string{"app/db::Open", "app/db::Query"}},
+		{"/api/search?q=e", []string{"app/db::Open", "app/db::Query", "app/util::Helper"}},
+		{"/api/search?q=db", []string{"app/db::Open", "app/db::Query"}},
+		{"/api/search?q=ma", []string{"app/cmd::main"}},
+		{"/api/search?q=o&limit=1", []string{"app/db::Open"}},
+	}
+	for _, tt := range tests {
+		var nodes []map[string]any
+		getJSON(t, s, tt.target, http.StatusOK, &nodes)
+		if got := nodeIDs(nodes); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GET %s = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestREST_Node(t *testing.T) {
+	s := newTestServer(t)
+
+	var node map[string]any
+	getJSON(t, s, "/api/node/"+url.PathEscape("db::Open"), http.StatusOK, &node)
+	if node["id"] != "app/db::Open" || node["attrs"].(map[string]any)["team"] != "storage" {
+		t.Errorf("Unexpected node: %v", node)
+	}
+
+	var apiErr apiError
+	getJSON(t, s, "/api/node/missing", http.StatusNotFound, &apiErr)
+	if apiErr.Error == "" {
+		t.Errorf("Expected an error message")
+	}
+	getJSON(t, s, "/api/node/main/dependents?transitive=maybe", http.StatusBadRequest, &apiErr)
+	getJSON(t, s, "/api/nodes?limit=-1", http.StatusBadRequest, &apiErr)
+	getJSON(t, s, "/api/search", http.StatusBadRequest, &apiErr)
+	getJSON(t, s, "/api/select?expr="+url.QueryEscape("kind="), http.StatusBadRequest, &apiErr)
+}
+
+func TestREST_Select(t *testing.T) {
+	s := newTestServer(t)
+
+	var result selection
+	getJSON(t, s, "/api/select?expr="+url.QueryEscape(`package=app/db || fanout>1`), http.StatusOK, &result)
+	if !reflect.DeepEqual(result.NodeIDs, []string{"app/cmd::main", "app/db::Open", "app/db::Query"}) || len(result.Edges) != 3 {
+		t.Errorf("Node selection = %+v", result)
+	}
+
+	getJSON(t, s, "/api/select?expr="+url.QueryEscape(`source.package!=target.package`), http.StatusOK, &result)
+	if !reflect.DeepEqual(result.NodeIDs, []string{"app/cmd::main", "app/db::Open", "app/db::Query"}) || len(result.Edges) != 2 {
+		t.Errorf("Edge selection = %+v", result)
+	}
+
+	getJSON(t, s, "/api/select?expr="+url.QueryEscape(`attr.team=payments`), http.StatusOK, &result)
+	if len(result.NodeIDs) != 0 || len(result.Edges) != 0 {
+		t.Errorf("Empty selection = %+v", result)
+	}
+}
+
+func TestREST_Graph(t *testing.T) {
+	s := newTestServer(t)
+
+	var paths [][]string
+	getJSON(t, s, "/api/paths?from=main&to=Open", http.StatusOK, &paths)
+	if !reflect.DeepEqual(paths, [][]string{{"app/cmd::main", "app/db::Open"}}) {
+		t.Errorf("paths = %v", paths)
+	}
+	getJSON(t, s, "/api/paths?from=Open&to=main", http.StatusOK, &paths)
+	if len(paths) != 0 {
+		t.Errorf("Expected no paths against the edges, got %v", paths)
+	}
+
+	var cycles [][]string
+	getJSON(t, s, "/api/cycles", http.StatusOK, &cycles)
+	if len(cycles) != 0 {
+		t.Errorf("cycles = %v", cycles)
+	}
+
+	var subgraphs []map[string]any
+	getJSON(t, s, "/api/subgraphs?limit=1", http.StatusOK, &subgraphs)
+	if len(subgraphs) != 1 || subgraphs[0]["edge_count"] != 3.0 {
+		t.Errorf("subgraphs = %v", subgraphs)
+	}
+
+	var metadata map[string]any
+	getJSON(t, s, "/api/metadata", http.StatusOK, &metadata)
+	if metadata["tool"] != "go-depmap" {
+		t.Errorf("metadata = %v", metadata)
+	}
+
+	var g struct {
+		Nodes map[string]map[string]any `json:"nodes"`
+		Edges map[string][]string       `json:"edges"`
+	}
+	getJSON(t, s, "/api/graph?package=app/db&boundary=true", http.StatusOK, &g)
+	if len(g.Nodes) != 3 || g.Nodes["app/cmd::main"]["external"] != true || len(g.Edges["app/cmd::main"]) != 2 {
+		t.Errorf("graph of app/db with its boundary = %v", g)
+	}
+	g.Nodes = nil
+	getJSON(t, s, "/api/graph?package=app/util&package=app/cmd", http.StatusOK, &g)
+	if len(g.Nodes) != 2 {
+		t.Errorf("graph of app/util and app/cmd = %v", g.Nodes)
+	}
+	var apiErr apiError
+	getJSON(t, s, "/api/graph?package=app/db&boundary=maybe", http.StatusBadRequest, &apiErr)
+}
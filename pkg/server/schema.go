@@ -0,0 +1,418 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"go-depmap/pkg/graph"
+
+	"github.com/graphql-go/graphql"
+)
+
+// The GraphQL schema, with node IDs accepted in the forms of graph.ResolveNode:
+//
+//	type Query {
+//	  node(id: String!): Node
+//	  nodes(filter: NodeFilter, limit: Int, offset: Int = 0): [Node!]!
+//	  dependencies(id: String!, transitive: Boolean = false): [Node!]!
+//	  dependents(id: String!, transitive: Boolean = false): [Node!]!
+//	  paths(from: String!, to: String!, limit: Int = 10): [[Node!]!]!
+//	  cycles: [[Node!]!]!
+//	  subgraphs(limit: Int): [Subgraph!]!
+//	  metadata: Metadata
+//	}
+//
+// Node has the fields of graph.Node in camel case, its custom attributes as key and JSON value
+// pairs, and its direct or transitive dependencies and dependents. Transitive lists are sorted
+// by distance, all others by ID.
+
+// snapshotKey is the context key of the snapshot a GraphQL request is resolved against
+type snapshotKey struct{}
+
+// snapshotFrom returns the snapshot a GraphQL request is resolved against
+func snapshotFrom(ctx context.Context) *snapshot {
+	return ctx.Value(snapshotKey{}).(*snapshot)
+}
+
+// edge is an edge between two nodes, as resolved by GraphQL
+type edge struct {
+	Source, Target *graph.Node
+	Kinds          []graph.EdgeKind
+	Weight         int
+}
+
+// attr is a custom attribute, as resolved by GraphQL
+type attr struct {
+	Key   string `json:"key"`
+	Value string `json:"value"` // JSON encoding of the value
+}
+
+// newSchema builds the GraphQL schema
+func newSchema() (graphql.Schema, error) {
+	nonNullString := graphql.NewNonNull(graphql.String)
+	idArgs := func(transitive bool) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{"id": {Type: nonNullString}}
+		if transitive {
+			args["transitive"] = &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false}
+		}
+		return args
+	}
+
+	attrType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Attr",
+		Description: "A custom attribute set by an analyzer, overlay or plugin",
+		Fields: graphql.Fields{
+			"key":   {Type: nonNullString},
+			"value": {Type: nonNullString, Description: "JSON encoding of the value"},
+		},
+	})
+
+	var nodeType, edgeType *graphql.Object
+	nodeList := func() graphql.Output {
+		return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(nodeType)))
+	}
+	nodeType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Node",
+		Description: "A function, method, type, variable or package",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":            nodeField(nonNullString, func(n *graph.Node) any { return n.ID }),
+				"name":          nodeField(nonNullString, func(n *graph.Node) any { return n.Name }),
+				"kind":          nodeField(nonNullString, func(n *graph.Node) any { return string(n.Kind) }),
+				"package":       nodeField(nonNullString, func(n *graph.Node) any { return n.Package }),
+				"file":          nodeField(graphql.String, func(n *graph.Node) any { return n.File }),
+				"path":          nodeField(graphql.String, func(n *graph.Node) any { return n.Path }),
+				"line":          nodeField(graphql.Int, func(n *graph.Node) any { return n.Line }),
+				"signature":     nodeField(graphql.String, func(n *graph.Node) any { return n.Signature }),
+				"subgraphId":    nodeField(graphql.Int, func(n *graph.Node) any { return n.SubgraphID }),
+				"subgraphScore": nodeField(graphql.Float, func(n *graph.Node) any { return n.SubgraphScore }),
+				"external":      nodeField(graphql.Boolean, func(n *graph.Node) any { return n.External }),
+				"doc":           nodeField(graphql.String, func(n *graph.Node) any { return n.Doc }),
+				"docUrl":        nodeField(graphql.String, func(n *graph.Node) any { return n.DocURL }),
+				"lines":         nodeField(graphql.Int, func(n *graph.Node) any { return n.Lines }),
+				"complexity":    nodeField(graphql.Int, func(n *graph.Node) any { return n.Complexity }),
+				"churn":         nodeField(graphql.Int, func(n *graph.Node) any { return n.Churn }),
+				"owner":         nodeField(graphql.String, func(n *graph.Node) any { return n.Owner }),
+				"deprecated":    nodeField(graphql.String, func(n *graph.Node) any { return n.Deprecated }),
+				"generated":     nodeField(graphql.Boolean, func(n *graph.Node) any { return n.Generated }),
+				"mock":          nodeField(graphql.Boolean, func(n *graph.Node) any { return n.Mock }),
+				"test":          nodeField(graphql.Boolean, func(n *graph.Node) any { return n.IsTest() }),
+				"recursive":     nodeField(graphql.Boolean, func(n *graph.Node) any { return n.Recursive }),
+				"risky":         nodeField(graphql.Boolean, func(n *graph.Node) any { return n.IsRisky() }),
+				"attrs": nodeField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(attrType))), func(n *graph.Node) any {
+					return attrsOf(n.Attrs)
+				}),
+				"dependencies": {
+					Type: nodeList(),
+					Args: graphql.FieldConfigArgument{"transitive": {Type: graphql.Boolean, DefaultValue: false}},
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return resolveDependencies(snapshotFrom(p.Context), p.Source.(*graph.Node).ID, p.Args["transitive"].(bool)), nil
+					},
+				},
+				"dependents": {
+					Type: nodeList(),
+					Args: graphql.FieldConfigArgument{"transitive": {Type: graphql.Boolean, DefaultValue: false}},
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return resolveDependents(snapshotFrom(p.Context), p.Source.(*graph.Node).ID, p.Args["transitive"].(bool)), nil
+					},
+				},
+				"edges": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+					Description: "Outgoing edges to the nodes of the graph, with their kinds",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						snap, source := snapshotFrom(p.Context), p.Source.(*graph.Node)
+						edges := make([]edge, 0)
+						for _, target := range snap.dependencies(source.ID) {
+							edges = append(edges, edge{
+								Source: source,
+								Target: target,
+								Kinds:  snap.graph.EdgeKindsOf(source.ID, target.ID),
+								Weight: snap.graph.EdgeWeight(source.ID, target.ID),
+							})
+						}
+						return edges, nil
+					},
+				},
+			}
+		}),
+	})
+
+	edgeType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Edge",
+		Description: "A dependency of one node on another",
+		Fields: graphql.Fields{
+			"source": edgeField(graphql.NewNonNull(nodeType), func(e edge) any { return e.Source }),
+			"target": edgeField(graphql.NewNonNull(nodeType), func(e edge) any { return e.Target }),
+			"kinds": edgeField(graphql.NewNonNull(graphql.NewList(nonNullString)), func(e edge) any {
+				kinds := make([]string, len(e.Kinds))
+				for i, kind := range e.Kinds {
+					kinds[i] = string(kind)
+				}
+				return kinds
+			}),
+			"weight": edgeField(graphql.NewNonNull(graphql.Int), func(e edge) any { return e.Weight }),
+		},
+	})
+
+	subgraphType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Subgraph",
+		Description: "A connected component, ranked by score",
+		Fields: graphql.Fields{
+			"id":        subgraphField(graphql.NewNonNull(graphql.Int), func(s graph.Subgraph) any { return s.ID }),
+			"score":     subgraphField(graphql.NewNonNull(graphql.Float), func(s graph.Subgraph) any { return s.Score }),
+			"edgeCount": subgraphField(graphql.NewNonNull(graphql.Int), func(s graph.Subgraph) any { return s.EdgeCount }),
+			"nodes": {
+				Type: nodeList(),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return snapshotFrom(p.Context).nodes(p.Source.(graph.Subgraph).NodeIDs), nil
+				},
+			},
+		},
+	})
+
+	metadataType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Metadata",
+		Description: "Provenance of the graph",
+		Fields: graphql.Fields{
+			"tool":      metadataField(graphql.String, func(m *graph.Metadata) any { return m.Tool }),
+			"version":   metadataField(graphql.String, func(m *graph.Metadata) any { return m.Version }),
+			"commit":    metadataField(graphql.String, func(m *graph.Metadata) any { return m.Commit }),
+			"buildDate": metadataField(graphql.String, func(m *graph.Metadata) any { return m.BuildDate }),
+			"module":    metadataField(graphql.String, func(m *graph.Metadata) any { return m.Module }),
+			"goVersion": metadataField(graphql.String, func(m *graph.Metadata) any { return m.GoVersion }),
+			"timestamp": metadataField(graphql.String, func(m *graph.Metadata) any { return m.Timestamp.Format(time.RFC3339) }),
+			"patterns":  metadataField(graphql.NewList(nonNullString), func(m *graph.Metadata) any { return m.Patterns }),
+			"nodes":     metadataField(graphql.Int, func(m *graph.Metadata) any { return m.Nodes }),
+			"edges":     metadataField(graphql.Int, func(m *graph.Metadata) any { return m.Edges }),
+		},
+	})
+
+	nodeFilterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "NodeFilter",
+		Description: "Conditions a node must all meet",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"package":  {Type: graphql.String, Description: "Package pattern, where a trailing /... matches the packages below"},
+			"kind":     {Type: graphql.String, Description: "Node kind, e.g. function or struct"},
+			"name":     {Type: graphql.String, Description: "Case-insensitive substring of the name"},
+			"owner":    {Type: graphql.String, Description: "One of the owners from CODEOWNERS"},
+			"external": {Type: graphql.Boolean},
+			"test":     {Type: graphql.Boolean, Description: "Defined in a _test.go file"},
+			"subgraph": {Type: graphql.Int, Description: "Subgraph ID"},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"node": {
+				Type: nodeType,
+				Args: idArgs(false),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					snap := snapshotFrom(p.Context)
+					nodeID, err := snap.graph.ResolveNode(p.Args["id"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return snap.graph.Nodes[nodeID], nil
+				},
+			},
+			"nodes": {
+				Type: nodeList(),
+				Args: graphql.FieldConfigArgument{
+					"filter": {Type: nodeFilterType},
+					"limit":  {Type: graphql.Int},
+					"offset": {Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					filter, _ := p.Args["filter"].(map[string]any)
+					nodes := filterNodes(snapshotFrom(p.Context).graph, filter)
+					nodes = nodes[min(p.Args["offset"].(int), len(nodes)):]
+					if limit, ok := p.Args["limit"].(int); ok && limit < len(nodes) {
+						nodes = nodes[:max(limit, 0)]
+					}
+					return nodes, nil
+				},
+			},
+			"dependencies": {
+				Type: nodeList(),
+				Args: idArgs(true),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					snap := snapshotFrom(p.Context)
+					nodeID, err := snap.graph.ResolveNode(p.Args["id"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return resolveDependencies(snap, nodeID, p.Args["transitive"].(bool)), nil
+				},
+			},
+			"dependents": {
+				Type: nodeList(),
+				Args: idArgs(true),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					snap := snapshotFrom(p.Context)
+					nodeID, err := snap.graph.ResolveNode(p.Args["id"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return resolveDependents(snap, nodeID, p.Args["transitive"].(bool)), nil
+				},
+			},
+			"paths": {
+				Type:        graphql.NewNonNull(graphql.NewList(nodeList())),
+				Description: "Shortest paths along the edges from one node to another",
+				Args: graphql.FieldConfigArgument{
+					"from":  {Type: nonNullString},
+					"to":    {Type: nonNullString},
+					"limit": {Type: graphql.Int, DefaultValue: 10},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					snap := snapshotFrom(p.Context)
+					from, err := snap.graph.ResolveNode(p.Args["from"].(string))
+					if err != nil {
+						return nil, err
+					}
+					to, err := snap.graph.ResolveNode(p.Args["to"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return snap.pathNodes(snap.graph.ShortestPaths(from, to, p.Args["limit"].(int))), nil
+				},
+			},
+			"cycles": {
+				Type:        graphql.NewNonNull(graphql.NewList(nodeList())),
+				Description: "Dependency cycles, largest first",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					snap := snapshotFrom(p.Context)
+					return snap.pathNodes(snap.graph.FindCycles()), nil
+				},
+			},
+			"subgraphs": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(subgraphType))),
+				Args: graphql.FieldConfigArgument{"limit": {Type: graphql.Int}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					subgraphs := snapshotFrom(p.Context).graph.Subgraphs
+					if limit, ok := p.Args["limit"].(int); ok && limit < len(subgraphs) {
+						subgraphs = subgraphs[:max(limit, 0)]
+					}
+					return subgraphs, nil
+				},
+			},
+			"metadata": {
+				Type: metadataType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					if metadata := snapshotFrom(p.Context).graph.Metadata; metadata != nil {
+						return metadata, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// nodeField returns a field resolved from the graph.Node of its object
+func nodeField(typ graphql.Output, value func(*graph.Node) any) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (any, error) {
+		return value(p.Source.(*graph.Node)), nil
+	}}
+}
+
+// edgeField returns a field resolved from the edge of its object
+func edgeField(typ graphql.Output, value func(edge) any) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (any, error) {
+		return value(p.Source.(edge)), nil
+	}}
+}
+
+// subgraphField returns a field resolved from the graph.Subgraph of its object
+func subgraphField(typ graphql.Output, value func(graph.Subgraph) any) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (any, error) {
+		return value(p.Source.(graph.Subgraph)), nil
+	}}
+}
+
+// metadataField returns a field resolved from the graph.Metadata of its object
+func metadataField(typ graphql.Output, value func(*graph.Metadata) any) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(p graphql.ResolveParams) (any, error) {
+		return value(p.Source.(*graph.Metadata)), nil
+	}}
+}
+
+// resolveDependencies returns the nodes a node depends on, directly or transitively
+func resolveDependencies(snap *snapshot, nodeID string, transitive bool) []*graph.Node {
+	if transitive {
+		return snap.transitive(snap.graph.Reachable(nodeID))
+	}
+	return snap.dependencies(nodeID)
+}
+
+// resolveDependents returns the nodes depending on a node, directly or transitively
+func resolveDependents(snap *snapshot, nodeID string, transitive bool) []*graph.Node {
+	if transitive {
+		return snap.transitive(snap.graph.Dependents(nodeID))
+	}
+	return snap.nodes(snap.dependents[nodeID])
+}
+
+// pathNodes returns the nodes of lists of node IDs, keeping their order
+func (snap *snapshot) pathNodes(paths [][]string) [][]*graph.Node {
+	result := make([][]*graph.Node, len(paths))
+	for i, path := range paths {
+		result[i] = make([]*graph.Node, len(path))
+		for j, nodeID := range path {
+			result[i][j] = snap.graph.Nodes[nodeID]
+		}
+	}
+	return result
+}
+
+// filterNodes returns the nodes meeting all conditions of a NodeFilter, sorted by ID
+func filterNodes(g *graph.DependencyGraph, filter map[string]any) []*graph.Node {
+	pattern, _ := filter["package"].(string)
+	kind, _ := filter["kind"].(string)
+	name, _ := filter["name"].(string)
+	owner, _ := filter["owner"].(string)
+	external, hasExternal := filter["external"].(bool)
+	test, hasTest := filter["test"].(bool)
+	subgraph, hasSubgraph := filter["subgraph"].(int)
+
+	nodeIDs := make([]string, 0, len(g.Nodes))
+	for nodeID, node := range g.Nodes {
+		switch {
+		case pattern != "" && !graph.MatchPackagePattern(pattern, node.Package),
+			kind != "" && string(node.Kind) != kind,
+			name != "" && !strings.Contains(strings.ToLower(node.Name), strings.ToLower(name)),
+			owner != "" && !slices.Contains(strings.Fields(node.Owner), owner),
+			hasExternal && node.External != external,
+			hasTest && node.IsTest() != test,
+			hasSubgraph && node.SubgraphID != subgraph:
+			continue
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	slices.Sort(nodeIDs)
+
+	nodes := make([]*graph.Node, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		nodes[i] = g.Nodes[nodeID]
+	}
+	return nodes
+}
+
+// attrsOf returns custom attributes sorted by key, with JSON-encoded values
+func attrsOf(attrs map[string]any) []attr {
+	result := make([]attr, 0, len(attrs))
+	for _, key := range graph.AttrKeys(attrs) {
+		value, err := json.Marshal(attrs[key])
+		if err != nil {
+			continue
+		}
+		result = append(result, attr{Key: key, Value: string(value)})
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+// Package server serves a dependency graph over HTTP, so that dashboards and other tools can
+// query live analysis results.
+package server
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"go-depmap/pkg/graph"
+
+	"github.com/graphql-go/graphql"
+	"golang.org/x/net/websocket"
+)
+
+// Server serves the latest dependency graph. The graph can be replaced while serving, such as
+// after a new analysis; each request sees a single graph from start to end.
+type Server struct {
+	mu          sync.RWMutex
+	current     *snapshot
+	version     int                      // Number of updates with changes, see Update
+	subscribers map[chan []byte]struct{} // Message queues of the live clients
+	updateMu    sync.Mutex               // Serializes updates, so that each delta applies to the previous version
+	schema      graphql.Schema
+}
+
+// snapshot is a served graph with the indexes derived from it
+type snapshot struct {
+	graph      *graph.DependencyGraph
+	dependents map[string][]string // TargetID -> sorted SourceIDs, for the nodes of the graph
+}
+
+// New returns a server for a graph, whose subgraphs must have been computed
+func New(g *graph.DependencyGraph) (*Server, error) {
+	schema, err := newSchema()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{schema: schema, subscribers: make(map[chan []byte]struct{})}
+	s.SetGraph(g)
+	return s, nil
+}
+
+// Graph returns the graph being served
+func (s *Server) Graph() *graph.DependencyGraph {
+	return s.snapshot().graph
+}
+
+// SetGraph replaces the graph being served, without notifying the live clients (see Update).
+// Requests in progress finish with the previous one.
+func (s *Server) SetGraph(g *graph.DependencyGraph) {
+	next := newSnapshot(g)
+	s.mu.Lock()
+	s.current = next
+	s.mu.Unlock()
+}
+
+// snapshot returns the graph being served with its indexes
+func (s *Server) snapshot() *snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Handler returns the HTTP handler of the server's endpoints:
+//
+//	GET, POST /graphql                    GraphQL queries, see schema.go
+//	GET /api/nodes                        nodes, filtered by the NodeFilter fields as parameters
+//	GET /api/node/{id}                    a node
+//	GET /api/node/{id}/dependencies       nodes it depends on, with transitive=true
+//	GET /api/node/{id}/dependents         nodes depending on it, with transitive=true
+//	GET /api/paths?from=&to=              shortest paths, as lists of node IDs
+//	GET /api/cycles                       dependency cycles, as lists of node IDs
+//	GET /api/subgraphs                    subgraphs by descending score
+//	GET /api/search?q=                    nodes whose name or ID contains q
+//	GET /api/metadata                     provenance of the graph
+//	GET /api/graph                        the whole graph, in the standard JSON format
+//	GET /api/live                         WebSocket of the graph's snapshot and deltas, see Update
+//	GET /                                 live view of the graph
+//
+// The list endpoints take a limit parameter, and /api/nodes an offset.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /graphql", s.serveGraphQL)
+	mux.HandleFunc("POST /graphql", s.serveGraphQL)
+	mux.HandleFunc("GET /api/nodes", s.serveNodes)
+	mux.HandleFunc("GET /api/node/{node...}", s.serveNode)
+	mux.HandleFunc("GET /api/paths", s.servePaths)
+	mux.HandleFunc("GET /api/cycles", s.serveCycles)
+	mux.HandleFunc("GET /api/subgraphs", s.serveSubgraphs)
+	mux.HandleFunc("GET /api/search", s.serveSearch)
+	mux.HandleFunc("GET /api/select", s.serveSelect)
+	mux.HandleFunc("GET /api/metadata", s.serveMetadata)
+	mux.HandleFunc("GET /api/graph", s.serveGraph)
+	mux.Handle("GET /api/live", websocket.Handler(s.serveLive))
+	mux.HandleFunc("GET /{$}", serveIndex)
+	return mux
+}
+
+// newSnapshot indexes a graph for serving
+func newSnapshot(g *graph.DependencyGraph) *snapshot {
+	dependents := make(map[string][]string)
+	for sourceID, targets := range g.Edges {
+		if _, exists := g.Nodes[sourceID]; !exists {
+			continue
+		}
+		for _, targetID := range targets {
+			if _, exists := g.Nodes[targetID]; exists {
+				dependents[targetID] = append(dependents[targetID], sourceID)
+			}
+		}
+	}
+	for _, sources := range dependents {
+		sort.Strings(sources)
+	}
+	return &snapshot{graph: g, dependents: dependents}
+}
+
+// dependencies returns the nodes a node depends on directly, sorted by ID
+func (snap *snapshot) dependencies(nodeID string) []*graph.Node {
+	return snap.nodes(snap.graph.Edges[nodeID])
+}
+
+// transitive returns the nodes at a distance of 1 or more, sorted by distance, then ID
+func (snap *snapshot) transitive(distances map[string]int) []*graph.Node {
+	nodeIDs := make([]string, 0, len(distances))
+	for nodeID, distance := range distances {
+		if distance > 0 {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool {
+		if distances[nodeIDs[i]] != distances[nodeIDs[j]] {
+			return distances[nodeIDs[i]] < distances[nodeIDs[j]]
+		}
+		return nodeIDs[i] < nodeIDs[j]
+	})
+	nodes := make([]*graph.Node, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		nodes[i] = snap.graph.Nodes[nodeID]
+	}
+	return nodes
+}
+
+// nodes returns the nodes of the given IDs sorted by ID, skipping the unknown ones
+func (snap *snapshot) nodes(nodeIDs []string) []*graph.Node {
+	nodes := make([]*graph.Node, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		if node, exists := snap.graph.Nodes[nodeID]; exists {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes
+}